@@ -0,0 +1,122 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inancgumus/learngo/pkg/errtrace"
+)
+
+// errUserNotFound is the sentinel at the bottom of the chain
+var errUserNotFound = errors.New("user not found")
+
+func main() {
+	fmt.Println("Stack Traces vs %w Wrapping")
+	fmt.Println("===========================")
+	fmt.Println()
+
+	// Example 1: The 01-error-wrapping way: a story, but no location
+	fmt.Println("1. Plain %w wrapping:")
+	err := processUserData(999)
+	fmt.Printf("   %%v:  %v\n", err)
+	fmt.Printf("   %%+v: %+v\n", err)
+	fmt.Println()
+
+	// Example 2: The same call chain, with a trace at the origin
+	fmt.Println("2. errtrace.Wrap where the error starts:")
+	err = processUserDataTraced(999)
+	fmt.Printf("   %%v:  %v\n", err)
+	fmt.Println("   trace:")
+	printFrames(errtrace.Frames(err))
+	fmt.Println()
+
+	// Example 3: Wrapping an error from the standard library
+	fmt.Println("3. errtrace.Wrap around an os error:")
+	err = loadConfig("missing.json")
+	fmt.Printf("   %%+v:\n%s\n", indent(fmt.Sprintf("%+v", err)))
+	fmt.Println()
+
+	// Example 4: The chain still works
+	fmt.Println("4. errors.Is and errors.As see through it:")
+	var pathErr *fs.PathError
+	found := errors.As(err, &pathErr)
+	fmt.Println("   errors.Is(err, fs.ErrNotExist):", errors.Is(err, fs.ErrNotExist))
+	fmt.Println("   errors.As(err, &pathErr):      ", found, pathErr.Path)
+	fmt.Println("   errors.Is(traced, sentinel):   ", errors.Is(processUserDataTraced(999), errUserNotFound))
+	fmt.Println()
+
+	// Example 5: Only the first trace is kept
+	fmt.Println("5. Wrapping twice keeps the original trace:")
+	again := errtrace.Wrap(fmt.Errorf("request failed: %w", processUserDataTraced(999)))
+	frames := errtrace.Frames(again)
+	fmt.Println("   message:", again)
+	fmt.Println("   trace starts in:", short(frames[0].Function))
+}
+
+// processUserData and loadUser are the chain from 01-error-wrapping
+func processUserData(userID int) error {
+	if err := loadUser(userID); err != nil {
+		return fmt.Errorf("failed to process user data: %w", err)
+	}
+	return nil
+}
+
+func loadUser(userID int) error {
+	if userID == 999 {
+		return fmt.Errorf("failed to load user %d from database: %w", userID, errUserNotFound)
+	}
+	return nil
+}
+
+// processUserDataTraced is the same chain, traced at the bottom
+func processUserDataTraced(userID int) error {
+	if err := loadUserTraced(userID); err != nil {
+		return fmt.Errorf("failed to process user data: %w", err)
+	}
+	return nil
+}
+
+func loadUserTraced(userID int) error {
+	if userID == 999 {
+		// Wrap captures the stack here, where the error starts
+		return errtrace.Wrap(fmt.Errorf("failed to load user %d from database: %w", userID, errUserNotFound))
+	}
+	return nil
+}
+
+// loadConfig reads a config file
+func loadConfig(name string) error {
+	_, err := os.ReadFile(name)
+	return errtrace.Wrap(err) // nil stays nil
+}
+
+// printFrames prints the frames of this program, with short paths
+func printFrames(frames []errtrace.Frame) {
+	for _, f := range frames {
+		if !strings.HasPrefix(f.Function, "main.") {
+			continue // skip the runtime's frames
+		}
+		fmt.Printf("     %-28s %s:%d\n", short(f.Function), filepath.Base(f.File), f.Line)
+	}
+}
+
+// short drops the package path from a function name
+func short(function string) string {
+	return function[strings.LastIndex(function, "/")+1:]
+}
+
+// indent indents every line, and shortens the file paths
+func indent(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, l := range lines {
+		if strings.HasPrefix(l, "\t") {
+			l = "\t" + filepath.Base(l)
+		}
+		lines[i] = "     " + l
+	}
+	return strings.Join(lines, "\n")
+}
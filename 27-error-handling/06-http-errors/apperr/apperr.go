@@ -0,0 +1,30 @@
+// Package apperr holds the errors of the example's domain. It knows
+// nothing about HTTP: the same errors could be shown by a CLI or sent
+// over gRPC.
+package apperr
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotFound is returned when a requested record does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrUnauthorized is returned when the caller is not logged in.
+	ErrUnauthorized = errors.New("unauthorized")
+)
+
+// ValidationError reports an invalid input field, as in 03-custom-errors.
+type ValidationError struct {
+	Field   string
+	Value   any
+	Message string
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed for field %q (value: %v): %s",
+		e.Field, e.Value, e.Message)
+}
@@ -0,0 +1,85 @@
+// Package httperr turns domain errors into HTTP responses.
+//
+// Handlers return errors instead of writing error responses
+// themselves; one place decides the status code and the body, so every
+// endpoint answers errors the same way:
+//
+//	mux.Handle("GET /users/{id}", httperr.Handler(getUser))
+//
+//	func getUser(w http.ResponseWriter, r *http.Request) error {
+//		user, err := store.Find(r.PathValue("id"))
+//		if err != nil {
+//			return fmt.Errorf("get user: %w", err) // 404 if ErrNotFound
+//		}
+//		...
+//	}
+package httperr
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+)
+
+// Body is the JSON body of every error response.
+type Body struct {
+	Status  int    `json:"status"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	Field   string `json:"field,omitempty"`
+}
+
+// FromError returns the response for err. The error may be wrapped any
+// number of times; errors.Is and errors.As look through the chain.
+//
+// Errors it does not recognize become a 500 whose message says nothing
+// about the cause: internal details stay in the server's logs.
+func FromError(err error) Body {
+	var verr *apperr.ValidationError
+
+	switch {
+	case errors.As(err, &verr):
+		return Body{Status: http.StatusBadRequest, Code: "invalid_input", Message: verr.Message, Field: verr.Field}
+	case errors.Is(err, apperr.ErrNotFound):
+		return Body{Status: http.StatusNotFound, Code: "not_found", Message: "resource not found"}
+	case errors.Is(err, apperr.ErrUnauthorized):
+		return Body{Status: http.StatusUnauthorized, Code: "unauthorized", Message: "authentication required"}
+	default:
+		return Body{Status: http.StatusInternalServerError, Code: "internal", Message: "internal server error"}
+	}
+}
+
+// Status returns the HTTP status code for err.
+func Status(err error) int {
+	return FromError(err).Status
+}
+
+// Write writes the response for err as JSON. Server errors are logged
+// with their full message, which the client never sees.
+func Write(w http.ResponseWriter, err error) {
+	body := FromError(err)
+	if body.Status >= 500 {
+		log.Printf("httperr: %v", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(body.Status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// HandlerFunc is an http.HandlerFunc that can fail.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// Handler adapts h to an http.Handler that writes h's error, if any,
+// with Write. h must not have written a response when it fails.
+func Handler(h HandlerFunc) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := h(w, r); err != nil {
+			Write(w, err)
+		}
+	})
+}
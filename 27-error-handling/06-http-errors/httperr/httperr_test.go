@@ -0,0 +1,112 @@
+package httperr_test
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/httperr"
+)
+
+func TestMapping(t *testing.T) {
+	invalid := &apperr.ValidationError{Field: "age", Value: 15, Message: "must be at least 18"}
+
+	tests := []struct {
+		name   string
+		err    error
+		status int
+		code   string
+	}{
+		{"validation", invalid, http.StatusBadRequest, "invalid_input"},
+		{"wrapped validation", fmt.Errorf("create user: %w", invalid), http.StatusBadRequest, "invalid_input"},
+		{"not found", apperr.ErrNotFound, http.StatusNotFound, "not_found"},
+		{"wrapped twice", fmt.Errorf("handler: %w", fmt.Errorf("store: %w", apperr.ErrNotFound)), http.StatusNotFound, "not_found"},
+		{"unauthorized", apperr.ErrUnauthorized, http.StatusUnauthorized, "unauthorized"},
+		{"joined", errors.Join(errors.New("audit failed"), apperr.ErrUnauthorized), http.StatusUnauthorized, "unauthorized"},
+		{"unknown", errors.New("disk full"), http.StatusInternalServerError, "internal"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := httperr.FromError(tt.err)
+			if body.Status != tt.status || body.Code != tt.code {
+				t.Errorf("want %d %s; got %d %s", tt.status, tt.code, body.Status, body.Code)
+			}
+			if got := httperr.Status(tt.err); got != tt.status {
+				t.Errorf("Status: want %d; got %d", tt.status, got)
+			}
+		})
+	}
+}
+
+// serve runs h through Handler and returns the response
+func serve(t *testing.T, h httperr.HandlerFunc) (*http.Response, httperr.Body) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	httperr.Handler(h).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	resp := rec.Result()
+
+	var body httperr.Body
+	if resp.Header.Get("Content-Type") == "application/json" {
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			t.Fatalf("decoding the body: %v", err)
+		}
+	}
+	return resp, body
+}
+
+func TestHandlerWritesJSON(t *testing.T) {
+	resp, body := serve(t, func(w http.ResponseWriter, r *http.Request) error {
+		return fmt.Errorf("create user: %w", &apperr.ValidationError{Field: "name", Message: "name cannot be empty"})
+	})
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("want 400; got %d", resp.StatusCode)
+	}
+	want := httperr.Body{Status: 400, Code: "invalid_input", Message: "name cannot be empty", Field: "name"}
+	if body != want {
+		t.Errorf("want %+v; got %+v", want, body)
+	}
+}
+
+func TestHandlerHidesInternalErrors(t *testing.T) {
+	var logs strings.Builder
+	prev := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(prev) })
+
+	resp, body := serve(t, func(w http.ResponseWriter, r *http.Request) error {
+		return errors.New("connect to 10.0.0.7:5432: connection refused")
+	})
+
+	if resp.StatusCode != http.StatusInternalServerError {
+		t.Errorf("want 500; got %d", resp.StatusCode)
+	}
+	if strings.Contains(body.Message, "10.0.0.7") {
+		t.Errorf("internal details leaked to the client: %q", body.Message)
+	}
+	if !strings.Contains(logs.String(), "10.0.0.7") {
+		t.Errorf("want the full error logged; got %q", logs.String())
+	}
+}
+
+func TestHandlerSuccessIsUntouched(t *testing.T) {
+	resp, _ := serve(t, func(w http.ResponseWriter, r *http.Request) error {
+		w.WriteHeader(http.StatusCreated)
+		io.WriteString(w, "ok")
+		return nil
+	})
+
+	got, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusCreated || string(got) != "ok" {
+		t.Errorf("want 201 ok; got %d %q", resp.StatusCode, got)
+	}
+}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/httperr"
+)
+
+// User is a registered user
+type User struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+	Age  int    `json:"age"`
+}
+
+// Store keeps users in memory
+type Store struct {
+	mu    sync.Mutex
+	users map[string]User
+	down  bool // simulates a broken database
+}
+
+// Find returns the user with the given id
+func (s *Store) Find(id string) (User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.down {
+		return User{}, errors.New("dial tcp 10.0.0.7:5432: connection refused")
+	}
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, fmt.Errorf("user %q: %w", id, apperr.ErrNotFound)
+	}
+	return u, nil
+}
+
+// Add validates and stores a user
+func (s *Store) Add(u User) error {
+	if err := validateUser(u); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.users[u.ID] = u
+	return nil
+}
+
+// validateUser is validateUser from 03-custom-errors
+func validateUser(u User) error {
+	if u.Name == "" {
+		return &apperr.ValidationError{Field: "name", Value: u.Name, Message: "name cannot be empty"}
+	}
+	if u.Age < 18 {
+		return &apperr.ValidationError{Field: "age", Value: u.Age, Message: "must be at least 18 years old"}
+	}
+	return nil
+}
+
+// Server has the handlers. They return errors; httperr.Handler turns
+// them into responses, so none of them picks a status code for a failure.
+type Server struct {
+	store *Store
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /users/{id}", httperr.Handler(s.getUser))
+	mux.Handle("POST /users", httperr.Handler(s.createUser))
+	return mux
+}
+
+func (s *Server) getUser(w http.ResponseWriter, r *http.Request) error {
+	if r.Header.Get("Authorization") != "Bearer gopher" {
+		return apperr.ErrUnauthorized
+	}
+
+	u, err := s.store.Find(r.PathValue("id"))
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	return json.NewEncoder(w).Encode(u)
+}
+
+func (s *Server) createUser(w http.ResponseWriter, r *http.Request) error {
+	var u User
+	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
+		return &apperr.ValidationError{Field: "body", Message: "invalid JSON"}
+	}
+	if err := s.store.Add(u); err != nil {
+		return fmt.Errorf("create user: %w", err)
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	return json.NewEncoder(w).Encode(u)
+}
+
+func main() {
+	fmt.Println("Mapping Errors to HTTP Status Codes")
+	fmt.Println("===================================")
+	fmt.Println()
+
+	// Server logs go to stdout, so they show up between the responses
+	log.SetOutput(os.Stdout)
+	log.SetFlags(0)
+	log.SetPrefix("   server log: ")
+
+	store := &Store{users: map[string]User{"1": {ID: "1", Name: "Alice", Age: 30}}}
+	srv := httptest.NewServer((&Server{store: store}).routes())
+	defer srv.Close()
+
+	gopher := client{base: srv.URL, token: "gopher"}
+	anonymous := client{base: srv.URL}
+
+	// Example 1: No error
+	fmt.Println("1. Success:")
+	gopher.do("GET", "/users/1", "")
+	fmt.Println()
+
+	// Example 2: ValidationError -> 400, with the field
+	fmt.Println("2. A *ValidationError, wrapped by the handler:")
+	gopher.do("POST", "/users", `{"id":"2","name":"Bob","age":15}`)
+	gopher.do("POST", "/users", `{not json`)
+	fmt.Println()
+
+	// Example 3: Sentinels -> 404 and 401
+	fmt.Println("3. Sentinel errors:")
+	gopher.do("GET", "/users/42", "")
+	anonymous.do("GET", "/users/1", "")
+	fmt.Println()
+
+	// Example 4: Anything else -> 500, details only in the log
+	fmt.Println("4. An unexpected error:")
+	store.mu.Lock()
+	store.down = true
+	store.mu.Unlock()
+	gopher.do("GET", "/users/1", "")
+}
+
+// client sends requests with an optional bearer token
+type client struct {
+	base  string
+	token string
+}
+
+// do sends a request and prints the response
+func (c client) do(method, path, data string) {
+	var body io.Reader
+	if data != "" {
+		body = strings.NewReader(data)
+	}
+
+	req, err := http.NewRequest(method, c.base+path, body)
+	if err != nil {
+		fmt.Println("   client:", err)
+		return
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("   client:", err)
+		return
+	}
+	defer resp.Body.Close()
+
+	got, _ := io.ReadAll(resp.Body)
+	fmt.Printf("   %-4s %-9s -> %d %s\n", method, path, resp.StatusCode, strings.TrimSpace(string(got)))
+}
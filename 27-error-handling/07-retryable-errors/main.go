@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+// Causes at the bottom of the chains below
+var (
+	errConnRefused = errors.New("connection refused")
+	errTimeout     = errors.New("i/o timeout")
+	errHostUnknown = errors.New("no such host")
+	errDeadlock    = errors.New("deadlock detected")
+	errNotFound    = errors.New("record not found")
+	errSyntax      = errors.New("syntax error")
+)
+
+// NetworkError is the NetworkError from 03-custom-errors, which now
+// also says whether it is temporary
+type NetworkError struct {
+	Host string
+	Port int
+	Err  error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error connecting to %s:%d: %v", e.Host, e.Port, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// Temporary follows the convention of net.Error: a refused connection or
+// a timeout may work next time, an unknown host will not
+func (e *NetworkError) Temporary() bool {
+	return errors.Is(e.Err, errConnRefused) || errors.Is(e.Err, errTimeout)
+}
+
+// DatabaseError is the DatabaseError from 03-custom-errors, which now
+// also says whether it is worth retrying
+type DatabaseError struct {
+	Operation string
+	Table     string
+	Err       error
+}
+
+func (e *DatabaseError) Error() string {
+	return fmt.Sprintf("database error during %s on table %q: %v", e.Operation, e.Table, e.Err)
+}
+
+func (e *DatabaseError) Unwrap() error { return e.Err }
+
+// Retryable is true for failures caused by timing, such as a deadlock
+// with another transaction; a missing record or a bad query will fail
+// the same way every time
+func (e *DatabaseError) Retryable() bool {
+	if errors.Is(e.Err, errDeadlock) {
+		return true
+	}
+	// The cause may know better, e.g. a NetworkError to the database
+	return retry.IsRetryable(e.Err)
+}
+
+func main() {
+	fmt.Println("Retryable vs Permanent Errors")
+	fmt.Println("=============================")
+	fmt.Println()
+
+	// Example 1: Each error classifies itself
+	fmt.Println("1. Classifying errors:")
+	example1Classify()
+	fmt.Println()
+
+	// Example 2: Wrapping does not hide the classification
+	fmt.Println("2. Wrapped errors keep their classification:")
+	example2Wrapped()
+	fmt.Println()
+
+	// Example 3: Only transient failures are retried
+	fmt.Println("3. retry.WithRetryableOnly:")
+	example3Retry()
+}
+
+// example1Classify asks each error whether it is retryable
+func example1Classify() {
+	errs := []error{
+		&NetworkError{Host: "api.example.com", Port: 443, Err: errConnRefused},
+		&NetworkError{Host: "api.example.com", Port: 443, Err: errTimeout},
+		&NetworkError{Host: "api.exmaple.com", Port: 443, Err: errHostUnknown},
+		&DatabaseError{Operation: "UPDATE", Table: "orders", Err: errDeadlock},
+		&DatabaseError{Operation: "SELECT", Table: "users", Err: errNotFound},
+		&DatabaseError{Operation: "SELECT", Table: "users", Err: errSyntax},
+		errors.New("something else"),
+	}
+	for _, err := range errs {
+		fmt.Printf("   %-9s %v\n", verdict(err), err)
+	}
+}
+
+// example2Wrapped classifies errors wrapped in several ways
+func example2Wrapped() {
+	conn := &NetworkError{Host: "db.internal", Port: 5432, Err: errTimeout}
+
+	errs := []error{
+		// Wrapped with %w on the way up
+		fmt.Errorf("load orders: %w", &DatabaseError{Operation: "SELECT", Table: "orders", Err: errDeadlock}),
+		// A DatabaseError caused by a NetworkError: it asks its cause
+		&DatabaseError{Operation: "SELECT", Table: "orders", Err: conn},
+		// The outermost error that has an opinion decides
+		fmt.Errorf("checkout: %w", retry.Permanent(conn)),
+		// In a join, the first error that has an opinion decides
+		errors.Join(errors.New("audit log failed"), conn),
+	}
+	for _, err := range errs {
+		// A joined error has one line per error
+		fmt.Printf("   %-9s %s\n", verdict(err), strings.ReplaceAll(err.Error(), "\n", "; "))
+	}
+}
+
+// example3Retry retries a flaky operation until it hits a permanent error
+func example3Retry() {
+	failures := []error{
+		&NetworkError{Host: "db.internal", Port: 5432, Err: errConnRefused},
+		&DatabaseError{Operation: "UPDATE", Table: "orders", Err: errDeadlock},
+		&DatabaseError{Operation: "UPDATE", Table: "orders", Err: errNotFound},
+		nil, // never reached
+	}
+
+	attempt := 0
+	err := retry.Do(context.Background(), func(context.Context) error {
+		err := failures[attempt]
+		attempt++
+		return err
+	},
+		retry.WithMaxAttempts(10),
+		retry.WithBackoff(10*time.Millisecond, 100*time.Millisecond),
+		retry.WithRetryableOnly(),
+		retry.WithOnRetry(func(n int, err error, _ time.Duration) {
+			fmt.Printf("   attempt %d failed, retrying: %v\n", n, err)
+		}),
+	)
+
+	fmt.Printf("   attempt %d failed, stopping:  %v\n", attempt, err)
+	fmt.Printf("   %d attempts, not 10: retrying a missing record cannot help\n", attempt)
+}
+
+// verdict names the classification of err
+func verdict(err error) string {
+	if retry.IsRetryable(err) {
+		return "RETRY"
+	}
+	return "PERMANENT"
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+func TestClassification(t *testing.T) {
+	refused := &NetworkError{Host: "db", Port: 5432, Err: errConnRefused}
+	unknownHost := &NetworkError{Host: "db", Port: 5432, Err: errHostUnknown}
+
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"refused connection", refused, true},
+		{"unknown host", unknownHost, false},
+		{"deadlock", &DatabaseError{Err: errDeadlock}, true},
+		{"not found", &DatabaseError{Err: errNotFound}, false},
+
+		{"wrapped deadlock", fmt.Errorf("load: %w", &DatabaseError{Err: errDeadlock}), true},
+		{"wrapped not found", fmt.Errorf("a: %w", fmt.Errorf("b: %w", &DatabaseError{Err: errNotFound})), false},
+		{"database over a retryable network error", &DatabaseError{Err: fmt.Errorf("conn: %w", refused)}, true},
+		{"database over a permanent network error", &DatabaseError{Err: unknownHost}, false},
+		{"marked permanent", fmt.Errorf("checkout: %w", retry.Permanent(refused)), false},
+		{"joined", errors.Join(errors.New("audit"), fmt.Errorf("x: %w", refused)), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retry.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("want %v; got %v", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Causes of the failing calls below
+var (
+	errOrdersDown = errors.New("service unavailable")
+	errBadReply   = errors.New("malformed response")
+)
+
+// service is a backend that answers after delay with err
+type service struct {
+	name  string
+	delay time.Duration
+	err   error
+}
+
+// services are called by every example: two of them fail
+var services = []service{
+	{"users", 20 * time.Millisecond, nil},
+	{"orders", 10 * time.Millisecond, errOrdersDown},
+	{"billing", 30 * time.Millisecond, errBadReply},
+	{"search", 50 * time.Millisecond, nil},
+}
+
+// call waits for s to answer, or for ctx to be done
+func call(ctx context.Context, s service) error {
+	select {
+	case <-time.After(s.delay):
+		if s.err != nil {
+			return fmt.Errorf("%s: %w", s.name, s.err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("%s: %w", s.name, ctx.Err())
+	}
+}
+
+func main() {
+	fmt.Println("Error Handling in Concurrent Code")
+	fmt.Println("=================================")
+	fmt.Println()
+
+	// Example 1: Collect every error through a channel
+	fmt.Println("1. Collecting errors with a channel:")
+	example1Channel()
+	fmt.Println()
+
+	// Example 2: The first error cancels the rest
+	fmt.Println("2. First error wins with errgroup:")
+	example2Errgroup()
+	fmt.Println()
+
+	// Example 3: Join every error into one
+	fmt.Println("3. Joining all errors with errors.Join:")
+	example3Join()
+	fmt.Println()
+
+	// Example 4: Cancellation errors are noise
+	fmt.Println("4. Filtering context errors:")
+	example4Filter()
+}
+
+// withChannel calls every service and returns the errors in the order
+// they arrived, like the WaitGroup example in 29-concurrency/05-waitgroups
+func withChannel(ctx context.Context, services []service) []error {
+	// Buffered, so no goroutine blocks if nobody reads yet
+	errc := make(chan error, len(services))
+
+	var wg sync.WaitGroup
+	for _, s := range services {
+		wg.Go(func() {
+			if err := call(ctx, s); err != nil {
+				errc <- err
+			}
+		})
+	}
+
+	// Close after the last send, so the range below ends
+	go func() {
+		wg.Wait()
+		close(errc)
+	}()
+
+	var errs []error
+	for err := range errc {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+func example1Channel() {
+	start := time.Now()
+	errs := withChannel(context.Background(), services)
+
+	fmt.Printf("   %d errors after %dms:\n", len(errs), ms(time.Since(start)))
+	for _, err := range errs {
+		fmt.Printf("   - %v\n", err)
+	}
+	fmt.Println("   Every call ran to the end; the order is arrival order")
+}
+
+// withErrgroup calls every service and returns the first error; the
+// other calls are cancelled. seen holds what each call returned.
+func withErrgroup(ctx context.Context, services []service) (seen []error, err error) {
+	g, ctx := errgroup.WithContext(ctx)
+
+	// One slot per goroutine: no mutex needed
+	seen = make([]error, len(services))
+	for i, s := range services {
+		g.Go(func() error {
+			seen[i] = call(ctx, s)
+			return seen[i]
+		})
+	}
+
+	// Wait returns the first non-nil error, after all calls returned
+	err = g.Wait()
+	return seen, err
+}
+
+func example2Errgroup() {
+	start := time.Now()
+	seen, err := withErrgroup(context.Background(), services)
+
+	fmt.Printf("   Wait returned after %dms: %v\n", ms(time.Since(start)), err)
+	for i, err := range seen {
+		fmt.Printf("   %-7s saw: %v\n", services[i].name, err)
+	}
+	fmt.Printf("   errors.Is(err, context.Canceled): %v\n", errors.Is(err, context.Canceled))
+}
+
+// withJoin calls every service and joins all the errors, in the order of
+// services; it returns nil if every call succeeded
+func withJoin(ctx context.Context, services []service) error {
+	errs := make([]error, len(services))
+
+	var wg sync.WaitGroup
+	for i, s := range services {
+		wg.Go(func() {
+			errs[i] = call(ctx, s)
+		})
+	}
+	wg.Wait()
+
+	// errors.Join skips the nils
+	return errors.Join(errs...)
+}
+
+func example3Join() {
+	err := withJoin(context.Background(), services)
+
+	fmt.Printf("   %s\n", strings.ReplaceAll(err.Error(), "\n", "\n   "))
+	fmt.Printf("   errors.Is(err, errOrdersDown): %v\n", errors.Is(err, errOrdersDown))
+	fmt.Printf("   errors.Is(err, errBadReply):   %v\n", errors.Is(err, errBadReply))
+
+	// A joined error unwraps to all of its errors
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		fmt.Printf("   %d errors joined\n", len(joined.Unwrap()))
+	}
+
+	ok := slices.DeleteFunc(slices.Clone(services), func(s service) bool { return s.err != nil })
+	fmt.Printf("   Only successes: %v\n", withJoin(context.Background(), ok))
+}
+
+// collect joins errs without the context errors they contain: those
+// only say a call was stopped, not why. If ctx itself is done, its
+// error is reported once instead.
+func collect(ctx context.Context, errs []error) error {
+	var real []error
+	for _, err := range errs {
+		if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			continue
+		}
+		real = append(real, err)
+	}
+
+	if ctx.Err() != nil {
+		real = append(real, fmt.Errorf("stopped early: %w", ctx.Err()))
+	}
+
+	// Only cancellations and no reason for them: don't hide them
+	if len(real) == 0 {
+		return errors.Join(errs...)
+	}
+	return errors.Join(real...)
+}
+
+func example4Filter() {
+	// A deadline stops billing and search before they answer
+	ctx, cancel := context.WithTimeout(context.Background(), 25*time.Millisecond)
+	defer cancel()
+
+	errs := make([]error, len(services))
+	var wg sync.WaitGroup
+	for i, s := range services {
+		wg.Go(func() { errs[i] = call(ctx, s) })
+	}
+	wg.Wait()
+
+	fmt.Println("   Joined as they are:")
+	fmt.Printf("   %s\n", strings.ReplaceAll(errors.Join(errs...).Error(), "\n", "\n   "))
+
+	fmt.Println("   Filtered:")
+	fmt.Printf("   %s\n", strings.ReplaceAll(collect(ctx, errs).Error(), "\n", "\n   "))
+}
+
+// ms rounds d to 10ms, so the output stays the same from run to run
+func ms(d time.Duration) int64 {
+	return d.Round(10 * time.Millisecond).Milliseconds()
+}
@@ -0,0 +1,102 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+func TestWithChannel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+		errs := withChannel(t.Context(), services)
+
+		// Every call runs, so it takes as long as the slowest one
+		if d := time.Since(start); d != 50*time.Millisecond {
+			t.Errorf("want 50ms; took %v", d)
+		}
+		if len(errs) != 2 || !errors.Is(errs[0], errOrdersDown) || !errors.Is(errs[1], errBadReply) {
+			t.Errorf("want orders then billing; got %v", errs)
+		}
+	})
+}
+
+func TestWithErrgroup(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+		seen, err := withErrgroup(t.Context(), services)
+
+		// The first failure stops the others
+		if d := time.Since(start); d != 10*time.Millisecond {
+			t.Errorf("want 10ms; took %v", d)
+		}
+		if !errors.Is(err, errOrdersDown) {
+			t.Errorf("want the orders error; got %v", err)
+		}
+		for i, err := range seen {
+			if services[i].name != "orders" && !errors.Is(err, context.Canceled) {
+				t.Errorf("%s: want context.Canceled; got %v", services[i].name, err)
+			}
+		}
+	})
+}
+
+func TestWithJoin(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		err := withJoin(t.Context(), services)
+
+		if !errors.Is(err, errOrdersDown) || !errors.Is(err, errBadReply) {
+			t.Errorf("want both errors; got %v", err)
+		}
+		if want := "orders: service unavailable\nbilling: malformed response"; err.Error() != want {
+			t.Errorf("want errors in the order of services:\n%s\ngot:\n%v", want, err)
+		}
+		if err := withJoin(t.Context(), services[:1]); err != nil {
+			t.Errorf("no failures: want nil; got %v", err)
+		}
+	})
+}
+
+func TestCollect(t *testing.T) {
+	real := fmt.Errorf("orders: %w", errOrdersDown)
+	stopped := fmt.Errorf("search: %w", context.Canceled)
+
+	done, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		errs []error
+		want []error // errors.Is must match each; nil means want nil
+		skip []error // errors.Is must not match
+	}{
+		{"no errors", context.Background(), []error{nil, nil}, nil, nil},
+		{"sibling cancelled", context.Background(), []error{real, stopped, nil}, []error{errOrdersDown}, []error{context.Canceled}},
+		{"caller cancelled", done, []error{real, stopped}, []error{errOrdersDown, context.Canceled}, nil},
+		{"only cancellations", context.Background(), []error{stopped}, []error{context.Canceled}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := collect(tt.ctx, tt.errs)
+
+			if tt.want == nil && err != nil {
+				t.Fatalf("want nil; got %v", err)
+			}
+			for _, w := range tt.want {
+				if !errors.Is(err, w) {
+					t.Errorf("want %v in %q", w, err)
+				}
+			}
+			for _, s := range tt.skip {
+				if errors.Is(err, s) {
+					t.Errorf("want no %v in %q", s, err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Style 1: a sentinel. Callers ask "is it this error?" with errors.Is.
+var ErrNotFound = errors.New("not found")
+
+// Style 2: a type. Callers ask "is it this kind of error?" with
+// errors.As, and then read its fields.
+type NotFoundError struct {
+	Kind string
+	ID   int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("%s %d not found", e.Kind, e.ID)
+}
+
+// Is lets errors.Is(err, ErrNotFound) match the type too, so callers
+// who only need a yes or no can keep using the sentinel
+func (e *NotFoundError) Is(target error) bool {
+	return target == ErrNotFound
+}
+
+// findSentinel fails the way an API with a sentinel does
+func findSentinel(id int) error {
+	return fmt.Errorf("find user %d: %w", id, ErrNotFound)
+}
+
+// findTyped fails the way an API with an error type does
+func findTyped(id int) error {
+	return fmt.Errorf("find: %w", &NotFoundError{Kind: "user", ID: id})
+}
+
+// findReworded is findSentinel after someone improved the message
+func findReworded(id int) error {
+	return fmt.Errorf("find user %d: no such user", id)
+}
+
+// isNotFoundString is the anti-pattern: it depends on the wording
+func isNotFoundString(err error) bool {
+	return err != nil && strings.Contains(err.Error(), "not found")
+}
+
+func main() {
+	fmt.Println("Sentinel vs Typed Errors vs String Matching")
+	fmt.Println("===========================================")
+	fmt.Println()
+
+	// Example 1: Each style, checked the way it is meant to be
+	fmt.Println("1. Checking each style:")
+	example1Checks()
+	fmt.Println()
+
+	// Example 2: Only a type carries data
+	fmt.Println("2. Reading details from a typed error:")
+	example2Details()
+	fmt.Println()
+
+	// Example 3: String matching breaks in both directions
+	fmt.Println("3. Why string matching is fragile:")
+	example3Strings()
+	fmt.Println()
+
+	fmt.Println("Run `go test -bench . -benchmem` to compare the costs")
+}
+
+func example1Checks() {
+	sentinel := findSentinel(42)
+	typed := findTyped(42)
+
+	var nf *NotFoundError
+	fmt.Printf("   %-26q errors.Is ErrNotFound:   %v\n", sentinel, errors.Is(sentinel, ErrNotFound))
+	fmt.Printf("   %-26q errors.As *NotFoundError: %v\n", typed, errors.As(typed, &nf))
+
+	// Thanks to NotFoundError.Is, the sentinel matches the type too
+	fmt.Printf("   %-26q errors.Is ErrNotFound:   %v\n", typed, errors.Is(typed, ErrNotFound))
+}
+
+func example2Details() {
+	err := findTyped(7)
+
+	var nf *NotFoundError
+	if errors.As(err, &nf) {
+		fmt.Printf("   kind=%s id=%d\n", nf.Kind, nf.ID)
+	}
+
+	// A sentinel says what happened, but not to what
+	fmt.Printf("   %q has nothing to extract\n", findSentinel(7))
+}
+
+func example3Strings() {
+	// False negative: the message changed, the meaning did not
+	err := findReworded(42)
+	fmt.Printf("   %q: string match %v, but the user is missing\n", err, isNotFoundString(err))
+
+	// False positive: a different failure uses the same words
+	err = errors.New("load avatar: image not found in cache; fetched it")
+	fmt.Printf("   %q: string match %v, but the user exists\n", err, isNotFoundString(err))
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestChecks(t *testing.T) {
+	var nf *NotFoundError
+
+	if !errors.Is(findSentinel(1), ErrNotFound) {
+		t.Error("sentinel: errors.Is should match")
+	}
+	if !errors.As(findTyped(1), &nf) || nf.ID != 1 {
+		t.Errorf("typed: errors.As should match with ID 1; got %v", nf)
+	}
+	if !errors.Is(findTyped(1), ErrNotFound) {
+		t.Error("typed: errors.Is should match the sentinel through Is")
+	}
+	if errors.Is(errors.New("not found"), ErrNotFound) {
+		t.Error("an error with the same text is not the sentinel")
+	}
+	if isNotFoundString(findReworded(1)) {
+		t.Error("string matching should miss the reworded error")
+	}
+}
+
+// wrap wraps err depth times, like a call stack that adds context at
+// every level
+func wrap(err error, depth int) error {
+	for i := range depth {
+		err = fmt.Errorf("level %d: %w", i, err)
+	}
+	return err
+}
+
+// sink keeps the compiler from removing the work being measured
+var sink bool
+
+var depths = []int{0, 1, 5}
+
+func BenchmarkIs(b *testing.B) {
+	for _, depth := range depths {
+		err := wrap(ErrNotFound, depth)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			for b.Loop() {
+				sink = errors.Is(err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func BenchmarkIsMiss(b *testing.B) {
+	// The common case: the error is something else, so every link is checked
+	for _, depth := range depths {
+		err := wrap(errors.New("timeout"), depth)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			for b.Loop() {
+				sink = errors.Is(err, ErrNotFound)
+			}
+		})
+	}
+}
+
+func BenchmarkAs(b *testing.B) {
+	for _, depth := range depths {
+		err := wrap(&NotFoundError{Kind: "user", ID: 1}, depth)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			for b.Loop() {
+				var nf *NotFoundError
+				sink = errors.As(err, &nf)
+			}
+		})
+	}
+}
+
+func BenchmarkStringMatch(b *testing.B) {
+	// Error() builds the whole message every time
+	for _, depth := range depths {
+		err := wrap(&NotFoundError{Kind: "user", ID: 1}, depth)
+		b.Run(fmt.Sprintf("depth=%d", depth), func(b *testing.B) {
+			for b.Loop() {
+				sink = isNotFoundString(err)
+			}
+		})
+	}
+}
+
+// errSink keeps the created errors alive
+var errSink error
+
+func BenchmarkCreate(b *testing.B) {
+	b.Run("sentinel", func(b *testing.B) {
+		for b.Loop() {
+			errSink = ErrNotFound
+		}
+	})
+	b.Run("typed", func(b *testing.B) {
+		for b.Loop() {
+			errSink = &NotFoundError{Kind: "user", ID: 1}
+		}
+	})
+	b.Run("wrapped", func(b *testing.B) {
+		for b.Loop() {
+			errSink = fmt.Errorf("find user %d: %w", 1, ErrNotFound)
+		}
+	})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// file is the part of *os.File that writing code needs
+type file interface {
+	io.Writer
+	Sync() error
+	Close() error
+}
+
+// createFile opens a file for writing; tests replace it to inject
+// failures that a real disk rarely produces on demand
+var createFile = func(name string) (file, error) {
+	return os.Create(name)
+}
+
+// Failures that a full or network disk may only report when the
+// buffered data is finally flushed, at Sync or Close
+var (
+	errDiskFull = errors.New("no space left on device")
+	errIO       = errors.New("input/output error")
+	errWrite    = errors.New("write failed")
+)
+
+// faultyFile accepts every write, but fails to flush them
+type faultyFile struct {
+	syncErr, closeErr error
+}
+
+func (f *faultyFile) Write(p []byte) (int, error) { return len(p), nil }
+func (f *faultyFile) Sync() error                 { return f.syncErr }
+func (f *faultyFile) Close() error                { return f.closeErr }
+
+// failingWriter fails every write, then fails to close as well
+type failingWriter struct{ faultyFile }
+
+func (*failingWriter) Write([]byte) (int, error) {
+	return 0, errWrite
+}
+
+// saveBroken is the classic bug: the error from Close is thrown away
+func saveBroken(f file, data []byte) error {
+	defer f.Close()
+
+	_, err := f.Write(data)
+	return err
+}
+
+// saveNamed reports the error from Close through the named result, but
+// only if nothing failed before it
+func saveNamed(f file, data []byte) (err error) {
+	defer func() {
+		if cerr := f.Close(); err == nil {
+			err = cerr
+		}
+	}()
+
+	_, err = f.Write(data)
+	return err
+}
+
+// saveJoined reports every error: the first failure and Close's too
+func saveJoined(f file, data []byte) (err error) {
+	defer func() { err = errors.Join(err, f.Close()) }()
+
+	_, err = f.Write(data)
+	return err
+}
+
+// copyFile copies src to dst. It returns nil only if the data is on the
+// disk: it checks the write, Sync, and Close. On failure, dst is removed.
+func copyFile(dst, src string) (err error) {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	// Nothing was written to in, so its Close error can't lose data
+	defer in.Close()
+
+	out, err := createFile(dst)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Join(err, out.Close())
+		if err != nil {
+			os.Remove(dst) // don't leave half a copy behind
+			err = fmt.Errorf("copy %s to %s: %w", src, dst, err)
+		}
+	}()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	// Flush to the disk, so a failure is reported here and not lost
+	return out.Sync()
+}
+
+func main() {
+	fmt.Println("Handling Errors from Deferred Close")
+	fmt.Println("===================================")
+	fmt.Println()
+
+	// Example 1: defer f.Close() loses the error
+	fmt.Println("1. defer f.Close() swallows the error:")
+	example1Broken()
+	fmt.Println()
+
+	// Example 2: A named result can carry it out
+	fmt.Println("2. Named result, first error wins:")
+	example2Named()
+	fmt.Println()
+
+	// Example 3: errors.Join keeps every error
+	fmt.Println("3. errors.Join(err, f.Close()):")
+	example3Joined()
+	fmt.Println()
+
+	// Example 4: Write, Sync, then Close
+	fmt.Println("4. A file copy that reports every failure:")
+	example4Copy()
+}
+
+func example1Broken() {
+	f := &faultyFile{closeErr: errDiskFull}
+	err := saveBroken(f, []byte("report"))
+
+	fmt.Printf("   saveBroken: %v, yet the data was never flushed\n", err)
+}
+
+func example2Named() {
+	f := &faultyFile{closeErr: errDiskFull}
+	fmt.Printf("   close fails:          %v\n", saveNamed(f, []byte("report")))
+
+	// A failed write wins; the Close error is dropped
+	w := &failingWriter{faultyFile{closeErr: errDiskFull}}
+	fmt.Printf("   write and close fail: %v\n", saveNamed(w, []byte("report")))
+}
+
+func example3Joined() {
+	w := &failingWriter{faultyFile{closeErr: errDiskFull}}
+	err := saveJoined(w, []byte("report"))
+
+	fmt.Printf("   write and close fail: %q\n", err)
+	fmt.Printf("   errors.Is(err, errDiskFull): %v\n", errors.Is(err, errDiskFull))
+
+	f := &faultyFile{}
+	fmt.Printf("   nothing fails:        %v\n", saveJoined(f, []byte("report")))
+}
+
+func example4Copy() {
+	dir, err := os.MkdirTemp("", "deferred-close")
+	if err != nil {
+		fmt.Printf("   %v\n", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src.txt")
+	dst := filepath.Join(dir, "dst.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		fmt.Printf("   %v\n", err)
+		return
+	}
+
+	fmt.Printf("   real disk: %v\n", copyFile(dst, src))
+
+	// Pretend the disk fills up while the data is flushed
+	prev := createFile
+	defer func() { createFile = prev }()
+	createFile = func(string) (file, error) {
+		return &faultyFile{syncErr: errDiskFull, closeErr: errIO}, nil
+	}
+
+	err = copyFile(dst, src)
+	msg := strings.ReplaceAll(err.Error(), dir+string(filepath.Separator), "")
+	fmt.Printf("   full disk: %q\n", msg)
+
+	_, statErr := os.Stat(dst)
+	fmt.Printf("   dst removed: %v\n", errors.Is(statErr, fs.ErrNotExist))
+}
@@ -0,0 +1,120 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSave(t *testing.T) {
+	tests := []struct {
+		name string
+		save func(file, []byte) error
+		file file
+		want []error // errors.Is must match each; nil means want nil
+	}{
+		{"broken loses close error", saveBroken, &faultyFile{closeErr: errDiskFull}, nil},
+		{"named reports close error", saveNamed, &faultyFile{closeErr: errDiskFull}, []error{errDiskFull}},
+		{"named keeps the first error", saveNamed, &failingWriter{faultyFile{closeErr: errDiskFull}}, []error{errWrite}},
+		{"joined reports close error", saveJoined, &faultyFile{closeErr: errDiskFull}, []error{errDiskFull}},
+		{"joined keeps both errors", saveJoined, &failingWriter{faultyFile{closeErr: errDiskFull}}, []error{errWrite, errDiskFull}},
+		{"joined without errors", saveJoined, &faultyFile{}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.save(tt.file, []byte("data"))
+
+			if tt.want == nil && err != nil {
+				t.Fatalf("want nil; got %v", err)
+			}
+			for _, w := range tt.want {
+				if !errors.Is(err, w) {
+					t.Errorf("want %v in %q", w, err)
+				}
+			}
+		})
+	}
+}
+
+// setup writes a source file and returns the source and destination paths
+func setup(t *testing.T) (src, dst string) {
+	t.Helper()
+
+	dir := t.TempDir()
+	src = filepath.Join(dir, "src.txt")
+	dst = filepath.Join(dir, "dst.txt")
+	if err := os.WriteFile(src, []byte("hello"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return src, dst
+}
+
+// fakeCreate makes copyFile write to f, and creates an empty dst on the
+// disk so the test can check that it is removed
+func fakeCreate(t *testing.T, f file) {
+	prev := createFile
+	t.Cleanup(func() { createFile = prev })
+
+	createFile = func(name string) (file, error) {
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			return nil, err
+		}
+		return f, nil
+	}
+}
+
+func TestCopyFile(t *testing.T) {
+	src, dst := setup(t)
+
+	if err := copyFile(dst, src); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := os.ReadFile(dst); string(got) != "hello" {
+		t.Errorf("want hello; got %q", got)
+	}
+}
+
+func TestCopyFileMissingSource(t *testing.T) {
+	src, dst := setup(t)
+
+	if err := copyFile(dst, src+".missing"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("want fs.ErrNotExist; got %v", err)
+	}
+}
+
+func TestCopyFileSurfacesFlushErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		file file
+		want []error
+	}{
+		{"close fails", &faultyFile{closeErr: errDiskFull}, []error{errDiskFull}},
+		{"sync fails", &faultyFile{syncErr: errDiskFull}, []error{errDiskFull}},
+		{"sync and close fail", &faultyFile{syncErr: errDiskFull, closeErr: errIO}, []error{errDiskFull, errIO}},
+		{"write and close fail", &failingWriter{faultyFile{closeErr: errIO}}, []error{errWrite, errIO}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			src, dst := setup(t)
+			fakeCreate(t, tt.file)
+
+			err := copyFile(dst, src)
+			if err == nil {
+				t.Fatal("want an error; got nil")
+			}
+			for _, w := range tt.want {
+				if !errors.Is(err, w) {
+					t.Errorf("want %v in %q", w, err)
+				}
+			}
+
+			if _, err := os.Stat(dst); !errors.Is(err, fs.ErrNotExist) {
+				t.Errorf("want dst removed; stat: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/sloghelpers"
+)
+
+// ValidationError is the ValidationError from 03-custom-errors, which
+// now also logs its fields
+type ValidationError struct {
+	Field   string
+	Value   any
+	Message string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("validation failed for field %q (value: %v): %s", e.Field, e.Value, e.Message)
+}
+
+// LogValue implements slog.LogValuer
+func (e *ValidationError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("field", e.Field),
+		slog.Any("value", e.Value),
+	)
+}
+
+// DatabaseError is the DatabaseError from 03-custom-errors
+type DatabaseError struct {
+	Operation string
+	Table     string
+	Err       error
+}
+
+func (e *DatabaseError) Error() string {
+	return fmt.Sprintf("database error during %s on table %q: %v", e.Operation, e.Table, e.Err)
+}
+
+func (e *DatabaseError) Unwrap() error { return e.Err }
+
+// LogValue logs only this error's own fields: the wrapped error logs its
+// own, and sloghelpers.Err merges them
+func (e *DatabaseError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("operation", e.Operation),
+		slog.String("table", e.Table),
+	)
+}
+
+// NetworkError is the NetworkError from 03-custom-errors
+type NetworkError struct {
+	Host    string
+	Port    int
+	Timeout time.Duration
+	Err     error
+}
+
+func (e *NetworkError) Error() string {
+	return fmt.Sprintf("network error connecting to %s:%d (timeout: %v): %v", e.Host, e.Port, e.Timeout, e.Err)
+}
+
+func (e *NetworkError) Unwrap() error { return e.Err }
+
+// LogValue implements slog.LogValuer
+func (e *NetworkError) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("host", e.Host),
+		slog.Int("port", e.Port),
+		slog.String("timeout", e.Timeout.String()),
+	)
+}
+
+// loadOrders fails the way a real call stack does: a network error,
+// inside a database error, wrapped with context on the way up
+func loadOrders() error {
+	netErr := &NetworkError{Host: "db.internal", Port: 5432, Timeout: 5 * time.Second, Err: errors.New("connection refused")}
+	dbErr := &DatabaseError{Operation: "SELECT", Table: "orders", Err: netErr}
+	return fmt.Errorf("load orders: %w", dbErr)
+}
+
+// newLogger writes JSON lines without the time, so the output stays the
+// same from run to run
+func newLogger(replace func([]string, slog.Attr) slog.Attr) *slog.Logger {
+	opts := &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			if replace != nil {
+				return replace(groups, a)
+			}
+			return a
+		},
+	}
+	return slog.New(slog.NewJSONHandler(os.Stdout, opts))
+}
+
+func main() {
+	fmt.Println("Structured Error Logging")
+	fmt.Println("========================")
+	fmt.Println()
+
+	err := loadOrders()
+
+	// Example 1: The error as one string
+	fmt.Println("1. A flattened string:")
+	newLogger(nil).Error("request failed", "error", err.Error())
+	fmt.Println()
+
+	// Example 2: slog.Any on a wrapped error
+	fmt.Println("2. slog.Any does not look inside the chain:")
+	newLogger(nil).Error("request failed", slog.Any("error", err))
+	fmt.Println()
+
+	// Example 3: Every error in the chain adds its fields
+	fmt.Println("3. sloghelpers.Err:")
+	newLogger(nil).Error("request failed", sloghelpers.Err(err))
+	fmt.Println()
+
+	// Example 4: Existing slog.Any calls, fixed in one place
+	fmt.Println("4. sloghelpers.ReplaceAttr on existing calls:")
+	newLogger(sloghelpers.ReplaceAttr).Error("request failed", slog.Any("error", err))
+	fmt.Println()
+
+	// Example 5: All the errors of a validation, joined
+	fmt.Println("5. Joined errors:")
+	verr := errors.Join(
+		&ValidationError{Field: "email", Value: "bob@", Message: "invalid email"},
+		&ValidationError{Field: "age", Value: -1, Message: "must be positive"},
+	)
+	newLogger(nil).Warn("invalid signup", sloghelpers.Err(verr))
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+)
+
+// The solution reads files by name, from the current directory. Each
+// test moves into a directory of its own, with t.Chdir, so the files it
+// creates never land in the repository, and a stray config.json there
+// can't change the result
+
+func TestReadFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.WriteFile("config.json", []byte(`{}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := readFile("config.json"); err != nil {
+		t.Errorf("want nil for a file that's there; got %v", err)
+	}
+
+	err := readFile("data.txt")
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("want fs.ErrNotExist through the wrapping; got %v", err)
+	}
+	if want := `failed to read file "data.txt": `; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("want an error starting with %q; got %v", want, err)
+	}
+}
+
+func TestProcessFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	if err := os.Mkdir("settings.yaml", 0o755); err != nil { // a directory, not a file
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, context string
+		notExist      bool
+	}{
+		{"config.json", "configuration", true},
+		{"settings.yaml", "settings", false},
+	}
+	for _, tt := range tests {
+		err := processFile(tt.name, tt.context)
+		if err == nil {
+			t.Errorf("%s: want an error", tt.name)
+			continue
+		}
+		want := "failed to process " + tt.context + `: failed to read file "` + tt.name + `": `
+		if !strings.HasPrefix(err.Error(), want) {
+			t.Errorf("%s: want both layers of context, %q; got %q", tt.name, want, err)
+		}
+		if got := errors.Is(err, fs.ErrNotExist); got != tt.notExist {
+			t.Errorf("%s: want errors.Is(err, fs.ErrNotExist) %t; got %t", tt.name, tt.notExist, got)
+		}
+
+		var pathErr *fs.PathError
+		if !errors.As(err, &pathErr) || pathErr.Path != tt.name {
+			t.Errorf("%s: want the *fs.PathError for %s at the bottom; got %v", tt.name, tt.name, err)
+		}
+	}
+}
+
+func TestProcessFileWorks(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for _, name := range []string{"config.json", "data.txt", "settings.yaml"} {
+		if err := os.WriteFile(name, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := processFile(name, "anything"); err != nil {
+			t.Errorf("%s: want nil; got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+// ---------------------------------------------------------
+// EXERCISE: Validate User
+//
+//  Validate a sign-up form and report EVERY problem at once,
+//  not just the first one, both for humans and for machines.
+//
+//  1- Create a User struct with Name, Email, Age and Password
+//
+//  2- Create a FieldError type with Field, Rule and Message
+//     (add json tags) and make *FieldError an error:
+//       "email: must be a valid email address"
+//
+//  3- Create Validate(u User) error that checks these rules
+//     and returns all the violations with errors.Join:
+//
+//     name      required, at most 50 characters
+//     email     required, a bare address like bob@example.com
+//               (hint: net/mail.ParseAddress), domain has a dot
+//     age       between 13 and 130
+//     password  at least 8 characters, has an uppercase letter,
+//               a lowercase letter and a digit, and must not
+//               contain the name
+//
+//  4- Create FieldErrors(err error) []*FieldError that returns
+//     every FieldError in err, even after it was wrapped with
+//     fmt.Errorf("signup: %w", err)
+//
+//     HINT: errors.As stops at the first match. Unwrap until you
+//           find the joined error (it has Unwrap() []error) and
+//           look at each error inside it.
+//
+//  5- Create Summary(err error) string for humans:
+//     "ok", "1 problem: ..." or "N problems:" with one line each
+//
+//  6- In main, validate the users below, wrap each error with
+//     "signup: ", then print the summary and the JSON list
+//
+//     {Name: "Alice", Email: "alice@example.com", Age: 30, Password: "Secr3tPass"}
+//     {Name: "Bob", Email: "bob@example", Age: 12, Password: "bob"}
+//     {Name: "", Email: "Carol <carol@example.com>", Age: 200, Password: "PASSWORD1"}
+//
+//
+// EXPECTED OUTPUT (similar to):
+//
+//  Validating {Name:Alice Email:alice@example.com Age:30 Password:Secr3tPass}
+//  ok
+//
+//  Validating {Name:Bob Email:bob@example Age:12 Password:bob}
+//  6 problems:
+//    - email: must be a valid email address
+//    - age: must be between 13 and 130
+//    - password: must be at least 8 characters
+//    - password: must contain an uppercase letter
+//    - password: must contain a digit
+//    - password: must not contain the name
+//  JSON: [{"field":"email","rule":"format","message":"must be a valid email address"},...]
+//
+//  Validating {Name: Email:Carol <carol@example.com> Age:200 Password:PASSWORD1}
+//  4 problems:
+//    - name: must not be empty
+//    - email: must be a valid email address
+//    - age: must be between 13 and 130
+//    - password: must contain a lowercase letter
+//  JSON: [{"field":"name","rule":"required","message":"must not be empty"},...]
+//
+// ---------------------------------------------------------
+
+package main
+
+func main() {
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode"
+)
+
+// User is the input to validate
+type User struct {
+	Name     string
+	Email    string
+	Age      int
+	Password string
+}
+
+// FieldError is one broken rule for one field
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Error implements the error interface
+func (e *FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// Validate checks every rule and returns all the violations joined with
+// errors.Join, or nil if there are none
+func Validate(u User) error {
+	var errs []error
+	add := func(field, rule, message string) {
+		errs = append(errs, &FieldError{Field: field, Rule: rule, Message: message})
+	}
+
+	// Name
+	name := strings.TrimSpace(u.Name)
+	switch {
+	case name == "":
+		add("name", "required", "must not be empty")
+	case len([]rune(name)) > 50:
+		add("name", "max_length", "must be at most 50 characters")
+	}
+
+	// Email: a bare address, without a display name like "Bob <bob@x.io>"
+	if u.Email == "" {
+		add("email", "required", "must not be empty")
+	} else if addr, err := mail.ParseAddress(u.Email); err != nil || addr.Address != u.Email || !strings.Contains(u.Email[strings.LastIndex(u.Email, "@"):], ".") {
+		add("email", "format", "must be a valid email address")
+	}
+
+	// Age
+	if u.Age < 13 || u.Age > 130 {
+		add("age", "range", "must be between 13 and 130")
+	}
+
+	// Password: every broken rule is reported, not just the first
+	p := u.Password
+	if len([]rune(p)) < 8 {
+		add("password", "min_length", "must be at least 8 characters")
+	}
+	if !strings.ContainsFunc(p, unicode.IsUpper) {
+		add("password", "uppercase", "must contain an uppercase letter")
+	}
+	if !strings.ContainsFunc(p, unicode.IsLower) {
+		add("password", "lowercase", "must contain a lowercase letter")
+	}
+	if !strings.ContainsFunc(p, unicode.IsDigit) {
+		add("password", "digit", "must contain a digit")
+	}
+	if name != "" && strings.Contains(strings.ToLower(p), strings.ToLower(name)) {
+		add("password", "contains_name", "must not contain the name")
+	}
+
+	// errors.Join returns nil when errs is empty
+	return errors.Join(errs...)
+}
+
+// FieldErrors returns every *FieldError in err, in order, even when
+// err was wrapped again, like fmt.Errorf("signup: %w", err)
+func FieldErrors(err error) []*FieldError {
+	// errors.As stops at the first match, so find the join first
+	for e := err; e != nil; e = errors.Unwrap(e) {
+		joined, ok := e.(interface{ Unwrap() []error })
+		if !ok {
+			continue
+		}
+		var all []*FieldError
+		for _, inner := range joined.Unwrap() {
+			all = append(all, FieldErrors(inner)...) // joins can nest
+		}
+		return all
+	}
+
+	// No join: at most one FieldError
+	var fe *FieldError
+	if errors.As(err, &fe) {
+		return []*FieldError{fe}
+	}
+	return nil
+}
+
+// Summary describes err for a human
+func Summary(err error) string {
+	if err == nil {
+		return "ok"
+	}
+
+	fes := FieldErrors(err)
+	switch len(fes) {
+	case 0:
+		return err.Error() // not a validation error
+	case 1:
+		return "1 problem: " + fes[0].Error()
+	}
+
+	lines := make([]string, len(fes))
+	for i, fe := range fes {
+		lines[i] = "  - " + fe.Error()
+	}
+	return fmt.Sprintf("%d problems:\n%s", len(fes), strings.Join(lines, "\n"))
+}
+
+func main() {
+	users := []User{
+		{Name: "Alice", Email: "alice@example.com", Age: 30, Password: "Secr3tPass"},
+		{Name: "Bob", Email: "bob@example", Age: 12, Password: "bob"},
+		{Name: "", Email: "Carol <carol@example.com>", Age: 200, Password: "PASSWORD1"},
+	}
+
+	for _, u := range users {
+		fmt.Printf("Validating %+v\n", u)
+
+		err := Validate(u)
+		if err != nil {
+			// Errors usually get wrapped on the way up; that is fine
+			err = fmt.Errorf("signup: %w", err)
+		}
+
+		fmt.Println(Summary(err))
+
+		if fes := FieldErrors(err); fes != nil {
+			data, _ := json.Marshal(fes)
+			fmt.Printf("JSON: %s\n", data)
+		}
+		fmt.Println()
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// valid is a user that passes every rule
+var valid = User{Name: "Alice", Email: "alice@example.com", Age: 30, Password: "Secr3tPass"}
+
+// rules returns "field/rule" for every FieldError in err
+func rules(err error) []string {
+	var out []string
+	for _, fe := range FieldErrors(err) {
+		out = append(out, fe.Field+"/"+fe.Rule)
+	}
+	return out
+}
+
+func TestValidUser(t *testing.T) {
+	if err := Validate(valid); err != nil {
+		t.Errorf("want nil; got %v", err)
+	}
+}
+
+func TestSingleField(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(*User)
+		want string
+	}{
+		{"empty name", func(u *User) { u.Name = "  " }, "name/required"},
+		{"long name", func(u *User) { u.Name = strings.Repeat("a", 51) }, "name/max_length"},
+		{"empty email", func(u *User) { u.Email = "" }, "email/required"},
+		{"no at sign", func(u *User) { u.Email = "alice.example.com" }, "email/format"},
+		{"no domain dot", func(u *User) { u.Email = "alice@example" }, "email/format"},
+		{"display name", func(u *User) { u.Email = "Alice <alice@example.com>" }, "email/format"},
+		{"too young", func(u *User) { u.Age = 12 }, "age/range"},
+		{"too old", func(u *User) { u.Age = 131 }, "age/range"},
+		{"short password", func(u *User) { u.Password = "Sh0rt" }, "password/min_length"},
+		{"no uppercase", func(u *User) { u.Password = "secr3tpass" }, "password/uppercase"},
+		{"no lowercase", func(u *User) { u.Password = "SECR3TPASS" }, "password/lowercase"},
+		{"no digit", func(u *User) { u.Password = "SecretPass" }, "password/digit"},
+		{"contains name", func(u *User) { u.Password = "ALICEsecret1" }, "password/contains_name"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := valid
+			tt.edit(&u)
+
+			if got := rules(Validate(u)); !slices.Equal(got, []string{tt.want}) {
+				t.Errorf("want [%s]; got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestAllViolationsAreReported(t *testing.T) {
+	err := Validate(User{Name: "", Email: "nope", Age: 0, Password: "abc"})
+
+	want := []string{
+		"name/required",
+		"email/format",
+		"age/range",
+		"password/min_length",
+		"password/uppercase",
+		"password/digit",
+	}
+	if got := rules(err); !slices.Equal(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
+
+func TestWrappedErrorsKeepEveryField(t *testing.T) {
+	err := Validate(User{Name: "Bob", Email: "bob@example.com", Age: 5, Password: "x"})
+	wrapped := fmt.Errorf("handler: %w", fmt.Errorf("signup: %w", err))
+
+	if got, want := rules(wrapped), rules(err); !slices.Equal(got, want) || len(got) < 2 {
+		t.Errorf("want %v; got %v", want, got)
+	}
+
+	// errors.As alone would only find the first one
+	var fe *FieldError
+	if !errors.As(wrapped, &fe) || fe.Field != "age" {
+		t.Errorf("want errors.As to find the first FieldError; got %v", fe)
+	}
+}
+
+func TestFieldErrorsOnOtherErrors(t *testing.T) {
+	if fes := FieldErrors(nil); fes != nil {
+		t.Errorf("nil: want nil; got %v", fes)
+	}
+	if fes := FieldErrors(errors.New("db down")); fes != nil {
+		t.Errorf("plain error: want nil; got %v", fes)
+	}
+
+	single := fmt.Errorf("x: %w", &FieldError{Field: "age", Rule: "range"})
+	if got := rules(single); !slices.Equal(got, []string{"age/range"}) {
+		t.Errorf("single wrapped FieldError: got %v", got)
+	}
+}
+
+func TestSummary(t *testing.T) {
+	if got := Summary(nil); got != "ok" {
+		t.Errorf("nil: want ok; got %q", got)
+	}
+	if got := Summary(errors.New("db down")); got != "db down" {
+		t.Errorf("plain error: want the message; got %q", got)
+	}
+
+	u := valid
+	u.Age = 1
+	if got := Summary(Validate(u)); got != "1 problem: age: must be between 13 and 130" {
+		t.Errorf("one problem: got %q", got)
+	}
+
+	u.Email = ""
+	got := Summary(Validate(u))
+	if !strings.HasPrefix(got, "2 problems:\n") || !strings.Contains(got, "  - email: must not be empty") {
+		t.Errorf("two problems: got %q", got)
+	}
+}
+
+func TestJSON(t *testing.T) {
+	u := valid
+	u.Age = 1
+
+	data, err := json.Marshal(FieldErrors(Validate(u)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"field":"age","rule":"range","message":"must be between 13 and 130"}]`
+	if string(data) != want {
+		t.Errorf("want %s; got %s", want, data)
+	}
+}
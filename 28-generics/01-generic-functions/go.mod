@@ -1,5 +1,3 @@
 module example
 
 go 1.25.6
-
-require golang.org/x/exp v0.0.0-20260112195511-716be5621a96 // indirect
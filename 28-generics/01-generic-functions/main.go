@@ -1,8 +1,8 @@
 package main
 
 import (
+	"cmp"
 	"fmt"
-	"golang.org/x/exp/constraints"
 )
 
 func main() {
@@ -17,6 +17,7 @@ func main() {
 	fmt.Printf("Min(3.14, 2.71) = %.2f\n", Min(3.14, 2.71))
 	fmt.Printf("Max(3.14, 2.71) = %.2f\n", Max(3.14, 2.71))
 	fmt.Printf("Min(\"apple\", \"banana\") = %s\n", Min("apple", "banana"))
+	fmt.Printf("Built-in min(5, 10, 3) = %d\n", min(5, 10, 3))
 	fmt.Println()
 
 	// Example 2: Generic Map function
@@ -69,8 +70,9 @@ func main() {
 }
 
 // Min returns the smaller of two values
-// Uses constraints.Ordered which includes all types that support < operator
-func Min[T constraints.Ordered](a, b T) T {
+// Uses cmp.Ordered which includes all types that support < operator
+// (Go 1.21 also added the built-in min and max, which do the same job)
+func Min[T cmp.Ordered](a, b T) T {
 	if a < b {
 		return a
 	}
@@ -78,7 +80,7 @@ func Min[T constraints.Ordered](a, b T) T {
 }
 
 // Max returns the larger of two values
-func Max[T constraints.Ordered](a, b T) T {
+func Max[T cmp.Ordered](a, b T) T {
 	if a > b {
 		return a
 	}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+func main() {
+	fmt.Println("A Generic Set")
+	fmt.Println("=============")
+	fmt.Println()
+
+	// Example 1: Unique values
+	fmt.Println("1. Adding, removing, and checking items:")
+	tags := generics.NewSet("go", "generics", "go", "sets")
+	fmt.Printf("Size: %d (the duplicate \"go\" was ignored)\n", tags.Len())
+	fmt.Printf("Contains \"go\": %v\n", tags.Contains("go"))
+
+	tags.Remove("generics")
+	tags.Add("iterators")
+	fmt.Printf("Sorted: %v\n", generics.Sorted(tags))
+	fmt.Println()
+
+	// Example 2: Union, intersection, and difference
+	fmt.Println("2. Set operations:")
+	backend := generics.NewSet("go", "sql", "docker", "linux")
+	frontend := generics.NewSet("typescript", "css", "docker", "linux")
+
+	fmt.Printf("Union:        %v\n", generics.Sorted(backend.Union(frontend)))
+	fmt.Printf("Intersection: %v\n", generics.Sorted(backend.Intersection(frontend)))
+	fmt.Printf("Difference:   %v\n", generics.Sorted(backend.Difference(frontend)))
+	fmt.Printf("Unchanged:    %v\n", generics.Sorted(backend))
+	fmt.Println()
+
+	// Example 3: Iter returns an iter.Seq, so range works on it
+	fmt.Println("3. Iterating with range:")
+	var upper []string
+	for tag := range tags.Iter() {
+		upper = append(upper, strings.ToUpper(tag))
+	}
+	slices.Sort(upper) // a set has no order of its own
+	fmt.Printf("Upper: %v\n", upper)
+
+	// The standard library accepts iter.Seq too
+	fmt.Printf("Collected and sorted: %v\n", slices.Sorted(tags.Iter()))
+	fmt.Println()
+
+	// Example 4: Any comparable type works, structs included
+	fmt.Println("4. A set of structs:")
+	type Point struct{ X, Y int }
+	visited := generics.NewSet(Point{0, 0}, Point{1, 0}, Point{0, 0})
+	fmt.Printf("Visited %d points\n", visited.Len())
+	fmt.Printf("Visited (1,0): %v\n", visited.Contains(Point{1, 0}))
+	fmt.Printf("Visited (2,2): %v\n", visited.Contains(Point{2, 2}))
+	fmt.Println()
+
+	// Example 5: The zero value is ready to use
+	fmt.Println("5. The zero value:")
+	var seen generics.Set[int]
+	for _, n := range []int{3, 1, 3, 2, 1} {
+		if seen.Contains(n) {
+			fmt.Printf("%d seen before\n", n)
+			continue
+		}
+		seen.Add(n)
+	}
+	fmt.Printf("Unique: %v\n", generics.Sorted(&seen))
+}
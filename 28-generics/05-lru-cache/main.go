@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/cache"
+)
+
+// User is what the cache stores in the examples below
+type User struct {
+	ID   int
+	Name string
+}
+
+func main() {
+	fmt.Println("A Generic LRU Cache")
+	fmt.Println("===================")
+	fmt.Println()
+
+	// Example 1: The least recently used entry goes first
+	fmt.Println("1. Eviction:")
+	evictionExample()
+	fmt.Println()
+
+	// Example 2: Hits, misses, and evictions
+	fmt.Println("2. Stats:")
+	statsExample()
+	fmt.Println()
+
+	// Example 3: Entries that expire
+	fmt.Println("3. TTL:")
+	ttlExample()
+	fmt.Println()
+
+	// Example 4: Many goroutines, one cache
+	fmt.Println("4. Concurrent use:")
+	concurrentExample()
+}
+
+func evictionExample() {
+	users := cache.New[int, User](3)
+	users.Put(1, User{1, "Ann"})
+	users.Put(2, User{2, "Bob"})
+	users.Put(3, User{3, "Cem"})
+	fmt.Printf("Keys, most recent first: %v\n", users.Keys())
+
+	// Reading 1 makes it the most recent; 2 is now the least
+	if u, ok := users.Get(1); ok {
+		fmt.Printf("Get(1): %s\n", u.Name)
+	}
+	fmt.Printf("Keys:                    %v\n", users.Keys())
+
+	users.Put(4, User{4, "Dan"})
+	fmt.Printf("After Put(4):            %v\n", users.Keys())
+
+	_, ok := users.Get(2)
+	fmt.Printf("Get(2) found: %v\n", ok)
+}
+
+func statsExample() {
+	lookups := 0
+	squares := cache.New[int, int](3)
+
+	// square pretends to be expensive, and caches its results
+	square := func(n int) int {
+		if v, ok := squares.Get(n); ok {
+			return v
+		}
+		lookups++
+		squares.Put(n, n*n)
+		return n * n
+	}
+
+	for _, n := range []int{1, 2, 1, 3, 1, 2, 4, 5, 1} {
+		square(n)
+	}
+
+	s := squares.Stats()
+	fmt.Printf("%+v\n", s)
+	fmt.Printf("Hit ratio: %.0f%%, expensive lookups: %d of %d\n",
+		100*float64(s.Hits)/float64(s.Hits+s.Misses), lookups, s.Hits+s.Misses)
+}
+
+func ttlExample() {
+	sessions := cache.New[string, int](10, cache.WithTTL(50*time.Millisecond))
+	sessions.Put("short", 1)
+	sessions.PutWithTTL("long", 2, time.Hour)
+
+	_, ok := sessions.Get("short")
+	fmt.Printf("Right away, short found: %v\n", ok)
+
+	time.Sleep(60 * time.Millisecond)
+
+	_, ok = sessions.Get("short")
+	fmt.Printf("After 60ms, short found: %v\n", ok)
+	_, ok = sessions.Get("long")
+	fmt.Printf("After 60ms, long found:  %v\n", ok)
+	fmt.Printf("%+v\n", sessions.Stats())
+}
+
+func concurrentExample() {
+	// cache.LRU is not safe for concurrent use; Concurrent adds a mutex
+	users := cache.NewConcurrent[int, User](100)
+
+	var (
+		wg    sync.WaitGroup
+		mu    sync.Mutex
+		loads int
+	)
+	for range 10 {
+		wg.Go(func() {
+			for id := range 5 {
+				// Check and store under one lock: only one goroutine
+				// stores each user
+				_, loaded := users.GetOrPut(id, User{ID: id})
+				if !loaded {
+					mu.Lock()
+					loads++
+					mu.Unlock()
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	fmt.Printf("10 goroutines asked for 5 users: %d stored, %d cached\n", loads, users.Len())
+}
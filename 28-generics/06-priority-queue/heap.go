@@ -0,0 +1,91 @@
+package main
+
+// Heap is a binary heap ordered by less: Pop always returns the item for
+// which less(item, other) holds against every other item. With a < b it
+// is a min-heap, with a > b a max-heap.
+type Heap[T any] struct {
+	items []T
+	less  func(a, b T) bool
+}
+
+// NewHeap returns a heap ordered by less that holds items. Building it
+// from a whole slice at once takes O(n), not O(n log n).
+func NewHeap[T any](less func(a, b T) bool, items ...T) *Heap[T] {
+	h := &Heap[T]{items: append([]T(nil), items...), less: less}
+
+	// Every node past the middle is a leaf, and a leaf is a heap
+	for i := len(h.items)/2 - 1; i >= 0; i-- {
+		h.down(i)
+	}
+	return h
+}
+
+// Push adds item in O(log n).
+func (h *Heap[T]) Push(item T) {
+	h.items = append(h.items, item)
+	h.up(len(h.items) - 1)
+}
+
+// Pop removes and returns the first item in O(log n). It returns the
+// zero value and false if the heap is empty.
+func (h *Heap[T]) Pop() (T, bool) {
+	var zero T
+	if len(h.items) == 0 {
+		return zero, false
+	}
+
+	top := h.items[0]
+	last := len(h.items) - 1
+	h.items[0] = h.items[last]
+	h.items[last] = zero // don't keep a reference to a popped item
+	h.items = h.items[:last]
+	h.down(0)
+
+	return top, true
+}
+
+// Peek returns the first item without removing it.
+func (h *Heap[T]) Peek() (T, bool) {
+	if len(h.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	return h.items[0], true
+}
+
+// Len returns the number of items.
+func (h *Heap[T]) Len() int {
+	return len(h.items)
+}
+
+// up moves the item at i towards the root until its parent comes first.
+func (h *Heap[T]) up(i int) {
+	for i > 0 {
+		parent := (i - 1) / 2
+		if !h.less(h.items[i], h.items[parent]) {
+			return
+		}
+		h.items[i], h.items[parent] = h.items[parent], h.items[i]
+		i = parent
+	}
+}
+
+// down moves the item at i towards the leaves until it comes before both
+// of its children.
+func (h *Heap[T]) down(i int) {
+	n := len(h.items)
+	for {
+		first := i
+		if l := 2*i + 1; l < n && h.less(h.items[l], h.items[first]) {
+			first = l
+		}
+		if r := 2*i + 2; r < n && h.less(h.items[r], h.items[first]) {
+			first = r
+		}
+		if first == i {
+			return
+		}
+		h.items[i], h.items[first] = h.items[first], h.items[i]
+		i = first
+	}
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"container/heap"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+// checkHeap fails the test unless no child comes before its parent
+func checkHeap[T any](t *testing.T, h *Heap[T]) {
+	t.Helper()
+	for i := 1; i < len(h.items); i++ {
+		parent := (i - 1) / 2
+		if h.less(h.items[i], h.items[parent]) {
+			t.Fatalf("child %d (%v) comes before its parent %d (%v)", i, h.items[i], parent, h.items[parent])
+		}
+	}
+}
+
+func intLess(a, b int) bool { return a < b }
+
+func TestNewHeapHeapifies(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	for n := range 50 {
+		items := make([]int, n)
+		for i := range items {
+			items[i] = r.IntN(20) // duplicates too
+		}
+
+		h := NewHeap(intLess, items...)
+		checkHeap(t, h)
+
+		var got []int
+		for h.Len() > 0 {
+			v, _ := h.Pop()
+			got = append(got, v)
+			checkHeap(t, h)
+		}
+		if want := slices.Sorted(slices.Values(items)); !slices.Equal(got, want) {
+			t.Fatalf("n=%d: want %v; got %v", n, want, got)
+		}
+	}
+}
+
+func TestInvariantUnderRandomOps(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	h := NewHeap(intLess)
+	var model []int // the same items, kept sorted
+
+	for range 2000 {
+		if r.IntN(3) > 0 || len(model) == 0 {
+			v := r.IntN(100)
+			h.Push(v)
+			model = append(model, v)
+			slices.Sort(model)
+		} else {
+			v, ok := h.Pop()
+			if !ok || v != model[0] {
+				t.Fatalf("Pop: want %d; got %d, %v", model[0], v, ok)
+			}
+			model = model[1:]
+		}
+
+		checkHeap(t, h)
+		if h.Len() != len(model) {
+			t.Fatalf("want len %d; got %d", len(model), h.Len())
+		}
+		if top, _ := h.Peek(); len(model) > 0 && top != model[0] {
+			t.Fatalf("Peek: want %d; got %d", model[0], top)
+		}
+	}
+}
+
+func TestNewHeapCopiesItems(t *testing.T) {
+	items := []int{3, 1, 2}
+	NewHeap(intLess, items...)
+
+	if !slices.Equal(items, []int{3, 1, 2}) {
+		t.Errorf("want the caller's slice unchanged; got %v", items)
+	}
+}
+
+func TestEmpty(t *testing.T) {
+	h := NewHeap(intLess)
+
+	if v, ok := h.Pop(); ok || v != 0 {
+		t.Errorf("Pop: want 0, false; got %d, %v", v, ok)
+	}
+	if v, ok := h.Peek(); ok || v != 0 {
+		t.Errorf("Peek: want 0, false; got %d, %v", v, ok)
+	}
+}
+
+func TestPopClearsSlot(t *testing.T) {
+	h := NewHeap(func(a, b *Task) bool { return a.Priority > b.Priority }, &Task{ID: 1}, &Task{ID: 2})
+	h.Pop()
+
+	if old := h.items[:2][1]; old != nil {
+		t.Errorf("want the popped slot cleared; got %v", old)
+	}
+}
+
+func TestTasksByPriority(t *testing.T) {
+	h := NewHeap(byPriority, tasks...)
+
+	var got []int
+	for h.Len() > 0 {
+		task, _ := h.Pop()
+		got = append(got, task.ID)
+	}
+
+	// Ties go to the lower ID: 3 before 5
+	if want := []int{4, 3, 5, 2, 1}; !slices.Equal(got, want) {
+		t.Errorf("want IDs %v; got %v", want, got)
+	}
+}
+
+func TestMatchesContainerHeap(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	var many []Task
+	for i := range 200 {
+		many = append(many, Task{ID: i, Priority: r.IntN(5)})
+	}
+
+	h := NewHeap(byPriority, many...)
+	q := &taskQueue{}
+	for _, task := range many {
+		heap.Push(q, task)
+	}
+
+	for q.Len() > 0 {
+		want := heap.Pop(q).(Task)
+		if got, _ := h.Pop(); got != want {
+			t.Fatalf("want %v; got %v", want, got)
+		}
+	}
+}
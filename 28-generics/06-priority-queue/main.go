@@ -0,0 +1,114 @@
+package main
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// Task is the Task from the generic stack exercise
+type Task struct {
+	ID       int
+	Name     string
+	Priority int
+}
+
+// tasks are scheduled by every example below
+var tasks = []Task{
+	{1, "Low priority task", 1},
+	{2, "Medium priority task", 2},
+	{3, "High priority task", 3},
+	{4, "Urgent task", 4},
+	{5, "Another high priority task", 3},
+}
+
+// byPriority puts the highest priority first, and the older task first
+// when two have the same priority
+func byPriority(a, b Task) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.ID < b.ID
+}
+
+func main() {
+	fmt.Println("A Generic Priority Queue")
+	fmt.Println("========================")
+	fmt.Println()
+
+	// Example 1: A min-heap of numbers
+	fmt.Println("1. Min-heap of ints:")
+	minHeap := NewHeap(func(a, b int) bool { return a < b }, 5, 2, 8, 1, 9)
+	minHeap.Push(3)
+	var ints []int
+	for minHeap.Len() > 0 {
+		n, _ := minHeap.Pop()
+		ints = append(ints, n)
+	}
+	fmt.Printf("Popped: %v\n", ints)
+	fmt.Println()
+
+	// Example 2: Only less changes for a max-heap
+	fmt.Println("2. Max-heap of strings:")
+	maxHeap := NewHeap(func(a, b string) bool { return a > b }, "pear", "apple", "fig", "kiwi")
+	var strs []string
+	for maxHeap.Len() > 0 {
+		s, _ := maxHeap.Pop()
+		strs = append(strs, s)
+	}
+	fmt.Printf("Popped: %v\n", strs)
+	fmt.Println()
+
+	// Example 3: The same schedule with container/heap
+	fmt.Println("3. Scheduling tasks with container/heap:")
+	containerHeapExample()
+	fmt.Println()
+
+	// Example 4: The generic Heap
+	fmt.Println("4. Scheduling tasks with Heap[Task]:")
+	genericHeapExample()
+}
+
+// taskQueue implements heap.Interface: five methods, two of them on any
+type taskQueue []Task
+
+func (q taskQueue) Len() int           { return len(q) }
+func (q taskQueue) Less(i, j int) bool { return byPriority(q[i], q[j]) }
+func (q taskQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+
+// Push and Pop take and return any: the compiler can't check the type
+func (q *taskQueue) Push(x any) { *q = append(*q, x.(Task)) }
+
+func (q *taskQueue) Pop() any {
+	old := *q
+	n := len(old)
+	t := old[n-1]
+	*q = old[:n-1]
+	return t
+}
+
+func containerHeapExample() {
+	q := &taskQueue{}
+	for _, t := range tasks {
+		heap.Push(q, t) // not q.Push: heap.Push keeps the order
+	}
+
+	for q.Len() > 0 {
+		t := heap.Pop(q).(Task) // a type assertion on every Pop
+		fmt.Printf("Processing: [ID:%d] %s (Priority: %d)\n", t.ID, t.Name, t.Priority)
+	}
+}
+
+func genericHeapExample() {
+	q := NewHeap(byPriority, tasks...)
+
+	if next, ok := q.Peek(); ok {
+		fmt.Printf("Next up: %s\n", next.Name)
+	}
+	for q.Len() > 0 {
+		t, _ := q.Pop() // already a Task
+		fmt.Printf("Processing: [ID:%d] %s (Priority: %d)\n", t.ID, t.Name, t.Priority)
+	}
+
+	_, ok := q.Pop()
+	fmt.Printf("Pop on empty: ok=%v\n", ok)
+}
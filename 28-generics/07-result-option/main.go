@@ -0,0 +1,106 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+var (
+	errPortRange = errors.New("port out of range")
+	errMissing   = errors.New("not set")
+)
+
+// settings stands in for environment variables or a config file
+var settings = map[string]string{
+	"PORT":       "8080",
+	"ADMIN_PORT": "99999",
+	"DEBUG_PORT": "eighty",
+}
+
+// lookup reads a setting that may be missing
+func lookup(key string) Option[string] {
+	v, ok := settings[key]
+	return FromPair(v, ok)
+}
+
+// validPort fails for ports outside 1-65535
+func validPort(p int) Result[int] {
+	if p < 1 || p > 65535 {
+		return Err[int](fmt.Errorf("%w: %d", errPortRange, p))
+	}
+	return Ok(p)
+}
+
+// addrResult reads a port with Result: each step runs only if the one
+// before it succeeded
+func addrResult(key string) Result[string] {
+	raw := OkOr(lookup(key), fmt.Errorf("%s: %w", key, errMissing))
+	port := AndThen(raw, func(s string) Result[int] { return From(strconv.Atoi(s)) })
+	port = AndThen(port, validPort)
+	return Map(port, func(p int) string { return fmt.Sprintf(":%d", p) })
+}
+
+// addrGo reads a port the way Go code usually does
+func addrGo(key string) (string, error) {
+	raw, ok := settings[key]
+	if !ok {
+		return "", fmt.Errorf("%s: %w", key, errMissing)
+	}
+	p, err := strconv.Atoi(raw)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", key, err)
+	}
+	if p < 1 || p > 65535 {
+		return "", fmt.Errorf("%s: %w: %d", key, errPortRange, p)
+	}
+	return fmt.Sprintf(":%d", p), nil
+}
+
+func main() {
+	fmt.Println("Result[T] and Option[T]")
+	fmt.Println("=======================")
+	fmt.Println()
+
+	// Example 1: Option wraps a value that may be missing
+	fmt.Println("1. Option:")
+	for _, key := range []string{"PORT", "HOST"} {
+		o := lookup(key)
+		fmt.Printf("%-4s %-14v or default: %s\n", key, o, o.UnwrapOr("localhost"))
+	}
+	length := MapOption(lookup("PORT"), func(s string) int { return len(s) })
+	fmt.Printf("MapOption to its length: %v\n", length)
+	fmt.Println()
+
+	// Example 2: Result chains steps that can fail
+	fmt.Println("2. Result with AndThen and Map:")
+	for _, key := range []string{"PORT", "ADMIN_PORT", "DEBUG_PORT", "HOST"} {
+		fmt.Printf("%-10s %v\n", key, addrResult(key))
+	}
+	fmt.Printf("UnwrapOr: %s\n", addrResult("DEBUG_PORT").UnwrapOr(":80"))
+	fmt.Println()
+
+	// Example 3: The same in plain Go
+	fmt.Println("3. The same with (T, error):")
+	for _, key := range []string{"PORT", "ADMIN_PORT", "DEBUG_PORT", "HOST"} {
+		addr, err := addrGo(key)
+		fmt.Printf("%-10s %q %v\n", key, addr, err)
+	}
+	fmt.Println()
+
+	// Example 4: Zero values
+	fmt.Println("4. Zero values:")
+	var o Option[int]
+	var r Result[int]
+	fmt.Printf("var o Option[int]: %v, IsSome: %v\n", o, o.IsSome())
+	fmt.Printf("var r Result[int]: %v, IsOk: %v  <- a zero Result looks like success\n", r, r.IsOk())
+	fmt.Println()
+
+	// Example 5: Back to Go's pairs at the edges
+	fmt.Println("5. Converting back:")
+	_, err := addrResult("ADMIN_PORT").Get()
+	fmt.Printf("errors.Is(err, errPortRange): %v\n", errors.Is(err, errPortRange))
+	if v, ok := lookup("PORT").Get(); ok {
+		fmt.Printf("PORT is %s\n", v)
+	}
+}
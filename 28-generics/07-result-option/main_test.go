@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestOption(t *testing.T) {
+	some, none := Some(2), None[int]()
+
+	if v, ok := some.Get(); !ok || v != 2 {
+		t.Errorf("Some(2).Get: got %d, %v", v, ok)
+	}
+	if none.IsSome() || none.UnwrapOr(7) != 7 {
+		t.Error("None: want no value and the fallback")
+	}
+	if (Option[int]{}) != none {
+		t.Error("want the zero value to be None")
+	}
+
+	double := func(n int) int { return n * 2 }
+	if got := MapOption(some, double); got != Some(4) {
+		t.Errorf("MapOption(Some): got %v", got)
+	}
+	if got := MapOption(none, double); got.IsSome() {
+		t.Errorf("MapOption(None): got %v", got)
+	}
+
+	half := func(n int) Option[int] {
+		if n%2 != 0 {
+			return None[int]()
+		}
+		return Some(n / 2)
+	}
+	if got := AndThenOption(AndThenOption(Some(4), half), half); got != Some(1) {
+		t.Errorf("4/2/2: got %v", got)
+	}
+	if got := AndThenOption(AndThenOption(Some(2), half), half); got.IsSome() {
+		t.Errorf("2/2 is odd, want None; got %v", got)
+	}
+}
+
+func TestResult(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	if r := From(strconv.Atoi("42")); !r.IsOk() || r.UnwrapOr(0) != 42 {
+		t.Errorf("From(42): got %v", r)
+	}
+	if r := From(strconv.Atoi("x")); r.IsOk() || r.UnwrapOr(-1) != -1 {
+		t.Errorf("From(x): got %v", r)
+	}
+
+	calls := 0
+	inc := func(n int) Result[int] { calls++; return Ok(n + 1) }
+	fail := func(int) Result[int] { calls++; return Err[int](errBoom) }
+
+	r := AndThen(AndThen(AndThen(Ok(1), inc), fail), inc)
+	if _, err := r.Get(); !errors.Is(err, errBoom) {
+		t.Errorf("want errBoom; got %v", err)
+	}
+	if calls != 2 {
+		t.Errorf("want the chain to stop at the error after 2 calls; got %d", calls)
+	}
+
+	if got := Map(Err[int](errBoom), strconv.Itoa); got.IsOk() {
+		t.Errorf("Map on an error: want the error passed through; got %v", got)
+	}
+	if got := OkOr(None[int](), errBoom); got.IsOk() {
+		t.Errorf("OkOr(None): want an error; got %v", got)
+	}
+}
+
+func TestAddr(t *testing.T) {
+	for key := range settings {
+		want, wantErr := addrGo(key)
+		got, err := addrResult(key).Get()
+
+		if got != want || (err == nil) != (wantErr == nil) {
+			t.Errorf("%s: Result gave %q, %v; plain Go gave %q, %v", key, got, err, want, wantErr)
+		}
+	}
+}
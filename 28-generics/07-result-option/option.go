@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+// Option holds a value or nothing. The zero value is None.
+type Option[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some returns an Option that holds v.
+func Some[T any](v T) Option[T] {
+	return Option[T]{value: v, ok: true}
+}
+
+// None returns an empty Option.
+func None[T any]() Option[T] {
+	return Option[T]{}
+}
+
+// FromPair turns Go's usual (value, ok) pair into an Option.
+func FromPair[T any](v T, ok bool) Option[T] {
+	if !ok {
+		return None[T]()
+	}
+	return Some(v)
+}
+
+// IsSome reports whether o holds a value.
+func (o Option[T]) IsSome() bool { return o.ok }
+
+// Get returns the value and whether there is one: back to Go's pair.
+func (o Option[T]) Get() (T, bool) { return o.value, o.ok }
+
+// UnwrapOr returns the value, or fallback if there is none.
+func (o Option[T]) UnwrapOr(fallback T) T {
+	if !o.ok {
+		return fallback
+	}
+	return o.value
+}
+
+// String implements fmt.Stringer.
+func (o Option[T]) String() string {
+	if !o.ok {
+		return "None"
+	}
+	return fmt.Sprintf("Some(%v)", o.value)
+}
+
+// MapOption applies fn to the value, if there is one.
+//
+// It is a function, not a method: methods can't have type parameters
+// of their own, and fn changes T into U.
+func MapOption[T, U any](o Option[T], fn func(T) U) Option[U] {
+	if !o.ok {
+		return None[U]()
+	}
+	return Some(fn(o.value))
+}
+
+// AndThenOption applies fn, which may itself return None, to the value.
+func AndThenOption[T, U any](o Option[T], fn func(T) Option[U]) Option[U] {
+	if !o.ok {
+		return None[U]()
+	}
+	return fn(o.value)
+}
@@ -0,0 +1,75 @@
+package main
+
+import "fmt"
+
+// Result holds a value or an error.
+type Result[T any] struct {
+	value T
+	err   error
+}
+
+// Ok returns a successful Result.
+func Ok[T any](v T) Result[T] {
+	return Result[T]{value: v}
+}
+
+// Err returns a failed Result.
+func Err[T any](err error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// From turns Go's usual (value, error) pair into a Result.
+func From[T any](v T, err error) Result[T] {
+	if err != nil {
+		return Err[T](err)
+	}
+	return Ok(v)
+}
+
+// IsOk reports whether r holds a value.
+func (r Result[T]) IsOk() bool { return r.err == nil }
+
+// Get returns the value and the error: back to Go's pair.
+func (r Result[T]) Get() (T, error) { return r.value, r.err }
+
+// UnwrapOr returns the value, or fallback if r failed.
+func (r Result[T]) UnwrapOr(fallback T) T {
+	if r.err != nil {
+		return fallback
+	}
+	return r.value
+}
+
+// String implements fmt.Stringer.
+func (r Result[T]) String() string {
+	if r.err != nil {
+		return fmt.Sprintf("Err(%v)", r.err)
+	}
+	return fmt.Sprintf("Ok(%v)", r.value)
+}
+
+// Map applies fn to the value of a successful Result, and passes an
+// error through untouched.
+func Map[T, U any](r Result[T], fn func(T) U) Result[U] {
+	if r.err != nil {
+		return Err[U](r.err)
+	}
+	return Ok(fn(r.value))
+}
+
+// AndThen applies fn, which may itself fail, to the value of a
+// successful Result. The first error stops the chain.
+func AndThen[T, U any](r Result[T], fn func(T) Result[U]) Result[U] {
+	if r.err != nil {
+		return Err[U](r.err)
+	}
+	return fn(r.value)
+}
+
+// OkOr turns an Option into a Result, with err for None.
+func OkOr[T any](o Option[T], err error) Result[T] {
+	if !o.ok {
+		return Err[T](err)
+	}
+	return Ok(o.value)
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+// Filter, Map, and Reduce are the eager versions from 01-generic-functions:
+// each one builds a whole new slice before the next one starts
+
+func Filter[T any](slice []T, predicate func(T) bool) []T {
+	result := make([]T, 0)
+	for _, v := range slice {
+		if predicate(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+func Map[T, U any](slice []T, fn func(T) U) []U {
+	result := make([]U, len(slice))
+	for i, v := range slice {
+		result[i] = fn(v)
+	}
+	return result
+}
+
+func Reduce[T, U any](slice []T, initial U, fn func(U, T) U) U {
+	acc := initial
+	for _, v := range slice {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+func isEven(n int) bool  { return n%2 == 0 }
+func square(n int) int   { return n * n }
+func add(acc, n int) int { return acc + n }
+
+func isPrime(n int) bool {
+	if n < 2 {
+		return false
+	}
+	for d := 2; d*d <= n; d++ {
+		if n%d == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// naturals is an infinite iterator over 0, 1, 2, ... It counts the items
+// the loop asked for in *pulled.
+func naturals(pulled *int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			*pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func main() {
+	fmt.Println("Lazy Iterators")
+	fmt.Println("==============")
+	fmt.Println()
+
+	// Example 1: The same pipeline, eager and lazy
+	fmt.Println("1. Eager slices vs lazy iterators:")
+	nums := []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+
+	evens := Filter(nums, isEven) // a new slice
+	squares := Map(evens, square) // another new slice
+	eager := Reduce(squares, 0, add)
+	fmt.Printf("Eager: %v -> %v -> %d\n", evens, squares, eager)
+
+	seq := generics.MapSeq(generics.FilterSeq(slices.Values(nums), isEven), square)
+	fmt.Printf("Lazy:  %d (no slices in between)\n", generics.ReduceSeq(seq, 0, add))
+	fmt.Println()
+
+	// Example 2: Nothing runs until the loop asks
+	fmt.Println("2. Pulling only what is needed:")
+	var pulled int
+	primes := generics.FilterSeq(naturals(&pulled), isPrime)
+	fmt.Printf("Built the pipeline, pulled %d numbers so far\n", pulled)
+	fmt.Printf("First 5 primes: %v\n", slices.Collect(generics.Take(primes, 5)))
+	fmt.Printf("Pulled %d numbers from an infinite source\n", pulled)
+	fmt.Println()
+
+	// Example 3: Zip walks two sequences in step
+	fmt.Println("3. Zip:")
+	players := slices.Values([]string{"ada", "bob", "cy"})
+	scores := slices.Values([]int{42, 17, 35, 99}) // one too many: Zip stops at the shorter
+	for name, score := range generics.Zip(players, scores) {
+		fmt.Printf("%-4s %d\n", name, score)
+	}
+	fmt.Println()
+
+	// Example 4: Chunk groups items into batches
+	fmt.Println("4. Chunk:")
+	ids := generics.Take(naturals(new(int)), 10)
+	for batch := range generics.Chunk(ids, 4) {
+		fmt.Printf("INSERT batch of %d: %v\n", len(batch), batch)
+	}
+	fmt.Println()
+
+	// Example 5: Composing a pipeline from small steps
+	fmt.Println("5. Composing steps:")
+	log := strings.Lines(`INFO start
+ERROR disk full
+INFO retry
+ERROR timeout
+ERROR disk full
+INFO done
+`)
+	errorsOnly := generics.FilterSeq(log, func(line string) bool { return strings.HasPrefix(line, "ERROR") })
+	messages := generics.MapSeq(errorsOnly, func(line string) string {
+		return strings.TrimSpace(strings.TrimPrefix(line, "ERROR"))
+	})
+	for i, msg := range generics.Zip(naturals(new(int)), generics.Take(messages, 2)) {
+		fmt.Printf("error %d: %s\n", i+1, msg)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+// input is large enough that the eager version's slices matter.
+var input = func() []int {
+	s := make([]int, 1_000_000)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}()
+
+func TestSameResult(t *testing.T) {
+	eager := Reduce(Map(Filter(input, isEven), square), 0, add)
+	lazy := generics.ReduceSeq(generics.MapSeq(generics.FilterSeq(slices.Values(input), isEven), square), 0, add)
+	if eager != lazy {
+		t.Errorf("eager %d != lazy %d", eager, lazy)
+	}
+}
+
+// Filter, then map, then sum all of the input.
+
+func BenchmarkSumEager(b *testing.B) {
+	for b.Loop() {
+		Reduce(Map(Filter(input, isEven), square), 0, add)
+	}
+}
+
+func BenchmarkSumLazy(b *testing.B) {
+	for b.Loop() {
+		seq := generics.MapSeq(generics.FilterSeq(slices.Values(input), isEven), square)
+		generics.ReduceSeq(seq, 0, add)
+	}
+}
+
+// Only the first 10 results are needed.
+
+func BenchmarkFirst10Eager(b *testing.B) {
+	for b.Loop() {
+		_ = Map(Filter(input, isEven), square)[:10]
+	}
+}
+
+func BenchmarkFirst10Lazy(b *testing.B) {
+	for b.Loop() {
+		seq := generics.MapSeq(generics.FilterSeq(slices.Values(input), isEven), square)
+		_ = slices.Collect(generics.Take(seq, 10))
+	}
+}
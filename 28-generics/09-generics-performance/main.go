@@ -0,0 +1,142 @@
+package main
+
+import "fmt"
+
+// Number is the constraint from 03-type-constraints
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Celsius has int's shape: Sum[Celsius] shares Sum[int]'s code
+type Celsius int
+
+// Sum is generic over the Number types
+func Sum[T Number](nums []T) T {
+	var total T
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+// sumInts and sumFloats are the hand-written versions Sum replaces
+func sumInts(nums []int) int {
+	var total int
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+func sumFloats(nums []float64) float64 {
+	var total float64
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+// sumAny is how a sum had to be written before generics: every item is
+// boxed in an interface, and a type switch unboxes it again
+func sumAny(nums []any) float64 {
+	var total float64
+	for _, n := range nums {
+		switch v := n.(type) {
+		case int:
+			total += float64(v)
+		case float64:
+			total += v
+		}
+	}
+	return total
+}
+
+// Scorer is a constraint with a method. Calling Score on a type
+// parameter works differently for value and pointer types: see the README
+type Scorer interface {
+	Score() int
+}
+
+type player struct {
+	name  string
+	score int
+}
+
+func (p player) Score() int { return p.score }
+
+// ptrPlayer has the same method on a pointer receiver
+type ptrPlayer struct {
+	name  string
+	score int
+}
+
+func (p *ptrPlayer) Score() int { return p.score }
+
+// Total calls a method through a type parameter
+func Total[T Scorer](items []T) int {
+	var total int
+	for _, it := range items {
+		total += it.Score()
+	}
+	return total
+}
+
+// totalPlayers is the hand-written version: the compiler knows it calls
+// player.Score, and inlines it
+func totalPlayers(items []player) int {
+	var total int
+	for _, it := range items {
+		total += it.Score()
+	}
+	return total
+}
+
+// totalIface does the same with an interface slice
+func totalIface(items []Scorer) int {
+	var total int
+	for _, it := range items {
+		total += it.Score()
+	}
+	return total
+}
+
+func main() {
+	fmt.Println("Generics Performance")
+	fmt.Println("====================")
+	fmt.Println()
+
+	// Example 1: Four ways to sum
+	fmt.Println("1. The same sum, four ways:")
+	ints := []int{1, 2, 3, 4, 5}
+	floats := []float64{1.5, 2.5, 3.5}
+	boxed := []any{1, 2, 3, 4, 5} // each int is copied into an interface
+
+	fmt.Printf("sumInts:      %d\n", sumInts(ints))
+	fmt.Printf("sumFloats:    %.1f\n", sumFloats(floats))
+	fmt.Printf("Sum[int]:     %d\n", Sum(ints))
+	fmt.Printf("Sum[float64]: %.1f\n", Sum(floats))
+	fmt.Printf("sumAny:       %.1f (always a float64: it can't know the type)\n", sumAny(boxed))
+	fmt.Println()
+
+	// Example 2: Types with the same shape share code
+	fmt.Println("2. One instantiation per shape:")
+	temps := []Celsius{21, 19, 23}
+	fmt.Printf("Sum[Celsius]: %d (runs Sum[go.shape.int], like Sum[int])\n", Sum(temps))
+	fmt.Println()
+
+	// Example 3: Methods through a type parameter
+	fmt.Println("3. Calling methods on T:")
+	values := []player{{"ada", 10}, {"bob", 20}}
+	ptrs := []*ptrPlayer{{"ada", 10}, {"bob", 20}}
+	ifaces := []Scorer{values[0], values[1]}
+
+	fmt.Printf("totalPlayers:      %d\n", totalPlayers(values))
+	fmt.Printf("Total[player]:     %d\n", Total(values))
+	fmt.Printf("Total[*ptrPlayer]: %d (every pointer type shares one shape)\n", Total(ptrs))
+	fmt.Printf("totalIface:        %d\n", totalIface(ifaces))
+	fmt.Println()
+
+	fmt.Println("Run go test -bench . -benchmem to compare their speed.")
+}
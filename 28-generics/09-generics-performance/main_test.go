@@ -0,0 +1,117 @@
+package main
+
+import "testing"
+
+const size = 10_000
+
+var (
+	ints   = make([]int, size)
+	floats = make([]float64, size)
+	boxed  = make([]any, size)
+
+	values = make([]player, size)
+	ptrs   = make([]*ptrPlayer, size)
+	ifaces = make([]Scorer, size)
+)
+
+func init() {
+	for i := range size {
+		ints[i] = i
+		floats[i] = float64(i)
+		boxed[i] = i
+		values[i] = player{score: i}
+		ptrs[i] = &ptrPlayer{score: i}
+		ifaces[i] = values[i]
+	}
+}
+
+// sink keeps the compiler from removing the loops being measured
+var sink int
+
+func TestSameResults(t *testing.T) {
+	want := sumInts(ints)
+	if got := Sum(ints); got != want {
+		t.Errorf("Sum[int] = %d; want %d", got, want)
+	}
+	if got := int(sumAny(boxed)); got != want {
+		t.Errorf("sumAny = %d; want %d", got, want)
+	}
+	if got := Sum(floats); got != sumFloats(floats) {
+		t.Errorf("Sum[float64] = %v; want %v", got, sumFloats(floats))
+	}
+	for name, got := range map[string]int{
+		"totalPlayers":      totalPlayers(values),
+		"Total[player]":     Total(values),
+		"Total[*ptrPlayer]": Total(ptrs),
+		"totalIface":        totalIface(ifaces),
+	} {
+		if got != want {
+			t.Errorf("%s = %d; want %d", name, got, want)
+		}
+	}
+}
+
+func BenchmarkSumInts(b *testing.B) {
+	for b.Loop() {
+		sink = sumInts(ints)
+	}
+}
+
+func BenchmarkSumGenericInt(b *testing.B) {
+	for b.Loop() {
+		sink = Sum(ints)
+	}
+}
+
+func BenchmarkSumFloats(b *testing.B) {
+	for b.Loop() {
+		sink = int(sumFloats(floats))
+	}
+}
+
+func BenchmarkSumGenericFloat(b *testing.B) {
+	for b.Loop() {
+		sink = int(Sum(floats))
+	}
+}
+
+func BenchmarkSumAny(b *testing.B) {
+	for b.Loop() {
+		sink = int(sumAny(boxed))
+	}
+}
+
+// BenchmarkBoxing measures building the []any that sumAny needs.
+func BenchmarkBoxing(b *testing.B) {
+	for b.Loop() {
+		s := make([]any, len(ints))
+		for i, n := range ints {
+			s[i] = n
+		}
+		sink = len(s)
+	}
+}
+
+func BenchmarkTotalConcrete(b *testing.B) {
+	for b.Loop() {
+		sink = totalPlayers(values)
+	}
+}
+
+func BenchmarkTotalValue(b *testing.B) {
+	for b.Loop() {
+		sink = Total(values)
+	}
+}
+
+func BenchmarkTotalPointer(b *testing.B) {
+	for b.Loop() {
+		sink = Total(ptrs)
+	}
+}
+
+func BenchmarkTotalInterface(b *testing.B) {
+	for b.Loop() {
+		sink = totalIface(ifaces)
+	}
+}
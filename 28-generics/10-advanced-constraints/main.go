@@ -0,0 +1,132 @@
+package main
+
+import "fmt"
+
+// Celsius is a named type: its underlying type is float64
+type Celsius float64
+
+func (c Celsius) String() string { return fmt.Sprintf("%.1f°C", float64(c)) }
+
+// Float accepts every type whose underlying type is float32 or float64
+type Float interface {
+	~float32 | ~float64
+}
+
+// Number is Float plus the integer types, all with ~
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		Float
+}
+
+// Mean returns a T, so a Celsius mean is still a Celsius. Compare
+// Average in 03-type-constraints, which always returns float64
+func Mean[T Float](nums []T) T {
+	var total T
+	for _, n := range nums {
+		total += n
+	}
+	return total / T(len(nums))
+}
+
+// Temperature mixes a type set with a method: only named float types
+// with a String method satisfy it. It can only be used as a constraint
+type Temperature interface {
+	~float64
+	String() string
+}
+
+// Warmest uses both: > from the type set, String from the method
+func Warmest[T Temperature](temps []T) string {
+	warmest := temps[0]
+	for _, t := range temps[1:] {
+		if t > warmest {
+			warmest = t
+		}
+	}
+	return "warmest: " + warmest.String()
+}
+
+// Index finds v in s. It compiles for any comparable T, including any
+func Index[T comparable](s []T, v T) int {
+	for i, item := range s {
+		if item == v {
+			return i
+		}
+	}
+	return -1
+}
+
+// safeIndex turns the panic Index can cause into an error
+func safeIndex[T comparable](s []T, v T) (i int, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	return Index(s, v), nil
+}
+
+// Convert converts between any two Number types. It compiles because
+// every type in Number converts to every other one
+func Convert[To, From Number](v From) To {
+	return To(v)
+}
+
+// describe can't switch on T directly: it converts v to any first
+func describe[T Number](v T) string {
+	switch x := any(v).(type) {
+	case Celsius:
+		return "a temperature: " + x.String()
+	case int, int64:
+		return fmt.Sprintf("an integer: %d", x)
+	default:
+		return fmt.Sprintf("some other %T: %v", x, x)
+	}
+}
+
+func main() {
+	fmt.Println("Advanced Constraints")
+	fmt.Println("====================")
+	fmt.Println()
+
+	// Example 1: Why ~ matters
+	fmt.Println("1. Named types and ~:")
+	temps := []Celsius{19.5, 23, 21.5}
+	fmt.Printf("Mean(temps): %v (a %T, so String still works)\n", Mean(temps), Mean(temps))
+	fmt.Printf("Mean of float64s: %v\n", Mean([]float64{1, 2, 3, 4}))
+	fmt.Println("Without ~, Mean(temps) doesn't compile: see the README")
+	fmt.Println()
+
+	// Example 2: A type set and a method in one constraint
+	fmt.Println("2. Type sets with methods:")
+	fmt.Println(Warmest(temps))
+	fmt.Println("Warmest([]float64{...}) doesn't compile: float64 has no String method")
+	fmt.Println()
+
+	// Example 3: comparable accepts interfaces, and they can panic
+	fmt.Println("3. The comparable trap:")
+	words := []any{"go", 42, 3.14}
+	fmt.Printf("Index(words, 42): %d\n", Index(words, any(42)))
+
+	// Different dynamic types are just unequal, but two []int panic
+	mixed := []any{"go", []int{1, 2}, 42}
+	i, err := safeIndex(mixed, any(42))
+	fmt.Printf("Index(mixed, 42): %d, %v\n", i, err)
+	i, err = safeIndex(mixed, any([]int{1, 2}))
+	fmt.Printf("Index(mixed, []int{1, 2}): %d, %v\n", i, err)
+	fmt.Println()
+
+	// Example 4: Converting between type parameters
+	fmt.Println("4. Conversions:")
+	fmt.Printf("Convert[int](Celsius(21.9)): %d (truncated)\n", Convert[int](Celsius(21.9)))
+	fmt.Printf("Convert[int8](300): %d (overflowed)\n", Convert[int8](300))
+	fmt.Printf("Convert[Celsius](25): %v\n", Convert[Celsius](25))
+	fmt.Println()
+
+	// Example 5: Type switches need any
+	fmt.Println("5. Switching on a type parameter:")
+	fmt.Println(describe(Celsius(18)))
+	fmt.Println(describe(7))
+	fmt.Println(describe(uint8(255)))
+}
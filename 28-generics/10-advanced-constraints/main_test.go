@@ -0,0 +1,166 @@
+package main
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"strings"
+	"testing"
+)
+
+// typeCheck compiles src as far as the type checker, and returns its
+// first error, or nil.
+func typeCheck(src string) error {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, "snippet.go", "package p\n"+src, 0)
+	if err != nil {
+		return err
+	}
+	conf := types.Config{GoVersion: "go1.25"} // the version in go.mod
+	_, err = conf.Check("p", fset, []*ast.File{f}, nil)
+	return err
+}
+
+// The snippets from the README: each one pairs code that doesn't compile
+// with a fix that does.
+var snippets = []struct {
+	name    string
+	broken  string
+	wantErr string
+	fixed   string
+}{
+	{
+		name: "named type without ~",
+		broken: `
+type Celsius float64
+type Float interface{ float32 | float64 }
+func Mean[T Float](nums []T) T { return nums[0] }
+var _ = Mean([]Celsius{1})`,
+		wantErr: "possibly missing ~ for float64 in Float",
+		fixed: `
+type Celsius float64
+type Float interface{ ~float32 | ~float64 }
+func Mean[T Float](nums []T) T { return nums[0] }
+var _ = Mean([]Celsius{1})`,
+	},
+	{
+		name: "type set as a variable type",
+		broken: `
+type Number interface{ ~int | ~float64 }
+var total Number`,
+		wantErr: "cannot use type Number outside a type constraint",
+		fixed: `
+type Number interface{ ~int | ~float64 }
+func Total[T Number](nums []T) (total T) { return }`,
+	},
+	{
+		name: "method on a constraint",
+		broken: `
+type Number interface{ ~int | ~float64 }
+func (n Number) Double() Number { return n * 2 }`,
+		wantErr: "cannot use type Number outside a type constraint",
+		fixed: `
+type Number interface{ ~int | ~float64 }
+func Double[T Number](n T) T { return n * 2 }`,
+	},
+	{
+		name: "method with its own type parameter before Go 1.27",
+		broken: `
+type Box[T any] struct{ v T }
+func (b Box[T]) Map[U any](fn func(T) U) Box[U] { return Box[U]{fn(b.v)} }`,
+		wantErr: "generic method requires go1.27 or later",
+		fixed: `
+type Box[T any] struct{ v T }
+func Map[T, U any](b Box[T], fn func(T) U) Box[U] { return Box[U]{fn(b.v)} }`,
+	},
+	{
+		name: "interface with methods in a union",
+		broken: `
+type Stringer interface{ String() string }
+type IntOrStringer interface{ int | Stringer }`,
+		wantErr: "cannot use p.Stringer in union (p.Stringer contains methods)",
+		fixed: `
+type Stringer interface{ String() string }
+type StringInt interface{ ~int; Stringer }`,
+	},
+	{
+		name: "method missing from a type set",
+		broken: `
+type Temperature interface{ ~float64; String() string }
+func Warmest[T Temperature](temps []T) T { return temps[0] }
+var _ = Warmest([]float64{1})`,
+		wantErr: "T (type float64) does not satisfy Temperature (missing method String)",
+		fixed: `
+type Celsius float64
+func (c Celsius) String() string { return "" }
+type Temperature interface{ ~float64; String() string }
+func Warmest[T Temperature](temps []T) T { return temps[0] }
+var _ = Warmest([]Celsius{1})`,
+	},
+	{
+		name: "conversion that isn't valid for every pair",
+		broken: `
+func Convert[To ~string, From ~float64](v From) To { return To(v) }`,
+		wantErr: "cannot convert v",
+		fixed: `
+func Convert[To ~int | ~float64, From ~float64](v From) To { return To(v) }`,
+	},
+	{
+		name: "type switch on a type parameter",
+		broken: `
+func describe[T any](v T) string {
+	switch v.(type) {
+	case int:
+		return "int"
+	}
+	return "other"
+}`,
+		wantErr: "cannot use type switch on type parameter value v",
+		fixed: `
+func describe[T any](v T) string {
+	switch any(v).(type) {
+	case int:
+		return "int"
+	}
+	return "other"
+}`,
+	},
+}
+
+func TestSnippets(t *testing.T) {
+	for _, s := range snippets {
+		t.Run(s.name, func(t *testing.T) {
+			err := typeCheck(s.broken)
+			if err == nil || !strings.Contains(err.Error(), s.wantErr) {
+				t.Errorf("broken: want an error containing %q; got %v", s.wantErr, err)
+			}
+			if err := typeCheck(s.fixed); err != nil {
+				t.Errorf("fixed: want no error; got %v", err)
+			}
+		})
+	}
+}
+
+func TestComparableTrap(t *testing.T) {
+	mixed := []any{"go", []int{1, 2}, 42}
+
+	if i, err := safeIndex(mixed, any(42)); err != nil || i != 2 {
+		t.Errorf("want 2, nil for different dynamic types; got %d, %v", i, err)
+	}
+	if _, err := safeIndex(mixed, any([]int{1, 2})); err == nil {
+		t.Error("want comparing two []int inside any to panic")
+	}
+}
+
+func TestConvert(t *testing.T) {
+	if got := Convert[int](Celsius(21.9)); got != 21 {
+		t.Errorf("want 21; got %d", got)
+	}
+	if got := Convert[int8](300); got != 44 {
+		t.Errorf("want 300 to wrap to 44; got %d", got)
+	}
+	if got := Mean([]Celsius{20, 22}); got != Celsius(21) {
+		t.Errorf("want 21°C; got %v", got)
+	}
+}
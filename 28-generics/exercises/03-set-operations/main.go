@@ -0,0 +1,65 @@
+package main
+
+import "fmt"
+
+/*
+EXERCISE: Set Operations
+
+Build a small generic Set and add the operations that
+pkg/generics.Set does not have:
+
+1. Set[T comparable] backed by map[T]struct{}, with
+   NewSet(items ...T), Contains(item T) bool, Len() int,
+   and Iter() iter.Seq[T]
+
+2. SymmetricDifference(other *Set[T]) *Set[T]
+   - Returns a new set with the items that are in exactly one of the sets
+
+3. IsSubsetOf(other *Set[T]) bool
+   - Reports whether every item of the set is also in other
+
+4. Equal(other *Set[T]) bool
+   - Reports whether both sets hold the same items
+
+5. Sorted[T cmp.Ordered](s *Set[T]) []T
+   - Returns the items as a sorted slice, for printing
+
+Requirements:
+- Operations must not change the sets they are called on
+- The empty set is a subset of every set
+- Equal ignores the order the items were added in
+
+Hints:
+- maps.Keys returns an iter.Seq over a map's keys
+- Sorted can't be a method: comparable doesn't allow <
+- A bigger set can never be a subset of a smaller one
+*/
+
+func main() {
+	fmt.Println("Set Operations Exercise")
+	fmt.Println("=======================")
+	fmt.Println()
+
+	// Test 1: Symmetric difference
+	fmt.Println("Test 1: Symmetric Difference")
+	// TODO: monday := NewSet("ann", "bob", "cem")
+	// TODO: tuesday := NewSet("bob", "cem", "dan")
+	// TODO: Print who came on only one day
+	fmt.Println()
+
+	// Test 2: Subsets
+	fmt.Println("Test 2: Subsets")
+	// TODO: required := NewSet("read", "write")
+	// TODO: granted := NewSet("read", "write", "admin")
+	// TODO: Check both directions, and the empty set
+	fmt.Println()
+
+	// Test 3: Equality
+	fmt.Println("Test 3: Equality")
+	// TODO: Compare NewSet(1, 2, 3) with NewSet(3, 2, 1, 1) and NewSet(1, 2, 4)
+}
+
+// TODO: Implement the Set type and its methods below
+// type Set[T comparable] struct {
+//     ...
+// }
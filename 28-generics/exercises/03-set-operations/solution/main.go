@@ -0,0 +1,109 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Set is a collection of unique values
+type Set[T comparable] struct {
+	items map[T]struct{}
+}
+
+// NewSet returns a set that holds items
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{items: make(map[T]struct{}, len(items))}
+	for _, item := range items {
+		s.items[item] = struct{}{}
+	}
+	return s
+}
+
+// Contains reports whether item is in the set
+func (s *Set[T]) Contains(item T) bool {
+	_, ok := s.items[item]
+	return ok
+}
+
+// Len returns the number of items
+func (s *Set[T]) Len() int {
+	return len(s.items)
+}
+
+// Iter returns an iterator over the items
+func (s *Set[T]) Iter() iter.Seq[T] {
+	return maps.Keys(s.items)
+}
+
+// SymmetricDifference returns the items that are in exactly one of the sets
+func (s *Set[T]) SymmetricDifference(other *Set[T]) *Set[T] {
+	d := NewSet[T]()
+	for item := range s.items {
+		if !other.Contains(item) {
+			d.items[item] = struct{}{}
+		}
+	}
+	for item := range other.items {
+		if !s.Contains(item) {
+			d.items[item] = struct{}{}
+		}
+	}
+	return d
+}
+
+// IsSubsetOf reports whether every item of s is also in other
+func (s *Set[T]) IsSubsetOf(other *Set[T]) bool {
+	// A bigger set can't fit inside a smaller one
+	if s.Len() > other.Len() {
+		return false
+	}
+	for item := range s.items {
+		if !other.Contains(item) {
+			return false
+		}
+	}
+	return true
+}
+
+// Equal reports whether both sets hold the same items
+func (s *Set[T]) Equal(other *Set[T]) bool {
+	return s.Len() == other.Len() && s.IsSubsetOf(other)
+}
+
+// Sorted returns the items as a sorted slice
+func Sorted[T cmp.Ordered](s *Set[T]) []T {
+	return slices.Sorted(s.Iter())
+}
+
+func main() {
+	fmt.Println("Set Operations Exercise - Solution")
+	fmt.Println("==================================")
+	fmt.Println()
+
+	// Test 1: Symmetric difference
+	fmt.Println("Test 1: Symmetric Difference")
+	monday := NewSet("ann", "bob", "cem")
+	tuesday := NewSet("bob", "cem", "dan")
+	fmt.Printf("Came on only one day: %v\n", Sorted(monday.SymmetricDifference(tuesday)))
+	fmt.Println()
+
+	// Test 2: Subsets
+	fmt.Println("Test 2: Subsets")
+	required := NewSet("read", "write")
+	granted := NewSet("read", "write", "admin")
+	fmt.Printf("%v subset of %v: %v\n", Sorted(required), Sorted(granted), required.IsSubsetOf(granted))
+	fmt.Printf("%v subset of %v: %v\n", Sorted(granted), Sorted(required), granted.IsSubsetOf(required))
+	fmt.Printf("Empty set subset of anything: %v\n", NewSet[string]().IsSubsetOf(required))
+	fmt.Println()
+
+	// Test 3: Equality ignores order and duplicates
+	fmt.Println("Test 3: Equality")
+	a := NewSet(1, 2, 3)
+	b := NewSet(3, 2, 1, 1)
+	c := NewSet(1, 2, 4)
+	fmt.Printf("%v == %v: %v\n", Sorted(a), Sorted(b), a.Equal(b))
+	fmt.Printf("%v == %v: %v\n", Sorted(a), Sorted(c), a.Equal(c))
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+)
+
+/*
+EXERCISE: Eviction Callback
+
+The LRU cache below works, but entries disappear silently. Add a
+callback that runs whenever an entry leaves the cache, so that
+programs can log evictions or release resources, like closing a
+connection that was cached.
+
+1. Add a Reason type with two values:
+   - Capacity: the entry was evicted to make room
+   - Deleted: the entry was removed with Delete
+   Give it a String method that returns "capacity" or "deleted"
+
+2. Add OnEvict(fn func(key K, value V, reason Reason)) to LRU
+
+3. Call the callback from Put (when it evicts) and Delete
+
+Requirements:
+- Replacing the value of an existing key is not an eviction
+- Call the callback AFTER the entry was removed from both the
+  map and the list, so the callback sees a consistent cache
+- A cache without a callback must work as before
+
+Hint:
+- Both Put and Delete remove entries: move that into one
+  remove(el *list.Element, reason Reason) method
+*/
+
+// entry is what the list holds
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// LRU is a small least-recently-used cache
+type LRU[K comparable, V any] struct {
+	capacity int
+	items    map[K]*list.Element
+	order    *list.List // front is the most recent
+	// TODO: Add a field for the callback
+}
+
+// NewLRU returns an empty cache that holds at most capacity entries
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// Get returns the value for key and marks it as the most recent
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*entry[K, V]).value, true
+}
+
+// Put adds or replaces the value for key
+func (c *LRU[K, V]) Put(key K, value V) {
+	if el, ok := c.items[key]; ok {
+		el.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		// TODO: Report this eviction
+		oldest := c.order.Remove(c.order.Back()).(*entry[K, V])
+		delete(c.items, oldest.key)
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key, value})
+}
+
+// Delete removes key, and reports whether it was there
+func (c *LRU[K, V]) Delete(key K) bool {
+	el, ok := c.items[key]
+	if ok {
+		// TODO: Report this removal
+		c.order.Remove(el)
+		delete(c.items, key)
+	}
+	return ok
+}
+
+func main() {
+	fmt.Println("Eviction Callback Exercise")
+	fmt.Println("==========================")
+	fmt.Println()
+
+	// Test 1: Log every eviction
+	fmt.Println("Test 1: Logging Evictions")
+	// TODO: Create an LRU[string, int] of capacity 2 that prints
+	//       "evicted <key>=<value> (<reason>)"
+	// TODO: Put a, b; Get a; Put c (evicts b); Put a again; Delete a
+	fmt.Println()
+
+	// Test 2: Release resources that leave the cache
+	fmt.Println("Test 2: Closing Connections")
+	// TODO: Cache *Conn values with capacity 1, and mark a Conn as
+	//       Closed when it leaves the cache
+	fmt.Println()
+
+	// Test 3: Keep what falls out of a small cache in a bigger one
+	fmt.Println("Test 3: Moving Evicted Entries to an Archive")
+	// TODO: On Capacity evictions from a cache of 1, Put the entry
+	//       into a second, bigger cache
+}
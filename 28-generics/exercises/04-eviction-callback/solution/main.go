@@ -0,0 +1,148 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+)
+
+// Reason says why an entry left the cache
+type Reason int
+
+const (
+	Capacity Reason = iota // evicted to make room
+	Deleted                // removed with Delete
+)
+
+func (r Reason) String() string {
+	if r == Capacity {
+		return "capacity"
+	}
+	return "deleted"
+}
+
+// entry is what the list holds
+type entry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// LRU is a small least-recently-used cache
+type LRU[K comparable, V any] struct {
+	capacity int
+	items    map[K]*list.Element
+	order    *list.List // front is the most recent
+	onEvict  func(key K, value V, reason Reason)
+}
+
+// NewLRU returns an empty cache that holds at most capacity entries
+func NewLRU[K comparable, V any](capacity int) *LRU[K, V] {
+	return &LRU[K, V]{
+		capacity: capacity,
+		items:    make(map[K]*list.Element),
+		order:    list.New(),
+	}
+}
+
+// OnEvict sets fn to be called after an entry leaves the cache
+func (c *LRU[K, V]) OnEvict(fn func(key K, value V, reason Reason)) {
+	c.onEvict = fn
+}
+
+// Get returns the value for key and marks it as the most recent
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	el, ok := c.items[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+	c.order.MoveToFront(el)
+	return el.Value.(*entry[K, V]).value, true
+}
+
+// Put adds or replaces the value for key
+func (c *LRU[K, V]) Put(key K, value V) {
+	if el, ok := c.items[key]; ok {
+		// Replacing is not an eviction: the key stays
+		el.Value.(*entry[K, V]).value = value
+		c.order.MoveToFront(el)
+		return
+	}
+
+	if c.order.Len() >= c.capacity {
+		c.remove(c.order.Back(), Capacity)
+	}
+	c.items[key] = c.order.PushFront(&entry[K, V]{key, value})
+}
+
+// Delete removes key, and reports whether it was there
+func (c *LRU[K, V]) Delete(key K) bool {
+	el, ok := c.items[key]
+	if ok {
+		c.remove(el, Deleted)
+	}
+	return ok
+}
+
+// remove takes el out of the cache first, and calls the callback last,
+// so the callback sees a consistent cache and may even use it
+func (c *LRU[K, V]) remove(el *list.Element, reason Reason) {
+	e := c.order.Remove(el).(*entry[K, V])
+	delete(c.items, e.key)
+
+	if c.onEvict != nil {
+		c.onEvict(e.key, e.value, reason)
+	}
+}
+
+func main() {
+	fmt.Println("Eviction Callback Exercise - Solution")
+	fmt.Println("=====================================")
+	fmt.Println()
+
+	// Test 1: Log every eviction
+	fmt.Println("Test 1: Logging Evictions")
+	c := NewLRU[string, int](2)
+	c.OnEvict(func(key string, value int, reason Reason) {
+		fmt.Printf("evicted %s=%d (%v)\n", key, value, reason)
+	})
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Get("a")
+	c.Put("c", 3) // evicts b
+	c.Put("a", 10)
+	c.Delete("a")
+	fmt.Println()
+
+	// Test 2: Release resources that leave the cache
+	fmt.Println("Test 2: Closing Connections")
+	type Conn struct {
+		Host   string
+		Closed bool
+	}
+	var closed []string
+	conns := NewLRU[string, *Conn](1)
+	conns.OnEvict(func(host string, conn *Conn, _ Reason) {
+		conn.Closed = true
+		closed = append(closed, host)
+	})
+
+	db := &Conn{Host: "db"}
+	conns.Put("db", db)
+	conns.Put("cache", &Conn{Host: "cache"})
+	fmt.Printf("db closed: %v, closed so far: %v\n", db.Closed, closed)
+	fmt.Println()
+
+	// Test 3: Keep what falls out of a small cache in a bigger one
+	fmt.Println("Test 3: Moving Evicted Entries to an Archive")
+	archive := NewLRU[int, string](10)
+	recent := NewLRU[int, string](1)
+	recent.OnEvict(func(k int, v string, reason Reason) {
+		if reason == Capacity {
+			archive.Put(k, v)
+		}
+	})
+	recent.Put(1, "one")
+	recent.Put(2, "two")
+	v, ok := archive.Get(1)
+	fmt.Printf("archive has 1: %q %v\n", v, ok)
+}
@@ -0,0 +1,68 @@
+package main
+
+import "fmt"
+
+/*
+EXERCISE: Option Type
+
+Build an Option[T] that holds a value or nothing, and learn
+where generic methods stop and generic functions begin.
+
+1. Option[T any] struct with two fields: the value and a bool
+
+2. Constructors: Some(v T) Option[T] and None[T]() Option[T]
+
+3. Methods:
+   - IsSome() bool
+   - Get() (T, bool)
+   - UnwrapOr(fallback T) T
+   - OrElse(other Option[T]) Option[T]
+   - Filter(keep func(T) bool) Option[T]
+   - String() string: "Some(42)" or "None"
+
+4. A FUNCTION: Map[T, U any](o Option[T], fn func(T) U) Option[U]
+
+Requirements:
+- The zero value, var o Option[int], must be None
+- Some("") must be Some: an empty string is still a value
+- Map and Filter must not call fn on None
+
+Questions to answer for yourself:
+- Why can Filter be a method, but Map can't?
+- Why is the bool needed? Couldn't a zero T mean "nothing"?
+*/
+
+// User is what the examples look up
+type User struct {
+	Name  string
+	Email string
+}
+
+var users = map[int]User{
+	1: {"Ann", "ann@example.com"},
+	2: {"Bob", ""},
+}
+
+func main() {
+	fmt.Println("Option Type Exercise")
+	fmt.Println("====================")
+	fmt.Println()
+
+	// Test 1: Some, None, and the zero value
+	fmt.Println("Test 1: Building Options")
+	// TODO: Print Some(42), None[int](), a zero Option[string], and Some("")
+	fmt.Println()
+
+	// Test 2: Map and Filter
+	fmt.Println("Test 2: Map and Filter")
+	// TODO: Write findUser(id int) Option[User]
+	// TODO: For ids 1, 2, 3: Map each user to their email, and Filter
+	//       out emails without an "@"
+	fmt.Println()
+
+	// Test 3: Fallbacks
+	fmt.Println("Test 3: Fallbacks")
+	// TODO: Fall back to a guest User with UnwrapOr, and to user 1 with OrElse
+}
+
+// TODO: Implement Option[T] below
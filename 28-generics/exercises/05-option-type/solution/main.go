@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Option holds a value or nothing
+type Option[T any] struct {
+	value T
+	ok    bool
+}
+
+// Some returns an Option that holds v
+func Some[T any](v T) Option[T] {
+	return Option[T]{value: v, ok: true}
+}
+
+// None returns an empty Option
+func None[T any]() Option[T] {
+	return Option[T]{} // the zero value is already None
+}
+
+// IsSome reports whether o holds a value
+func (o Option[T]) IsSome() bool {
+	return o.ok
+}
+
+// Get returns the value and whether there is one
+func (o Option[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// UnwrapOr returns the value, or fallback
+func (o Option[T]) UnwrapOr(fallback T) T {
+	if !o.ok {
+		return fallback
+	}
+	return o.value
+}
+
+// OrElse returns o if it holds a value, and other if it doesn't
+func (o Option[T]) OrElse(other Option[T]) Option[T] {
+	if o.ok {
+		return o
+	}
+	return other
+}
+
+// Filter keeps the value only if keep returns true. It is a method:
+// it keeps T, so it needs no type parameter of its own.
+func (o Option[T]) Filter(keep func(T) bool) Option[T] {
+	if o.ok && keep(o.value) {
+		return o
+	}
+	return None[T]()
+}
+
+// String prints Some(value) or None
+func (o Option[T]) String() string {
+	if !o.ok {
+		return "None"
+	}
+	return fmt.Sprintf("Some(%v)", o.value)
+}
+
+// Map changes the value's type from T to U, so it must be a function
+func Map[T, U any](o Option[T], fn func(T) U) Option[U] {
+	if !o.ok {
+		return None[U]()
+	}
+	return Some(fn(o.value))
+}
+
+// User is what the examples look up
+type User struct {
+	Name  string
+	Email string
+}
+
+var users = map[int]User{
+	1: {"Ann", "ann@example.com"},
+	2: {"Bob", ""},
+}
+
+// findUser returns Some(user) or None
+func findUser(id int) Option[User] {
+	u, ok := users[id]
+	if !ok {
+		return None[User]()
+	}
+	return Some(u)
+}
+
+func main() {
+	fmt.Println("Option Type Exercise - Solution")
+	fmt.Println("===============================")
+	fmt.Println()
+
+	// Test 1: Some, None, and the zero value
+	fmt.Println("Test 1: Building Options")
+	var zero Option[string]
+	fmt.Printf("Some(42): %v\n", Some(42))
+	fmt.Printf("None[int](): %v\n", None[int]())
+	fmt.Printf("var zero Option[string]: %v\n", zero)
+	fmt.Printf("Some(\"\"): %v, IsSome: %v\n", Some(""), Some("").IsSome())
+	fmt.Println()
+
+	// Test 2: Map and Filter
+	fmt.Println("Test 2: Map and Filter")
+	for _, id := range []int{1, 2, 3} {
+		email := Map(findUser(id), func(u User) string { return u.Email }).
+			Filter(func(e string) bool { return strings.Contains(e, "@") })
+		fmt.Printf("user %d email: %v\n", id, email)
+	}
+	fmt.Println()
+
+	// Test 3: Fallbacks
+	fmt.Println("Test 3: Fallbacks")
+	guest := User{Name: "guest"}
+	fmt.Printf("user 3 or guest: %s\n", findUser(3).UnwrapOr(guest).Name)
+	fmt.Printf("user 3, else user 1: %v\n", findUser(3).OrElse(findUser(1)))
+}
@@ -0,0 +1,74 @@
+package main
+
+import "fmt"
+
+/*
+EXERCISE: Generic Binary Search Tree
+
+Build a BST[T] that keeps its values in order, using a comparator
+function instead of a constraint.
+
+1. BST[T any] struct: a root node, a compare func(a, b T) int, and a size
+   - Each node holds a value and left and right children
+   - Smaller values go left, larger values go right
+
+2. NewBST[T any](compare func(a, b T) int) *BST[T]
+   - compare works like cmp.Compare: negative, zero, or positive
+
+3. Methods:
+   - Insert(v T) bool: false if an equal value is already there
+   - Contains(v T) bool
+   - Delete(v T) bool: false if v wasn't there
+   - Len() int
+   - Min() (T, bool) and Max() (T, bool): false on an empty tree
+   - All() iter.Seq[T]: the values in order, smallest first
+   - Height() int: the number of nodes on the longest path
+
+Requirements:
+- "Equal" means compare returns 0, even if the values differ otherwise
+- Deleting a node with two children must keep the tree in order
+- A break in a range over All() must stop the walk
+- No rebalancing: keep it simple
+
+Questions to answer for yourself:
+- Why take a comparator instead of T cmp.Ordered?
+- What happens to the height when values are inserted in sorted order?
+*/
+
+// Book is ordered by title with a custom comparator
+type Book struct {
+	Title string
+	Year  int
+}
+
+func main() {
+	fmt.Println("Generic BST Exercise")
+	fmt.Println("====================")
+	fmt.Println()
+
+	// Test 1: Insert, Contains, Min, and Max
+	fmt.Println("Test 1: Numbers")
+	// TODO: Insert 50, 30, 70, 20, 40, 60, 80, 30 with cmp.Compare[int]
+	// TODO: Print the length, the values in order, Contains(40),
+	//       Contains(45), Min, and Max
+	fmt.Println()
+
+	// Test 2: Delete
+	fmt.Println("Test 2: Delete")
+	// TODO: Delete 20 (a leaf), 30 (one child), and 50 (the root, two
+	//       children), then print the values in order and Delete(99)
+	fmt.Println()
+
+	// Test 3: A custom comparator
+	fmt.Println("Test 3: Books by title")
+	// TODO: Order Books by title, ignoring case, and insert
+	//       "Learning Go" and "learning go": only one is added
+	fmt.Println()
+
+	// Test 4: No rebalancing
+	fmt.Println("Test 4: Height")
+	// TODO: Compare the height of 1-7 inserted as 4, 2, 6, 1, 3, 5, 7
+	//       with 1-7 inserted in order
+}
+
+// TODO: Implement BST[T] below
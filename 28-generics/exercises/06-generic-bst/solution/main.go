@@ -0,0 +1,237 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"iter"
+	"slices"
+	"strings"
+)
+
+// node is one value in the tree. Smaller values go left, larger right
+type node[T any] struct {
+	value       T
+	left, right *node[T]
+}
+
+// BST is a binary search tree ordered by a comparator function
+type BST[T any] struct {
+	root *node[T]
+	cmp  func(a, b T) int
+	size int
+}
+
+// NewBST returns an empty tree. compare returns a negative number when
+// a < b, zero when they are equal, and a positive number when a > b,
+// like cmp.Compare
+func NewBST[T any](compare func(a, b T) int) *BST[T] {
+	return &BST[T]{cmp: compare}
+}
+
+// Len returns the number of values in the tree
+func (t *BST[T]) Len() int {
+	return t.size
+}
+
+// Insert adds v, and reports whether it was added. A value equal to one
+// already in the tree is not added
+func (t *BST[T]) Insert(v T) bool {
+	link := &t.root // the pointer to change: root, or a left or right
+	for *link != nil {
+		c := t.cmp(v, (*link).value)
+		switch {
+		case c < 0:
+			link = &(*link).left
+		case c > 0:
+			link = &(*link).right
+		default:
+			return false
+		}
+	}
+	*link = &node[T]{value: v}
+	t.size++
+	return true
+}
+
+// Contains reports whether the tree holds a value equal to v
+func (t *BST[T]) Contains(v T) bool {
+	n := t.root
+	for n != nil {
+		c := t.cmp(v, n.value)
+		switch {
+		case c < 0:
+			n = n.left
+		case c > 0:
+			n = n.right
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+// Delete removes v, and reports whether it was in the tree
+func (t *BST[T]) Delete(v T) bool {
+	var deleted bool
+	t.root, deleted = t.delete(t.root, v)
+	if deleted {
+		t.size--
+	}
+	return deleted
+}
+
+// delete removes v from the subtree at n, and returns the subtree's new
+// root
+func (t *BST[T]) delete(n *node[T], v T) (*node[T], bool) {
+	if n == nil {
+		return nil, false
+	}
+
+	var deleted bool
+	c := t.cmp(v, n.value)
+	switch {
+	case c < 0:
+		n.left, deleted = t.delete(n.left, v)
+		return n, deleted
+	case c > 0:
+		n.right, deleted = t.delete(n.right, v)
+		return n, deleted
+	}
+
+	// n holds v. With zero or one child, the child takes its place
+	if n.left == nil {
+		return n.right, true
+	}
+	if n.right == nil {
+		return n.left, true
+	}
+
+	// With two children, the smallest value on the right takes its
+	// place: it is larger than everything on the left, and smaller than
+	// the rest of the right
+	successor := n.right
+	for successor.left != nil {
+		successor = successor.left
+	}
+	n.value = successor.value
+	n.right, _ = t.delete(n.right, successor.value)
+	return n, true
+}
+
+// Min returns the smallest value, or false if the tree is empty
+func (t *BST[T]) Min() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	n := t.root
+	for n.left != nil {
+		n = n.left
+	}
+	return n.value, true
+}
+
+// Max returns the largest value, or false if the tree is empty
+func (t *BST[T]) Max() (T, bool) {
+	if t.root == nil {
+		var zero T
+		return zero, false
+	}
+	n := t.root
+	for n.right != nil {
+		n = n.right
+	}
+	return n.value, true
+}
+
+// All returns an iterator over the values in order, smallest first
+func (t *BST[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		t.root.walk(yield)
+	}
+}
+
+// walk calls yield on the subtree's values in order. It returns false
+// as soon as yield does, so a break in the range loop stops the walk
+func (n *node[T]) walk(yield func(T) bool) bool {
+	if n == nil {
+		return true
+	}
+	return n.left.walk(yield) && yield(n.value) && n.right.walk(yield)
+}
+
+// Height returns the number of nodes on the longest path from the root
+func (t *BST[T]) Height() int {
+	return t.root.height()
+}
+
+func (n *node[T]) height() int {
+	if n == nil {
+		return 0
+	}
+	return 1 + max(n.left.height(), n.right.height())
+}
+
+// Book is ordered by title with a custom comparator
+type Book struct {
+	Title string
+	Year  int
+}
+
+func main() {
+	fmt.Println("Generic BST Exercise - Solution")
+	fmt.Println("===============================")
+	fmt.Println()
+
+	// Test 1: Insert, Contains, Min, and Max
+	fmt.Println("Test 1: Numbers")
+	nums := NewBST(cmp.Compare[int])
+	for _, n := range []int{50, 30, 70, 20, 40, 60, 80, 30} {
+		if !nums.Insert(n) {
+			fmt.Printf("Insert(%d): already in the tree\n", n)
+		}
+	}
+	fmt.Printf("Len: %d\n", nums.Len())
+	fmt.Printf("In order: %v\n", slices.Collect(nums.All()))
+	fmt.Printf("Contains(40): %v, Contains(45): %v\n", nums.Contains(40), nums.Contains(45))
+	lo, _ := nums.Min()
+	hi, _ := nums.Max()
+	fmt.Printf("Min: %d, Max: %d\n", lo, hi)
+	fmt.Println()
+
+	// Test 2: Delete a leaf, a node with one child, and one with two
+	fmt.Println("Test 2: Delete")
+	nums.Delete(20) // a leaf
+	nums.Delete(30) // now has one child, 40
+	nums.Delete(50) // the root, with two children
+	fmt.Printf("After deleting 20, 30, 50: %v\n", slices.Collect(nums.All()))
+	fmt.Printf("Delete(99): %v\n", nums.Delete(99))
+	fmt.Println()
+
+	// Test 3: A custom comparator
+	fmt.Println("Test 3: Books by title")
+	books := NewBST(func(a, b Book) int {
+		return strings.Compare(strings.ToLower(a.Title), strings.ToLower(b.Title))
+	})
+	books.Insert(Book{"The Go Programming Language", 2015})
+	books.Insert(Book{"Concurrency in Go", 2017})
+	books.Insert(Book{"Learning Go", 2021})
+	books.Insert(Book{"learning go", 2024}) // equal to the comparator: not added
+	for b := range books.All() {
+		fmt.Printf("%s (%d)\n", b.Title, b.Year)
+	}
+	fmt.Println()
+
+	// Test 4: No rebalancing
+	fmt.Println("Test 4: Height")
+	random := NewBST(cmp.Compare[int])
+	sorted := NewBST(cmp.Compare[int])
+	for _, n := range []int{4, 2, 6, 1, 3, 5, 7} {
+		random.Insert(n)
+	}
+	for n := 1; n <= 7; n++ {
+		sorted.Insert(n)
+	}
+	fmt.Printf("7 values, mixed order:  height %d\n", random.Height())
+	fmt.Printf("7 values, sorted order: height %d (a linked list)\n", sorted.Height())
+}
@@ -0,0 +1,199 @@
+package main
+
+import (
+	"cmp"
+	"math/rand/v2"
+	"slices"
+	"testing"
+)
+
+func newInts(values ...int) *BST[int] {
+	t := NewBST(cmp.Compare[int])
+	for _, v := range values {
+		t.Insert(v)
+	}
+	return t
+}
+
+func TestEmptyTree(t *testing.T) {
+	tree := newInts()
+
+	if tree.Len() != 0 || tree.Height() != 0 {
+		t.Errorf("want len 0 and height 0; got %d and %d", tree.Len(), tree.Height())
+	}
+	if tree.Contains(1) {
+		t.Error("want Contains false")
+	}
+	if tree.Delete(1) {
+		t.Error("want Delete false")
+	}
+	if _, ok := tree.Min(); ok {
+		t.Error("want Min false")
+	}
+	if _, ok := tree.Max(); ok {
+		t.Error("want Max false")
+	}
+	for v := range tree.All() {
+		t.Errorf("want no values; got %d", v)
+	}
+}
+
+func TestSingleNode(t *testing.T) {
+	tree := newInts(7)
+
+	lo, _ := tree.Min()
+	hi, _ := tree.Max()
+	if lo != 7 || hi != 7 {
+		t.Errorf("want Min and Max 7; got %d and %d", lo, hi)
+	}
+	if !tree.Delete(7) {
+		t.Fatal("want the root deleted")
+	}
+	if tree.Len() != 0 || tree.Contains(7) {
+		t.Error("want an empty tree after deleting the only node")
+	}
+	if !tree.Insert(7) {
+		t.Error("want the tree usable after emptying it")
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	tree := newInts(5, 3, 8)
+
+	if tree.Insert(3) {
+		t.Error("want Insert of a duplicate to return false")
+	}
+	if tree.Len() != 3 {
+		t.Errorf("want len 3; got %d", tree.Len())
+	}
+	if got := slices.Collect(tree.All()); !slices.Equal(got, []int{3, 5, 8}) {
+		t.Errorf("want [3 5 8]; got %v", got)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	//        50
+	//      /    \
+	//    30      70
+	//   /  \    /
+	//  20  40  60
+	tests := []struct {
+		name   string
+		delete int
+		want   []int
+	}{
+		{"leaf", 20, []int{30, 40, 50, 60, 70}},
+		{"one child", 70, []int{20, 30, 40, 50, 60}},
+		{"two children", 30, []int{20, 40, 50, 60, 70}},
+		{"root", 50, []int{20, 30, 40, 60, 70}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := newInts(50, 30, 70, 20, 40, 60)
+
+			if !tree.Delete(tt.delete) {
+				t.Fatalf("want %d deleted", tt.delete)
+			}
+			if got := slices.Collect(tree.All()); !slices.Equal(got, tt.want) {
+				t.Errorf("want %v; got %v", tt.want, got)
+			}
+			if tree.Len() != len(tt.want) {
+				t.Errorf("want len %d; got %d", len(tt.want), tree.Len())
+			}
+			if tree.Delete(tt.delete) {
+				t.Error("want a second Delete to return false")
+			}
+		})
+	}
+}
+
+func TestMinMax(t *testing.T) {
+	tree := newInts(50, 30, 70, 20, 80)
+
+	lo, _ := tree.Min()
+	hi, _ := tree.Max()
+	if lo != 20 || hi != 80 {
+		t.Errorf("want 20 and 80; got %d and %d", lo, hi)
+	}
+}
+
+func TestAllStops(t *testing.T) {
+	tree := newInts(4, 2, 6, 1, 3, 5, 7)
+
+	var got []int
+	for v := range tree.All() {
+		if v == 4 {
+			break
+		}
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("want [1 2 3]; got %v", got)
+	}
+}
+
+func TestComparator(t *testing.T) {
+	// Reversing the comparator reverses the order
+	desc := NewBST(func(a, b int) int { return cmp.Compare(b, a) })
+	for _, v := range []int{2, 3, 1} {
+		desc.Insert(v)
+	}
+	if got := slices.Collect(desc.All()); !slices.Equal(got, []int{3, 2, 1}) {
+		t.Errorf("want [3 2 1]; got %v", got)
+	}
+
+	// Values the comparator calls equal are duplicates
+	byYear := NewBST(func(a, b Book) int { return cmp.Compare(a.Year, b.Year) })
+	byYear.Insert(Book{"A", 2020})
+	if byYear.Insert(Book{"B", 2020}) {
+		t.Error("want a book from the same year treated as a duplicate")
+	}
+	if !byYear.Contains(Book{"anything", 2020}) {
+		t.Error("want Contains to use the comparator")
+	}
+}
+
+func TestHeight(t *testing.T) {
+	if h := newInts(4, 2, 6, 1, 3, 5, 7).Height(); h != 3 {
+		t.Errorf("want a balanced tree of 7 to have height 3; got %d", h)
+	}
+	if h := newInts(1, 2, 3, 4, 5, 6, 7).Height(); h != 7 {
+		t.Errorf("want sorted inserts to make height 7; got %d", h)
+	}
+}
+
+// TestRandom inserts and deletes random values, and compares the tree
+// with a sorted slice after every step
+func TestRandom(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	tree := newInts()
+	var model []int
+
+	for step := range 2000 {
+		v := r.IntN(100)
+		i, found := slices.BinarySearch(model, v)
+
+		if r.IntN(2) == 0 {
+			if added := tree.Insert(v); added == found {
+				t.Fatalf("step %d: Insert(%d) = %v, but in the tree: %v", step, v, added, found)
+			}
+			if !found {
+				model = slices.Insert(model, i, v)
+			}
+		} else {
+			if deleted := tree.Delete(v); deleted != found {
+				t.Fatalf("step %d: Delete(%d) = %v, but in the tree: %v", step, v, deleted, found)
+			}
+			if found {
+				model = slices.Delete(model, i, i+1)
+			}
+		}
+
+		if got := slices.Collect(tree.All()); !slices.Equal(got, model) {
+			t.Fatalf("step %d:\nwant %v\ngot  %v", step, model, got)
+		}
+		if tree.Len() != len(model) {
+			t.Fatalf("step %d: len %d; want %d", step, tree.Len(), len(model))
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import "fmt"
+
+/*
+EXERCISE: Units
+
+Write generic functions that work with named measurement types, and
+keep those types instead of turning everything into float64.
+
+1. Types: Celsius, Fahrenheit, and Meters (float64 underneath), each
+   with a Unit() string method ("°C", "°F", "m"); and Count (an int,
+   with no Unit method)
+
+2. Constraints:
+   - Number: every integer and float type, including named ones
+   - Measurement: a float64 underneath AND a Unit method
+
+3. Functions:
+   - Sum[T Number](nums []T) T
+   - Clamp[T cmp.Ordered](v, lo, hi T) T
+   - ConvertAll[To, From Number](s []From) []To
+   - Format[T Measurement](v T) string: "21.5 °C"
+   - ToFahrenheit(c Celsius) Fahrenheit: does this need generics?
+   - SafeEqual[T comparable](a, b T) (bool, error): recover the panic
+     from comparing two slices inside interfaces
+
+Requirements:
+- Sum([]Celsius{...}) must return a Celsius
+- Format(Count(3)) and Format(3.5) must NOT compile
+- SafeEqual[any]([]int{1}, []int{1}) must return an error, not crash
+
+Questions to answer for yourself:
+- What goes wrong in Sum([]Celsius{...}) if Number has no ~?
+- Why can ConvertAll convert any Number to any Number?
+*/
+
+func main() {
+	fmt.Println("Units Exercise")
+	fmt.Println("==============")
+	fmt.Println()
+
+	// Test 1: Named types keep their type
+	fmt.Println("Test 1: Sum and Clamp")
+	// TODO: Sum 18.5, 21, and 23.5 Celsius and print the result and its type
+	// TODO: Sum some Counts; Clamp 35°C between 10 and 30; Clamp a string
+	fmt.Println()
+
+	// Test 2: Conversions
+	fmt.Println("Test 2: Conversions")
+	// TODO: ConvertAll the temperatures to int, and Counts to Meters
+	// TODO: Convert 21°C to Fahrenheit
+	fmt.Println()
+
+	// Test 3: Formatting measurements
+	fmt.Println("Test 3: Format")
+	// TODO: Format Meters(42.195) and Fahrenheit(98.6)
+	fmt.Println()
+
+	// Test 4: The comparable trap
+	fmt.Println("Test 4: SafeEqual")
+	// TODO: Compare two Celsius, 1 and "1" as any, and two []int as any
+}
+
+// TODO: Implement the types, constraints, and functions below
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+)
+
+// Measurement types: each one is a float64 underneath
+type (
+	Celsius    float64
+	Fahrenheit float64
+	Meters     float64
+)
+
+func (Celsius) Unit() string    { return "°C" }
+func (Fahrenheit) Unit() string { return "°F" }
+func (Meters) Unit() string     { return "m" }
+
+// Count is an int underneath, with no Unit method
+type Count int
+
+// Number needs ~ so the named types above satisfy it
+type Number interface {
+	~int | ~int8 | ~int16 | ~int32 | ~int64 |
+		~uint | ~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// Measurement is a type set and a method: a float64 with a unit
+type Measurement interface {
+	~float64
+	Unit() string
+}
+
+// Sum returns a T, so the caller's named type survives
+func Sum[T Number](nums []T) T {
+	var total T
+	for _, n := range nums {
+		total += n
+	}
+	return total
+}
+
+// Clamp keeps v between lo and hi
+func Clamp[T cmp.Ordered](v, lo, hi T) T {
+	return min(max(v, lo), hi)
+}
+
+// ConvertAll converts every item: every Number converts to every other
+func ConvertAll[To, From Number](s []From) []To {
+	out := make([]To, len(s))
+	for i, v := range s {
+		out[i] = To(v)
+	}
+	return out
+}
+
+// Format uses the method from the constraint and float64 formatting
+// from the type set
+func Format[T Measurement](v T) string {
+	return fmt.Sprintf("%.1f %s", float64(v), v.Unit())
+}
+
+// ToFahrenheit only makes sense for Celsius, so it isn't generic
+func ToFahrenheit(c Celsius) Fahrenheit {
+	return Fahrenheit(c*9/5 + 32)
+}
+
+// SafeEqual compares a and b, and turns the panic from comparing two
+// uncomparable values inside interfaces into an error
+func SafeEqual[T comparable](a, b T) (equal bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("cannot compare: %v", r)
+		}
+	}()
+	return a == b, nil
+}
+
+func main() {
+	fmt.Println("Units Exercise - Solution")
+	fmt.Println("=========================")
+	fmt.Println()
+
+	// Test 1: Named types keep their type
+	fmt.Println("Test 1: Sum and Clamp")
+	temps := []Celsius{18.5, 21, 23.5}
+	total := Sum(temps)
+	fmt.Printf("Sum: %s (type %T)\n", Format(total), total)
+	fmt.Printf("Sum of Counts: %d\n", Sum([]Count{3, 4, 5}))
+	fmt.Printf("Clamp(35°C, 10, 30): %s\n", Format(Clamp[Celsius](35, 10, 30)))
+	fmt.Printf("Clamp(\"zebra\", \"a\", \"m\"): %s\n", Clamp("zebra", "a", "m"))
+	fmt.Println()
+
+	// Test 2: Conversions
+	fmt.Println("Test 2: Conversions")
+	fmt.Printf("ConvertAll[int](temps): %v (truncated)\n", ConvertAll[int](temps))
+	fmt.Printf("ConvertAll[Meters]([]Count{1, 2}): %v\n", ConvertAll[Meters]([]Count{1, 2}))
+	fmt.Printf("ToFahrenheit(21°C): %s\n", Format(ToFahrenheit(21)))
+	fmt.Println()
+
+	// Test 3: Formatting measurements
+	fmt.Println("Test 3: Format")
+	fmt.Println(Format(Meters(42.195)))
+	fmt.Println(Format(Fahrenheit(98.6)))
+	// Format(Count(3)) and Format(3.5) don't compile: no Unit method
+	fmt.Println()
+
+	// Test 4: The comparable trap
+	fmt.Println("Test 4: SafeEqual")
+	fmt.Println(SafeEqual(Celsius(21), Celsius(21)))
+	fmt.Println(SafeEqual[any](1, "1"))
+	fmt.Println(SafeEqual[any]([]int{1}, []int{1}))
+}
@@ -3,6 +3,8 @@ package main
 import (
 	"fmt"
 	"time"
+
+	"github.com/inancgumus/learngo/pkg/pipeline"
 )
 
 func main() {
@@ -99,7 +101,8 @@ func main() {
 	worker2 := square(input)
 
 	// Fan-in: merge results from multiple workers
-	results := merge(worker1, worker2)
+	// (pipeline.Merge closes results after BOTH workers are done)
+	results := pipeline.Merge(worker1, worker2)
 
 	fmt.Print("   Results: ")
 	for result := range results {
@@ -162,27 +165,6 @@ func square(in <-chan int) <-chan int {
 	return out
 }
 
-// merge combines multiple channels into one
-func merge(channels ...<-chan int) <-chan int {
-	out := make(chan int)
-
-	for _, ch := range channels {
-		go func(c <-chan int) {
-			for v := range c {
-				out <- v
-			}
-		}(ch)
-	}
-
-	// Close output channel when all inputs are done
-	go func() {
-		time.Sleep(100 * time.Millisecond) // Simple synchronization for demo
-		close(out)
-	}()
-
-	return out
-}
-
 // sendOnly demonstrates send-only channel parameter
 func sendOnly(ch chan<- int) {
 	ch <- 99
@@ -1,9 +1,16 @@
 package main
 
 import (
+	"bytes"
+	"flag"
 	"fmt"
+	"runtime"
+	"strings"
 	"sync"
+	"testing"
 	"time"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
 )
 
 // Counter demonstrates unsafe concurrent access
@@ -130,70 +137,67 @@ func rwMutexExample() {
 	wg.Wait()
 }
 
-// performanceComparison compares Mutex vs RWMutex
+// performanceComparison compares Mutex vs RWMutex with testing.Benchmark.
+// Timing one run of a thousand goroutines with time.Since measured
+// starting the goroutines more than the locks, and changed from run to
+// run. main_test.go has the same benchmarks for go test -bench, and
+// 37-performance/04-counter-benchmarks compares more kinds of counter
 func performanceComparison() {
-	const numReads = 1000
-	const numWrites = 10
-
-	// Test with Mutex
-	mutexCounter := &SafeCounter{}
-	mutexStart := time.Now()
-	var wg1 sync.WaitGroup
-
-	for i := 0; i < numReads; i++ {
-		wg1.Add(1)
-		go func() {
-			defer wg1.Done()
-			mutexCounter.mu.Lock()
-			_ = mutexCounter.value
-			mutexCounter.mu.Unlock()
-		}()
+	testing.Init() // makes -test.benchtime settable
+	flag.Set("test.benchtime", "200ms")
+
+	var results []benchutil.Result
+	for _, reads := range []int{50, 90, 99} {
+		name := fmt.Sprintf("reads=%d%%", reads)
+		results = append(results,
+			benchutil.Run("Mutex/"+name, benchSafeCounter(reads)),
+			benchutil.Run("RWMutex/"+name, benchRWCounter(reads)))
 	}
-
-	for i := 0; i < numWrites; i++ {
-		wg1.Add(1)
-		go func() {
-			defer wg1.Done()
-			mutexCounter.mu.Lock()
-			mutexCounter.value++
-			mutexCounter.mu.Unlock()
-		}()
+	var buf bytes.Buffer
+	benchutil.Write(&buf, results)
+	for line := range strings.Lines(buf.String()) {
+		fmt.Print("   ", line)
 	}
+	fmt.Printf("   GOMAXPROCS=%d: RWMutex only wins when readers really run at once\n", runtime.GOMAXPROCS(0))
+}
 
-	wg1.Wait()
-	mutexDuration := time.Since(mutexStart)
-
-	// Test with RWMutex
-	rwCounter := &RWCounter{}
-	rwStart := time.Now()
-	var wg2 sync.WaitGroup
-
-	for i := 0; i < numReads; i++ {
-		wg2.Add(1)
-		go func() {
-			defer wg2.Done()
-			rwCounter.mu.RLock()
-			_ = rwCounter.value
-			rwCounter.mu.RUnlock()
-		}()
+// benchSafeCounter returns a benchmark of SafeCounter from GOMAXPROCS
+// goroutines, where reads percent of the operations are reads
+func benchSafeCounter(reads int) func(b *testing.B) {
+	return func(b *testing.B) {
+		c := &SafeCounter{}
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				c.mu.Lock()
+				if i%100 < reads {
+					_ = c.value
+				} else {
+					c.value++
+				}
+				c.mu.Unlock()
+			}
+		})
 	}
+}
 
-	for i := 0; i < numWrites; i++ {
-		wg2.Add(1)
-		go func() {
-			defer wg2.Done()
-			rwCounter.mu.Lock()
-			rwCounter.value++
-			rwCounter.mu.Unlock()
-		}()
+// benchRWCounter is benchSafeCounter for RWCounter: reads take the read lock
+func benchRWCounter(reads int) func(b *testing.B) {
+	return func(b *testing.B) {
+		c := &RWCounter{}
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if i%100 < reads {
+					c.mu.RLock()
+					_ = c.value
+					c.mu.RUnlock()
+				} else {
+					c.mu.Lock()
+					c.value++
+					c.mu.Unlock()
+				}
+			}
+		})
 	}
-
-	wg2.Wait()
-	rwDuration := time.Since(rwStart)
-
-	fmt.Printf("   Mutex time: %v\n", mutexDuration)
-	fmt.Printf("   RWMutex time: %v\n", rwDuration)
-	fmt.Println("   Note: RWMutex is typically faster for read-heavy workloads")
 }
 
 // criticalSectionExample shows protecting multiple operations
@@ -0,0 +1,21 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// Run these with: go test -bench . -count 6
+// and compare two runs with benchstat, as 36-testing/03-benchmarks shows
+
+func BenchmarkMutex(b *testing.B) {
+	for _, reads := range []int{50, 90, 99} {
+		b.Run(fmt.Sprintf("reads=%d%%", reads), benchSafeCounter(reads))
+	}
+}
+
+func BenchmarkRWMutex(b *testing.B) {
+	for _, reads := range []int{50, 90, 99} {
+		b.Run(fmt.Sprintf("reads=%d%%", reads), benchRWCounter(reads))
+	}
+}
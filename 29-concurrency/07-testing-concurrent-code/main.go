@@ -2,9 +2,14 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"sync"
 	"time"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+	"github.com/inancgumus/learngo/pkg/timing"
 )
 
 func main() {
@@ -33,8 +38,13 @@ func main() {
 	}
 	fmt.Println()
 
-	// Example 2: Debouncer - debounces rapid events
-	fmt.Println("Example 2: Debouncer")
+	// Example 2: pkg/retry - the same idea with options
+	fmt.Println("Example 2: Retry with pkg/retry options")
+	example2RetryOptions()
+	fmt.Println()
+
+	// Example 3: Debouncer - debounces rapid events
+	fmt.Println("Example 3: Debouncer")
 	var result string
 	var mu sync.Mutex
 
@@ -60,8 +70,8 @@ func main() {
 	mu.Unlock()
 	fmt.Println()
 
-	// Example 3: Timeout - operation with timeout
-	fmt.Println("Example 3: Operation with timeout")
+	// Example 4: Timeout - operation with timeout
+	fmt.Println("Example 4: Operation with timeout")
 
 	// Fast operation - should succeed
 	err = WithTimeout(100*time.Millisecond, func() error {
@@ -86,66 +96,89 @@ func main() {
 	}
 }
 
-// RetryWithBackoff retries an operation with exponential backoff
-func RetryWithBackoff(ctx context.Context, maxAttempts int, initialDelay time.Duration, operation func() error) error {
-	delay := initialDelay
+// errNotFound is a failure that retrying cannot fix
+var errNotFound = errors.New("not found")
 
-	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		err := operation()
-		if err == nil {
-			return nil
-		}
+// example2RetryOptions shows jitter, a retry-if predicate, hooks, and
+// permanent errors from pkg/retry
+func example2RetryOptions() {
+	ctx := context.Background()
+	hook := retry.WithOnRetry(func(attempt int, err error, delay time.Duration) {
+		fmt.Printf("  attempt %d failed (%v), waiting %v\n", attempt, err, delay.Round(time.Millisecond))
+	})
 
-		if attempt == maxAttempts {
-			return fmt.Errorf("failed after %d attempts: %w", maxAttempts, err)
+	// Jitter spreads out retries from many clients; the delays vary per run
+	calls := 0
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		calls++
+		if calls < 3 {
+			return fmt.Errorf("connection refused")
 		}
+		return nil
+	},
+		retry.WithMaxAttempts(5),
+		retry.WithMaxElapsed(time.Second),
+		retry.WithBackoff(20*time.Millisecond, 200*time.Millisecond),
+		retry.WithJitter(retry.EqualJitter),
+		hook,
+	)
+	fmt.Printf("  with jitter: err=%v after %d attempts\n", err, calls)
+
+	// A permanent error stops at once: no hook, no waiting
+	calls = 0
+	err = retry.Do(ctx, func(ctx context.Context) error {
+		calls++
+		return retry.Permanent(errNotFound)
+	}, hook)
+	fmt.Printf("  permanent:   err=%v after %d attempt\n", err, calls)
+
+	// RetryIf decides which errors are worth another try
+	calls = 0
+	err = retry.Do(ctx, func(ctx context.Context) error {
+		calls++
+		return errNotFound
+	},
+		retry.WithRetryIf(func(err error) bool { return !errors.Is(err, errNotFound) }),
+		hook,
+	)
+	fmt.Printf("  retry-if:    err=%v after %d attempt\n", err, calls)
+}
 
-		// Wait before retry with exponential backoff
-		select {
-		case <-time.After(delay):
-			delay *= 2
-		case <-ctx.Done():
-			return ctx.Err()
-		}
+// RetryWithBackoff retries an operation with exponential backoff.
+// It is the lesson's small API on top of pkg/retry: no jitter, and the
+// delay keeps doubling.
+//
+// maxAttempts must be at least 1. pkg/retry reads 0 as "no limit", so
+// RetryWithBackoff returns an error for it rather than retry forever.
+func RetryWithBackoff(ctx context.Context, maxAttempts int, initialDelay time.Duration, operation func() error) error {
+	if maxAttempts < 1 {
+		return fmt.Errorf("retry: maxAttempts is %d, want at least 1", maxAttempts)
 	}
-
-	return nil
+	return retry.Do(ctx, func(context.Context) error {
+		return operation()
+	},
+		retry.WithMaxAttempts(maxAttempts),
+		retry.WithBackoff(initialDelay, math.MaxInt64),
+	)
 }
 
-// Debouncer delays execution until events stop arriving
+// Debouncer delays execution until events stop arriving.
+// It wraps timing.Debouncer from pkg/timing, which also has a
+// Throttler and a Sampler.
 type Debouncer struct {
-	delay time.Duration
-	timer *time.Timer
-	mu    sync.Mutex
-	stop  chan struct{}
+	d *timing.Debouncer
 }
 
 func NewDebouncer(delay time.Duration) *Debouncer {
-	return &Debouncer{
-		delay: delay,
-		stop:  make(chan struct{}),
-	}
+	return &Debouncer{d: timing.NewDebouncer(context.Background(), delay)}
 }
 
 func (d *Debouncer) Debounce(f func()) {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	if d.timer != nil {
-		d.timer.Stop()
-	}
-
-	d.timer = time.AfterFunc(d.delay, f)
+	d.d.Do(f)
 }
 
 func (d *Debouncer) Stop() {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	if d.timer != nil {
-		d.timer.Stop()
-	}
-	close(d.stop)
+	d.d.Stop()
 }
 
 // WithTimeout executes an operation with a timeout
@@ -2,323 +2,317 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"testing"
 	"testing/synctest"
 	"time"
 )
 
-// Example 1: Testing retry with backoff - Traditional (slow)
-func TestRetryWithBackoff_Traditional(t *testing.T) {
-	t.Log("Traditional test: Uses real time")
+// Every test in the first part runs inside a synctest bubble. Time in a
+// bubble is fake: it moves forward only when every goroutine in the
+// bubble is blocked, and then it jumps straight to the next timer. So
+// the tests take no real time, and they can check EXACT durations.
+//
+// The second part has the same kind of tests written with real sleeps,
+// for comparison.
 
-	attempts := 0
-	start := time.Now()
-
-	err := RetryWithBackoff(context.Background(), 3, 50*time.Millisecond, func() error {
-		attempts++
-		if attempts < 3 {
-			return fmt.Errorf("temporary error")
-		}
-		return nil
-	})
-
-	elapsed := time.Since(start)
-
-	if err != nil {
-		t.Errorf("Expected success, got error: %v", err)
-	}
-
-	if attempts != 3 {
-		t.Errorf("Expected 3 attempts, got %d", attempts)
-	}
-
-	// Should take at least 50ms + 100ms = 150ms due to backoff
-	if elapsed < 150*time.Millisecond {
-		t.Errorf("Test completed too quickly: %v", elapsed)
-	}
-
-	t.Logf("Test took %v (real time)", elapsed)
-}
-
-// Example 2: Testing retry with backoff - With synctest (instant)
-func TestRetryWithBackoff_WithSynctest(t *testing.T) {
+func TestRetryWithBackoff(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
-		t.Log("Synctest test: Uses fake time, instant")
-
-		attempts := 0
 		start := time.Now()
+		var at []time.Duration // when each attempt ran
 
 		err := RetryWithBackoff(context.Background(), 3, 50*time.Millisecond, func() error {
-			attempts++
-			if attempts < 3 {
-				return fmt.Errorf("temporary error")
+			at = append(at, time.Since(start))
+			if len(at) < 3 {
+				return errors.New("temporary error")
 			}
 			return nil
 		})
-
-		elapsed := time.Since(start)
-
 		if err != nil {
-			t.Errorf("Expected success, got error: %v", err)
+			t.Fatalf("want success on the third attempt; got %v", err)
 		}
 
-		if attempts != 3 {
-			t.Errorf("Expected 3 attempts, got %d", attempts)
+		// Waits of 50ms, then 100ms: the delay doubles
+		want := []time.Duration{0, 50 * time.Millisecond, 150 * time.Millisecond}
+		if !slices.Equal(at, want) {
+			t.Errorf("want attempts at %v; got %v", want, at)
 		}
-
-		t.Logf("Test simulated %v but completed in %v", 150*time.Millisecond, elapsed)
-	})
-}
-
-// Example 3: Testing timeout - Traditional (slow)
-func TestWithTimeout_Traditional(t *testing.T) {
-	t.Log("Traditional timeout test: slow")
-
-	// Fast operation - should succeed
-	err := WithTimeout(100*time.Millisecond, func() error {
-		time.Sleep(50 * time.Millisecond)
-		return nil
-	})
-	if err != nil {
-		t.Errorf("Fast operation should succeed, got: %v", err)
-	}
-
-	// Slow operation - should timeout
-	err = WithTimeout(100*time.Millisecond, func() error {
-		time.Sleep(200 * time.Millisecond)
-		return nil
 	})
-	if err == nil {
-		t.Error("Slow operation should timeout")
-	}
 }
 
-// Example 4: Testing timeout - With synctest (instant)
-// Note: Timeout tests with synctest need careful handling of goroutines
-func TestWithTimeout_WithSynctest(t *testing.T) {
+func TestRetryWithBackoffGivesUp(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
-		t.Log("Synctest timeout test: instant (success case only)")
+		errTemp := errors.New("temporary error")
+		start := time.Now()
+		attempts := 0
 
-		// Fast operation - should succeed
-		err := WithTimeout(100*time.Millisecond, func() error {
-			time.Sleep(50 * time.Millisecond)
-			return nil
+		err := RetryWithBackoff(context.Background(), 4, 10*time.Millisecond, func() error {
+			attempts++
+			return errTemp
 		})
-		if err != nil {
-			t.Errorf("Fast operation should succeed, got: %v", err)
-		}
 
-		// Note: Timeout case causes deadlock in synctest because the
-		// sleeping goroutine never completes. This is a limitation
-		// when testing timeout scenarios with synctest.
-		t.Log("Timeout case not tested with synctest (causes deadlock)")
+		if !errors.Is(err, errTemp) {
+			t.Errorf("want the last error wrapped; got %v", err)
+		}
+		if attempts != 4 {
+			t.Errorf("want 4 attempts; got %d", attempts)
+		}
+		// 10 + 20 + 40: no wait after the last attempt
+		if got := time.Since(start); got != 70*time.Millisecond {
+			t.Errorf("want to give up after 70ms; got %v", got)
+		}
 	})
 }
 
-// Example 5: Testing batch processor - Traditional (slow)
-func TestBatchProcessor_Traditional(t *testing.T) {
-	t.Log("Traditional batch processor test: slow")
-
-	items := []string{"a", "b", "c", "d", "e"}
-	start := time.Now()
-
-	results := BatchProcessor(items, 2, 50*time.Millisecond)
-
-	elapsed := time.Since(start)
-
-	if len(results) != 5 {
-		t.Errorf("Expected 5 results, got %d", len(results))
-	}
-
-	// Should take at least 100ms (2 delays between 3 batches)
-	if elapsed < 100*time.Millisecond {
-		t.Errorf("Test completed too quickly: %v", elapsed)
+// pkg/retry reads 0 attempts as "no limit": the wrapper must not pass
+// it on and retry forever
+func TestRetryWithBackoffNoAttempts(t *testing.T) {
+	for _, n := range []int{0, -1} {
+		calls := 0
+		err := RetryWithBackoff(context.Background(), n, time.Millisecond, func() error {
+			calls++
+			return errors.New("keeps failing")
+		})
+		if err == nil {
+			t.Errorf("maxAttempts %d: want an error; got nil", n)
+		}
+		if calls != 0 {
+			t.Errorf("maxAttempts %d: want no calls; got %d", n, calls)
+		}
 	}
-
-	t.Logf("Processed %d items in %v", len(results), elapsed)
 }
 
-// Example 6: Testing batch processor - With synctest (instant)
-func TestBatchProcessor_WithSynctest(t *testing.T) {
+func TestRetryWithBackoffCancel(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
-		t.Log("Synctest batch processor test: instant")
-
-		items := []string{"a", "b", "c", "d", "e"}
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(100*time.Millisecond, cancel)
 		start := time.Now()
 
-		results := BatchProcessor(items, 2, 50*time.Millisecond)
-
-		elapsed := time.Since(start)
+		err := RetryWithBackoff(ctx, 10, 50*time.Millisecond, func() error {
+			return errors.New("keeps failing")
+		})
 
-		if len(results) != 5 {
-			t.Errorf("Expected 5 results, got %d", len(results))
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want context.Canceled; got %v", err)
+		}
+		// Cancelled in the middle of the second wait (50ms + 100ms)
+		if got := time.Since(start); got != 100*time.Millisecond {
+			t.Errorf("want to stop as soon as ctx is cancelled, at 100ms; got %v", got)
 		}
-
-		t.Logf("Processed %d items in %v (simulated 100ms+)", len(results), elapsed)
 	})
 }
 
-// Example 7: Testing concurrent operations with synctest
-func TestConcurrentOperations_WithSynctest(t *testing.T) {
+func TestDebouncer(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
-		t.Log("Testing 10 concurrent goroutines with fake time")
-
-		const goroutines = 10
-		var wg sync.WaitGroup
-		var count int
-		var mu sync.Mutex
-
-		// Launch concurrent goroutines
-		for i := 0; i < goroutines; i++ {
-			wg.Add(1)
-			i := i
-			go func() {
-				defer wg.Done()
-				// Each sleeps for a different duration
-				time.Sleep(time.Duration(i*10) * time.Millisecond)
+		var (
+			mu   sync.Mutex
+			runs []int
+		)
+		d := NewDebouncer(100 * time.Millisecond)
+		defer d.Stop()
+
+		// Five events, 30ms apart: never 100ms of quiet until the end
+		for i := 1; i <= 5; i++ {
+			d.Debounce(func() {
 				mu.Lock()
-				count++
-				mu.Unlock()
-			}()
+				defer mu.Unlock()
+				runs = append(runs, i)
+			})
+			if i < 5 {
+				time.Sleep(30 * time.Millisecond)
+			}
 		}
 
-		// Wait for all to complete (instantly in synctest!)
-		wg.Wait()
+		// One moment before the delay is over, nothing has run. Wait
+		// lets every goroutine that could run, run: without it, the
+		// check would race with the timer's goroutine
+		time.Sleep(100*time.Millisecond - 1)
+		synctest.Wait()
+		mu.Lock()
+		if len(runs) != 0 {
+			t.Errorf("want nothing run before 100ms of quiet; got %v", runs)
+		}
+		mu.Unlock()
 
+		time.Sleep(1)
+		synctest.Wait()
 		mu.Lock()
 		defer mu.Unlock()
-
-		if count != goroutines {
-			t.Errorf("Expected %d goroutines to complete, got %d", goroutines, count)
+		if !slices.Equal(runs, []int{5}) {
+			t.Errorf("want only the last event run, once; got %v", runs)
 		}
-
-		t.Logf("All %d goroutines completed instantly", count)
 	})
 }
 
-// Example 8: Testing context cancellation with synctest
-func TestContextCancellation_WithSynctest(t *testing.T) {
+func TestDebouncerStop(t *testing.T) {
 	synctest.Test(t, func(t *testing.T) {
-		t.Log("Testing context cancellation with fake time")
-
-		ctx, cancel := context.WithCancel(context.Background())
+		ran := false
+		d := NewDebouncer(100 * time.Millisecond)
 
-		// Cancel after 100ms
-		go func() {
-			time.Sleep(100 * time.Millisecond)
-			cancel()
-		}()
+		d.Debounce(func() { ran = true })
+		d.Stop()
 
-		// This should be cancelled
-		err := RetryWithBackoff(ctx, 10, 50*time.Millisecond, func() error {
-			return fmt.Errorf("keeps failing")
-		})
-
-		if err != context.Canceled {
-			t.Errorf("Expected context.Canceled, got: %v", err)
+		time.Sleep(time.Second)
+		synctest.Wait()
+		if ran {
+			t.Error("want a pending call dropped by Stop")
 		}
-
-		t.Log("Context cancellation worked correctly")
 	})
 }
 
-// Example 9: Demonstrating synctest.Wait() behavior
-func TestSynctestWait(t *testing.T) {
-	synctest.Test(t, func(t *testing.T) {
-		t.Log("Demonstrating synctest.Wait()")
-
-		var wg sync.WaitGroup
-		var completed bool
-		var mu sync.Mutex
-
-		wg.Add(1)
-		// Start a goroutine that sleeps
-		go func() {
-			defer wg.Done()
-			time.Sleep(100 * time.Millisecond)
-			mu.Lock()
-			completed = true
-			mu.Unlock()
-		}()
-
-		// Wait for goroutine to complete (instantly with fake time!)
-		wg.Wait()
-
-		mu.Lock()
-		defer mu.Unlock()
-
-		if !completed {
-			t.Error("Goroutine should have completed")
-		} else {
-			t.Log("Goroutine completed instantly with fake time")
-		}
-	})
+func TestWithTimeout(t *testing.T) {
+	errOp := errors.New("operation failed")
+
+	tests := []struct {
+		name    string
+		work    time.Duration // how long the operation takes
+		err     error         // what it returns
+		wantErr bool
+		want    time.Duration // when WithTimeout returns
+	}{
+		{"fast", 50 * time.Millisecond, nil, false, 50 * time.Millisecond},
+		{"fast error", 10 * time.Millisecond, errOp, true, 10 * time.Millisecond},
+		{"slow", 200 * time.Millisecond, nil, true, 100 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				start := time.Now()
+				err := WithTimeout(100*time.Millisecond, func() error {
+					time.Sleep(tt.work)
+					return tt.err
+				})
+
+				if (err != nil) != tt.wantErr {
+					t.Errorf("want error %v; got %v", tt.wantErr, err)
+				}
+				if tt.err != nil && !errors.Is(err, tt.err) {
+					t.Errorf("want the operation's error; got %v", err)
+				}
+				if got := time.Since(start); got != tt.want {
+					t.Errorf("want WithTimeout to return at %v; got %v", tt.want, got)
+				}
+
+				// After a timeout, the operation's goroutine is still
+				// running: WithTimeout can't stop it. If the bubble ended
+				// now, synctest.Test would panic with "blocked goroutines
+				// remain". Let the operation finish first
+				time.Sleep(tt.work)
+				synctest.Wait()
+			})
+		})
+	}
 }
 
-// Example 10: Comparing test speeds
-func TestSpeedComparison(t *testing.T) {
-	t.Run("Traditional", func(t *testing.T) {
-		start := time.Now()
-
-		// Sleep for a total of 300ms
-		time.Sleep(100 * time.Millisecond)
-		time.Sleep(100 * time.Millisecond)
-		time.Sleep(100 * time.Millisecond)
-
-		elapsed := time.Since(start)
-		t.Logf("Traditional test took: %v", elapsed)
-
-		if elapsed < 300*time.Millisecond {
-			t.Error("Should have taken at least 300ms")
-		}
-	})
-
-	t.Run("WithSynctest", func(t *testing.T) {
-		outerStart := time.Now()
-
-		synctest.Test(t, func(t *testing.T) {
-			// Same total sleep time
-			time.Sleep(100 * time.Millisecond)
-			time.Sleep(100 * time.Millisecond)
-			time.Sleep(100 * time.Millisecond)
-
-			t.Log("Inside synctest, time is fake")
+func TestBatchProcessor(t *testing.T) {
+	tests := []struct {
+		items     int
+		batchSize int
+		want      time.Duration // one 50ms delay between batches
+	}{
+		{items: 5, batchSize: 2, want: 100 * time.Millisecond}, // 3 batches
+		{items: 4, batchSize: 2, want: 50 * time.Millisecond},  // no delay after the last
+		{items: 2, batchSize: 5, want: 0},
+		{items: 0, batchSize: 2, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%d items by %d", tt.items, tt.batchSize), func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				var items, want []string
+				for i := range tt.items {
+					items = append(items, fmt.Sprint(i))
+					want = append(want, fmt.Sprintf("processed-%d", i))
+				}
+				start := time.Now()
+
+				got := BatchProcessor(items, tt.batchSize, 50*time.Millisecond)
+
+				if !slices.Equal(got, want) {
+					t.Errorf("want %q; got %q", want, got)
+				}
+				if elapsed := time.Since(start); elapsed != tt.want {
+					t.Errorf("want %v of delays; got %v", tt.want, elapsed)
+				}
+			})
 		})
+	}
+}
 
-		elapsed := time.Since(outerStart)
-		t.Logf("Synctest test took: %v (simulated 300ms)", elapsed)
+// ---------------------------------------------------------
+// For comparison: the same kind of tests with real sleeps
+//
+//  These wait for real, so they're slow: together they take
+//  about half a second, where the tests above take none.
+//  They're also weaker and flaky:
+//
+//  - They can only check lower bounds ("at least 150ms"). A
+//    busy machine makes everything later, so an upper bound
+//    would fail on a slow CI runner
+//  - To check that something happened, they sleep for the
+//    delay PLUS some slack, and hope the slack is enough.
+//    Too little slack fails under load; more is slower
+//  - To check that something did NOT happen yet, they sleep
+//    a bit LESS than the delay, and hope the scheduler didn't
+//    stall for longer than the difference
+//
+//  go test -short skips them.
+// ---------------------------------------------------------
+
+func TestRetryWithBackoff_Sleep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("real-time test")
+	}
+	start := time.Now()
+	attempts := 0
 
-		// Should complete in milliseconds
-		if elapsed > 100*time.Millisecond {
-			t.Logf("Warning: Took longer than expected: %v", elapsed)
+	err := RetryWithBackoff(context.Background(), 3, 50*time.Millisecond, func() error {
+		attempts++
+		if attempts < 3 {
+			return errors.New("temporary error")
 		}
+		return nil
 	})
-}
-
-// Example 11: Testing debouncer behavior
-// Note: Debouncer uses time.AfterFunc which creates goroutines
-// that may not complete cleanly in synctest
-func TestDebouncer_WithSynctest(t *testing.T) {
-	t.Log("Debouncer test demonstrates synctest limitations")
-	t.Log("time.AfterFunc creates goroutines that persist, causing potential deadlocks")
-	t.Log("This is a known limitation when testing timer-based code with synctest")
-	// Skipping this test to avoid deadlock
-	t.Skip("Skipping: time.AfterFunc not compatible with synctest")
-}
 
-// Benchmark: Comparing traditional vs synctest performance
-func BenchmarkTraditionalSleep(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		time.Sleep(10 * time.Millisecond)
+	if err != nil || attempts != 3 {
+		t.Fatalf("want success after 3 attempts; got %v after %d", err, attempts)
+	}
+	// Only a lower bound: it could be 150ms, or 400ms on a busy machine
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("want at least 150ms of backoff; got %v", elapsed)
 	}
 }
 
-func BenchmarkSynctestSleep(b *testing.B) {
-	for i := 0; i < b.N; i++ {
-		synctest.Test(&testing.T{}, func(t *testing.T) {
-			time.Sleep(10 * time.Millisecond)
+func TestDebouncer_Sleep(t *testing.T) {
+	if testing.Short() {
+		t.Skip("real-time test")
+	}
+	var (
+		mu   sync.Mutex
+		runs []int
+	)
+	d := NewDebouncer(100 * time.Millisecond)
+	defer d.Stop()
+
+	for i := 1; i <= 5; i++ {
+		d.Debounce(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			runs = append(runs, i)
 		})
+		// If this sleep overshoots to 100ms+, an early event runs,
+		// and the test fails for no fault of the Debouncer
+		time.Sleep(30 * time.Millisecond)
+	}
+
+	// 100ms delay + 50ms of slack, hoping that is enough
+	time.Sleep(150 * time.Millisecond)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !slices.Equal(runs, []int{5}) {
+		t.Errorf("want only the last event run, once; got %v", runs)
 	}
 }
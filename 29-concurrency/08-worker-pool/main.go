@@ -2,14 +2,30 @@ package main
 
 import (
 	"context"
+	"flag"
 	"fmt"
 	"io"
 	"net/http"
+	"os"
+	"runtime/trace"
 	"sync"
 	"time"
+
+	"github.com/inancgumus/learngo/pkg/workerpool"
 )
 
 func main() {
+	traceTo := flag.String("trace", "", "write an execution trace of the examples to this file")
+	flag.Parse()
+	if *traceTo != "" {
+		stop, err := startTrace(*traceTo)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer stop()
+	}
+
 	fmt.Println("Worker Pool Pattern")
 	fmt.Println("===================")
 	fmt.Println()
@@ -37,6 +53,11 @@ func main() {
 	// Example 5: Worker Pool with Timeout and Cancellation
 	fmt.Println("Example 5: Worker pool with timeout and cancellation")
 	workerPoolWithTimeout()
+	fmt.Println()
+
+	// Example 6: The same pattern as a reusable library
+	fmt.Println("Example 6: Reusable generic pool (pkg/workerpool)")
+	libraryWorkerPool()
 }
 
 // Example 1: Basic Worker Pool
@@ -379,3 +400,67 @@ func workerPoolWithTimeout() {
 		}
 	}
 }
+
+// Example 6: Reusable Worker Pool
+// Examples 1-5 repeat the same plumbing: jobs channel, results channel,
+// WaitGroup, closing results after wg.Wait(). pkg/workerpool packages
+// that plumbing once, generically, so only the job function is left.
+func libraryWorkerPool() {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	// The job function: same rules as Example 2
+	process := func(ctx context.Context, job Job) (int, error) {
+		if job.Value < 0 {
+			return 0, fmt.Errorf("cannot process negative value %d", job.Value)
+		}
+		time.Sleep(50 * time.Millisecond) // Simulate work
+		return job.Value * 2, nil
+	}
+
+	// Pool[Job, int]: inputs are Jobs, outputs are ints
+	pool := workerpool.New(ctx, 3, process)
+
+	// Submit blocks until a worker is free, then Drain waits for
+	// in-flight jobs and closes Results
+	go func() {
+		jobData := []int{1, 2, -3, 4, -5, 6, 7, 8}
+		for i, val := range jobData {
+			if err := pool.Submit(Job{ID: i + 1, Value: val}); err != nil {
+				fmt.Printf("  Submit failed: %v\n", err)
+				break
+			}
+		}
+		pool.Drain()
+	}()
+
+	successCount := 0
+	errorCount := 0
+	for result := range pool.Results() {
+		if result.Err != nil {
+			fmt.Printf("  Job %d error: %v\n", result.Input.ID, result.Err)
+			errorCount++
+			continue
+		}
+		fmt.Printf("  Job %d result: %d\n", result.Input.ID, result.Value)
+		successCount++
+	}
+	fmt.Printf("  Completed: %d successful, %d errors\n", successCount, errorCount)
+}
+
+// startTrace writes an execution trace to path until stop is called.
+// 37-performance/02-execution-tracer shows how to read one
+func startTrace(path string) (stop func(), err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := trace.Start(f); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() {
+		trace.Stop()
+		f.Close()
+	}, nil
+}
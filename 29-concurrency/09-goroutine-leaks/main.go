@@ -0,0 +1,321 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"runtime/pprof"
+	"strings"
+	"sync"
+	"time"
+)
+
+func main() {
+	fmt.Println("Detecting and Fixing Goroutine Leaks")
+	fmt.Println("====================================")
+	fmt.Println()
+
+	// Example 1: Blocked send on an unbuffered channel
+	fmt.Println("Example 1: Blocked send on an unbuffered channel")
+	example1BlockedSend()
+	fmt.Println()
+
+	// Example 2: Forgotten receiver (the fanIn from 03-channel-select)
+	fmt.Println("Example 2: Forgotten receiver in fan-in")
+	example2ForgottenReceiver()
+	fmt.Println()
+
+	// Example 3: Ranging over a channel nobody closes
+	fmt.Println("Example 3: Ranging over a channel that is never closed")
+	example3NeverClosed()
+	fmt.Println()
+
+	// Example 4: Reading a goroutine profile with runtime/pprof
+	fmt.Println("Example 4: Finding leaks with a goroutine profile")
+	example4GoroutineProfile()
+}
+
+// example1BlockedSend shows a timeout helper that leaks its worker
+func example1BlockedSend() {
+	before := runtime.NumGoroutine()
+
+	for i := 0; i < 5; i++ {
+		_, err := leakyFetch(50*time.Millisecond, 10*time.Millisecond)
+		if err != nil {
+			fmt.Printf("  leakyFetch #%d: %v\n", i+1, err)
+		}
+	}
+	fmt.Printf("  Leaked goroutines after leakyFetch: %d\n", settle(before))
+
+	before = runtime.NumGoroutine()
+	for i := 0; i < 5; i++ {
+		_, err := fixedFetch(50*time.Millisecond, 10*time.Millisecond)
+		if err != nil {
+			fmt.Printf("  fixedFetch #%d: %v\n", i+1, err)
+		}
+	}
+	fmt.Printf("  Leaked goroutines after fixedFetch: %d\n", settle(before))
+}
+
+// example2ForgottenReceiver shows that stopping early leaks the forwarders
+func example2ForgottenReceiver() {
+	before := runtime.NumGoroutine()
+
+	combined := leakyFanIn(generator("source1", 5), generator("source2", 5))
+	for i := 0; i < 2; i++ {
+		fmt.Printf("  %s\n", <-combined)
+	}
+	// We stopped reading: the generators and forwarders are stuck forever
+	fmt.Printf("  Leaked goroutines after leakyFanIn: %d\n", settle(before))
+
+	before = runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	combinedCtx := fixedFanIn(ctx,
+		generatorCtx(ctx, "source1", 5),
+		generatorCtx(ctx, "source2", 5),
+	)
+	for i := 0; i < 2; i++ {
+		fmt.Printf("  %s\n", <-combinedCtx)
+	}
+	// Cancelling tells every producer and forwarder to give up
+	cancel()
+	fmt.Printf("  Leaked goroutines after fixedFanIn: %d\n", settle(before))
+}
+
+// example3NeverClosed shows a consumer that waits forever for close
+func example3NeverClosed() {
+	before := runtime.NumGoroutine()
+
+	sum := leakySum([]int{1, 2, 3})
+	fmt.Printf("  leakySum = %d\n", sum)
+	fmt.Printf("  Leaked goroutines after leakySum: %d\n", settle(before))
+
+	before = runtime.NumGoroutine()
+
+	sum = fixedSum([]int{1, 2, 3})
+	fmt.Printf("  fixedSum = %d\n", sum)
+	fmt.Printf("  Leaked goroutines after fixedSum: %d\n", settle(before))
+}
+
+// example4GoroutineProfile prints the stacks of every live goroutine
+// The leaked goroutines from the previous examples show up here
+func example4GoroutineProfile() {
+	var buf bytes.Buffer
+
+	// debug=1 groups identical stacks and prints a count for each group
+	if err := pprof.Lookup("goroutine").WriteTo(&buf, 1); err != nil {
+		fmt.Fprintf(os.Stderr, "  profile error: %v\n", err)
+		return
+	}
+
+	// Drop the "goroutine profile: total N" summary line
+	_, profile, _ := strings.Cut(buf.String(), "\n")
+
+	fmt.Println("  Stacks that mention the leaky functions:")
+	for _, group := range strings.Split(profile, "\n\n") {
+		if strings.Contains(group, "main.leaky") {
+			// The first line looks like: "3 @ 0x... 0x..."
+			header, _, _ := strings.Cut(group, "\n")
+			count, _, _ := strings.Cut(header, " @")
+			fmt.Printf("    %s goroutine(s) in %s\n", count, leakyFrame(group))
+		}
+	}
+
+	fmt.Println()
+	fmt.Println("  Tip: in a server, import _ \"net/http/pprof\" and open")
+	fmt.Println("  /debug/pprof/goroutine?debug=1 to get the same report.")
+}
+
+// leakyFrame returns the first main.leaky* function found in a stack group
+func leakyFrame(group string) string {
+	for _, line := range strings.Split(group, "\n") {
+		if i := strings.Index(line, "main.leaky"); i >= 0 {
+			name, _, _ := strings.Cut(strings.Fields(line[i:])[0], "+")
+			return name
+		}
+	}
+	return "unknown"
+}
+
+// settle gives exiting goroutines a moment to finish and returns
+// how many more goroutines are alive than before
+func settle(before int) int {
+	deadline := time.Now().Add(200 * time.Millisecond)
+	for time.Now().Before(deadline) {
+		if runtime.NumGoroutine() <= before {
+			return 0
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return runtime.NumGoroutine() - before
+}
+
+// ---------------------------------------------------------
+// LEAK 1: blocked send on an unbuffered channel
+// ---------------------------------------------------------
+
+// leakyFetch runs a slow operation with a timeout
+// BUG: when the timeout wins, nobody ever receives from result,
+// so the worker goroutine blocks on its send forever
+func leakyFetch(work, timeout time.Duration) (string, error) {
+	result := make(chan string)
+
+	go func() {
+		time.Sleep(work)
+		result <- "data" // blocks forever after a timeout
+	}()
+
+	select {
+	case r := <-result:
+		return r, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("timed out after %v", timeout)
+	}
+}
+
+// fixedFetch gives the channel a buffer of one
+// The worker can always complete its send and exit, even if
+// nobody is listening anymore
+func fixedFetch(work, timeout time.Duration) (string, error) {
+	result := make(chan string, 1)
+
+	go func() {
+		time.Sleep(work)
+		result <- "data" // never blocks: the buffer has room
+	}()
+
+	select {
+	case r := <-result:
+		return r, nil
+	case <-time.After(timeout):
+		return "", fmt.Errorf("timed out after %v", timeout)
+	}
+}
+
+// ---------------------------------------------------------
+// LEAK 2: forgotten receiver
+// ---------------------------------------------------------
+
+// generator creates a channel that produces messages
+// (same as in 03-channel-select)
+func generator(prefix string, count int) <-chan string {
+	ch := make(chan string)
+	go func() {
+		for i := 1; i <= count; i++ {
+			ch <- fmt.Sprintf("%s: message %d", prefix, i)
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+// leakyFanIn is the fanIn from 03-channel-select
+// BUG: it never closes out, and if the caller stops reading,
+// every forwarding goroutine (and its generator) blocks forever
+func leakyFanIn(inputs ...<-chan string) <-chan string {
+	out := make(chan string)
+
+	for _, input := range inputs {
+		go func(ch <-chan string) {
+			for msg := range ch {
+				out <- msg
+			}
+		}(input)
+	}
+
+	return out
+}
+
+// generatorCtx is a generator that stops when ctx is cancelled
+func generatorCtx(ctx context.Context, prefix string, count int) <-chan string {
+	ch := make(chan string)
+	go func() {
+		defer close(ch)
+		for i := 1; i <= count; i++ {
+			select {
+			case ch <- fmt.Sprintf("%s: message %d", prefix, i):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// fixedFanIn forwards until ctx is cancelled and closes out
+// once every forwarder has returned
+func fixedFanIn(ctx context.Context, inputs ...<-chan string) <-chan string {
+	out := make(chan string)
+	var wg sync.WaitGroup
+
+	for _, input := range inputs {
+		wg.Go(func() {
+			for msg := range input {
+				select {
+				case out <- msg:
+				case <-ctx.Done():
+					return
+				}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// ---------------------------------------------------------
+// LEAK 3: a channel that is never closed
+// ---------------------------------------------------------
+
+// leakySum hands the numbers to a summing goroutine
+// BUG: the producer never closes nums, so the range loop in the
+// consumer never ends and the goroutine lives forever
+func leakySum(numbers []int) int {
+	nums := make(chan int)
+	partial := make(chan int)
+
+	go func() {
+		total := 0
+		for n := range nums { // waits for a close that never comes
+			total += n
+			partial <- total
+		}
+	}()
+
+	total := 0
+	for _, n := range numbers {
+		nums <- n
+		total = <-partial
+	}
+	return total
+}
+
+// fixedSum closes the input so the consumer's range loop ends,
+// and reports the total over a separate channel
+func fixedSum(numbers []int) int {
+	nums := make(chan int)
+	result := make(chan int)
+
+	go func() {
+		total := 0
+		for n := range nums {
+			total += n
+		}
+		result <- total
+	}()
+
+	for _, n := range numbers {
+		nums <- n
+	}
+	close(nums) // the consumer's range loop can now finish
+
+	return <-result
+}
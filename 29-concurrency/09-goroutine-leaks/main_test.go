@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+)
+
+// goroutineIDs returns the stacks of all live goroutines keyed by ID
+// This is the same trick go.uber.org/goleak uses: runtime.Stack with
+// all=true dumps every goroutine, one blank-line separated block each
+func goroutineIDs() map[string]string {
+	buf := make([]byte, 1<<20)
+	buf = buf[:runtime.Stack(buf, true)]
+
+	stacks := make(map[string]string)
+	for _, block := range strings.Split(string(buf), "\n\n") {
+		// Header looks like: "goroutine 42 [chan send]:"
+		header, _, _ := strings.Cut(block, "\n")
+		fields := strings.Fields(header)
+		if len(fields) < 2 {
+			continue
+		}
+		stacks[fields[1]] = block
+	}
+	return stacks
+}
+
+// leakedSince returns goroutines that exist now but did not exist in
+// before. Goroutines owned by the testing framework are ignored.
+func leakedSince(before map[string]string) []string {
+	var leaked []string
+	for id, stack := range goroutineIDs() {
+		if _, ok := before[id]; ok {
+			continue
+		}
+		if strings.Contains(stack, "testing.tRunner") {
+			continue
+		}
+		leaked = append(leaked, stack)
+	}
+	return leaked
+}
+
+// findLeaks polls for a short time so that goroutines which are in the
+// middle of exiting are not reported as leaks
+func findLeaks(before map[string]string) []string {
+	deadline := time.Now().Add(time.Second)
+	for {
+		leaked := leakedSince(before)
+		if len(leaked) == 0 || time.Now().After(deadline) {
+			return leaked
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// verifyNoLeaks is a tiny goleak.VerifyNone: call it at the top of a
+// test and it fails the test if goroutines are left behind
+func verifyNoLeaks(t *testing.T) {
+	t.Helper()
+	before := goroutineIDs()
+
+	t.Cleanup(func() {
+		if leaked := findLeaks(before); len(leaked) > 0 {
+			t.Errorf("found %d leaked goroutine(s):\n\n%s",
+				len(leaked), strings.Join(leaked, "\n\n"))
+		}
+	})
+}
+
+// The leaky versions are tested the other way around: we assert that
+// the detector DOES find the leak, so the lesson stays honest.
+
+func TestLeakyFetch_Leaks(t *testing.T) {
+	before := goroutineIDs()
+
+	if _, err := leakyFetch(50*time.Millisecond, time.Millisecond); err == nil {
+		t.Fatal("expected a timeout")
+	}
+
+	leaked := findLeaks(before)
+	if len(leaked) != 1 {
+		t.Fatalf("want 1 leaked goroutine; got %d", len(leaked))
+	}
+	if !strings.Contains(leaked[0], "chan send") {
+		t.Errorf("want goroutine blocked in chan send; got:\n%s", leaked[0])
+	}
+}
+
+func TestFixedFetch_NoLeak(t *testing.T) {
+	verifyNoLeaks(t)
+
+	if _, err := fixedFetch(50*time.Millisecond, time.Millisecond); err == nil {
+		t.Fatal("expected a timeout")
+	}
+}
+
+func TestLeakyFanIn_Leaks(t *testing.T) {
+	before := goroutineIDs()
+
+	out := leakyFanIn(generator("a", 3), generator("b", 3))
+	<-out
+
+	if leaked := findLeaks(before); len(leaked) == 0 {
+		t.Fatal("expected leakyFanIn to leak goroutines")
+	}
+}
+
+func TestFixedFanIn_NoLeak(t *testing.T) {
+	verifyNoLeaks(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := fixedFanIn(ctx, generatorCtx(ctx, "a", 3), generatorCtx(ctx, "b", 3))
+	<-out
+}
+
+func TestFixedFanIn_DeliversEverything(t *testing.T) {
+	verifyNoLeaks(t)
+
+	ctx := context.Background()
+	out := fixedFanIn(ctx, generatorCtx(ctx, "a", 3), generatorCtx(ctx, "b", 3))
+
+	count := 0
+	for range out {
+		count++
+	}
+	if count != 6 {
+		t.Errorf("want 6 messages; got %d", count)
+	}
+}
+
+func TestLeakySum_Leaks(t *testing.T) {
+	before := goroutineIDs()
+
+	if got := leakySum([]int{1, 2, 3}); got != 6 {
+		t.Errorf("want 6; got %d", got)
+	}
+	if leaked := findLeaks(before); len(leaked) != 1 {
+		t.Errorf("want 1 leaked goroutine; got %d", len(leaked))
+	}
+}
+
+func TestFixedSum_NoLeak(t *testing.T) {
+	verifyNoLeaks(t)
+
+	if got := fixedSum([]int{1, 2, 3}); got != 6 {
+		t.Errorf("want 6; got %d", got)
+	}
+}
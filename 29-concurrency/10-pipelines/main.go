@@ -0,0 +1,159 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/pipeline"
+)
+
+func main() {
+	fmt.Println("Composing Generic Pipelines")
+	fmt.Println("===========================")
+	fmt.Println()
+
+	// Example 1: The int-only pipeline from 02-channels, rebuilt with generic stages
+	fmt.Println("Example 1: generate -> square -> filterEven")
+	example1Basic()
+	fmt.Println()
+
+	// Example 2: Stages can change the element type
+	fmt.Println("Example 2: Changing types between stages")
+	example2ChangingTypes()
+	fmt.Println()
+
+	// Example 3: Fan-out a slow stage, then fan-in the results
+	fmt.Println("Example 3: Fan-out / fan-in")
+	example3FanOutFanIn()
+	fmt.Println()
+
+	// Example 4: Tee one stream into two consumers
+	fmt.Println("Example 4: Tee")
+	example4Tee()
+	fmt.Println()
+
+	// Example 5: Stopping early with context cancellation
+	fmt.Println("Example 5: Stopping an endless pipeline early")
+	example5Cancellation()
+}
+
+// example1Basic rebuilds generate/square/filterEven from the
+// pipeline-processor exercise with reusable generic stages
+func example1Basic() {
+	ctx := context.Background()
+
+	nums := pipeline.FromSlice(ctx, []int{1, 2, 3, 4, 5, 6, 7, 8, 9, 10})
+	squares := pipeline.MapStage(ctx, nums, func(n int) int { return n * n })
+	evens := pipeline.FilterStage(ctx, squares, func(n int) bool { return n%2 == 0 })
+
+	fmt.Printf("  Results: %v\n", pipeline.Collect(ctx, evens))
+}
+
+// example2ChangingTypes shows MapStage turning strings into a struct
+func example2ChangingTypes() {
+	type word struct {
+		Text   string
+		Length int
+	}
+
+	ctx := context.Background()
+
+	words := pipeline.FromSlice(ctx, strings.Fields("go channels compose like unix pipes"))
+	upper := pipeline.MapStage(ctx, words, strings.ToUpper)
+	measured := pipeline.MapStage(ctx, upper, func(s string) word {
+		return word{Text: s, Length: len(s)}
+	})
+	long := pipeline.FilterStage(ctx, measured, func(w word) bool { return w.Length > 4 })
+
+	for w := range long {
+		fmt.Printf("  %-10s (%d letters)\n", w.Text, w.Length)
+	}
+}
+
+// example3FanOutFanIn runs a slow stage on 3 parallel workers
+func example3FanOutFanIn() {
+	ctx := context.Background()
+
+	slowSquare := func(n int) int {
+		time.Sleep(100 * time.Millisecond) // Simulate expensive work
+		return n * n
+	}
+
+	start := time.Now()
+
+	nums := pipeline.FromSlice(ctx, []int{1, 2, 3, 4, 5, 6})
+
+	// Fan-out: three channels share the input, each value goes to one of them
+	var workers []<-chan int
+	for _, branch := range pipeline.FanOut(ctx, nums, 3) {
+		workers = append(workers, pipeline.MapStage(ctx, branch, slowSquare))
+	}
+
+	// Fan-in: merge the workers back into one stream (order not preserved)
+	results := pipeline.Collect(ctx, pipeline.FanIn(ctx, workers...))
+
+	fmt.Printf("  Results: %v\n", results)
+	fmt.Printf("  6 jobs x 100ms on 3 workers took ~%v\n",
+		time.Since(start).Round(100*time.Millisecond))
+}
+
+// example4Tee sends every value to a printer and a summer
+func example4Tee() {
+	ctx := context.Background()
+
+	nums := pipeline.FromSlice(ctx, []int{10, 20, 30})
+	toPrint, toSum := pipeline.Tee(ctx, nums)
+
+	// Both outputs must be read: Tee waits for both before moving on
+	sum := make(chan int)
+	go func() {
+		total := 0
+		for n := range toSum {
+			total += n
+		}
+		sum <- total
+	}()
+
+	for n := range toPrint {
+		fmt.Printf("  Saw %d\n", n)
+	}
+	fmt.Printf("  Sum: %d\n", <-sum)
+}
+
+// example5Cancellation takes the first few values from an endless
+// source, then cancels so every stage shuts down
+func example5Cancellation() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// An endless source: counts up until ctx is cancelled
+	counter := make(chan int)
+	go func() {
+		defer close(counter)
+		for i := 1; ; i++ {
+			select {
+			case counter <- i:
+			case <-ctx.Done():
+				fmt.Println("  Source stopped")
+				return
+			}
+		}
+	}()
+
+	odds := pipeline.FilterStage(ctx, counter, func(n int) bool { return n%2 == 1 })
+	cubes := pipeline.MapStage(ctx, odds, func(n int) int { return n * n * n })
+
+	for range 5 {
+		fmt.Printf("  %d\n", <-cubes)
+	}
+
+	// Without cancel, the source and both stages would leak
+	cancel()
+
+	// cubes closes once cancellation has reached the last stage
+	for range cubes {
+	}
+	time.Sleep(10 * time.Millisecond)
+	fmt.Println("  All stages closed")
+}
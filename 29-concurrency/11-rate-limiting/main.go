@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"golang.org/x/time/rate"
+
+	"github.com/inancgumus/learngo/pkg/ratelimit"
+)
+
+func main() {
+	fmt.Println("Rate Limiting: Ticker vs Token Bucket")
+	fmt.Println("=====================================")
+	fmt.Println()
+
+	// Example 1: The ticker approach from exercises/02-rate-limiter
+	fmt.Println("Example 1: time.Ticker (no burst)")
+	tickerLimiter()
+	fmt.Println()
+
+	// Example 2: Token bucket with Wait
+	fmt.Println("Example 2: pkg/ratelimit Wait (burst of 3, then 4/sec)")
+	tokenBucketWait()
+	fmt.Println()
+
+	// Example 3: Token bucket with the Allow fast path
+	fmt.Println("Example 3: pkg/ratelimit Allow (reject instead of wait)")
+	tokenBucketAllow()
+	fmt.Println()
+
+	// Example 4: The same thing with golang.org/x/time/rate
+	fmt.Println("Example 4: golang.org/x/time/rate")
+	xTimeRate()
+	fmt.Println()
+
+	// Example 5: Wait respects context deadlines
+	fmt.Println("Example 5: Wait with a deadline")
+	waitWithDeadline()
+}
+
+// tickerLimiter spaces every request by the tick interval
+// Even the first request waits, and idle time is never "saved up"
+func tickerLimiter() {
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for i := 1; i <= 5; i++ {
+		<-ticker.C
+		fmt.Printf("  [%s] request %d\n", since(start), i)
+	}
+}
+
+// tokenBucketWait lets a burst through immediately, then paces the rest
+func tokenBucketWait() {
+	lim := ratelimit.New(4, 3)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 1; i <= 6; i++ {
+		if err := lim.Wait(ctx); err != nil {
+			fmt.Printf("  request %d: %v\n", i, err)
+			continue
+		}
+		fmt.Printf("  [%s] request %d\n", since(start), i)
+	}
+}
+
+// tokenBucketAllow never blocks: requests over the limit are rejected
+// This is what an HTTP server does when it returns 429 Too Many Requests
+func tokenBucketAllow() {
+	lim := ratelimit.New(4, 3)
+
+	start := time.Now()
+	for i := 1; i <= 8; i++ {
+		if lim.Allow() {
+			fmt.Printf("  [%s] request %d allowed\n", since(start), i)
+		} else {
+			fmt.Printf("  [%s] request %d rejected (%.2f tokens left)\n",
+				since(start), i, lim.Tokens())
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+// xTimeRate repeats Example 2 with the extended standard library limiter
+func xTimeRate() {
+	// rate.Limit is events per second, just like ratelimit.New
+	lim := rate.NewLimiter(rate.Limit(4), 3)
+	ctx := context.Background()
+
+	start := time.Now()
+	for i := 1; i <= 6; i++ {
+		if err := lim.Wait(ctx); err != nil {
+			fmt.Printf("  request %d: %v\n", i, err)
+			continue
+		}
+		fmt.Printf("  [%s] request %d\n", since(start), i)
+	}
+
+	// x/time/rate has more to offer: reservations, bursts of N events,
+	// and changing the limit at runtime
+	r := lim.Reserve()
+	fmt.Printf("  Reserve(): next event may happen in %v\n", r.Delay().Round(time.Millisecond))
+	r.Cancel()
+}
+
+// waitWithDeadline shows Wait failing fast when the deadline is too close
+func waitWithDeadline() {
+	lim := ratelimit.New(1, 1)
+	lim.Allow() // empty the bucket: the next token is 1s away
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err := lim.Wait(ctx)
+	fmt.Printf("  [%s] pkg/ratelimit: %v\n", since(start), err)
+
+	xlim := rate.NewLimiter(1, 1)
+	xlim.Allow()
+
+	start = time.Now()
+	err = xlim.Wait(ctx)
+	fmt.Printf("  [%s] x/time/rate:   %v\n", since(start), err)
+}
+
+// since formats the elapsed time in seconds like the exercise does
+func since(start time.Time) string {
+	return fmt.Sprintf("%.3fs", time.Since(start).Seconds())
+}
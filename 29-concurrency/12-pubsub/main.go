@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/pubsub"
+)
+
+// Order is the message type published in these examples
+type Order struct {
+	ID    int
+	Total float64
+}
+
+func main() {
+	fmt.Println("In-Process Pub/Sub")
+	fmt.Println("==================")
+	fmt.Println()
+
+	// Example 1: One publisher, many subscribers
+	fmt.Println("Example 1: Every subscriber gets its own copy")
+	example1Broadcast()
+	fmt.Println()
+
+	// Example 2: Slow consumer with the Drop policy
+	fmt.Println("Example 2: Slow consumer, Drop policy")
+	example2Drop()
+	fmt.Println()
+
+	// Example 3: Slow consumer with the Block policy
+	fmt.Println("Example 3: Slow consumer, Block policy")
+	example3Block()
+	fmt.Println()
+
+	// Example 4: Unsubscribing and closing
+	fmt.Println("Example 4: Unsubscribe and Close")
+	example4Lifecycle()
+}
+
+// example1Broadcast fans one topic out to two independent services
+func example1Broadcast() {
+	ctx := context.Background()
+	broker := pubsub.NewBroker[Order]()
+	defer broker.Close()
+
+	billing := broker.Subscribe("orders", 10, pubsub.Block)
+	shipping := broker.Subscribe("orders", 10, pubsub.Block)
+
+	var wg sync.WaitGroup
+	for name, sub := range map[string]*pubsub.Subscription[Order]{
+		"billing":  billing,
+		"shipping": shipping,
+	} {
+		wg.Go(func() {
+			for order := range sub.C() {
+				fmt.Printf("  %-8s got order #%d ($%.2f)\n", name, order.ID, order.Total)
+			}
+		})
+	}
+
+	for i := 1; i <= 3; i++ {
+		broker.Publish(ctx, "orders", Order{ID: i, Total: float64(i) * 9.99})
+	}
+
+	// Closing the subscriptions ends the range loops above
+	billing.Unsubscribe()
+	shipping.Unsubscribe()
+	wg.Wait()
+}
+
+// example2Drop shows that a slow Drop subscriber misses messages
+// but never slows the publisher down
+func example2Drop() {
+	ctx := context.Background()
+	broker := pubsub.NewBroker[int]()
+	defer broker.Close()
+
+	sub := broker.Subscribe("ticks", 2, pubsub.Drop)
+
+	start := time.Now()
+	for i := 1; i <= 10; i++ {
+		broker.Publish(ctx, "ticks", i)
+	}
+	fmt.Printf("  Published 10 messages in %v\n", time.Since(start).Round(time.Millisecond))
+
+	sub.Unsubscribe()
+	var received []int
+	for n := range sub.C() {
+		received = append(received, n)
+	}
+	fmt.Printf("  Received %v, dropped %d\n", received, sub.Dropped())
+}
+
+// example3Block shows that a slow Block subscriber receives everything
+// but holds the publisher back to its own pace
+func example3Block() {
+	ctx := context.Background()
+	broker := pubsub.NewBroker[int]()
+	defer broker.Close()
+
+	sub := broker.Subscribe("jobs", 2, pubsub.Block)
+
+	var received []int
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		for n := range sub.C() {
+			time.Sleep(50 * time.Millisecond) // slow consumer
+			received = append(received, n)
+		}
+	})
+
+	start := time.Now()
+	for i := 1; i <= 6; i++ {
+		broker.Publish(ctx, "jobs", i)
+	}
+	fmt.Printf("  Published 6 messages in %v\n", time.Since(start).Round(10*time.Millisecond))
+
+	sub.Unsubscribe()
+	wg.Wait()
+	fmt.Printf("  Received %d of 6, dropped %d\n", len(received), sub.Dropped())
+}
+
+// example4Lifecycle shows the broker after unsubscribe and close
+func example4Lifecycle() {
+	ctx := context.Background()
+	broker := pubsub.NewBroker[string]()
+
+	a := broker.Subscribe("chat", 1, pubsub.Drop)
+	broker.Subscribe("chat", 1, pubsub.Drop)
+	fmt.Printf("  Subscribers: %d\n", broker.Subscribers("chat"))
+
+	a.Unsubscribe()
+	fmt.Printf("  After one Unsubscribe: %d\n", broker.Subscribers("chat"))
+
+	broker.Close()
+	err := broker.Publish(ctx, "chat", "anyone there?")
+	fmt.Printf("  Publish after Close: %v\n", err)
+}
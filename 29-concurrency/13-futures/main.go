@@ -0,0 +1,223 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/async"
+)
+
+// Profile and Order are what the "services" below return
+type Profile struct{ Name string }
+type Order struct{ ID int }
+
+// fetchProfile simulates a slow service call
+func fetchProfile(id int) (Profile, error) {
+	time.Sleep(80 * time.Millisecond)
+	return Profile{Name: fmt.Sprintf("user-%d", id)}, nil
+}
+
+// fetchOrders simulates another slow service call
+func fetchOrders(id int) ([]Order, error) {
+	time.Sleep(100 * time.Millisecond)
+	return []Order{{ID: id * 10}, {ID: id*10 + 1}}, nil
+}
+
+// fetchFrom simulates a mirror that answers after d, or fails
+func fetchFrom(name string, d time.Duration, fail bool) func() (string, error) {
+	return func() (string, error) {
+		time.Sleep(d)
+		if fail {
+			return "", fmt.Errorf("%s: unavailable", name)
+		}
+		return "data from " + name, nil
+	}
+}
+
+func main() {
+	fmt.Println("Futures and Promises")
+	fmt.Println("====================")
+	fmt.Println()
+
+	// Example 1: Two concurrent calls with plain channels
+	fmt.Println("Example 1: Plain channels")
+	example1Channels()
+	fmt.Println()
+
+	// Example 2: The same calls with futures
+	fmt.Println("Example 2: Futures")
+	example2Futures()
+	fmt.Println()
+
+	// Example 3: A future can be awaited many times
+	fmt.Println("Example 3: Await many times")
+	example3AwaitTwice()
+	fmt.Println()
+
+	// Example 4: All - wait for every result
+	fmt.Println("Example 4: All")
+	example4All()
+	fmt.Println()
+
+	// Example 5: Any vs Race
+	fmt.Println("Example 5: Any vs Race")
+	example5AnyVsRace()
+	fmt.Println()
+
+	// Example 6: Timeouts with Await
+	fmt.Println("Example 6: Await with a timeout")
+	example6Timeout()
+}
+
+// example1Channels runs two calls concurrently using channels only
+func example1Channels() {
+	start := time.Now()
+
+	// Every call needs a result type that carries the error too,
+	// and its own buffered channel so the goroutine never blocks
+	type profileResult struct {
+		p   Profile
+		err error
+	}
+	type ordersResult struct {
+		o   []Order
+		err error
+	}
+
+	profileCh := make(chan profileResult, 1)
+	ordersCh := make(chan ordersResult, 1)
+
+	go func() {
+		p, err := fetchProfile(1)
+		profileCh <- profileResult{p, err}
+	}()
+	go func() {
+		o, err := fetchOrders(1)
+		ordersCh <- ordersResult{o, err}
+	}()
+
+	pr := <-profileCh
+	or := <-ordersCh
+	if pr.err != nil || or.err != nil {
+		fmt.Println("  error:", errors.Join(pr.err, or.err))
+		return
+	}
+
+	fmt.Printf("  %s has %d orders\n", pr.p.Name, len(or.o))
+	fmt.Printf("  took ~%dms (concurrent)\n", time.Since(start).Round(10*time.Millisecond).Milliseconds())
+}
+
+// example2Futures runs the same two calls with async.Go
+func example2Futures() {
+	ctx := context.Background()
+	start := time.Now()
+
+	// No result structs and no channels: the future carries both
+	profile := async.Go(func() (Profile, error) { return fetchProfile(1) })
+	orders := async.Go(func() ([]Order, error) { return fetchOrders(1) })
+
+	p, err := profile.Await(ctx)
+	if err != nil {
+		fmt.Println("  error:", err)
+		return
+	}
+	o, err := orders.Await(ctx)
+	if err != nil {
+		fmt.Println("  error:", err)
+		return
+	}
+
+	fmt.Printf("  %s has %d orders\n", p.Name, len(o))
+	fmt.Printf("  took ~%dms (concurrent)\n", time.Since(start).Round(10*time.Millisecond).Milliseconds())
+}
+
+// example3AwaitTwice shows the difference from a channel receive
+func example3AwaitTwice() {
+	ctx := context.Background()
+
+	// A value sent on a channel is received exactly once
+	ch := make(chan int, 1)
+	ch <- 42
+	fmt.Println("  channel, 1st receive:", <-ch)
+	select {
+	case v := <-ch:
+		fmt.Println("  channel, 2nd receive:", v)
+	default:
+		fmt.Println("  channel, 2nd receive: nothing left")
+	}
+
+	// A future keeps its value, so every caller sees it
+	f := async.Go(func() (int, error) { return 42, nil })
+	v1, _ := f.Await(ctx)
+	v2, _ := f.Await(ctx)
+	fmt.Println("  future,  1st await:  ", v1)
+	fmt.Println("  future,  2nd await:  ", v2)
+}
+
+// example4All waits for several futures of the same type
+func example4All() {
+	ctx := context.Background()
+	start := time.Now()
+
+	var profiles []*async.Future[Profile]
+	for id := 1; id <= 3; id++ {
+		profiles = append(profiles, async.Go(func() (Profile, error) {
+			return fetchProfile(id)
+		}))
+	}
+
+	all, err := async.All(profiles...).Await(ctx)
+	if err != nil {
+		fmt.Println("  error:", err)
+		return
+	}
+	for _, p := range all {
+		fmt.Println("  got", p.Name)
+	}
+	fmt.Printf("  3 calls took ~%dms, not ~240ms\n", time.Since(start).Round(10*time.Millisecond).Milliseconds())
+
+	// One failure fails the whole group, without waiting for the rest
+	_, err = async.All(
+		async.Go(fetchFrom("slow", 200*time.Millisecond, false)),
+		async.Go(fetchFrom("broken", 10*time.Millisecond, true)),
+	).Await(ctx)
+	fmt.Println("  with a failure:", err)
+}
+
+// example5AnyVsRace contrasts the first success with the first result
+func example5AnyVsRace() {
+	ctx := context.Background()
+
+	mirrors := func() []*async.Future[string] {
+		return []*async.Future[string]{
+			async.Go(fetchFrom("mirror-a", 10*time.Millisecond, true)),
+			async.Go(fetchFrom("mirror-b", 50*time.Millisecond, false)),
+			async.Go(fetchFrom("mirror-c", 90*time.Millisecond, false)),
+		}
+	}
+
+	// Any ignores failures until something succeeds
+	v, err := async.Any(mirrors()...).Await(ctx)
+	fmt.Printf("  Any:  %q, err=%v\n", v, err)
+
+	// Race takes whatever settles first, even a failure
+	v, err = async.Race(mirrors()...).Await(ctx)
+	fmt.Printf("  Race: %q, err=%v\n", v, err)
+}
+
+// example6Timeout gives up waiting without stopping the work
+func example6Timeout() {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Millisecond)
+	defer cancel()
+
+	f := async.Go(fetchFrom("slow", 100*time.Millisecond, false))
+
+	_, err := f.Await(ctx)
+	fmt.Println("  await with timeout:", err)
+
+	// The goroutine kept running; the result is still there
+	v, _ := f.Await(context.Background())
+	fmt.Println("  await again:       ", v)
+}
@@ -26,6 +26,11 @@
 //  5- Bonus: Add timing to show how much faster concurrent
 //     checking is compared to sequential checking
 //
+//  6- Bonus: Check at most 3 URLs at the same time, and print
+//     the results in the same order as the URL list.
+//     Hint: conc.ConcurrentMap from pkg/conc does both, and
+//     replaces the channel and the WaitGroup.
+//
 //
 // EXPECTED OUTPUT (timing will vary):
 //
@@ -3,9 +3,11 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
-	"sync"
 	"time"
+
+	"github.com/inancgumus/learngo/pkg/conc"
 )
 
 // Result holds the check result for a URL
@@ -15,6 +17,9 @@ type Result struct {
 	Error  error
 }
 
+// maxInFlight is how many URLs are checked at the same time
+const maxInFlight = 3
+
 func main() {
 	urls := []string{
 		"https://www.google.com",
@@ -28,29 +33,19 @@ func main() {
 
 	start := time.Now()
 
-	// Create channel to receive results
-	results := make(chan Result, len(urls))
-
-	// WaitGroup to track goroutines
-	var wg sync.WaitGroup
-
-	// Launch a goroutine for each URL
-	for _, url := range urls {
-		wg.Add(1)
-		go func(u string) {
-			defer wg.Done()
-			results <- checkURL(u)
-		}(url)
+	// ConcurrentMap replaces the goroutine-per-URL loop, the results
+	// channel, and the WaitGroup. It also caps the goroutines at
+	// maxInFlight and returns the results in the order of urls.
+	//
+	// checkURL reports an unreachable URL in its Result rather than as
+	// an error, so one bad URL does not cancel the other checks.
+	results, err := conc.ConcurrentMap(context.Background(), urls, maxInFlight, checkURL)
+	if err != nil {
+		fmt.Println("check failed:", err)
+		return
 	}
 
-	// Close results channel when all goroutines complete
-	go func() {
-		wg.Wait()
-		close(results)
-	}()
-
-	// Print results as they come in
-	for result := range results {
+	for _, result := range results {
 		if result.Status == "reachable" {
 			fmt.Printf("%s: %s\n", result.URL, result.Status)
 		} else {
@@ -62,9 +57,9 @@ func main() {
 }
 
 // checkURL makes an HTTP GET request to the URL and returns the result
-func checkURL(url string) Result {
+func checkURL(ctx context.Context, url string) (Result, error) {
 	// Create a context with timeout
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 	defer cancel()
 
 	// Create request with context
@@ -74,7 +69,7 @@ func checkURL(url string) Result {
 			URL:    url,
 			Status: "unreachable",
 			Error:  err,
-		}
+		}, nil
 	}
 
 	// Make the request
@@ -84,13 +79,18 @@ func checkURL(url string) Result {
 			URL:    url,
 			Status: "unreachable",
 			Error:  err,
-		}
+		}, nil
 	}
 	defer resp.Body.Close()
 
+	// Read the body to the end, even though it isn't used: only then
+	// can the connection go back to the pool for the next URL on the
+	// same host. 32-http-servers/05-http-client shows the difference
+	io.Copy(io.Discard, resp.Body)
+
 	return Result{
 		URL:    url,
 		Status: "reachable",
 		Error:  nil,
-	}
+	}, nil
 }
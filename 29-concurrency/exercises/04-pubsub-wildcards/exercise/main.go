@@ -0,0 +1,126 @@
+// ---------------------------------------------------------
+// EXERCISE: Pub/Sub with Wildcard Topics
+//
+//  The broker below only delivers a message when the topic
+//  matches a subscription EXACTLY. Add wildcard subscriptions
+//  so one subscriber can listen to a whole family of topics.
+//
+//  Topics are dot-separated segments: "orders.eu.created"
+//
+//  1- Implement match(pattern, topic string) bool:
+//     - "*" matches exactly one segment
+//         "orders.*.created" matches "orders.eu.created"
+//         "orders.*.created" does NOT match "orders.created"
+//     - ">" matches one or more trailing segments, and is only
+//       allowed as the last segment
+//         "orders.>" matches "orders.eu" and "orders.eu.created"
+//         "orders.>" does NOT match "orders"
+//     - Any other segment must match exactly
+//
+//  2- Change Publish so it delivers to every subscription whose
+//     pattern matches the topic (not only exact matches).
+//     Hint: the map is keyed by pattern, so loop over it.
+//
+//  3- Make sure a subscriber that matches through several
+//     patterns only gets ONE copy of each message.
+//     (Each Subscribe call is one subscriber with one pattern,
+//     so this is automatic if you loop correctly. Think about why.)
+//
+//  4- Bonus: Publishing now loops over every pattern. Keep a
+//     separate map for exact patterns so that publishing to
+//     a topic with only exact subscribers stays a map lookup.
+//
+//
+// EXPECTED OUTPUT:
+//
+//  all-orders    <- orders.eu.created: #1
+//  created       <- orders.eu.created: #1
+//  eu-exact      <- orders.eu.created: #1
+//  all-orders    <- orders.us.created: #2
+//  created       <- orders.us.created: #2
+//  all-orders    <- orders.us.shipped: #3
+//
+// ---------------------------------------------------------
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// subscription receives messages for one topic pattern
+type subscription struct {
+	name string
+	ch   chan string
+}
+
+// broker is a minimal version of pkg/pubsub with exact topic matching
+type broker struct {
+	mu   sync.RWMutex
+	subs map[string][]*subscription // pattern -> subscribers
+}
+
+func newBroker() *broker {
+	return &broker{subs: make(map[string][]*subscription)}
+}
+
+// subscribe registers a named subscriber for a topic pattern
+func (b *broker) subscribe(name, pattern string) *subscription {
+	s := &subscription{name: name, ch: make(chan string, 16)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[pattern] = append(b.subs[pattern], s)
+	return s
+}
+
+// publish delivers msg to subscribers of topic
+// TODO: deliver to every subscription whose pattern matches topic
+func (b *broker) publish(topic, msg string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.subs[topic] {
+		s.ch <- topic + ": " + msg
+	}
+}
+
+// match reports whether topic matches pattern
+// TODO: support "*" and ">" wildcards
+func match(pattern, topic string) bool {
+	return pattern == topic
+}
+
+func main() {
+	b := newBroker()
+
+	subs := []*subscription{
+		b.subscribe("all-orders", "orders.>"),
+		b.subscribe("created", "orders.*.created"),
+		b.subscribe("eu-exact", "orders.eu.created"),
+		b.subscribe("nothing", "orders"),
+	}
+
+	b.publish("orders.eu.created", "#1")
+	b.publish("orders.us.created", "#2")
+	b.publish("orders.us.shipped", "#3")
+
+	// Drain every subscriber and print in a stable order
+	var lines []string
+	for _, s := range subs {
+		close(s.ch)
+		for msg := range s.ch {
+			lines = append(lines, fmt.Sprintf("%-13s <- %s", s.name, msg))
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i][len(lines[i])-2:] < lines[j][len(lines[j])-2:]
+	})
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+
+	_ = match // remove once publish uses match
+}
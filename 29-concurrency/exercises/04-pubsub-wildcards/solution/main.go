@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// subscription receives messages for one topic pattern
+type subscription struct {
+	name string
+	ch   chan string
+}
+
+// broker delivers messages to subscribers whose pattern matches the topic
+type broker struct {
+	mu       sync.RWMutex
+	exact    map[string][]*subscription // patterns without wildcards
+	wildcard map[string][]*subscription // patterns with "*" or ">"
+}
+
+func newBroker() *broker {
+	return &broker{
+		exact:    make(map[string][]*subscription),
+		wildcard: make(map[string][]*subscription),
+	}
+}
+
+// subscribe registers a named subscriber for a topic pattern
+func (b *broker) subscribe(name, pattern string) *subscription {
+	s := &subscription{name: name, ch: make(chan string, 16)}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// Bonus: keep exact patterns in their own map so they stay a lookup
+	if hasWildcard(pattern) {
+		b.wildcard[pattern] = append(b.wildcard[pattern], s)
+	} else {
+		b.exact[pattern] = append(b.exact[pattern], s)
+	}
+	return s
+}
+
+// publish delivers msg to every subscription whose pattern matches topic
+//
+// Each subscription is stored under exactly one pattern, so visiting
+// each pattern once means each subscriber gets at most one copy.
+func (b *broker) publish(topic, msg string) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	for _, s := range b.exact[topic] {
+		s.ch <- topic + ": " + msg
+	}
+
+	for pattern, subs := range b.wildcard {
+		if !match(pattern, topic) {
+			continue
+		}
+		for _, s := range subs {
+			s.ch <- topic + ": " + msg
+		}
+	}
+}
+
+// hasWildcard reports whether a pattern contains a wildcard segment
+func hasWildcard(pattern string) bool {
+	for _, seg := range strings.Split(pattern, ".") {
+		if seg == "*" || seg == ">" {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether topic matches pattern
+//
+//	"*" matches exactly one segment
+//	">" matches one or more trailing segments (last segment only)
+func match(pattern, topic string) bool {
+	p := strings.Split(pattern, ".")
+	t := strings.Split(topic, ".")
+
+	for i, seg := range p {
+		if seg == ">" {
+			// Must be last, and must consume at least one segment
+			return i == len(p)-1 && len(t) > i
+		}
+		if i >= len(t) {
+			return false
+		}
+		if seg != "*" && seg != t[i] {
+			return false
+		}
+	}
+	return len(p) == len(t)
+}
+
+func main() {
+	b := newBroker()
+
+	subs := []*subscription{
+		b.subscribe("all-orders", "orders.>"),
+		b.subscribe("created", "orders.*.created"),
+		b.subscribe("eu-exact", "orders.eu.created"),
+		b.subscribe("nothing", "orders"),
+	}
+
+	b.publish("orders.eu.created", "#1")
+	b.publish("orders.us.created", "#2")
+	b.publish("orders.us.shipped", "#3")
+
+	// Drain every subscriber and print in a stable order
+	var lines []string
+	for _, s := range subs {
+		close(s.ch)
+		for msg := range s.ch {
+			lines = append(lines, fmt.Sprintf("%-13s <- %s", s.name, msg))
+		}
+	}
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i][len(lines[i])-2:] < lines[j][len(lines[j])-2:]
+	})
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+}
@@ -150,7 +150,7 @@ func example4APICallTimeout() {
 
 // example5DatabaseTimeout simulates a database query with timeout
 func example5DatabaseTimeout() {
-	// Simulate database query
+	// Simulate database query; see 07-context-database for real ones
 	queryDatabase := func(ctx context.Context, query string) (string, error) {
 		// Check if context already expired before starting
 		select {
@@ -3,20 +3,27 @@ package main
 import (
 	"context"
 	"fmt"
+	"io"
+	"log/slog"
+	"os"
 	"time"
+
+	"github.com/inancgumus/learngo/pkg/config"
+	"github.com/inancgumus/learngo/pkg/ctxmeta"
 )
 
-// Define type-safe keys to avoid collisions
+// A custom key type avoids collisions, but values still come back as any
 type contextKey string
 
-const (
-	requestIDKey  contextKey = "requestID"
-	userIDKey     contextKey = "userID"
-	correlationID contextKey = "correlationID"
-)
+const legacyUserIDKey contextKey = "userID"
 
-// Alternative: use unexported struct type for even stronger type safety
-type requestContextKey struct{}
+// Typed keys: unique, and the compiler checks the values stored and read
+var (
+	requestIDKey  = ctxmeta.NewKey[string]("requestID")
+	userIDKey     = ctxmeta.NewKey[string]("userID")
+	correlationID = ctxmeta.NewKey[string]("correlationID")
+	userKey       = ctxmeta.NewKey[*User]("user")
+)
 
 func main() {
 	fmt.Println("Context Values and Best Practices")
@@ -87,51 +94,85 @@ func example1BasicValues() {
 
 // example2TypeSafeKeys demonstrates the recommended approach
 func example2TypeSafeKeys() {
-	// Using custom type for keys prevents collisions
-	ctx := context.WithValue(context.Background(), requestIDKey, "req-12345")
-	ctx = context.WithValue(ctx, userIDKey, "user-67890")
+	// Step 1: a custom key type prevents collisions...
+	ctx := context.WithValue(context.Background(), legacyUserIDKey, 67890)
+
+	// ...but the value is still an any: a wrong assertion fails at runtime
+	userID, ok := ctx.Value(legacyUserIDKey).(string)
+	fmt.Printf("   Custom key type:  %q, ok=%v (an int was stored!)\n", userID, ok)
 
-	// Retrieve values using type-safe keys
-	reqID := ctx.Value(requestIDKey)
-	userID := ctx.Value(userIDKey)
+	// Step 2: a typed key from pkg/ctxmeta also fixes the value's type
+	ctx = ctxmeta.WithValue(context.Background(), requestIDKey, "req-12345")
+	ctx = ctxmeta.WithValue(ctx, userIDKey, "user-67890")
+	// ctxmeta.WithValue(ctx, userIDKey, 67890) would not compile
 
-	fmt.Printf("   Request ID: %v\n", reqID)
-	fmt.Printf("   User ID: %v\n", userID)
+	reqID, _ := ctxmeta.Value(ctx, requestIDKey) // reqID is a string
+	userID, _ = ctxmeta.Value(ctx, userIDKey)
+
+	fmt.Printf("   Typed keys:       request=%s user=%s\n", reqID, userID)
 	fmt.Println()
-	fmt.Println("   Benefits of custom types:")
+	fmt.Println("   Benefits of typed keys:")
 	fmt.Println("   - No collisions with other packages")
 	fmt.Println("   - Clear intent and documentation")
-	fmt.Println("   - Type safety at compile time")
+	fmt.Println("   - Type safety at compile time, no type assertions")
 }
 
 // example3RequestScoped demonstrates request-scoped metadata
 func example3RequestScoped() {
 	// Simulating HTTP request with request ID and correlation ID
 	ctx := context.Background()
-	ctx = context.WithValue(ctx, requestIDKey, "req-abc123")
-	ctx = context.WithValue(ctx, correlationID, "corr-xyz789")
+	ctx = ctxmeta.WithValue(ctx, requestIDKey, "req-abc123")
+	ctx = ctxmeta.WithValue(ctx, correlationID, "corr-xyz789")
 
+	log := newLogger()
 	fmt.Println("   Incoming request:")
-	logRequest(ctx, "Processing user login")
+	logRequest(ctx, log, "Processing user login")
 
 	// Simulate calling another service
 	fmt.Println()
-	callExternalService(ctx)
+	callExternalService(ctx, log)
 }
 
-// logRequest demonstrates using context values for logging
-func logRequest(ctx context.Context, message string) {
-	reqID := ctx.Value(requestIDKey)
-	corrID := ctx.Value(correlationID)
-
-	fmt.Printf("   [RequestID: %v] [CorrelationID: %v] %s\n", reqID, corrID, message)
+// logRequest logs message with the IDs from the context as attributes,
+// so a log search can find every line of one request
+func logRequest(ctx context.Context, log *slog.Logger, message string) {
+	log.With(
+		slog.String("request_id", ctxmeta.ValueOr(ctx, requestIDKey, "-")),
+		slog.String("correlation_id", ctxmeta.ValueOr(ctx, correlationID, "-")),
+	).InfoContext(ctx, message)
 }
 
 // callExternalService shows values propagating to external calls
-func callExternalService(ctx context.Context) {
-	logRequest(ctx, "Calling external authentication service")
+func callExternalService(ctx context.Context, log *slog.Logger) {
+	logRequest(ctx, log, "Calling external authentication service")
 	time.Sleep(50 * time.Millisecond)
-	logRequest(ctx, "External service responded")
+	logRequest(ctx, log, "External service responded")
+}
+
+// newLogger returns a logger whose lines are indented like the rest of
+// the output, and have no time, so runs compare easily
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(indent{os.Stdout}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// indent writes every line three spaces in. A slog handler writes a
+// whole line per Write call
+type indent struct {
+	w io.Writer
+}
+
+func (i indent) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(i.w, "   "); err != nil {
+		return 0, err
+	}
+	return i.w.Write(p)
 }
 
 // User represents an authenticated user
@@ -141,9 +182,6 @@ type User struct {
 	Role     string
 }
 
-// userKey is an unexported type for stronger type safety
-type userKey struct{}
-
 // example4AuthData demonstrates storing auth information in context
 func example4AuthData() {
 	// After authentication, store user in context
@@ -153,7 +191,7 @@ func example4AuthData() {
 		Role:     "admin",
 	}
 
-	ctx := context.WithValue(context.Background(), userKey{}, user)
+	ctx := ctxmeta.WithValue(context.Background(), userKey, user)
 
 	// Pass context through application layers
 	handleAuthenticatedRequest(ctx)
@@ -161,8 +199,8 @@ func example4AuthData() {
 
 // handleAuthenticatedRequest shows using auth data from context
 func handleAuthenticatedRequest(ctx context.Context) {
-	// Extract user from context
-	user, ok := ctx.Value(userKey{}).(*User)
+	// Extract user from context: user is already a *User
+	user, ok := ctxmeta.Value(ctx, userKey)
 	if !ok {
 		fmt.Println("   Error: No authenticated user in context")
 		return
@@ -181,7 +219,7 @@ func handleAuthenticatedRequest(ctx context.Context) {
 
 // performAdminAction demonstrates accessing user info deep in call chain
 func performAdminAction(ctx context.Context) {
-	user, ok := ctx.Value(userKey{}).(*User)
+	user, ok := ctxmeta.Value(ctx, userKey)
 	if !ok || user.Role != "admin" {
 		fmt.Println("   Unauthorized: Admin access required")
 		return
@@ -193,19 +231,19 @@ func performAdminAction(ctx context.Context) {
 // example5ValuePropagation shows how values flow through contexts
 func example5ValuePropagation() {
 	// Parent context with value
-	parent := context.WithValue(context.Background(), requestIDKey, "req-parent")
+	parent := ctxmeta.WithValue(context.Background(), requestIDKey, "req-parent")
 
 	// Child context inherits parent values
-	child := context.WithValue(parent, userIDKey, "user-child")
+	child := ctxmeta.WithValue(parent, userIDKey, "user-child")
 
 	// Grandchild context inherits both
-	grandchild := context.WithValue(child, correlationID, "corr-grandchild")
+	grandchild := ctxmeta.WithValue(child, correlationID, "corr-grandchild")
 
 	// All values are accessible from grandchild
 	fmt.Printf("   From grandchild context:\n")
-	fmt.Printf("   - Request ID: %v (from parent)\n", grandchild.Value(requestIDKey))
-	fmt.Printf("   - User ID: %v (from child)\n", grandchild.Value(userIDKey))
-	fmt.Printf("   - Correlation ID: %v (from grandchild)\n", grandchild.Value(correlationID))
+	fmt.Printf("   - Request ID: %s (from parent)\n", ctxmeta.ValueOr(grandchild, requestIDKey, ""))
+	fmt.Printf("   - User ID: %s (from child)\n", ctxmeta.ValueOr(grandchild, userIDKey, ""))
+	fmt.Printf("   - Correlation ID: %s (from grandchild)\n", ctxmeta.ValueOr(grandchild, correlationID, ""))
 	fmt.Println()
 	fmt.Println("   Note: Child contexts inherit all parent values")
 }
@@ -245,7 +283,8 @@ func badFetchData(ctx context.Context, url string) {
 	fmt.Println("   ^ This makes the function signature misleading!")
 }
 
-// example7AntiPatternConfig shows WRONG use for configuration
+// example7AntiPatternConfig shows WRONG use for configuration, and what
+// replaces it
 func example7AntiPatternConfig() {
 	fmt.Println("   BAD: Using context for application configuration")
 	fmt.Println()
@@ -264,10 +303,16 @@ func example7AntiPatternConfig() {
 	fmt.Println("   - Violates principle of explicit dependencies")
 	fmt.Println("   - No compile-time type checking")
 	fmt.Println()
-	fmt.Println("   CORRECT APPROACH:")
-	fmt.Println("   Use a Config struct passed to constructors:")
-	fmt.Println("   func NewDatabase(config *DBConfig) (*DB, error)")
-	fmt.Println("   Or use dependency injection")
+
+	// RIGHT: load it once at startup, into a struct, and pass it in
+	fmt.Println("   CORRECT APPROACH: load a Config struct once, and pass it to constructors:")
+	cfg, err := loadAppConfig(os.LookupEnv)
+	if err != nil {
+		fmt.Printf("   Config error: %v\n", err)
+		return
+	}
+	initializeApp(cfg)
+	fmt.Println("   See 35-files-io/10-config for defaults, files, env, and flags")
 }
 
 // badInitializeApp demonstrates the anti-pattern
@@ -281,6 +326,36 @@ func badInitializeApp(ctx context.Context) {
 	fmt.Println("   ^ Configuration should be passed explicitly, not through context!")
 }
 
+// AppConfig is what the app needs to start. The compiler checks every
+// field's type, and a test builds one as a literal
+type AppConfig struct {
+	DBHost   string
+	DBPort   int
+	LogLevel string
+}
+
+// loadAppConfig reads the defaults and the APP_ environment variables
+// with pkg/config, and reports every bad value at once
+func loadAppConfig(getenv func(string) (string, bool)) (AppConfig, error) {
+	c := config.New()
+	c.SetDefaults(map[string]string{"db.host": "localhost", "db.port": "5432", "log.level": "debug"})
+	c.UseEnv("APP_", getenv)
+
+	b := config.NewBinder(c)
+	cfg := AppConfig{
+		DBHost:   config.Bind[string](b, "db.host"),
+		DBPort:   config.Bind[int](b, "db.port"),
+		LogLevel: config.Bind[string](b, "log.level"),
+	}
+	return cfg, b.Err()
+}
+
+// initializeApp takes its configuration as a parameter: the signature
+// shows what it depends on
+func initializeApp(cfg AppConfig) {
+	fmt.Printf("   Initializing app with host=%s, port=%d, log=%s\n", cfg.DBHost, cfg.DBPort, cfg.LogLevel)
+}
+
 // example8GoodUseCases summarizes when to use context values
 func example8GoodUseCases() {
 	fmt.Println("   GOOD use cases for context values:")
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// UpstreamError is a rich cancellation reason: which service failed, and how
+type UpstreamError struct {
+	Service string
+	Status  int
+}
+
+func (e *UpstreamError) Error() string {
+	return fmt.Sprintf("%s returned %d", e.Service, e.Status)
+}
+
+// ErrShuttingDown is a sentinel cause for a server shutdown
+var ErrShuttingDown = errors.New("server is shutting down")
+
+func main() {
+	fmt.Println("Cancellation Causes")
+	fmt.Println("===================")
+	fmt.Println()
+
+	// Example 1: The problem - ctx.Err() says only "canceled"
+	fmt.Println("1. The problem with ctx.Err():")
+	example1Problem()
+	fmt.Println()
+
+	// Example 2: WithCancelCause and Cause
+	fmt.Println("2. WithCancelCause and Cause:")
+	example2CancelCause()
+	fmt.Println()
+
+	// Example 3: Workers learn why they were stopped
+	fmt.Println("3. Workers reading the cause:")
+	example3Workers()
+	fmt.Println()
+
+	// Example 4: Timeouts and deadlines with a cause
+	fmt.Println("4. WithTimeoutCause and WithDeadlineCause:")
+	example4TimeoutCause()
+	fmt.Println()
+
+	// Example 5: How causes propagate to children
+	fmt.Println("5. Causes and child contexts:")
+	example5Propagation()
+	fmt.Println()
+
+	// Example 6: Typed causes with errors.As
+	fmt.Println("6. Typed causes with errors.As:")
+	example6TypedCause()
+}
+
+// example1Problem shows that every cancellation looks the same through Err
+func example1Problem() {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Was it the user? A failed dependency? A shutdown? Err can't say.
+	cancel()
+
+	fmt.Println("   ctx.Err():", ctx.Err())
+}
+
+// example2CancelCause attaches a reason to the cancellation
+func example2CancelCause() {
+	ctx, cancel := context.WithCancelCause(context.Background())
+
+	cancel(&UpstreamError{Service: "payments", Status: 503})
+
+	// Err stays the same, so existing checks keep working...
+	fmt.Println("   ctx.Err():          ", ctx.Err())
+	fmt.Println("   Err is Canceled:    ", errors.Is(ctx.Err(), context.Canceled))
+
+	// ...and Cause returns the reason
+	fmt.Println("   context.Cause(ctx): ", context.Cause(ctx))
+
+	// Only the first cancel counts, like with WithCancel
+	cancel(ErrShuttingDown)
+	fmt.Println("   after 2nd cancel:   ", context.Cause(ctx))
+}
+
+// example3Workers cancels sibling workers with the error of the one that failed
+func example3Workers() {
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	services := []string{"users", "payments", "inventory"}
+	var wg sync.WaitGroup
+
+	for i, service := range services {
+		wg.Go(func() {
+			// payments fails quickly; the others would take longer
+			if service == "payments" {
+				time.Sleep(50 * time.Millisecond)
+				cancel(&UpstreamError{Service: service, Status: 503})
+				return
+			}
+
+			select {
+			case <-time.After(time.Duration(i+1) * time.Second):
+				fmt.Printf("   %-9s done\n", service)
+			case <-ctx.Done():
+				// Without Cause, this would print "context canceled"
+				fmt.Printf("   %-9s stopped: %v\n", service, context.Cause(ctx))
+			}
+		})
+	}
+
+	wg.Wait()
+}
+
+// example4TimeoutCause names the timeout instead of "deadline exceeded"
+func example4TimeoutCause() {
+	errSlowQuery := errors.New("report query took longer than 50ms")
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 50*time.Millisecond, errSlowQuery)
+	defer cancel()
+
+	<-ctx.Done()
+	fmt.Println("   ctx.Err():         ", ctx.Err())
+	fmt.Println("   context.Cause(ctx):", context.Cause(ctx))
+
+	// WithDeadlineCause is the same with an absolute time
+	deadline := time.Now().Add(50 * time.Millisecond)
+	ctx2, cancel2 := context.WithDeadlineCause(context.Background(), deadline, ErrShuttingDown)
+	defer cancel2()
+
+	<-ctx2.Done()
+	fmt.Println("   deadline cause:    ", context.Cause(ctx2))
+
+	// The cause is only used when the deadline fires; cancel() itself
+	// sets no cause, so Cause falls back to Err
+	ctx3, cancel3 := context.WithTimeoutCause(context.Background(), time.Hour, errSlowQuery)
+	cancel3()
+	fmt.Println("   cancelled early:   ", context.Cause(ctx3))
+}
+
+// example5Propagation shows which contexts see the cause
+func example5Propagation() {
+	parent, cancel := context.WithCancelCause(context.Background())
+	child, cancelChild := context.WithCancel(parent)
+	defer cancelChild()
+
+	cancel(ErrShuttingDown)
+
+	// Children inherit the parent's cause
+	fmt.Println("   child cause:     ", context.Cause(child))
+
+	// A nil cause means plain context.Canceled
+	ctx, cancelNil := context.WithCancelCause(context.Background())
+	cancelNil(nil)
+	fmt.Println("   nil cause:       ", context.Cause(ctx))
+
+	// Not cancelled yet: Cause is nil, like Err
+	fresh, cancelFresh := context.WithCancelCause(context.Background())
+	defer cancelFresh(nil)
+	fmt.Println("   not cancelled:   ", context.Cause(fresh))
+}
+
+// example6TypedCause recovers the details of a cause for decisions
+func example6TypedCause() {
+	for _, cause := range []error{
+		&UpstreamError{Service: "payments", Status: 503},
+		&UpstreamError{Service: "search", Status: 400},
+		ErrShuttingDown,
+	} {
+		ctx, cancel := context.WithCancelCause(context.Background())
+		cancel(fmt.Errorf("checkout: %w", cause))
+
+		fmt.Printf("   %-35v -> %s\n", context.Cause(ctx), decide(ctx))
+	}
+}
+
+// decide picks a reaction based on why ctx was cancelled
+func decide(ctx context.Context) string {
+	cause := context.Cause(ctx)
+
+	var upstream *UpstreamError
+	switch {
+	case errors.As(cause, &upstream) && upstream.Status >= 500:
+		return "retry later"
+	case errors.As(cause, &upstream):
+		return "report a bug"
+	case errors.Is(cause, ErrShuttingDown):
+		return "save progress"
+	default:
+		return "give up"
+	}
+}
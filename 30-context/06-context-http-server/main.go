@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/sloghelpers"
+)
+
+// errQueryTimeout is the cause used when a query takes longer than the
+// server allows
+var errQueryTimeout = errors.New("report query timed out")
+
+// Store simulates a slow database
+type Store struct {
+	delay time.Duration
+}
+
+// Report "runs a query" that takes s.delay, unless ctx ends first
+func (s *Store) Report(ctx context.Context, id string) (string, error) {
+	select {
+	case <-time.After(s.delay):
+		return "report " + id + ": 42 orders", nil
+	case <-ctx.Done():
+		// A real driver would also tell the database to stop
+		return "", fmt.Errorf("query aborted: %w", context.Cause(ctx))
+	}
+}
+
+// Server serves reports from a Store
+type Server struct {
+	store        *Store
+	queryTimeout time.Duration
+	log          *slog.Logger
+
+	// onQueryDone, if set, is called after every query; tests use it
+	onQueryDone func(err error)
+}
+
+// Handler returns the server's routes
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /report/{id}", s.handleReport)
+	return mux
+}
+
+// handleReport runs a query with the request's context
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	// r.Context() is cancelled when the client disconnects, when the
+	// request is done, or when the server shuts down
+	ctx := r.Context()
+
+	// The server's own limit, on top of whatever the client does
+	if s.queryTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, s.queryTimeout, errQueryTimeout)
+		defer cancel()
+	}
+
+	report, err := s.store.Report(ctx, r.PathValue("id"))
+	if s.onQueryDone != nil {
+		s.onQueryDone(err)
+	}
+
+	switch {
+	case err == nil:
+		fmt.Fprintln(w, report)
+	case errors.Is(err, errQueryTimeout):
+		s.log.WarnContext(ctx, "query timed out", "path", r.URL.Path, sloghelpers.Err(err))
+		http.Error(w, "report took too long", http.StatusGatewayTimeout)
+	case r.Context().Err() != nil:
+		// The client is gone: nobody will read a response
+		s.log.InfoContext(ctx, "client went away", "path", r.URL.Path, sloghelpers.Err(err))
+	default:
+		s.log.ErrorContext(ctx, "query failed", "path", r.URL.Path, sloghelpers.Err(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+	}
+}
+
+// newLogger returns the server's logger. Its lines are prefixed to tell
+// them apart from the client's, and have no time, so runs compare easily
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(prefixWriter{os.Stdout, "   server: "}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// prefixWriter writes prefix before every Write. A slog handler writes
+// one whole line per call
+type prefixWriter struct {
+	w      io.Writer
+	prefix string
+}
+
+func (p prefixWriter) Write(b []byte) (int, error) {
+	if _, err := io.WriteString(p.w, p.prefix); err != nil {
+		return 0, err
+	}
+	return p.w.Write(b)
+}
+
+func main() {
+	fmt.Println("Context in an HTTP Server")
+	fmt.Println("=========================")
+	fmt.Println()
+
+	log := newLogger()
+	store := &Store{delay: 200 * time.Millisecond}
+
+	base, stop, err := serve(&Server{store: store, queryTimeout: time.Second, log: log})
+	if err != nil {
+		fmt.Println("serve:", err)
+		return
+	}
+	defer stop()
+
+	// Example 1: The query finishes in time
+	fmt.Println("1. A request that completes:")
+	get(context.Background(), base+"/report/1")
+	fmt.Println()
+
+	// Example 2: The client gives up, which cancels r.Context()
+	fmt.Println("2. The client disconnects mid-request:")
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	get(ctx, base+"/report/2")
+	cancel()
+	time.Sleep(50 * time.Millisecond) // let the server log
+	fmt.Println()
+
+	// Example 3: The server's own deadline, shorter than the query
+	fmt.Println("3. The server's query timeout:")
+	strict, stopStrict, err := serve(&Server{store: store, queryTimeout: 100 * time.Millisecond, log: log})
+	if err != nil {
+		fmt.Println("serve:", err)
+		return
+	}
+	defer stopStrict()
+
+	get(context.Background(), strict+"/report/3")
+}
+
+// serve starts srv on a random free port, so the example never clashes
+// with anything, and returns its base URL and a function to stop it
+func serve(srv *Server) (string, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+
+	hs := &http.Server{Handler: srv.Handler()}
+	go hs.Serve(ln)
+
+	stop := func() { hs.Shutdown(context.Background()) }
+	return "http://" + ln.Addr().String(), stop, nil
+}
+
+// get makes a request and prints the response, or why there was none
+func get(ctx context.Context, url string) {
+	start := time.Now()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		fmt.Println("   client:", err)
+		return
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Printf("   client: gave up after ~%dms: %v\n", ms(time.Since(start)), errors.Unwrap(err))
+		return
+	}
+	defer resp.Body.Close()
+
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("   client: %s after ~%dms: %s\n", resp.Status, ms(time.Since(start)), strings.TrimSpace(string(body)))
+}
+
+// ms rounds a duration to tens of milliseconds for stable output
+func ms(d time.Duration) int64 {
+	return d.Round(10 * time.Millisecond).Milliseconds()
+}
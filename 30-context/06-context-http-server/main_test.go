@@ -0,0 +1,111 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// newTestServer returns a Server whose queries take delay, and a channel
+// that receives the error of every finished query
+func newTestServer(t *testing.T, delay, timeout time.Duration) (*Server, <-chan error) {
+	queries := make(chan error, 1)
+	srv := &Server{
+		store:        &Store{delay: delay},
+		queryTimeout: timeout,
+		log:          slog.New(slog.NewTextHandler(t.Output(), nil)),
+		onQueryDone:  func(err error) { queries <- err },
+	}
+	return srv, queries
+}
+
+func TestReportCompletes(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		srv, _ := newTestServer(t, time.Second, time.Minute)
+
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report/7", nil))
+
+		if rec.Code != http.StatusOK {
+			t.Fatalf("want 200; got %d", rec.Code)
+		}
+		if body := rec.Body.String(); !strings.Contains(body, "report 7") {
+			t.Errorf("unexpected body %q", body)
+		}
+	})
+}
+
+func TestCancelledRequestAbortsQuery(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		srv, queries := newTestServer(t, time.Hour, 0)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req := httptest.NewRequestWithContext(ctx, http.MethodGet, "/report/7", nil)
+
+		start := time.Now()
+		go srv.Handler().ServeHTTP(httptest.NewRecorder(), req)
+
+		// Cancel mid-request, the way net/http does on a disconnect
+		time.Sleep(100 * time.Millisecond)
+		cancel()
+
+		err := <-queries
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want the query aborted with Canceled; got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed != 100*time.Millisecond {
+			t.Errorf("want the query to stop at 100ms; got %v", elapsed)
+		}
+	})
+}
+
+func TestQueryTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		srv, queries := newTestServer(t, time.Hour, 2*time.Second)
+
+		rec := httptest.NewRecorder()
+		srv.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/report/7", nil))
+
+		if rec.Code != http.StatusGatewayTimeout {
+			t.Errorf("want 504; got %d", rec.Code)
+		}
+		if err := <-queries; !errors.Is(err, errQueryTimeout) {
+			t.Errorf("want errQueryTimeout as the cause; got %v", err)
+		}
+	})
+}
+
+// TestClientDisconnect uses a real server and a real connection: when
+// the client goes away, net/http cancels r.Context() on its own.
+func TestClientDisconnect(t *testing.T) {
+	srv, queries := newTestServer(t, time.Hour, 0)
+
+	ts := httptest.NewServer(srv.Handler())
+	defer ts.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, ts.URL+"/report/7", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ts.Client().Do(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want the client to time out; got %v", err)
+	}
+
+	select {
+	case err := <-queries:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want the query aborted with Canceled; got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("query still running 5s after the client disconnected")
+	}
+}
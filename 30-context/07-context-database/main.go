@@ -0,0 +1,214 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	_ "modernc.org/sqlite" // a pure-Go SQLite driver, registered as "sqlite"
+)
+
+// slowQuery counts forever, so it only stops when it is interrupted
+const slowQuery = `
+	WITH RECURSIVE counter(n) AS (
+		SELECT 1 UNION ALL SELECT n + 1 FROM counter
+	)
+	SELECT count(*) FROM counter`
+
+func main() {
+	fmt.Println("Context with database/sql")
+	fmt.Println("=========================")
+	fmt.Println()
+
+	dir, err := os.MkdirTemp("", "ctxdb")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := openDB(context.Background(), filepath.Join(dir, "shop.db"))
+	if err != nil {
+		fmt.Println("open:", err)
+		return
+	}
+	defer db.Close()
+
+	// Example 1: Queries that finish in time
+	fmt.Println("1. QueryContext within a deadline:")
+	example1Query(db)
+	fmt.Println()
+
+	// Example 2: A slow query interrupted by a timeout
+	fmt.Println("2. A slow query cancelled by a timeout:")
+	example2SlowQuery(db)
+	fmt.Println()
+
+	// Example 3: A transaction whose context ends
+	fmt.Println("3. ExecContext in a cancelled transaction:")
+	example3Transaction(db)
+	fmt.Println()
+
+	// Example 4: Cancelling while reading rows
+	fmt.Println("4. Cancelling while iterating rows:")
+	example4Rows(db)
+	fmt.Println()
+
+	// Example 5: Waiting for a free connection
+	fmt.Println("5. Connection checkout respects the context:")
+	example5Checkout(db)
+}
+
+// openDB opens a SQLite database at path and seeds it with some orders
+func openDB(ctx context.Context, path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Open does not connect; PingContext does, and respects ctx
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	if _, err := db.ExecContext(ctx, `CREATE TABLE orders (id INTEGER PRIMARY KEY, total INTEGER)`); err != nil {
+		db.Close()
+		return nil, err
+	}
+	for i := 1; i <= 100; i++ {
+		if _, err := db.ExecContext(ctx, `INSERT INTO orders (total) VALUES (?)`, i*10); err != nil {
+			db.Close()
+			return nil, err
+		}
+	}
+	return db, nil
+}
+
+// example1Query runs ordinary queries with a deadline
+func example1Query(db *sql.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	var count, sum int
+	err := db.QueryRowContext(ctx, `SELECT count(*), sum(total) FROM orders`).Scan(&count, &sum)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	fmt.Printf("   %d orders, total %d\n", count, sum)
+}
+
+// example2SlowQuery lets a timeout stop a query that never ends
+func example2SlowQuery(db *sql.DB) {
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var n int
+	err := db.QueryRowContext(ctx, slowQuery).Scan(&n)
+
+	// The driver interrupts SQLite itself: the query really stops,
+	// it is not just abandoned while it keeps running
+	fmt.Printf("   stopped after ~%dms: %v\n", ms(time.Since(start)), err)
+	fmt.Println("   errors.Is(err, DeadlineExceeded):", errors.Is(err, context.DeadlineExceeded))
+}
+
+// example3Transaction shows a transaction rolled back by its context
+func example3Transaction(db *sql.DB) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		fmt.Println("   begin:", err)
+		cancel()
+		return
+	}
+	defer tx.Rollback() // a no-op once the transaction is finished
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM orders`); err != nil {
+		fmt.Println("   delete:", err)
+	}
+
+	// Cancelling the context given to BeginTx rolls the transaction back
+	cancel()
+
+	_, err = tx.ExecContext(ctx, `INSERT INTO orders (total) VALUES (1)`)
+	fmt.Println("   exec after cancel:  ", err)
+	fmt.Println("   commit after cancel:", tx.Commit())
+
+	var count int
+	db.QueryRowContext(context.Background(), `SELECT count(*) FROM orders`).Scan(&count)
+	fmt.Println("   orders still in the table:", count)
+}
+
+// example4Rows cancels a query that would produce rows forever
+func example4Rows(db *sql.DB) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rows, err := db.QueryContext(ctx, `
+		WITH RECURSIVE counter(n) AS (
+			SELECT 1 UNION ALL SELECT n + 1 FROM counter
+		)
+		SELECT n FROM counter`)
+	if err != nil {
+		fmt.Println("   query:", err)
+		return
+	}
+	defer rows.Close()
+
+	read := 0
+	for rows.Next() {
+		read++
+		if read == 3 {
+			cancel() // e.g. the client went away
+		}
+	}
+
+	// Next returns false both at the end and on an error: always check
+	fmt.Println("   the loop ended after cancel, read more than 3 rows:", read > 3)
+	fmt.Println("   rows.Err():", rows.Err())
+}
+
+// example5Checkout waits for a connection from a pool of one
+func example5Checkout(db *sql.DB) {
+	db.SetMaxOpenConns(1)
+	defer db.SetMaxOpenConns(0)
+
+	// Hold the only connection, like a long transaction would
+	conn, err := db.Conn(context.Background())
+	if err != nil {
+		fmt.Println("   conn:", err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	var count int
+	err = db.QueryRowContext(ctx, `SELECT count(*) FROM orders`).Scan(&count)
+	fmt.Printf("   pool exhausted: gave up after ~%dms: %v\n", ms(time.Since(start)), err)
+
+	stats := db.Stats()
+	fmt.Printf("   waited for a connection %d time(s)\n", stats.WaitCount)
+
+	// Returning the connection lets the next query through
+	conn.Close()
+
+	ctx2, cancel2 := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel2()
+
+	err = db.QueryRowContext(ctx2, `SELECT count(*) FROM orders`).Scan(&count)
+	fmt.Printf("   connection released: %d orders, err = %v\n", count, err)
+}
+
+// ms rounds a duration to tens of milliseconds for stable output
+func ms(d time.Duration) int64 {
+	return d.Round(10 * time.Millisecond).Milliseconds()
+}
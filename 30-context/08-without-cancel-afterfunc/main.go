@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// requestIDKey is the context key for the request ID in these examples
+type requestIDKey struct{}
+
+func main() {
+	fmt.Println("WithoutCancel and AfterFunc")
+	fmt.Println("===========================")
+	fmt.Println()
+
+	// Example 1: Background work dies with the request
+	fmt.Println("1. The problem: work tied to a finished request:")
+	example1Problem()
+	fmt.Println()
+
+	// Example 2: Detaching with WithoutCancel
+	fmt.Println("2. Detaching with WithoutCancel:")
+	example2WithoutCancel()
+	fmt.Println()
+
+	// Example 3: Detached, but still bounded
+	fmt.Println("3. WithoutCancel plus a timeout of its own:")
+	example3DetachedTimeout()
+	fmt.Println()
+
+	// Example 4: Manual <-ctx.Done() goroutines
+	fmt.Println("4. Cleanup with a <-ctx.Done() goroutine:")
+	example4ManualCleanup()
+	fmt.Println()
+
+	// Example 5: Cleanup with AfterFunc
+	fmt.Println("5. Cleanup with context.AfterFunc:")
+	example5AfterFunc()
+	fmt.Println()
+
+	// Example 6: Making a blocking call cancellable
+	fmt.Println("6. Unblocking a read with AfterFunc:")
+	example6UnblockRead()
+}
+
+// auditLog pretends to write an audit record, which takes a while
+func auditLog(ctx context.Context, event string) error {
+	select {
+	case <-time.After(100 * time.Millisecond):
+		fmt.Printf("   audit [%v]: %s\n", ctx.Value(requestIDKey{}), event)
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// handle simulates a request handler that starts background work and
+// returns; the request context is cancelled as soon as it returns
+func handle(detach bool) *sync.WaitGroup {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, requestIDKey{}, "req-42")
+	defer cancel() // what net/http does when the handler returns
+
+	var wg sync.WaitGroup
+	bg := ctx
+	if detach {
+		bg = context.WithoutCancel(ctx)
+	}
+
+	wg.Go(func() {
+		if err := auditLog(bg, "order placed"); err != nil {
+			fmt.Println("   audit failed:", err)
+		}
+	})
+
+	fmt.Println("   handler returned")
+	return &wg
+}
+
+// example1Problem loses the audit record when the request ends
+func example1Problem() {
+	handle(false).Wait()
+}
+
+// example2WithoutCancel keeps the audit record alive after the request
+func example2WithoutCancel() {
+	handle(true).Wait()
+
+	// What a detached context keeps and what it drops
+	parent, cancel := context.WithTimeout(context.Background(), time.Second)
+	parent = context.WithValue(parent, requestIDKey{}, "req-42")
+	cancel()
+
+	detached := context.WithoutCancel(parent)
+	_, hasDeadline := detached.Deadline()
+
+	fmt.Println("   parent.Err():    ", parent.Err())
+	fmt.Println("   detached.Err():  ", detached.Err())
+	fmt.Println("   detached value:  ", detached.Value(requestIDKey{}))
+	fmt.Println("   has deadline:    ", hasDeadline)
+	fmt.Println("   Done() is nil:   ", detached.Done() == nil)
+}
+
+// example3DetachedTimeout gives detached work its own limit
+func example3DetachedTimeout() {
+	ctx, cancel := context.WithCancel(context.Background())
+	ctx = context.WithValue(ctx, requestIDKey{}, "req-43")
+	cancel() // the request is already over
+
+	// Never detach without a limit: this work must not run forever
+	bg, cancelBG := context.WithTimeout(context.WithoutCancel(ctx), 50*time.Millisecond)
+	defer cancelBG()
+
+	if err := auditLog(bg, "slow audit backend"); err != nil {
+		fmt.Println("   audit gave up:", err)
+	}
+}
+
+// example4ManualCleanup shows the goroutine that waits forever when the
+// context is never cancelled
+func example4ManualCleanup() {
+	before := runtime.NumGoroutine()
+
+	for range 3 {
+		ctx := context.Background() // never cancelled
+
+		go func() {
+			<-ctx.Done() // blocks forever
+			fmt.Println("   cleanup")
+		}()
+	}
+
+	time.Sleep(10 * time.Millisecond)
+	fmt.Printf("   goroutines waiting: %d\n", runtime.NumGoroutine()-before)
+}
+
+// example5AfterFunc registers cleanup without a waiting goroutine
+func example5AfterFunc() {
+	before := runtime.NumGoroutine()
+
+	// Registered, but the context is never cancelled: no goroutine waits
+	stop := context.AfterFunc(context.Background(), func() {
+		fmt.Println("   never runs")
+	})
+	fmt.Printf("   goroutines waiting: %d\n", runtime.NumGoroutine()-before)
+
+	// stop unregisters it; true means it had not run yet
+	fmt.Println("   stop() before cancel:", stop())
+
+	// On cancellation the function runs in its own goroutine
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	stop = context.AfterFunc(ctx, func() {
+		fmt.Println("   cleanup ran after cancel")
+		close(done)
+	})
+
+	cancel()
+	<-done
+	fmt.Println("   stop() after it ran: ", stop())
+}
+
+// example6UnblockRead cancels a Read that knows nothing about contexts
+func example6UnblockRead() {
+	r, w := io.Pipe()
+	defer r.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// When ctx ends, close the writing side: the blocked Read returns
+	// the error passed to CloseWithError
+	stop := context.AfterFunc(ctx, func() {
+		w.CloseWithError(context.Cause(ctx))
+	})
+	defer stop()
+
+	buf := make([]byte, 16)
+	_, err := r.Read(buf) // nobody writes, so this blocks until ctx ends
+	fmt.Println("   read returned:", err)
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/ctxio"
+)
+
+const (
+	KB = 1 << 10
+	MB = 1 << 20
+)
+
+// errUserStop is the cause used when the "user" stops a download
+var errUserStop = errors.New("user pressed stop")
+
+func main() {
+	fmt.Println("Cancellable I/O")
+	fmt.Println("===============")
+	fmt.Println()
+
+	// Example 1: io.Copy ignores contexts
+	fmt.Println("1. io.Copy does not know about contexts:")
+	example1PlainCopy()
+	fmt.Println()
+
+	// Example 2: Wrapping the reader
+	fmt.Println("2. Copying through ctxio.NewReader:")
+	example2CtxReader()
+	fmt.Println()
+
+	// Example 3: Streaming a large file, cancelled part way
+	fmt.Println("3. Streaming a large file with progress:")
+	example3StreamFile()
+	fmt.Println()
+
+	// Example 4: Wrapping the writer
+	fmt.Println("4. One large Write through ctxio.NewWriter:")
+	example4CtxWriter()
+	fmt.Println()
+
+	// Example 5: A Read that is already blocked
+	fmt.Println("5. Interrupting a blocked Read:")
+	example5BlockedRead()
+}
+
+// slowReader produces zero bytes, up to 64KB at a time, sleeping
+// before each chunk like a slow disk or network
+type slowReader struct {
+	left  int
+	delay time.Duration
+}
+
+func (s *slowReader) Read(p []byte) (int, error) {
+	if s.left == 0 {
+		return 0, io.EOF
+	}
+	time.Sleep(s.delay)
+
+	n := min(len(p), s.left, 64*KB)
+	clear(p[:n])
+	s.left -= n
+	return n, nil
+}
+
+// example1PlainCopy shows a copy running long past its deadline
+func example1PlainCopy() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	src := &slowReader{left: 512 * KB, delay: 2 * time.Millisecond}
+
+	// ctx is right there, but io.Copy has no way to use it
+	n, err := io.Copy(io.Discard, src)
+
+	fmt.Printf("   copied %d KB in ~%dms, err=%v\n", n/KB, ms(time.Since(start)), err)
+	fmt.Println("   ctx.Err():", ctx.Err())
+}
+
+// example2CtxReader stops the same copy at the deadline
+func example2CtxReader() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	src := &slowReader{left: 512 * KB, delay: 2 * time.Millisecond}
+
+	n, err := io.Copy(io.Discard, ctxio.NewReader(ctx, src))
+
+	fmt.Printf("   copied %d KB in ~%dms, err=%v\n", n/KB, ms(time.Since(start)), err)
+}
+
+// progress counts bytes written and cancels ctx at a given point, as if
+// the user had pressed a stop button while watching the progress bar
+type progress struct {
+	total, done int64
+	stopAt      int64
+	stop        context.CancelCauseFunc
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	before := p.done
+	p.done += int64(len(b))
+
+	// Report every 8MB
+	if p.done/(8*MB) != before/(8*MB) {
+		fmt.Printf("   progress: %3d%% (%d MB)\n", p.done*100/p.total, p.done/MB)
+	}
+	if p.done >= p.stopAt {
+		p.stop(errUserStop)
+	}
+	return len(b), nil
+}
+
+// example3StreamFile copies a 64MB file and stops after about 40%
+func example3StreamFile() {
+	dir, err := os.MkdirTemp("", "ctxio-*")
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	srcPath := filepath.Join(dir, "large.bin")
+	dstPath := filepath.Join(dir, "copy.bin")
+
+	if err := createFile(srcPath, 64*MB); err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	defer cancel(nil)
+
+	err = copyFile(ctx, dstPath, srcPath, func(total int64) io.Writer {
+		return &progress{total: total, stopAt: total * 40 / 100, stop: cancel}
+	})
+
+	fmt.Println("   copy stopped:", err)
+	if _, statErr := os.Stat(dstPath); errors.Is(statErr, os.ErrNotExist) {
+		fmt.Println("   partial copy was removed")
+	}
+}
+
+// createFile writes size zero bytes to path
+func createFile(path string, size int64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if _, err := io.CopyN(f, zeros{}, size); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// copyFile streams src to dst until done or ctx ends, reporting progress
+// to the writer made by newProgress. A cancelled copy leaves no file.
+func copyFile(ctx context.Context, dstPath, srcPath string, newProgress func(total int64) io.Writer) (err error) {
+	src, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	info, err := src.Stat()
+	if err != nil {
+		return err
+	}
+
+	dst, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if closeErr := dst.Close(); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			os.Remove(dstPath)
+		}
+	}()
+
+	// *os.File has fast paths (ReadFrom, WriteTo) that would copy the
+	// whole file in one go; the ctxio wrapper hides them on purpose
+	w := io.MultiWriter(dst, newProgress(info.Size()))
+	_, err = io.Copy(w, ctxio.NewReader(ctx, src))
+	return err
+}
+
+// example4CtxWriter cancels in the middle of a single Write call
+func example4CtxWriter() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// A sink that takes 10ms per chunk it receives
+	sink := writerFunc(func(p []byte) (int, error) {
+		time.Sleep(10 * time.Millisecond)
+		return len(p), nil
+	})
+
+	data := make([]byte, 1*MB)
+	n, err := ctxio.NewWriter(ctx, sink).Write(data)
+
+	// NewWriter splits the 1MB into 32KB chunks and checks ctx between them
+	fmt.Printf("   wrote %d KB of %d KB, err=%v\n", n/KB, len(data)/KB, err)
+}
+
+// example5BlockedRead combines ctxio with context.AfterFunc
+func example5BlockedRead() {
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	// Nobody ever writes to this pipe, so a Read blocks forever: the
+	// check before Read has already passed and cannot help
+	r, w := io.Pipe()
+	defer r.Close()
+
+	// Closing the other side is what actually unblocks it
+	stop := context.AfterFunc(ctx, func() {
+		w.CloseWithError(context.Cause(ctx))
+	})
+	defer stop()
+
+	start := time.Now()
+	_, err := io.Copy(io.Discard, ctxio.NewReader(ctx, r))
+	fmt.Printf("   read unblocked after ~%dms, err=%v\n", ms(time.Since(start)), err)
+}
+
+// zeros is an endless source of zero bytes
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// writerFunc turns a function into an io.Writer
+type writerFunc func(p []byte) (int, error)
+
+func (f writerFunc) Write(p []byte) (int, error) { return f(p) }
+
+// ms rounds a duration to tens of milliseconds for stable output
+func ms(d time.Duration) int64 {
+	return d.Round(10 * time.Millisecond).Milliseconds()
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// This file re-implements the core of the context package. The types
+// satisfy the real context.Context interface, so they can be passed to
+// any function that takes a context, and mixed with the standard ones.
+
+// Canceled is the error returned by Err after cancel is called.
+// It is the standard sentinel, so errors.Is checks keep working.
+var Canceled = context.Canceled
+
+// emptyCtx is the root: never cancelled, no values, no deadline.
+type emptyCtx struct{}
+
+func (emptyCtx) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (emptyCtx) Done() <-chan struct{}       { return nil } // a nil channel blocks forever
+func (emptyCtx) Err() error                  { return nil }
+func (emptyCtx) Value(key any) any           { return nil }
+
+// Background returns the root context.
+func Background() context.Context { return emptyCtx{} }
+
+// valueCtx holds one key/value pair and embeds its parent, which
+// provides every method it does not override.
+type valueCtx struct {
+	context.Context
+	key, val any
+}
+
+// WithValue returns a copy of parent that also holds key/val.
+func WithValue(parent context.Context, key, val any) context.Context {
+	if key == nil {
+		panic("nil key")
+	}
+	return &valueCtx{Context: parent, key: key, val: val}
+}
+
+// Value returns its own value for key, or asks the parent. A lookup is
+// a walk up a linked list, which is why contexts should hold few values.
+func (c *valueCtx) Value(key any) any {
+	if c.key == key {
+		return c.val
+	}
+	return c.Context.Value(key)
+}
+
+// cancelCtxKey is the key a cancelCtx answers to with itself, so a
+// child can find the nearest cancelCtx above it through Value.
+var cancelCtxKey int
+
+// cancelCtx can be cancelled; cancelling it cancels its children.
+type cancelCtx struct {
+	context.Context // the parent
+
+	mu       sync.Mutex
+	done     chan struct{}
+	err      error
+	children map[*cancelCtx]struct{}
+}
+
+// WithCancel returns a copy of parent with a new Done channel, which is
+// closed when cancel is called or when parent's Done is closed.
+func WithCancel(parent context.Context) (context.Context, context.CancelFunc) {
+	c := &cancelCtx{Context: parent, done: make(chan struct{})}
+	c.propagateCancel()
+	return c, func() { c.cancel(true, Canceled) }
+}
+
+// propagateCancel arranges for c to be cancelled when its parent is.
+func (c *cancelCtx) propagateCancel() {
+	parentDone := c.Context.Done()
+	if parentDone == nil {
+		return // the parent can never be cancelled
+	}
+
+	select {
+	case <-parentDone:
+		c.cancel(false, c.Context.Err()) // already cancelled
+		return
+	default:
+	}
+
+	// Fast path: the parent is one of ours, so register as its child.
+	// The Done check matters: Value may find one of our contexts above
+	// a standard one, and registering there would skip the standard one.
+	if p, ok := c.Context.Value(&cancelCtxKey).(*cancelCtx); ok && p.done == parentDone {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+
+		if p.err != nil {
+			c.cancel(false, p.err) // cancelled since the check above
+			return
+		}
+		if p.children == nil {
+			p.children = make(map[*cancelCtx]struct{})
+		}
+		p.children[c] = struct{}{}
+		return
+	}
+
+	// Slow path: an unknown parent, such as a standard context. All we
+	// can do is watch its Done channel from a goroutine.
+	go func() {
+		select {
+		case <-parentDone:
+			c.cancel(false, c.Context.Err())
+		case <-c.done:
+		}
+	}()
+}
+
+// Value answers cancelCtxKey with c itself, and delegates the rest.
+func (c *cancelCtx) Value(key any) any {
+	if key == &cancelCtxKey {
+		return c
+	}
+	return c.Context.Value(key)
+}
+
+func (c *cancelCtx) Done() <-chan struct{} {
+	return c.done
+}
+
+func (c *cancelCtx) Err() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.err
+}
+
+// cancel closes done, cancels every child, and, if removeFromParent is
+// set, unregisters c from its parent so the parent does not keep a
+// reference to it forever.
+func (c *cancelCtx) cancel(removeFromParent bool, err error) {
+	c.mu.Lock()
+	if c.err != nil {
+		c.mu.Unlock()
+		return // already cancelled: only the first cancel counts
+	}
+	c.err = err
+	close(c.done)
+	children := c.children
+	c.children = nil
+	c.mu.Unlock()
+
+	// Children are cancelled outside c's lock; each one locks itself
+	for child := range children {
+		child.cancel(false, err)
+	}
+
+	if removeFromParent {
+		if p, ok := c.Context.Value(&cancelCtxKey).(*cancelCtx); ok {
+			p.mu.Lock()
+			delete(p.children, c)
+			p.mu.Unlock()
+		}
+	}
+}
+
+// childCount reports how many children are registered with c.
+// It exists to show (and test) the registration.
+func (c *cancelCtx) childCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.children)
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// ctxKey is an unexported key type, so no other package can collide
+type ctxKey string
+
+const (
+	userKey    ctxKey = "user"
+	requestKey ctxKey = "request"
+)
+
+func main() {
+	fmt.Println("Implementing Context")
+	fmt.Println("====================")
+	fmt.Println()
+
+	// Example 1: The root context
+	fmt.Println("1. emptyCtx - the root:")
+	example1Root()
+	fmt.Println()
+
+	// Example 2: Values as a linked list
+	fmt.Println("2. valueCtx - values as a linked list:")
+	example2Values()
+	fmt.Println()
+
+	// Example 3: Cancelling a tree
+	fmt.Println("3. cancelCtx - cancelling a tree:")
+	example3CancelTree()
+	fmt.Println()
+
+	// Example 4: Child registration
+	fmt.Println("4. Child registration and cleanup:")
+	example4Registration()
+	fmt.Println()
+
+	// Example 5: Mixing with the standard library
+	fmt.Println("5. Mixing with the standard context package:")
+	example5Interop()
+}
+
+// example1Root inspects the root context
+func example1Root() {
+	ctx := Background()
+	_, hasDeadline := ctx.Deadline()
+
+	fmt.Printf("   Done(): %v (nil: receiving blocks forever)\n", ctx.Done())
+	fmt.Printf("   Err(): %v, deadline: %v\n", ctx.Err(), hasDeadline)
+}
+
+// example2Values shows lookups walking up the parents
+func example2Values() {
+	ctx := WithValue(Background(), userKey, "gopher")
+	ctx = WithValue(ctx, requestKey, "req-1")
+	ctx = WithValue(ctx, userKey, "shadowed") // the nearest one wins
+
+	fmt.Println("   user:   ", ctx.Value(userKey))
+	fmt.Println("   request:", ctx.Value(requestKey))
+	fmt.Println("   missing:", ctx.Value(ctxKey("missing")))
+
+	// Walk the list by hand to see its shape
+	fmt.Print("   chain:   ")
+	for c := ctx; ; {
+		v, ok := c.(*valueCtx)
+		if !ok {
+			fmt.Printf("%T\n", c)
+			break
+		}
+		fmt.Printf("%v=%v -> ", v.key, v.val)
+		c = v.Context
+	}
+}
+
+// example3CancelTree cancels a parent and watches its descendants
+func example3CancelTree() {
+	root, cancelRoot := WithCancel(Background())
+	child, cancelChild := WithCancel(root)
+	defer cancelChild()
+	grandchild, cancelGrandchild := WithCancel(WithValue(child, userKey, "gopher"))
+	defer cancelGrandchild()
+
+	sibling, cancelSibling := WithCancel(root)
+	cancelSibling() // cancelling a child leaves its parent alone
+
+	fmt.Println("   after cancelling sibling: root.Err() =", root.Err())
+
+	cancelRoot()
+
+	names := []string{"child", "grandchild", "sibling"}
+	for i, ctx := range []context.Context{child, grandchild, sibling} {
+		name := names[i]
+		select {
+		case <-ctx.Done():
+			fmt.Printf("   %-10s done, Err() = %v\n", name, ctx.Err())
+		default:
+			fmt.Printf("   %-10s still running\n", name)
+		}
+	}
+}
+
+// example4Registration shows children joining and leaving a parent
+func example4Registration() {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+	p := parent.(*cancelCtx)
+
+	var cancels []context.CancelFunc
+	for range 3 {
+		_, c := WithCancel(parent)
+		cancels = append(cancels, c)
+	}
+	fmt.Println("   children after 3 WithCancel calls:", p.childCount())
+
+	// Forgetting to call cancel would leave them registered forever:
+	// that is the leak `go vet` warns about (lostcancel)
+	for _, c := range cancels {
+		c()
+	}
+	fmt.Println("   children after calling cancel:    ", p.childCount())
+}
+
+// example5Interop mixes our contexts with the standard ones
+func example5Interop() {
+	// A standard timeout above our context: we must watch it ourselves
+	std, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	ours, cancelOurs := WithCancel(std)
+	defer cancelOurs()
+
+	<-ours.Done()
+	fmt.Println("   ours, under a standard timeout:", ours.Err())
+
+	// Our context above a standard one: the standard package watches us
+	parent, cancelParent := WithCancel(Background())
+	stdChild, cancelStd := context.WithCancel(parent)
+	defer cancelStd()
+
+	cancelParent()
+	<-stdChild.Done()
+	fmt.Println("   standard, under ours:          ", stdChild.Err())
+}
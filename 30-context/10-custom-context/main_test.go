@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// impl is one implementation of the context functions, so every test
+// runs against ours and the standard library's and expects the same
+type impl struct {
+	name       string
+	background func() context.Context
+	withCancel func(context.Context) (context.Context, context.CancelFunc)
+	withValue  func(context.Context, any, any) context.Context
+}
+
+var impls = []impl{
+	{"ours", Background, WithCancel, WithValue},
+	{"std", context.Background, context.WithCancel, context.WithValue},
+}
+
+// isDone reports whether ctx's Done channel is closed, waiting a little
+// for contexts that are cancelled from a goroutine
+func isDone(ctx context.Context) bool {
+	select {
+	case <-ctx.Done():
+		return true
+	case <-time.After(100 * time.Millisecond):
+		return false
+	}
+}
+
+func TestBackground(t *testing.T) {
+	for _, im := range impls {
+		t.Run(im.name, func(t *testing.T) {
+			ctx := im.background()
+			if ctx.Done() != nil || ctx.Err() != nil || ctx.Value("k") != nil {
+				t.Error("want a nil Done, nil Err, and no values")
+			}
+			if _, ok := ctx.Deadline(); ok {
+				t.Error("want no deadline")
+			}
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	for _, im := range impls {
+		t.Run(im.name, func(t *testing.T) {
+			ctx := im.withValue(im.background(), userKey, "a")
+			ctx, cancel := im.withCancel(ctx) // values pass through cancel layers
+			defer cancel()
+			ctx = im.withValue(ctx, requestKey, "r")
+			ctx = im.withValue(ctx, userKey, "b")
+
+			if got := ctx.Value(userKey); got != "b" {
+				t.Errorf("want the nearest value b; got %v", got)
+			}
+			if got := ctx.Value(requestKey); got != "r" {
+				t.Errorf("want r; got %v", got)
+			}
+			if got := ctx.Value(ctxKey("missing")); got != nil {
+				t.Errorf("want nil; got %v", got)
+			}
+		})
+	}
+}
+
+func TestCancelPropagatesDown(t *testing.T) {
+	for _, im := range impls {
+		t.Run(im.name, func(t *testing.T) {
+			root, cancel := im.withCancel(im.background())
+			child, cancelChild := im.withCancel(root)
+			defer cancelChild()
+			grandchild, cancelGrandchild := im.withCancel(im.withValue(child, userKey, "v"))
+			defer cancelGrandchild()
+
+			if child.Err() != nil || grandchild.Err() != nil {
+				t.Fatal("want nil Err before cancel")
+			}
+
+			cancel()
+
+			for _, ctx := range []context.Context{root, child, grandchild} {
+				if !isDone(ctx) {
+					t.Fatal("want every descendant done")
+				}
+				if !errors.Is(ctx.Err(), context.Canceled) {
+					t.Errorf("want Canceled; got %v", ctx.Err())
+				}
+			}
+		})
+	}
+}
+
+func TestCancelDoesNotPropagateUp(t *testing.T) {
+	for _, im := range impls {
+		t.Run(im.name, func(t *testing.T) {
+			root, cancel := im.withCancel(im.background())
+			defer cancel()
+			sibling, cancelSibling := im.withCancel(root)
+			defer cancelSibling()
+			child, cancelChild := im.withCancel(root)
+
+			cancelChild()
+
+			if !isDone(child) {
+				t.Error("want child done")
+			}
+			if root.Err() != nil || sibling.Err() != nil {
+				t.Error("want parent and sibling unaffected")
+			}
+		})
+	}
+}
+
+func TestCancelIsIdempotentAndDoneIsStable(t *testing.T) {
+	for _, im := range impls {
+		t.Run(im.name, func(t *testing.T) {
+			ctx, cancel := im.withCancel(im.background())
+
+			done := ctx.Done()
+			if ctx.Done() != done {
+				t.Error("want the same Done channel every time")
+			}
+
+			// Concurrent cancels must not panic on a double close
+			var wg sync.WaitGroup
+			for range 10 {
+				wg.Go(cancel)
+			}
+			wg.Wait()
+
+			if !isDone(ctx) || !errors.Is(ctx.Err(), context.Canceled) {
+				t.Errorf("want done with Canceled; got %v", ctx.Err())
+			}
+		})
+	}
+}
+
+func TestChildOfCancelledParent(t *testing.T) {
+	for _, im := range impls {
+		t.Run(im.name, func(t *testing.T) {
+			parent, cancel := im.withCancel(im.background())
+			cancel()
+
+			child, cancelChild := im.withCancel(parent)
+			defer cancelChild()
+
+			// Born cancelled: no goroutine or delay needed
+			select {
+			case <-child.Done():
+			default:
+				t.Error("want a child of a cancelled parent to start done")
+			}
+		})
+	}
+}
+
+func TestMixedWithStandardLibrary(t *testing.T) {
+	t.Run("ours under std", func(t *testing.T) {
+		std, cancel := context.WithCancel(context.Background())
+		ours, cancelOurs := WithCancel(std)
+		defer cancelOurs()
+
+		cancel()
+		if !isDone(ours) {
+			t.Error("want ours done when the std parent is cancelled")
+		}
+	})
+
+	t.Run("std under ours", func(t *testing.T) {
+		ours, cancel := WithCancel(Background())
+		std, cancelStd := context.WithCancel(ours)
+		defer cancelStd()
+
+		cancel()
+		if !isDone(std) {
+			t.Error("want std done when our parent is cancelled")
+		}
+	})
+
+	t.Run("ours under std under ours", func(t *testing.T) {
+		top, cancelTop := WithCancel(Background())
+		defer cancelTop()
+		middle, cancelMiddle := context.WithCancel(top)
+		bottom, cancelBottom := WithCancel(middle)
+		defer cancelBottom()
+
+		// bottom must not skip the standard context in the middle
+		cancelMiddle()
+		if !isDone(bottom) {
+			t.Error("want bottom done when the std middle is cancelled")
+		}
+		if top.Err() != nil {
+			t.Error("want top unaffected")
+		}
+	})
+}
+
+func TestChildrenUnregisterOnCancel(t *testing.T) {
+	parent, cancel := WithCancel(Background())
+	defer cancel()
+	p := parent.(*cancelCtx)
+
+	_, cancel1 := WithCancel(parent)
+	_, cancel2 := WithCancel(WithValue(parent, userKey, "v"))
+
+	if n := p.childCount(); n != 2 {
+		t.Fatalf("want 2 registered children; got %d", n)
+	}
+
+	cancel1()
+	cancel2()
+
+	if n := p.childCount(); n != 0 {
+		t.Errorf("want cancelled children unregistered; got %d left", n)
+	}
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/ctxmeta"
+	"github.com/inancgumus/learngo/pkg/sloghelpers"
+)
+
+// requestIDHeader carries the ID between services
+const requestIDHeader = "X-Request-ID"
+
+// requestIDKey holds the ID in a request's context
+var requestIDKey = ctxmeta.NewKey[string]("request-id")
+
+// Middleware wraps a handler with extra behavior
+type Middleware func(http.Handler) http.Handler
+
+// chain applies mws to h; the first one runs first
+func chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// WithRequestID reuses the caller's request ID, or makes a new one, and
+// stores it in the request's context and the response's headers
+func WithRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validID(id) {
+			id = newRequestID()
+		}
+
+		w.Header().Set(requestIDHeader, id)
+		ctx := ctxmeta.WithValue(r.Context(), requestIDKey, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// validID reports whether id is safe to reuse: it ends up in every log
+// line, so a caller must not be able to inject anything else
+func validID(id string) bool {
+	if id == "" || len(id) > 64 {
+		return false
+	}
+	for _, r := range id {
+		if !(r == '-' || r == '_' || '0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 8 random bytes in hex
+func newRequestID() string {
+	b := make([]byte, 8)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// requestIDHandler adds the request ID from the context to every record
+// logged with InfoContext and the other ...Context methods
+type requestIDHandler struct {
+	slog.Handler
+}
+
+func (h requestIDHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := ctxmeta.Value(ctx, requestIDKey); ok {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs and WithGroup wrap the result again, or a logger made with
+// With would drop the request IDs
+func (h requestIDHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return requestIDHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h requestIDHandler) WithGroup(name string) slog.Handler {
+	return requestIDHandler{h.Handler.WithGroup(name)}
+}
+
+// newLogger returns a logger for one service. The lines are indented
+// like the rest of the output, and have no time, so runs compare easily
+func newLogger(service string) *slog.Logger {
+	h := slog.NewTextHandler(indent{os.Stdout}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	})
+	return slog.New(requestIDHandler{h}).With("service", service)
+}
+
+// indent writes every line three spaces in. A slog handler writes a
+// whole line per Write call
+type indent struct {
+	w io.Writer
+}
+
+func (i indent) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(i.w, "   "); err != nil {
+		return 0, err
+	}
+	return i.w.Write(p)
+}
+
+// WithLogging logs every request after it is served. It must run after
+// WithRequestID, so the ID is already in the context.
+func WithLogging(log *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			sw := &statusWriter{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(sw, r)
+
+			log.InfoContext(r.Context(), "served",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", sw.status,
+				"took", time.Since(start).Round(time.Microsecond))
+		})
+	}
+}
+
+// statusWriter remembers the status code a handler wrote
+type statusWriter struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusWriter) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+// propagateID is a RoundTripper that copies the request ID from an
+// outgoing request's context into its headers
+type propagateID struct {
+	next http.RoundTripper
+}
+
+func (t propagateID) RoundTrip(req *http.Request) (*http.Response, error) {
+	id, ok := ctxmeta.Value(req.Context(), requestIDKey)
+	if ok && req.Header.Get(requestIDHeader) == "" {
+		// A RoundTripper must not modify the request it was given
+		req = req.Clone(req.Context())
+		req.Header.Set(requestIDHeader, id)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// newInventory returns the downstream service
+func newInventory() http.Handler {
+	log := newLogger("inventory")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /stock/{item}", func(w http.ResponseWriter, r *http.Request) {
+		log.InfoContext(r.Context(), "checking stock", "item", r.PathValue("item"))
+		fmt.Fprintln(w, 7)
+	})
+	return chain(mux, WithRequestID, WithLogging(log))
+}
+
+// newOrders returns the service that clients call; it calls inventory
+// with a client that propagates the request ID
+func newOrders(inventoryURL string) http.Handler {
+	log := newLogger("orders")
+	client := &http.Client{Transport: propagateID{next: http.DefaultTransport}}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /orders/{item}", func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		log.InfoContext(ctx, "placing an order", "item", r.PathValue("item"))
+
+		// The context carries both cancellation and the request ID
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, inventoryURL+"/stock/"+r.PathValue("item"), nil)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			log.ErrorContext(ctx, "calling inventory", sloghelpers.Err(err))
+			http.Error(w, "inventory unavailable", http.StatusBadGateway)
+			return
+		}
+		defer resp.Body.Close()
+
+		stock, _ := io.ReadAll(resp.Body)
+		log.InfoContext(ctx, "in stock", "count", strings.TrimSpace(string(stock)))
+		w.WriteHeader(http.StatusCreated)
+	})
+	return chain(mux, WithRequestID, WithLogging(log))
+}
+
+func main() {
+	fmt.Println("Request-ID Middleware")
+	fmt.Println("=====================")
+	fmt.Println()
+
+	inventoryURL, stopInventory, err := serve(newInventory())
+	if err != nil {
+		fmt.Println("serve:", err)
+		return
+	}
+	defer stopInventory()
+
+	ordersURL, stopOrders, err := serve(newOrders(inventoryURL))
+	if err != nil {
+		fmt.Println("serve:", err)
+		return
+	}
+	defer stopOrders()
+
+	// Example 1: The first service makes up an ID
+	fmt.Println("1. A new request ID, followed across two services:")
+	post(ordersURL+"/orders/gopher-plush", "")
+	fmt.Println()
+
+	// Example 2: The caller already has an ID
+	fmt.Println("2. An ID sent by the caller is kept:")
+	post(ordersURL+"/orders/gopher-mug", "checkout-42")
+	fmt.Println()
+
+	// Example 3: An ID that could forge log lines is replaced
+	fmt.Println("3. An unsafe ID sent by the caller is replaced:")
+	post(ordersURL+"/orders/gopher-hat", "x] admin logged in [x")
+}
+
+// post sends a request, with a request ID if id is not empty
+func post(url, id string) {
+	req, err := http.NewRequest(http.MethodPost, url, nil)
+	if err != nil {
+		fmt.Println("   client:", err)
+		return
+	}
+	if id != "" {
+		req.Header.Set(requestIDHeader, id)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("   client:", err)
+		return
+	}
+	resp.Body.Close()
+
+	// Clients can quote the ID when they report a problem
+	fmt.Printf("   client: %s request_id=%s\n", resp.Status, resp.Header.Get(requestIDHeader))
+}
+
+// serve starts h on a random free port, and returns its base URL and a
+// function to stop it
+func serve(h http.Handler) (string, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+
+	hs := &http.Server{Handler: h}
+	go hs.Serve(ln)
+
+	stop := func() { hs.Shutdown(context.Background()) }
+	return "http://" + ln.Addr().String(), stop, nil
+}
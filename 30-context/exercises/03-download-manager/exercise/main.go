@@ -0,0 +1,95 @@
+// ---------------------------------------------------------
+// EXERCISE: Concurrent Download Manager
+//
+//  Build a downloader that fetches many URLs at once and
+//  uses contexts to enforce every limit it has.
+//
+//  1- Write a function:
+//
+//     func Download(ctx context.Context, client *http.Client,
+//         urls []string, opts Options,
+//         progress chan<- Progress) ([]Result, error)
+//
+//     Options has:
+//     - Concurrency: how many downloads run at once
+//     - Deadline:    a limit for the whole batch
+//     - PerRequest:  a limit for each single download
+//     - MaxFailures: how many failed downloads are tolerated
+//
+//     Return one Result (URL, Bytes, Err) per URL, in the
+//     same order as urls.
+//
+//  2- Limits:
+//     - Run at most Concurrency downloads at a time
+//       (use a buffered channel as a semaphore)
+//     - Wrap ctx with context.WithTimeout for the Deadline
+//     - Give each request its own context.WithTimeoutCause,
+//       with an ErrRequestTimeout cause
+//
+//  3- Error budget:
+//     - Count the failed downloads
+//     - When there are more than MaxFailures, cancel all the
+//       remaining downloads, running or not yet started
+//     - Use context.WithCancelCause with an ErrBudgetExceeded
+//       cause, so every result says why it was cancelled
+//
+//  4- Progress:
+//     - Send a Progress (URL, Bytes so far, Total) to the
+//       channel while the body is being read
+//     - Send a final Progress with Done: true and Err when a
+//       download ends, or is skipped
+//     - Close the channel when Download returns
+//
+//  5- Return an error that says what went wrong:
+//     - nil, if every download succeeded
+//     - the context's cause, if the batch was cancelled
+//     - otherwise, how many downloads failed
+//
+//  6- In main, start an httptest.Server with a few fast
+//     files, one slow endpoint, and one that fails, and try
+//     different Options on it.
+//
+//  HINTS
+//     - net/http returns context.DeadlineExceeded or
+//       context.Canceled; use context.Cause(ctx) to find out
+//       which of your limits was hit
+//     - A failure that happens because the batch was already
+//       cancelled should not count against the budget
+//
+//  The solution has tests in main_test.go. Copy them next to
+//  your main.go and run `go test` to check your work.
+//
+//
+// EXPECTED OUTPUT (order will vary):
+//
+//  1. Per-request timeout, failures tolerated:
+//     done /file/a?size=300            300 bytes  err=<nil>
+//     done /file/b?size=500            500 bytes  err=<nil>
+//     done /missing                      0 bytes  err=404 Not Found
+//     done /file/c?size=200            200 bytes  err=<nil>
+//     done /slow                        50 bytes  err=request timed out
+//     3/5 downloaded in ~300ms, err = 2 of 5 downloads failed
+//
+//  2. No failures tolerated:
+//     done /file/a?size=300            300 bytes  err=<nil>
+//     done /file/b?size=500            500 bytes  err=<nil>
+//     done /missing                      0 bytes  err=404 Not Found
+//     done /file/c?size=200              0 bytes  err=error budget exceeded: 1 failed, 0 allowed
+//     done /slow                         0 bytes  err=error budget exceeded: 1 failed, 0 allowed
+//     2/5 downloaded in ~0ms, err = error budget exceeded: 1 failed, 0 allowed
+//
+//  3. A global deadline shorter than the slow download:
+//     done /file/a?size=300            300 bytes  err=<nil>
+//     done /file/b?size=500            500 bytes  err=<nil>
+//     done /file/c?size=200            200 bytes  err=<nil>
+//     done /missing                      0 bytes  err=404 Not Found
+//     done /slow                        30 bytes  err=context deadline exceeded
+//     3/5 downloaded in ~200ms, err = context deadline exceeded
+//
+// ---------------------------------------------------------
+
+package main
+
+func main() {
+	// TODO: Implement the download manager
+}
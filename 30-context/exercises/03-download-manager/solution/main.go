@@ -0,0 +1,268 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrRequestTimeout is the cause when one download takes too long
+	ErrRequestTimeout = errors.New("request timed out")
+
+	// ErrBudgetExceeded is the cause when too many downloads failed
+	ErrBudgetExceeded = errors.New("error budget exceeded")
+)
+
+// Options configures a Download
+type Options struct {
+	Concurrency int           // downloads at once; <= 0 means all at once
+	Deadline    time.Duration // for the whole batch; 0 means none
+	PerRequest  time.Duration // for each download; 0 means none
+	MaxFailures int           // failures tolerated; one more cancels the rest
+}
+
+// Progress is sent while a download runs, and once more when it ends
+type Progress struct {
+	URL   string
+	Bytes int64 // received so far
+	Total int64 // from Content-Length, or -1 if unknown
+	Done  bool
+	Err   error // set when Done and the download failed
+}
+
+// Result is the outcome of one download
+type Result struct {
+	URL   string
+	Bytes int64
+	Err   error
+}
+
+// Download fetches every URL, at most opts.Concurrency at a time, and
+// returns the results in the order of urls.
+//
+// If progress is not nil, Download sends updates to it and closes it
+// before returning; the caller must keep receiving until then.
+func Download(ctx context.Context, client *http.Client, urls []string, opts Options, progress chan<- Progress) ([]Result, error) {
+	if progress != nil {
+		defer close(progress)
+	}
+
+	if opts.Deadline > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Deadline)
+		defer cancel()
+	}
+
+	// Cancelled with a cause once the error budget is spent
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	limit := opts.Concurrency
+	if limit <= 0 {
+		limit = len(urls)
+	}
+	sem := make(chan struct{}, max(limit, 1))
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		failures int
+	)
+	results := make([]Result, len(urls))
+
+	for i, url := range urls {
+		results[i].URL = url
+
+		// Wait for a free slot, unless the batch is already over
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			results[i].Err = context.Cause(ctx)
+			if progress != nil {
+				progress <- Progress{URL: url, Done: true, Err: results[i].Err}
+			}
+			continue
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+
+			n, err := fetch(ctx, client, url, opts.PerRequest, progress)
+			results[i].Bytes, results[i].Err = n, err
+
+			if progress != nil {
+				progress <- Progress{URL: url, Bytes: n, Done: true, Err: err}
+			}
+
+			// Failures caused by the batch ending do not count
+			if err == nil || ctx.Err() != nil {
+				return
+			}
+			mu.Lock()
+			failures++
+			if failures > opts.MaxFailures {
+				cancel(fmt.Errorf("%w: %d failed, %d allowed", ErrBudgetExceeded, failures, opts.MaxFailures))
+			}
+			mu.Unlock()
+		})
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	switch {
+	case failed == 0:
+		return results, nil
+	case context.Cause(ctx) != nil:
+		return results, context.Cause(ctx)
+	default:
+		return results, fmt.Errorf("%d of %d downloads failed", failed, len(urls))
+	}
+}
+
+// fetch downloads url, discarding the body, and returns its size
+func fetch(ctx context.Context, client *http.Client, url string, timeout time.Duration, progress chan<- Progress) (int64, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, ErrRequestTimeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, reason(ctx, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("%s", resp.Status)
+	}
+
+	// The body is read with the request's context: a timeout or a
+	// cancel stops it mid-transfer
+	pr := &progressReader{r: resp.Body, url: url, total: resp.ContentLength, progress: progress}
+	n, err := io.Copy(io.Discard, pr)
+	if err != nil {
+		return n, reason(ctx, err)
+	}
+	return n, nil
+}
+
+// reason replaces a context error from net/http with the context's
+// cause, which says which limit was hit
+func reason(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return context.Cause(ctx)
+	}
+	return err
+}
+
+// progressReader reports every read to a progress channel
+type progressReader struct {
+	r        io.Reader
+	url      string
+	read     int64
+	total    int64
+	progress chan<- Progress
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.read += int64(n)
+	if n > 0 && p.progress != nil {
+		p.progress <- Progress{URL: p.url, Bytes: p.read, Total: p.total}
+	}
+	return n, err
+}
+
+func main() {
+	srv := newDemoServer()
+	defer srv.Close()
+
+	urls := []string{
+		srv.URL + "/file/a?size=300",
+		srv.URL + "/file/b?size=500",
+		srv.URL + "/slow",
+		srv.URL + "/missing",
+		srv.URL + "/file/c?size=200",
+	}
+
+	run := func(opts Options) {
+		progress := make(chan Progress)
+		done := make(chan struct{})
+
+		// Print only the final update of each download
+		go func() {
+			defer close(done)
+			for p := range progress {
+				if p.Done {
+					fmt.Printf("   done %-26s %4d bytes  err=%v\n", strings.TrimPrefix(p.URL, srv.URL), p.Bytes, p.Err)
+				}
+			}
+		}()
+
+		start := time.Now()
+		results, err := Download(context.Background(), srv.Client(), urls, opts, progress)
+		<-done
+
+		ok := 0
+		for _, r := range results {
+			if r.Err == nil {
+				ok++
+			}
+		}
+		fmt.Printf("   %d/%d downloaded in ~%dms, err = %v\n", ok, len(results), time.Since(start).Round(50*time.Millisecond).Milliseconds(), err)
+	}
+
+	fmt.Println("1. Per-request timeout, failures tolerated:")
+	run(Options{Concurrency: 2, Deadline: 2 * time.Second, PerRequest: 300 * time.Millisecond, MaxFailures: 5})
+	fmt.Println()
+
+	fmt.Println("2. No failures tolerated:")
+	run(Options{Concurrency: 2, Deadline: 2 * time.Second, PerRequest: 300 * time.Millisecond, MaxFailures: 0})
+	fmt.Println()
+
+	fmt.Println("3. A global deadline shorter than the slow download:")
+	run(Options{Concurrency: 5, Deadline: 200 * time.Millisecond, MaxFailures: 5})
+}
+
+// newDemoServer serves files of a given size, one slow endpoint, and
+// 404 for the rest
+func newDemoServer() *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /file/{name}", func(w http.ResponseWriter, r *http.Request) {
+		var size int
+		fmt.Sscan(r.URL.Query().Get("size"), &size)
+		w.Header().Set("Content-Length", fmt.Sprint(size))
+		io.Copy(w, strings.NewReader(strings.Repeat("x", size)))
+	})
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		// Streams 10 bytes a tick, until done or the client gives up
+		for range 100 {
+			select {
+			case <-time.After(50 * time.Millisecond):
+				w.Write([]byte("0123456789"))
+				w.(http.Flusher).Flush()
+			case <-r.Context().Done():
+				return
+			}
+		}
+	})
+	return httptest.NewServer(mux)
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// fakeTransport serves requests in memory, so the tests run inside a
+// synctest bubble with a fake clock:
+//
+//	/ok      100 bytes, one byte every 10ms
+//	/slow    an endless body, one byte per second
+//	/fail    500 Internal Server Error
+type fakeTransport struct {
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	f.mu.Lock()
+	f.inFlight++
+	f.maxInFlight = max(f.maxInFlight, f.inFlight)
+	f.mu.Unlock()
+
+	done := func() {
+		f.mu.Lock()
+		f.inFlight--
+		f.mu.Unlock()
+	}
+
+	resp := &http.Response{StatusCode: http.StatusOK, Status: "200 OK", ContentLength: -1, Request: req}
+	switch req.URL.Path {
+	case "/ok":
+		resp.ContentLength = 100
+		resp.Body = &tickBody{ctx: req.Context(), left: 100, tick: 10 * time.Millisecond, done: done}
+	case "/slow":
+		resp.Body = &tickBody{ctx: req.Context(), left: -1, tick: time.Second, done: done}
+	default:
+		done()
+		resp.StatusCode, resp.Status = http.StatusInternalServerError, "500 Internal Server Error"
+		resp.Body = io.NopCloser(strings.NewReader(""))
+	}
+	return resp, nil
+}
+
+// tickBody returns one byte per tick, until left runs out or ctx ends
+type tickBody struct {
+	ctx  context.Context
+	left int // -1 means endless
+	tick time.Duration
+	done func()
+	once sync.Once
+}
+
+func (b *tickBody) Read(p []byte) (int, error) {
+	if b.left == 0 {
+		return 0, io.EOF
+	}
+	select {
+	case <-time.After(b.tick):
+	case <-b.ctx.Done():
+		return 0, b.ctx.Err()
+	}
+	if b.left > 0 {
+		b.left--
+	}
+	p[0] = 'x'
+	return 1, nil
+}
+
+func (b *tickBody) Close() error {
+	b.once.Do(b.done)
+	return nil
+}
+
+// download runs Download with a fake client and collects the progress
+func download(t *testing.T, ctx context.Context, urls []string, opts Options) ([]Result, error, []Progress, *fakeTransport) {
+	t.Helper()
+
+	ft := &fakeTransport{}
+	client := &http.Client{Transport: ft}
+
+	progress := make(chan Progress)
+	var updates []Progress
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for p := range progress {
+			updates = append(updates, p)
+		}
+	}()
+
+	results, err := Download(ctx, client, urls, opts, progress)
+	<-collected
+	return results, err, updates, ft
+}
+
+func TestAllSucceed(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		urls := []string{"http://x/ok", "http://y/ok", "http://z/ok"}
+
+		results, err, updates, _ := download(t, context.Background(), urls, Options{})
+		if err != nil {
+			t.Fatalf("want nil; got %v", err)
+		}
+
+		for i, r := range results {
+			if r.URL != urls[i] || r.Bytes != 100 || r.Err != nil {
+				t.Errorf("result %d: want %s, 100 bytes, nil; got %+v", i, urls[i], r)
+			}
+		}
+
+		// 100 updates while reading, plus a final one, for each URL
+		if len(updates) != 3*101 {
+			t.Errorf("want %d progress updates; got %d", 3*101, len(updates))
+		}
+		final := 0
+		for _, p := range updates {
+			if p.Done {
+				final++
+			} else if p.Total != 100 {
+				t.Errorf("want Total 100; got %d", p.Total)
+			}
+		}
+		if final != 3 {
+			t.Errorf("want 3 final updates; got %d", final)
+		}
+	})
+}
+
+func TestConcurrencyLimit(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		urls := make([]string, 10)
+		for i := range urls {
+			urls[i] = "http://x/ok"
+		}
+
+		start := time.Now()
+		_, err, _, ft := download(t, context.Background(), urls, Options{Concurrency: 3})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ft.maxInFlight != 3 {
+			t.Errorf("want at most 3 downloads at once; got %d", ft.maxInFlight)
+		}
+		// 10 downloads of 1s each, 3 at a time: 4 rounds
+		if elapsed := time.Since(start); elapsed != 4*time.Second {
+			t.Errorf("want 4s; got %v", elapsed)
+		}
+	})
+}
+
+func TestPerRequestTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		urls := []string{"http://x/ok", "http://x/slow", "http://x/ok"}
+
+		opts := Options{PerRequest: 2500 * time.Millisecond, MaxFailures: 1}
+		results, err, _, _ := download(t, context.Background(), urls, opts)
+
+		if !errors.Is(results[1].Err, ErrRequestTimeout) {
+			t.Errorf("want ErrRequestTimeout for /slow; got %v", results[1].Err)
+		}
+		if results[1].Bytes != 2 {
+			t.Errorf("want the 2 bytes read before the timeout; got %d", results[1].Bytes)
+		}
+		if results[0].Err != nil || results[2].Err != nil {
+			t.Errorf("want the others unaffected; got %v, %v", results[0].Err, results[2].Err)
+		}
+
+		// Within budget: an overall error, but not a cancellation
+		if err == nil || errors.Is(err, ErrBudgetExceeded) {
+			t.Errorf("want a plain failure count; got %v", err)
+		}
+	})
+}
+
+func TestGlobalDeadline(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		urls := []string{"http://x/slow", "http://x/slow", "http://x/ok", "http://x/ok"}
+
+		start := time.Now()
+		results, err, _, _ := download(t, context.Background(), urls, Options{
+			Concurrency: 2,
+			Deadline:    5 * time.Second,
+			MaxFailures: 10,
+		})
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want DeadlineExceeded; got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed != 5*time.Second {
+			t.Errorf("want everything stopped at the deadline; got %v", elapsed)
+		}
+
+		// The slow ones held both slots, so the rest never started
+		for i, r := range results {
+			if !errors.Is(r.Err, context.DeadlineExceeded) {
+				t.Errorf("result %d: want DeadlineExceeded; got %v", i, r.Err)
+			}
+		}
+	})
+}
+
+func TestErrorBudget(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		urls := []string{"http://x/fail", "http://x/fail", "http://x/ok", "http://x/slow"}
+
+		results, err, updates, _ := download(t, context.Background(), urls, Options{
+			Concurrency: 1,
+			MaxFailures: 1,
+		})
+
+		if !errors.Is(err, ErrBudgetExceeded) {
+			t.Fatalf("want ErrBudgetExceeded; got %v", err)
+		}
+
+		// One at a time: the second failure cancels what is left
+		for i, r := range results[2:] {
+			if !errors.Is(r.Err, ErrBudgetExceeded) || r.Bytes != 0 {
+				t.Errorf("result %d: want skipped with ErrBudgetExceeded; got %+v", i+2, r)
+			}
+		}
+
+		// Skipped downloads still get a final update
+		final := 0
+		for _, p := range updates {
+			if p.Done {
+				final++
+			}
+		}
+		if final != len(urls) {
+			t.Errorf("want %d final updates; got %d", len(urls), final)
+		}
+	})
+}
+
+func TestBudgetCancelsRunningDownloads(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		urls := []string{"http://x/slow", "http://x/slow", "http://x/fail"}
+
+		start := time.Now()
+		results, err, _, _ := download(t, context.Background(), urls, Options{})
+
+		if !errors.Is(err, ErrBudgetExceeded) {
+			t.Fatalf("want ErrBudgetExceeded; got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed != 0 {
+			t.Errorf("want the slow downloads stopped at once; got %v", elapsed)
+		}
+		for _, r := range results[:2] {
+			if !errors.Is(r.Err, ErrBudgetExceeded) {
+				t.Errorf("want ErrBudgetExceeded; got %v", r.Err)
+			}
+		}
+	})
+}
+
+func TestParentCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(3*time.Second, cancel)
+
+		results, err, _, _ := download(t, ctx, []string{"http://x/slow", "http://x/ok"}, Options{})
+
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want Canceled; got %v", err)
+		}
+		if results[1].Err != nil {
+			t.Errorf("want /ok finished before the cancel; got %v", results[1].Err)
+		}
+	})
+}
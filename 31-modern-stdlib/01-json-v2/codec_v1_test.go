@@ -0,0 +1,14 @@
+//go:build !goexperiment.jsonv2 || go1.27
+
+package main
+
+import "encoding/json"
+
+// The benchmarks use v1, unless GOEXPERIMENT=jsonv2 builds
+// codec_v2_test.go instead. Both name their benchmarks the same, so
+// benchstat can compare a run of each
+var (
+	codec     = "v1"
+	marshal   = json.Marshal
+	unmarshal = json.Unmarshal
+)
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// makeUsers returns n users like the one in demonstrateJSONv1
+func makeUsers(n int) []User {
+	users := make([]User, n)
+	for i := range users {
+		users[i] = User{
+			ID:     i + 1,
+			Name:   fmt.Sprintf("User %d", i+1),
+			Email:  fmt.Sprintf("user%d@example.com", i+1),
+			Age:    20 + i%50,
+			Tags:   []string{"developer", "golang"},
+			Active: i%2 == 0,
+		}
+	}
+	return users
+}
+
+func TestRoundTrip(t *testing.T) {
+	users := makeUsers(3)
+	data, err := marshal(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []User
+	if err := unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(got) != fmt.Sprint(users) {
+		t.Errorf("%s: got %v; want %v", codec, got, users)
+	}
+}
+
+// The claims about v2's speed are easy to check on your own data. Run
+// each version six times, and compare them with benchstat:
+//
+//	export GOTOOLCHAIN=go1.26.0 # v2 needs the experiment of Go 1.25 or 1.26
+//	go test -run XXX -bench . -count 6 > v1.txt
+//	GOEXPERIMENT=jsonv2 go test -run XXX -bench . -count 6 > v2.txt
+//	benchstat v1.txt v2.txt
+
+func BenchmarkMarshal(b *testing.B) {
+	for _, n := range []int{1, 1000} {
+		b.Run(fmt.Sprintf("users=%d", n), func(b *testing.B) {
+			users := makeUsers(n)
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := marshal(users); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	for _, n := range []int{1, 1000} {
+		b.Run(fmt.Sprintf("users=%d", n), func(b *testing.B) {
+			data, err := marshal(makeUsers(n))
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for b.Loop() {
+				var users []User
+				if err := unmarshal(data, &users); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
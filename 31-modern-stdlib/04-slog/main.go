@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// noTime drops the time from every record, so the output below is the
+// same on every run. Real programs keep it
+func noTime(groups []string, a slog.Attr) slog.Attr {
+	if len(groups) == 0 && a.Key == slog.TimeKey {
+		return slog.Attr{}
+	}
+	return a
+}
+
+// newText returns a logger that writes key=value lines to stdout
+func newText(level slog.Leveler) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: level, ReplaceAttr: noTime}))
+}
+
+// newJSON returns a logger that writes one JSON object per line
+func newJSON() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: noTime}))
+}
+
+// User logs itself without its password, through slog.LogValuer
+type User struct {
+	ID       int
+	Name     string
+	Password string
+}
+
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("id", u.ID),
+		slog.String("name", u.Name),
+	)
+}
+
+// requestIDKey is the context key that contextHandler looks for
+type requestIDKey struct{}
+
+// contextHandler adds the request ID from the context to every record.
+// It wraps another handler, which does the formatting
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if id, ok := ctx.Value(requestIDKey{}).(string); ok {
+		r.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, r)
+}
+
+// WithAttrs and WithGroup must wrap the result again. Without them, the
+// embedded handler's methods return a plain handler, and a logger made
+// with Logger.With would stop adding request IDs
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+func main() {
+	fmt.Println("Structured Logging with log/slog")
+	fmt.Println("================================")
+	fmt.Println()
+
+	// Example 1: A message plus key-value pairs
+	fmt.Println("1. Logger and Handler:")
+	logger := newText(nil)
+	logger.Info("user signed in", "user", "ada", "attempt", 2)
+	logger.Warn("disk almost full", slog.Int("percent", 91), slog.String("mount", "/var"))
+	fmt.Println()
+
+	// Example 2: The same call, two handlers
+	fmt.Println("2. Text vs JSON:")
+	for _, l := range []*slog.Logger{newText(nil), newJSON()} {
+		l.Info("order placed", "order_id", 1042, "total", 59.90, "gift", true)
+	}
+	fmt.Println()
+
+	// Example 3: Attributes shared by many lines
+	fmt.Println("3. With and Group:")
+	orders := newText(nil).With("service", "orders")
+	orders.Info("started", "port", 8080)
+	orders.Info("request",
+		slog.Group("http", "method", "POST", "path", "/orders", "status", 201))
+
+	db := newJSON().WithGroup("db")
+	db.Info("query", "table", "orders", "rows", 3)
+	fmt.Println()
+
+	// Example 4: Levels, changed while the program runs
+	fmt.Println("4. Levels:")
+	var level slog.LevelVar // Info by default
+	leveled := newText(&level)
+	leveled.Debug("cache miss", "key", "user:42") // dropped
+	leveled.Info("level is", "level", level.Level())
+
+	level.Set(slog.LevelDebug)
+	leveled.Debug("cache miss", "key", "user:42") // now shown
+	if leveled.Enabled(context.Background(), slog.LevelDebug) {
+		leveled.Debug("expensive details", "dump", fmt.Sprint(map[string]int{"hits": 3}))
+	}
+	fmt.Println()
+
+	// Example 5: Types decide how they are logged
+	fmt.Println("5. LogValuer:")
+	u := User{ID: 42, Name: "ada", Password: "hunter2"}
+	newText(nil).Info("login", "user", u)
+	newJSON().Info("login", "user", u)
+	fmt.Println()
+
+	// Example 6: A custom handler reads the context
+	fmt.Println("6. A handler that reads the context:")
+	ctxLogger := slog.New(contextHandler{slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{ReplaceAttr: noTime})})
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "req-7f3a")
+
+	ctxLogger.InfoContext(ctx, "checking stock", "item", "gopher-plush")
+	ctxLogger.With("service", "inventory").InfoContext(ctx, "in stock", "count", 7)
+	ctxLogger.Info("no context, no ID")
+	fmt.Println()
+
+	// Example 7: Existing log.Printf calls go through slog too
+	fmt.Println("7. Replacing log.Printf:")
+	slog.SetDefault(newText(nil))
+	log.Printf("legacy message from %s", "the log package")
+	slog.Info("new code calls slog directly", "took", 120*time.Millisecond)
+}
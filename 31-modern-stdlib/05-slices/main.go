@@ -0,0 +1,121 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Employee is sorted by several keys below
+type Employee struct {
+	Name string
+	Dept string
+	Age  int
+}
+
+func main() {
+	fmt.Println("The slices Package")
+	fmt.Println("==================")
+	fmt.Println()
+
+	// Example 1: Searching
+	fmt.Println("1. Contains, Index, and BinarySearch:")
+	langs := []string{"go", "rust", "zig", "c"}
+	fmt.Printf("Contains(langs, \"zig\"): %v\n", slices.Contains(langs, "zig"))
+	fmt.Printf("Index(langs, \"c\"): %d, Index(langs, \"java\"): %d\n", slices.Index(langs, "c"), slices.Index(langs, "java"))
+	long := slices.IndexFunc(langs, func(s string) bool { return len(s) > 3 })
+	fmt.Printf("IndexFunc(len > 3): %d (%s)\n", long, langs[long])
+
+	sorted := []int{10, 20, 30, 40, 50}
+	i, found := slices.BinarySearch(sorted, 30)
+	fmt.Printf("BinarySearch(30): index %d, found %v\n", i, found)
+	i, found = slices.BinarySearch(sorted, 35)
+	fmt.Printf("BinarySearch(35): index %d, found %v (where 35 would go)\n", i, found)
+	fmt.Println()
+
+	// Example 2: Sorting
+	fmt.Println("2. Sort and SortFunc:")
+	nums := []int{5, 2, 8, 1, 9, 3}
+	slices.Sort(nums)
+	fmt.Printf("Sort: %v\n", nums)
+
+	staff := []Employee{
+		{"Ann", "eng", 41}, {"Bob", "ops", 29}, {"Cy", "eng", 29}, {"Di", "ops", 35},
+	}
+	// By department, then by age: cmp.Or returns the first non-zero result
+	slices.SortFunc(staff, func(a, b Employee) int {
+		return cmp.Or(
+			cmp.Compare(a.Dept, b.Dept),
+			cmp.Compare(a.Age, b.Age),
+		)
+	})
+	for _, e := range staff {
+		fmt.Printf("  %-4s %-3s %d\n", e.Name, e.Dept, e.Age)
+	}
+	fmt.Printf("Min: %d, Max: %d\n", slices.Min(nums), slices.Max(nums))
+	fmt.Println()
+
+	// Example 3: Comparing, copying, and joining
+	fmt.Println("3. Equal, Clone, Concat, and Compact:")
+	a := []int{1, 2, 3}
+	b := slices.Clone(a) // a new array: changing b doesn't change a
+	b[0] = 99
+	fmt.Printf("a: %v, b: %v, Equal: %v\n", a, b, slices.Equal(a, b))
+	fmt.Printf("Concat: %v\n", slices.Concat(a, b, []int{7}))
+
+	tags := []string{"go", "db", "go", "api", "db", "go"}
+	slices.Sort(tags)
+	tags = slices.Compact(tags) // removes consecutive duplicates: sort first
+	fmt.Printf("Sort then Compact: %v\n", tags)
+	fmt.Println()
+
+	// Example 4: Insert and Delete return the new slice
+	fmt.Println("4. Insert and Delete:")
+	s := []string{"a", "b", "c", "d"}
+	s = slices.Insert(s, 1, "x", "y")
+	fmt.Printf("Insert at 1: %v\n", s)
+	s = slices.Delete(s, 1, 3)
+	fmt.Printf("Delete [1:3]: %v\n", s)
+	fmt.Println()
+
+	// Example 5: Delete clears the tail, and other slices can see it
+	fmt.Println("5. Gotcha: Delete and another slice of the same array:")
+	orig := []int{1, 2, 3, 4, 5}
+	view := orig // the same array
+	trimmed := slices.Delete(orig, 1, 3)
+	fmt.Printf("trimmed: %v\n", trimmed)
+	fmt.Printf("view:    %v <- the old length, and zeros at the end\n", view)
+	fmt.Println()
+
+	// Example 6: Insert can overwrite another slice's elements
+	fmt.Println("6. Gotcha: Insert into a slice with spare capacity:")
+	backing := []string{"a", "b", "c", "d", "e"}
+	first := backing[:2] // len 2, cap 5: "c", "d", "e" are spare capacity
+	rest := backing[2:]
+	first = slices.Insert(first, 1, "X")
+	fmt.Printf("first: %v\n", first)
+	fmt.Printf("rest:  %v <- \"c\" was overwritten\n", rest)
+
+	safe := backing[:2:2] // a full slice expression caps it at len 2
+	safe = slices.Insert(safe, 1, "Y")
+	fmt.Printf("with a capped slice, rest stays: %v (safe: %v)\n", rest, safe)
+	fmt.Println()
+
+	// Example 7: Hand-rolled helpers vs the package
+	fmt.Println("7. Compared with the generics lessons:")
+	fmt.Println(`MinSlice([]int{}) returned 0: is that the minimum or "empty"?`)
+	fmt.Printf("slices.Min([]int{}) panics instead: %s\n", minOrPanic([]int{}))
+	fmt.Printf("strings.Join(slices.Sorted(...)): %s\n",
+		strings.Join(slices.Sorted(slices.Values([]string{"c", "a", "b"})), ","))
+}
+
+// minOrPanic reports the panic from slices.Min on an empty slice
+func minOrPanic(s []int) (msg string) {
+	defer func() {
+		if r := recover(); r != nil {
+			msg = fmt.Sprint(r)
+		}
+	}()
+	return fmt.Sprint(slices.Min(s))
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+func main() {
+	fmt.Println("The maps Package and Iteration Order")
+	fmt.Println("====================================")
+	fmt.Println()
+
+	// Example 1: Range order is random
+	fmt.Println("1. Range order changes from loop to loop:")
+	stock := map[string]int{"apple": 5, "banana": 0, "cherry": 12, "date": 3, "elder": 0}
+	orders := make(map[string]bool)
+	for range 20 {
+		var keys []string
+		for k := range stock {
+			keys = append(keys, k)
+		}
+		orders[strings.Join(keys, " ")] = true
+	}
+	fmt.Printf("20 loops over the same map gave %d different orders (run it again: the number changes)\n", len(orders))
+	fmt.Println()
+
+	// Example 2: Sorted keys give the same output every time
+	fmt.Println("2. Sorted keys:")
+	for _, k := range slices.Sorted(maps.Keys(stock)) {
+		fmt.Printf("%-7s %d\n", k, stock[k])
+	}
+	fmt.Printf("fmt sorts the keys too: %v\n", stock)
+	fmt.Println()
+
+	// Example 3: Keys and Values are iterators, not slices
+	fmt.Println("3. Keys and Values are iterators:")
+	total := 0
+	for n := range maps.Values(stock) { // no slice is built
+		total += n
+	}
+	fmt.Printf("Total items: %d\n", total)
+
+	counts := slices.Sorted(maps.Values(stock))
+	fmt.Printf("Sorted values: %v\n", counts)
+
+	// Sort by value, then by key, so ties come out the same way every run
+	names := slices.SortedFunc(maps.Keys(stock), func(a, b string) int {
+		return cmp.Or(cmp.Compare(stock[b], stock[a]), cmp.Compare(a, b))
+	})
+	fmt.Printf("Most stock first: %v\n", names)
+	fmt.Println()
+
+	// Example 4: Clone and Equal
+	fmt.Println("4. Clone and Equal:")
+	backup := maps.Clone(stock)
+	fmt.Printf("Equal after Clone: %v\n", maps.Equal(stock, backup))
+	backup["apple"] = 99
+	fmt.Printf("Equal after a change: %v (stock[\"apple\"] is still %d)\n",
+		maps.Equal(stock, backup), stock["apple"])
+
+	sameFruit := maps.EqualFunc(stock, backup, func(a, b int) bool {
+		return (a > 0) == (b > 0) // compare "in stock", not the counts
+	})
+	fmt.Printf("Same fruit in stock: %v\n", sameFruit)
+	fmt.Println()
+
+	// Example 5: DeleteFunc, Copy, and Collect
+	fmt.Println("5. DeleteFunc, Copy, and Collect:")
+	maps.DeleteFunc(backup, func(_ string, n int) bool { return n == 0 })
+	fmt.Printf("Without empty items: %v\n", backup)
+
+	maps.Copy(backup, map[string]int{"fig": 7, "apple": 1}) // overwrites apple
+	fmt.Printf("After Copy: %v\n", backup)
+
+	// slices.All yields (index, value) pairs, so this maps rank -> name
+	rank := maps.Collect(slices.All(names))
+	fmt.Printf("Collect from an iterator: %v\n", rank)
+	fmt.Println()
+
+	// Example 6: A Clone is shallow
+	fmt.Println("6. Clone copies the values, not what they point to:")
+	teams := map[string][]string{"eng": {"ann", "cy"}}
+	copied := maps.Clone(teams)
+	copied["eng"][0] = "bob" // the same backing array
+	fmt.Printf("teams[\"eng\"]: %v <- changed through the clone\n", teams["eng"])
+}
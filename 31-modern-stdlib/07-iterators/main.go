@@ -0,0 +1,190 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// Countdown is a push iterator: it calls yield for each number, and
+// stops as soon as yield returns false
+func Countdown(from int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := from; i > 0; i-- {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+// Fibonacci yields (position, value) pairs forever. The loop that
+// ranges over it decides when to stop
+func Fibonacci() iter.Seq2[int, int] {
+	return func(yield func(int, int) bool) {
+		a, b := 0, 1
+		for i := 0; ; i++ {
+			if !yield(i, a) {
+				return
+			}
+			a, b = b, a+b
+		}
+	}
+}
+
+// Rows pretends to read from a database. The deferred Close runs
+// when the loop ends, even when it ends with break
+func Rows(table string) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		fmt.Printf("open %s\n", table)
+		defer fmt.Printf("close %s\n", table)
+
+		for i := 1; i <= 5; i++ {
+			if !yield(fmt.Sprintf("%s row %d", table, i)) {
+				return
+			}
+		}
+	}
+}
+
+// Merge yields the values of two sorted sequences in sorted order.
+// It needs both sides at once, so it pulls from them
+func Merge(a, b iter.Seq[int]) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		nextA, stopA := iter.Pull(a)
+		defer stopA()
+		nextB, stopB := iter.Pull(b)
+		defer stopB()
+
+		va, okA := nextA()
+		vb, okB := nextB()
+		for okA || okB {
+			if okA && (!okB || va <= vb) {
+				if !yield(va) {
+					return
+				}
+				va, okA = nextA()
+			} else {
+				if !yield(vb) {
+					return
+				}
+				vb, okB = nextB()
+			}
+		}
+	}
+}
+
+// chanCountdown is Countdown written with a goroutine and a channel
+func chanCountdown(from int) <-chan int {
+	ch := make(chan int)
+	go func() {
+		for i := from; i > 0; i-- {
+			ch <- i // blocks forever if the reader stops early
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func main() {
+	fmt.Println("Iterators and Range-over-Func")
+	fmt.Println("=============================")
+	fmt.Println()
+
+	// Example 1: Range over a function
+	fmt.Println("1. iter.Seq and range:")
+	for n := range Countdown(5) {
+		fmt.Print(n, " ")
+	}
+	fmt.Println()
+
+	// The loop above is compiled to roughly this
+	Countdown(3)(func(n int) bool {
+		fmt.Print(n, " ")
+		return true // false would mean "break"
+	})
+	fmt.Println()
+	fmt.Println()
+
+	// Example 2: Two values per step
+	fmt.Println("2. iter.Seq2 and break:")
+	for i, f := range Fibonacci() {
+		if i == 10 {
+			break // yield returns false, and Fibonacci returns
+		}
+		fmt.Print(f, " ")
+	}
+	fmt.Println()
+	fmt.Println()
+
+	// Example 3: Cleanup runs on break
+	fmt.Println("3. defer in an iterator runs when the loop ends:")
+	for row := range Rows("orders") {
+		fmt.Println(row)
+		if strings.HasSuffix(row, "2") {
+			break
+		}
+	}
+	fmt.Println()
+
+	// Example 4: Iterators in the standard library
+	fmt.Println("4. Standard library iterators:")
+	text := "first line\nsecond line\nthird line\n"
+	for line := range strings.Lines(text) { // each line keeps its \n
+		fmt.Printf("%q\n", line)
+	}
+	for part := range strings.SplitSeq("a,b,c", ",") { // no []string
+		fmt.Print(part, " ")
+	}
+	fmt.Println()
+
+	langs := []string{"go", "rust", "zig"}
+	for i, lang := range slices.Backward(langs) {
+		fmt.Printf("%d:%s ", i, lang)
+	}
+	fmt.Println()
+
+	ages := map[string]int{"cy": 29, "ann": 41, "bob": 35}
+	fmt.Println(slices.Sorted(maps.Keys(ages)))
+	fmt.Println(slices.Collect(Countdown(4)))
+	fmt.Println()
+
+	// Example 5: Pull iterators
+	fmt.Println("5. iter.Pull:")
+	next, stop := iter.Pull(Countdown(3))
+	for {
+		n, ok := next()
+		if !ok {
+			break
+		}
+		fmt.Print(n, " ")
+	}
+	stop() // safe to call after the end, and required before it
+	fmt.Println()
+
+	odds := slices.Values([]int{1, 3, 5, 7})
+	evens := slices.Values([]int{2, 4, 6})
+	fmt.Println(slices.Collect(Merge(odds, evens)))
+	fmt.Println()
+
+	// Example 6: Iterators vs channel generators
+	fmt.Println("6. Stopping early: channel vs iterator:")
+	before := runtime.NumGoroutine()
+	for n := range chanCountdown(100) {
+		if n == 98 {
+			break
+		}
+	}
+	fmt.Printf("channel:  %d goroutine(s) left behind\n", runtime.NumGoroutine()-before)
+
+	before = runtime.NumGoroutine()
+	for n := range Countdown(100) {
+		if n == 98 {
+			break
+		}
+	}
+	fmt.Printf("iterator: %d goroutine(s) left behind\n", runtime.NumGoroutine()-before)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileServer serves the files in root. Every path is opened through
+// the root, so no request can read a file outside it
+func FileServer(root *os.Root) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if name == "" {
+			name = "index.html"
+		}
+
+		f, err := root.Open(name)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			http.NotFound(w, r)
+			return
+		case err != nil: // escapes the root, or can't be read
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		defer f.Close()
+
+		info, err := f.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+		// Sets Content-Type, and handles Range and If-Modified-Since
+		http.ServeContent(w, r, info.Name(), info.ModTime(), f)
+	})
+}
+
+// naiveFileServer joins the request path to dir. filepath.Join cleans
+// the result, and cleaning "dir/../secret.txt" gives "secret.txt" NEXT
+// TO dir: this server can read any file the process can
+func naiveFileServer(dir string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := os.ReadFile(filepath.Join(dir, r.URL.Path))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(data)
+	})
+}
+
+// setup creates this tree in base:
+//
+//	secret.txt              must never be served
+//	public/hello.txt
+//	public/css/site.css
+//	public/link-in   -> hello.txt      (a symlink inside public)
+//	public/link-out  -> ../secret.txt  (a symlink out of public)
+func setup(base string) error {
+	public := filepath.Join(base, "public")
+
+	return errors.Join(
+		os.WriteFile(filepath.Join(base, "secret.txt"), []byte("password=hunter2\n"), 0o600),
+		os.MkdirAll(filepath.Join(public, "css"), 0o755),
+		os.WriteFile(filepath.Join(public, "hello.txt"), []byte("hello from public\n"), 0o644),
+		os.WriteFile(filepath.Join(public, "css", "site.css"), []byte("body { color: teal }\n"), 0o644),
+		os.Symlink("hello.txt", filepath.Join(public, "link-in")),
+		os.Symlink(filepath.Join("..", "secret.txt"), filepath.Join(public, "link-out")),
+	)
+}
+
+func main() {
+	fmt.Println("os.Root: Sandboxed File Access (Go 1.24)")
+	fmt.Println("========================================")
+	fmt.Println()
+
+	base, err := os.MkdirTemp("", "osroot")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer os.RemoveAll(base)
+	if err := setup(base); err != nil {
+		fmt.Println("setup:", err)
+		return
+	}
+	public := filepath.Join(base, "public")
+
+	// Example 1: The bug os.Root prevents
+	fmt.Println("1. filepath.Join does not stop \"..\":")
+	name := "../secret.txt" // from a request, a zip file, a config...
+	data, err := os.ReadFile(filepath.Join(public, name))
+	fmt.Printf("os.ReadFile(Join(public, %q)): %q, err=%v\n", name, data, err)
+	fmt.Println()
+
+	// Example 2: The same read through a Root
+	fmt.Println("2. Opening through os.Root:")
+	root, err := os.OpenRoot(public)
+	if err != nil {
+		fmt.Println("open root:", err)
+		return
+	}
+	defer root.Close()
+
+	for _, name := range []string{
+		"hello.txt",
+		"css/../hello.txt", // ".." is fine while it stays inside
+		"link-in",          // a symlink inside the root
+		"../secret.txt",
+		"css/../../secret.txt",
+		"link-out",    // a symlink that points out
+		"/etc/passwd", // an absolute path
+	} {
+		data, err := root.ReadFile(name)
+		if err != nil {
+			fmt.Printf("%-22s error: %v\n", name, err)
+			continue
+		}
+		fmt.Printf("%-22s %q\n", name, data)
+	}
+	fmt.Println()
+
+	// Example 3: Create, Stat, and the rest, inside the root
+	fmt.Println("3. Creating and inspecting files in the root:")
+	if err := root.MkdirAll("uploads/2026", 0o755); err != nil {
+		fmt.Println("mkdir:", err)
+	}
+	f, err := root.Create("uploads/2026/avatar.png")
+	if err == nil {
+		io.WriteString(f, "not really a png")
+		f.Close()
+	}
+	if info, err := root.Stat("uploads/2026/avatar.png"); err == nil {
+		fmt.Printf("Stat: %s, %d bytes\n", info.Name(), info.Size())
+	}
+	_, err = root.Create("../uploaded.txt")
+	fmt.Printf("Create(\"../uploaded.txt\"): %v\n", err)
+
+	// fs.FS works with fs.WalkDir, fs.Glob, template.ParseFS, and more
+	matches, _ := fs.Glob(root.FS(), "*.txt")
+	fmt.Printf("fs.Glob(root.FS(), \"*.txt\"): %v\n", matches)
+	fmt.Println()
+
+	// Example 4: A static file server
+	fmt.Println("4. A file server on os.Root vs a naive one:")
+	safe := FileServer(root)
+	naive := naiveFileServer(public)
+
+	for _, path := range []string{"/hello.txt", "/css/site.css", "/missing.txt", "/../secret.txt", "/link-out"} {
+		fmt.Printf("GET %-15s safe: %-28s naive: %s\n", path, get(safe, path), get(naive, path))
+	}
+}
+
+// get sends a request straight to h, without a client that would clean
+// the path first, and summarizes the response
+func get(h http.Handler, path string) string {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+	return fmt.Sprintf("%d %q", w.Code, strings.TrimSpace(w.Body.String()))
+}
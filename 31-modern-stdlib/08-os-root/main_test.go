@@ -0,0 +1,157 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const secret = "password=hunter2\n"
+
+// openPublic builds the tree from setup and opens its public directory
+// as a root
+func openPublic(t *testing.T) (base string, root *os.Root) {
+	t.Helper()
+	base = t.TempDir()
+	if err := setup(base); err != nil {
+		t.Fatal(err)
+	}
+	root, err := os.OpenRoot(filepath.Join(base, "public"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { root.Close() })
+	return base, root
+}
+
+// escapes are names that point outside the root, one way or another
+var escapes = []string{
+	"..",
+	"../secret.txt",
+	"css/../../secret.txt",
+	"./../secret.txt",
+	"link-out",
+	"/etc/passwd",
+}
+
+func TestRootRejectsEscapes(t *testing.T) {
+	base, root := openPublic(t)
+
+	for _, name := range escapes {
+		t.Run(name, func(t *testing.T) {
+			if data, err := root.ReadFile(name); err == nil {
+				t.Errorf("ReadFile: want an error; read %q", data)
+			}
+			if _, err := root.Stat(name); err == nil {
+				t.Error("Stat: want an error")
+			}
+			if f, err := root.OpenFile(name, os.O_WRONLY|os.O_TRUNC, 0); err == nil {
+				f.Close()
+				t.Error("OpenFile for writing: want an error")
+			}
+		})
+	}
+
+	for _, name := range []string{"../new.txt", "css/../../new.txt"} {
+		if f, err := root.Create(name); err == nil {
+			f.Close()
+			t.Errorf("Create(%q): want an error", name)
+		}
+	}
+	if err := root.Mkdir("../newdir", 0o755); err == nil {
+		t.Error(`Mkdir("../newdir"): want an error`)
+	}
+	if err := root.Remove("../secret.txt"); err == nil {
+		t.Error(`Remove("../secret.txt"): want an error`)
+	}
+
+	// Nothing outside the root changed
+	data, err := os.ReadFile(filepath.Join(base, "secret.txt"))
+	if err != nil || string(data) != secret {
+		t.Errorf("want secret.txt untouched; got %q, %v", data, err)
+	}
+	entries, _ := os.ReadDir(base)
+	if len(entries) != 2 {
+		t.Errorf("want only public and secret.txt in base; got %d entries", len(entries))
+	}
+}
+
+func TestRootAllowsInsidePaths(t *testing.T) {
+	_, root := openPublic(t)
+
+	for _, name := range []string{"hello.txt", "./hello.txt", "css/../hello.txt", "link-in"} {
+		data, err := root.ReadFile(name)
+		if err != nil || string(data) != "hello from public\n" {
+			t.Errorf("ReadFile(%q): got %q, %v", name, data, err)
+		}
+	}
+
+	if err := root.MkdirAll("a/b", 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := root.WriteFile("a/b/c.txt", []byte("ok"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := root.Stat("a/b/c.txt"); err != nil || info.Size() != 2 {
+		t.Errorf("want a/b/c.txt with 2 bytes; got %v, %v", info, err)
+	}
+}
+
+func TestFileServer(t *testing.T) {
+	_, root := openPublic(t)
+	h := FileServer(root)
+
+	tests := []struct {
+		path     string
+		wantCode int
+		wantBody string
+	}{
+		{"/hello.txt", http.StatusOK, "hello from public\n"},
+		{"/css/site.css", http.StatusOK, "body { color: teal }\n"},
+		{"/link-in", http.StatusOK, "hello from public\n"},
+		{"/missing.txt", http.StatusNotFound, ""},
+		{"/css", http.StatusNotFound, ""}, // no directory listings
+		{"/", http.StatusNotFound, ""},    // no index.html
+		{"/../secret.txt", http.StatusForbidden, ""},
+		{"/css/../../secret.txt", http.StatusForbidden, ""},
+		{"/%2e%2e/secret.txt", http.StatusForbidden, ""},
+		{"/..%2fsecret.txt", http.StatusForbidden, ""},
+		{"/link-out", http.StatusForbidden, ""},
+		{"//etc/passwd", http.StatusForbidden, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d; got %d", tt.wantCode, w.Code)
+			}
+			body := w.Body.String()
+			if tt.wantBody != "" && body != tt.wantBody {
+				t.Errorf("want body %q; got %q", tt.wantBody, body)
+			}
+			if strings.Contains(body, "hunter2") {
+				t.Errorf("served the secret: %q", body)
+			}
+		})
+	}
+}
+
+// The naive server is here to show the bug the root prevents. If this
+// test ever fails, the example in main no longer shows anything
+func TestNaiveFileServerLeaks(t *testing.T) {
+	base, _ := openPublic(t)
+	h := naiveFileServer(filepath.Join(base, "public"))
+
+	for _, path := range []string{"/../secret.txt", "/link-out"} {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != secret {
+			t.Errorf("GET %s: want the naive server to leak the secret; got %d %q", path, w.Code, w.Body)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	crand "crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Pick returns a random element of items. It takes the generator as an
+// argument, so a test can pass a seeded one and know the answer
+func Pick[T any](r *rand.Rand, items []T) T {
+	return items[r.IntN(len(items))]
+}
+
+// Backoff returns a retry delay between base/2 and base: "equal
+// jitter", so clients that failed together don't retry together
+func Backoff(r *rand.Rand, base time.Duration) time.Duration {
+	half := base / 2
+	return half + time.Duration(r.Int64N(int64(half)+1))
+}
+
+func main() {
+	fmt.Println("math/rand/v2 and Randomness Patterns (Go 1.22)")
+	fmt.Println("==============================================")
+	fmt.Println()
+
+	// Example 1: Top-level functions
+	fmt.Println("1. Top-level functions (different on every run):")
+	fmt.Printf("rand.IntN(100):      %d\n", rand.IntN(100)) // [0, 100)
+	fmt.Printf("rand.Float64():      %.3f\n", rand.Float64())
+	fmt.Printf("rand.N(time.Second): %v\n", rand.N(time.Second)) // any integer type
+	fmt.Printf("rand.N(int8(10)):    %d\n", rand.N(int8(10)))
+	fmt.Println()
+
+	// Example 2: The same seed gives the same numbers
+	fmt.Println("2. Seeded generators:")
+	a := rand.New(rand.NewPCG(1, 2))
+	b := rand.New(rand.NewPCG(1, 2))
+	fmt.Printf("PCG(1, 2): %d %d %d\n", a.IntN(100), a.IntN(100), a.IntN(100))
+	fmt.Printf("PCG(1, 2): %d %d %d <- the same\n", b.IntN(100), b.IntN(100), b.IntN(100))
+
+	var seed [32]byte
+	copy(seed[:], "a 32 byte seed for the ChaCha8..")
+	c := rand.New(rand.NewChaCha8(seed))
+	fmt.Printf("ChaCha8:   %d %d %d\n", c.IntN(100), c.IntN(100), c.IntN(100))
+	fmt.Println()
+
+	// Example 3: Shuffle and Perm
+	fmt.Println("3. Shuffle and Perm:")
+	r := rand.New(rand.NewPCG(7, 7))
+	deck := []string{"A", "K", "Q", "J", "10", "9"}
+	r.Shuffle(len(deck), func(i, j int) { deck[i], deck[j] = deck[j], deck[i] })
+	fmt.Printf("Shuffle: %v\n", deck)
+	fmt.Printf("Perm(5): %v\n", r.Perm(5))
+	fmt.Println()
+
+	// Example 4: Pass the generator in
+	fmt.Println("4. Functions that take a *rand.Rand:")
+	colors := []string{"red", "green", "blue", "teal"}
+	for range 2 {
+		r := rand.New(rand.NewPCG(42, 0)) // what a test would do
+		fmt.Printf("Pick: %s, Backoff(1s): %v\n", Pick(r, colors), Backoff(r, time.Second))
+	}
+	fmt.Println()
+
+	// Example 5: Secrets need crypto/rand
+	fmt.Println("5. crypto/rand for secrets:")
+	fmt.Printf("crand.Text():  %s\n", crand.Text()) // Go 1.24: 26 base32 chars, 128 bits
+
+	key := make([]byte, 16)
+	crand.Read(key) // never returns an error since Go 1.24
+	fmt.Printf("crand.Read:    %s\n", hex.EncodeToString(key))
+
+	// A PCG's state can be worked out from its output. Tokens from it
+	// can be predicted: never use math/rand for secrets
+	guessable := rand.New(rand.NewPCG(1, 2))
+	fmt.Printf("not a secret:  %x\n", guessable.Uint64())
+	fmt.Println()
+
+	// Example 6: v1 habits that are gone
+	fmt.Println("6. What changed from math/rand:")
+	fmt.Println("rand.Seed is gone: the top-level generator seeds itself")
+	fmt.Println("rand.Intn is now rand.IntN, rand.Int63 is now rand.Int64")
+	fmt.Println("rand.Read is gone: use crypto/rand")
+}
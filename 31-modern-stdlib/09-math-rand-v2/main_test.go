@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+	"time"
+)
+
+// A seed per test: when a test fails, running it again gives the same
+// numbers, so the failure can be reproduced
+func TestPickIsReproducible(t *testing.T) {
+	items := []string{"a", "b", "c", "d"}
+
+	var first, second []string
+	r1 := rand.New(rand.NewPCG(1, 2))
+	r2 := rand.New(rand.NewPCG(1, 2))
+	for range 20 {
+		first = append(first, Pick(r1, items))
+		second = append(second, Pick(r2, items))
+	}
+
+	if !slices.Equal(first, second) {
+		t.Errorf("want the same picks from the same seed:\n%v\n%v", first, second)
+	}
+}
+
+// Check properties, not exact numbers. The numbers a seed gives are
+// an implementation detail; "every item shows up" isn't
+func TestPickReachesEveryItem(t *testing.T) {
+	r := rand.New(rand.NewPCG(3, 4))
+	items := []int{10, 20, 30}
+
+	seen := make(map[int]int)
+	for range 300 {
+		seen[Pick(r, items)]++
+	}
+	for _, it := range items {
+		if seen[it] == 0 {
+			t.Errorf("want %d picked at least once in 300 tries; counts %v", it, seen)
+		}
+	}
+}
+
+func TestBackoffRange(t *testing.T) {
+	r := rand.New(rand.NewPCG(5, 6))
+	base := time.Second
+
+	for range 1000 {
+		d := Backoff(r, base)
+		if d < base/2 || d > base {
+			t.Fatalf("want a delay in [%v, %v]; got %v", base/2, base, d)
+		}
+	}
+}
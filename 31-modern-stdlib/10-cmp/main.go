@@ -0,0 +1,107 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+)
+
+// Version is compared part by part: 1.10.0 comes after 1.9.3
+type Version struct {
+	Major, Minor, Patch int
+}
+
+// Compare returns -1, 0, or +1, like cmp.Compare. Methods with this
+// shape plug into slices.SortFunc as method expressions
+func (v Version) Compare(o Version) int {
+	return cmp.Or(
+		cmp.Compare(v.Major, o.Major),
+		cmp.Compare(v.Minor, o.Minor),
+		cmp.Compare(v.Patch, o.Patch),
+	)
+}
+
+func (v Version) String() string {
+	return fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+}
+
+// Player is sorted by several keys below
+type Player struct {
+	Name  string
+	Team  string
+	Score int
+}
+
+func main() {
+	fmt.Println("The cmp Package and Ordering")
+	fmt.Println("============================")
+	fmt.Println()
+
+	// Example 1: Compare and Less
+	fmt.Println("1. cmp.Compare and cmp.Less:")
+	fmt.Printf("Compare(1, 2): %d, Compare(2, 2): %d, Compare(3, 2): %d\n",
+		cmp.Compare(1, 2), cmp.Compare(2, 2), cmp.Compare(3, 2))
+	fmt.Printf("Compare(\"go\", \"rust\"): %d\n", cmp.Compare("go", "rust"))
+	fmt.Printf("Less(1.5, 2.5): %v\n", cmp.Less(1.5, 2.5))
+	fmt.Println()
+
+	// Example 2: NaN has a place in the order
+	fmt.Println("2. NaN:")
+	nan := math.NaN()
+	fmt.Printf("nan < 1: %v, nan > 1: %v, nan == nan: %v <- < and == give up\n", nan < 1, nan > 1, nan == nan)
+	fmt.Printf("Compare(nan, 1): %d, Compare(nan, nan): %d <- NaN sorts first\n", cmp.Compare(nan, 1), cmp.Compare(nan, nan))
+	floats := []float64{3, nan, 1, 2}
+	slices.SortFunc(floats, cmp.Compare[float64])
+	fmt.Printf("sorted: %v\n", floats)
+	fmt.Println()
+
+	// Example 3: Or picks the first non-zero value
+	fmt.Println("3. cmp.Or:")
+	var flagPort, envPort string // empty: not set
+	fmt.Printf("port: %s\n", cmp.Or(flagPort, envPort, "8080"))
+	fmt.Printf("Or(0, 0, 3, 4): %d\n", cmp.Or(0, 0, 3, 4))
+	fmt.Println()
+
+	// Example 4: Sorting by several keys
+	fmt.Println("4. Multi-key sorting with cmp.Or:")
+	players := []Player{
+		{"ann", "red", 30}, {"bob", "blue", 45}, {"cy", "red", 45},
+		{"di", "blue", 45}, {"ed", "red", 10},
+	}
+	slices.SortFunc(players, func(a, b Player) int {
+		return cmp.Or(
+			cmp.Compare(a.Team, b.Team),   // team A to Z
+			cmp.Compare(b.Score, a.Score), // then score, high first: b before a
+			strings.Compare(a.Name, b.Name),
+		)
+	})
+	for _, p := range players {
+		fmt.Printf("%-4s %-4s %d\n", p.Team, p.Name, p.Score)
+	}
+	fmt.Println()
+
+	// Example 5: A Compare method
+	fmt.Println("5. Sorting and searching with a Compare method:")
+	versions := []Version{{1, 10, 0}, {1, 9, 3}, {2, 0, 0}, {1, 9, 12}}
+	slices.SortFunc(versions, Version.Compare)
+	fmt.Printf("sorted: %v\n", versions)
+	i, found := slices.BinarySearchFunc(versions, Version{1, 10, 0}, Version.Compare)
+	fmt.Printf("BinarySearchFunc(1.10.0): index %d, found %v\n", i, found)
+	fmt.Printf("newest: %v\n", slices.MaxFunc(versions, Version.Compare))
+	fmt.Println()
+
+	// Example 6: The built-in min and max
+	fmt.Println("6. Built-in min and max (Go 1.21):")
+	fmt.Printf("min(3, 1, 2): %d, max(3, 1, 2): %d\n", min(3, 1, 2), max(3, 1, 2))
+	fmt.Printf("max(\"go\", \"rust\", \"c\"): %s\n", max("go", "rust", "c"))
+	fmt.Printf("min(2.5, nan): %v <- any NaN wins\n", min(2.5, nan))
+
+	limit := 100
+	requested := 250
+	fmt.Printf("clamp: %d\n", max(0, min(requested, limit)))
+
+	scores := []int{7, 3, 9}
+	fmt.Printf("min of a slice needs slices.Min: %d\n", slices.Min(scores))
+}
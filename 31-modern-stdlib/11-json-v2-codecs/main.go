@@ -0,0 +1,194 @@
+//go:build goexperiment.jsonv2 && !go1.27
+
+package main
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Email is its own type so that a marshaler can find every email
+// address in a value, wherever it is, by its type
+type Email string
+
+// Tags is a set. In JSON it's a sorted array of names, written and read
+// a token at a time
+type Tags map[string]bool
+
+// MarshalJSONTo writes straight to the encoder, with no []byte in
+// between: v2 calls it instead of MarshalJSON, and it's faster
+func (t Tags) MarshalJSONTo(enc *jsontext.Encoder) error {
+	if err := enc.WriteToken(jsontext.BeginArray); err != nil {
+		return err
+	}
+	for _, name := range slices.Sorted(maps.Keys(t)) {
+		if err := enc.WriteToken(jsontext.String(name)); err != nil {
+			return err
+		}
+	}
+	return enc.WriteToken(jsontext.EndArray)
+}
+
+// UnmarshalJSONFrom reads one JSON value from the decoder, a token at a
+// time, and must read all of it
+func (t *Tags) UnmarshalJSONFrom(dec *jsontext.Decoder) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '[' {
+		return fmt.Errorf("tags: want an array, got %v", tok.Kind())
+	}
+	set := make(Tags)
+	for dec.PeekKind() != ']' {
+		tok, err := dec.ReadToken()
+		if err != nil {
+			return err
+		}
+		if tok.Kind() != '"' {
+			return fmt.Errorf("tags: want strings, got %v", tok.Kind())
+		}
+		name := tok.String()
+		if set[name] {
+			return fmt.Errorf("tags: %q twice", name)
+		}
+		set[name] = true
+	}
+	if _, err := dec.ReadToken(); err != nil { // the ]
+		return err
+	}
+	*t = set
+	return nil
+}
+
+// User is the user of the earlier lessons, with tags
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email Email  `json:"email"`
+	Tags  Tags   `json:"tags,omitempty"`
+}
+
+// Session uses format options: each one picks how v2 writes a time, a
+// duration, or bytes, for one field, with no type of its own
+type Session struct {
+	Started  time.Time     `json:"started,format:DateOnly"`
+	LastSeen time.Time     `json:"last_seen,format:unix"`
+	Idle     time.Duration `json:"idle,format:units"`
+	Timeout  time.Duration `json:"timeout,format:sec"`
+	Token    []byte        `json:"token,format:base16"`
+}
+
+// redactEmails writes every Email as "a***@example.com", for logs.
+// It's an option, not a method: Email itself still marshals in full
+// everywhere else
+var redactEmails = json.MarshalToFunc(func(enc *jsontext.Encoder, e Email) error {
+	return enc.WriteToken(jsontext.String(redact(e)))
+})
+
+func redact(e Email) string {
+	local, domain, ok := strings.Cut(string(e), "@")
+	if !ok || local == "" {
+		return "***"
+	}
+	return local[:1] + "***@" + domain
+}
+
+// lowerEmails reads every Email in lower case, whichever struct it's in
+var lowerEmails = json.UnmarshalFromFunc(func(dec *jsontext.Decoder, e *Email) error {
+	tok, err := dec.ReadToken()
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != '"' {
+		return fmt.Errorf("email: want a string, got %v", tok.Kind())
+	}
+	*e = Email(strings.ToLower(tok.String()))
+	return nil
+})
+
+var ada = User{ID: 1, Name: "Ada Lovelace", Email: "ada@example.com", Tags: Tags{"admin": true}}
+
+var session = Session{
+	Started:  time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC),
+	LastSeen: time.Date(2025, 3, 1, 11, 0, 0, 0, time.UTC),
+	Idle:     90 * time.Second,
+	Timeout:  30 * time.Minute,
+	Token:    []byte{0xde, 0xad, 0xbe, 0xef},
+}
+
+func main() {
+	fmt.Println("JSON v2: Custom Codecs and Options")
+	fmt.Println("==================================")
+	fmt.Println()
+
+	// Example 1: MarshalerTo and UnmarshalerFrom
+	fmt.Println("1. Tags, written and read as tokens:")
+	show(Tags{"go": true, "json": true, "api": true})
+	for _, in := range []string{`["b","a"]`, `["a","a"]`, `"a"`, `[1]`} {
+		var t Tags
+		err := json.Unmarshal([]byte(in), &t)
+		fmt.Printf("   %-10s -> %v, err: %v\n", in, slices.Sorted(maps.Keys(t)), err)
+	}
+	fmt.Println()
+
+	// Example 2: Format options
+	fmt.Println("2. Format options on the fields:")
+	show(session, jsontext.Multiline(true), jsontext.WithIndentPrefix("   "))
+	fmt.Println()
+
+	// Example 3: Type-specific marshalers, as options
+	fmt.Println("3. The same users, for an API and for a log:")
+	team := map[string][]User{"admins": {ada}}
+	show(team)
+	show(team, json.WithMarshalers(redactEmails))
+
+	var u User
+	in := `{"id":2,"name":"Grace","email":"Grace.Hopper@Example.COM"}`
+	if err := json.Unmarshal([]byte(in), &u, json.WithUnmarshalers(lowerEmails)); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   read with lowerEmails: %s\n", u.Email)
+	fmt.Println()
+
+	// Example 4: Options that match names
+	fmt.Println("4. Matching names:")
+	for _, opts := range []struct {
+		name string
+		opts []json.Options
+	}{
+		{"the default", nil},
+		{"MatchCaseInsensitiveNames", []json.Options{json.MatchCaseInsensitiveNames(true)}},
+		{"RejectUnknownMembers", []json.Options{json.RejectUnknownMembers(true)}},
+	} {
+		var u User
+		err := json.Unmarshal([]byte(`{"ID":3,"Name":"Ken","role":"admin"}`), &u, opts.opts...)
+		fmt.Printf("   %-26s id=%d name=%q err: %v\n", opts.name+":", u.ID, u.Name, err)
+	}
+	fmt.Println()
+
+	// Example 5: Options for output
+	fmt.Println("5. Maps and nil slices:")
+	counts := map[string]int{"c": 3, "a": 1, "b": 2}
+	show(counts) // in no particular order
+	show(counts, json.Deterministic(true))
+	var none []string
+	show(none)
+	show(none, json.FormatNilSliceAsNull(true))
+}
+
+// show prints v as v2 writes it, with opts
+func show(v any, opts ...json.Options) {
+	data, err := json.Marshal(v, opts...)
+	if err != nil {
+		fmt.Printf("   error: %v\n", err)
+		return
+	}
+	fmt.Printf("   %s\n", data)
+}
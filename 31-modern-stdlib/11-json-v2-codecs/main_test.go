@@ -0,0 +1,189 @@
+//go:build goexperiment.jsonv2 && !go1.27
+
+package main
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTagsRoundTrip(t *testing.T) {
+	for _, in := range []Tags{nil, {}, {"b": true, "a": true, "c": true}} {
+		data, err := json.Marshal(in)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Tags
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("%s: %v", data, err)
+		}
+		if len(got) != len(in) {
+			t.Errorf("%s: want %v; got %v", data, in, got)
+		}
+	}
+
+	data, _ := json.Marshal(Tags{"b": true, "a": true})
+	if want := `["a","b"]`; string(data) != want {
+		t.Errorf("want %s, sorted; got %s", want, data)
+	}
+}
+
+func TestTagsErrors(t *testing.T) {
+	tests := []struct {
+		in, err string
+	}{
+		{`["a","a"]`, `"a" twice`},
+		{`"a"`, "want an array"},
+		{`{}`, "want an array"},
+		{`[1]`, "want strings"},
+		{`["a",null]`, "want strings"},
+		{`["a"`, "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Tags
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Fatalf("want an error with %q; got %v", tt.err, err)
+			}
+			if got != nil {
+				t.Errorf("want the tags left alone on an error; got %v", got)
+			}
+		})
+	}
+}
+
+// TestTagsInAStream checks that UnmarshalJSONFrom reads its value and
+// nothing more: the decoder must be at the next value afterwards
+func TestTagsInAStream(t *testing.T) {
+	in := `{"id":1,"tags":["x","y"],"name":"Ada"}`
+	var u User
+	if err := json.Unmarshal([]byte(in), &u); err != nil {
+		t.Fatal(err)
+	}
+	if u.Name != "Ada" || !u.Tags["x"] || !u.Tags["y"] {
+		t.Errorf("want every field read; got %+v", u)
+	}
+
+	dec := jsontext.NewDecoder(strings.NewReader(`["a"] ["b"]`))
+	var first, second Tags
+	if err := json.UnmarshalDecode(dec, &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.UnmarshalDecode(dec, &second); err != nil {
+		t.Fatal(err)
+	}
+	if !first["a"] || !second["b"] {
+		t.Errorf("want [a] then [b]; got %v then %v", first, second)
+	}
+}
+
+func TestRedactEmails(t *testing.T) {
+	tests := []struct {
+		in   Email
+		want string
+	}{
+		{"ada@example.com", "a***@example.com"},
+		{"a@example.com", "a***@example.com"},
+		{"@example.com", "***"},
+		{"not an email", "***"},
+	}
+	for _, tt := range tests {
+		if got := redact(tt.in); got != tt.want {
+			t.Errorf("redact(%q): want %q; got %q", tt.in, tt.want, got)
+		}
+	}
+
+	// Every Email, at any depth; and only with the option
+	v := map[string][]User{"team": {ada, {Email: "grace@example.com"}}}
+	data, err := json.Marshal(v, json.WithMarshalers(redactEmails))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(data); strings.Contains(s, "ada@") || strings.Contains(s, "grace@") {
+		t.Errorf("want every email redacted; got %s", s)
+	}
+	data, _ = json.Marshal(v)
+	if !strings.Contains(string(data), "ada@example.com") {
+		t.Errorf("want emails in full without the option; got %s", data)
+	}
+}
+
+func TestLowerEmails(t *testing.T) {
+	var u User
+	err := json.Unmarshal([]byte(`{"email":"Ada@Example.COM"}`), &u, json.WithUnmarshalers(lowerEmails))
+	if err != nil || u.Email != "ada@example.com" {
+		t.Errorf("want ada@example.com; got %q, %v", u.Email, err)
+	}
+
+	err = json.Unmarshal([]byte(`{"email":42}`), &u, json.WithUnmarshalers(lowerEmails))
+	if err == nil || !strings.Contains(err.Error(), "want a string") {
+		t.Errorf("want an error for a number; got %v", err)
+	}
+}
+
+func TestNameMatching(t *testing.T) {
+	in := []byte(`{"ID":3,"Name":"Ken"}`)
+
+	var u User
+	if err := json.Unmarshal(in, &u); err != nil || u.ID != 0 {
+		t.Errorf("want names matched exactly by default; got %+v, %v", u, err)
+	}
+	if err := json.Unmarshal(in, &u, json.MatchCaseInsensitiveNames(true)); err != nil || u.ID != 3 || u.Name != "Ken" {
+		t.Errorf("want ID and Name matched; got %+v, %v", u, err)
+	}
+	if err := json.Unmarshal(in, &u, json.RejectUnknownMembers(true)); err == nil {
+		t.Error("want an error for the unknown ID")
+	}
+}
+
+func TestOutputOptions(t *testing.T) {
+	data, err := json.Marshal(map[string]int{"c": 3, "a": 1, "b": 2}, json.Deterministic(true))
+	if err != nil || string(data) != `{"a":1,"b":2,"c":3}` {
+		t.Errorf("want sorted keys; got %s, %v", data, err)
+	}
+
+	var none []string
+	for _, tt := range []struct {
+		opts []json.Options
+		want string
+	}{
+		{nil, `[]`},
+		{[]json.Options{json.FormatNilSliceAsNull(true)}, `null`},
+	} {
+		data, err := json.Marshal(none, tt.opts...)
+		if err != nil || string(data) != tt.want {
+			t.Errorf("want %s; got %s, %v", tt.want, data, err)
+		}
+	}
+}
+
+func TestFormatOptions(t *testing.T) {
+	data, err := json.Marshal(session)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"started":"2025-03-01"`,
+		`"last_seen":1740826800`,
+		`"idle":"1m30s"`,
+		`"timeout":1800`,
+		`"token":"deadbeef"`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("want %s in %s", want, data)
+		}
+	}
+
+	var got Session
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Started.Equal(session.Started.Truncate(24*time.Hour)) || !got.LastSeen.Equal(session.LastSeen) ||
+		got.Idle != session.Idle || got.Timeout != session.Timeout || string(got.Token) != string(session.Token) {
+		t.Errorf("want %+v back; got %+v", session, got)
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !goexperiment.jsonv2 || go1.27
+
+package main
+
+import "fmt"
+
+// This lesson needs encoding/json/v2, which Go 1.25 and 1.26 only build
+// with GOEXPERIMENT=jsonv2. Go 1.27 makes it stable, but only for modules
+// that say go 1.27 in go.mod, and this one says 1.25: on Go 1.27 the
+// experiment is no help, and only an older toolchain runs the lesson
+func main() {
+	fmt.Println("JSON v2: Custom Codecs and Options")
+	fmt.Println("==================================")
+	fmt.Println()
+	fmt.Println("This lesson needs the json/v2 experiment of Go 1.25 or 1.26.")
+	fmt.Println("The go command downloads the toolchain. Run it with:")
+	fmt.Println("   GOTOOLCHAIN=go1.26.0 GOEXPERIMENT=jsonv2 go run .")
+	fmt.Println("   GOTOOLCHAIN=go1.26.0 GOEXPERIMENT=jsonv2 go test -race -v")
+}
@@ -0,0 +1,63 @@
+// ---------------------------------------------------------
+// EXERCISE: Custom slog Handler
+//
+//  Write a slog.Handler that prints short, readable lines
+//  for a terminal, like this:
+//
+//    INFO  order placed service=orders http.status=201
+//
+//  1- Create a PrettyHandler type and a constructor:
+//       NewPrettyHandler(w io.Writer, level slog.Leveler) *PrettyHandler
+//     A nil level means slog.LevelInfo
+//
+//  2- Implement the four methods of slog.Handler:
+//     - Enabled: true for levels at or above the handler's level
+//     - Handle: write the level (padded to 5 characters), the
+//       message, then " key=value" for every attribute
+//     - WithAttrs: return a NEW handler that adds these attributes
+//       to every line
+//     - WithGroup: return a NEW handler that puts the keys that
+//       come after it in a group: "db.table=orders"
+//
+//  3- Handle the details:
+//     - Groups print as dotted keys: http.method=POST
+//     - A group with an empty key is inlined; empty groups and
+//       empty attributes are skipped
+//     - Call Value.Resolve, so slog.LogValuer types work
+//     - Quote values that are empty or contain a space, =, ",
+//       a tab, or a newline
+//     - Write each line with ONE Write call, under a mutex shared
+//       by every handler made from the same NewPrettyHandler
+//
+//  4- In main, log the lines in the expected output below
+//
+// HINTS
+//
+//  - slog.Record.Attrs(func(slog.Attr) bool) visits the attributes
+//  - Copy the handler struct in WithAttrs and WithGroup:
+//      h2 := *h
+//  - Format the WithAttrs attributes once, and keep the result
+//  - strconv.AppendQuote quotes a string
+//
+// EXPECTED OUTPUT
+//
+//  Test 1: Messages and levels
+//  INFO  server started port=8080
+//  DEBUG shown at Debug cache=warm
+//  ERROR payment failed reason="card declined"
+//
+//  Test 2: With, Group, and WithGroup
+//  INFO  order placed service=orders http.method=POST http.status=201
+//  INFO  query service=orders db.table=orders db.rows=3
+//  INFO  the original logger is unchanged
+//
+//  Test 3: LogValuer and quoting
+//  INFO  login user.id=42 user.name=ada
+//  INFO  quoted query="name = 'ada'" empty=""
+//
+// ---------------------------------------------------------
+
+package main
+
+func main() {
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// PrettyHandler writes short, readable lines for a terminal:
+//
+//	INFO  order placed service=orders http.status=201
+type PrettyHandler struct {
+	w     io.Writer
+	mu    *sync.Mutex // shared by every handler made from this one
+	level slog.Leveler
+
+	prefix string // open groups, each followed by a dot
+	attrs  string // attributes from WithAttrs, already formatted
+}
+
+// NewPrettyHandler returns a handler that writes records at level or
+// above to w. A nil level means slog.LevelInfo.
+func NewPrettyHandler(w io.Writer, level slog.Leveler) *PrettyHandler {
+	if level == nil {
+		level = slog.LevelInfo
+	}
+	return &PrettyHandler{w: w, mu: new(sync.Mutex), level: level}
+}
+
+// Enabled reports whether records at l are written. Loggers call it
+// before they build a record, so filtered calls cost almost nothing
+func (h *PrettyHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
+}
+
+// Handle formats r and writes it with a single Write call
+func (h *PrettyHandler) Handle(_ context.Context, r slog.Record) error {
+	buf := fmt.Appendf(nil, "%-5s %s", r.Level, r.Message)
+	buf = append(buf, h.attrs...)
+	r.Attrs(func(a slog.Attr) bool {
+		buf = appendAttr(buf, h.prefix, a)
+		return true
+	})
+	buf = append(buf, '\n')
+
+	// Lines from different goroutines must not mix
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.w.Write(buf)
+	return err
+}
+
+// WithAttrs returns a new handler that adds attrs to every record. It
+// formats them once, here, instead of on every Handle
+func (h *PrettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	buf := []byte(h.attrs)
+	for _, a := range attrs {
+		buf = appendAttr(buf, h.prefix, a)
+	}
+
+	h2 := *h // a copy: h itself must not change
+	h2.attrs = string(buf)
+	return &h2
+}
+
+// WithGroup returns a new handler that puts every attribute that comes
+// after it in the group name
+func (h *PrettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	h2 := *h
+	h2.prefix += name + "."
+	return &h2
+}
+
+// appendAttr appends " key=value", or one of those per attribute in a
+// group, with the group names before the key
+func appendAttr(buf []byte, prefix string, a slog.Attr) []byte {
+	a.Value = a.Value.Resolve() // calls LogValue
+	if a.Equal(slog.Attr{}) {
+		return buf
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		if a.Key != "" { // a group without a key is inlined
+			prefix += a.Key + "."
+		}
+		for _, ga := range a.Value.Group() {
+			buf = appendAttr(buf, prefix, ga)
+		}
+		return buf
+	}
+
+	buf = append(buf, ' ')
+	buf = append(buf, prefix...)
+	buf = append(buf, a.Key...)
+	buf = append(buf, '=')
+	return appendValue(buf, a.Value.String())
+}
+
+// appendValue quotes values that would be ambiguous without quotes
+func appendValue(buf []byte, s string) []byte {
+	if s == "" || strings.ContainsAny(s, " =\"\n\t") {
+		return strconv.AppendQuote(buf, s)
+	}
+	return append(buf, s...)
+}
+
+// User logs only its ID and name
+type User struct {
+	ID       int
+	Name     string
+	Password string
+}
+
+func (u User) LogValue() slog.Value {
+	return slog.GroupValue(slog.Int("id", u.ID), slog.String("name", u.Name))
+}
+
+func main() {
+	fmt.Println("Custom Handler Exercise - Solution")
+	fmt.Println("==================================")
+	fmt.Println()
+
+	var level slog.LevelVar
+	logger := slog.New(NewPrettyHandler(os.Stdout, &level))
+
+	fmt.Println("Test 1: Messages and levels")
+	logger.Info("server started", "port", 8080)
+	logger.Debug("not shown at Info")
+	level.Set(slog.LevelDebug)
+	logger.Debug("shown at Debug", "cache", "warm")
+	logger.Error("payment failed", "reason", "card declined")
+	fmt.Println()
+
+	fmt.Println("Test 2: With, Group, and WithGroup")
+	orders := logger.With("service", "orders")
+	orders.Info("order placed", slog.Group("http", "method", "POST", "status", 201))
+	orders.WithGroup("db").Info("query", "table", "orders", "rows", 3)
+	logger.Info("the original logger is unchanged")
+	fmt.Println()
+
+	fmt.Println("Test 3: LogValuer and quoting")
+	logger.Info("login", "user", User{ID: 42, Name: "ada", Password: "hunter2"})
+	logger.Info("quoted", "query", "name = 'ada'", "empty", "")
+}
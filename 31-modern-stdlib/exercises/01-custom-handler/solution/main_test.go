@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func newLogger(level slog.Leveler) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(NewPrettyHandler(&buf, level)), &buf
+}
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		name string
+		log  func(*slog.Logger)
+		want string
+	}{
+		{
+			"message only",
+			func(l *slog.Logger) { l.Info("hello") },
+			"INFO  hello\n",
+		},
+		{
+			"pairs and attrs",
+			func(l *slog.Logger) { l.Warn("disk", "percent", 91, slog.Bool("full", false)) },
+			"WARN  disk percent=91 full=false\n",
+		},
+		{
+			"quoted values",
+			func(l *slog.Logger) { l.Info("q", "a", "x y", "b", "k=v", "c", `say "hi"`, "d", "") },
+			`INFO  q a="x y" b="k=v" c="say \"hi\"" d=""` + "\n",
+		},
+		{
+			"group",
+			func(l *slog.Logger) { l.Info("req", slog.Group("http", "method", "GET", "status", 200)) },
+			"INFO  req http.method=GET http.status=200\n",
+		},
+		{
+			"nested and inlined groups",
+			func(l *slog.Logger) {
+				l.Info("g", slog.Group("a", slog.Group("b", "c", 1)), slog.Group("", "inline", true))
+			},
+			"INFO  g a.b.c=1 inline=true\n",
+		},
+		{
+			"empty attrs and groups are skipped",
+			func(l *slog.Logger) { l.Info("e", slog.Attr{}, slog.Group("none")) },
+			"INFO  e\n",
+		},
+		{
+			"LogValuer",
+			func(l *slog.Logger) { l.Info("login", "user", User{ID: 1, Name: "ada", Password: "secret"}) },
+			"INFO  login user.id=1 user.name=ada\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, buf := newLogger(nil)
+			tt.log(l)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("\nwant %q\ngot  %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLevel(t *testing.T) {
+	var level slog.LevelVar
+	l, buf := newLogger(&level)
+
+	l.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Errorf("want Debug dropped at Info; got %q", buf.String())
+	}
+
+	level.Set(slog.LevelDebug)
+	l.Debug("shown")
+	if got := buf.String(); got != "DEBUG shown\n" {
+		t.Errorf("want the new level used right away; got %q", got)
+	}
+}
+
+func TestWithAttrsAndGroup(t *testing.T) {
+	l, buf := newLogger(nil)
+
+	orders := l.With("service", "orders")
+	db := orders.WithGroup("db").With("table", "orders")
+	db.Info("query", "rows", 3)
+	orders.Info("done")
+	l.Info("plain")
+
+	want := "INFO  query service=orders db.table=orders db.rows=3\n" +
+		"INFO  done service=orders\n" +
+		"INFO  plain\n"
+	if got := buf.String(); got != want {
+		t.Errorf("want parents unchanged by With and WithGroup:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+// Run with -race: the handlers made by With share one mutex
+func TestConcurrentLines(t *testing.T) {
+	l, buf := newLogger(nil)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		child := l.With("g", g)
+		wg.Go(func() {
+			for i := range 100 {
+				child.Info("line", "i", i)
+			}
+		})
+	}
+	wg.Wait()
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	if len(lines) != 800 {
+		t.Fatalf("want 800 lines; got %d", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "INFO  line g=") {
+			t.Fatalf("want whole lines, not mixed ones; got %q", line)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// ---------------------------------------------------------
+// EXERCISE: Generator to Iterator
+//
+//  29-concurrency/03-channel-select has a generator that starts
+//  a goroutine and sends messages on a channel, and a fanIn
+//  that merges several of those channels. Rewrite both as
+//  iterators, with no goroutines and no channels.
+//
+//  1- Write Generate:
+//       func Generate(prefix string, count int) iter.Seq[string]
+//     It yields "prefix: message 1" ... "prefix: message count",
+//     and stops as soon as yield returns false
+//
+//  2- Write Interleave, the iterator version of fanIn:
+//       func Interleave(seqs ...iter.Seq[string]) iter.Seq[string]
+//     It yields one value from each sequence in turn, skips the
+//     sequences that have ended, and stops when all of them have.
+//     Unlike fanIn, the order is always the same
+//
+//  3- Stopping early must clean up: after a break, every sequence
+//     that Interleave started must have returned
+//
+//  4- Copy generator from 03-channel-select. Break out of a loop
+//     over it and over Interleave, and print how many goroutines
+//     each one left behind (runtime.NumGoroutine before and after)
+//
+// HINTS
+//
+//  - Interleave can't range over several sequences at once.
+//    iter.Pull turns each one into a next function
+//  - Call every stop function, with defer
+//
+// EXPECTED OUTPUT
+//
+//  Test 1: Generate
+//  source1: message 1
+//  source1: message 2
+//  source1: message 3
+//
+//  Test 2: Interleave
+//  source1: message 1
+//  source2: message 1
+//  source1: message 2
+//  source2: message 2
+//  source1: message 3
+//
+//  Test 3: Stopping early
+//  generator: 1 goroutine(s) left behind
+//  Interleave: 0 goroutine(s) left behind
+//
+// ---------------------------------------------------------
+
+package main
+
+func main() {
+}
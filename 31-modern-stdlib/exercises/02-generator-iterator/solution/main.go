@@ -0,0 +1,92 @@
+package main
+
+import (
+	"fmt"
+	"iter"
+	"runtime"
+	"time"
+)
+
+// Generate yields count messages. It is the iterator version of
+// generator: no goroutine, no channel
+func Generate(prefix string, count int) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for i := 1; i <= count; i++ {
+			if !yield(fmt.Sprintf("%s: message %d", prefix, i)) {
+				return
+			}
+		}
+	}
+}
+
+// Interleave yields one value from each sequence in turn, until all of
+// them end. It is the iterator version of fanIn, with a fixed order
+func Interleave(seqs ...iter.Seq[string]) iter.Seq[string] {
+	return func(yield func(string) bool) {
+		nexts := make([]func() (string, bool), 0, len(seqs))
+		for _, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop() // lets each sequence run its own defers
+			nexts = append(nexts, next)
+		}
+
+		for len(nexts) > 0 {
+			live := nexts[:0]
+			for _, next := range nexts {
+				v, ok := next()
+				if !ok {
+					continue // this one ended: drop it
+				}
+				if !yield(v) {
+					return
+				}
+				live = append(live, next)
+			}
+			nexts = live
+		}
+	}
+}
+
+// generator is the channel version, from 29-concurrency/03-channel-select
+func generator(prefix string, count int) <-chan string {
+	ch := make(chan string)
+	go func() {
+		for i := 1; i <= count; i++ {
+			ch <- fmt.Sprintf("%s: message %d", prefix, i)
+			time.Sleep(50 * time.Millisecond)
+		}
+		close(ch)
+	}()
+	return ch
+}
+
+func main() {
+	fmt.Println("Generator to Iterator Exercise - Solution")
+	fmt.Println("=========================================")
+	fmt.Println()
+
+	fmt.Println("Test 1: Generate")
+	for msg := range Generate("source1", 3) {
+		fmt.Println(msg)
+	}
+	fmt.Println()
+
+	fmt.Println("Test 2: Interleave")
+	for msg := range Interleave(Generate("source1", 3), Generate("source2", 2)) {
+		fmt.Println(msg)
+	}
+	fmt.Println()
+
+	fmt.Println("Test 3: Stopping early")
+	before := runtime.NumGoroutine()
+	for range generator("chan", 10) {
+		break
+	}
+	fmt.Printf("generator: %d goroutine(s) left behind\n", runtime.NumGoroutine()-before)
+
+	before = runtime.NumGoroutine()
+	for range Interleave(Generate("a", 10), Generate("b", 10)) {
+		break
+	}
+	fmt.Printf("Interleave: %d goroutine(s) left behind\n", runtime.NumGoroutine()-before)
+}
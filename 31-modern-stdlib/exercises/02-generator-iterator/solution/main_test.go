@@ -0,0 +1,99 @@
+package main
+
+import (
+	"iter"
+	"slices"
+	"testing"
+)
+
+func TestGenerate(t *testing.T) {
+	got := slices.Collect(Generate("s", 3))
+	want := []string{"s: message 1", "s: message 2", "s: message 3"}
+	if !slices.Equal(got, want) {
+		t.Errorf("want %q; got %q", want, got)
+	}
+
+	if got := slices.Collect(Generate("s", 0)); len(got) != 0 {
+		t.Errorf("want nothing for count 0; got %q", got)
+	}
+}
+
+// A Seq that keeps calling yield after it returned false panics, so
+// this also checks that Generate stops
+func TestGenerateBreak(t *testing.T) {
+	n := 0
+	for range Generate("s", 100) {
+		n++
+		if n == 2 {
+			break
+		}
+	}
+	if n != 2 {
+		t.Errorf("want 2 values before break; got %d", n)
+	}
+}
+
+func TestInterleave(t *testing.T) {
+	tests := []struct {
+		name string
+		seqs []iter.Seq[string]
+		want []string
+	}{
+		{"none", nil, nil},
+		{
+			"equal lengths",
+			[]iter.Seq[string]{slices.Values([]string{"a1", "a2"}), slices.Values([]string{"b1", "b2"})},
+			[]string{"a1", "b1", "a2", "b2"},
+		},
+		{
+			"one ends first",
+			[]iter.Seq[string]{
+				slices.Values([]string{"a1"}),
+				slices.Values([]string{"b1", "b2", "b3"}),
+				slices.Values([]string{"c1", "c2"}),
+			},
+			[]string{"a1", "b1", "c1", "b2", "c2", "b3"},
+		},
+		{
+			"an empty one",
+			[]iter.Seq[string]{slices.Values([]string{}), slices.Values([]string{"b1"})},
+			[]string{"b1"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := slices.Collect(Interleave(tt.seqs...))
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("want %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestInterleaveBreakStopsSources(t *testing.T) {
+	var closed []string
+	source := func(name string) iter.Seq[string] {
+		return func(yield func(string) bool) {
+			defer func() { closed = append(closed, name) }()
+			for {
+				if !yield(name) {
+					return
+				}
+			}
+		}
+	}
+
+	// Both sources have started after three values. A source that
+	// never started has nothing to clean up, and iter.Pull never runs it
+	n := 0
+	for range Interleave(source("a"), source("b")) {
+		if n++; n == 3 {
+			break
+		}
+	}
+
+	slices.Sort(closed)
+	if want := []string{"a", "b"}; !slices.Equal(closed, want) {
+		t.Errorf("want every source's defer run after break; ran for %q", closed)
+	}
+}
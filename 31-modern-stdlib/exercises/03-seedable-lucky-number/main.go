@@ -0,0 +1,124 @@
+// ---------------------------------------------------------
+// EXERCISE: Seedable Lucky Number
+//
+//  Below is the lucky number game from
+//  x-tba/foundations/03-if-switch-loop. It calls the top-level
+//  rand.IntN, so no two runs are the same, and nothing about it
+//  can be tested without luck. Make it repeatable.
+//
+//  1- Move the game loop into a function that takes the
+//     generator:
+//       func Play(r *rand.Rand, guess, turns int) (picks []int, won bool)
+//
+//  2- Move the messages into:
+//       func Message(r *rand.Rand, won bool) string
+//
+//  3- Add a -seed flag (use the flag package, and keep -v):
+//       lucky [-v] [-seed N] <number>
+//     With -seed, build the generator with rand.NewPCG(N, N).
+//     Without it, pick a random seed with rand.Uint64
+//
+//  4- Always print the seed at the end, so any game can be
+//     played again:
+//       (replay with -seed 42)
+//
+//  5- BONUS: Move everything main does into
+//       func run(args []string, w io.Writer) error
+//     and write tests that:
+//     - play the same seed twice and get the same game
+//     - check that the picks are in [0, guess], and that the
+//       game ends on the first pick that matches
+//     - check that run with the same args prints the same text
+//
+// HINTS
+//
+//  - flag.NewFlagSet("lucky", flag.ContinueOnError) returns an
+//    error instead of exiting, which run (and a test) needs
+//  - Only ONE call may use the top-level rand functions: the
+//    one that picks the seed
+//
+// EXPECTED OUTPUT
+//
+//  go run main.go -v -seed 42 10
+//    [6 4 7 5 10]
+//    🎉  YOU WIN!
+//    (replay with -seed 42)
+//
+//  Run it again: the output is the same.
+//
+// ---------------------------------------------------------
+
+package main
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"strconv"
+)
+
+const (
+	maxTurns = 5 // less is more difficult
+	usage    = `Welcome to the Lucky Number Game! 🍀
+
+The program will pick %d random numbers.
+Your mission is to guess one of those numbers.
+
+(Provide -v flag to see the picked numbers.)
+`
+)
+
+func main() {
+	args := os.Args[1:]
+	if len(args) < 1 {
+		fmt.Printf(usage, maxTurns)
+		return
+	}
+
+	var verbose bool
+	if args[0] == "-v" {
+		verbose = true
+	}
+
+	guess, err := strconv.Atoi(args[len(args)-1])
+	if err != nil {
+		fmt.Println("Not a number.")
+		return
+	}
+
+	if guess < 0 {
+		fmt.Println("Please pick a positive number.")
+		return
+	}
+
+	for turn := 0; turn < maxTurns; turn++ {
+		n := rand.IntN(guess + 1)
+
+		if verbose {
+			fmt.Printf("%d ", n)
+		}
+
+		if n == guess {
+			switch rand.IntN(3) {
+			case 0:
+				fmt.Println("🎉  YOU WIN!")
+			case 1:
+				fmt.Println("🎉  YOU'RE AWESOME!")
+			case 2:
+				fmt.Println("🎉  PERFECT!")
+			}
+			return
+		}
+	}
+
+	var msg string
+	switch n := rand.IntN(10); {
+	case n <= 5: // more probability
+		msg = "☠️  YOU LOST..."
+	case n <= 8:
+		msg = "☠️  JUST A BAD LUCK..."
+	default:
+		msg = "☠️  TRY NEXT TIME..."
+	}
+	fmt.Printf("%s Try again?\n", msg)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"os"
+	"strconv"
+)
+
+const maxTurns = 5 // less is more difficult
+
+// Play picks up to turns random numbers in [0, guess], and stops at the
+// first one that equals guess. It returns the picks, and whether the
+// player won
+func Play(r *rand.Rand, guess, turns int) (picks []int, won bool) {
+	for range turns {
+		n := r.IntN(guess + 1)
+		picks = append(picks, n)
+		if n == guess {
+			return picks, true
+		}
+	}
+	return picks, false
+}
+
+// Message returns a random message for the end of the game
+func Message(r *rand.Rand, won bool) string {
+	if won {
+		return [...]string{"🎉  YOU WIN!", "🎉  YOU'RE AWESOME!", "🎉  PERFECT!"}[r.IntN(3)]
+	}
+	switch n := r.IntN(10); {
+	case n <= 5: // more probability
+		return "☠️  YOU LOST... Try again?"
+	case n <= 8:
+		return "☠️  JUST A BAD LUCK... Try again?"
+	default:
+		return "☠️  TRY NEXT TIME... Try again?"
+	}
+}
+
+// run plays one game with the command-line args, and writes to w. The
+// same args, with the same -seed, always write the same output
+func run(args []string, w io.Writer) error {
+	fs := flag.NewFlagSet("lucky", flag.ContinueOnError)
+	fs.SetOutput(w)
+	verbose := fs.Bool("v", false, "print the picked numbers")
+	seed := fs.Uint64("seed", 0, "replay a game with this seed (0 picks a random one)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if fs.NArg() != 1 {
+		fmt.Fprintf(w, "Welcome to the Lucky Number Game! 🍀\n\n")
+		fmt.Fprintf(w, "The program will pick %d random numbers.\n", maxTurns)
+		fmt.Fprintf(w, "Your mission is to guess one of those numbers.\n\n")
+		fmt.Fprintf(w, "usage: lucky [-v] [-seed N] <number>\n")
+		return nil
+	}
+
+	guess, err := strconv.Atoi(fs.Arg(0))
+	if err != nil {
+		return fmt.Errorf("not a number: %q", fs.Arg(0))
+	}
+	if guess < 0 {
+		return fmt.Errorf("please pick a positive number")
+	}
+
+	if *seed == 0 {
+		*seed = rand.Uint64() // the only call that is different every run
+	}
+	r := rand.New(rand.NewPCG(*seed, *seed))
+
+	picks, won := Play(r, guess, maxTurns)
+	if *verbose {
+		fmt.Fprintln(w, picks)
+	}
+	fmt.Fprintln(w, Message(r, won))
+	fmt.Fprintf(w, "(replay with -seed %d)\n", *seed)
+	return nil
+}
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestPlaySameSeedSameGame(t *testing.T) {
+	for seed := range uint64(20) {
+		picks1, won1 := Play(rand.New(rand.NewPCG(seed, seed)), 7, maxTurns)
+		picks2, won2 := Play(rand.New(rand.NewPCG(seed, seed)), 7, maxTurns)
+
+		if !slices.Equal(picks1, picks2) || won1 != won2 {
+			t.Errorf("seed %d: want the same game twice; got %v %v and %v %v",
+				seed, picks1, won1, picks2, won2)
+		}
+	}
+}
+
+func TestPlayRules(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+
+	for range 200 {
+		guess := r.IntN(10)
+		picks, won := Play(r, guess, maxTurns)
+
+		if len(picks) == 0 || len(picks) > maxTurns {
+			t.Fatalf("want 1 to %d picks; got %v", maxTurns, picks)
+		}
+		for _, n := range picks {
+			if n < 0 || n > guess {
+				t.Fatalf("want picks in [0, %d]; got %v", guess, picks)
+			}
+		}
+		last := picks[len(picks)-1]
+		if won != (last == guess) {
+			t.Fatalf("guess %d, picks %v: won is %v", guess, picks, won)
+		}
+		if !won && len(picks) != maxTurns {
+			t.Fatalf("want every turn played on a loss; got %v", picks)
+		}
+	}
+}
+
+func TestPlayZeroAlwaysWins(t *testing.T) {
+	picks, won := Play(rand.New(rand.NewPCG(9, 9)), 0, maxTurns)
+	if !won || !slices.Equal(picks, []int{0}) {
+		t.Errorf("want a win on the first pick for guess 0; got %v %v", picks, won)
+	}
+}
+
+func TestRunReplays(t *testing.T) {
+	play := func() string {
+		var out strings.Builder
+		if err := run([]string{"-v", "-seed", "42", "10"}, &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	first, second := play(), play()
+	if first != second {
+		t.Errorf("want the same output with the same seed:\n%s\n%s", first, second)
+	}
+	if !strings.Contains(first, "-seed 42") {
+		t.Errorf("want the seed printed for a replay; got:\n%s", first)
+	}
+}
+
+func TestRunPrintsRandomSeed(t *testing.T) {
+	var out strings.Builder
+	if err := run([]string{"10"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), "(replay with -seed ") {
+		t.Errorf("want the chosen seed printed; got:\n%s", out.String())
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	for _, args := range [][]string{{"ten"}, {"-5"}, {"-seed", "x", "1"}} {
+		var out strings.Builder
+		if err := run(args, &out); err == nil {
+			t.Errorf("run(%q): want an error", args)
+		}
+	}
+}
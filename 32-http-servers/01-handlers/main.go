@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// hello is a plain function with the handler signature. It isn't an
+// http.Handler until http.HandlerFunc converts it
+func hello(w http.ResponseWriter, r *http.Request) {
+	name := r.URL.Query().Get("name")
+	if name == "" {
+		name = "world"
+	}
+	fmt.Fprintf(w, "hello, %s\n", name)
+}
+
+// Counter is a handler with state. Its ServeHTTP method makes it an
+// http.Handler. The server calls it from many goroutines at once, so
+// the count needs a lock
+type Counter struct {
+	mu    sync.Mutex
+	count int
+}
+
+func (c *Counter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	c.mu.Lock()
+	c.count++
+	n := c.count
+	c.mu.Unlock()
+
+	fmt.Fprintf(w, "visit #%d\n", n)
+}
+
+// Greeter shows the other way to give a handler dependencies: a method
+// value. g.greet is a func with the handler signature, bound to g
+type Greeter struct {
+	greeting string
+}
+
+func (g *Greeter) greet(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "%s!\n", g.greeting)
+}
+
+// created shows the order a response must be written in: headers,
+// then the status line, then the body
+func created(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Header().Set("Location", "/items/1")
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintln(w, "item 1 created")
+
+	// Too late: the headers and the status are already sent
+	w.Header().Set("X-Ignored", "yes")
+}
+
+// late writes the body first, which sends a 200 status. The WriteHeader
+// call after it does nothing except log "superfluous WriteHeader call"
+func late(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "oops")
+	w.WriteHeader(http.StatusTeapot)
+}
+
+// routes builds the server's handler
+func routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// HandleFunc converts a function; Handle takes any Handler
+	mux.HandleFunc("/hello", hello)
+	mux.Handle("/count", &Counter{})
+	mux.HandleFunc("/greet", (&Greeter{greeting: "good morning"}).greet)
+	mux.Handle("/adapted", http.HandlerFunc(hello)) // what HandleFunc does
+	mux.HandleFunc("/created", created)
+	mux.HandleFunc("/late", late)
+
+	// Handlers in the standard library
+	mux.Handle("/old", http.RedirectHandler("/hello", http.StatusMovedPermanently))
+	mux.Handle("/gone", http.NotFoundHandler())
+	mux.Handle("/slow", http.TimeoutHandler(http.HandlerFunc(slow), 50*time.Millisecond, "too slow\n"))
+	return mux
+}
+
+// slow takes longer than the TimeoutHandler in front of it allows
+func slow(w http.ResponseWriter, r *http.Request) {
+	select {
+	case <-time.After(time.Second):
+		fmt.Fprintln(w, "done")
+	case <-r.Context().Done(): // TimeoutHandler cancels the context
+	}
+}
+
+func main() {
+	fmt.Println("Handlers and HandlerFunc")
+	fmt.Println("========================")
+	fmt.Println()
+
+	base, stop, err := serve(routes())
+	if err != nil {
+		fmt.Println("serve:", err)
+		return
+	}
+	defer stop()
+
+	// Example 1: A function as a handler
+	fmt.Println("1. HandlerFunc:")
+	get(base, "/hello")
+	get(base, "/hello?name=gopher")
+	get(base, "/adapted?name=adapter")
+	fmt.Println()
+
+	// Example 2: A type as a handler
+	fmt.Println("2. A type with ServeHTTP keeps state:")
+	get(base, "/count")
+	get(base, "/count")
+	get(base, "/greet")
+	fmt.Println()
+
+	// Example 3: Writing a response in the right order
+	fmt.Println("3. Header, WriteHeader, then Write:")
+	get(base, "/created")
+	get(base, "/late")
+	fmt.Println()
+
+	// Example 4: Ready-made handlers
+	fmt.Println("4. Handlers from net/http:")
+	get(base, "/old") // the client follows the redirect
+	get(base, "/gone")
+	get(base, "/slow")
+}
+
+// serve starts h on a random free port, and returns its base URL and a
+// function that stops it
+func serve(h http.Handler) (string, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+
+	// The server logs the "superfluous WriteHeader" warning to
+	// ErrorLog; discarding it keeps the output readable
+	srv := &http.Server{Handler: h, ErrorLog: log.New(io.Discard, "", 0)}
+	go srv.Serve(ln)
+
+	return "http://" + ln.Addr().String(), func() { srv.Shutdown(context.Background()) }, nil
+}
+
+// get prints the status, the headers the example cares about, and the
+// body of a GET request
+func get(base, path string) {
+	resp, err := http.Get(base + path)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+
+	fmt.Printf("   GET %-22s %s", path, resp.Status)
+	for _, h := range []string{"Location", "X-Ignored"} {
+		if v := resp.Header.Get(h); v != "" {
+			fmt.Printf(" [%s: %s]", h, v)
+		}
+	}
+	fmt.Printf(" %q\n", strings.TrimSpace(string(body)))
+}
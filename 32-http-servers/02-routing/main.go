@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+)
+
+// routes registers every pattern the lesson discusses. Each handler
+// writes which pattern matched (r.Pattern), and the path values
+func routes() *http.ServeMux {
+	mux := http.NewServeMux()
+
+	// {$} matches the end of the path: only "/", not everything
+	mux.HandleFunc("GET /{$}", show)
+
+	// The same path with different methods
+	mux.HandleFunc("GET /api/users", show)
+	mux.HandleFunc("POST /api/users", show)
+
+	// A wildcard matches one whole path segment
+	mux.HandleFunc("GET /api/users/{id}", showUser)
+	mux.HandleFunc("DELETE /api/users/{id}", showUser)
+
+	// More specific than /api/users/{id}, so it wins for "me"
+	mux.HandleFunc("GET /api/users/me", show)
+
+	// Two wildcards
+	mux.HandleFunc("GET /api/users/{id}/posts/{post}", show)
+
+	// {name...} matches the rest of the path, slashes included
+	mux.HandleFunc("GET /files/{path...}", show)
+
+	// A trailing slash makes a subtree: /docs/ and everything under it,
+	// for any method
+	mux.HandleFunc("/docs/", show)
+
+	// A host in the pattern: only requests for that host match
+	mux.HandleFunc("GET api.example.com/status", show)
+
+	return mux
+}
+
+// show writes the pattern that matched, and the path values
+func show(w http.ResponseWriter, r *http.Request) {
+	var values []string
+	for _, name := range []string{"id", "post", "path"} {
+		if v := r.PathValue(name); v != "" {
+			values = append(values, name+"="+v)
+		}
+	}
+	fmt.Fprintf(w, "%q %s", r.Pattern, strings.Join(values, " "))
+}
+
+// showUser checks that {id} is a number before using it. The pattern
+// only promises a non-empty segment
+func showUser(w http.ResponseWriter, r *http.Request) {
+	if _, err := strconv.Atoi(r.PathValue("id")); err != nil {
+		http.Error(w, "id must be a number", http.StatusBadRequest)
+		return
+	}
+	show(w, r)
+}
+
+func main() {
+	fmt.Println("Routing with the Go 1.22 ServeMux")
+	fmt.Println("=================================")
+	fmt.Println()
+
+	mux := routes()
+
+	// Example 1: Methods and wildcards
+	fmt.Println("1. Methods and wildcards:")
+	try(mux, "GET", "/")
+	try(mux, "GET", "/api/users")
+	try(mux, "POST", "/api/users")
+	try(mux, "GET", "/api/users/42")
+	try(mux, "DELETE", "/api/users/42")
+	try(mux, "GET", "/api/users/42/posts/7")
+	try(mux, "GET", "/files/img/2026/cat.png")
+	fmt.Println()
+
+	// Example 2: The most specific pattern wins
+	fmt.Println("2. Precedence:")
+	try(mux, "GET", "/api/users/me")
+	try(mux, "GET", "/api/users/abc") // matches {id}; the handler rejects it
+	try(mux, "GET", "/docs/intro")
+	try(mux, "GET", "http://api.example.com/status")
+	fmt.Println()
+
+	// Example 3: What happens when nothing matches
+	fmt.Println("3. 404, 405, and redirects:")
+	try(mux, "GET", "/nope")
+	try(mux, "GET", "/api/users/42/posts") // no pattern for this path
+	try(mux, "PUT", "/api/users")          // the path matches, the method doesn't
+	try(mux, "HEAD", "/api/users/42")      // GET patterns also match HEAD
+	try(mux, "GET", "/docs")               // redirected to the subtree
+	try(mux, "GET", "/api/users/../users") // cleaned, then redirected
+	fmt.Println()
+
+	// Example 4: Patterns that conflict
+	fmt.Println("4. Conflicting patterns panic when registered:")
+	register(mux, "GET /api/{resource}/me")
+	register(mux, "GET /api/users/{name}")
+	register(mux, "GET /api/{resource}/{id}/posts")
+}
+
+// try sends a request straight to the mux, and prints what came back
+func try(mux *http.ServeMux, method, target string) {
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+
+	fmt.Printf("%-6s %-30s %d %s", method, target, w.Code, strings.TrimSpace(w.Body.String()))
+	if allow := w.Header().Get("Allow"); allow != "" {
+		fmt.Printf(" [Allow: %s]", allow)
+	}
+	if loc := w.Header().Get("Location"); loc != "" {
+		fmt.Printf(" [Location: %s]", loc)
+	}
+	fmt.Println()
+}
+
+// register adds a pattern, and prints why it panicked if it conflicts
+// with one that is already registered
+func register(mux *http.ServeMux, pattern string) {
+	defer func() {
+		if r := recover(); r != nil {
+			// The first line says where both patterns were registered;
+			// the rest explains the conflict
+			_, why, _ := strings.Cut(fmt.Sprint(r), "\n")
+			fmt.Printf("%s\n  panic: %s\n", pattern, strings.ReplaceAll(why, "\n", "\n  "))
+		}
+	}()
+	mux.HandleFunc(pattern, show)
+	fmt.Printf("%s\n  ok\n", pattern)
+}
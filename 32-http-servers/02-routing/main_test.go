@@ -0,0 +1,99 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRoutes(t *testing.T) {
+	mux := routes()
+
+	tests := []struct {
+		method, target string
+		wantCode       int
+		wantBody       string // a prefix; the pattern comes first
+		wantAllow      string
+	}{
+		{"GET", "/", 200, `"GET /{$}"`, ""},
+		{"GET", "/api/users", 200, `"GET /api/users"`, ""},
+		{"POST", "/api/users", 200, `"POST /api/users"`, ""},
+		{"GET", "/api/users/42", 200, `"GET /api/users/{id}" id=42`, ""},
+		{"DELETE", "/api/users/42", 200, `"DELETE /api/users/{id}" id=42`, ""},
+		{"GET", "/api/users/me", 200, `"GET /api/users/me"`, ""},
+		{"GET", "/api/users/42/posts/7", 200, `"GET /api/users/{id}/posts/{post}" id=42 post=7`, ""},
+		{"GET", "/files/a/b/c.txt", 200, `"GET /files/{path...}" path=a/b/c.txt`, ""},
+		{"GET", "/files/", 200, `"GET /files/{path...}"`, ""}, // {path...} can be empty
+		{"GET", "/docs/intro", 200, `"/docs/"`, ""},
+		{"POST", "/docs/intro", 200, `"/docs/"`, ""}, // no method: any method
+		{"GET", "http://api.example.com/status", 200, `"GET api.example.com/status"`, ""},
+
+		// Wildcards decode escapes: %20 is a space in the value
+		{"GET", "/files/my%20cat.png", 200, `"GET /files/{path...}" path=my cat.png`, ""},
+
+		{"GET", "/api/users/abc", 400, "id must be a number", ""},
+		{"HEAD", "/api/users/42", 200, "", ""},
+
+		{"GET", "/nope", 404, "404 page not found", ""},
+		{"GET", "/index.html", 404, "", ""}, // {$}: only "/" itself
+		{"GET", "/status", 404, "", ""},     // the host doesn't match
+		{"GET", "/api/users/42/posts", 404, "", ""},
+		{"PUT", "/api/users", 405, "Method Not Allowed", "GET, HEAD, POST"},
+		{"POST", "/api/users/42", 405, "", "DELETE, GET, HEAD"},
+
+		{"GET", "/docs", 307, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.target, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d; got %d (%q)", tt.wantCode, w.Code, w.Body)
+			}
+			if body := w.Body.String(); !strings.HasPrefix(body, tt.wantBody) {
+				t.Errorf("want body starting %q; got %q", tt.wantBody, body)
+			}
+			if allow := w.Header().Get("Allow"); allow != tt.wantAllow {
+				t.Errorf("want Allow %q; got %q", tt.wantAllow, allow)
+			}
+		})
+	}
+}
+
+func TestConflictingPatternsPanic(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		wantPanic bool
+	}{
+		{"GET /api/{resource}/me", true},     // overlaps {id}; neither is more specific
+		{"GET /api/users/{name}", true},      // the same as {id}, with another name
+		{"GET /api/users/{id}/posts", false}, // a longer path: no overlap
+		{"POST /api/users/me", false},        // a different method
+	}
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tt.wantPanic {
+					t.Errorf("want panic %v; got %v", tt.wantPanic, r)
+				}
+			}()
+			routes().HandleFunc(tt.pattern, show)
+		})
+	}
+}
+
+func TestSetPathValue(t *testing.T) {
+	// A handler can be tested without the mux: set the path values
+	// the mux would have set
+	req := httptest.NewRequest(http.MethodGet, "/anything", nil)
+	req.SetPathValue("id", "7")
+
+	w := httptest.NewRecorder()
+	showUser(w, req)
+
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), "id=7") {
+		t.Errorf("want 200 with id=7; got %d %q", w.Code, w.Body)
+	}
+}
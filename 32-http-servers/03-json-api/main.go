@@ -0,0 +1,286 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// User is what the API stores, and what it sends and receives as JSON
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// validate reports the first problem with a user sent by a client
+func (u User) validate() error {
+	switch {
+	case strings.TrimSpace(u.Name) == "":
+		return errors.New("name is required")
+	case !strings.Contains(u.Email, "@"):
+		return errors.New("email must contain @")
+	}
+	return nil
+}
+
+// Store keeps users in memory. Handlers run on many goroutines at once,
+// so every method takes the lock
+type Store struct {
+	mu     sync.Mutex
+	users  map[int]User
+	nextID int
+}
+
+func NewStore() *Store {
+	return &Store{users: make(map[int]User), nextID: 1}
+}
+
+// List returns every user, ordered by ID
+func (s *Store) List() []User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	slices.SortFunc(users, func(a, b User) int { return a.ID - b.ID })
+	return users
+}
+
+func (s *Store) Get(id int) (User, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	return u, ok
+}
+
+// Create stores u under a new ID, and returns it with the ID set
+func (s *Store) Create(u User) User {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u.ID = s.nextID
+	s.nextID++
+	s.users[u.ID] = u
+	return u
+}
+
+// Delete reports whether there was a user to delete
+func (s *Store) Delete(id int) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, ok := s.users[id]
+	delete(s.users, id)
+	return ok
+}
+
+// API holds the handlers' dependencies: here, only the store
+type API struct {
+	store *Store
+}
+
+// Handler returns the API's routes
+func (a *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/users", a.listUsers)
+	mux.HandleFunc("POST /api/users", a.createUser)
+	mux.HandleFunc("GET /api/users/{id}", a.getUser)
+	mux.HandleFunc("DELETE /api/users/{id}", a.deleteUser)
+	return mux
+}
+
+func (a *API) listUsers(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, a.store.List())
+}
+
+func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
+	var u User
+	if err := readJSON(w, r, &u); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := u.validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	u = a.store.Create(u)
+	w.Header().Set("Location", fmt.Sprintf("/api/users/%d", u.ID))
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (a *API) getUser(w http.ResponseWriter, r *http.Request) {
+	id, err := userID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	u, ok := a.store.Get(id)
+	if !ok {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (a *API) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := userID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !a.store.Delete(id) {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// userID reads the {id} path value. The pattern matches any segment,
+// so it may not be a number
+func userID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		return 0, errors.New("id must be a positive number")
+	}
+	return id, nil
+}
+
+// maxBodyBytes limits how much of a request body readJSON reads
+const maxBodyBytes = 1 << 20
+
+// readJSON decodes the request body into dst. It rejects bodies larger
+// than maxBodyBytes, fields dst doesn't have, and anything after the
+// first JSON value
+func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("body is empty")
+		case errors.As(err, &tooLarge):
+			return fmt.Errorf("body is larger than %d bytes", tooLarge.Limit)
+		}
+		return fmt.Errorf("bad JSON: %w", err)
+	}
+	if dec.More() {
+		return errors.New("body must hold a single JSON value")
+	}
+	return nil
+}
+
+// writeJSON sends v as JSON with the given status. The headers must be
+// set before WriteHeader, so the order here matters
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends errors in one shape, so clients can parse them
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func main() {
+	fmt.Println("A Small JSON API")
+	fmt.Println("================")
+	fmt.Println()
+
+	api := &API{store: NewStore()}
+	base, stop, err := serve(api.Handler())
+	if err != nil {
+		fmt.Println("serve:", err)
+		return
+	}
+	defer stop()
+
+	// Example 1: Creating users
+	fmt.Println("1. POST creates, and answers 201 with a Location:")
+	send(base, "POST", "/api/users", `{"name": "Ada", "email": "ada@example.com"}`)
+	send(base, "POST", "/api/users", `{"name": "Linus", "email": "linus@example.com"}`)
+	fmt.Println()
+
+	// Example 2: Reading them back
+	fmt.Println("2. GET lists and fetches:")
+	send(base, "GET", "/api/users", "")
+	send(base, "GET", "/api/users/2", "")
+	fmt.Println()
+
+	// Example 3: Deleting
+	fmt.Println("3. DELETE answers 204, with no body:")
+	send(base, "DELETE", "/api/users/1", "")
+	send(base, "GET", "/api/users/1", "")
+	fmt.Println()
+
+	// Example 4: Bad requests
+	fmt.Println("4. Errors are JSON too:")
+	send(base, "POST", "/api/users", `{"name": "Ada"`)
+	send(base, "POST", "/api/users", `{"name": "Ada", "email": "ada@example.com", "admin": true}`)
+	send(base, "POST", "/api/users", `{"name": "", "email": "nobody"}`)
+	send(base, "GET", "/api/users/abc", "")
+	send(base, "PATCH", "/api/users/2", "{}") // the mux answers this one
+}
+
+// serve starts h on a random free port, and returns its base URL and a
+// function that stops it
+func serve(h http.Handler) (string, func(), error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+
+	srv := &http.Server{Handler: h}
+	go srv.Serve(ln)
+
+	return "http://" + ln.Addr().String(), func() { srv.Shutdown(context.Background()) }, nil
+}
+
+// send makes a request with an optional JSON body, and prints the
+// status, the Location header, and the body
+func send(base, method, path, body string) {
+	req, err := http.NewRequest(method, base+path, strings.NewReader(body))
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	respBody, _ := io.ReadAll(resp.Body)
+
+	fmt.Printf("   %-6s %-14s %s", method, path, resp.Status)
+	if loc := resp.Header.Get("Location"); loc != "" {
+		fmt.Printf(" [Location: %s]", loc)
+	}
+	if b := strings.TrimSpace(string(respBody)); b != "" {
+		fmt.Printf(" %s", b)
+	}
+	fmt.Println()
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// newAPI returns an API with two users in its store: 1 (Ada) and 2 (Linus)
+func newAPI(t *testing.T) http.Handler {
+	t.Helper()
+	store := NewStore()
+	store.Create(User{Name: "Ada", Email: "ada@example.com"})
+	store.Create(User{Name: "Linus", Email: "linus@example.com"})
+	return (&API{store: store}).Handler()
+}
+
+// do sends a request straight to h, and returns the recorded response
+func do(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+func TestAPI(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+		wantBody string // compared after trimming the trailing newline
+	}{
+		{"list", "GET", "/api/users", "", 200,
+			`[{"id":1,"name":"Ada","email":"ada@example.com"},{"id":2,"name":"Linus","email":"linus@example.com"}]`},
+		{"get", "GET", "/api/users/2", "", 200, `{"id":2,"name":"Linus","email":"linus@example.com"}`},
+		{"get missing", "GET", "/api/users/9", "", 404, `{"error":"user not found"}`},
+		{"get bad id", "GET", "/api/users/abc", "", 400, `{"error":"id must be a positive number"}`},
+		{"get zero id", "GET", "/api/users/0", "", 400, `{"error":"id must be a positive number"}`},
+
+		{"create", "POST", "/api/users", `{"name":"Grace","email":"grace@example.com"}`, 201,
+			`{"id":3,"name":"Grace","email":"grace@example.com"}`},
+		{"create ignores id", "POST", "/api/users", `{"id":1,"name":"Grace","email":"grace@example.com"}`, 201,
+			`{"id":3,"name":"Grace","email":"grace@example.com"}`},
+		{"create empty body", "POST", "/api/users", "", 400, `{"error":"body is empty"}`},
+		{"create bad JSON", "POST", "/api/users", `{"name":`, 400, `{"error":"bad JSON: unexpected EOF"}`},
+		{"create wrong type", "POST", "/api/users", `{"name":42}`, 400, ""},
+		{"create unknown field", "POST", "/api/users", `{"name":"Grace","admin":true}`, 400,
+			`{"error":"bad JSON: json: unknown field \"admin\""}`},
+		{"create two values", "POST", "/api/users", `{"name":"A","email":"a@b"}{}`, 400,
+			`{"error":"body must hold a single JSON value"}`},
+		{"create no name", "POST", "/api/users", `{"name":" ","email":"grace@example.com"}`, 422,
+			`{"error":"name is required"}`},
+		{"create bad email", "POST", "/api/users", `{"name":"Grace","email":"grace"}`, 422,
+			`{"error":"email must contain @"}`},
+
+		{"delete", "DELETE", "/api/users/1", "", 204, ""},
+		{"delete missing", "DELETE", "/api/users/9", "", 404, `{"error":"user not found"}`},
+
+		{"wrong method", "PUT", "/api/users/1", "", 405, "Method Not Allowed"},
+		{"unknown path", "GET", "/api/posts", "", 404, "404 page not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(newAPI(t), tt.method, tt.target, tt.body)
+
+			if w.Code != tt.wantCode {
+				t.Errorf("want status %d; got %d (%q)", tt.wantCode, w.Code, w.Body)
+			}
+			if tt.wantBody == "" {
+				return
+			}
+			if body := strings.TrimSpace(w.Body.String()); body != tt.wantBody {
+				t.Errorf("want body %s; got %s", tt.wantBody, body)
+			}
+		})
+	}
+}
+
+func TestCreateSetsHeaders(t *testing.T) {
+	w := do(newAPI(t), "POST", "/api/users", `{"name":"Grace","email":"grace@example.com"}`)
+
+	if loc := w.Header().Get("Location"); loc != "/api/users/3" {
+		t.Errorf("want Location /api/users/3; got %q", loc)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("want Content-Type application/json; got %q", ct)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	big := `{"name":"` + strings.Repeat("a", maxBodyBytes) + `","email":"a@b"}`
+	w := do(newAPI(t), "POST", "/api/users", big)
+
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "larger than") {
+		t.Errorf("want 400 for a body over the limit; got %d %q", w.Code, w.Body)
+	}
+}
+
+func TestCreateThenGet(t *testing.T) {
+	api := newAPI(t)
+
+	w := do(api, "POST", "/api/users", `{"name":"Grace","email":"grace@example.com"}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create: want 201; got %d", w.Code)
+	}
+
+	// Follow the Location header, the way a client would
+	w = do(api, "GET", w.Header().Get("Location"), "")
+
+	var u User
+	if err := json.NewDecoder(w.Body).Decode(&u); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if want := (User{ID: 3, Name: "Grace", Email: "grace@example.com"}); u != want {
+		t.Errorf("want %+v; got %+v", want, u)
+	}
+
+	if w = do(api, "DELETE", "/api/users/3", ""); w.Code != http.StatusNoContent {
+		t.Fatalf("delete: want 204; got %d", w.Code)
+	}
+	if w = do(api, "GET", "/api/users/3", ""); w.Code != http.StatusNotFound {
+		t.Errorf("get after delete: want 404; got %d", w.Code)
+	}
+}
+
+func TestConcurrentCreates(t *testing.T) {
+	// Run with -race: the handlers share the store
+	api := newAPI(t)
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Go(func() {
+			do(api, "POST", "/api/users", `{"name":"Gopher","email":"gopher@example.com"}`)
+		})
+	}
+	wg.Wait()
+
+	var users []User
+	json.NewDecoder(do(api, "GET", "/api/users", "").Body).Decode(&users)
+
+	if len(users) != 52 {
+		t.Fatalf("want 52 users; got %d", len(users))
+	}
+	for i, u := range users {
+		if u.ID != i+1 {
+			t.Fatalf("want IDs 1 to 52 in order; got %d at %d", u.ID, i)
+		}
+	}
+}
@@ -0,0 +1,309 @@
+package main
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/ctxmeta"
+)
+
+// Middleware wraps a handler with extra behavior, and returns the
+// result as another handler
+type Middleware func(http.Handler) http.Handler
+
+// Chain combines mws into one middleware. The first one is the
+// outermost: it sees the request first, and the response last
+func Chain(mws ...Middleware) Middleware {
+	return func(h http.Handler) http.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			h = mws[i](h)
+		}
+		return h
+	}
+}
+
+// Logging logs every request after it is served, with the status and
+// the size of the response
+func Logging(log *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			rw := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+			next.ServeHTTP(rw, r)
+
+			log.Info("served",
+				"method", r.Method,
+				"path", r.URL.Path,
+				"status", rw.status,
+				"bytes", rw.bytes,
+				"took", time.Since(start).Round(time.Microsecond))
+		})
+	}
+}
+
+// responseRecorder remembers the status and counts the bytes a handler
+// wrote, and passes everything on
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	w.bytes += n
+	return n, err
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath, for
+// Flush and the other methods the wrapper hides
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Recover turns a panic in a handler into a 500, and logs it. Without
+// it, net/http recovers too, but closes the connection and sends the
+// client nothing
+func Recover(log *slog.Logger) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				v := recover()
+				if v == nil {
+					return
+				}
+				// ErrAbortHandler means "stop quietly": let net/http have it
+				if v == http.ErrAbortHandler {
+					panic(v)
+				}
+				log.Error("panic", "method", r.Method, "path", r.URL.Path, "value", v)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Gzip compresses responses for clients that accept gzip
+func Gzip(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Caches must store the two versions separately
+		w.Header().Add("Vary", "Accept-Encoding")
+
+		if !strings.Contains(r.Header.Get("Accept-Encoding"), "gzip") {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		gw := &gzipWriter{ResponseWriter: w}
+		defer gw.close()
+		next.ServeHTTP(gw, r)
+	})
+}
+
+// gzipWriter sends the body through a gzip.Writer. It starts one only
+// when the response has a body: a handler that panics before writing
+// anything must leave the response untouched for Recover
+type gzipWriter struct {
+	http.ResponseWriter
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		w.ResponseWriter.WriteHeader(status) // let net/http log it
+		return
+	}
+	w.wroteHeader = true
+
+	// 1xx, 204, and 304 responses have no body to compress
+	if status >= 200 && status != http.StatusNoContent && status != http.StatusNotModified {
+		// A length the handler set is the uncompressed one
+		w.Header().Del("Content-Length")
+		w.Header().Set("Content-Encoding", "gzip")
+		w.gz = gzip.NewWriter(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		// net/http would sniff the type from compressed bytes, so
+		// sniff it here, from the plain ones
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.gz.Write(p)
+}
+
+// close writes the gzip footer, if there is a gzip stream
+func (w *gzipWriter) close() {
+	if w.gz != nil {
+		w.gz.Close()
+	}
+}
+
+// userKey holds the authenticated user in a request's context
+var userKey = ctxmeta.NewKey[string]("user")
+
+// Auth lets through only requests with a known bearer token, and
+// stores the token's user in the request's context
+func Auth(tokens map[string]string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			user, known := tokens[token]
+			if !ok || !known {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+				return
+			}
+
+			ctx := ctxmeta.WithValue(r.Context(), userKey, user)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// trace prints when a request enters and leaves it, to show the order
+// a chain runs in
+func trace(name string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			fmt.Printf("   -> %s\n", name)
+			next.ServeHTTP(w, r)
+			fmt.Printf("   <- %s\n", name)
+		})
+	}
+}
+
+// routes returns the application's handlers, with no middleware
+func routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /me", func(w http.ResponseWriter, r *http.Request) {
+		user, _ := ctxmeta.Value(r.Context(), userKey)
+		fmt.Fprintf(w, "hello, %s\n", user)
+	})
+	mux.HandleFunc("GET /report", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, strings.Repeat("all systems normal\n", 50))
+	})
+	mux.HandleFunc("GET /panic", func(w http.ResponseWriter, r *http.Request) {
+		var m map[string]int
+		m["boom"]++ // assignment to entry in nil map
+	})
+	return mux
+}
+
+func main() {
+	fmt.Println("Middleware")
+	fmt.Println("==========")
+	fmt.Println()
+
+	log := newLogger()
+	tokens := map[string]string{"s3cret": "gopher"}
+
+	// Example 1: The order a chain runs in
+	fmt.Println("1. Chain(a, b, c): a runs first, and finishes last:")
+	h := Chain(trace("a"), trace("b"), trace("c"))(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) { fmt.Println("      handler") },
+	))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	fmt.Println()
+
+	// Logging is outermost, so it logs the 401s from Auth and the 500s
+	// from Recover. Recover wraps Gzip, so a panic still gets a 500
+	app := Chain(Logging(log), Recover(log), Gzip, Auth(tokens))(routes())
+
+	// Example 2: Auth
+	fmt.Println("2. Auth:")
+	try(app, "/me", "", "")
+	try(app, "/me", "Bearer wrong", "")
+	try(app, "/me", "Bearer s3cret", "")
+	fmt.Println()
+
+	// Example 3: Gzip
+	fmt.Println("3. Gzip:")
+	try(app, "/report", "Bearer s3cret", "")
+	try(app, "/report", "Bearer s3cret", "gzip")
+	fmt.Println()
+
+	// Example 4: Recover
+	fmt.Println("4. Recover:")
+	try(app, "/panic", "Bearer s3cret", "")
+	try(app, "/panic", "Bearer s3cret", "gzip") // Gzip hadn't started yet
+}
+
+// try sends a request straight to h, and prints the response. The
+// logging middleware prints its line first
+func try(h http.Handler, path, auth, encoding string) {
+	req := httptest.NewRequest("GET", path, nil)
+	if auth != "" {
+		req.Header.Set("Authorization", auth)
+	}
+	if encoding != "" {
+		req.Header.Set("Accept-Encoding", encoding)
+	}
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	body := w.Body.String()
+	if w.Header().Get("Content-Encoding") == "gzip" {
+		gz, err := gzip.NewReader(strings.NewReader(body))
+		if err != nil {
+			fmt.Println("   gzip:", err)
+			return
+		}
+		plain, _ := io.ReadAll(gz)
+		body = fmt.Sprintf("%d bytes, gzipped from %d", len(body), len(plain))
+	} else if len(body) > 40 {
+		body = fmt.Sprintf("%d bytes", len(body))
+	}
+
+	fmt.Printf("   GET %-8s %-14q %d %q\n", path, auth, w.Code, strings.TrimSpace(body))
+}
+
+// newLogger returns a logger that writes lines three spaces in, with no
+// time, like the rest of the output
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(indent{os.Stdout}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// indent writes every line three spaces in. A slog handler writes a
+// whole line per Write call
+type indent struct {
+	w io.Writer
+}
+
+func (i indent) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(i.w, "   "); err != nil {
+		return 0, err
+	}
+	return i.w.Write(p)
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/ctxmeta"
+)
+
+// Each middleware is tested alone, around a small handler written for
+// the test: no mux, no server, no other middleware
+
+func ok(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprint(w, "ok")
+}
+
+func TestChainOrder(t *testing.T) {
+	var calls []string
+	record := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls = append(calls, "enter "+name)
+				next.ServeHTTP(w, r)
+				calls = append(calls, "leave "+name)
+			})
+		}
+	}
+
+	h := Chain(record("a"), record("b"))(http.HandlerFunc(ok))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+	want := "enter a, enter b, leave b, leave a"
+	if got := strings.Join(calls, ", "); got != want {
+		t.Errorf("want %q; got %q", want, got)
+	}
+}
+
+func TestChainEmpty(t *testing.T) {
+	w := httptest.NewRecorder()
+	Chain()(http.HandlerFunc(ok)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Body.String() != "ok" {
+		t.Errorf("an empty chain must call the handler; got %q", w.Body)
+	}
+}
+
+func TestLogging(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		want    string
+	}{
+		{"implicit 200", ok, "status=200 bytes=2"},
+		{"explicit status", func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "nope", http.StatusForbidden)
+		}, "status=403 bytes=5"},
+		{"no body", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}, "status=204 bytes=0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log := slog.New(slog.NewTextHandler(&logs, nil))
+
+			h := Logging(log)(tt.handler)
+			h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/items", nil))
+
+			line := logs.String()
+			for _, want := range []string{"method=POST", "path=/items", tt.want} {
+				if !strings.Contains(line, want) {
+					t.Errorf("want %q in the log; got %q", want, line)
+				}
+			}
+		})
+	}
+}
+
+func TestLoggingKeepsFlusher(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := Logging(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Errorf("flush through the wrapper: %v", err)
+		}
+	}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if !w.Flushed {
+		t.Error("want the recorder flushed")
+	}
+}
+
+func TestRecover(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+
+	h := Recover(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("want 500; got %d", w.Code)
+	}
+	if !strings.Contains(logs.String(), "value=boom") {
+		t.Errorf("want the panic logged; got %q", logs.String())
+	}
+}
+
+func TestRecoverPassesOn(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	w := httptest.NewRecorder()
+	Recover(log)(http.HandlerFunc(ok)).ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "ok" {
+		t.Errorf("want 200 ok; got %d %q", w.Code, w.Body)
+	}
+}
+
+func TestRecoverRepanicsAbort(t *testing.T) {
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	h := Recover(log)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	defer func() {
+		if v := recover(); v != http.ErrAbortHandler {
+			t.Errorf("want ErrAbortHandler to reach net/http; got %v", v)
+		}
+	}()
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+}
+
+func TestGzip(t *testing.T) {
+	body := strings.Repeat("compress me ", 100)
+	handler := Gzip(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, body)
+	}))
+
+	tests := []struct {
+		acceptEncoding string
+		wantGzip       bool
+	}{
+		{"", false},
+		{"br", false},
+		{"gzip", true},
+		{"gzip, deflate, br", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptEncoding, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", tt.acceptEncoding)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if vary := w.Header().Get("Vary"); vary != "Accept-Encoding" {
+				t.Errorf("want Vary: Accept-Encoding; got %q", vary)
+			}
+			gotGzip := w.Header().Get("Content-Encoding") == "gzip"
+			if gotGzip != tt.wantGzip {
+				t.Fatalf("want gzip %v; got %v", tt.wantGzip, gotGzip)
+			}
+
+			got := w.Body.String()
+			if gotGzip {
+				if w.Body.Len() >= len(body) {
+					t.Errorf("want fewer than %d bytes; got %d", len(body), w.Body.Len())
+				}
+				if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+					t.Errorf("want the type sniffed from the plain body; got %q", ct)
+				}
+				gz, err := gzip.NewReader(w.Body)
+				if err != nil {
+					t.Fatal(err)
+				}
+				plain, err := io.ReadAll(gz)
+				if err != nil {
+					t.Fatal(err)
+				}
+				got = string(plain)
+			}
+			if got != body {
+				t.Errorf("want the body back unchanged; got %d bytes", len(got))
+			}
+		})
+	}
+}
+
+func TestGzipSkipsEmptyResponses(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+	}{
+		{"no content", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}},
+		{"panic", func(w http.ResponseWriter, r *http.Request) {
+			panic("boom")
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.Header.Set("Accept-Encoding", "gzip")
+			w := httptest.NewRecorder()
+
+			func() {
+				defer func() { recover() }()
+				Gzip(tt.handler).ServeHTTP(w, req)
+			}()
+
+			if ce := w.Header().Get("Content-Encoding"); ce != "" {
+				t.Errorf("want no Content-Encoding; got %q", ce)
+			}
+			if w.Body.Len() != 0 {
+				t.Errorf("want no body; got %d bytes", w.Body.Len())
+			}
+		})
+	}
+}
+
+func TestAuth(t *testing.T) {
+	handler := Auth(map[string]string{"t0ken": "ada"})(http.HandlerFunc(
+		func(w http.ResponseWriter, r *http.Request) {
+			user, _ := ctxmeta.Value(r.Context(), userKey)
+			fmt.Fprint(w, user)
+		},
+	))
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantCode      int
+		wantBody      string
+	}{
+		{"no header", "", 401, "Unauthorized\n"},
+		{"wrong scheme", "Basic t0ken", 401, "Unauthorized\n"},
+		{"unknown token", "Bearer nope", 401, "Unauthorized\n"},
+		{"empty token", "Bearer ", 401, "Unauthorized\n"},
+		{"valid", "Bearer t0ken", 200, "ada"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantCode || w.Body.String() != tt.wantBody {
+				t.Errorf("want %d %q; got %d %q", tt.wantCode, tt.wantBody, w.Code, w.Body)
+			}
+			if w.Code == 401 && w.Header().Get("WWW-Authenticate") != "Bearer" {
+				t.Error("want a WWW-Authenticate header with a 401")
+			}
+		})
+	}
+}
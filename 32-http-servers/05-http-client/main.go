@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+// server is the other side of every example. It counts the TCP
+// connections clients open, and the requests they send
+type server struct {
+	URL   string
+	conns atomic.Int64
+	calls atomic.Int64
+	srv   *http.Server
+}
+
+func newServer() (*server, error) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+
+	s := &server{URL: "http://" + ln.Addr().String()}
+	s.srv = &http.Server{
+		Handler: s.routes(),
+		ConnState: func(_ net.Conn, state http.ConnState) {
+			if state == http.StateNew {
+				s.conns.Add(1)
+			}
+		},
+	}
+	go s.srv.Serve(ln)
+	return s, nil
+}
+
+func (s *server) Close() { s.srv.Close() }
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Waits before sending the headers
+	mux.HandleFunc("GET /slow-headers", func(w http.ResponseWriter, r *http.Request) {
+		if sleep(r.Context(), 300*time.Millisecond) {
+			fmt.Fprintln(w, "finally")
+		}
+	})
+
+	// Sends the headers at once, then the body slowly
+	mux.HandleFunc("GET /slow-body", func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		for i := range 3 {
+			fmt.Fprintf(w, "part %d\n", i+1)
+			rc.Flush()
+			if !sleep(r.Context(), 100*time.Millisecond) {
+				return
+			}
+		}
+	})
+
+	// Takes a moment, so that concurrent requests overlap
+	mux.HandleFunc("GET /work", func(w http.ResponseWriter, r *http.Request) {
+		sleep(r.Context(), 20*time.Millisecond)
+		fmt.Fprintln(w, "done")
+	})
+
+	// A body big enough that closing it unread must drop the connection
+	mux.HandleFunc("GET /big", func(w http.ResponseWriter, r *http.Request) {
+		w.Write(make([]byte, 1<<20))
+	})
+
+	// Says how many bytes of body it received
+	mux.HandleFunc("/echo", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		fmt.Fprintf(w, "got %d bytes", n)
+	})
+
+	// Fails the first two calls of every three with a 503
+	mux.HandleFunc("/flaky", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := io.Copy(io.Discard, r.Body)
+		if s.calls.Add(1)%3 != 0 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintf(w, "ok, got %d bytes", n)
+	})
+	return mux
+}
+
+// sleep waits for d, and reports false if ctx ended first
+func sleep(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// retryTransport is a RoundTripper that sends a request again when it
+// fails in a way that another try might fix. It retries only idempotent
+// requests: repeating a POST could, say, charge a card twice
+type retryTransport struct {
+	next http.RoundTripper
+	opts []retry.Option
+}
+
+func (t retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !idempotent(req) || (req.Body != nil && req.Body != http.NoBody && req.GetBody == nil) {
+		// Not safe to repeat, or no way to send the body twice
+		return t.next.RoundTrip(req)
+	}
+
+	opts := append([]retry.Option{retry.WithRetryIf(shouldRetry)}, t.opts...)
+	attempt := 0
+
+	// The last response with a status worth retrying. It's thrown away
+	// only when another attempt starts: if none does, it's the answer
+	var last *http.Response
+	discard := func() {
+		if last != nil {
+			// Read the body to the end, so the connection can be reused
+			io.Copy(io.Discard, last.Body)
+			last.Body.Close()
+			last = nil
+		}
+	}
+
+	resp, err := retry.DoValue(req.Context(), func(ctx context.Context) (*http.Response, error) {
+		attempt++
+		discard()
+
+		// A RoundTripper must not modify its request, and a body can
+		// be read only once: every attempt gets a copy, with a new body
+		r := req.Clone(ctx)
+		if attempt > 1 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, retry.Permanent(err)
+			}
+			r.Body = body
+		}
+
+		resp, err := t.next.RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		if retryStatus(resp.StatusCode) {
+			last = resp
+			return nil, &statusError{code: resp.StatusCode}
+		}
+		return resp, nil
+	}, opts...)
+
+	// Out of attempts on a bad status: a RoundTripper returns the
+	// response it got, as it would without retrying, never an error
+	var se *statusError
+	if errors.As(err, &se) && last != nil {
+		return last, nil
+	}
+	discard()
+	return resp, err
+}
+
+// idempotent reports whether sending req twice has the same effect as
+// sending it once
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case "", http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return req.Header.Get("Idempotency-Key") != ""
+}
+
+// retryStatus reports whether a response with code is worth retrying:
+// the server is overloaded, or a proxy couldn't reach it
+func retryStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// statusError tells retry.DoValue that a response is worth retrying.
+// It never leaves retryTransport
+type statusError struct {
+	code int
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("server returned %d %s", e.code, http.StatusText(e.code))
+}
+
+// shouldRetry retries bad statuses and network failures, but not a
+// request whose context ended: nobody is waiting for it any more
+func shouldRetry(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+func main() {
+	fmt.Println("http.Client in Depth")
+	fmt.Println("====================")
+	fmt.Println()
+
+	srv, err := newServer()
+	if err != nil {
+		fmt.Println("serve:", err)
+		return
+	}
+	defer srv.Close()
+
+	// Example 1: Three kinds of timeout
+	fmt.Println("1. Client.Timeout vs a context vs the Transport:")
+	timeouts(srv.URL)
+	fmt.Println()
+
+	// Example 2: Connection reuse
+	fmt.Println("2. Connection pooling:")
+	pooling()
+	fmt.Println()
+
+	// Example 3: Request bodies are read once
+	fmt.Println("3. Sending a body twice:")
+	bodies(srv.URL)
+	fmt.Println()
+
+	// Example 4: A retrying RoundTripper
+	fmt.Println("4. A retrying transport:")
+	retries(srv)
+}
+
+func timeouts(base string) {
+	// Client.Timeout covers everything: connecting, the headers, and
+	// reading the body
+	client := &http.Client{Timeout: 150 * time.Millisecond}
+	fetch("Client.Timeout, slow body", client, base+"/slow-body", nil)
+
+	// A context deadline covers the same, for one request. It's the
+	// one to use in a server, where the request's context is the parent
+	ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+	defer cancel()
+	fetch("context, slow headers", http.DefaultClient, base+"/slow-headers", ctx)
+
+	// ResponseHeaderTimeout covers only the wait for the headers. A
+	// slow body is fine, so it suits streaming responses
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = 150 * time.Millisecond
+	client = &http.Client{Transport: transport}
+	fetch("ResponseHeaderTimeout, slow headers", client, base+"/slow-headers", nil)
+	fetch("ResponseHeaderTimeout, slow body", client, base+"/slow-body", nil)
+}
+
+// fetch GETs url, reads the whole body, and prints how it went
+func fetch(label string, client *http.Client, url string, ctx context.Context) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	start := time.Now()
+
+	body, err := getBody(ctx, client, url)
+
+	took := time.Since(start).Round(50 * time.Millisecond)
+	if err != nil {
+		fmt.Printf("   %-36s after ~%v: %v\n", label, took, withoutURL(err))
+		return
+	}
+	fmt.Printf("   %-36s after ~%v: ok, %d bytes\n", label, took, len(body))
+}
+
+func getBody(ctx context.Context, client *http.Client, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	return io.ReadAll(resp.Body)
+}
+
+// withoutURL drops the `Get "http://127.0.0.1:port/..."` prefix, which
+// changes on every run
+func withoutURL(err error) error {
+	var ue *url.Error
+	if errors.As(err, &ue) {
+		return ue.Err
+	}
+	return err
+}
+
+func pooling() {
+	// Every response body must be read to the end and closed, or its
+	// connection can't go back to the pool
+	srv, _ := newServer()
+	for range 10 {
+		resp, err := http.Get(srv.URL + "/big")
+		if err != nil {
+			continue
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	}
+	fmt.Printf("   10 requests, bodies read and closed:   %2d connections\n", srv.conns.Load())
+	srv.Close()
+
+	srv, _ = newServer()
+	for range 10 {
+		resp, err := http.Get(srv.URL + "/big")
+		if err != nil {
+			continue
+		}
+		resp.Body.Close() // without reading it
+	}
+	fmt.Printf("   10 requests, bodies only closed:       %2d connections\n", srv.conns.Load())
+	srv.Close()
+
+	// After a burst, the pool keeps MaxIdleConnsPerHost connections to
+	// each host, 2 by default, and closes the rest
+	for _, idle := range []int{http.DefaultMaxIdleConnsPerHost, 10} {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.MaxIdleConnsPerHost = idle
+		client := &http.Client{Transport: transport}
+
+		srv, _ := newServer()
+		burst(client, srv.URL+"/work", 10)
+		burst(client, srv.URL+"/work", 10)
+		fmt.Printf("   2 bursts of 10, MaxIdleConnsPerHost=%-2d %2d connections\n", idle, srv.conns.Load())
+
+		transport.CloseIdleConnections()
+		srv.Close()
+	}
+}
+
+// burst sends n requests at once, and waits for them all
+func burst(client *http.Client, url string, n int) {
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			getBody(context.Background(), client, url)
+		})
+	}
+	wg.Wait()
+}
+
+func bodies(base string) {
+	// http.NewRequest knows how to rewind a strings.Reader,
+	// bytes.Reader, or bytes.Buffer, and sets req.GetBody. For any
+	// other reader, the body is gone after the first send
+	req, _ := http.NewRequest(http.MethodPost, base+"/echo", io.MultiReader(strings.NewReader("hello world")))
+	fmt.Printf("   io.Reader, GetBody set: %v\n", req.GetBody != nil)
+	send(req)
+	send(req)
+
+	req, _ = http.NewRequest(http.MethodPost, base+"/echo", strings.NewReader("hello world"))
+	fmt.Printf("   strings.Reader, GetBody set: %v\n", req.GetBody != nil)
+	send(req)
+	req.Body, _ = req.GetBody() // what redirects and retries do
+	send(req)
+}
+
+func send(req *http.Request) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("      error:", withoutURL(err))
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("      %s\n", body)
+}
+
+func retries(srv *server) {
+	client := &http.Client{Transport: retryTransport{
+		next: http.DefaultTransport,
+		opts: []retry.Option{
+			retry.WithBackoff(10*time.Millisecond, 100*time.Millisecond),
+			retry.WithOnRetry(func(attempt int, err error, delay time.Duration) {
+				fmt.Printf("      attempt %d: %v\n", attempt, err)
+			}),
+		},
+	}}
+
+	for _, method := range []string{http.MethodPut, http.MethodPost} {
+		srv.calls.Store(0)
+		fmt.Printf("   %s /flaky:\n", method)
+
+		req, _ := http.NewRequest(method, srv.URL+"/flaky", strings.NewReader("payload"))
+		resp, err := client.Do(req)
+		if err != nil {
+			fmt.Println("      error:", withoutURL(err))
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("      %s: %s\n", resp.Status, strings.TrimSpace(string(body)))
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+// fakeTransport answers each attempt with the next of its results, and
+// records the body every attempt sent
+type fakeTransport struct {
+	results []any // a status code, or an error
+	bodies  []string
+	closed  int // response bodies closed
+}
+
+func (f *fakeTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body := ""
+	if req.Body != nil {
+		b, _ := io.ReadAll(req.Body)
+		body = string(b)
+	}
+	f.bodies = append(f.bodies, body)
+
+	result := f.results[min(len(f.bodies), len(f.results))-1]
+	if err, ok := result.(error); ok {
+		return nil, err
+	}
+	return &http.Response{
+		StatusCode: result.(int),
+		Body:       closeCounter{io.NopCloser(strings.NewReader("body")), &f.closed},
+		Request:    req,
+	}, nil
+}
+
+type closeCounter struct {
+	io.ReadCloser
+	n *int
+}
+
+func (c closeCounter) Close() error {
+	*c.n++
+	return c.ReadCloser.Close()
+}
+
+func TestRetryTransport(t *testing.T) {
+	errNetwork := errors.New("connection reset")
+
+	tests := []struct {
+		name      string
+		method    string
+		body      io.Reader
+		header    string // an Idempotency-Key
+		results   []any
+		wantCalls int
+		wantCode  int
+	}{
+		{"success", "GET", nil, "", []any{200}, 1, 200},
+		{"503 then 200", "GET", nil, "", []any{503, 503, 200}, 3, 200},
+		{"429, 502, and 504 are retried", "GET", nil, "", []any{429, 502, 504, 200}, 4, 200},
+		{"network error then 200", "GET", nil, "", []any{errNetwork, 200}, 2, 200},
+		{"500 isn't retried", "GET", nil, "", []any{500, 200}, 1, 500},
+		{"404 isn't retried", "GET", nil, "", []any{404, 200}, 1, 404},
+		{"gives up with the last response", "GET", nil, "", []any{503}, 4, 503},
+
+		{"PUT is retried", "PUT", strings.NewReader("payload"), "", []any{503, 200}, 2, 200},
+		{"DELETE is retried", "DELETE", nil, "", []any{503, 200}, 2, 200},
+		{"POST isn't retried", "POST", strings.NewReader("payload"), "", []any{503, 200}, 1, 503},
+		{"POST with an Idempotency-Key is", "POST", strings.NewReader("payload"), "k1", []any{503, 200}, 2, 200},
+		{"no GetBody: not retried", "PUT", io.MultiReader(strings.NewReader("payload")), "", []any{503, 200}, 1, 503},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				fake := &fakeTransport{results: tt.results}
+				client := &http.Client{Transport: retryTransport{
+					next: fake,
+					opts: []retry.Option{retry.WithMaxAttempts(4)},
+				}}
+
+				req := httptest.NewRequest(tt.method, "http://example.com/", tt.body)
+				req.RequestURI = "" // a client request must not have one
+				if tt.header != "" {
+					req.Header.Set("Idempotency-Key", tt.header)
+				}
+				if _, ok := tt.body.(*strings.Reader); ok {
+					// httptest.NewRequest, unlike http.NewRequest, doesn't
+					// set GetBody
+					req.GetBody = func() (io.ReadCloser, error) {
+						return io.NopCloser(strings.NewReader("payload")), nil
+					}
+				}
+
+				resp, err := client.Do(req)
+
+				if len(fake.bodies) != tt.wantCalls {
+					t.Errorf("want %d calls; got %d", tt.wantCalls, len(fake.bodies))
+				}
+				if err != nil {
+					t.Fatalf("want %d; got %v", tt.wantCode, err)
+				}
+				// The body is still there to read, even after retries
+				body, _ := io.ReadAll(resp.Body)
+				resp.Body.Close()
+				if resp.StatusCode != tt.wantCode || string(body) != "body" {
+					t.Errorf("want %d with its body; got %d, %q", tt.wantCode, resp.StatusCode, body)
+				}
+			})
+		})
+	}
+}
+
+func TestRetryTransportResendsBody(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		fake := &fakeTransport{results: []any{503, 503, 200}}
+		client := &http.Client{Transport: retryTransport{next: fake}}
+
+		req, _ := http.NewRequest("PUT", "http://example.com/", strings.NewReader("payload"))
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		for i, body := range fake.bodies {
+			if body != "payload" {
+				t.Errorf("attempt %d: want the whole body; got %q", i+1, body)
+			}
+		}
+		// Two failed responses, closed by the transport; one by us
+		if fake.closed != 3 {
+			t.Errorf("want every response body closed; got %d of 3", fake.closed)
+		}
+	})
+}
+
+func TestRetryTransportWaits(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		fake := &fakeTransport{results: []any{503, 503, 200}}
+		client := &http.Client{Transport: retryTransport{
+			next: fake,
+			opts: []retry.Option{retry.WithBackoff(time.Second, time.Minute)},
+		}}
+
+		start := time.Now()
+		resp, err := client.Get("http://example.com/")
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+
+		// 1s, then 2s
+		if took := time.Since(start); took != 3*time.Second {
+			t.Errorf("want 3s of backoff; got %v", took)
+		}
+	})
+}
+
+func TestRetryTransportStopsWithContext(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		fake := &fakeTransport{results: []any{503}}
+		client := &http.Client{Transport: retryTransport{
+			next: fake,
+			opts: []retry.Option{retry.WithMaxAttempts(0), retry.WithBackoff(time.Second, time.Second)},
+		}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 2500*time.Millisecond)
+		defer cancel()
+		req, _ := http.NewRequestWithContext(ctx, "GET", "http://example.com/", nil)
+
+		_, err := client.Do(req)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want DeadlineExceeded; got %v", err)
+		}
+		// Attempts at 0s, 1s, and 2s; the deadline ends the wait after
+		if len(fake.bodies) != 3 {
+			t.Errorf("want 3 calls; got %d", len(fake.bodies))
+		}
+	})
+}
+
+func TestTimeouts(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for real network timeouts")
+	}
+
+	ts := httptest.NewServer((&server{}).routes())
+	defer ts.Close()
+
+	headerTimeout := ts.Client().Transport.(*http.Transport).Clone()
+	headerTimeout.ResponseHeaderTimeout = 150 * time.Millisecond
+
+	tests := []struct {
+		name    string
+		client  *http.Client
+		path    string
+		wantErr bool
+	}{
+		{"Client.Timeout, slow body", &http.Client{Timeout: 150 * time.Millisecond}, "/slow-body", true},
+		{"Client.Timeout, slow headers", &http.Client{Timeout: 150 * time.Millisecond}, "/slow-headers", true},
+		{"ResponseHeaderTimeout, slow headers", &http.Client{Transport: headerTimeout}, "/slow-headers", true},
+		{"ResponseHeaderTimeout, slow body", &http.Client{Transport: headerTimeout}, "/slow-body", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := getBody(context.Background(), tt.client, ts.URL+tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("want error %v; got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,420 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// Worker runs background jobs that handlers queue, like sending an
+// email after a signup. The jobs in its queue must be done before the
+// program exits
+type Worker struct {
+	handle  func(job string)
+	done    chan struct{} // closed when Run returns
+	pending atomic.Int64  // jobs queued or running
+
+	mu     sync.Mutex
+	jobs   chan string
+	closed bool
+}
+
+func NewWorker(queue int, handle func(job string)) *Worker {
+	return &Worker{handle: handle, done: make(chan struct{}), jobs: make(chan string, queue)}
+}
+
+// Run does the jobs one by one, until Stop is called and the queue is
+// empty
+func (w *Worker) Run() {
+	defer close(w.done)
+	for job := range w.jobs {
+		w.handle(job)
+		w.pending.Add(-1)
+	}
+}
+
+// Enqueue adds a job. It reports false if the queue is full, or if the
+// worker is stopping
+func (w *Worker) Enqueue(job string) bool {
+	// The lock keeps Stop from closing jobs in the middle of a send,
+	// which would panic
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return false
+	}
+	select {
+	case w.jobs <- job:
+		w.pending.Add(1)
+		return true
+	default:
+		return false
+	}
+}
+
+// Stop refuses new jobs, and waits until the queued ones are done, or
+// until ctx ends
+func (w *Worker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	if !w.closed {
+		w.closed = true
+		close(w.jobs)
+	}
+	w.mu.Unlock()
+
+	select {
+	case <-w.done:
+		return nil
+	case <-ctx.Done():
+		if w.pending.Load() == 0 {
+			<-w.done // nothing left to do: Run is returning
+			return nil
+		}
+		return fmt.Errorf("worker: %d jobs unfinished: %w", w.pending.Load(), ctx.Err())
+	}
+}
+
+// App is an HTTP server and the worker behind it, which start and stop
+// together
+type App struct {
+	srv    *http.Server
+	worker *Worker
+	log    *slog.Logger
+
+	// drainDelay is how long the app keeps serving after it reports
+	// not ready, so that a load balancer notices, and stops sending it
+	// new requests
+	drainDelay time.Duration
+
+	ready    atomic.Bool
+	inFlight atomic.Int64
+
+	// stopping is cancelled when shutdown starts. Requests that never
+	// end on their own, like event streams, watch it
+	stopping context.Context
+	stop     context.CancelFunc
+}
+
+func NewApp(log *slog.Logger, worker *Worker, drainDelay time.Duration) *App {
+	a := &App{worker: worker, log: log, drainDelay: drainDelay}
+	a.stopping, a.stop = context.WithCancel(context.Background())
+	a.srv = &http.Server{
+		Handler:           a.routes(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	// Shutdown runs this, and doesn't wait for it
+	a.srv.RegisterOnShutdown(a.stop)
+	return a
+}
+
+func (a *App) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Liveness: the process is up. Stays 200 until the very end
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "ok")
+	})
+
+	// Readiness: the app wants new requests. Turns 503 when shutdown
+	// starts
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if !a.ready.Load() {
+			http.Error(w, "shutting down", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprintln(w, "ready")
+	})
+
+	// A request that takes a while: ?ms=300
+	mux.HandleFunc("GET /work", func(w http.ResponseWriter, r *http.Request) {
+		ms, _ := strconv.Atoi(r.URL.Query().Get("ms"))
+		select {
+		case <-time.After(time.Duration(ms) * time.Millisecond):
+			fmt.Fprintf(w, "done after %dms\n", ms)
+		case <-r.Context().Done():
+			// The client left, or the server was forced to close
+		}
+	})
+
+	// Queues a background job: ?name=welcome-email
+	mux.HandleFunc("POST /jobs", func(w http.ResponseWriter, r *http.Request) {
+		if !a.worker.Enqueue(r.URL.Query().Get("name")) {
+			http.Error(w, "try again later", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintln(w, "queued")
+	})
+
+	// A stream that never ends on its own. Shutdown waits for every
+	// request, so without watching a.stopping, it would wait for this
+	// one until its deadline
+	mux.HandleFunc("GET /events", func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		for i := 1; ; i++ {
+			fmt.Fprintf(w, "event %d\n", i)
+			rc.Flush()
+
+			select {
+			case <-time.After(60 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			case <-a.stopping.Done():
+				fmt.Fprintln(w, "bye: server shutting down")
+				return
+			}
+		}
+	})
+
+	return a.track(mux)
+}
+
+// track counts the requests being served
+func (a *App) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.inFlight.Add(1)
+		defer a.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Start starts the worker, and serves on ln
+func (a *App) Start(ln net.Listener) {
+	go a.worker.Run()
+	go func() {
+		if err := a.srv.Serve(ln); !errors.Is(err, http.ErrServerClosed) {
+			a.log.Error("serve", "err", err)
+		}
+	}()
+	a.ready.Store(true)
+}
+
+// Shutdown stops the app, in an order that loses no request and no job:
+//
+//  1. Report not ready, and keep serving for drainDelay
+//  2. Stop accepting connections, close the idle ones, and wait for
+//     the requests in flight
+//  3. If ctx ends first, close the connections that are left
+//  4. Stop the worker last: the requests in step 2 may queue jobs
+func (a *App) Shutdown(ctx context.Context) error {
+	a.ready.Store(false)
+	a.log.Info("not ready", "drain", a.drainDelay)
+	select {
+	case <-time.After(a.drainDelay):
+	case <-ctx.Done():
+	}
+
+	a.log.Info("shutting down", "in_flight", a.inFlight.Load())
+	err := a.srv.Shutdown(ctx)
+	if err != nil {
+		a.log.Warn("deadline passed, closing connections", "in_flight", a.inFlight.Load())
+		a.srv.Close()
+	}
+
+	if werr := a.worker.Stop(ctx); werr != nil {
+		err = errors.Join(err, werr)
+	}
+	a.log.Info("stopped", "err", err)
+	return err
+}
+
+func main() {
+	addr := flag.String("addr", "", "serve on this address until Ctrl+C, instead of the demo")
+	flag.Parse()
+
+	if *addr != "" {
+		serve(*addr)
+		return
+	}
+
+	fmt.Println("Graceful HTTP Server Shutdown")
+	fmt.Println("=============================")
+	fmt.Println()
+
+	// Example 1: Nothing is lost
+	fmt.Println("1. Shutdown with requests in flight, a stream, and queued jobs:")
+	graceful()
+	fmt.Println()
+
+	// Example 2: The deadline
+	fmt.Println("2. A request that outlives the deadline:")
+	deadline()
+}
+
+// serve runs the app until the process gets SIGINT or SIGTERM, like a
+// real server. Kubernetes, systemd, and docker stop send SIGTERM
+func serve(addr string) {
+	log := slog.New(slog.NewTextHandler(os.Stdout, nil))
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		log.Error("listen", "err", err)
+		os.Exit(1)
+	}
+
+	worker := NewWorker(100, func(job string) {
+		time.Sleep(time.Second)
+		log.Info("job done", "job", job)
+	})
+	app := NewApp(log, worker, 5*time.Second)
+	app.Start(ln)
+	log.Info("serving", "addr", ln.Addr().String())
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	<-ctx.Done()
+	// A second Ctrl+C kills the process at once
+	stop()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	if err := app.Shutdown(ctx); err != nil {
+		os.Exit(1)
+	}
+}
+
+func graceful() {
+	log := newLogger()
+	worker := NewWorker(10, func(job string) {
+		time.Sleep(250 * time.Millisecond)
+		log.Info("job done", "job", job)
+	})
+	app := NewApp(log, worker, 100*time.Millisecond)
+	base := start(app)
+
+	var wg sync.WaitGroup
+	wg.Go(func() { get("slow request", base+"/work?ms=350") })
+	wg.Go(func() { stream(base + "/events") })
+	for _, job := range []string{"welcome-email", "thumbnail", "invoice"} {
+		post("job", base+"/jobs?name="+job)
+	}
+	time.Sleep(50 * time.Millisecond) // everything has started
+
+	wg.Go(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		app.Shutdown(ctx)
+	})
+
+	time.Sleep(50 * time.Millisecond) // draining: still serving
+	get("readyz", base+"/readyz")
+	get("healthz", base+"/healthz")
+
+	time.Sleep(100 * time.Millisecond) // the listener is closed
+	get("new request", base+"/healthz")
+
+	wg.Wait()
+}
+
+func deadline() {
+	log := newLogger()
+	app := NewApp(log, NewWorker(1, func(string) {}), 0)
+	base := start(app)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		get("2s request", base+"/work?ms=2000")
+	}()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	if err := app.Shutdown(ctx); err != nil {
+		fmt.Println("   Shutdown:", err)
+	}
+	<-done
+}
+
+// start serves app on a random local port, and returns its URL
+func start(app *App) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println("listen:", err)
+		os.Exit(1)
+	}
+	app.Start(ln)
+	return "http://" + ln.Addr().String()
+}
+
+// client doesn't keep idle connections, so every request dials, and
+// shows whether the server still accepts connections
+var client = &http.Client{
+	Timeout:   5 * time.Second,
+	Transport: &http.Transport{DisableKeepAlives: true},
+}
+
+func get(label, url string) {
+	resp, err := client.Get(url)
+	report(label, resp, err)
+}
+
+func post(label, url string) {
+	resp, err := client.Post(url, "", nil)
+	report(label, resp, err)
+}
+
+func report(label string, resp *http.Response, err error) {
+	if err != nil {
+		var op *net.OpError
+		if errors.As(err, &op) && op.Op == "dial" {
+			err = errors.New("connection refused")
+		}
+		fmt.Printf("   %-13s -> error: %v\n", label, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("   %-13s -> %d %s\n", label, resp.StatusCode, strings.TrimSpace(string(body)))
+}
+
+// stream prints the last line of an event stream when it ends
+func stream(url string) {
+	resp, err := client.Get(url)
+	if err != nil {
+		report("stream", nil, err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	lines := strings.Split(strings.TrimSpace(string(body)), "\n")
+	fmt.Printf("   %-13s -> %d events, then %q\n", "stream", len(lines)-1, lines[len(lines)-1])
+}
+
+// newLogger returns a logger that writes lines three spaces in, with no
+// time, like the rest of the output
+func newLogger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(indent{os.Stdout}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// indent writes every line three spaces in. A slog handler writes a
+// whole line per Write call
+type indent struct {
+	w io.Writer
+}
+
+func (i indent) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(i.w, "   "); err != nil {
+		return 0, err
+	}
+	return i.w.Write(p)
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// startApp serves a new App on a random port, and returns it and its URL
+func startApp(t *testing.T, drainDelay time.Duration) (*App, string) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+	app := NewApp(log, NewWorker(10, func(string) {}), drainDelay)
+	app.Start(ln)
+	t.Cleanup(func() { app.srv.Close() })
+	return app, "http://" + ln.Addr().String()
+}
+
+// waitFor polls until cond is true, or fails the test after a second
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(time.Second); !cond(); {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// shutdown runs app.Shutdown in the background, and returns its result
+func shutdown(app *App, timeout time.Duration) <-chan error {
+	done := make(chan error, 1)
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+		done <- app.Shutdown(ctx)
+	}()
+	return done
+}
+
+func body(t *testing.T, resp *http.Response) string {
+	t.Helper()
+	defer resp.Body.Close()
+	b, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return strings.TrimSpace(string(b))
+}
+
+func TestInFlightRequestsComplete(t *testing.T) {
+	app, base := startApp(t, 0)
+	addr := strings.TrimPrefix(base, "http://")
+
+	type result struct {
+		resp *http.Response
+		err  error
+	}
+	results := make(chan result, 3)
+	for range 3 {
+		go func() {
+			resp, err := client.Get(base + "/work?ms=200")
+			results <- result{resp, err}
+		}()
+	}
+	waitFor(t, "the requests to start", func() bool { return app.inFlight.Load() == 3 })
+
+	done := shutdown(app, 2*time.Second)
+
+	// New connections are refused while the old requests finish
+	waitFor(t, "the listener to close", func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			conn.Close()
+		}
+		return err != nil
+	})
+	if n := app.inFlight.Load(); n != 3 {
+		t.Errorf("want 3 requests still in flight; got %d", n)
+	}
+
+	for range 3 {
+		r := <-results
+		if r.err != nil {
+			t.Fatalf("in-flight request: %v", r.err)
+		}
+		if got := body(t, r.resp); r.resp.StatusCode != http.StatusOK || got != "done after 200ms" {
+			t.Errorf("want 200 done; got %d %q", r.resp.StatusCode, got)
+		}
+	}
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+}
+
+func TestReadinessDuringDrain(t *testing.T) {
+	app, base := startApp(t, 300*time.Millisecond)
+
+	if resp, err := client.Get(base + "/readyz"); err != nil || resp.StatusCode != http.StatusOK {
+		t.Fatalf("want ready before shutdown; got %v, %v", resp, err)
+	}
+
+	done := shutdown(app, 2*time.Second)
+	waitFor(t, "not ready", func() bool { return !app.ready.Load() })
+
+	// Draining: the app still serves, but asks for no new requests
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/readyz", http.StatusServiceUnavailable},
+		{"/healthz", http.StatusOK},
+		{"/work?ms=0", http.StatusOK},
+	}
+	for _, tt := range tests {
+		resp, err := client.Get(base + tt.path)
+		if err != nil {
+			t.Fatalf("%s while draining: %v", tt.path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.want {
+			t.Errorf("%s while draining: want %d; got %d", tt.path, tt.want, resp.StatusCode)
+		}
+	}
+
+	if err := <-done; err != nil {
+		t.Errorf("Shutdown: %v", err)
+	}
+	if _, err := client.Get(base + "/healthz"); err == nil {
+		t.Error("want requests refused after shutdown")
+	}
+}
+
+func TestShutdownDeadline(t *testing.T) {
+	app, base := startApp(t, 0)
+
+	failed := make(chan error, 1)
+	go func() {
+		_, err := client.Get(base + "/work?ms=10000")
+		failed <- err
+	}()
+	waitFor(t, "the request to start", func() bool { return app.inFlight.Load() == 1 })
+
+	start := time.Now()
+	if err := <-shutdown(app, 50*time.Millisecond); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want DeadlineExceeded; got %v", err)
+	}
+	if took := time.Since(start); took > time.Second {
+		t.Errorf("want Shutdown to give up at its deadline; took %v", took)
+	}
+	if err := <-failed; err == nil {
+		t.Error("want the cut request to fail")
+	}
+}
+
+func TestStreamEndsOnShutdown(t *testing.T) {
+	app, base := startApp(t, 0)
+
+	resp, err := client.Get(base + "/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	lines := bufio.NewScanner(resp.Body)
+	if !lines.Scan() || lines.Text() != "event 1" {
+		t.Fatalf("want the first event; got %q", lines.Text())
+	}
+
+	// Without RegisterOnShutdown, Shutdown would wait for the stream
+	// until its deadline
+	if err := <-shutdown(app, time.Second); err != nil {
+		t.Fatalf("Shutdown: %v", err)
+	}
+	var last string
+	for lines.Scan() {
+		last = lines.Text()
+	}
+	if last != "bye: server shutting down" {
+		t.Errorf("want a goodbye; got %q", last)
+	}
+}
+
+func TestWorkerFinishesQueuedJobs(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var mu sync.Mutex
+		var done []string
+		w := NewWorker(10, func(job string) {
+			time.Sleep(time.Second)
+			mu.Lock()
+			done = append(done, job)
+			mu.Unlock()
+		})
+		go w.Run()
+
+		for _, job := range []string{"a", "b", "c"} {
+			if !w.Enqueue(job) {
+				t.Fatalf("Enqueue(%q) refused", job)
+			}
+		}
+		if err := w.Stop(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.Join(done, ","); got != "a,b,c" {
+			t.Errorf("want every job done; got %q", got)
+		}
+		if w.Enqueue("late") {
+			t.Error("want Enqueue refused after Stop")
+		}
+	})
+}
+
+func TestWorkerStopDeadline(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		w := NewWorker(10, func(string) { time.Sleep(time.Minute) })
+		go w.Run()
+		w.Enqueue("a")
+		w.Enqueue("b")
+
+		ctx, cancel := context.WithTimeout(context.Background(), 90*time.Second)
+		defer cancel()
+		err := w.Stop(ctx)
+		if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "1 jobs unfinished") {
+			t.Errorf("want one job unfinished at the deadline; got %v", err)
+		}
+		<-w.done // let the last job end inside the bubble
+	})
+}
+
+func TestWorkerIdleStopsAfterDeadline(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		w := NewWorker(10, func(string) {})
+		go w.Run()
+
+		// Even with no time left, an idle worker stops cleanly
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := w.Stop(ctx); err != nil {
+			t.Errorf("want nil; got %v", err)
+		}
+	})
+}
+
+func TestWorkerQueueFull(t *testing.T) {
+	w := NewWorker(1, func(string) {})
+	if !w.Enqueue("a") {
+		t.Fatal("want the first job queued")
+	}
+	if w.Enqueue("b") {
+		t.Error("want the second job refused: nothing runs the queue")
+	}
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/ratelimit"
+)
+
+// KeyFunc names the client a request counts against. Clients with the
+// same key share one bucket
+type KeyFunc func(r *http.Request) string
+
+// ByIP counts requests per remote IP address. Behind a proxy or a load
+// balancer, RemoteAddr is the proxy's: see the README
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// ByAPIKey counts requests per API key, and requests without one per
+// IP address. The prefixes keep a key from colliding with an address
+func ByAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return "key:" + key
+	}
+	return "ip:" + ByIP(r)
+}
+
+// RateLimiter gives every client its own token bucket
+type RateLimiter struct {
+	rate  float64
+	burst int
+	key   KeyFunc
+
+	mu      sync.Mutex
+	clients map[string]*client
+}
+
+type client struct {
+	limiter  *ratelimit.Limiter
+	lastSeen time.Time
+}
+
+// NewRateLimiter allows each client rate requests per second, with
+// bursts of up to burst requests
+func NewRateLimiter(rate float64, burst int, key KeyFunc) *RateLimiter {
+	return &RateLimiter{rate: rate, burst: burst, key: key, clients: make(map[string]*client)}
+}
+
+// limiter returns the bucket for key, and creates it on the client's
+// first request
+func (rl *RateLimiter) limiter(key string) *ratelimit.Limiter {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	c, ok := rl.clients[key]
+	if !ok {
+		c = &client{limiter: ratelimit.New(rl.rate, rl.burst)}
+		rl.clients[key] = c
+	}
+	c.lastSeen = time.Now()
+	return c.limiter
+}
+
+// Limit is the middleware. A client over its limit gets a 429, and a
+// Retry-After header that says how many seconds to wait
+func (rl *RateLimiter) Limit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lim := rl.limiter(rl.key(r))
+
+		w.Header().Set("X-RateLimit-Limit", strconv.Itoa(rl.burst))
+		if !lim.Allow() {
+			// Retry-After is in whole seconds: round up, or the client
+			// retries too early and is rejected again
+			wait := math.Ceil(lim.Delay().Seconds())
+			w.Header().Set("Retry-After", strconv.Itoa(max(int(wait), 1)))
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			http.Error(w, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(lim.Tokens())))
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Janitor forgets clients idle for longer than idle, every interval,
+// until ctx is done. Without it, every address that ever sent a request
+// would stay in memory for good
+func (rl *RateLimiter) Janitor(ctx context.Context, every, idle time.Duration) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			rl.sweep(idle)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// sweep deletes the clients idle for longer than idle. A client that
+// comes back gets a new, full bucket, which is what it would have by
+// now anyway, if idle is long enough for the bucket to refill
+func (rl *RateLimiter) sweep(idle time.Duration) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	for key, c := range rl.clients {
+		if time.Since(c.lastSeen) > idle {
+			delete(rl.clients, key)
+		}
+	}
+}
+
+// Len returns the number of clients being tracked
+func (rl *RateLimiter) Len() int {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+	return len(rl.clients)
+}
+
+func hello(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintln(w, "hello")
+}
+
+func main() {
+	fmt.Println("Rate-Limiting Middleware")
+	fmt.Println("========================")
+	fmt.Println()
+
+	// Example 1: Per IP
+	fmt.Println("1. 2 requests per second, bursts of 3, per IP address:")
+	byIP := NewRateLimiter(2, 3, ByIP)
+	h := byIP.Limit(http.HandlerFunc(hello))
+	for range 5 {
+		send(h, "10.0.0.1", "")
+	}
+	send(h, "10.0.0.2", "") // another client, another bucket
+	fmt.Println()
+
+	// Example 2: Refill
+	fmt.Println("2. Half a second later, one token has come back:")
+	time.Sleep(500 * time.Millisecond)
+	send(h, "10.0.0.1", "")
+	send(h, "10.0.0.1", "")
+	fmt.Println()
+
+	// Example 3: Per API key
+	fmt.Println("3. Per API key: two keys behind the same address:")
+	byKey := NewRateLimiter(2, 2, ByAPIKey)
+	h = byKey.Limit(http.HandlerFunc(hello))
+	for _, key := range []string{"alice", "alice", "alice", "bob", "", ""} {
+		send(h, "10.0.0.9", key)
+	}
+	fmt.Println()
+
+	// Example 4: The janitor
+	fmt.Println("4. The janitor forgets idle clients:")
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go byIP.Janitor(ctx, 50*time.Millisecond, 100*time.Millisecond)
+
+	h = byIP.Limit(http.HandlerFunc(hello))
+	for i := range 1000 {
+		h.ServeHTTP(httptest.NewRecorder(), request(fmt.Sprintf("10.1.%d.%d", i/256, i%256), ""))
+	}
+	fmt.Println("   clients, after 1000 more addresses:", byIP.Len())
+	time.Sleep(200 * time.Millisecond)
+	fmt.Println("   clients, after 200ms of quiet:     ", byIP.Len())
+}
+
+// request returns a request from ip, with an API key if key isn't empty
+func request(ip, key string) *http.Request {
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = ip + ":1234"
+	if key != "" {
+		r.Header.Set("X-API-Key", key)
+	}
+	return r
+}
+
+// send serves one request, and prints the response
+func send(h http.Handler, ip, key string) {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, request(ip, key))
+
+	from := ip
+	if key != "" {
+		from += " key=" + key
+	}
+	line := fmt.Sprintf("   %-22s %d remaining=%s", from, w.Code, w.Header().Get("X-RateLimit-Remaining"))
+	if after := w.Header().Get("Retry-After"); after != "" {
+		line += " retry-after=" + after + "s"
+	}
+	fmt.Println(strings.TrimRight(line, " "))
+}
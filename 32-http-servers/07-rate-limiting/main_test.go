@@ -0,0 +1,172 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// The tests run in synctest bubbles: time.Now and the janitor's ticker
+// use a fake clock, which moves only when every goroutine is blocked.
+// pkg/ratelimit reads the same clock, so buckets refill on cue
+
+func serve(h http.Handler, ip, key string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, request(ip, key))
+	return w
+}
+
+func TestLimit(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		h := NewRateLimiter(1, 3, ByIP).Limit(http.HandlerFunc(hello))
+
+		for i, want := range []int{200, 200, 200, 429, 429} {
+			if w := serve(h, "10.0.0.1", ""); w.Code != want {
+				t.Errorf("request %d: want %d; got %d", i+1, want, w.Code)
+			}
+		}
+
+		w := serve(h, "10.0.0.1", "")
+		if got := w.Header().Get("Retry-After"); got != "1" {
+			t.Errorf("want Retry-After 1; got %q", got)
+		}
+		if got := w.Header().Get("X-RateLimit-Remaining"); got != "0" {
+			t.Errorf("want 0 remaining; got %q", got)
+		}
+		if got := w.Body.String(); got != "rate limit exceeded\n" {
+			t.Errorf("want the error body; got %q", got)
+		}
+
+		// Other clients have their own buckets
+		if w := serve(h, "10.0.0.2", ""); w.Code != 200 {
+			t.Errorf("another IP: want 200; got %d", w.Code)
+		}
+
+		time.Sleep(time.Second) // one token back
+		if w := serve(h, "10.0.0.1", ""); w.Code != 200 {
+			t.Errorf("after a second: want 200; got %d", w.Code)
+		}
+		if w := serve(h, "10.0.0.1", ""); w.Code != 429 {
+			t.Errorf("and then: want 429; got %d", w.Code)
+		}
+	})
+}
+
+func TestRetryAfterRoundsUp(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		h := NewRateLimiter(0.25, 1, ByIP).Limit(http.HandlerFunc(hello)) // a token every 4s
+		serve(h, "10.0.0.1", "")
+
+		tests := []struct {
+			after time.Duration
+			want  string
+		}{
+			{0, "4"},
+			{1500 * time.Millisecond, "3"}, // 2.5s left: waiting 2s would be too early
+			{2400 * time.Millisecond, "1"}, // 0.1s left: never 0
+		}
+		for _, tt := range tests {
+			time.Sleep(tt.after)
+			if got := serve(h, "10.0.0.1", "").Header().Get("Retry-After"); got != tt.want {
+				t.Errorf("after %v more: want Retry-After %s; got %q", tt.after, tt.want, got)
+			}
+		}
+	})
+}
+
+func TestKeyFuncs(t *testing.T) {
+	tests := []struct {
+		remoteAddr, apiKey  string
+		wantByIP, wantByKey string
+	}{
+		{"10.0.0.1:1234", "", "10.0.0.1", "ip:10.0.0.1"},
+		{"10.0.0.1:5678", "secret", "10.0.0.1", "key:secret"},
+		{"[::1]:1234", "", "::1", "ip:::1"},
+		{"no-port", "", "no-port", "ip:no-port"},
+	}
+	for _, tt := range tests {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = tt.remoteAddr
+		if tt.apiKey != "" {
+			r.Header.Set("X-API-Key", tt.apiKey)
+		}
+		if got := ByIP(r); got != tt.wantByIP {
+			t.Errorf("ByIP(%q): want %q; got %q", tt.remoteAddr, tt.wantByIP, got)
+		}
+		if got := ByAPIKey(r); got != tt.wantByKey {
+			t.Errorf("ByAPIKey(%q, %q): want %q; got %q", tt.remoteAddr, tt.apiKey, tt.wantByKey, got)
+		}
+	}
+}
+
+func TestByAPIKeySeparatesKeys(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		h := NewRateLimiter(1, 1, ByAPIKey).Limit(http.HandlerFunc(hello))
+
+		for _, key := range []string{"alice", "bob", ""} {
+			if w := serve(h, "10.0.0.1", key); w.Code != 200 {
+				t.Errorf("first request with key %q: want 200; got %d", key, w.Code)
+			}
+			if w := serve(h, "10.0.0.1", key); w.Code != 429 {
+				t.Errorf("second request with key %q: want 429; got %d", key, w.Code)
+			}
+		}
+	})
+}
+
+func TestJanitor(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		rl := NewRateLimiter(1, 1, ByIP)
+		h := rl.Limit(http.HandlerFunc(hello))
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go rl.Janitor(ctx, time.Minute, 5*time.Minute)
+
+		serve(h, "10.0.0.1", "")
+		serve(h, "10.0.0.2", "")
+
+		// 10.0.0.2 keeps coming back; 10.0.0.1 goes quiet
+		for range 6 {
+			time.Sleep(time.Minute)
+			serve(h, "10.0.0.2", "")
+		}
+		synctest.Wait()
+
+		if n := rl.Len(); n != 1 {
+			t.Errorf("want only the active client left; got %d", n)
+		}
+		if _, ok := rl.clients["10.0.0.2"]; !ok {
+			t.Error("want the active client kept")
+		}
+		// cancel stops the janitor, or synctest.Test would never return
+	})
+}
+
+func TestConcurrentRequests(t *testing.T) {
+	// Run with -race: every request goes through the shared map
+	synctest.Test(t, func(t *testing.T) {
+		h := NewRateLimiter(1, 10, ByIP).Limit(http.HandlerFunc(hello))
+
+		var mu sync.Mutex
+		codes := map[int]int{}
+		var wg sync.WaitGroup
+		for range 50 {
+			wg.Go(func() {
+				code := serve(h, "10.0.0.1", "").Code
+				mu.Lock()
+				codes[code]++
+				mu.Unlock()
+			})
+		}
+		wg.Wait()
+
+		// The fake clock stands still, so no token comes back
+		if codes[200] != 10 || codes[429] != 40 {
+			t.Errorf("want 10 allowed and 40 limited; got %v", codes)
+		}
+	})
+}
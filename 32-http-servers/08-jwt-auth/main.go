@@ -0,0 +1,236 @@
+package main
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/ctxmeta"
+)
+
+// claimsKey holds the verified claims in a request's context
+var claimsKey = ctxmeta.NewKey[Claims]("claims")
+
+// Authenticate lets through only requests with a valid bearer token,
+// and stores its claims in the request's context
+func Authenticate(s *Signer) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok {
+				unauthorized(w, "missing bearer token")
+				return
+			}
+			claims, err := s.Verify(token)
+			if err != nil {
+				unauthorized(w, err.Error())
+				return
+			}
+
+			ctx := ctxmeta.WithValue(r.Context(), claimsKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// unauthorized answers 401, and says why in the WWW-Authenticate header
+// the way RFC 6750 describes
+func unauthorized(w http.ResponseWriter, why string) {
+	w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer error="invalid_token", error_description=%q`, why))
+	writeError(w, http.StatusUnauthorized, why)
+}
+
+// RequireRole lets through only requests whose token has role. It runs
+// after Authenticate, which put the claims in the context
+func RequireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := ctxmeta.Value(r.Context(), claimsKey)
+			if !ok || claims.Role != role {
+				// 403, not 401: we know who they are, and the answer is no
+				writeError(w, http.StatusForbidden, "requires role "+role)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type user struct {
+	password string
+	role     string
+}
+
+// App holds the users, and the signer for their tokens
+type App struct {
+	signer *Signer
+	users  map[string]user
+}
+
+func (a *App) Handler() http.Handler {
+	auth := Authenticate(a.signer)
+	admin := RequireRole("admin")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /login", a.login)
+	mux.Handle("GET /me", auth(http.HandlerFunc(me)))
+	mux.Handle("GET /admin", auth(admin(http.HandlerFunc(adminOnly))))
+	return mux
+}
+
+// login trades a username and password for a token
+func (a *App) login(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Username string `json:"username"`
+		Password string `json:"password"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<10)).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "bad JSON")
+		return
+	}
+
+	// A real app stores password hashes, from bcrypt or argon2, never
+	// passwords. ConstantTimeCompare keeps the timing from revealing
+	// how much of a guess was right
+	u, ok := a.users[req.Username]
+	if !ok || subtle.ConstantTimeCompare([]byte(u.password), []byte(req.Password)) != 1 {
+		writeError(w, http.StatusUnauthorized, "wrong username or password")
+		return
+	}
+
+	token, err := a.signer.Sign(req.Username, u.role)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "can't sign a token")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"token":      token,
+		"token_type": "Bearer",
+		"expires_in": int(a.signer.ttl.Seconds()),
+	})
+}
+
+func me(w http.ResponseWriter, r *http.Request) {
+	claims, _ := ctxmeta.Value(r.Context(), claimsKey)
+	writeJSON(w, http.StatusOK, map[string]string{"user": claims.Subject, "role": claims.Role})
+}
+
+func adminOnly(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"secret": "the admin area"})
+}
+
+// writeJSON sends v as JSON with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends errors in one shape, so clients can parse them
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
+
+func main() {
+	fmt.Println("JWT Authentication")
+	fmt.Println("==================")
+	fmt.Println()
+
+	// In production the key comes from a secret store, so that it
+	// survives restarts, and every instance of the app shares it
+	key := make([]byte, 32)
+	rand.Read(key)
+	signer := NewSigner(key, "learngo", 15*time.Minute)
+	app := &App{signer: signer, users: map[string]user{
+		"gopher": {password: "correct horse", role: "user"},
+		"root":   {password: "battery staple", role: "admin"},
+	}}
+	h := app.Handler()
+
+	// Example 1: Logging in
+	fmt.Println("1. Logging in returns a token:")
+	token := login(h, "gopher", "correct horse")
+	login(h, "gopher", "wrong")
+	fmt.Println()
+
+	// Example 2: What's inside
+	fmt.Println("2. Anyone can read a token; it's signed, not encrypted:")
+	parts := strings.Split(token, ".")
+	for i, name := range []string{"header", "payload"} {
+		data, _ := b64.DecodeString(parts[i])
+		fmt.Printf("   %-10s %s\n", name+":", data)
+	}
+	fmt.Printf("   %-10s %s...\n", "signature:", parts[2][:16])
+	fmt.Println()
+
+	// Example 3: Using it
+	fmt.Println("3. Sending it:")
+	get(h, "/me", "no token", "")
+	get(h, "/me", "user token", token)
+	get(h, "/admin", "user token", token)
+	get(h, "/admin", "admin token", login(h, "root", "battery staple"))
+	fmt.Println()
+
+	// Example 4: Attacks
+	fmt.Println("4. Tokens that must be rejected:")
+
+	// Change the payload, keep the signature
+	payload, _ := b64.DecodeString(parts[1])
+	promoted := strings.Replace(string(payload), `"role":"user"`, `"role":"admin"`, 1)
+	forged := parts[0] + "." + b64.EncodeToString([]byte(promoted)) + "." + parts[2]
+	get(h, "/admin", "role edited", forged)
+
+	// Claim there is no signature to check
+	none := b64.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + b64.EncodeToString([]byte(promoted)) + "."
+	get(h, "/admin", `alg "none"`, none)
+
+	// Sign with another key
+	other := make([]byte, 32)
+	rand.Read(other)
+	stranger, _ := NewSigner(other, "learngo", time.Minute).Sign("gopher", "admin")
+	get(h, "/admin", "another key", stranger)
+
+	// Sign with the right key, but too long ago
+	expired, _ := NewSigner(key, "learngo", -time.Minute).Sign("gopher", "user")
+	get(h, "/me", "expired", expired)
+
+	get(h, "/me", "garbage", "not.a.token")
+}
+
+// login posts a username and password, prints the response, and returns
+// the token, if there is one
+func login(h http.Handler, username, password string) string {
+	body := fmt.Sprintf(`{"username":%q,"password":%q}`, username, password)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("POST", "/login", strings.NewReader(body)))
+
+	var resp struct {
+		Token     string `json:"token"`
+		ExpiresIn int    `json:"expires_in"`
+		Error     string `json:"error"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	if resp.Token == "" {
+		fmt.Printf("   POST /login %-7s -> %d %s\n", username, w.Code, resp.Error)
+		return ""
+	}
+	fmt.Printf("   POST /login %-7s -> %d token=%s... expires_in=%d\n", username, w.Code, resp.Token[:20], resp.ExpiresIn)
+	return resp.Token
+}
+
+// get sends a request with token, and prints the response
+func get(h http.Handler, path, label, token string) {
+	req := httptest.NewRequest("GET", path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+
+	fmt.Printf("   GET %-6s %-12s -> %d %s\n", path, label, w.Code, strings.TrimSpace(w.Body.String()))
+}
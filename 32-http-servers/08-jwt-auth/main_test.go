@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+var testKey = []byte("0123456789abcdef0123456789abcdef")
+
+func newApp() *App {
+	return &App{signer: NewSigner(testKey, "test", time.Hour), users: map[string]user{
+		"gopher": {password: "secret", role: "user"},
+		"root":   {password: "toor", role: "admin"},
+	}}
+}
+
+// edit decodes part i of token, replaces old with new in it, and
+// encodes it back. The signature is left as it was
+func edit(token string, i int, old, new string) string {
+	parts := strings.Split(token, ".")
+	data, _ := b64.DecodeString(parts[i])
+	parts[i] = b64.EncodeToString([]byte(strings.Replace(string(data), old, new, 1)))
+	return strings.Join(parts, ".")
+}
+
+func TestSignVerify(t *testing.T) {
+	s := NewSigner(testKey, "test", time.Hour)
+	token, err := s.Sign("gopher", "user")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(token, "."); n != 2 {
+		t.Fatalf("want 3 parts; got %d in %q", n+1, token)
+	}
+
+	c, err := s.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Subject != "gopher" || c.Role != "user" || c.Issuer != "test" {
+		t.Errorf("got claims %+v", c)
+	}
+	if got := c.ExpiresAt - c.IssuedAt; got != 3600 {
+		t.Errorf("want exp an hour after iat; got %ds", got)
+	}
+}
+
+func TestVerifyRejects(t *testing.T) {
+	s := NewSigner(testKey, "test", time.Hour)
+	token, _ := s.Sign("gopher", "user")
+	parts := strings.Split(token, ".")
+
+	otherKey := []byte("another key, just as long as ours")
+	fromOther, _ := NewSigner(otherKey, "test", time.Hour).Sign("gopher", "user")
+	fromIssuer, _ := NewSigner(testKey, "someone else", time.Hour).Sign("gopher", "user")
+	none := b64.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`)) + "." + parts[1] + "."
+
+	tests := []struct {
+		name  string
+		token string
+		want  error
+	}{
+		{"payload edited", edit(token, 1, `"role":"user"`, `"role":"admin"`), ErrSignature},
+		{"signature edited", parts[0] + "." + parts[1] + "." + b64.EncodeToString([]byte("forged")), ErrSignature},
+		{"signature dropped", parts[0] + "." + parts[1] + ".", ErrSignature},
+		{"another key", fromOther, ErrSignature},
+		{"alg none", none, ErrAlgorithm},
+		{"alg HS512", edit(token, 0, "HS256", "HS512"), ErrAlgorithm},
+		{"another issuer", fromIssuer, ErrIssuer},
+		{"two parts", parts[0] + "." + parts[1], ErrMalformed},
+		{"not base64", "!!!." + parts[1] + "." + parts[2], ErrMalformed},
+		{"empty", "", ErrMalformed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := s.Verify(tt.token); !errors.Is(err, tt.want) {
+				t.Errorf("want %v; got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestVerifyExpired(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		s := NewSigner(testKey, "test", time.Minute)
+		token, _ := s.Sign("gopher", "user")
+
+		time.Sleep(59 * time.Second)
+		if _, err := s.Verify(token); err != nil {
+			t.Fatalf("want valid before exp; got %v", err)
+		}
+		time.Sleep(time.Second)
+		if _, err := s.Verify(token); !errors.Is(err, ErrExpired) {
+			t.Errorf("want ErrExpired at exp; got %v", err)
+		}
+	})
+}
+
+// do serves a request with token, if it isn't empty
+func do(h http.Handler, method, path, token, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	if token != "" {
+		r.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// loginAs returns a token for username, or fails the test
+func loginAs(t *testing.T, h http.Handler, username, password string) string {
+	t.Helper()
+	w := do(h, "POST", "/login", "", `{"username":"`+username+`","password":"`+password+`"}`)
+	var resp struct {
+		Token string `json:"token"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || w.Code != http.StatusOK || resp.Token == "" {
+		t.Fatalf("login as %s: %d %s", username, w.Code, w.Body)
+	}
+	return resp.Token
+}
+
+func TestLogin(t *testing.T) {
+	h := newApp().Handler()
+	loginAs(t, h, "gopher", "secret")
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"wrong password", `{"username":"gopher","password":"nope"}`, http.StatusUnauthorized},
+		{"no such user", `{"username":"nobody","password":"secret"}`, http.StatusUnauthorized},
+		{"bad JSON", `{"username":`, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(h, "POST", "/login", "", tt.body)
+			if w.Code != tt.want || strings.Contains(w.Body.String(), "token") {
+				t.Errorf("want %d and no token; got %d %s", tt.want, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestMe(t *testing.T) {
+	h := newApp().Handler()
+	w := do(h, "GET", "/me", loginAs(t, h, "gopher", "secret"), "")
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200; got %d %s", w.Code, w.Body)
+	}
+
+	var got map[string]string
+	json.Unmarshal(w.Body.Bytes(), &got)
+	if got["user"] != "gopher" || got["role"] != "user" {
+		t.Errorf("want the claims from the context; got %v", got)
+	}
+}
+
+func TestUnauthorized(t *testing.T) {
+	h := newApp().Handler()
+	token := loginAs(t, h, "gopher", "secret")
+	parts := strings.Split(token, ".")
+
+	tests := []struct {
+		name  string
+		token string
+		why   string
+	}{
+		{"no token", "", "missing bearer token"},
+		{"tampered payload", edit(token, 1, `"sub":"gopher"`, `"sub":"root"`), "invalid signature"},
+		{"tampered signature", parts[0] + "." + parts[1] + "." + parts[2][1:], "invalid signature"},
+		{"garbage", "garbage", "malformed token"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := do(h, "GET", "/me", tt.token, "")
+			if w.Code != http.StatusUnauthorized {
+				t.Fatalf("want 401; got %d %s", w.Code, w.Body)
+			}
+			if got := w.Header().Get("WWW-Authenticate"); !strings.HasPrefix(got, "Bearer ") || !strings.Contains(got, tt.why) {
+				t.Errorf("want a Bearer challenge saying %q; got %q", tt.why, got)
+			}
+		})
+	}
+}
+
+func TestExpiredToken(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		h := newApp().Handler()
+		token := loginAs(t, h, "gopher", "secret")
+
+		if w := do(h, "GET", "/me", token, ""); w.Code != http.StatusOK {
+			t.Fatalf("want 200 while fresh; got %d", w.Code)
+		}
+		time.Sleep(time.Hour)
+		w := do(h, "GET", "/me", token, "")
+		if w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), "token expired") {
+			t.Errorf("want 401 token expired; got %d %s", w.Code, w.Body)
+		}
+	})
+}
+
+func TestRequireRole(t *testing.T) {
+	h := newApp().Handler()
+
+	tests := []struct {
+		user, password string
+		want           int
+	}{
+		{"gopher", "secret", http.StatusForbidden},
+		{"root", "toor", http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.user, func(t *testing.T) {
+			w := do(h, "GET", "/admin", loginAs(t, h, tt.user, tt.password), "")
+			if w.Code != tt.want {
+				t.Errorf("want %d; got %d %s", tt.want, w.Code, w.Body)
+			}
+		})
+	}
+
+	// Promoting yourself in the payload breaks the signature
+	forged := edit(loginAs(t, h, "gopher", "secret"), 1, `"role":"user"`, `"role":"admin"`)
+	if w := do(h, "GET", "/admin", forged, ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("want 401 for a self-promoted token; got %d", w.Code)
+	}
+}
+
+func TestShortKeyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic for a 16-byte key")
+		}
+	}()
+	NewSigner(make([]byte, 16), "test", time.Hour)
+}
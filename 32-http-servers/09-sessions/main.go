@@ -0,0 +1,228 @@
+package main
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/ctxmeta"
+)
+
+// cookieName starts with __Host-, so browsers only accept the cookie
+// over HTTPS, with Path=/ and no Domain. A sibling subdomain can't set
+// it, which closes the usual way to plant a session ID in a browser
+const cookieName = "__Host-session"
+
+// sessionKey holds the request's session in its context
+var sessionKey = ctxmeta.NewKey[Session]("session")
+
+// setCookie sends the session's ID to the browser
+func setCookie(w http.ResponseWriter, sess Session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:  cookieName,
+		Value: sess.ID,
+		Path:  "/",
+
+		// JavaScript can't read it, so an XSS bug can't steal it
+		HttpOnly: true,
+		// Only sent over HTTPS, so nobody on the network sees it
+		Secure: true,
+		// Not sent with cross-site POSTs, which blocks most CSRF
+		SameSite: http.SameSiteLaxMode,
+
+		// No MaxAge: the browser forgets the cookie when it closes, and
+		// the store decides when the session expires
+	})
+}
+
+// clearCookie tells the browser to delete the cookie
+func clearCookie(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name: cookieName, Value: "", Path: "/", MaxAge: -1,
+		HttpOnly: true, Secure: true, SameSite: http.SameSiteLaxMode,
+	})
+}
+
+// App is a small site with logins
+type App struct {
+	store *Store
+	users map[string]string // username -> password
+}
+
+// Handler returns the app's routes. CrossOriginProtection rejects
+// cross-origin POSTs before they reach a session
+func (a *App) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", a.home)
+	mux.HandleFunc("POST /login", a.login)
+	mux.HandleFunc("POST /logout", a.logout)
+	mux.HandleFunc("POST /email", a.changeEmail)
+
+	csrf := http.NewCrossOriginProtection()
+	return csrf.Handler(a.sessions(mux))
+}
+
+// sessions finds the request's session from its cookie, or starts a new
+// one, and stores it in the request's context
+func (a *App) sessions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var sess Session
+		ok := false
+		if c, err := r.Cookie(cookieName); err == nil {
+			sess, ok = a.store.Get(c.Value)
+		}
+		if !ok {
+			sess = a.store.Create()
+			setCookie(w, sess)
+		}
+
+		ctx := ctxmeta.WithValue(r.Context(), sessionKey, sess)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// session returns the request's session
+func session(r *http.Request) Session {
+	sess, _ := ctxmeta.Value(r.Context(), sessionKey)
+	return sess
+}
+
+func (a *App) home(w http.ResponseWriter, r *http.Request) {
+	if user := session(r).User; user != "" {
+		fmt.Fprintf(w, "hello, %s\n", user)
+		return
+	}
+	fmt.Fprintln(w, "hello, guest")
+}
+
+// login checks a username and password from a form, and logs the
+// session in to a new ID
+func (a *App) login(w http.ResponseWriter, r *http.Request) {
+	user, password := r.PostFormValue("username"), r.PostFormValue("password")
+	want, ok := a.users[user]
+	if !ok || subtle.ConstantTimeCompare([]byte(want), []byte(password)) != 1 {
+		http.Error(w, "wrong username or password", http.StatusUnauthorized)
+		return
+	}
+
+	sess, ok := a.store.Login(session(r).ID, user)
+	if !ok {
+		http.Error(w, "session expired, try again", http.StatusUnauthorized)
+		return
+	}
+	setCookie(w, sess)
+	fmt.Fprintf(w, "logged in as %s\n", user)
+}
+
+func (a *App) logout(w http.ResponseWriter, r *http.Request) {
+	a.store.Delete(session(r).ID)
+	clearCookie(w)
+	fmt.Fprintln(w, "logged out")
+}
+
+// changeEmail is the kind of request CSRF attacks forge: it changes
+// something, and the cookie alone authorizes it
+func (a *App) changeEmail(w http.ResponseWriter, r *http.Request) {
+	user := session(r).User
+	if user == "" {
+		http.Error(w, "log in first", http.StatusUnauthorized)
+		return
+	}
+	fmt.Fprintf(w, "email of %s changed to %s\n", user, r.PostFormValue("email"))
+}
+
+func main() {
+	fmt.Println("Secure Cookie Sessions")
+	fmt.Println("======================")
+	fmt.Println()
+
+	app := &App{store: NewStore(30 * time.Minute), users: map[string]string{"gopher": "correct horse"}}
+	h := app.Handler()
+
+	// Example 1: The cookie
+	fmt.Println("1. The first request starts a session, and sets a cookie:")
+	b := &browser{name: "you", h: h}
+	b.do("GET", "/", nil)
+	fmt.Println("  ", b.setCookie)
+	fmt.Println()
+
+	// Example 2: Logging in
+	fmt.Println("2. Logging in moves the session to a new ID:")
+	before := b.cookie
+	b.do("POST", "/login", url.Values{"username": {"gopher"}, "password": {"correct horse"}})
+	b.do("GET", "/", nil)
+	fmt.Printf("   ID before: %s...  after: %s...\n", before[:8], b.cookie[:8])
+	fmt.Println()
+
+	// Example 3: Session fixation
+	fmt.Println("3. Session fixation: the attacker plants their ID before the login:")
+	attacker := &browser{name: "attacker", h: h}
+	attacker.do("GET", "/", nil)
+	victim := &browser{name: "victim", h: h, cookie: attacker.cookie}
+	victim.do("POST", "/login", url.Values{"username": {"gopher"}, "password": {"correct horse"}})
+	attacker.do("GET", "/", nil) // the planted ID is gone
+	fmt.Println()
+
+	// Example 4: CSRF
+	fmt.Println("4. A form on evil.example posts to us, with the victim's cookie:")
+	form := url.Values{"email": {"attacker@evil.example"}}
+	victim.do("POST", "/email", form, "Sec-Fetch-Site", "cross-site")
+	victim.do("POST", "/email", form, "Origin", "https://evil.example")
+	victim.do("POST", "/email", url.Values{"email": {"gopher@go.dev"}}, "Sec-Fetch-Site", "same-origin")
+	fmt.Println()
+
+	// Example 5: Logging out
+	fmt.Println("5. Logging out deletes the session on the server:")
+	old := victim.cookie
+	victim.do("POST", "/logout", nil)
+	thief := &browser{name: "thief", h: h, cookie: old} // a copy of the old cookie
+	thief.do("GET", "/", nil)
+}
+
+// browser keeps one cookie, the way a browser would for one site
+type browser struct {
+	name      string
+	h         http.Handler
+	cookie    string
+	setCookie string // the last Set-Cookie header
+}
+
+// do sends a request with the browser's cookie, and headers as name,
+// value pairs, and prints the response
+func (b *browser) do(method, path string, form url.Values, headers ...string) {
+	r := httptest.NewRequest(method, "https://example.com"+path, strings.NewReader(form.Encode()))
+	if form != nil {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	if b.cookie != "" {
+		r.AddCookie(&http.Cookie{Name: cookieName, Value: b.cookie})
+	}
+
+	w := httptest.NewRecorder()
+	b.h.ServeHTTP(w, r)
+
+	var note string
+	for _, c := range w.Result().Cookies() {
+		b.setCookie = w.Header().Get("Set-Cookie")
+		if c.MaxAge < 0 {
+			b.cookie = ""
+			note = " (cookie deleted)"
+		} else {
+			b.cookie = c.Value
+			note = " (new session " + c.Value[:8] + "...)"
+		}
+	}
+
+	label := method + " " + path
+	if len(headers) == 2 {
+		label += " " + headers[0] + ": " + headers[1]
+	}
+	fmt.Printf("   %-8s %-40s -> %d %s%s\n", b.name, label, w.Code, strings.TrimSpace(w.Body.String()), note)
+}
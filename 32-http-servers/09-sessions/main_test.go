@@ -0,0 +1,258 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+func newApp() *App {
+	return &App{store: NewStore(30 * time.Minute), users: map[string]string{"gopher": "secret"}}
+}
+
+// serve sends a request with cookie, if it isn't empty, and headers as
+// name, value pairs
+func serve(h http.Handler, method, path, cookie string, form url.Values, headers ...string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, "https://example.com"+path, strings.NewReader(form.Encode()))
+	if form != nil {
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	if cookie != "" {
+		r.AddCookie(&http.Cookie{Name: cookieName, Value: cookie})
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// sessionCookie returns the session cookie w sets, or nil
+func sessionCookie(w *httptest.ResponseRecorder) *http.Cookie {
+	var last *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == cookieName {
+			last = c
+		}
+	}
+	return last
+}
+
+var credentials = url.Values{"username": {"gopher"}, "password": {"secret"}}
+
+// loggedIn returns the cookie of a logged-in session
+func loggedIn(t *testing.T, h http.Handler) string {
+	t.Helper()
+	id := sessionCookie(serve(h, "GET", "/", "", nil)).Value
+	w := serve(h, "POST", "/login", id, credentials)
+	if w.Code != http.StatusOK {
+		t.Fatalf("login: %d %s", w.Code, w.Body)
+	}
+	return sessionCookie(w).Value
+}
+
+func TestStoreExpiry(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		s := NewStore(time.Minute)
+		sess := s.Create()
+
+		// Every Get pushes the expiry back: a minute of quiet, not a
+		// minute in total
+		for range 3 {
+			time.Sleep(50 * time.Second)
+			if _, ok := s.Get(sess.ID); !ok {
+				t.Fatal("want the session kept alive by use")
+			}
+		}
+
+		time.Sleep(time.Minute)
+		if _, ok := s.Get(sess.ID); ok {
+			t.Error("want the session expired after a minute of quiet")
+		}
+		if n := s.Len(); n != 0 {
+			t.Errorf("want Get to delete the expired session; %d left", n)
+		}
+	})
+}
+
+func TestStoreSweep(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		s := NewStore(time.Minute)
+		s.Create()
+		s.Create()
+		time.Sleep(30 * time.Second)
+		fresh := s.Create()
+
+		time.Sleep(30 * time.Second)
+		if n := s.Sweep(); n != 2 {
+			t.Errorf("want 2 swept; got %d", n)
+		}
+		if _, ok := s.Get(fresh.ID); !ok || s.Len() != 1 {
+			t.Errorf("want only the fresh session left; got %d", s.Len())
+		}
+	})
+}
+
+func TestStoreLogin(t *testing.T) {
+	s := NewStore(time.Minute)
+	anon := s.Create()
+
+	sess, ok := s.Login(anon.ID, "gopher")
+	if !ok || sess.User != "gopher" {
+		t.Fatalf("Login: %+v, %v", sess, ok)
+	}
+	if sess.ID == anon.ID {
+		t.Error("want a new ID")
+	}
+	if _, ok := s.Get(anon.ID); ok {
+		t.Error("want the old ID gone")
+	}
+	if _, ok := s.Login("no-such-id", "gopher"); ok {
+		t.Error("want Login to refuse an unknown ID")
+	}
+}
+
+func TestCookieAttributes(t *testing.T) {
+	w := serve(newApp().Handler(), "GET", "/", "", nil)
+	c := sessionCookie(w)
+	if c == nil {
+		t.Fatal("want a session cookie on the first request")
+	}
+	if !c.HttpOnly || !c.Secure || c.SameSite != http.SameSiteLaxMode || c.Path != "/" || c.Domain != "" {
+		t.Errorf("want HttpOnly, Secure, SameSite=Lax, Path=/, no Domain; got %q", w.Header().Get("Set-Cookie"))
+	}
+	if c.MaxAge != 0 || !c.Expires.IsZero() {
+		t.Errorf("want a browser-session cookie; got MaxAge=%d Expires=%v", c.MaxAge, c.Expires)
+	}
+	if len(c.Value) < 26 {
+		t.Errorf("want at least 128 bits of ID; got %q", c.Value)
+	}
+}
+
+func TestSessionKept(t *testing.T) {
+	h := newApp().Handler()
+	id := sessionCookie(serve(h, "GET", "/", "", nil)).Value
+
+	w := serve(h, "GET", "/", id, nil)
+	if c := sessionCookie(w); c != nil {
+		t.Errorf("want no new cookie for a live session; got %v", c)
+	}
+}
+
+func TestLogin(t *testing.T) {
+	h := newApp().Handler()
+	id := loggedIn(t, h)
+
+	if got := serve(h, "GET", "/", id, nil).Body.String(); got != "hello, gopher\n" {
+		t.Errorf("want the session logged in; got %q", got)
+	}
+
+	bad := url.Values{"username": {"gopher"}, "password": {"wrong"}}
+	anon := sessionCookie(serve(h, "GET", "/", "", nil)).Value
+	w := serve(h, "POST", "/login", anon, bad)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("want 401 for a wrong password; got %d", w.Code)
+	}
+	if got := serve(h, "GET", "/", anon, nil).Body.String(); got != "hello, guest\n" {
+		t.Errorf("want the session still anonymous; got %q", got)
+	}
+}
+
+func TestSessionFixation(t *testing.T) {
+	h := newApp().Handler()
+
+	// The attacker gets a valid ID, and plants it in the victim's browser
+	planted := sessionCookie(serve(h, "GET", "/", "", nil)).Value
+
+	w := serve(h, "POST", "/login", planted, credentials)
+	c := sessionCookie(w)
+	if w.Code != http.StatusOK || c == nil {
+		t.Fatalf("want a login with a new cookie; got %d %v", w.Code, c)
+	}
+	if c.Value == planted {
+		t.Fatal("want a new session ID after login")
+	}
+
+	// The attacker's copy of the ID is now worthless
+	w = serve(h, "GET", "/", planted, nil)
+	if got := w.Body.String(); got != "hello, guest\n" {
+		t.Errorf("want the planted ID logged out; got %q", got)
+	}
+	if sessionCookie(w) == nil {
+		t.Error("want a fresh session for the dead ID")
+	}
+}
+
+func TestLogout(t *testing.T) {
+	h := newApp().Handler()
+	id := loggedIn(t, h)
+
+	w := serve(h, "POST", "/logout", id, nil)
+	if c := sessionCookie(w); c == nil || c.MaxAge >= 0 {
+		t.Errorf("want the cookie deleted; got %v", c)
+	}
+
+	// Deleting the cookie isn't enough: a copy of it must not work either
+	if got := serve(h, "GET", "/", id, nil).Body.String(); got != "hello, guest\n" {
+		t.Errorf("want the old ID logged out; got %q", got)
+	}
+}
+
+func TestExpiredSession(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		h := newApp().Handler()
+		id := loggedIn(t, h)
+
+		time.Sleep(31 * time.Minute)
+		w := serve(h, "POST", "/email", id, url.Values{"email": {"a@b.c"}})
+		if w.Code != http.StatusUnauthorized {
+			t.Errorf("want 401 after the session expired; got %d %s", w.Code, w.Body)
+		}
+	})
+}
+
+func TestCSRF(t *testing.T) {
+	h := newApp().Handler()
+	id := loggedIn(t, h)
+	form := url.Values{"email": {"attacker@evil.example"}}
+
+	tests := []struct {
+		name    string
+		method  string
+		headers []string
+		want    int
+	}{
+		{"cross-site POST", "POST", []string{"Sec-Fetch-Site", "cross-site"}, http.StatusForbidden},
+		{"same-site POST from a sibling", "POST", []string{"Sec-Fetch-Site", "same-site"}, http.StatusForbidden},
+		{"foreign Origin", "POST", []string{"Origin", "https://evil.example"}, http.StatusForbidden},
+		{"same-origin POST", "POST", []string{"Sec-Fetch-Site", "same-origin"}, http.StatusOK},
+		{"matching Origin", "POST", []string{"Origin", "https://example.com"}, http.StatusOK},
+		{"non-browser client", "POST", nil, http.StatusOK},
+		{"cross-site GET", "GET", []string{"Sec-Fetch-Site", "cross-site"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := "/email"
+			if tt.method == "GET" {
+				path = "/" // GETs must not change anything, so they're let through
+			}
+			w := serve(h, tt.method, path, id, form, tt.headers...)
+			if w.Code != tt.want {
+				t.Errorf("want %d; got %d %s", tt.want, w.Code, w.Body)
+			}
+		})
+	}
+}
+
+func TestEmailNeedsLogin(t *testing.T) {
+	h := newApp().Handler()
+	w := serve(h, "POST", "/email", "", url.Values{"email": {"a@b.c"}})
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("want 401 for an anonymous session; got %d", w.Code)
+	}
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"crypto/rand"
+	"sync"
+	"time"
+)
+
+// Session is what the server remembers about one browser. The browser
+// only holds its ID, in a cookie
+type Session struct {
+	ID      string
+	User    string // empty until the browser logs in
+	Expires time.Time
+}
+
+// Store keeps sessions in memory. A session expires after ttl without a
+// request: every Get pushes its expiry back. Sessions in memory are lost
+// on a restart, and aren't shared between instances; a real app keeps
+// them in a database or in Redis, behind the same methods
+type Store struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]Session
+}
+
+func NewStore(ttl time.Duration) *Store {
+	return &Store{ttl: ttl, sessions: make(map[string]Session)}
+}
+
+// newID returns 128 random bits, as text. Whoever knows a session's ID
+// is logged in as its user, so IDs must be impossible to guess: never a
+// counter, a timestamp, or math/rand
+func newID() string {
+	return rand.Text()
+}
+
+// Create starts a new, anonymous session
+func (s *Store) Create() Session {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess := Session{ID: newID(), Expires: time.Now().Add(s.ttl)}
+	s.sessions[sess.ID] = sess
+	return sess
+}
+
+// Get returns the session with id, and pushes its expiry back. It
+// reports false if there is no such session, or if it has expired
+func (s *Store) Get(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	if !time.Now().Before(sess.Expires) {
+		delete(s.sessions, id)
+		return Session{}, false
+	}
+	sess.Expires = time.Now().Add(s.ttl)
+	s.sessions[id] = sess
+	return sess, true
+}
+
+// Login moves the session with id to a new ID, and logs user in to it.
+// The old ID stops working.
+//
+// Keeping the old ID would allow session fixation: an attacker who got
+// their session ID into the victim's browser, before the victim logged
+// in, would share the victim's logged-in session
+func (s *Store) Login(id, user string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[id]
+	if !ok || !time.Now().Before(sess.Expires) {
+		return Session{}, false
+	}
+	delete(s.sessions, id)
+
+	sess.ID = newID()
+	sess.User = user
+	sess.Expires = time.Now().Add(s.ttl)
+	s.sessions[sess.ID] = sess
+	return sess, true
+}
+
+// Delete ends the session with id
+func (s *Store) Delete(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.sessions, id)
+}
+
+// Sweep deletes the expired sessions, and returns how many it deleted.
+// Get only finds the expired sessions that come back; run Sweep on a
+// ticker to forget the ones that never do
+func (s *Store) Sweep() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	n := 0
+	now := time.Now()
+	for id, sess := range s.sessions {
+		if !now.Before(sess.Expires) {
+			delete(s.sessions, id)
+			n++
+		}
+	}
+	return n
+}
+
+// Len returns the number of sessions in the store
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.sessions)
+}
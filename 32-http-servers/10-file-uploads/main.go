@@ -0,0 +1,328 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// maxUpload caps a whole upload request, files and form fields together
+const maxUpload = 8 << 20
+
+// Files stores uploads in one directory, and serves them back
+type Files struct {
+	// root is the directory. Opening names through an os.Root means
+	// that no name, like "../../etc/passwd", can reach outside it
+	root *os.Root
+}
+
+func NewFiles(dir string) (*Files, error) {
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return nil, err
+	}
+	return &Files{root: root}, nil
+}
+
+func (f *Files) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /upload", f.upload)
+	mux.HandleFunc("GET /files/{name}", f.download)
+	return mux
+}
+
+// upload saves the files in a multipart/form-data request, one part at
+// a time, as they arrive. Neither the request nor a whole file is ever
+// in memory: only a copy buffer is
+func (f *Files) upload(w http.ResponseWriter, r *http.Request) {
+	// Reading past maxUpload fails, and tells the server to close the
+	// connection, so a client can't make us read forever
+	r.Body = http.MaxBytesReader(w, r.Body, maxUpload)
+
+	// ParseMultipartForm would spool every part to memory or to temp
+	// files before returning; MultipartReader hands us the parts as a
+	// stream
+	mr, err := r.MultipartReader()
+	if err != nil {
+		http.Error(w, "want a multipart/form-data body", http.StatusBadRequest)
+		return
+	}
+
+	var saved []string
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			continue // NextPart skips what we don't read
+		}
+
+		name, n, err := f.save(part.FileName(), part)
+		if err != nil {
+			uploadError(w, err)
+			return
+		}
+		saved = append(saved, fmt.Sprintf("%s (%d bytes)", name, n))
+	}
+
+	if len(saved) == 0 {
+		http.Error(w, `no "file" part`, http.StatusBadRequest)
+		return
+	}
+	w.WriteHeader(http.StatusCreated)
+	fmt.Fprintln(w, "saved", strings.Join(saved, ", "))
+}
+
+// save writes src to a file named after the client's filename. It
+// writes to a temporary name first, so a failed upload never leaves
+// half a file under the real name
+func (f *Files) save(filename string, src io.Reader) (name string, n int64, err error) {
+	// The filename comes from the client: keep only its last element,
+	// whatever separators it uses
+	name = filepath.Base(strings.ReplaceAll(filename, `\`, "/"))
+	if name == "." || name == "/" || strings.HasPrefix(name, ".") {
+		return "", 0, fmt.Errorf("bad filename %q", filename)
+	}
+
+	tmp := name + ".part"
+	dst, err := f.root.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", 0, err
+	}
+	n, err = io.Copy(dst, src)
+	if cerr := dst.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = f.root.Rename(tmp, name)
+	}
+	if err != nil {
+		f.root.Remove(tmp)
+		return "", 0, err
+	}
+	return name, n, nil
+}
+
+// uploadError answers 413 if the upload was too large, and 400 for any
+// other error
+func uploadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, fmt.Sprintf("upload larger than %d MB", maxUpload>>20), http.StatusRequestEntityTooLarge)
+		return
+	}
+	http.Error(w, "upload failed: "+err.Error(), http.StatusBadRequest)
+}
+
+// download serves a file. ServeContent does the hard parts: Range
+// requests, for resuming and seeking, If-Modified-Since, HEAD, and
+// Content-Type
+func (f *Files) download(w http.ResponseWriter, r *http.Request) {
+	name := r.PathValue("name")
+	file, err := f.root.Open(name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil || !info.Mode().IsRegular() {
+		http.NotFound(w, r)
+		return
+	}
+
+	// Ask the browser to save the file, not to show it
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": name}))
+	http.ServeContent(w, r, name, info.ModTime(), file)
+}
+
+// progress counts the bytes read through it, and reports every quarter
+// of total
+type progress struct {
+	r       io.Reader
+	total   int64
+	n       int64
+	quarter int64 // the next quarter to report
+	report  func(n, total int64)
+}
+
+func newProgress(r io.Reader, total int64, report func(n, total int64)) *progress {
+	return &progress{r: r, total: total, quarter: 1, report: report}
+}
+
+func (p *progress) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+	p.n += int64(n)
+	if p.quarter <= 4 && p.n >= p.total*p.quarter/4 {
+		p.report(p.n, p.total)
+		for p.quarter <= 4 && p.n >= p.total*p.quarter/4 {
+			p.quarter++
+		}
+	}
+	return n, err
+}
+
+// upload streams src to url as the "file" part of a form. The body is
+// written through a pipe while it's being sent, so the client doesn't
+// hold the file in memory either
+func upload(url, filename string, src io.Reader) (*http.Response, error) {
+	pr, pw := io.Pipe()
+	mw := multipart.NewWriter(pw)
+	go func() {
+		part, err := mw.CreateFormFile("file", filename)
+		if err == nil {
+			_, err = io.Copy(part, src)
+		}
+		if err == nil {
+			err = mw.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+
+	req, err := http.NewRequest("POST", url, pr)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return http.DefaultClient.Do(req)
+}
+
+// zeros is an endless stream of zero bytes, to upload without a file
+type zeros struct{}
+
+func (zeros) Read(b []byte) (int, error) {
+	clear(b)
+	return len(b), nil
+}
+
+func main() {
+	fmt.Println("Streaming File Uploads and Downloads")
+	fmt.Println("====================================")
+	fmt.Println()
+
+	dir, err := os.MkdirTemp("", "uploads")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	files, err := NewFiles(dir)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	srv := httptest.NewServer(files.Handler())
+	defer srv.Close()
+
+	// Example 1: A streaming upload
+	fmt.Println("1. Uploading 5 MB, with progress:")
+	const size = 5 << 20
+	src := newProgress(io.LimitReader(zeros{}, size), size, func(n, total int64) {
+		fmt.Printf("   sent %3d%%  %7d bytes\n", n*100/total, n)
+	})
+	show(upload(srv.URL+"/upload", "report.bin", src))
+	fmt.Println()
+
+	// Example 2: Too large
+	fmt.Println("2. Uploading 20 MB, past the 8 MB limit:")
+	show(upload(srv.URL+"/upload", "huge.bin", io.LimitReader(zeros{}, 20<<20)))
+	entries, _ := os.ReadDir(dir)
+	fmt.Println("   files left on disk:", len(entries), "(no half-written huge.bin)")
+	fmt.Println()
+
+	// Example 3: Downloads
+	fmt.Println("3. Downloading, whole and in part:")
+	download(srv.URL+"/files/report.bin", "")
+	download(srv.URL+"/files/report.bin", "bytes=0-99")
+	download(srv.URL+"/files/report.bin", "bytes=-1024")
+	download(srv.URL+"/files/report.bin", "bytes=99999999-")
+	fmt.Println()
+
+	// Example 4: Hostile names
+	fmt.Println("4. Names that try to leave the directory:")
+	show(upload(srv.URL+"/upload", "../../escape.txt", strings.NewReader("hi")))
+	download(srv.URL+"/files/..%2f..%2fetc%2fpasswd", "")
+	fmt.Println()
+
+	// Example 5: Why not io.ReadAll
+	fmt.Println("5. Memory allocated to receive a 32 MB body:")
+	fmt.Printf("   io.ReadAll:  %3d MB\n", allocated(slurp, 32<<20)>>20)
+	fmt.Printf("   io.Copy:     %3d MB\n", allocated(stream, 32<<20)>>20)
+}
+
+// slurp reads the whole body into memory, the way small JSON handlers
+// do. Fine for a 1 KB body; for a file, it's a way to run out of memory
+func slurp(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	fmt.Fprintln(w, len(data))
+}
+
+// stream processes the body as it arrives, with a fixed-size buffer
+func stream(w http.ResponseWriter, r *http.Request) {
+	n, _ := io.Copy(io.Discard, r.Body)
+	fmt.Fprintln(w, n)
+}
+
+// allocated returns how many bytes are allocated while h receives a
+// body of size bytes
+func allocated(h http.HandlerFunc, size int64) uint64 {
+	var before, after runtime.MemStats
+	r := httptest.NewRequest("POST", "/", io.LimitReader(zeros{}, size))
+
+	runtime.ReadMemStats(&before)
+	h(httptest.NewRecorder(), r)
+	runtime.ReadMemStats(&after)
+	return after.TotalAlloc - before.TotalAlloc
+}
+
+// download gets url, with a Range header if rng isn't empty, and prints
+// the response, without its body
+func download(url, rng string) {
+	req, _ := http.NewRequest("GET", url, nil)
+	if rng != "" {
+		req.Header.Set("Range", rng)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		fmt.Println("  ", err)
+		return
+	}
+	defer resp.Body.Close()
+	n, _ := io.Copy(io.Discard, resp.Body)
+
+	label := "GET " + url[strings.Index(url, "/files/"):]
+	if rng != "" {
+		label += " Range: " + rng
+	}
+	line := fmt.Sprintf("   %-50s -> %d, %d bytes", label, resp.StatusCode, n)
+	if cr := resp.Header.Get("Content-Range"); cr != "" {
+		line += ", Content-Range: " + cr
+	}
+	fmt.Println(line)
+}
+
+// show prints an upload's response
+func show(resp *http.Response, err error) {
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("   -> %d %s\n", resp.StatusCode, strings.TrimSpace(string(body)))
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newFiles returns Files in a new temporary directory, and the directory
+func newFiles(t *testing.T) (*Files, string) {
+	t.Helper()
+	dir := t.TempDir()
+	f, err := NewFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return f, dir
+}
+
+// form builds a multipart body with a "note" field, and a "file" part
+// for each filename and content pair
+func form(t *testing.T, files ...string) (body *bytes.Buffer, contentType string) {
+	t.Helper()
+	body = new(bytes.Buffer)
+	mw := multipart.NewWriter(body)
+	mw.WriteField("note", "fields that aren't files are skipped")
+	for i := 0; i+1 < len(files); i += 2 {
+		part, err := mw.CreateFormFile("file", files[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.WriteString(part, files[i+1])
+	}
+	mw.Close()
+	return body, mw.FormDataContentType()
+}
+
+// postForm posts form's body to h
+func postForm(t *testing.T, h http.Handler, files ...string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, contentType := form(t, files...)
+	return post(h, body, contentType)
+}
+
+func post(h http.Handler, body io.Reader, contentType string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("POST", "/upload", body)
+	r.Header.Set("Content-Type", contentType)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// list returns the names of the files in dir
+func list(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	return names
+}
+
+func TestUpload(t *testing.T) {
+	f, dir := newFiles(t)
+	w := postForm(t, f.Handler(), "a.txt", "alpha", "b.txt", "bravo!")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want 201; got %d %s", w.Code, w.Body)
+	}
+	if got, want := w.Body.String(), "saved a.txt (5 bytes), b.txt (6 bytes)\n"; got != want {
+		t.Errorf("want %q; got %q", want, got)
+	}
+
+	for name, want := range map[string]string{"a.txt": "alpha", "b.txt": "bravo!"} {
+		got, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil || string(got) != want {
+			t.Errorf("%s: want %q; got %q, %v", name, want, got, err)
+		}
+	}
+}
+
+func TestUploadTooLarge(t *testing.T) {
+	f, dir := newFiles(t)
+	w := postForm(t, f.Handler(), "big.bin", strings.Repeat("x", maxUpload))
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("want 413; got %d %s", w.Code, w.Body)
+	}
+	if names := list(t, dir); len(names) != 0 {
+		t.Errorf("want nothing left on disk; got %v", names)
+	}
+}
+
+func TestUploadBadRequests(t *testing.T) {
+	f, _ := newFiles(t)
+	h := f.Handler()
+
+	if w := post(h, strings.NewReader(`{"a":1}`), "application/json"); w.Code != http.StatusBadRequest {
+		t.Errorf("not multipart: want 400; got %d", w.Code)
+	}
+	if w := postForm(t, h); w.Code != http.StatusBadRequest {
+		t.Errorf("no file: want 400; got %d", w.Code)
+	}
+}
+
+func TestUploadFilenames(t *testing.T) {
+	tests := []struct {
+		filename string
+		want     string // the saved name, or "" for a rejected upload
+	}{
+		{"report.pdf", "report.pdf"},
+		{"../../escape.txt", "escape.txt"},
+		{"/etc/cron.d/job", "job"},
+		{`..\..\windows.ini`, "windows.ini"},
+		{".bashrc", ""},
+		{"..", ""},
+		{"dir/", "dir"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.filename, func(t *testing.T) {
+			f, dir := newFiles(t)
+			w := postForm(t, f.Handler(), tt.filename, "data")
+			names := list(t, dir)
+
+			if tt.want == "" {
+				if w.Code != http.StatusBadRequest || len(names) != 0 {
+					t.Errorf("want 400 and no file; got %d, %v", w.Code, names)
+				}
+				return
+			}
+			if w.Code != http.StatusCreated || len(names) != 1 || names[0] != tt.want {
+				t.Errorf("want 201 and %q; got %d, %v", tt.want, w.Code, names)
+			}
+		})
+	}
+}
+
+func TestDownload(t *testing.T) {
+	f, dir := newFiles(t)
+	content := "0123456789abcdefghij"
+	if err := os.WriteFile(filepath.Join(dir, "data.txt"), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	modified := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+
+	tests := []struct {
+		name    string
+		method  string
+		path    string
+		headers []string
+		code    int
+		body    string
+	}{
+		{"whole", "GET", "/files/data.txt", nil, http.StatusOK, content},
+		{"range", "GET", "/files/data.txt", []string{"Range", "bytes=2-5"}, http.StatusPartialContent, "2345"},
+		{"suffix", "GET", "/files/data.txt", []string{"Range", "bytes=-3"}, http.StatusPartialContent, "hij"},
+		{"open-ended", "GET", "/files/data.txt", []string{"Range", "bytes=15-"}, http.StatusPartialContent, "fghij"},
+		{"unsatisfiable", "GET", "/files/data.txt", []string{"Range", "bytes=100-"}, http.StatusRequestedRangeNotSatisfiable, ""},
+		{"not modified", "GET", "/files/data.txt", []string{"If-Modified-Since", modified}, http.StatusNotModified, ""},
+		{"head", "HEAD", "/files/data.txt", nil, http.StatusOK, ""},
+		{"missing", "GET", "/files/nope.txt", nil, http.StatusNotFound, ""},
+		{"traversal", "GET", "/files/..%2f..%2fetc%2fpasswd", nil, http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			for i := 0; i+1 < len(tt.headers); i += 2 {
+				r.Header.Set(tt.headers[i], tt.headers[i+1])
+			}
+			w := httptest.NewRecorder()
+			f.Handler().ServeHTTP(w, r)
+
+			if w.Code != tt.code {
+				t.Fatalf("want %d; got %d %s", tt.code, w.Code, w.Body)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("want body %q; got %q", tt.body, w.Body)
+			}
+		})
+	}
+}
+
+func TestDownloadHeaders(t *testing.T) {
+	f, dir := newFiles(t)
+	os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("hello"), 0o644)
+
+	w := httptest.NewRecorder()
+	f.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/files/notes.txt", nil))
+
+	want := map[string]string{
+		"Content-Type":        "text/plain; charset=utf-8",
+		"Content-Length":      "5",
+		"Accept-Ranges":       "bytes",
+		"Content-Disposition": "attachment; filename=notes.txt",
+	}
+	for name, v := range want {
+		if got := w.Header().Get(name); got != v {
+			t.Errorf("%s: want %q; got %q", name, v, got)
+		}
+	}
+	if w.Header().Get("Last-Modified") == "" {
+		t.Error("want a Last-Modified header")
+	}
+}
+
+func TestProgress(t *testing.T) {
+	var reports []int64
+	p := newProgress(strings.NewReader(strings.Repeat("x", 100)), 100, func(n, total int64) {
+		reports = append(reports, n)
+	})
+
+	buf := make([]byte, 10)
+	for {
+		if _, err := p.Read(buf); err == io.EOF {
+			break
+		}
+	}
+	// Reports land on the first read at or past each quarter
+	if want := []int64{30, 50, 80, 100}; !slices.Equal(reports, want) {
+		t.Errorf("want reports at %v; got %v", want, reports)
+	}
+
+	// One read past two quarters makes one report, and the last one
+	// comes at the end, even when total doesn't divide by 4
+	reports = nil
+	p = newProgress(strings.NewReader("0123456789"), 10, func(n, total int64) {
+		reports = append(reports, n)
+	})
+	buf = make([]byte, 6)
+	for {
+		if _, err := p.Read(buf); err == io.EOF {
+			break
+		}
+	}
+	if want := []int64{6, 10}; !slices.Equal(reports, want) {
+		t.Errorf("want reports at %v; got %v", want, reports)
+	}
+}
+
+func TestStreamingClient(t *testing.T) {
+	f, dir := newFiles(t)
+	srv := httptest.NewServer(f.Handler())
+	defer srv.Close()
+
+	const size = 3 << 20
+	resp, err := upload(srv.URL+"/upload", "zeros.bin", io.LimitReader(zeros{}, size))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("want 201; got %d", resp.StatusCode)
+	}
+	info, err := os.Stat(filepath.Join(dir, "zeros.bin"))
+	if err != nil || info.Size() != size {
+		t.Errorf("want a %d-byte file; got %v, %v", size, info, err)
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// assets holds the templates and static files, compiled into the
+// binary. The binary is all a deployment needs: no files to copy next
+// to it, and no paths that work only from one directory
+//
+//go:embed templates static
+var assets embed.FS
+
+// funcs are the functions the templates can call. They must be added
+// before parsing, because the parser checks every name it sees
+var funcs = template.FuncMap{
+	"date": func(t time.Time) string { return t.Format("Jan 2, 2006") },
+}
+
+// parseTemplates parses one template set per page: the layout, the
+// partials, and the page. Each page defines "title" and "content"; in
+// one shared set, the last page parsed would win for every page
+func parseTemplates(fsys fs.FS) (map[string]*template.Template, error) {
+	pages, err := fs.Glob(fsys, "templates/pages/*.html")
+	if err != nil {
+		return nil, err
+	}
+
+	sets := make(map[string]*template.Template)
+	for _, page := range pages {
+		t, err := template.New("").Funcs(funcs).ParseFS(fsys,
+			"templates/layout.html", "templates/partials/*.html", page)
+		if err != nil {
+			return nil, err
+		}
+		sets[strings.TrimSuffix(path.Base(page), ".html")] = t
+	}
+	return sets, nil
+}
+
+type Post struct {
+	ID        int
+	Title     string
+	Author    string
+	Body      string
+	Published time.Time
+	Comments  []Comment
+}
+
+type Comment struct {
+	Author  string
+	Website string
+	Text    string
+}
+
+// view is the data every page gets. Path is for the nav, to mark the
+// current page
+type view struct {
+	Path  string
+	Posts []Post
+	Post  Post
+}
+
+// App is a small blog
+type App struct {
+	fsys  fs.FS
+	pages map[string]*template.Template
+	posts []Post
+}
+
+// NewApp parses the templates in fsys, which is assets in the program.
+// Parsing at startup means that a broken template stops the program
+// before it serves anything, not on the first request for its page
+func NewApp(fsys fs.FS, posts []Post) (*App, error) {
+	pages, err := parseTemplates(fsys)
+	if err != nil {
+		return nil, err
+	}
+	return &App{fsys: fsys, pages: pages, posts: posts}, nil
+}
+
+func (a *App) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", a.home)
+	mux.HandleFunc("GET /about", a.about)
+	mux.HandleFunc("GET /posts/{id}", a.post)
+	mux.HandleFunc("/", a.notFound)
+
+	// Serve the embedded static directory at /static/. fs.Sub strips
+	// the "static/" prefix the files have inside assets
+	static, _ := fs.Sub(a.fsys, "static")
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	return mux
+}
+
+// render executes page into a buffer first. If a template fails halfway,
+// nothing has been sent yet, and the client gets a clean 500 instead of
+// half a page with a 200
+func (a *App) render(w http.ResponseWriter, status int, page string, v view) {
+	t, ok := a.pages[page]
+	if !ok {
+		http.Error(w, "no page "+page, http.StatusInternalServerError)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, "layout", v); err != nil {
+		http.Error(w, "template error", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	buf.WriteTo(w)
+}
+
+func (a *App) home(w http.ResponseWriter, r *http.Request) {
+	a.render(w, http.StatusOK, "home", view{Path: r.URL.Path, Posts: a.posts})
+}
+
+func (a *App) about(w http.ResponseWriter, r *http.Request) {
+	a.render(w, http.StatusOK, "about", view{Path: r.URL.Path})
+}
+
+func (a *App) post(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(r.PathValue("id"))
+	for _, p := range a.posts {
+		if p.ID == id {
+			a.render(w, http.StatusOK, "post", view{Path: r.URL.Path, Post: p})
+			return
+		}
+	}
+	a.notFound(w, r)
+}
+
+func (a *App) notFound(w http.ResponseWriter, r *http.Request) {
+	a.render(w, http.StatusNotFound, "notfound", view{Path: r.URL.Path})
+}
+
+var posts = []Post{
+	{
+		ID: 1, Title: "Embedding files", Author: "gopher",
+		Body:      "One binary, with every template inside.",
+		Published: time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC),
+		Comments: []Comment{
+			{Author: "ada", Website: "https://example.com/ada", Text: "Nice & tidy."},
+		},
+	},
+	{
+		ID: 2, Title: `Escaping </script> & "quotes"`, Author: "gopher",
+		Body:      "Look at the comments.",
+		Published: time.Date(2025, 4, 1, 0, 0, 0, 0, time.UTC),
+		Comments: []Comment{
+			{
+				Author:  `"><img src=x onerror=alert(1)>`,
+				Website: "javascript:alert(document.cookie)",
+				Text:    `<script>alert("hi")</script>`,
+			},
+		},
+	},
+}
+
+func main() {
+	fmt.Println("Embedded Assets and html/template")
+	fmt.Println("=================================")
+	fmt.Println()
+
+	app, err := NewApp(assets, posts)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	h := app.Handler()
+
+	// Example 1: What's embedded
+	fmt.Println("1. Files embedded in the binary:")
+	fs.WalkDir(assets, ".", func(name string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			fmt.Println("  ", name)
+		}
+		return err
+	})
+	fmt.Println()
+
+	// Example 2: A page
+	fmt.Println("2. GET /, the layout, the nav partial, and the home page:")
+	printBody(h, "/")
+	fmt.Println()
+
+	// Example 3: Escaping
+	fmt.Println("3. Hostile input, escaped for where it lands:")
+	body := get(h, "/posts/2")
+	for _, line := range strings.Split(body, "\n") {
+		line = strings.TrimSpace(line)
+		if strings.Contains(line, "alert") || strings.HasPrefix(line, "<script>const") {
+			fmt.Println("  ", line)
+		}
+	}
+	fmt.Println()
+
+	// Example 4: Static files and 404s
+	fmt.Println("4. Static files, and a 404 in the layout:")
+	for _, p := range []string{"/static/style.css", "/static/app.js", "/static/missing.css", "/nowhere"} {
+		w := serve(h, p)
+		fmt.Printf("   GET %-20s -> %d %s\n", p, w.Code, w.Header().Get("Content-Type"))
+	}
+}
+
+func serve(h http.Handler, path string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func get(h http.Handler, path string) string {
+	return serve(h, path).Body.String()
+}
+
+// printBody prints the response body for path, three spaces in
+func printBody(h http.Handler, path string) {
+	for _, line := range strings.Split(strings.TrimSpace(get(h, path)), "\n") {
+		fmt.Println("  ", line)
+	}
+}
@@ -0,0 +1,191 @@
+package main
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/inancgumus/learngo/internal/golden"
+)
+
+func newApp(t *testing.T) http.Handler {
+	t.Helper()
+	app, err := NewApp(assets, posts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app.Handler()
+}
+
+func TestEmbedded(t *testing.T) {
+	for _, name := range []string{"templates/layout.html", "templates/partials/nav.html", "static/style.css"} {
+		if _, err := fs.Stat(assets, name); err != nil {
+			t.Errorf("want %s embedded: %v", name, err)
+		}
+	}
+
+	pages, err := parseTemplates(assets)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, page := range []string{"home", "about", "post", "notfound"} {
+		if pages[page] == nil {
+			t.Errorf("want a template set for %s", page)
+		}
+	}
+}
+
+func TestPages(t *testing.T) {
+	h := newApp(t)
+
+	tests := []struct {
+		path  string
+		code  int
+		wants []string
+	}{
+		{"/", http.StatusOK, []string{
+			"<title>Home · Gopher Blog</title>",
+			`<a href="/" aria-current="page">Home</a>`,
+			`<a href="/posts/1">Embedding files</a>, Mar 14, 2025`,
+		}},
+		{"/about", http.StatusOK, []string{
+			"<title>About · Gopher Blog</title>",
+			`<a href="/about" aria-current="page">About</a>`,
+		}},
+		{"/posts/1", http.StatusOK, []string{
+			"<h1>Embedding files</h1>",
+			"<h2>1 comments</h2>",
+			`<a href="https://example.com/ada" title="ada">ada</a>`,
+			"<p>Nice &amp; tidy.</p>",
+		}},
+		{"/posts/99", http.StatusNotFound, []string{"<title>Not found · Gopher Blog</title>", "nothing at /posts/99"}},
+		{"/nowhere", http.StatusNotFound, []string{"nothing at /nowhere"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := serve(h, tt.path)
+			if w.Code != tt.code {
+				t.Fatalf("want %d; got %d", tt.code, w.Code)
+			}
+			if got := w.Header().Get("Content-Type"); got != "text/html; charset=utf-8" {
+				t.Errorf("want HTML; got %q", got)
+			}
+			for _, want := range tt.wants {
+				if !strings.Contains(w.Body.String(), want) {
+					t.Errorf("want %q in:\n%s", want, w.Body)
+				}
+			}
+		})
+	}
+}
+
+// TestSnapshots compares each rendered page with a file in
+// testdata/pages. TestPages checks a few lines of each; a snapshot shows
+// a change anywhere in the layout, the partials, or the escaping. After
+// changing a template on purpose, run: go test -update
+func TestSnapshots(t *testing.T) {
+	h := newApp(t)
+	pages := map[string]string{
+		"home":     "/",
+		"about":    "/about",
+		"post":     "/posts/1",
+		"escaping": "/posts/2",
+		"notfound": "/nowhere",
+	}
+	for name, path := range pages {
+		t.Run(name, func(t *testing.T) {
+			golden.Assert(t, "pages/"+name+".html", serve(h, path).Body.Bytes())
+		})
+	}
+}
+
+func TestEscaping(t *testing.T) {
+	body := get(newApp(t), "/posts/2")
+
+	tests := []struct {
+		context string
+		want    string
+		never   string
+	}{
+		{"HTML text", "<p>&lt;script&gt;alert(&#34;hi&#34;)&lt;/script&gt;</p>", `<script>alert("hi")`},
+		{"attribute", `title="&#34;&gt;&lt;img src=x onerror=alert(1)&gt;"`, "<img src=x"},
+		{"URL", `href="#ZgotmplZ"`, "javascript:"},
+		{"JavaScript", `title: "Escaping \u003c/script\u003e \u0026 \"quotes\""`, "Escaping </script>"},
+		{"<title>", "<title>Escaping &lt;/script&gt; &amp; &#34;quotes&#34; · Gopher Blog</title>", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.context, func(t *testing.T) {
+			if !strings.Contains(body, tt.want) {
+				t.Errorf("want %s in:\n%s", tt.want, body)
+			}
+			if tt.never != "" && strings.Contains(body, tt.never) {
+				t.Errorf("want no %s in the page", tt.never)
+			}
+		})
+	}
+}
+
+func TestStatic(t *testing.T) {
+	h := newApp(t)
+
+	tests := []struct {
+		path        string
+		code        int
+		contentType string
+	}{
+		{"/static/style.css", http.StatusOK, "text/css; charset=utf-8"},
+		{"/static/app.js", http.StatusOK, "text/javascript; charset=utf-8"},
+		{"/static/missing.css", http.StatusNotFound, "text/plain; charset=utf-8"},
+		// Only the static directory is served, not the templates
+		{"/static/templates/layout.html", http.StatusNotFound, "text/plain; charset=utf-8"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := serve(h, tt.path)
+			if w.Code != tt.code {
+				t.Fatalf("want %d; got %d", tt.code, w.Code)
+			}
+			if tt.contentType != "" && w.Header().Get("Content-Type") != tt.contentType {
+				t.Errorf("want %q; got %q", tt.contentType, w.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+func TestRenderError(t *testing.T) {
+	// A page that fails after writing part of its output. index is
+	// out of range, which is an error only when it runs
+	fsys := fstest.MapFS{
+		"templates/layout.html":      {Data: []byte(`{{define "layout"}}<p>start</p>{{template "content" .}}{{end}}`)},
+		"templates/partials/x.html":  {Data: []byte(`{{define "x"}}{{end}}`)},
+		"templates/pages/home.html":  {Data: []byte(`{{define "content"}}{{index .Posts 5}}{{end}}`)},
+		"templates/pages/about.html": {Data: []byte(`{{define "content"}}fine{{end}}`)},
+		"static/app.js":              {Data: []byte("")},
+	}
+	app, err := NewApp(fsys, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := app.Handler()
+
+	w := serve(h, "/")
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "start") {
+		t.Errorf("want a clean 500, with no half page; got %d %q", w.Code, w.Body)
+	}
+	if w := serve(h, "/about"); w.Code != http.StatusOK || w.Body.String() != "<p>start</p>fine" {
+		t.Errorf("want the other page unaffected; got %d %q", w.Code, w.Body)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/layout.html":     {Data: []byte(`{{define "layout"}}{{end}}`)},
+		"templates/partials/x.html": {Data: []byte(`{{define "x"}}{{end}}`)},
+		"templates/pages/home.html": {Data: []byte(`{{define "content"}}{{nosuchfunc .}}{{end}}`)},
+	}
+	if _, err := NewApp(fsys, nil); err == nil || !strings.Contains(err.Error(), "nosuchfunc") {
+		t.Errorf("want the unknown function reported at startup; got %v", err)
+	}
+}
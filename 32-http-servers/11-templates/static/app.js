@@ -0,0 +1,4 @@
+// Marks external links, to show that scripts are served from the binary too
+for (const a of document.querySelectorAll("a[href^='http']")) {
+  a.rel = "noopener";
+}
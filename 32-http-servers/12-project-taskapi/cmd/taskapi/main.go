@@ -0,0 +1,77 @@
+// Command taskapi serves the task API until it gets SIGINT or SIGTERM.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/inancgumus/learngo/32-http-servers/12-project-taskapi/internal/api"
+	"github.com/inancgumus/learngo/32-http-servers/12-project-taskapi/internal/task"
+)
+
+func main() {
+	if err := run(os.Args[1:]); err != nil {
+		fmt.Fprintln(os.Stderr, "taskapi:", err)
+		os.Exit(1)
+	}
+}
+
+// run is main, with errors to return instead of exits, so that every
+// defer runs
+func run(args []string) error {
+	fs := flag.NewFlagSet("taskapi", flag.ContinueOnError)
+	addr := fs.String("addr", "localhost:8080", "address to listen on")
+	timeout := fs.Duration("timeout", 2*time.Second, "how long each request may take")
+	latency := fs.Duration("latency", 0, "delay every store call by this much, to try -timeout")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	log := slog.New(slog.NewTextHandler(os.Stderr, nil))
+	repo := task.NewMemory()
+	repo.Latency = *latency
+
+	srv := &http.Server{
+		Addr:              *addr,
+		Handler:           api.New(repo, log, *timeout).Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		ReadTimeout:       10 * time.Second,
+		WriteTimeout:      *timeout + 5*time.Second,
+		IdleTimeout:       time.Minute,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errc := make(chan error, 1)
+	go func() {
+		log.Info("listening", "addr", *addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		return err // the listener failed: the port is taken, say
+	case <-ctx.Done():
+	}
+	stop() // a second Ctrl+C kills the process at once
+
+	log.Info("shutting down")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		return err
+	}
+	if err := <-errc; !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
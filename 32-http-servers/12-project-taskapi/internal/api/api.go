@@ -0,0 +1,207 @@
+// Package api serves the task API over HTTP. It turns requests into
+// calls on a task.Repository, and the results and errors into JSON
+// responses.
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/inancgumus/learngo/32-http-servers/12-project-taskapi/internal/task"
+)
+
+// Server holds the handlers' dependencies.
+type Server struct {
+	repo    task.Repository
+	log     *slog.Logger
+	timeout time.Duration
+}
+
+// New returns a Server that stores tasks in repo, and gives each
+// request timeout to finish.
+func New(repo task.Repository, log *slog.Logger, timeout time.Duration) *Server {
+	return &Server{repo: repo, log: log, timeout: timeout}
+}
+
+// Handler returns the API's routes, wrapped in its middleware.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", s.health)
+	mux.HandleFunc("GET /tasks", s.list)
+	mux.HandleFunc("POST /tasks", s.create)
+	mux.HandleFunc("GET /tasks/{id}", s.get)
+	mux.HandleFunc("PATCH /tasks/{id}", s.update)
+	mux.HandleFunc("DELETE /tasks/{id}", s.delete)
+
+	return Chain(
+		Logging(s.log), // outermost, so it logs the 500 that Recover sends
+		Recover(s.log),
+		CSRF(),
+		Timeout(s.timeout),
+	)(mux)
+}
+
+func (s *Server) health(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+func (s *Server) list(w http.ResponseWriter, r *http.Request) {
+	f := task.Filter{Status: task.Status(r.URL.Query().Get("status"))}
+	if f.Status != "" && f.Status != task.Todo && f.Status != task.Doing && f.Status != task.Done {
+		writeError(w, http.StatusBadRequest, "status must be todo, doing, or done")
+		return
+	}
+
+	tasks, err := s.repo.List(r.Context(), f)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, tasks)
+}
+
+// createRequest is what a client sends to create a task. Status and
+// Priority are pointers, so that leaving them out is different from
+// sending a bad value: left out, they get defaults.
+type createRequest struct {
+	Title    string       `json:"title"`
+	Status   *task.Status `json:"status"`
+	Priority *int         `json:"priority"`
+}
+
+func (s *Server) create(w http.ResponseWriter, r *http.Request) {
+	var req createRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	t := task.Task{Title: req.Title, Status: task.Todo, Priority: 3}
+	if req.Status != nil {
+		t.Status = *req.Status
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if err := t.Validate(); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	t, err := s.repo.Create(r.Context(), t)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/tasks/%d", t.ID))
+	writeJSON(w, http.StatusCreated, t)
+}
+
+func (s *Server) get(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	t, err := s.repo.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+// updateRequest is a PATCH: only the fields a client sends change.
+type updateRequest struct {
+	Title    *string      `json:"title"`
+	Status   *task.Status `json:"status"`
+	Priority *int         `json:"priority"`
+}
+
+func (s *Server) update(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	var req updateRequest
+	if err := readJSON(w, r, &req); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	t, err := s.repo.Get(r.Context(), id)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	if req.Title != nil {
+		t.Title = *req.Title
+	}
+	if req.Status != nil {
+		t.Status = *req.Status
+	}
+	if req.Priority != nil {
+		t.Priority = *req.Priority
+	}
+	if err := t.Validate(); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+
+	t, err = s.repo.Update(r.Context(), t)
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, t)
+}
+
+func (s *Server) delete(w http.ResponseWriter, r *http.Request) {
+	id, err := taskID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.repo.Delete(r.Context(), id); err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// taskID reads the {id} path value. The pattern matches any segment,
+// so it may not be a number.
+func taskID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil || id < 1 {
+		return 0, errors.New("id must be a positive number")
+	}
+	return id, nil
+}
+
+// fail answers with the status err calls for. Errors the client can't
+// act on are logged, and hidden behind a plain 500.
+func (s *Server) fail(w http.ResponseWriter, r *http.Request, err error) {
+	var invalid *task.ValidationError
+	switch {
+	case errors.As(err, &invalid):
+		writeJSON(w, http.StatusUnprocessableEntity, errorBody{Error: "invalid task", Fields: invalid.Fields})
+	case errors.Is(err, task.ErrNotFound):
+		writeError(w, http.StatusNotFound, err.Error())
+	case errors.Is(err, context.DeadlineExceeded):
+		writeError(w, http.StatusServiceUnavailable, "request timed out")
+	case errors.Is(err, context.Canceled):
+		// The client is gone: there's no one to answer
+	default:
+		s.log.Error("request failed", "method", r.Method, "path", r.URL.Path, "err", err)
+		writeError(w, http.StatusInternalServerError, "internal error")
+	}
+}
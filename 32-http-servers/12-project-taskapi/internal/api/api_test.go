@@ -0,0 +1,208 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/32-http-servers/12-project-taskapi/internal/task"
+)
+
+var quiet = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// newServer returns a handler over a store with two tasks: 1 is todo,
+// and 2 is done
+func newServer(t *testing.T) (http.Handler, *task.Memory) {
+	t.Helper()
+	repo := task.NewMemory()
+	ctx := context.Background()
+	repo.Create(ctx, task.Task{Title: "write the API", Status: task.Todo, Priority: 2})
+	repo.Create(ctx, task.Task{Title: "design it", Status: task.Done, Priority: 1})
+	return New(repo, quiet, time.Second).Handler(), repo
+}
+
+// serve sends a request, with headers as name, value pairs
+func serve(h http.Handler, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	var r *http.Request
+	if body == "" {
+		r = httptest.NewRequest(method, path, nil)
+	} else {
+		r = httptest.NewRequest(method, path, strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		r.Header.Set(headers[i], headers[i+1])
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+func TestHandlers(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		code   int
+		want   string // a substring of the response body
+	}{
+		// Reading
+		{"list", "GET", "/tasks", "", http.StatusOK, `"title":"write the API"`},
+		{"list by status", "GET", "/tasks?status=done", "", http.StatusOK, `[{"id":2,`},
+		{"list by a bad status", "GET", "/tasks?status=later", "", http.StatusBadRequest, "status must be"},
+		{"get", "GET", "/tasks/1", "", http.StatusOK, `"id":1,"title":"write the API","status":"todo","priority":2`},
+		{"get missing", "GET", "/tasks/99", "", http.StatusNotFound, `{"error":"task not found"}`},
+		{"get a bad id", "GET", "/tasks/abc", "", http.StatusBadRequest, "id must be a positive number"},
+		{"get id zero", "GET", "/tasks/0", "", http.StatusBadRequest, "id must be a positive number"},
+
+		// Creating
+		{"create with defaults", "POST", "/tasks", `{"title":"ship it"}`, http.StatusCreated, `"id":3,"title":"ship it","status":"todo","priority":3`},
+		{"create with every field", "POST", "/tasks", `{"title":"ship it","status":"doing","priority":5}`, http.StatusCreated, `"status":"doing","priority":5`},
+		{"create invalid", "POST", "/tasks", `{"title":"","priority":0}`, http.StatusUnprocessableEntity, `"fields":{"priority":"must be between 1 and 5","title":"is required"}`},
+		{"create with an unknown field", "POST", "/tasks", `{"title":"x","owner":"me"}`, http.StatusBadRequest, `unknown field \"owner\"`},
+		{"create with a wrong type", "POST", "/tasks", `{"title":"x","priority":"high"}`, http.StatusBadRequest, "priority must be a int"},
+		{"create with bad JSON", "POST", "/tasks", `{"title":`, http.StatusBadRequest, "bad JSON"},
+		{"create with two values", "POST", "/tasks", `{"title":"a"}{"title":"b"}`, http.StatusBadRequest, "single JSON value"},
+		{"create with no body", "POST", "/tasks", "", http.StatusBadRequest, "body is empty"},
+
+		// Updating
+		{"update one field", "PATCH", "/tasks/1", `{"status":"doing"}`, http.StatusOK, `"title":"write the API","status":"doing","priority":2`},
+		{"update invalid", "PATCH", "/tasks/1", `{"priority":10}`, http.StatusUnprocessableEntity, `"priority":"must be between 1 and 5"`},
+		{"update missing", "PATCH", "/tasks/99", `{"status":"done"}`, http.StatusNotFound, "task not found"},
+
+		// Deleting
+		{"delete", "DELETE", "/tasks/2", "", http.StatusNoContent, ""},
+		{"delete missing", "DELETE", "/tasks/99", "", http.StatusNotFound, "task not found"},
+
+		// The mux
+		{"wrong method", "PUT", "/tasks/1", `{}`, http.StatusMethodNotAllowed, ""},
+		{"health", "GET", "/healthz", "", http.StatusOK, `{"status":"ok"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newServer(t)
+			w := serve(h, tt.method, tt.path, tt.body)
+
+			if w.Code != tt.code {
+				t.Fatalf("want %d; got %d %s", tt.code, w.Code, w.Body)
+			}
+			if !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("want %s in the body; got %s", tt.want, w.Body)
+			}
+			if w.Code != http.StatusNoContent && w.Code != http.StatusMethodNotAllowed {
+				if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+					t.Errorf("want JSON; got %q", ct)
+				}
+			}
+		})
+	}
+}
+
+func TestCreateLocation(t *testing.T) {
+	h, repo := newServer(t)
+	w := serve(h, "POST", "/tasks", `{"title":"ship it"}`)
+	if loc := w.Header().Get("Location"); loc != "/tasks/3" {
+		t.Errorf("want Location /tasks/3; got %q", loc)
+	}
+
+	var got task.Task
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	stored, err := repo.Get(context.Background(), 3)
+	if err != nil || stored.Title != got.Title || !stored.CreatedAt.Equal(got.CreatedAt) {
+		t.Errorf("want the response to match the store; got %+v and %+v, %v", got, stored, err)
+	}
+}
+
+func TestDeleteThenGet(t *testing.T) {
+	h, _ := newServer(t)
+	serve(h, "DELETE", "/tasks/1", "")
+	if w := serve(h, "GET", "/tasks/1", ""); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 after delete; got %d", w.Code)
+	}
+}
+
+func TestBodyTooLarge(t *testing.T) {
+	h, _ := newServer(t)
+	body := `{"title":"` + strings.Repeat("a", maxBodyBytes) + `"}`
+	w := serve(h, "POST", "/tasks", body)
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "larger than") {
+		t.Errorf("want 400 larger than; got %d %s", w.Code, w.Body)
+	}
+}
+
+// brokenRepo fails every call with err. Handlers depend on the
+// Repository interface, so a test can swap in any failure it wants
+type brokenRepo struct {
+	task.Repository // nil: only the overridden methods are called
+	err             error
+}
+
+func (b brokenRepo) List(context.Context, task.Filter) ([]task.Task, error) { return nil, b.err }
+func (b brokenRepo) Get(context.Context, int64) (task.Task, error)          { return task.Task{}, b.err }
+
+func TestRepositoryErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		code int
+		want string
+	}{
+		{"not found", task.ErrNotFound, http.StatusNotFound, "task not found"},
+		{"timeout", context.DeadlineExceeded, http.StatusServiceUnavailable, "request timed out"},
+		{"wrapped timeout", errors.Join(errors.New("query"), context.DeadlineExceeded), http.StatusServiceUnavailable, "request timed out"},
+		{"anything else", errors.New("connection refused: db:5432"), http.StatusInternalServerError, `{"error":"internal error"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var logs bytes.Buffer
+			log := slog.New(slog.NewTextHandler(&logs, nil))
+			h := New(brokenRepo{err: tt.err}, log, time.Second).Handler()
+
+			w := serve(h, "GET", "/tasks/1", "")
+			if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+				t.Errorf("want %d %s; got %d %s", tt.code, tt.want, w.Code, w.Body)
+			}
+			// Internal details go to the log, never to the client
+			if strings.Contains(w.Body.String(), "5432") {
+				t.Error("want the internal error hidden from the client")
+			}
+			if tt.code == http.StatusInternalServerError && !strings.Contains(logs.String(), "5432") {
+				t.Errorf("want the internal error logged; got %s", logs.String())
+			}
+		})
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		repo := task.NewMemory()
+		repo.Latency = 3 * time.Second
+		h := New(repo, quiet, 2*time.Second).Handler()
+
+		start := time.Now()
+		w := serve(h, "POST", "/tasks", `{"title":"slow"}`)
+		if w.Code != http.StatusServiceUnavailable {
+			t.Errorf("want 503; got %d %s", w.Code, w.Body)
+		}
+		if took := time.Since(start); took != 2*time.Second {
+			t.Errorf("want the request cut at 2s; took %v", took)
+		}
+
+		repo.Latency = time.Second
+		if w := serve(h, "GET", "/tasks", ""); w.Code != http.StatusOK {
+			t.Errorf("want 200 within the timeout; got %d", w.Code)
+		}
+	})
+}
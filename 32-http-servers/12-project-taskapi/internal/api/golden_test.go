@@ -0,0 +1,55 @@
+package api
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/golden"
+)
+
+// dump writes a response the way a client sees it: the status, the
+// headers it reads, and the body, indented
+func dump(w *httptest.ResponseRecorder) []byte {
+	b := fmt.Appendf(nil, "%d\n", w.Code)
+	for _, name := range []string{"Content-Type", "Location"} {
+		if v := w.Header().Get(name); v != "" {
+			b = fmt.Appendf(b, "%s: %s\n", name, v)
+		}
+	}
+	b = append(b, '\n')
+	if w.Body.Len() > 0 {
+		b = append(b, golden.IndentJSON(w.Body.Bytes())...)
+	}
+	return b
+}
+
+// TestResponses compares whole responses with testdata/responses. The
+// substrings in TestHandlers check one field each; these files show a
+// reviewer every field a client gets, and a change to any of them. The
+// times are replaced, since every run creates the tasks anew. After a
+// change on purpose, run: go test -update
+func TestResponses(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+	}{
+		{"list", "GET", "/tasks", ""},
+		{"list_done", "GET", "/tasks?status=done", ""},
+		{"get", "GET", "/tasks/1", ""},
+		{"get_missing", "GET", "/tasks/99", ""},
+		{"create", "POST", "/tasks", `{"title":"ship it","priority":4}`},
+		{"create_invalid", "POST", "/tasks", `{"title":"","status":"later","priority":0}`},
+		{"update", "PATCH", "/tasks/2", `{"status":"doing"}`},
+		{"delete", "DELETE", "/tasks/1", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h, _ := newServer(t)
+			w := serve(h, tt.method, tt.path, tt.body)
+			golden.Assert(t, "responses/"+tt.name+".txt", dump(w), golden.Timestamps)
+		})
+	}
+}
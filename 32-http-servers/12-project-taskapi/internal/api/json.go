@@ -0,0 +1,62 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// maxBodyBytes limits how much of a request body readJSON reads.
+const maxBodyBytes = 1 << 20
+
+// readJSON decodes the request body into dst. It rejects bodies larger
+// than maxBodyBytes, fields dst doesn't have, and anything after the
+// first JSON value.
+func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		var syntax *json.SyntaxError
+		var typ *json.UnmarshalTypeError
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("body is empty")
+		case errors.As(err, &tooLarge):
+			return fmt.Errorf("body is larger than %d bytes", tooLarge.Limit)
+		case errors.As(err, &syntax):
+			return fmt.Errorf("bad JSON at byte %d", syntax.Offset)
+		case errors.As(err, &typ):
+			return fmt.Errorf("%s must be a %s", typ.Field, typ.Type)
+		}
+		return fmt.Errorf("bad JSON: %w", err)
+	}
+	if dec.More() {
+		return errors.New("body must hold a single JSON value")
+	}
+	return nil
+}
+
+// writeJSON sends v as JSON with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// errorBody is the shape of every error response. Fields is only set
+// when a task fails validation.
+type errorBody struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty"`
+}
+
+// writeError sends errors in one shape, so clients can parse them.
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorBody{Error: msg})
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestChainOrder(t *testing.T) {
+	var order []string
+	mark := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				order = append(order, name)
+				next.ServeHTTP(w, r)
+			})
+		}
+	}
+	h := Chain(mark("a"), mark("b"), mark("c"))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		order = append(order, "handler")
+	}))
+
+	serve(h, "GET", "/", "")
+	if got := strings.Join(order, ","); got != "a,b,c,handler" {
+		t.Errorf("want a,b,c,handler; got %s", got)
+	}
+}
+
+func TestLoggingAndRecover(t *testing.T) {
+	var logs bytes.Buffer
+	log := slog.New(slog.NewTextHandler(&logs, nil))
+	h := Chain(Logging(log), Recover(log))(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {
+		panic("boom")
+	}))
+
+	w := serve(h, "GET", "/explode", "")
+	if w.Code != http.StatusInternalServerError || w.Body.String() != "{\"error\":\"internal error\"}\n" {
+		t.Errorf("want a JSON 500; got %d %q", w.Code, w.Body)
+	}
+	for _, want := range []string{"msg=panic", "value=boom", "msg=served", "path=/explode", "status=500"} {
+		if !strings.Contains(logs.String(), want) {
+			t.Errorf("want %s in the log; got:\n%s", want, logs.String())
+		}
+	}
+}
+
+func TestCSRF(t *testing.T) {
+	h, _ := newServer(t)
+
+	tests := []struct {
+		name    string
+		method  string
+		headers []string
+		want    int
+	}{
+		{"cross-site POST", "POST", []string{"Sec-Fetch-Site", "cross-site"}, http.StatusForbidden},
+		{"foreign Origin", "POST", []string{"Origin", "https://evil.example"}, http.StatusForbidden},
+		{"same-origin POST", "POST", []string{"Sec-Fetch-Site", "same-origin"}, http.StatusCreated},
+		{"curl, no browser headers", "POST", nil, http.StatusCreated},
+		{"cross-site GET", "GET", []string{"Sec-Fetch-Site", "cross-site"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, body := "/tasks", `{"title":"x"}`
+			if tt.method == "GET" {
+				body = ""
+			}
+			w := serve(h, tt.method, path, body, tt.headers...)
+			if w.Code != tt.want {
+				t.Fatalf("want %d; got %d %s", tt.want, w.Code, w.Body)
+			}
+			if w.Code == http.StatusForbidden && !strings.Contains(w.Body.String(), `"error":"cross-origin request rejected"`) {
+				t.Errorf("want a JSON error; got %s", w.Body)
+			}
+		})
+	}
+}
+
+func TestTimeoutSetsDeadline(t *testing.T) {
+	var left time.Duration
+	h := Timeout(time.Minute)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		deadline, ok := r.Context().Deadline()
+		if ok {
+			left = time.Until(deadline)
+		}
+	}))
+
+	serve(h, "GET", "/", "")
+	if left <= 59*time.Second || left > time.Minute {
+		t.Errorf("want a deadline about a minute away; got %v", left)
+	}
+}
@@ -0,0 +1,122 @@
+package task
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Memory is a Repository that keeps tasks in a map. Handlers run on
+// many goroutines at once, so every method takes the lock.
+type Memory struct {
+	// Latency, if set, is how long every call takes, like a round trip
+	// to a database. It shows how request timeouts reach the store.
+	Latency time.Duration
+
+	mu     sync.Mutex
+	tasks  map[int64]Task
+	nextID int64
+}
+
+func NewMemory() *Memory {
+	return &Memory{tasks: make(map[int64]Task), nextID: 1}
+}
+
+// wait sleeps for m.Latency, or until ctx ends.
+func (m *Memory) wait(ctx context.Context) error {
+	if m.Latency == 0 {
+		return ctx.Err()
+	}
+	t := time.NewTimer(m.Latency)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// List returns the tasks that match f, ordered by ID.
+func (m *Memory) List(ctx context.Context, f Filter) ([]Task, error) {
+	if err := m.wait(ctx); err != nil {
+		return nil, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tasks := make([]Task, 0, len(m.tasks))
+	for _, t := range m.tasks {
+		if f.Status == "" || t.Status == f.Status {
+			tasks = append(tasks, t)
+		}
+	}
+	slices.SortFunc(tasks, func(a, b Task) int { return cmp.Compare(a.ID, b.ID) })
+	return tasks, nil
+}
+
+func (m *Memory) Get(ctx context.Context, id int64) (Task, error) {
+	if err := m.wait(ctx); err != nil {
+		return Task{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t, ok := m.tasks[id]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	return t, nil
+}
+
+// Create stores t under a new ID, and returns it with its ID and
+// timestamps set.
+func (m *Memory) Create(ctx context.Context, t Task) (Task, error) {
+	if err := m.wait(ctx); err != nil {
+		return Task{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	t.ID = m.nextID
+	m.nextID++
+	t.CreatedAt = time.Now().UTC()
+	t.UpdatedAt = t.CreatedAt
+	m.tasks[t.ID] = t
+	return t, nil
+}
+
+// Update replaces the task with t's ID, and returns it with UpdatedAt
+// set.
+func (m *Memory) Update(ctx context.Context, t Task) (Task, error) {
+	if err := m.wait(ctx); err != nil {
+		return Task{}, err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old, ok := m.tasks[t.ID]
+	if !ok {
+		return Task{}, ErrNotFound
+	}
+	t.CreatedAt = old.CreatedAt
+	t.UpdatedAt = time.Now().UTC()
+	m.tasks[t.ID] = t
+	return t, nil
+}
+
+func (m *Memory) Delete(ctx context.Context, id int64) error {
+	if err := m.wait(ctx); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.tasks[id]; !ok {
+		return ErrNotFound
+	}
+	delete(m.tasks, id)
+	return nil
+}
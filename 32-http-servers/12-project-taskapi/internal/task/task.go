@@ -0,0 +1,100 @@
+// Package task holds the API's domain: what a task is, what makes one
+// valid, and the interface for storing tasks. It knows nothing about
+// HTTP.
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// Status is where a task is on the board.
+type Status string
+
+const (
+	Todo  Status = "todo"
+	Doing Status = "doing"
+	Done  Status = "done"
+)
+
+func (s Status) valid() bool {
+	return s == Todo || s == Doing || s == Done
+}
+
+// MaxTitle is the longest title a task can have, in characters.
+const MaxTitle = 200
+
+// Task is what the API stores, and what it sends as JSON.
+type Task struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Status    Status    `json:"status"`
+	Priority  int       `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Validate reports every problem with t, not only the first, so a
+// client can fix them all in one round trip.
+func (t Task) Validate() error {
+	fields := make(map[string]string)
+
+	switch title := strings.TrimSpace(t.Title); {
+	case title == "":
+		fields["title"] = "is required"
+	case utf8.RuneCountInString(title) > MaxTitle:
+		fields["title"] = fmt.Sprintf("must be at most %d characters", MaxTitle)
+	}
+	if !t.Status.valid() {
+		fields["status"] = `must be "todo", "doing", or "done"`
+	}
+	if t.Priority < 1 || t.Priority > 5 {
+		fields["priority"] = "must be between 1 and 5"
+	}
+
+	if len(fields) > 0 {
+		return &ValidationError{Fields: fields}
+	}
+	return nil
+}
+
+// ValidationError lists the invalid fields of a task, with what's wrong
+// with each.
+type ValidationError struct {
+	Fields map[string]string
+}
+
+func (e *ValidationError) Error() string {
+	var b strings.Builder
+	b.WriteString("invalid task:")
+	for _, name := range slices.Sorted(maps.Keys(e.Fields)) {
+		fmt.Fprintf(&b, " %s %s;", name, e.Fields[name])
+	}
+	return strings.TrimSuffix(b.String(), ";")
+}
+
+// ErrNotFound is returned for an ID that no task has.
+var ErrNotFound = errors.New("task not found")
+
+// Filter narrows a List. Its zero value matches every task.
+type Filter struct {
+	Status Status
+}
+
+// Repository stores tasks. The handlers depend on this interface, not
+// on a database, so tests and the in-memory store can stand in for
+// one. Every method takes the request's context, and gives up when it
+// ends.
+type Repository interface {
+	List(ctx context.Context, f Filter) ([]Task, error)
+	Get(ctx context.Context, id int64) (Task, error)
+	Create(ctx context.Context, t Task) (Task, error)
+	Update(ctx context.Context, t Task) (Task, error)
+	Delete(ctx context.Context, id int64) error
+}
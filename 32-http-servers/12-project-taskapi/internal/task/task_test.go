@@ -0,0 +1,121 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+func TestValidate(t *testing.T) {
+	valid := Task{Title: "write tests", Status: Todo, Priority: 3}
+
+	tests := []struct {
+		name   string
+		edit   func(*Task)
+		fields []string // the invalid fields, or none
+	}{
+		{"valid", func(*Task) {}, nil},
+		{"empty title", func(t *Task) { t.Title = "" }, []string{"title"}},
+		{"blank title", func(t *Task) { t.Title = "   " }, []string{"title"}},
+		{"title at the limit", func(t *Task) { t.Title = strings.Repeat("é", MaxTitle) }, nil},
+		{"title too long", func(t *Task) { t.Title = strings.Repeat("a", MaxTitle+1) }, []string{"title"}},
+		{"unknown status", func(t *Task) { t.Status = "blocked" }, []string{"status"}},
+		{"priority too low", func(t *Task) { t.Priority = 0 }, []string{"priority"}},
+		{"priority too high", func(t *Task) { t.Priority = 6 }, []string{"priority"}},
+		{"everything wrong", func(t *Task) { *t = Task{} }, []string{"priority", "status", "title"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task := valid
+			tt.edit(&task)
+			err := task.Validate()
+
+			if tt.fields == nil {
+				if err != nil {
+					t.Errorf("want valid; got %v", err)
+				}
+				return
+			}
+			var invalid *ValidationError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("want a *ValidationError; got %v", err)
+			}
+			if got := slices.Sorted(maps.Keys(invalid.Fields)); !slices.Equal(got, tt.fields) {
+				t.Errorf("want fields %v; got %v", tt.fields, got)
+			}
+		})
+	}
+}
+
+func TestValidationErrorMessage(t *testing.T) {
+	err := Task{Title: "x", Status: "nope", Priority: 9}.Validate()
+	want := `invalid task: priority must be between 1 and 5; status must be "todo", "doing", or "done"`
+	if err == nil || err.Error() != want {
+		t.Errorf("want %q; got %v", want, err)
+	}
+}
+
+func TestMemory(t *testing.T) {
+	ctx := context.Background()
+	m := NewMemory()
+
+	a, _ := m.Create(ctx, Task{Title: "a", Status: Todo, Priority: 1})
+	b, _ := m.Create(ctx, Task{Title: "b", Status: Done, Priority: 2})
+	if a.ID != 1 || b.ID != 2 || a.CreatedAt.IsZero() {
+		t.Fatalf("want IDs 1 and 2, and timestamps; got %+v, %+v", a, b)
+	}
+
+	all, _ := m.List(ctx, Filter{})
+	done, _ := m.List(ctx, Filter{Status: Done})
+	if len(all) != 2 || all[0].ID != 1 || len(done) != 1 || done[0].ID != 2 {
+		t.Errorf("List: want [a b] and [b]; got %v and %v", all, done)
+	}
+
+	a.Title = "a, renamed"
+	a.CreatedAt = time.Time{} // clients can't change it
+	u, err := m.Update(ctx, a)
+	if err != nil || u.Title != "a, renamed" || u.CreatedAt.IsZero() || u.UpdatedAt.Before(u.CreatedAt) {
+		t.Errorf("Update: got %+v, %v", u, err)
+	}
+
+	if err := m.Delete(ctx, b.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Get(ctx, b.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: want ErrNotFound; got %v", err)
+	}
+	if err := m.Delete(ctx, b.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete twice: want ErrNotFound; got %v", err)
+	}
+	if _, err := m.Update(ctx, Task{ID: 99}); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Update a missing task: want ErrNotFound; got %v", err)
+	}
+}
+
+func TestMemoryHonorsContext(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		m := NewMemory()
+		m.Latency = time.Second
+
+		ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+		defer cancel()
+		start := time.Now()
+		if _, err := m.Create(ctx, Task{Title: "slow"}); !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("want DeadlineExceeded; got %v", err)
+		}
+		if took := time.Since(start); took != 500*time.Millisecond {
+			t.Errorf("want to give up at the deadline; took %v", took)
+		}
+
+		// Nothing was stored
+		tasks, _ := m.List(context.Background(), Filter{})
+		if len(tasks) != 0 {
+			t.Errorf("want no tasks; got %v", tasks)
+		}
+	})
+}
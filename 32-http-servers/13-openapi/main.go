@@ -0,0 +1,370 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inancgumus/learngo/32-http-servers/13-openapi/openapi"
+)
+
+type Role string
+
+const (
+	Admin  Role = "admin"
+	Member Role = "member"
+)
+
+// User is what the API sends back. The doc, format, and enum tags are
+// for the generator; encoding/json ignores them
+type User struct {
+	ID      int       `json:"id" doc:"Assigned by the server"`
+	Name    string    `json:"name"`
+	Email   string    `json:"email" format:"email"`
+	Role    Role      `json:"role" enum:"admin,member"`
+	Created time.Time `json:"created"`
+}
+
+// CreateUser is what a client sends. Role has omitempty, so the spec
+// says it's optional
+type CreateUser struct {
+	Name  string `json:"name" doc:"The display name"`
+	Email string `json:"email" format:"email"`
+	Role  Role   `json:"role,omitempty" enum:"admin,member" doc:"member, if left out"`
+}
+
+type UserList struct {
+	Users []User `json:"users"`
+	Total int    `json:"total"`
+}
+
+// ErrorResponse is the body of every error, on every route
+type ErrorResponse struct {
+	Error  string            `json:"error"`
+	Fields map[string]string `json:"fields,omitempty" doc:"What is wrong with each invalid field"`
+}
+
+// endpoint is a route's documentation and its handler, side by side.
+// The mux and the spec are both built from one list of them, so a
+// route can't be served without being documented
+type endpoint struct {
+	openapi.Route
+	handler http.HandlerFunc
+}
+
+// App is a small user API that describes itself
+type App struct {
+	mu     sync.Mutex
+	users  []User
+	nextID int
+	now    func() time.Time
+
+	spec     *openapi.Document
+	specJSON []byte
+}
+
+// NewApp generates the spec at startup. A route the generator can't
+// describe stops the program before it serves anything
+func NewApp() (*App, error) {
+	a := &App{nextID: 1, now: time.Now}
+	spec, err := openapi.Generate(a.api())
+	if err != nil {
+		return nil, err
+	}
+	specJSON, err := json.MarshalIndent(spec, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	a.spec, a.specJSON = spec, specJSON
+	return a, nil
+}
+
+func (a *App) endpoints() []endpoint {
+	return []endpoint{
+		{openapi.Route{
+			Method: "GET", Path: "/users", Summary: "List users",
+			Params:   []openapi.Param{{Name: "role", In: "query", Doc: "Only users with this role"}},
+			Response: UserList{},
+		}, a.listUsers},
+		{openapi.Route{
+			Method: "POST", Path: "/users", Summary: "Create a user",
+			Request: CreateUser{},
+			Status:  http.StatusCreated, Response: User{},
+			Errors: []int{http.StatusBadRequest, http.StatusUnprocessableEntity},
+		}, a.createUser},
+		{openapi.Route{
+			Method: "GET", Path: "/users/{id}", Summary: "Get a user",
+			Params:   []openapi.Param{{Name: "id", In: "path", Type: 0}},
+			Response: User{},
+			Errors:   []int{http.StatusBadRequest, http.StatusNotFound},
+		}, a.getUser},
+		{openapi.Route{
+			Method: "DELETE", Path: "/users/{id}", Summary: "Delete a user",
+			Params: []openapi.Param{{Name: "id", In: "path", Type: 0}},
+			Status: http.StatusNoContent,
+			Errors: []int{http.StatusBadRequest, http.StatusNotFound},
+		}, a.deleteUser},
+	}
+}
+
+// api is the input to the generator: the endpoints without handlers
+func (a *App) api() openapi.API {
+	api := openapi.API{Title: "Users", Version: "1.0.0", Error: ErrorResponse{}}
+	for _, e := range a.endpoints() {
+		api.Routes = append(api.Routes, e.Route)
+	}
+	return api
+}
+
+func (a *App) Handler() http.Handler {
+	mux := http.NewServeMux()
+	for _, e := range a.endpoints() {
+		mux.HandleFunc(e.Method+" "+e.Path, e.handler)
+	}
+	mux.HandleFunc("GET /openapi.json", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(a.specJSON)
+	})
+	return mux
+}
+
+func (a *App) listUsers(w http.ResponseWriter, r *http.Request) {
+	role := Role(r.URL.Query().Get("role"))
+
+	a.mu.Lock()
+	list := UserList{Users: []User{}}
+	for _, u := range a.users {
+		if role == "" || u.Role == role {
+			list.Users = append(list.Users, u)
+		}
+	}
+	a.mu.Unlock()
+
+	list.Total = len(list.Users)
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (a *App) createUser(w http.ResponseWriter, r *http.Request) {
+	dec := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	var req CreateUser
+	if err := dec.Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: err.Error()})
+		return
+	}
+	if req.Role == "" {
+		req.Role = Member
+	}
+
+	fields := make(map[string]string)
+	if strings.TrimSpace(req.Name) == "" {
+		fields["name"] = "is required"
+	}
+	if !strings.Contains(req.Email, "@") {
+		fields["email"] = "must contain @"
+	}
+	if req.Role != Admin && req.Role != Member {
+		fields["role"] = "must be admin or member"
+	}
+	if len(fields) > 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, ErrorResponse{Error: "invalid user", Fields: fields})
+		return
+	}
+
+	a.mu.Lock()
+	u := User{ID: a.nextID, Name: req.Name, Email: req.Email, Role: req.Role, Created: a.now().UTC()}
+	a.nextID++
+	a.users = append(a.users, u)
+	a.mu.Unlock()
+
+	w.Header().Set("Location", "/users/"+strconv.Itoa(u.ID))
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (a *App) getUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	i := a.index(id)
+	var u User
+	if i >= 0 {
+		u = a.users[i]
+	}
+	a.mu.Unlock()
+
+	if i < 0 {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (a *App) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := userID(w, r)
+	if !ok {
+		return
+	}
+
+	a.mu.Lock()
+	i := a.index(id)
+	if i >= 0 {
+		a.users = slices.Delete(a.users, i, i+1)
+	}
+	a.mu.Unlock()
+
+	if i < 0 {
+		writeJSON(w, http.StatusNotFound, ErrorResponse{Error: "user not found"})
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// index returns where the user with id is in a.users, or -1. The
+// caller holds the lock
+func (a *App) index(id int) int {
+	return slices.IndexFunc(a.users, func(u User) bool { return u.ID == id })
+}
+
+// userID reads the {id} path value, or answers 400
+func userID(w http.ResponseWriter, r *http.Request) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: "id must be a number"})
+		return 0, false
+	}
+	return id, true
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func main() {
+	fmt.Println("Documenting an API with OpenAPI")
+	fmt.Println("===============================")
+	fmt.Println()
+
+	app, err := NewApp()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	h := app.Handler()
+
+	// Example 1: One list, two uses
+	fmt.Println("1. Each endpoint is registered on the mux and documented:")
+	for _, e := range app.endpoints() {
+		fmt.Printf("   %-6s %-12s %s\n", e.Method, e.Path, e.Summary)
+	}
+	fmt.Println()
+
+	// Example 2: A schema from reflection
+	fmt.Println("2. The User schema, read from the struct and its tags:")
+	printJSON(app.spec.Components.Schemas["User"])
+	fmt.Println()
+
+	// Example 3: An operation
+	fmt.Println("3. POST /users: the request body, and every response:")
+	printJSON(app.spec.Paths["/users"]["post"])
+	fmt.Println()
+
+	// Example 4: Serving the spec
+	w := serve(h, "GET", "/openapi.json", "")
+	fmt.Println("4. GET /openapi.json, for Swagger UI, client generators, and linters:")
+	fmt.Printf("   %d %s, %d bytes, %d paths, %d schemas\n", w.Code,
+		w.Header().Get("Content-Type"), w.Body.Len(),
+		len(app.spec.Paths), len(app.spec.Components.Schemas))
+	fmt.Println()
+
+	// Example 5: Checking responses against the spec
+	fmt.Println("5. Real responses, checked against the spec:")
+	for _, c := range []struct{ method, path, body string }{
+		{"POST", "/users", `{"name":"Ada","email":"ada@example.com","role":"admin"}`},
+		{"POST", "/users", `{"name":"","email":"nope"}`},
+		{"GET", "/users", ""},
+		{"GET", "/users/7", ""},
+	} {
+		w := serve(h, c.method, c.path, c.body)
+		result := "matches"
+		if err := check(app.spec, c.method, c.path, w); err != nil {
+			result = err.Error()
+		}
+		fmt.Printf("   %-6s %-9s -> %d, %s\n", c.method, c.path, w.Code, result)
+	}
+	fmt.Println()
+
+	// Example 6: Drift
+	fmt.Println("6. Responses that drifted from the spec:")
+	user := &openapi.Schema{Ref: "#/components/schemas/User"}
+	for _, body := range []string{
+		`{"id":1,"name":"Ada","email":"a@b.c","role":"admin","created":"2025-01-02T03:04:05Z","password":"hunter2"}`,
+		`{"id":1,"name":"Ada","email":"a@b.c","role":"owner","created":"2025-01-02T03:04:05Z"}`,
+		`{"id":"1","name":"Ada","email":"a@b.c","role":"admin","created":"2025-01-02T03:04:05Z"}`,
+		`{"id":1,"name":"Ada","role":"admin","created":"2025-01-02T03:04:05Z"}`,
+	} {
+		fmt.Println("  ", app.spec.Validate(user, []byte(body)))
+	}
+	fmt.Println()
+
+	// Example 7: Routes the generator refuses
+	fmt.Println("7. Routes the generator can't describe:")
+	for _, r := range []openapi.Route{
+		{Method: "GET", Path: "/events", Response: make(chan User)},
+		{Method: "GET", Path: "/users", Params: []openapi.Param{{Name: "id", In: "path"}}},
+		{Method: "POST", Path: "/jobs", Request: struct{ Run func() }{}},
+	} {
+		_, err := openapi.Generate(openapi.API{Routes: []openapi.Route{r}})
+		fmt.Println("  ", err)
+	}
+}
+
+// check validates the body of w against the schema the spec gives for
+// method, path, and w's status. path is a real path; it is matched to
+// the spec's pattern by asking a mux
+func check(spec *openapi.Document, method, path string, w *httptest.ResponseRecorder) error {
+	mux := http.NewServeMux()
+	for pattern := range spec.Paths {
+		mux.Handle(pattern, http.NotFoundHandler())
+	}
+	_, pattern := mux.Handler(httptest.NewRequest(method, path, nil))
+
+	op := spec.Paths[pattern][strings.ToLower(method)]
+	if op == nil {
+		return fmt.Errorf("%s %s is not documented", method, path)
+	}
+	resp, ok := op.Responses[strconv.Itoa(w.Code)]
+	if !ok {
+		return fmt.Errorf("status %d is not documented", w.Code)
+	}
+	if resp.Content == nil {
+		if w.Body.Len() > 0 {
+			return fmt.Errorf("status %d has a body, but the spec says none", w.Code)
+		}
+		return nil
+	}
+	return spec.Validate(resp.Content["application/json"].Schema, w.Body.Bytes())
+}
+
+func serve(h http.Handler, method, path, body string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest(method, path, strings.NewReader(body))
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+	return w
+}
+
+// printJSON prints v as indented JSON, three spaces in
+func printJSON(v any) {
+	b, _ := json.MarshalIndent(v, "   ", "  ")
+	fmt.Println("  ", string(b))
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inancgumus/learngo/32-http-servers/13-openapi/openapi"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/openapi.json")
+
+func newApp(t *testing.T) *App {
+	t.Helper()
+	app, err := NewApp()
+	if err != nil {
+		t.Fatal(err)
+	}
+	app.now = func() time.Time { return time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC) }
+	return app
+}
+
+// TestSpecChanges compares the spec with the one checked in. A change to
+// a type or a route changes the API for every client, so it shows up in
+// the diff of testdata/openapi.json, for a reviewer to see. After a
+// change on purpose, run: go test -update
+func TestSpecChanges(t *testing.T) {
+	app := newApp(t)
+	golden := filepath.Join("testdata", "openapi.json")
+	got := append(app.specJSON, '\n')
+
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("the spec changed; if that's on purpose, run go test -update\ngot:\n%s", got)
+	}
+}
+
+func TestSpecIsServed(t *testing.T) {
+	app := newApp(t)
+	w := serve(app.Handler(), "GET", "/openapi.json", "")
+
+	var doc openapi.Document
+	if err := json.Unmarshal(w.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+	if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("want 200 JSON; got %d %s", w.Code, w.Header().Get("Content-Type"))
+	}
+	if doc.OpenAPI != "3.1.0" || doc.Info.Title != "Users" || len(doc.Paths) != 2 {
+		t.Errorf("got %s %+v with %d paths", doc.OpenAPI, doc.Info, len(doc.Paths))
+	}
+}
+
+// TestEveryOperationIsRouted asks the mux which pattern serves each
+// operation in the spec. It must be the operation's own pattern, not a
+// catch-all or a 404
+func TestEveryOperationIsRouted(t *testing.T) {
+	app := newApp(t)
+	mux := app.Handler().(*http.ServeMux)
+
+	for path, item := range app.spec.Paths {
+		for method := range item {
+			method = strings.ToUpper(method)
+			r := httptest.NewRequest(method, strings.ReplaceAll(path, "{id}", "1"), nil)
+			if _, pattern := mux.Handler(r); pattern != method+" "+path {
+				t.Errorf("%s %s: served by %q", method, path, pattern)
+			}
+		}
+	}
+}
+
+// TestResponsesMatchSpec sends each request the API documents, and
+// checks every response against the spec: its status must be listed,
+// and its body must match the schema for that status
+func TestResponsesMatchSpec(t *testing.T) {
+	app := newApp(t)
+	h := app.Handler()
+
+	steps := []struct {
+		method, path, body string
+		code               int
+	}{
+		{"POST", "/users", `{"name":"Ada","email":"ada@example.com","role":"admin"}`, 201},
+		{"POST", "/users", `{"name":"Bob","email":"bob@example.com"}`, 201},
+		{"POST", "/users", `{"name":"","email":"nope","role":"owner"}`, 422},
+		{"POST", "/users", `{"name":"Eve","email":"e@example.com","admin":true}`, 400},
+		{"GET", "/users", "", 200},
+		{"GET", "/users?role=member", "", 200},
+		{"GET", "/users?role=owner", "", 200},
+		{"GET", "/users/1", "", 200},
+		{"GET", "/users/abc", "", 400},
+		{"DELETE", "/users/1", "", 204},
+		{"GET", "/users/1", "", 404},
+		{"DELETE", "/users/1", "", 404},
+	}
+	for _, s := range steps {
+		w := serve(h, s.method, s.path, s.body)
+		if w.Code != s.code {
+			t.Errorf("%s %s: want %d; got %d %s", s.method, s.path, s.code, w.Code, w.Body)
+			continue
+		}
+		if err := check(app.spec, s.method, s.path, w); err != nil {
+			t.Errorf("%s %s: %d doesn't match the spec: %v", s.method, s.path, w.Code, err)
+		}
+	}
+}
+
+func TestCheckCatchesDrift(t *testing.T) {
+	app := newApp(t)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		code   int
+		body   string
+		want   string
+	}{
+		{"undocumented route", "PUT", "/users/1", 200, `{}`, "PUT /users/1 is not documented"},
+		{"undocumented status", "GET", "/users/1", 500, `{"error":"boom"}`, "status 500 is not documented"},
+		{"a body on a 204", "DELETE", "/users/1", 204, `{}`, "status 204 has a body"},
+		{"a leaked field", "GET", "/users", 200, `{"users":[],"total":0,"next":"abc"}`, "$: next is not documented"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			w.WriteHeader(tt.code)
+			w.Body.WriteString(tt.body)
+
+			err := check(app.spec, tt.method, tt.path, w)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("want %q; got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,225 @@
+// Package openapi generates an OpenAPI 3.1 document from routes declared
+// in Go. Request and response bodies are Go values; their schemas come
+// from reflection over the types and their struct tags.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// API describes a whole API: its title, the body of every error
+// response, and its routes.
+type API struct {
+	Title   string
+	Version string
+	Error   any // a value of the type every error response has, or nil
+	Routes  []Route
+}
+
+// Route describes one operation. Request and Response are values of
+// the body types, usually zero values; only their types matter.
+type Route struct {
+	Method   string
+	Path     string // a ServeMux pattern path, like /users/{id}
+	Summary  string
+	Params   []Param
+	Request  any   // nil: no request body
+	Status   int   // the success status; 0 means 200
+	Response any   // nil: no response body
+	Errors   []int // the error statuses the route can answer with
+}
+
+// Param describes a path or query parameter. Type is a value of the
+// parameter's type. Path parameters that aren't listed are strings.
+type Param struct {
+	Name string
+	In   string // "path" or "query"
+	Doc  string
+	Type any
+}
+
+// Document is an OpenAPI document. Marshal it with encoding/json.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title   string `json:"title"`
+	Version string `json:"version"`
+}
+
+// PathItem maps lowercase methods, like "get", to operations.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Summary     string              `json:"summary,omitempty"`
+	Parameters  []Parameter         `json:"parameters,omitempty"`
+	RequestBody *RequestBody        `json:"requestBody,omitempty"`
+	Responses   map[string]Response `json:"responses"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"`
+	Description string  `json:"description,omitempty"`
+	Required    bool    `json:"required,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas map[string]*Schema `json:"schemas,omitempty"`
+}
+
+// pathParam matches the wildcards in a pattern path.
+var pathParam = regexp.MustCompile(`\{(\w+)(?:\.\.\.)?\}`)
+
+// Generate builds the document for api. It fails on a route that is
+// declared twice, a parameter that isn't in its path, or a type that
+// JSON can't describe, like a channel.
+func Generate(api API) (*Document, error) {
+	g := &generator{schemas: make(map[string]*Schema), types: make(map[string]reflect.Type)}
+	doc := &Document{
+		OpenAPI: "3.1.0",
+		Info:    Info{Title: api.Title, Version: api.Version},
+		Paths:   make(map[string]PathItem),
+	}
+
+	var errSchema *Schema
+	if api.Error != nil {
+		s, err := g.schemaOf(api.Error)
+		if err != nil {
+			return nil, fmt.Errorf("error type: %w", err)
+		}
+		errSchema = s
+	}
+
+	for _, r := range api.Routes {
+		op, err := g.operation(r, errSchema)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", r.Method, r.Path, err)
+		}
+		item := doc.Paths[r.Path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[r.Path] = item
+		}
+		method := strings.ToLower(r.Method)
+		if item[method] != nil {
+			return nil, fmt.Errorf("%s %s: declared twice", r.Method, r.Path)
+		}
+		item[method] = op
+	}
+
+	doc.Components.Schemas = g.schemas
+	return doc, nil
+}
+
+func (g *generator) operation(r Route, errSchema *Schema) (*Operation, error) {
+	op := &Operation{Summary: r.Summary, Responses: make(map[string]Response)}
+
+	params, err := g.parameters(r)
+	if err != nil {
+		return nil, err
+	}
+	op.Parameters = params
+
+	if r.Request != nil {
+		s, err := g.schemaOf(r.Request)
+		if err != nil {
+			return nil, fmt.Errorf("request: %w", err)
+		}
+		op.RequestBody = &RequestBody{Required: true, Content: jsonContent(s)}
+	}
+
+	status := r.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	ok := Response{Description: http.StatusText(status)}
+	if r.Response != nil {
+		s, err := g.schemaOf(r.Response)
+		if err != nil {
+			return nil, fmt.Errorf("response: %w", err)
+		}
+		ok.Content = jsonContent(s)
+	}
+	op.Responses[strconv.Itoa(status)] = ok
+
+	for _, code := range r.Errors {
+		resp := Response{Description: http.StatusText(code)}
+		if errSchema != nil {
+			resp.Content = jsonContent(errSchema)
+		}
+		op.Responses[strconv.Itoa(code)] = resp
+	}
+	return op, nil
+}
+
+// parameters returns the declared parameters, then a string parameter
+// for each path wildcard that wasn't declared.
+func (g *generator) parameters(r Route) ([]Parameter, error) {
+	var inPath []string
+	for _, m := range pathParam.FindAllStringSubmatch(r.Path, -1) {
+		inPath = append(inPath, m[1])
+	}
+
+	var params []Parameter
+	declared := make(map[string]bool)
+	for _, p := range r.Params {
+		if p.In != "path" && p.In != "query" {
+			return nil, fmt.Errorf("parameter %s: in must be path or query, not %q", p.Name, p.In)
+		}
+		if p.In == "path" && !slices.Contains(inPath, p.Name) {
+			return nil, fmt.Errorf("parameter %s: not in the path", p.Name)
+		}
+		var t any = ""
+		if p.Type != nil {
+			t = p.Type
+		}
+		s, err := g.schemaOf(t)
+		if err != nil {
+			return nil, fmt.Errorf("parameter %s: %w", p.Name, err)
+		}
+		params = append(params, Parameter{
+			Name: p.Name, In: p.In, Description: p.Doc,
+			Required: p.In == "path", Schema: s,
+		})
+		declared[p.Name] = true
+	}
+
+	for _, name := range inPath {
+		if !declared[name] {
+			params = append(params, Parameter{
+				Name: name, In: "path", Required: true, Schema: &Schema{Type: "string"},
+			})
+		}
+	}
+	return params, nil
+}
+
+func jsonContent(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
@@ -0,0 +1,259 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/netip"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func schemaJSON(t *testing.T, v any) string {
+	t.Helper()
+	g := &generator{schemas: make(map[string]*Schema), types: make(map[string]reflect.Type)}
+	s, err := g.schemaOf(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+type named struct{}
+
+func TestSchemaTypes(t *testing.T) {
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"bool", false, `{"type":"boolean"}`},
+		{"int", 0, `{"type":"integer","format":"int64"}`},
+		{"int32", int32(0), `{"type":"integer","format":"int32"}`},
+		{"uint8", uint8(0), `{"type":"integer","format":"int32"}`},
+		{"float", 0.0, `{"type":"number"}`},
+		{"string", "", `{"type":"string"}`},
+		{"pointer", new(string), `{"type":"string"}`},
+		{"bytes, as base64", []byte{}, `{"type":"string","format":"byte"}`},
+		{"slice", []string{}, `{"type":"array","items":{"type":"string"}}`},
+		{"array", [2]int{}, `{"type":"array","items":{"type":"integer","format":"int64"}}`},
+		{"map", map[string]bool{}, `{"type":"object","additionalProperties":{"type":"boolean"}}`},
+		{"map with int keys", map[int]bool{}, `{"type":"object","additionalProperties":{"type":"boolean"}}`},
+		{"any", new(any), `{}`},
+		{"time", time.Time{}, `{"type":"string","format":"date-time"}`},
+		{"time pointer", new(time.Time), `{"type":"string","format":"date-time"}`},
+		{"a TextMarshaler", netip.Addr{}, `{"type":"string"}`},
+		{"a json.Marshaler", json.RawMessage{}, `{}`},
+		{"anonymous struct, inline", struct{ A int }{}, `{"type":"object","properties":{"A":{"type":"integer","format":"int64"}},"required":["A"]}`},
+		{"named struct, a ref", named{}, `{"$ref":"#/components/schemas/named"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := schemaJSON(t, tt.v); got != tt.want {
+				t.Errorf("want %s; got %s", tt.want, got)
+			}
+		})
+	}
+}
+
+type Base struct {
+	ID int `json:"id"`
+}
+
+type Meta struct {
+	Tags []string `json:"tags"`
+}
+
+type fields struct {
+	Base                        // promoted
+	Meta      `json:"meta"`     // named, so not promoted
+	Renamed   string            `json:"renamed"`
+	Untagged  string            // uses the field name
+	Skipped   string            `json:"-"`
+	Dash      string            `json:"-,"` // the name is "-"
+	Optional  string            `json:"optional,omitempty"`
+	Zero      time.Time         `json:"zero,omitzero"`
+	Pointer   *int              `json:"pointer"`
+	Described string            `json:"described" doc:"Says what it is" format:"email"`
+	Enum      string            `json:"enum" enum:"a,b,c"`
+	unexport  string            // skipped: not exported
+	Extra     map[string]string `json:"extra,omitempty"`
+}
+
+func TestSchemaFields(t *testing.T) {
+	got := schemaJSON(t, struct{ fields }{})
+	want := `{"type":"object","properties":{` +
+		`"-":{"type":"string"},` +
+		`"Untagged":{"type":"string"},` +
+		`"described":{"type":"string","format":"email","description":"Says what it is"},` +
+		`"enum":{"type":"string","enum":["a","b","c"]},` +
+		`"extra":{"type":"object","additionalProperties":{"type":"string"}},` +
+		`"id":{"type":"integer","format":"int64"},` +
+		`"meta":{"$ref":"#/components/schemas/Meta"},` +
+		`"optional":{"type":"string"},` +
+		`"pointer":{"type":"integer","format":"int64"},` +
+		`"renamed":{"type":"string"},` +
+		`"zero":{"type":"string","format":"date-time"}},` +
+		`"required":["id","meta","renamed","Untagged","-","described","enum"]}`
+	if got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+type Node struct {
+	Name     string `json:"name"`
+	Children []Node `json:"children"`
+}
+
+func TestRecursiveType(t *testing.T) {
+	doc, err := Generate(API{Routes: []Route{{Method: "GET", Path: "/tree", Response: Node{}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := json.Marshal(doc.Components.Schemas["Node"].Properties["children"])
+	if want := `{"type":"array","items":{"$ref":"#/components/schemas/Node"}}`; string(b) != want {
+		t.Errorf("want %s; got %s", want, b)
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	type Thing struct {
+		Name string `json:"name"`
+	}
+	type Problem struct {
+		Message string `json:"message"`
+	}
+	doc, err := Generate(API{
+		Title: "Things", Version: "2", Error: Problem{},
+		Routes: []Route{
+			{Method: "GET", Path: "/things/{id}/parts/{part}", Summary: "Get a part",
+				Params:   []Param{{Name: "part", In: "path", Type: 0}, {Name: "full", In: "query", Type: false}},
+				Response: Thing{}, Errors: []int{404}},
+			{Method: "PUT", Path: "/things/{id}/parts/{part}", Request: Thing{}, Status: 204},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if doc.OpenAPI != "3.1.0" || doc.Info != (Info{"Things", "2"}) {
+		t.Errorf("got %s %+v", doc.OpenAPI, doc.Info)
+	}
+
+	get := doc.Paths["/things/{id}/parts/{part}"]["get"]
+	params, _ := json.Marshal(get.Parameters)
+	wantParams := `[{"name":"part","in":"path","required":true,"schema":{"type":"integer","format":"int64"}},` +
+		`{"name":"full","in":"query","schema":{"type":"boolean"}},` +
+		`{"name":"id","in":"path","required":true,"schema":{"type":"string"}}]`
+	if string(params) != wantParams {
+		t.Errorf("parameters:\nwant %s\ngot  %s", wantParams, params)
+	}
+	if r := get.Responses["404"]; r.Description != "Not Found" || r.Content["application/json"].Schema.Ref != refPrefix+"Problem" {
+		t.Errorf("want 404 with a Problem; got %+v", r)
+	}
+
+	put := doc.Paths["/things/{id}/parts/{part}"]["put"]
+	if put.RequestBody == nil || !put.RequestBody.Required {
+		t.Errorf("want a required request body; got %+v", put.RequestBody)
+	}
+	if r, ok := put.Responses["204"]; !ok || r.Content != nil {
+		t.Errorf("want 204 without content; got %+v", put.Responses)
+	}
+	if len(doc.Components.Schemas) != 2 {
+		t.Errorf("want Thing and Problem once each; got %v", doc.Components.Schemas)
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	sameName := func() any {
+		type Thing struct{ A int }
+		return Thing{}
+	}
+	type Thing struct{ B int }
+
+	tests := []struct {
+		name   string
+		routes []Route
+		want   string
+	}{
+		{"declared twice", []Route{{Method: "GET", Path: "/a"}, {Method: "get", Path: "/a"}}, "get /a: declared twice"},
+		{"param not in path", []Route{{Method: "GET", Path: "/a", Params: []Param{{Name: "id", In: "path"}}}}, "parameter id: not in the path"},
+		{"param in a header", []Route{{Method: "GET", Path: "/a", Params: []Param{{Name: "X-Id", In: "header"}}}}, `in must be path or query, not "header"`},
+		{"a channel", []Route{{Method: "GET", Path: "/a", Response: make(chan int)}}, "response: chan int: JSON can't describe a chan"},
+		{"a func field", []Route{{Method: "POST", Path: "/a", Request: struct{ F func() }{}}}, "request: field F: func(): JSON can't describe a func"},
+		{"a struct key", []Route{{Method: "GET", Path: "/a", Response: map[Thing]int{}}}, "can't use a openapi.Thing as an object key"},
+		{"two types, one name", []Route{{Method: "GET", Path: "/a", Response: Thing{}}, {Method: "GET", Path: "/b", Response: sameName()}}, "two types are named Thing"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Generate(API{Routes: tt.routes})
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("want an error with %q; got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	type Item struct {
+		ID    int            `json:"id"`
+		Kind  string         `json:"kind" enum:"a,b"`
+		At    time.Time      `json:"at"`
+		Score float64        `json:"score,omitempty"`
+		Ok    bool           `json:"ok,omitempty"`
+		Tags  []string       `json:"tags,omitempty"`
+		Attrs map[string]int `json:"attrs,omitempty"`
+		Any   any            `json:"any,omitempty"`
+	}
+	doc, err := Generate(API{Routes: []Route{{Method: "GET", Path: "/", Response: []Item{}}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := doc.Paths["/"]["get"].Responses["200"].Content["application/json"].Schema
+
+	const ok = `"id":1,"kind":"a","at":"2025-01-02T03:04:05Z"`
+	tests := []struct {
+		name string
+		body string
+		want string // the error, or "" for none
+	}{
+		{"valid", `[{` + ok + `}]`, ""},
+		{"every field", `[{` + ok + `,"score":1.5,"ok":true,"tags":["x"],"attrs":{"n":2},"any":{"deep":[1]}}]`, ""},
+		{"empty array", `[]`, ""},
+		{"null where a slice goes", `null`, ""},
+		{"null field", `[{` + ok + `,"tags":null}]`, ""},
+		{"not an array", `{}`, "$: want array; got object"},
+		{"missing field", `[{"id":1,"kind":"a"}]`, "$[0]: at is required"},
+		{"undocumented field", `[{` + ok + `,"secret":1}]`, "$[0]: secret is not documented"},
+		{"wrong enum", `[{"id":1,"kind":"c","at":"2025-01-02T03:04:05Z"}]`, `$[0].kind: want one of a, b; got "c"`},
+		{"fraction for an integer", `[{"id":1.5,"kind":"a","at":"2025-01-02T03:04:05Z"}]`, "$[0].id: want integer; got 1.5"},
+		{"string for an integer", `[{"id":"1","kind":"a","at":"2025-01-02T03:04:05Z"}]`, "$[0].id: want integer; got string"},
+		{"bad date-time", `[{"id":1,"kind":"a","at":"yesterday"}]`, `$[0].at: want a date-time; got "yesterday"`},
+		{"bad map value", `[{` + ok + `,"attrs":{"n":"two"}}]`, "$[0].attrs.n: want integer; got string"},
+		{"bad array item", `[{` + ok + `,"tags":[1]}]`, "$[0].tags[0]: want string; got number"},
+		{"bad boolean", `[{` + ok + `,"ok":"yes"}]`, "$[0].ok: want boolean; got string"},
+		{"not JSON", `[{`, "unexpected EOF"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := doc.Validate(schema, []byte(tt.body))
+			if tt.want == "" {
+				if err != nil {
+					t.Errorf("want valid; got %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tt.want {
+				t.Errorf("want %q; got %v", tt.want, err)
+			}
+		})
+	}
+}
+
+func TestValidateUnknownRef(t *testing.T) {
+	var doc Document
+	err := doc.Validate(&Schema{Ref: refPrefix + "Ghost"}, []byte(`{}`))
+	if err == nil || !strings.Contains(err.Error(), "no schema #/components/schemas/Ghost") {
+		t.Errorf("want no schema; got %v", err)
+	}
+}
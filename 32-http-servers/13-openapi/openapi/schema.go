@@ -0,0 +1,191 @@
+package openapi
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// Schema is a JSON Schema, as OpenAPI 3.1 uses it. A named struct type
+// becomes a component, and everywhere it's used is a Ref to it.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Enum                 []string           `json:"enum,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+}
+
+const refPrefix = "#/components/schemas/"
+
+var (
+	timeType          = reflect.TypeFor[time.Time]()
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// generator collects the component schemas while a document is built.
+type generator struct {
+	schemas map[string]*Schema
+	types   map[string]reflect.Type // schema name -> the type it describes
+}
+
+func (g *generator) schemaOf(v any) (*Schema, error) {
+	return g.schemaFor(reflect.TypeOf(v))
+}
+
+// schemaFor follows the rules of encoding/json: it describes what
+// json.Marshal would produce for a value of type t.
+func (g *generator) schemaFor(t reflect.Type) (*Schema, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem() // a pointer marshals like what it points to, or null
+	}
+
+	// Types that marshal themselves come first: time.Time is a struct,
+	// but its JSON is a string
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}, nil
+	case t.Implements(jsonMarshalerType):
+		return &Schema{}, nil // could be any JSON at all
+	case t.Implements(textMarshalerType):
+		return &Schema{Type: "string"}, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}, nil
+	case reflect.Int, reflect.Int64, reflect.Uint, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}, nil
+	case reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}, nil
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}, nil
+	case reflect.String:
+		return &Schema{Type: "string"}, nil
+	case reflect.Interface:
+		return &Schema{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}, nil // base64
+		}
+		items, err := g.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "array", Items: items}, nil
+	case reflect.Map:
+		// Keys are object names, so they must turn into strings
+		switch k := t.Key(); {
+		case k.Kind() == reflect.String, k.Implements(textMarshalerType):
+		case k.Kind() >= reflect.Int && k.Kind() <= reflect.Uintptr:
+		default:
+			return nil, fmt.Errorf("%s: JSON can't use a %s as an object key", t, k)
+		}
+		values, err := g.schemaFor(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{Type: "object", AdditionalProperties: values}, nil
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t) // an anonymous struct goes inline
+		}
+		return g.component(t)
+	}
+	return nil, fmt.Errorf("%s: JSON can't describe a %s", t, t.Kind())
+}
+
+// component adds the schema for the named type t to the components,
+// once, and returns a reference to it.
+func (g *generator) component(t reflect.Type) (*Schema, error) {
+	name := t.Name()
+	ref := &Schema{Ref: refPrefix + name}
+	if prev, ok := g.types[name]; ok {
+		if prev != t {
+			return nil, fmt.Errorf("two types are named %s: %s in %q, and %s in %q",
+				name, prev, prev.PkgPath(), t, t.PkgPath())
+		}
+		return ref, nil // done already, or in progress: a type can refer to itself
+	}
+	g.types[name] = t
+
+	s, err := g.object(t)
+	if err != nil {
+		return nil, err
+	}
+	g.schemas[name] = s
+	return ref, nil
+}
+
+// object describes struct type t as an object, with a property per
+// field that json.Marshal writes.
+func (g *generator) object(t reflect.Type) (*Schema, error) {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	if err := g.fields(s, t); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (g *generator) fields(s *Schema, t reflect.Type) error {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue // but "-," names the field "-"
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+
+		// An embedded struct without a name in its tag has its fields
+		// promoted, like json.Marshal does
+		ft := f.Type
+		if ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if f.Anonymous && name == "" && ft.Kind() == reflect.Struct && ft != timeType {
+			if err := g.fields(s, ft); err != nil {
+				return err
+			}
+			continue
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop, err := g.schemaFor(f.Type)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		if doc := f.Tag.Get("doc"); doc != "" {
+			prop.Description = doc
+		}
+		if format := f.Tag.Get("format"); format != "" {
+			prop.Format = format
+		}
+		if enum := f.Tag.Get("enum"); enum != "" {
+			prop.Enum = strings.Split(enum, ",")
+		}
+		s.Properties[name] = prop
+
+		// A field the client may leave out isn't required
+		optional := f.Type.Kind() == reflect.Pointer ||
+			strings.Contains(","+opts+",", ",omitempty,") ||
+			strings.Contains(","+opts+",", ",omitzero,")
+		if !optional {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validate reports whether data, one JSON value, matches s. It resolves
+// refs against d's components.
+//
+// It is stricter than JSON Schema in one way: an object may only have
+// the properties s lists. A handler that sends a field the document
+// doesn't mention fails, so the document can't fall behind the code.
+// It is looser in another: null matches anything, because every
+// pointer, slice, and map can marshal to null.
+func (d *Document) Validate(s *Schema, data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep 1.5 and 1 apart, for "integer"
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	return d.validate(s, v, "$")
+}
+
+func (d *Document) validate(s *Schema, v any, path string) error {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, refPrefix)
+		c, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: no schema %s", path, s.Ref)
+		}
+		s = c
+	}
+	if v == nil || s.Type == "" {
+		return nil
+	}
+
+	mismatch := func() error {
+		return fmt.Errorf("%s: want %s; got %s", path, s.Type, typeOf(v))
+	}
+	switch s.Type {
+	case "object":
+		m, ok := v.(map[string]any)
+		if !ok {
+			return mismatch()
+		}
+		for _, name := range s.Required {
+			if _, ok := m[name]; !ok {
+				return fmt.Errorf("%s: %s is required", path, name)
+			}
+		}
+		for _, name := range slices.Sorted(maps.Keys(m)) {
+			prop, ok := s.Properties[name]
+			if !ok {
+				prop = s.AdditionalProperties
+			}
+			if prop == nil {
+				return fmt.Errorf("%s: %s is not documented", path, name)
+			}
+			if err := d.validate(prop, m[name], path+"."+name); err != nil {
+				return err
+			}
+		}
+
+	case "array":
+		a, ok := v.([]any)
+		if !ok {
+			return mismatch()
+		}
+		for i, e := range a {
+			if err := d.validate(s.Items, e, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return mismatch()
+		}
+		if len(s.Enum) > 0 && !slices.Contains(s.Enum, str) {
+			return fmt.Errorf("%s: want one of %s; got %q", path, strings.Join(s.Enum, ", "), str)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, str); err != nil {
+				return fmt.Errorf("%s: want a date-time; got %q", path, str)
+			}
+		}
+
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return mismatch()
+		}
+		if _, err := n.Int64(); err != nil {
+			return fmt.Errorf("%s: want integer; got %s", path, n)
+		}
+
+	case "number":
+		if _, ok := v.(json.Number); !ok {
+			return mismatch()
+		}
+
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return mismatch()
+		}
+	}
+	return nil
+}
+
+// typeOf names the JSON type of v, a value decoded with UseNumber.
+func typeOf(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return "null"
+}
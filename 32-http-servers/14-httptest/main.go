@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// Forecast is what the upstream weather service sends, and what the
+// app passes on
+type Forecast struct {
+	City  string  `json:"city"`
+	TempC float64 `json:"temp_c"`
+}
+
+// StatusError is an upstream answer that wasn't 200
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("weather service: %d %s", e.Code, http.StatusText(e.Code))
+}
+
+// WeatherClient calls the upstream weather service. HTTP is a field, so
+// a test can give it a client of its own: one from an httptest.Server,
+// or one with a fake transport
+type WeatherClient struct {
+	BaseURL string
+	Key     string
+	HTTP    *http.Client
+}
+
+func (c *WeatherClient) Forecast(ctx context.Context, city string) (Forecast, error) {
+	u := c.BaseURL + "/v1/forecast?" + url.Values{"city": {city}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, "GET", u, nil)
+	if err != nil {
+		return Forecast{}, err
+	}
+	req.Header.Set("Authorization", "Bearer "+c.Key)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return Forecast{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Forecast{}, &StatusError{Code: resp.StatusCode}
+	}
+	var f Forecast
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&f); err != nil {
+		return Forecast{}, fmt.Errorf("weather service: decoding: %w", err)
+	}
+	return f, nil
+}
+
+// App serves the forecast for a city, from the upstream service
+type App struct {
+	weather *WeatherClient
+}
+
+func (a *App) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /weather/{city}", a.forecast)
+	return mux
+}
+
+func (a *App) forecast(w http.ResponseWriter, r *http.Request) {
+	f, err := a.weather.Forecast(r.Context(), r.PathValue("city"))
+	var status *StatusError
+	switch {
+	case errors.As(err, &status) && status.Code == http.StatusNotFound:
+		writeJSON(w, http.StatusNotFound, map[string]string{"error": "unknown city"})
+		return
+	case err != nil:
+		writeJSON(w, http.StatusBadGateway, map[string]string{"error": "weather service unavailable"})
+		return
+	}
+	w.Header().Set("Cache-Control", "max-age=300")
+	writeJSON(w, http.StatusOK, f)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// roundTripFunc turns a function into an http.RoundTripper, the way
+// http.HandlerFunc turns one into a handler
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// handlerTransport sends requests to a handler, in memory. The fake
+// upstream is an ordinary handler, but no port is opened
+type handlerTransport struct {
+	h http.Handler
+}
+
+func (t handlerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	w := httptest.NewRecorder()
+	t.h.ServeHTTP(w, r)
+	return w.Result(), nil
+}
+
+// upstream is a fake weather service. It knows two cities
+func upstream() http.Handler {
+	temps := map[string]float64{"Istanbul": 18.5, "São Paulo": 24}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		city := r.URL.Query().Get("city")
+		temp, ok := temps[city]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writeJSON(w, http.StatusOK, Forecast{City: city, TempC: temp})
+	})
+}
+
+func main() {
+	fmt.Println("httptest: Recorder, Server, and Fake Transports")
+	fmt.Println("===============================================")
+	fmt.Println()
+
+	// Example 1: ResponseRecorder
+	fmt.Println("1. NewRecorder: call the handler like a function, no network:")
+	app := &App{weather: &WeatherClient{
+		Key:  "secret",
+		HTTP: &http.Client{Transport: handlerTransport{upstream()}},
+	}}
+	for _, city := range []string{"Istanbul", "Atlantis"} {
+		w := httptest.NewRecorder()
+		app.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/weather/"+city, nil))
+		fmt.Printf("   %-9s -> %d %s", city, w.Code, w.Body)
+	}
+	fmt.Println()
+
+	// Example 2: The recorder is not a server
+	fmt.Println("2. The same handler through a recorder, and through a real server:")
+	late := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+		w.Header().Set("X-Late", "too late") // after the body: never sent
+	})
+	w := httptest.NewRecorder()
+	late.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	fmt.Printf("   recorder, w.Header():        X-Late=%q Date=%q\n", w.Header().Get("X-Late"), w.Header().Get("Date"))
+	fmt.Printf("   recorder, w.Result().Header: X-Late=%q\n", w.Result().Header.Get("X-Late"))
+
+	srv := httptest.NewServer(late)
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	resp.Body.Close()
+	fmt.Printf("   server:                      X-Late=%q Date set=%t Content-Length=%s\n",
+		resp.Header.Get("X-Late"), resp.Header.Get("Date") != "", resp.Header.Get("Content-Length"))
+	srv.Close()
+	fmt.Println()
+
+	// Example 3: httptest.Server
+	fmt.Println("3. NewServer: a real listener on 127.0.0.1, for testing client code:")
+	var seen *http.Request
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = r
+		upstream().ServeHTTP(w, r)
+	}))
+	client := &WeatherClient{BaseURL: srv.URL, Key: "secret", HTTP: srv.Client()}
+	f, err := client.Forecast(context.Background(), "São Paulo")
+	fmt.Printf("   got %+v, %v\n", f, err)
+	fmt.Printf("   the server saw %s %s\n", seen.Method, seen.URL)
+	fmt.Printf("   with Authorization=%q\n", seen.Header.Get("Authorization"))
+	srv.Close()
+	fmt.Println()
+
+	// Example 4: TLS and HTTP/2
+	fmt.Println("4. NewTLSServer: HTTPS with a certificate only srv.Client() trusts:")
+	// NewTLSServer does this in one call. Unstarted, the server can be
+	// set up first: here for HTTP/2, and to keep the handshake error
+	// from the plain client out of the output
+	srv = httptest.NewUnstartedServer(upstream())
+	srv.EnableHTTP2 = true
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0)
+	srv.StartTLS()
+	client = &WeatherClient{BaseURL: srv.URL, Key: "secret", HTTP: srv.Client()}
+	_, err = client.Forecast(context.Background(), "Istanbul")
+	fmt.Printf("   srv.Client():        %v\n", errOrOK(err))
+	client.HTTP = &http.Client{}
+	_, err = client.Forecast(context.Background(), "Istanbul")
+	var certErr *tls.CertificateVerificationError
+	fmt.Printf("   a plain http.Client: certificate error=%t\n", errors.As(err, &certErr))
+	resp, err = srv.Client().Get(srv.URL)
+	if err != nil {
+		log.Fatal(err)
+	}
+	resp.Body.Close()
+	fmt.Printf("   protocol:            %s\n", resp.Proto)
+	srv.Close()
+	fmt.Println()
+
+	// Example 5: A fake RoundTripper
+	fmt.Println("5. A fake transport: failures a real server can't easily produce:")
+	for _, rt := range []struct {
+		name string
+		fake roundTripFunc
+	}{
+		{"connection refused", func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("dial tcp 10.0.0.1:443: connect: connection refused")
+		}},
+		{"half a JSON body", func(r *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(`{"city":"Ist`)),
+				Request:    r,
+			}, nil
+		}},
+		{"a slow upstream", func(r *http.Request) (*http.Response, error) {
+			<-r.Context().Done() // hangs until the client gives up
+			return nil, r.Context().Err()
+		}},
+	} {
+		client := &WeatherClient{BaseURL: "https://weather.example", HTTP: &http.Client{
+			Transport: rt.fake,
+			Timeout:   50 * time.Millisecond,
+		}}
+		_, err := client.Forecast(context.Background(), "Istanbul")
+		fmt.Printf("   %-18s -> %v\n", rt.name, err)
+	}
+}
+
+func errOrOK(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "ok"
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// stub answers every request with status and body, and keeps the last
+// request it got
+func stub(status int, body string, got **http.Request) roundTripFunc {
+	return func(r *http.Request) (*http.Response, error) {
+		if got != nil {
+			*got = r
+		}
+		return &http.Response{
+			StatusCode: status,
+			Header:     http.Header{"Content-Type": {"application/json"}},
+			Body:       io.NopCloser(strings.NewReader(body)),
+			Request:    r,
+		}, nil
+	}
+}
+
+// The handler, with a recorder: the fastest test, and the one to write
+// the most of. The upstream is a fake transport, so every failure the
+// handler has to map is one line in the table
+func TestForecastHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		upstream roundTripFunc
+		code     int
+		body     string
+	}{
+		{"ok", stub(200, `{"city":"Istanbul","temp_c":18.5}`, nil), 200, `{"city":"Istanbul","temp_c":18.5}`},
+		{"unknown city", stub(404, ``, nil), 404, `{"error":"unknown city"}`},
+		{"upstream down", stub(503, ``, nil), 502, `{"error":"weather service unavailable"}`},
+		{"bad JSON", stub(200, `{"city":`, nil), 502, `{"error":"weather service unavailable"}`},
+		{"network error", func(*http.Request) (*http.Response, error) {
+			return nil, errors.New("connection reset by peer")
+		}, 502, `{"error":"weather service unavailable"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := &App{weather: &WeatherClient{HTTP: &http.Client{Transport: tt.upstream}}}
+			w := httptest.NewRecorder()
+			app.Handler().ServeHTTP(w, httptest.NewRequest("GET", "/weather/Istanbul", nil))
+
+			resp := w.Result() // what was sent, not the live header map
+			if resp.StatusCode != tt.code {
+				t.Errorf("want %d; got %d", tt.code, resp.StatusCode)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.body {
+				t.Errorf("want %s; got %s", tt.body, got)
+			}
+			if cached := resp.Header.Get("Cache-Control") != ""; cached != (tt.code == 200) {
+				t.Errorf("want Cache-Control only on a 200; got %q", resp.Header.Get("Cache-Control"))
+			}
+		})
+	}
+}
+
+// The client, against a real server: this checks what goes over the
+// wire, encoding and all
+func TestForecastClientRequest(t *testing.T) {
+	var got *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		io.WriteString(w, `{"city":"São Paulo","temp_c":24}`)
+	}))
+	defer srv.Close()
+
+	c := &WeatherClient{BaseURL: srv.URL, Key: "k3y", HTTP: srv.Client()}
+	f, err := c.Forecast(context.Background(), "São Paulo")
+	if err != nil || f != (Forecast{"São Paulo", 24}) {
+		t.Fatalf("got %+v, %v", f, err)
+	}
+
+	if got.Method != "GET" || got.URL.Path != "/v1/forecast" {
+		t.Errorf("want GET /v1/forecast; got %s %s", got.Method, got.URL.Path)
+	}
+	if got.URL.RawQuery != "city=S%C3%A3o+Paulo" {
+		t.Errorf("want the city escaped; got %s", got.URL.RawQuery)
+	}
+	if got.Header.Get("Authorization") != "Bearer k3y" || got.Header.Get("Accept") != "application/json" {
+		t.Errorf("got headers %v", got.Header)
+	}
+}
+
+func TestForecastClientErrors(t *testing.T) {
+	c := &WeatherClient{HTTP: &http.Client{Transport: stub(429, ``, nil)}}
+	_, err := c.Forecast(context.Background(), "Istanbul")
+	var status *StatusError
+	if !errors.As(err, &status) || status.Code != 429 {
+		t.Errorf("want a *StatusError with 429; got %v", err)
+	}
+
+	c.HTTP.Transport = stub(200, `{"city":"Istanbul","temp_c":"warm"}`, nil)
+	if _, err := c.Forecast(context.Background(), "Istanbul"); err == nil || !strings.Contains(err.Error(), "decoding") {
+		t.Errorf("want a decoding error; got %v", err)
+	}
+}
+
+// A fake transport that never answers, inside a synctest bubble: the
+// timeout is tested in no time, and exactly
+func TestForecastClientTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		hang := roundTripFunc(func(r *http.Request) (*http.Response, error) {
+			<-r.Context().Done()
+			return nil, r.Context().Err()
+		})
+		c := &WeatherClient{BaseURL: "https://weather.example", HTTP: &http.Client{Transport: hang}}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		start := time.Now()
+		_, err := c.Forecast(ctx, "Istanbul")
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want DeadlineExceeded; got %v", err)
+		}
+		if took := time.Since(start); took != 3*time.Second {
+			t.Errorf("want to give up at 3s; took %v", took)
+		}
+	})
+}
+
+// Headers set after the body are in the recorder's live map, but were
+// never sent. Result() shows what a client would see
+func TestRecorderHeaderAfterWrite(t *testing.T) {
+	late := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "hello")
+		w.Header().Set("X-Late", "1")
+	})
+
+	w := httptest.NewRecorder()
+	late.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	if w.Header().Get("X-Late") != "1" {
+		t.Error("want the live map to have X-Late")
+	}
+	if w.Result().Header.Get("X-Late") != "" {
+		t.Error("want Result() without X-Late")
+	}
+
+	srv := httptest.NewServer(late)
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.Header.Get("X-Late") != "" {
+		t.Error("want the server not to send X-Late")
+	}
+	if resp.Header.Get("Date") == "" || resp.ContentLength != 5 {
+		t.Errorf("want Date and Content-Length from the server; got %v", resp.Header)
+	}
+}
+
+// A panic in a handler fails a recorder test at once. Through a
+// server, it's recovered, and the client sees a dropped connection
+func TestPanicRecorderVsServer(t *testing.T) {
+	boom := http.HandlerFunc(func(http.ResponseWriter, *http.Request) { panic("boom") })
+
+	func() {
+		defer func() {
+			if v := recover(); v != "boom" {
+				t.Errorf("want the panic to reach the test; got %v", v)
+			}
+		}()
+		boom.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+	}()
+
+	srv := httptest.NewUnstartedServer(boom)
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // the server logs the panic
+	srv.Start()
+	defer srv.Close()
+	if _, err := srv.Client().Get(srv.URL); err == nil {
+		t.Error("want the client to get an error")
+	}
+}
+
+func TestTLSServer(t *testing.T) {
+	srv := httptest.NewUnstartedServer(upstream())
+	srv.EnableHTTP2 = true
+	srv.Config.ErrorLog = log.New(io.Discard, "", 0) // the failed handshake below
+	srv.StartTLS()
+	defer srv.Close()
+
+	c := &WeatherClient{BaseURL: srv.URL, Key: "secret", HTTP: srv.Client()}
+	if _, err := c.Forecast(context.Background(), "Istanbul"); err != nil {
+		t.Errorf("srv.Client() trusts the test certificate; got %v", err)
+	}
+
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.ProtoMajor != 2 || resp.TLS == nil {
+		t.Errorf("want HTTP/2 over TLS; got %s", resp.Proto)
+	}
+
+	c.HTTP = &http.Client{}
+	_, err = c.Forecast(context.Background(), "Istanbul")
+	var certErr *tls.CertificateVerificationError
+	if !errors.As(err, &certErr) {
+		t.Errorf("want a certificate error from a plain client; got %v", err)
+	}
+}
+
+// The whole stack: the app behind a real server, calling a fake
+// upstream behind another. Slower, and fewer of them, but nothing is
+// skipped
+func TestEndToEnd(t *testing.T) {
+	up := httptest.NewServer(upstream())
+	defer up.Close()
+	app := &App{weather: &WeatherClient{BaseURL: up.URL, Key: "secret", HTTP: up.Client()}}
+	srv := httptest.NewServer(app.Handler())
+	defer srv.Close()
+
+	tests := []struct {
+		path string
+		code int
+	}{
+		{"/weather/Istanbul", 200},
+		{"/weather/S%C3%A3o%20Paulo", 200},
+		{"/weather/Atlantis", 404},
+		{"/forecast", 404},
+	}
+	for _, tt := range tests {
+		resp, err := srv.Client().Get(srv.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var f Forecast
+		json.NewDecoder(resp.Body).Decode(&f)
+		resp.Body.Close()
+
+		if resp.StatusCode != tt.code {
+			t.Errorf("%s: want %d; got %d", tt.path, tt.code, resp.StatusCode)
+		}
+		if tt.code == 200 && f.TempC == 0 {
+			t.Errorf("%s: want a forecast; got %+v", tt.path, f)
+		}
+	}
+
+	// With the wrong key, the upstream says 401, and the app 502
+	app.weather.Key = "wrong"
+	resp, err := srv.Client().Get(srv.URL + "/weather/Istanbul")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadGateway {
+		t.Errorf("want 502; got %d", resp.StatusCode)
+	}
+}
+
+// handlerTransport runs a fake upstream handler in memory: a real
+// handler, like with a server, but no port
+func TestHandlerTransport(t *testing.T) {
+	var got *http.Request
+	fake := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r
+		upstream().ServeHTTP(w, r)
+	})
+	c := &WeatherClient{BaseURL: "https://weather.example", Key: "secret",
+		HTTP: &http.Client{Transport: handlerTransport{fake}}}
+
+	f, err := c.Forecast(context.Background(), "Istanbul")
+	if err != nil || f.TempC != 18.5 {
+		t.Fatalf("got %+v, %v", f, err)
+	}
+	if got.Host != "weather.example" {
+		t.Errorf("want the request for weather.example; got %s", got.Host)
+	}
+}
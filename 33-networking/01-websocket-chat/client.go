@@ -0,0 +1,118 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// writeWait is how long a write to a client may take
+	writeWait = 5 * time.Second
+
+	// maxMessageSize limits what a client can send in one message
+	maxMessageSize = 4 << 10
+)
+
+// Client is one connection. Two goroutines serve it: readPump reads
+// what the client sends, and writePump writes what the hub sends. A
+// websocket.Conn allows one reader and one writer at a time, so each
+// goroutine owns one direction
+type Client struct {
+	hub  *Hub
+	conn *websocket.Conn
+	name string
+	send chan Message
+}
+
+// readPump passes every message the client sends to the hub, until the
+// client disconnects or sends something invalid
+func (c *Client) readPump() {
+	for {
+		var m Message
+		if err := websocket.JSON.Receive(c.conn, &m); err != nil {
+			return
+		}
+		// Clients can't speak for someone else
+		m.From = c.name
+		m.Text = strings.TrimSpace(m.Text)
+		if m.Text == "" {
+			continue
+		}
+		if !c.hub.Broadcast(m) {
+			return
+		}
+	}
+}
+
+// writePump writes every message the hub queues for the client. When
+// the hub closes the channel, it closes the connection, which also
+// ends readPump
+func (c *Client) writePump() {
+	defer c.conn.Close()
+
+	for m := range c.send {
+		c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+		if err := websocket.JSON.Send(c.conn, m); err != nil {
+			return
+		}
+	}
+}
+
+// Handler upgrades requests to /ws?name=... to WebSocket connections,
+// and joins them to hub
+func Handler(hub *Hub) http.Handler {
+	return websocket.Server{
+		Handshake: sameOrigin,
+		Handler: func(conn *websocket.Conn) {
+			serveClient(hub, conn)
+		},
+	}
+}
+
+func serveClient(hub *Hub, conn *websocket.Conn) {
+	hub.conns.Add(1)
+	defer hub.conns.Done()
+
+	conn.MaxPayloadBytes = maxMessageSize
+
+	name := conn.Request().URL.Query().Get("name")
+	if name == "" {
+		name = "anonymous"
+	}
+	c := &Client{hub: hub, conn: conn, name: name, send: make(chan Message, sendBuffer)}
+	if !hub.Join(c) {
+		return
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.writePump()
+		close(done)
+	}()
+
+	// The handler's goroutine is the read pump. When it returns, the
+	// client leaves, the hub closes c.send, and writePump finishes
+	c.readPump()
+	hub.Leave(c)
+	<-done
+}
+
+// sameOrigin rejects connections that a page from another site opened.
+// Browsers let any page open a WebSocket to any host, with the user's
+// cookies, so the server must check the Origin header itself. Clients
+// that aren't browsers, and send no Origin, are let through
+func sameOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != req.Host {
+		return &websocket.ProtocolError{ErrorString: "cross-origin connection refused"}
+	}
+	return nil
+}
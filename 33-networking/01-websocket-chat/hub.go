@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"sync"
+)
+
+// Message is what clients send and receive, as JSON
+type Message struct {
+	From string `json:"from"`
+	Text string `json:"text"`
+}
+
+// sendBuffer is how many messages a client can fall behind before the
+// hub gives up on it
+const sendBuffer = 16
+
+// Hub owns the set of connected clients. Only its Run goroutine touches
+// the set, so it needs no lock: clients ask for changes over channels
+type Hub struct {
+	register   chan *Client
+	unregister chan *Client
+	broadcast  chan Message
+	done       chan struct{} // closed when Run returns
+
+	clients map[*Client]bool
+
+	// conns counts the connection handlers still running, so a
+	// shutdown can wait for them
+	conns sync.WaitGroup
+}
+
+func NewHub() *Hub {
+	return &Hub{
+		register:   make(chan *Client),
+		unregister: make(chan *Client),
+		broadcast:  make(chan Message),
+		done:       make(chan struct{}),
+		clients:    make(map[*Client]bool),
+	}
+}
+
+// Run serves the clients' requests until ctx is cancelled. Then it says
+// goodbye, and closes every client's send channel, which makes their
+// write pumps close the connections
+func (h *Hub) Run(ctx context.Context) {
+	defer close(h.done)
+
+	for {
+		select {
+		case c := <-h.register:
+			h.clients[c] = true
+			h.fanOut(Message{From: "server", Text: c.name + " joined"})
+
+		case c := <-h.unregister:
+			if h.clients[c] {
+				h.remove(c)
+				h.fanOut(Message{From: "server", Text: c.name + " left"})
+			}
+
+		case m := <-h.broadcast:
+			h.fanOut(m)
+
+		case <-ctx.Done():
+			h.fanOut(Message{From: "server", Text: "shutting down"})
+			for c := range h.clients {
+				h.remove(c)
+			}
+			return
+		}
+	}
+}
+
+// fanOut queues m for every client. It never blocks: a client whose
+// buffer is full is too slow, and is dropped, so one stuck connection
+// can't stall the whole chat
+func (h *Hub) fanOut(m Message) {
+	for c := range h.clients {
+		select {
+		case c.send <- m:
+		default:
+			h.remove(c)
+		}
+	}
+}
+
+// remove forgets c, and closes its send channel. Only Run calls it, so
+// a channel is never closed twice
+func (h *Hub) remove(c *Client) {
+	delete(h.clients, c)
+	close(c.send)
+}
+
+// Join adds c to the chat. It reports false if the hub has stopped
+func (h *Hub) Join(c *Client) bool {
+	select {
+	case h.register <- c:
+		return true
+	case <-h.done:
+		return false
+	}
+}
+
+// Leave removes c from the chat, if it's still in it
+func (h *Hub) Leave(c *Client) {
+	select {
+	case h.unregister <- c:
+	case <-h.done:
+	}
+}
+
+// Broadcast sends m to every client. It reports false if the hub has
+// stopped
+func (h *Hub) Broadcast(m Message) bool {
+	select {
+	case h.broadcast <- m:
+		return true
+	case <-h.done:
+		return false
+	}
+}
+
+// Wait blocks until Run has returned and every connection is closed
+func (h *Hub) Wait() {
+	<-h.done
+	h.conns.Wait()
+}
@@ -0,0 +1,210 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// routes serves the chat page at / and the WebSocket endpoint at /ws
+func routes(hub *Hub) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		fmt.Fprint(w, page)
+	})
+	mux.Handle("GET /ws", Handler(hub))
+	return mux
+}
+
+// Chat is a running chat server
+type Chat struct {
+	URL string // ws://host:port
+	srv *http.Server
+	hub *Hub
+}
+
+// Start starts a chat server on ln
+func Start(ln net.Listener) *Chat {
+	hub := NewHub()
+	hubCtx, stopHub := context.WithCancel(context.Background())
+	go hub.Run(hubCtx)
+
+	srv := &http.Server{Handler: routes(hub)}
+
+	// Shutdown doesn't wait for hijacked connections, and WebSocket
+	// connections are hijacked. It does call these functions, so the
+	// hub can close them itself
+	srv.RegisterOnShutdown(stopHub)
+
+	go srv.Serve(ln)
+	return &Chat{URL: "ws://" + ln.Addr().String(), srv: srv, hub: hub}
+}
+
+// Shutdown stops accepting connections, tells every client goodbye,
+// and waits until they are all closed, or ctx ends
+func (c *Chat) Shutdown(ctx context.Context) error {
+	if err := c.srv.Shutdown(ctx); err != nil {
+		return err
+	}
+
+	done := make(chan struct{})
+	go func() {
+		c.hub.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func main() {
+	addr := flag.String("addr", "", "serve the chat on this address, like :8080, until Ctrl+C")
+	flag.Parse()
+
+	if *addr != "" {
+		if err := serve(*addr); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+	demo()
+}
+
+// serve runs the chat until the process is interrupted, then shuts it
+// down gracefully
+func serve(addr string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	chat := Start(ln)
+	fmt.Printf("chat on http://%s (Ctrl+C to stop)\n", ln.Addr())
+
+	<-ctx.Done()
+	fmt.Println("shutting down...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return chat.Shutdown(ctx)
+}
+
+func demo() {
+	fmt.Println("WebSocket Chat")
+	fmt.Println("==============")
+	fmt.Println()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println("listen:", err)
+		return
+	}
+	chat := Start(ln)
+
+	// Example 1: Joining
+	fmt.Println("1. Three clients join:")
+	alice := dial(chat.URL, "alice")
+	recv(alice)
+	bob := dial(chat.URL, "bob")
+	recv(alice, bob)
+	carol := dial(chat.URL, "carol")
+	recv(alice, bob, carol)
+	fmt.Println()
+
+	// Example 2: Broadcasting
+	fmt.Println("2. A message goes to everyone, the sender too:")
+	say(alice, "hi everyone")
+	recv(alice, bob, carol)
+	say(bob, "   ") // blank messages are dropped
+	say(bob, "hi alice")
+	recv(alice, bob, carol)
+	fmt.Println()
+
+	// Example 3: Leaving
+	fmt.Println("3. A client disconnects:")
+	carol.conn.Close()
+	recv(alice, bob)
+	fmt.Println()
+
+	// Example 4: Graceful shutdown
+	fmt.Println("4. The server shuts down:")
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := chat.Shutdown(ctx); err != nil {
+		fmt.Println("   shutdown:", err)
+	}
+	recv(alice, bob) // the goodbye
+	recv(alice, bob) // then the connection closes
+}
+
+// chatClient is the demo's side of a connection
+type chatClient struct {
+	name string
+	conn *websocket.Conn
+}
+
+func dial(base, name string) *chatClient {
+	conn, err := websocket.Dial(base+"/ws?name="+name, "", "http://"+strings.TrimPrefix(base, "ws://"))
+	if err != nil {
+		fmt.Println("   dial:", err)
+		os.Exit(1)
+	}
+	return &chatClient{name: name, conn: conn}
+}
+
+func say(c *chatClient, text string) {
+	fmt.Printf("   %-5s -> %q\n", c.name, text)
+	websocket.JSON.Send(c.conn, Message{Text: text})
+}
+
+// recv reads the next message of every client, in order, and prints it
+func recv(clients ...*chatClient) {
+	for _, c := range clients {
+		c.conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+		var m Message
+		if err := websocket.JSON.Receive(c.conn, &m); err != nil {
+			fmt.Printf("   %-5s <- (connection closed)\n", c.name)
+			continue
+		}
+		fmt.Printf("   %-5s <- %s: %s\n", c.name, m.From, m.Text)
+	}
+}
+
+// page is a minimal browser client, for go run . -addr :8080
+const page = `<!doctype html>
+<title>Go chat</title>
+<pre id="log"></pre>
+<form id="form"><input id="text" autofocus autocomplete="off"> <button>Send</button></form>
+<script>
+const name = prompt("Your name?") || "anonymous";
+const ws = new WebSocket("ws://" + location.host + "/ws?name=" + encodeURIComponent(name));
+const log = document.getElementById("log");
+ws.onmessage = e => {
+  const m = JSON.parse(e.data);
+  log.textContent += m.from + ": " + m.text + "\n";
+};
+ws.onclose = () => { log.textContent += "(disconnected)\n"; };
+document.getElementById("form").onsubmit = e => {
+  e.preventDefault();
+  const input = document.getElementById("text");
+  ws.send(JSON.stringify({text: input.value}));
+  input.value = "";
+};
+</script>
+`
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// The hub is tested alone: its clients are only send channels, so no
+// connection is needed, and synctest.Wait knows when Run is idle
+
+func newTestClient(name string, buffer int) *Client {
+	return &Client{name: name, send: make(chan Message, buffer)}
+}
+
+// drain returns every message queued for c
+func drain(c *Client) []string {
+	var got []string
+	for {
+		select {
+		case m, ok := <-c.send:
+			if !ok {
+				return append(got, "(closed)")
+			}
+			got = append(got, m.From+": "+m.Text)
+		default:
+			return got
+		}
+	}
+}
+
+func TestHub(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		hub := NewHub()
+		go hub.Run(ctx)
+
+		ada, bob := newTestClient("ada", 10), newTestClient("bob", 10)
+		hub.Join(ada)
+		hub.Join(bob)
+		hub.Broadcast(Message{From: "ada", Text: "hi"})
+		hub.Leave(bob)
+		hub.Leave(bob) // leaving twice is harmless
+		synctest.Wait()
+
+		want := "server: ada joined|server: bob joined|ada: hi|server: bob left"
+		if got := strings.Join(drain(ada), "|"); got != want {
+			t.Errorf("ada: want %q; got %q", want, got)
+		}
+		want = "server: bob joined|ada: hi|(closed)"
+		if got := strings.Join(drain(bob), "|"); got != want {
+			t.Errorf("bob: want %q; got %q", want, got)
+		}
+
+		cancel()
+		hub.Wait()
+
+		want = "server: shutting down|(closed)"
+		if got := strings.Join(drain(ada), "|"); got != want {
+			t.Errorf("ada after shutdown: want %q; got %q", want, got)
+		}
+		if hub.Join(newTestClient("late", 1)) || hub.Broadcast(Message{}) {
+			t.Error("want Join and Broadcast to fail after Run returns")
+		}
+	})
+}
+
+func TestHubDropsSlowClients(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		hub := NewHub()
+		go hub.Run(ctx)
+
+		fast := newTestClient("fast", 10)
+		slow := newTestClient("slow", 2) // room for its join and one message
+		hub.Join(fast)
+		hub.Join(slow)
+		hub.Broadcast(Message{From: "fast", Text: "1"})
+		hub.Broadcast(Message{From: "fast", Text: "2"}) // slow's buffer is full
+		hub.Broadcast(Message{From: "fast", Text: "3"})
+		synctest.Wait()
+
+		want := "server: slow joined|fast: 1|(closed)"
+		if got := strings.Join(drain(slow), "|"); got != want {
+			t.Errorf("slow: want %q; got %q", want, got)
+		}
+		// The fast client got everything, and was never blocked
+		want = "server: fast joined|server: slow joined|fast: 1|fast: 2|fast: 3"
+		if got := strings.Join(drain(fast), "|"); got != want {
+			t.Errorf("fast: want %q; got %q", want, got)
+		}
+	})
+}
+
+// The rest of the tests run a real server, and real WebSocket clients
+
+func startChat(t *testing.T) *Chat {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chat := Start(ln)
+	t.Cleanup(func() { chat.Shutdown(context.Background()) })
+	return chat
+}
+
+func testDial(t *testing.T, chat *Chat, name string) *websocket.Conn {
+	t.Helper()
+	origin := "http://" + strings.TrimPrefix(chat.URL, "ws://")
+	conn, err := websocket.Dial(chat.URL+"/ws?name="+name, "", origin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn
+}
+
+// expect reads the next message from conn, and checks it
+func expect(t *testing.T, conn *websocket.Conn, want string) {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+
+	var m Message
+	if err := websocket.JSON.Receive(conn, &m); err != nil {
+		t.Fatalf("want %q; got error %v", want, err)
+	}
+	if got := m.From + ": " + m.Text; got != want {
+		t.Fatalf("want %q; got %q", want, got)
+	}
+}
+
+func TestChat(t *testing.T) {
+	chat := startChat(t)
+
+	ada := testDial(t, chat, "ada")
+	expect(t, ada, "server: ada joined")
+	bob := testDial(t, chat, "bob")
+	expect(t, ada, "server: bob joined")
+	expect(t, bob, "server: bob joined")
+
+	// The server sets From: a client can't pretend to be someone else
+	websocket.JSON.Send(ada, Message{From: "bob", Text: "  hello  "})
+	expect(t, ada, "ada: hello")
+	expect(t, bob, "ada: hello")
+
+	bob.Close()
+	expect(t, ada, "server: bob left")
+}
+
+func TestChatShutdown(t *testing.T) {
+	chat := startChat(t)
+
+	ada := testDial(t, chat, "ada")
+	expect(t, ada, "server: ada joined")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := chat.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	expect(t, ada, "server: shutting down")
+	var m Message
+	if err := websocket.JSON.Receive(ada, &m); err == nil {
+		t.Errorf("want the connection closed; got %+v", m)
+	}
+}
+
+func TestChatTooLargeMessage(t *testing.T) {
+	chat := startChat(t)
+
+	ada := testDial(t, chat, "ada")
+	expect(t, ada, "server: ada joined")
+	bob := testDial(t, chat, "bob")
+	expect(t, ada, "server: bob joined")
+	expect(t, bob, "server: bob joined")
+
+	// The server stops reading from bob, which makes bob leave
+	websocket.JSON.Send(bob, Message{Text: strings.Repeat("x", maxMessageSize)})
+	expect(t, ada, "server: bob left")
+}
+
+func TestSameOrigin(t *testing.T) {
+	chat := startChat(t)
+	host := strings.TrimPrefix(chat.URL, "ws://")
+
+	tests := []struct {
+		origin string
+		want   int
+	}{
+		{"http://evil.example.com", http.StatusForbidden},
+		{"http://" + host, http.StatusSwitchingProtocols},
+	}
+	for _, tt := range tests {
+		t.Run(tt.origin, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "http://"+host+"/ws", nil)
+			req.Header.Set("Origin", tt.origin)
+			req.Header.Set("Connection", "Upgrade")
+			req.Header.Set("Upgrade", "websocket")
+			req.Header.Set("Sec-WebSocket-Version", "13")
+			req.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+
+			resp, err := http.DefaultTransport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Errorf("want %d; got %d", tt.want, resp.StatusCode)
+			}
+		})
+	}
+}
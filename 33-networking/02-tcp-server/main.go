@@ -0,0 +1,351 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// maxLine limits one command, its newline included. A client that never
+// sends a newline can't make the server buffer forever
+const maxLine = 1024
+
+// Store is the key-value map that every connection shares
+type Store struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+func NewStore() *Store {
+	return &Store{data: make(map[string]string)}
+}
+
+// Server speaks a line protocol over TCP. A client sends one command
+// per line, and gets one line back:
+//
+//	SET key value   ->  OK
+//	GET key         ->  VALUE value, or NOT_FOUND
+//	DEL key         ->  OK, or NOT_FOUND
+//	ECHO text       ->  text
+//	QUIT            ->  BYE
+type Server struct {
+	store       *Store
+	idleTimeout time.Duration
+
+	mu       sync.Mutex
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	closing  atomic.Bool
+	wg       sync.WaitGroup
+}
+
+func NewServer(store *Store, idleTimeout time.Duration) *Server {
+	return &Server{store: store, idleTimeout: idleTimeout, conns: make(map[net.Conn]struct{})}
+}
+
+// ErrServerClosed is returned by Serve after Shutdown
+var ErrServerClosed = errors.New("server closed")
+
+// Serve accepts connections on ln, and serves each one on its own
+// goroutine, until Shutdown is called
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	s.listener = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if s.closing.Load() {
+				return ErrServerClosed
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		if s.closing.Load() {
+			// Shutdown has already woken the connections it knew about
+			s.mu.Unlock()
+			conn.Close()
+			return ErrServerClosed
+		}
+		s.conns[conn] = struct{}{}
+		s.wg.Add(1)
+		s.mu.Unlock()
+
+		go func() {
+			defer s.wg.Done()
+			defer s.forget(conn)
+			s.ServeConn(conn)
+		}()
+	}
+}
+
+func (s *Server) forget(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+}
+
+// ServeConn serves one connection until the client quits, goes idle,
+// or the server shuts down. It closes conn when it returns
+func (s *Server) ServeConn(conn net.Conn) {
+	defer conn.Close()
+
+	// A Scanner splits the stream into lines: TCP has no messages,
+	// only bytes, so the protocol must say where each one ends
+	scanner := bufio.NewScanner(conn)
+	scanner.Buffer(make([]byte, 0, 256), maxLine)
+
+	for {
+		if !s.armDeadline(conn) {
+			break
+		}
+		if !scanner.Scan() {
+			break
+		}
+		reply, quit := s.exec(scanner.Text())
+		if _, err := fmt.Fprintln(conn, reply); err != nil || quit {
+			return
+		}
+	}
+
+	var ne net.Error
+	switch err := scanner.Err(); {
+	case s.closing.Load():
+		fmt.Fprintln(conn, "BYE server shutting down")
+	case errors.Is(err, bufio.ErrTooLong):
+		fmt.Fprintln(conn, "ERR line too long")
+	case errors.As(err, &ne) && ne.Timeout():
+		fmt.Fprintln(conn, "ERR idle timeout")
+	}
+}
+
+// armDeadline gives the client idleTimeout to send its next command.
+// It's a deadline, not a timer: the next read fails once it passes.
+//
+// It holds the lock so it can't undo Shutdown's deadline: either it
+// runs first and Shutdown overrides it, or it sees closing and reports
+// false
+func (s *Server) armDeadline(conn net.Conn) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closing.Load() {
+		return false
+	}
+	conn.SetReadDeadline(time.Now().Add(s.idleTimeout))
+	return true
+}
+
+// exec runs one command, and reports whether the client is done
+func (s *Server) exec(line string) (reply string, quit bool) {
+	cmd, args, _ := strings.Cut(strings.TrimSpace(line), " ")
+
+	switch strings.ToUpper(cmd) {
+	case "SET":
+		key, value, ok := strings.Cut(args, " ")
+		if !ok || key == "" {
+			return "ERR usage: SET key value", false
+		}
+		s.store.mu.Lock()
+		s.store.data[key] = value
+		s.store.mu.Unlock()
+		return "OK", false
+
+	case "GET":
+		s.store.mu.Lock()
+		value, ok := s.store.data[args]
+		s.store.mu.Unlock()
+		if !ok {
+			return "NOT_FOUND", false
+		}
+		return "VALUE " + value, false
+
+	case "DEL":
+		s.store.mu.Lock()
+		_, ok := s.store.data[args]
+		delete(s.store.data, args)
+		s.store.mu.Unlock()
+		if !ok {
+			return "NOT_FOUND", false
+		}
+		return "OK", false
+
+	case "ECHO":
+		return args, false
+
+	case "QUIT":
+		return "BYE", true
+
+	case "":
+		return "ERR empty command", false
+	}
+	return fmt.Sprintf("ERR unknown command %q", cmd), false
+}
+
+// Shutdown stops accepting connections, and wakes every connection
+// that is waiting for a command so it can say goodbye. It waits for
+// them to close, or for ctx to end
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.mu.Lock()
+	s.closing.Store(true)
+	if s.listener != nil {
+		s.listener.Close()
+	}
+	// A deadline in the past makes a blocked Read return at once
+	for conn := range s.conns {
+		conn.SetReadDeadline(time.Now())
+	}
+	s.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Client sends commands and reads the replies, one line each
+type Client struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+func Dial(addr string) (*Client, error) {
+	conn, err := net.DialTimeout("tcp", addr, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(conn), nil
+}
+
+// NewClient uses a connection that is already open, like one end of a
+// net.Pipe
+func NewClient(conn net.Conn) *Client {
+	return &Client{conn: conn, r: bufio.NewReader(conn)}
+}
+
+// Do sends one command, and returns the reply without its newline
+func (c *Client) Do(cmd string) (string, error) {
+	if _, err := fmt.Fprintln(c.conn, cmd); err != nil {
+		return "", err
+	}
+	return c.ReadLine()
+}
+
+// ReadLine reads a line the server sent without being asked, like a
+// goodbye
+func (c *Client) ReadLine() (string, error) {
+	line, err := c.r.ReadString('\n')
+	return strings.TrimSuffix(line, "\n"), err
+}
+
+func (c *Client) Close() error { return c.conn.Close() }
+
+func main() {
+	fmt.Println("TCP Servers and Line Protocols")
+	fmt.Println("==============================")
+	fmt.Println()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println("listen:", err)
+		return
+	}
+	srv := NewServer(NewStore(), 200*time.Millisecond)
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	addr := ln.Addr().String()
+
+	// Example 1: Commands
+	fmt.Println("1. One command per line, one reply per line:")
+	alice := mustDial(addr)
+	for _, cmd := range []string{"SET lang go", "GET lang", "ECHO hello, world", "DEL lang", "GET lang", "PUT x", "SET onlykey"} {
+		do(alice, cmd)
+	}
+	fmt.Println()
+
+	// Example 2: Connections share the store
+	fmt.Println("2. A second connection, served at the same time:")
+	bob := mustDial(addr)
+	do(alice, "SET greeting hi from alice")
+	do(bob, "GET greeting")
+	do(bob, "QUIT")
+	fmt.Println()
+
+	// Example 3: Limits
+	fmt.Println("3. A line over the limit, and an idle client:")
+	carol := mustDial(addr)
+	do(carol, strings.Repeat("x", maxLine+1))
+	readAll(carol)
+
+	time.Sleep(300 * time.Millisecond) // longer than the idle timeout
+	readAll(alice)
+	fmt.Println()
+
+	// Example 4: Graceful shutdown
+	fmt.Println("4. Shutdown with a client connected:")
+	dave := mustDial(addr)
+	do(dave, "ECHO still here")
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		fmt.Println("   shutdown:", err)
+	}
+	readAll(dave)
+	fmt.Println("   Serve returned:", <-served)
+
+	if _, err := Dial(addr); err != nil {
+		fmt.Println("   a new client can't connect")
+	}
+}
+
+func mustDial(addr string) *Client {
+	c, err := Dial(addr)
+	if err != nil {
+		fmt.Println("dial:", err)
+		os.Exit(1)
+	}
+	return c
+}
+
+// do sends cmd, and prints it with the reply
+func do(c *Client, cmd string) {
+	reply, err := c.Do(cmd)
+	if len(cmd) > 30 {
+		cmd = cmd[:12] + fmt.Sprintf("... (%d bytes)", len(cmd))
+	}
+	if err != nil {
+		fmt.Printf("   %-30s -> error: %v\n", cmd, err)
+		return
+	}
+	fmt.Printf("   %-30s -> %s\n", cmd, reply)
+}
+
+// readAll prints what the server sends until it closes the connection
+func readAll(c *Client) {
+	c.conn.SetReadDeadline(time.Now().Add(time.Second))
+	for {
+		line, err := c.ReadLine()
+		if err != nil {
+			fmt.Println("   (connection closed)")
+			return
+		}
+		fmt.Printf("   %-30s <- %s\n", "", line)
+	}
+}
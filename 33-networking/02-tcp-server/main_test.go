@@ -0,0 +1,193 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+// pipe serves one end of an in-memory connection, and returns a client
+// on the other end. net.Pipe has deadlines like a TCP connection, but
+// no port, and no network
+func pipe(t *testing.T, srv *Server) *Client {
+	t.Helper()
+	server, client := net.Pipe()
+	go srv.ServeConn(server)
+
+	c := NewClient(client)
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestCommands(t *testing.T) {
+	c := pipe(t, NewServer(NewStore(), time.Minute))
+
+	steps := []struct{ cmd, want string }{
+		{"GET lang", "NOT_FOUND"},
+		{"SET lang go", "OK"},
+		{"GET lang", "VALUE go"},
+		{"SET lang  go 1.25 ", "OK"}, // the value is the rest of the line
+		{"GET lang", "VALUE  go 1.25"},
+		{"set lang go", "OK"}, // commands ignore case
+		{"DEL lang", "OK"},
+		{"DEL lang", "NOT_FOUND"},
+		{"ECHO hello, world", "hello, world"},
+		{"ECHO", ""},
+		{"SET", "ERR usage: SET key value"},
+		{"SET key", "ERR usage: SET key value"},
+		{"", "ERR empty command"},
+		{"FLY away", `ERR unknown command "FLY"`},
+	}
+	for _, step := range steps {
+		got, err := c.Do(step.cmd)
+		if err != nil {
+			t.Fatalf("%q: %v", step.cmd, err)
+		}
+		if got != step.want {
+			t.Errorf("%q: want %q; got %q", step.cmd, step.want, got)
+		}
+	}
+}
+
+func TestQuit(t *testing.T) {
+	c := pipe(t, NewServer(NewStore(), time.Minute))
+
+	if got, _ := c.Do("QUIT"); got != "BYE" {
+		t.Errorf("want BYE; got %q", got)
+	}
+	if _, err := c.ReadLine(); err != io.EOF {
+		t.Errorf("want the connection closed; got %v", err)
+	}
+}
+
+func TestLineTooLong(t *testing.T) {
+	tests := []struct {
+		size int
+		want string
+	}{
+		{maxLine - 6, strings.Repeat("x", maxLine-6)}, // "ECHO ", the text, and "\n" is maxLine
+		{maxLine - 5, "ERR line too long"},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.size), func(t *testing.T) {
+			c := pipe(t, NewServer(NewStore(), time.Minute))
+
+			// A pipe has no buffer: the server may answer before it has
+			// read the whole line, so write on another goroutine
+			go fmt.Fprintln(c.conn, "ECHO "+strings.Repeat("x", tt.size))
+			got, err := c.ReadLine()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("want %.20q; got %.20q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestIdleTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := pipe(t, NewServer(NewStore(), time.Minute))
+
+		// Every command restarts the clock
+		time.Sleep(50 * time.Second)
+		c.Do("ECHO still here")
+		start := time.Now()
+
+		line, err := c.ReadLine()
+		if line != "ERR idle timeout" || err != nil {
+			t.Errorf("want the idle error; got %q, %v", line, err)
+		}
+		if took := time.Since(start); took != time.Minute {
+			t.Errorf("want the timeout a minute after the last command; got %v", took)
+		}
+		if _, err := c.ReadLine(); err != io.EOF {
+			t.Errorf("want the connection closed; got %v", err)
+		}
+	})
+}
+
+// listen starts srv on a random port, and returns its address, and a
+// channel that receives what Serve returns
+func listen(t *testing.T, srv *Server) (string, <-chan error) {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve(ln) }()
+	t.Cleanup(func() { srv.Shutdown(context.Background()) })
+	return ln.Addr().String(), served
+}
+
+func TestShutdown(t *testing.T) {
+	srv := NewServer(NewStore(), time.Minute)
+	addr, served := listen(t, srv)
+
+	var clients []*Client
+	for range 3 {
+		c, err := Dial(addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer c.Close()
+		c.Do("ECHO hi") // the server has accepted it
+		clients = append(clients, c)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+
+	for i, c := range clients {
+		if line, _ := c.ReadLine(); line != "BYE server shutting down" {
+			t.Errorf("client %d: want a goodbye; got %q", i, line)
+		}
+	}
+	if err := <-served; !errors.Is(err, ErrServerClosed) {
+		t.Errorf("want ErrServerClosed from Serve; got %v", err)
+	}
+	if _, err := Dial(addr); err == nil {
+		t.Error("want new connections refused")
+	}
+}
+
+func TestConcurrentClients(t *testing.T) {
+	// Run with -race: every connection shares the store
+	srv := NewServer(NewStore(), time.Minute)
+	addr, _ := listen(t, srv)
+
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			c, err := Dial(addr)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			defer c.Close()
+
+			key := fmt.Sprintf("key%d", i)
+			c.Do("SET " + key + " " + key)
+			if got, _ := c.Do("GET " + key); got != "VALUE "+key {
+				t.Errorf("%s: got %q", key, got)
+			}
+		})
+	}
+	wg.Wait()
+
+	if n := len(srv.store.data); n != 20 {
+		t.Errorf("want 20 keys; got %d", n)
+	}
+}
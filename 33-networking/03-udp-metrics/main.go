@@ -0,0 +1,267 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// readBuffer fits the largest datagram UDP can carry. Reading into a
+// smaller buffer doesn't fail: the rest of the datagram is silently cut
+const readBuffer = 65535
+
+// socketBuffer is how much the kernel may hold for the collector
+const socketBuffer = 4 << 20
+
+// maxPacket is how much the client puts in one datagram. Anything that
+// fits in an Ethernet frame crosses most networks without being split
+// into IP fragments, which are lost more often
+const maxPacket = 1432
+
+// Collector receives metrics over UDP. Serve reads datagrams and parses
+// them; another goroutine aggregates what it parsed
+type Collector struct {
+	conn    *net.UDPConn
+	metrics chan Metric
+
+	packets atomic.Int64 // datagrams received
+	bad     atomic.Int64 // lines that didn't parse
+	dropped atomic.Int64 // metrics the aggregator had no room for
+}
+
+// Listen opens a UDP socket on addr. queue is how many parsed metrics
+// can wait for the aggregator
+func Listen(addr string, queue int) (*Collector, error) {
+	udpAddr, err := net.ResolveUDPAddr("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// There is no Accept: one socket receives from every client
+	conn, err := net.ListenUDP("udp", udpAddr)
+	if err != nil {
+		return nil, err
+	}
+	// Datagrams wait in the kernel until Serve reads them. When that
+	// buffer is full, new ones are dropped, so ask for a bigger one. The
+	// kernel may give less: on Linux, net.core.rmem_max caps it
+	conn.SetReadBuffer(socketBuffer)
+	return &Collector{conn: conn, metrics: make(chan Metric, queue)}, nil
+}
+
+func (c *Collector) Addr() net.Addr { return c.conn.LocalAddr() }
+
+// Metrics is what Serve parsed. It's closed when Serve returns
+func (c *Collector) Metrics() <-chan Metric { return c.metrics }
+
+// Serve reads datagrams until Close is called
+func (c *Collector) Serve() error {
+	defer close(c.metrics)
+
+	buf := make([]byte, readBuffer)
+	for {
+		// One read returns one whole datagram, from any client
+		n, _, err := c.conn.ReadFromUDP(buf)
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		c.packets.Add(1)
+
+		metrics, bad := parsePacket(buf[:n])
+		c.bad.Add(int64(bad))
+		for _, m := range metrics {
+			// Never wait for the aggregator. While Serve waits, nobody
+			// reads the socket, the kernel's buffer fills up, and the
+			// kernel drops datagrams without telling anyone. Dropping
+			// here at least counts them
+			select {
+			case c.metrics <- m:
+			default:
+				c.dropped.Add(1)
+			}
+		}
+	}
+}
+
+// Close stops Serve
+func (c *Collector) Close() error { return c.conn.Close() }
+
+// Client batches metrics into datagrams of up to maxPacket bytes
+type Client struct {
+	conn net.Conn
+	buf  []byte
+}
+
+// Dial doesn't send anything: a UDP "connection" only fixes the address
+// that Write sends to. It succeeds even if nobody is listening
+func Dial(addr string) (*Client, error) {
+	conn, err := net.Dial("udp", addr)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{conn: conn, buf: make([]byte, 0, maxPacket)}, nil
+}
+
+// Send adds m to the current datagram, and sends the datagram first if
+// m wouldn't fit
+func (c *Client) Send(m Metric) error {
+	line := m.String()
+	if len(c.buf) > 0 && len(c.buf)+1+len(line) > maxPacket {
+		if err := c.Flush(); err != nil {
+			return err
+		}
+	}
+	if len(c.buf) > 0 {
+		c.buf = append(c.buf, '\n')
+	}
+	c.buf = append(c.buf, line...)
+	return nil
+}
+
+// Flush sends the current datagram. A nil error means it left this
+// machine, not that it arrived
+func (c *Client) Flush() error {
+	if len(c.buf) == 0 {
+		return nil
+	}
+	_, err := c.conn.Write(c.buf)
+	c.buf = c.buf[:0]
+	return err
+}
+
+func (c *Client) Close() error {
+	err := c.Flush()
+	c.conn.Close()
+	return err
+}
+
+func main() {
+	fmt.Println("UDP and Connectionless Messaging")
+	fmt.Println("================================")
+	fmt.Println()
+
+	// Example 1: The statsd format
+	fmt.Println("1. Parsing the statsd format:")
+	for _, line := range []string{"requests:1|c", "requests:1|c|@0.1", "queue:42|g", "latency:12.5|ms", "requests", "requests:one|c", "requests:1|x"} {
+		if m, err := ParseMetric(line); err != nil {
+			fmt.Printf("   %-20s -> error: %v\n", line, err)
+		} else {
+			fmt.Printf("   %-20s -> %+v\n", line, m)
+		}
+	}
+	fmt.Println()
+
+	// Example 2: Collect and aggregate
+	fmt.Println("2. Many metrics in one datagram:")
+	col, err := Listen("127.0.0.1:0", 1000)
+	if err != nil {
+		fmt.Println("listen:", err)
+		os.Exit(1)
+	}
+	go col.Serve()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Aggregate(col.Metrics(), time.Hour, func(s Snapshot) {
+			fmt.Println("   snapshot:", s)
+		})
+	}()
+
+	c := mustDial(col.Addr().String())
+	c.Send(Metric{Name: "requests", Value: 1, Kind: Counter})
+	c.Send(Metric{Name: "requests", Value: 1, Kind: Counter})
+	c.Send(Metric{Name: "errors", Value: 1, Kind: Counter, Rate: 0.5})
+	c.Send(Metric{Name: "queue", Value: 7, Kind: Gauge})
+	c.Send(Metric{Name: "queue", Value: 3, Kind: Gauge})
+	for _, ms := range []float64{10, 20, 60} {
+		c.Send(Metric{Name: "latency", Value: ms, Kind: Timing})
+	}
+	c.Close()
+	waitFor(func() bool { return col.packets.Load() == 1 })
+
+	col.Close() // Serve closes Metrics, and Aggregate reports the rest
+	<-done
+	fmt.Printf("   datagrams: %d, metrics in them: 8\n", col.packets.Load())
+	fmt.Println()
+
+	// Example 3: Loss
+	fmt.Println("3. Eight clients, one datagram per metric:")
+	load(8, 5000, 50000, false)
+	fmt.Println()
+
+	fmt.Println("4. The same metrics, batched into fewer datagrams:")
+	load(8, 5000, 50000, true)
+	fmt.Println()
+
+	fmt.Println("5. Batched, but the aggregator's queue is small:")
+	load(8, 5000, 16, true)
+}
+
+// load sends perClient counters from each of n clients, and reports what
+// reached the aggregator. Unless batched, each counter is a datagram
+func load(n, perClient, queue int, batched bool) {
+	col, err := Listen("127.0.0.1:0", queue)
+	if err != nil {
+		fmt.Println("listen:", err)
+		return
+	}
+	go col.Serve()
+	var counted float64
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		Aggregate(col.Metrics(), time.Hour, func(s Snapshot) {
+			counted += s.Counters["hits"]
+		})
+	}()
+
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			c := mustDial(col.Addr().String())
+			defer c.Close()
+			for range perClient {
+				c.Send(Metric{Name: "hits", Value: 1, Kind: Counter})
+				if !batched {
+					c.Flush()
+				}
+			}
+		})
+	}
+	wg.Wait()
+	took := time.Since(start)
+
+	time.Sleep(100 * time.Millisecond) // let the last datagrams arrive
+	col.Close()
+	<-done
+
+	sent := n * perClient
+	fmt.Printf("   sent:      %6d metrics in %v\n", sent, took.Round(time.Millisecond))
+	fmt.Printf("   datagrams: %6d received\n", col.packets.Load())
+	fmt.Printf("   dropped:   %6d with the queue full\n", col.dropped.Load())
+	fmt.Printf("   counted:   %6.0f (%.1f%% of what was sent)\n", counted, 100*counted/float64(sent))
+}
+
+// waitFor polls until cond is true, or a second passes. UDP has no
+// acknowledgements, so there is nothing else to wait on
+func waitFor(cond func() bool) {
+	for deadline := time.Now().Add(time.Second); !cond() && time.Now().Before(deadline); {
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func mustDial(addr string) *Client {
+	c, err := Dial(addr)
+	if err != nil {
+		fmt.Println("dial:", err)
+		os.Exit(1)
+	}
+	return c
+}
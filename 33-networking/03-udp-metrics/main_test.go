@@ -0,0 +1,205 @@
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+)
+
+func TestParseMetric(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    Metric
+		wantErr bool
+	}{
+		{line: "requests:1|c", want: Metric{"requests", 1, Counter, 1}},
+		{line: "requests:1|c|@0.25", want: Metric{"requests", 1, Counter, 0.25}},
+		{line: "queue:-3|g", want: Metric{"queue", -3, Gauge, 1}},
+		{line: "db.query:12.5|ms", want: Metric{"db.query", 12.5, Timing, 1}},
+		{line: "", wantErr: true},
+		{line: "requests", wantErr: true},
+		{line: ":1|c", wantErr: true},
+		{line: "requests:1", wantErr: true},
+		{line: "requests:one|c", wantErr: true},
+		{line: "requests:1|x", wantErr: true},
+		{line: "requests:1|c|0.5", wantErr: true},
+		{line: "requests:1|c|@0", wantErr: true},
+		{line: "requests:1|c|@2", wantErr: true},
+		{line: "requests:1|c|@0.5|extra", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMetric(tt.line)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: want error %t; got %v", tt.line, tt.wantErr, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%q: want %+v; got %+v", tt.line, tt.want, got)
+		}
+		// A metric prints as the line it came from
+		if err == nil && got.String() != tt.line {
+			t.Errorf("%q: String() = %q", tt.line, got.String())
+		}
+	}
+}
+
+func TestParsePacket(t *testing.T) {
+	metrics, bad := parsePacket([]byte("a:1|c\nnot a metric\nb:2|g\n\nc:3|x\n"))
+	if len(metrics) != 2 || metrics[0].Name != "a" || metrics[1].Name != "b" {
+		t.Errorf("want a and b; got %+v", metrics)
+	}
+	if bad != 2 {
+		t.Errorf("want 2 bad lines; got %d", bad)
+	}
+}
+
+func TestAggregate(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		in := make(chan Metric)
+		var got []string
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			Aggregate(in, 10*time.Second, func(s Snapshot) {
+				got = append(got, s.String())
+			})
+		}()
+
+		in <- Metric{Name: "hits", Value: 1, Kind: Counter, Rate: 1}
+		in <- Metric{Name: "hits", Value: 1, Kind: Counter, Rate: 0.1}
+		in <- Metric{Name: "hits", Value: 2, Kind: Counter} // no rate means 1
+		in <- Metric{Name: "queue", Value: 5, Kind: Gauge}
+		in <- Metric{Name: "queue", Value: 2, Kind: Gauge}
+		in <- Metric{Name: "took", Value: 30, Kind: Timing}
+		in <- Metric{Name: "took", Value: 10, Kind: Timing}
+		in <- Metric{Name: "took", Value: 20, Kind: Timing}
+
+		time.Sleep(10 * time.Second) // the first interval ends
+		synctest.Wait()
+		time.Sleep(10 * time.Second) // nothing happened: no report
+		synctest.Wait()
+
+		in <- Metric{Name: "hits", Value: 1, Kind: Counter}
+		close(in) // reports what's left
+		<-done
+
+		want := []string{
+			"hits=13 queue=2 took=[n=3 min=10 max=30 avg=20]",
+			"hits=1",
+		}
+		if strings.Join(got, "|") != strings.Join(want, "|") {
+			t.Errorf("want %q; got %q", want, got)
+		}
+	})
+}
+
+func TestClientBatches(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	c, err := Dial(pc.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	const sent = 500
+	for i := range sent {
+		c.Send(Metric{Name: "requests", Value: float64(i), Kind: Counter})
+	}
+	c.Close()
+
+	// Loopback doesn't lose a few datagrams, so expect them all
+	var datagrams, metrics int
+	buf := make([]byte, readBuffer)
+	pc.SetReadDeadline(time.Now().Add(time.Second))
+	for metrics < sent {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("after %d metrics: %v", metrics, err)
+		}
+		if n > maxPacket {
+			t.Errorf("want datagrams of at most %d bytes; got %d", maxPacket, n)
+		}
+		got, bad := parsePacket(buf[:n])
+		if bad > 0 {
+			t.Errorf("a line was split across datagrams: %q", buf[:n])
+		}
+		datagrams++
+		metrics += len(got)
+	}
+	if datagrams < 2 || datagrams > 10 {
+		t.Errorf("want a few full datagrams; got %d", datagrams)
+	}
+}
+
+func TestCollector(t *testing.T) {
+	col, err := Listen("127.0.0.1:0", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- col.Serve() }()
+
+	conn, err := net.Dial("udp", col.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.Write([]byte("hits:1|c\ngarbage\nqueue:4|g"))
+
+	for _, want := range []string{"hits", "queue"} {
+		select {
+		case m := <-col.Metrics():
+			if m.Name != want {
+				t.Errorf("want %s; got %+v", want, m)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("no %s metric", want)
+		}
+	}
+	if n := col.bad.Load(); n != 1 {
+		t.Errorf("want 1 bad line; got %d", n)
+	}
+
+	col.Close()
+	if err := <-served; err != nil {
+		t.Errorf("want nil from Serve after Close; got %v", err)
+	}
+	if _, ok := <-col.Metrics(); ok {
+		t.Error("want Metrics closed")
+	}
+}
+
+func TestCollectorDropsWhenFull(t *testing.T) {
+	col, err := Listen("127.0.0.1:0", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer col.Close()
+	go col.Serve()
+
+	// Nobody reads Metrics: two fit in the queue, the rest are dropped
+	c, err := Dial(col.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 5 {
+		c.Send(Metric{Name: "hits", Value: 1, Kind: Counter})
+	}
+	c.Close()
+
+	deadline := time.Now().Add(time.Second)
+	for col.dropped.Load() < 3 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if n := col.dropped.Load(); n != 3 {
+		t.Errorf("want 3 dropped; got %d", n)
+	}
+	if n := len(col.Metrics()); n != 2 {
+		t.Errorf("want 2 queued; got %d", n)
+	}
+}
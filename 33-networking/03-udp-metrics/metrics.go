@@ -0,0 +1,182 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the type of a metric, the letters after the "|"
+type Kind string
+
+const (
+	Counter Kind = "c"  // adds up: requests, errors
+	Gauge   Kind = "g"  // the last value wins: queue length, memory
+	Timing  Kind = "ms" // every value counts: latencies
+)
+
+// Metric is one line of the statsd format:
+//
+//	name:value|kind
+//	name:value|c|@0.1   a counter sampled one time in ten
+type Metric struct {
+	Name  string
+	Value float64
+	Kind  Kind
+	Rate  float64 // the sample rate, for counters: 1 means every event
+}
+
+func (m Metric) String() string {
+	s := m.Name + ":" + strconv.FormatFloat(m.Value, 'f', -1, 64) + "|" + string(m.Kind)
+	if m.Rate > 0 && m.Rate < 1 {
+		s += "|@" + strconv.FormatFloat(m.Rate, 'f', -1, 64)
+	}
+	return s
+}
+
+var errFormat = errors.New("want name:value|kind")
+
+// ParseMetric parses one line. The input comes from the network, so
+// anything that isn't exactly right is an error
+func ParseMetric(line string) (Metric, error) {
+	name, rest, ok := strings.Cut(line, ":")
+	if !ok || name == "" {
+		return Metric{}, fmt.Errorf("%q: %w", line, errFormat)
+	}
+	fields := strings.Split(rest, "|")
+	if len(fields) < 2 || len(fields) > 3 {
+		return Metric{}, fmt.Errorf("%q: %w", line, errFormat)
+	}
+
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return Metric{}, fmt.Errorf("%q: bad value: %w", line, err)
+	}
+
+	m := Metric{Name: name, Value: value, Kind: Kind(fields[1]), Rate: 1}
+	switch m.Kind {
+	case Counter, Gauge, Timing:
+	default:
+		return Metric{}, fmt.Errorf("%q: unknown kind %q", line, fields[1])
+	}
+
+	if len(fields) == 3 {
+		rate, ok := strings.CutPrefix(fields[2], "@")
+		if m.Rate, err = strconv.ParseFloat(rate, 64); !ok || err != nil || m.Rate <= 0 || m.Rate > 1 {
+			return Metric{}, fmt.Errorf("%q: bad sample rate", line)
+		}
+	}
+	return m, nil
+}
+
+// parsePacket parses every line of one datagram, and counts the lines
+// it couldn't parse. A datagram is never split: it arrives whole, or
+// not at all, so a line never continues in the next one
+func parsePacket(p []byte) (metrics []Metric, bad int) {
+	for line := range strings.SplitSeq(string(p), "\n") {
+		if line == "" {
+			continue
+		}
+		m, err := ParseMetric(line)
+		if err != nil {
+			bad++
+			continue
+		}
+		metrics = append(metrics, m)
+	}
+	return metrics, bad
+}
+
+// Stats summarizes the timings of one interval
+type Stats struct {
+	Count         int
+	Min, Max, Avg float64
+}
+
+// Snapshot is what one interval added up to
+type Snapshot struct {
+	Counters map[string]float64
+	Gauges   map[string]float64
+	Timings  map[string]Stats
+}
+
+func (s Snapshot) String() string {
+	var b strings.Builder
+	for _, name := range slices.Sorted(maps.Keys(s.Counters)) {
+		fmt.Fprintf(&b, "%s=%g ", name, s.Counters[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Gauges)) {
+		fmt.Fprintf(&b, "%s=%g ", name, s.Gauges[name])
+	}
+	for _, name := range slices.Sorted(maps.Keys(s.Timings)) {
+		t := s.Timings[name]
+		fmt.Fprintf(&b, "%s=[n=%d min=%g max=%g avg=%g] ", name, t.Count, t.Min, t.Max, t.Avg)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// Aggregate adds up the metrics from in, and hands report a snapshot
+// every interval. It owns the totals, so it needs no lock. When in is
+// closed, it reports what's left and returns
+func Aggregate(in <-chan Metric, every time.Duration, report func(Snapshot)) {
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+
+	counters := make(map[string]float64)
+	gauges := make(map[string]float64)
+	timings := make(map[string][]float64)
+
+	flush := func() {
+		if len(counters)+len(gauges)+len(timings) == 0 {
+			return
+		}
+		s := Snapshot{Counters: counters, Gauges: gauges, Timings: make(map[string]Stats)}
+		for name, values := range timings {
+			sum := 0.0
+			for _, v := range values {
+				sum += v
+			}
+			s.Timings[name] = Stats{
+				Count: len(values),
+				Min:   slices.Min(values),
+				Max:   slices.Max(values),
+				Avg:   sum / float64(len(values)),
+			}
+		}
+		report(s)
+
+		// The snapshot keeps the old maps, so start new ones
+		counters = make(map[string]float64)
+		gauges = make(map[string]float64)
+		timings = make(map[string][]float64)
+	}
+
+	for {
+		select {
+		case m, ok := <-in:
+			if !ok {
+				flush()
+				return
+			}
+			switch m.Kind {
+			case Counter:
+				// One sampled event stands for 1/rate events
+				if m.Rate > 0 {
+					m.Value /= m.Rate
+				}
+				counters[m.Name] += m.Value
+			case Gauge:
+				gauges[m.Name] = m.Value
+			case Timing:
+				timings[m.Name] = append(timings[m.Name], m.Value)
+			}
+
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,244 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// generateCert makes a self-signed certificate for hosts, valid from
+// notBefore for validFor, and returns it and its private key as PEM:
+// the format of the .crt and .key files that servers load
+func generateCert(hosts []string, notBefore time.Time, validFor time.Duration) (certPEM, keyPEM []byte, err error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Every certificate from an issuer needs a unique serial number
+	serial, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{Organization: []string{"learngo"}, CommonName: hosts[0]},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(validFor),
+
+		KeyUsage:    x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+
+		// It signs itself, so it's its own authority: a client that
+		// trusts it trusts no other certificate
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	// Clients check the host they dialed against these, not CommonName
+	for _, h := range hosts {
+		if ip := net.ParseIP(h); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, h)
+		}
+	}
+
+	// The template is both the certificate and its issuer
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, err
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	certPEM = pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM = pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM, nil
+}
+
+// quietLog discards the server's log of failed handshakes: the demo
+// fails them on purpose
+var quietLog = log.New(io.Discard, "", 0)
+
+// serveTLS serves HTTPS on a random local port with the certificate,
+// and returns the port and a function that stops the server
+func serveTLS(certPEM, keyPEM []byte) (port string, stop func(), err error) {
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		return "", nil, err
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	srv := &http.Server{
+		Handler: http.HandlerFunc(hello),
+		TLSConfig: &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		},
+		ErrorLog: quietLog,
+	}
+	// The certificate is in TLSConfig, so no file names are needed
+	go srv.ServeTLS(ln, "", "")
+
+	_, port, _ = net.SplitHostPort(ln.Addr().String())
+	return port, func() { srv.Close() }, nil
+}
+
+// hello tells the client what the handshake agreed on
+func hello(w http.ResponseWriter, r *http.Request) {
+	fmt.Fprintf(w, "hello over %s, %s\n", tls.VersionName(r.TLS.Version), tls.CipherSuiteName(r.TLS.CipherSuite))
+}
+
+// newClient returns a client that trusts only the certificates in
+// rootsPEM, instead of the ones the operating system trusts
+func newClient(rootsPEM []byte) (*http.Client, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(rootsPEM) {
+		return nil, errors.New("no certificates in PEM")
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:    pool,
+		MinVersion: tls.VersionTLS12,
+	}
+	return &http.Client{Transport: transport, Timeout: 5 * time.Second}, nil
+}
+
+// why explains a failed request. Each kind of verification failure has
+// its own error type in crypto/x509
+func why(err error) string {
+	var (
+		unknown  x509.UnknownAuthorityError
+		hostname x509.HostnameError
+		invalid  x509.CertificateInvalidError
+		urlErr   *url.Error
+	)
+	switch {
+	case errors.As(err, &unknown):
+		return "unknown authority: nothing in the client's pool signed it"
+	case errors.As(err, &hostname):
+		return "wrong host: the certificate is for " + strings.Join(hostname.Certificate.DNSNames, ", ") + ", not " + hostname.Host
+	case errors.As(err, &invalid) && invalid.Reason == x509.Expired:
+		return "expired, or not valid yet: " + invalid.Detail
+	case errors.As(err, &invalid):
+		return "invalid: " + invalid.Error()
+	case errors.As(err, &urlErr):
+		return urlErr.Err.Error() // without the method and URL
+	}
+	return err.Error()
+}
+
+func main() {
+	fmt.Println("TLS Servers and Clients")
+	fmt.Println("=======================")
+	fmt.Println()
+
+	hosts := []string{"localhost", "127.0.0.1"}
+
+	// Example 1: A certificate
+	fmt.Println("1. Generating a self-signed certificate:")
+	certPEM, keyPEM, err := generateCert(hosts, time.Now().Add(-time.Hour), 24*time.Hour)
+	if err != nil {
+		fmt.Println("generate:", err)
+		os.Exit(1)
+	}
+	block, _ := pem.Decode(certPEM)
+	leaf, _ := x509.ParseCertificate(block.Bytes)
+	fmt.Println("  ", strings.SplitN(string(certPEM), "\n", 2)[0], fmt.Sprintf("(%d bytes of PEM)", len(certPEM)))
+	fmt.Println("   subject:  ", leaf.Subject)
+	fmt.Println("   hosts:    ", leaf.DNSNames, leaf.IPAddresses)
+	fmt.Println("   valid for:", leaf.NotAfter.Sub(leaf.NotBefore))
+	fmt.Println()
+
+	port, stop, err := serveTLS(certPEM, keyPEM)
+	if err != nil {
+		fmt.Println("serve:", err)
+		os.Exit(1)
+	}
+	defer stop()
+	url := "https://localhost:" + port
+
+	// Example 2: The default client doesn't trust it
+	fmt.Println("2. A client with the system's roots:")
+	fmt.Println("  ", fetch(http.DefaultClient, url))
+	fmt.Println()
+
+	// Example 3: A client that trusts it
+	fmt.Println("3. A client that trusts our certificate:")
+	client, err := newClient(certPEM)
+	if err != nil {
+		fmt.Println("client:", err)
+		os.Exit(1)
+	}
+	fmt.Println("  ", fetch(client, url))
+	fmt.Println("  ", fetch(client, "https://127.0.0.1:"+port))
+	fmt.Println()
+
+	// Example 4: Verification failures
+	fmt.Println("4. Verification failures:")
+
+	otherPEM, _, _ := generateCert(hosts, time.Now(), time.Hour)
+	other, _ := newClient(otherPEM)
+	fmt.Printf("   %-22s %s\n", "another certificate:", fetch(other, url))
+
+	wrongHost := cloneClient(client, func(c *tls.Config) { c.ServerName = "example.com" })
+	fmt.Printf("   %-22s %s\n", "another name:", fetch(wrongHost, url))
+
+	expiredPEM, expiredKey, _ := generateCert(hosts, time.Now().Add(-48*time.Hour), 24*time.Hour)
+	expiredPort, stopExpired, _ := serveTLS(expiredPEM, expiredKey)
+	defer stopExpired()
+	trustsExpired, _ := newClient(expiredPEM)
+	fmt.Printf("   %-22s %s\n", "an expired one:", fetch(trustsExpired, "https://localhost:"+expiredPort))
+
+	old := cloneClient(client, func(c *tls.Config) {
+		c.MinVersion = tls.VersionTLS10
+		c.MaxVersion = tls.VersionTLS11
+	})
+	fmt.Printf("   %-22s %s\n", "an old TLS version:", fetch(old, url))
+	fmt.Println()
+
+	// Example 5: Turning verification off
+	fmt.Println("5. InsecureSkipVerify connects to anyone, attackers included:")
+	insecure := cloneClient(other, func(c *tls.Config) { c.InsecureSkipVerify = true })
+	fmt.Println("  ", fetch(insecure, url))
+}
+
+// cloneClient returns a copy of c with its TLS config changed by edit
+func cloneClient(c *http.Client, edit func(*tls.Config)) *http.Client {
+	transport := c.Transport.(*http.Transport).Clone()
+	edit(transport.TLSClientConfig)
+	return &http.Client{Transport: transport, Timeout: c.Timeout}
+}
+
+// fetch requests url, and returns the reply, or why it failed
+func fetch(c *http.Client, url string) string {
+	resp, err := c.Get(url)
+	if err != nil {
+		return "error: " + why(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	return resp.Proto + " " + resp.Request.URL.Host + ": " + strings.TrimSpace(string(body))
+}
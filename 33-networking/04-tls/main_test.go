@@ -0,0 +1,162 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"testing"
+	"time"
+)
+
+func mustGenerate(t *testing.T, notBefore time.Time, validFor time.Duration) (certPEM, keyPEM []byte) {
+	t.Helper()
+	certPEM, keyPEM, err := generateCert([]string{"localhost", "127.0.0.1"}, notBefore, validFor)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return certPEM, keyPEM
+}
+
+func mustServe(t *testing.T, certPEM, keyPEM []byte) string {
+	t.Helper()
+	port, stop, err := serveTLS(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(stop)
+	return port
+}
+
+func TestGenerateCert(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	certPEM, keyPEM := mustGenerate(t, now, time.Hour)
+
+	// The key must belong to the certificate
+	if _, err := tls.X509KeyPair(certPEM, keyPEM); err != nil {
+		t.Fatalf("X509KeyPair: %v", err)
+	}
+
+	block, rest := pem.Decode(certPEM)
+	if block == nil || block.Type != "CERTIFICATE" || len(rest) != 0 {
+		t.Fatalf("want one CERTIFICATE block; got %v", block)
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cert.VerifyHostname("localhost"); err != nil {
+		t.Error(err)
+	}
+	if err := cert.VerifyHostname("127.0.0.1"); err != nil {
+		t.Error(err)
+	}
+	if !cert.NotBefore.Equal(now) || !cert.NotAfter.Equal(now.Add(time.Hour)) {
+		t.Errorf("want valid from %v for an hour; got %v to %v", now, cert.NotBefore, cert.NotAfter)
+	}
+
+	// Two certificates never share a serial number
+	otherPEM, _ := mustGenerate(t, now, time.Hour)
+	other, _ := pem.Decode(otherPEM)
+	otherCert, _ := x509.ParseCertificate(other.Bytes)
+	if cert.SerialNumber.Cmp(otherCert.SerialNumber) == 0 {
+		t.Error("want unique serial numbers")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	certPEM, keyPEM := mustGenerate(t, now.Add(-time.Hour), 24*time.Hour)
+	port := mustServe(t, certPEM, keyPEM)
+
+	expiredPEM, expiredKey := mustGenerate(t, now.Add(-48*time.Hour), 24*time.Hour)
+	expiredPort := mustServe(t, expiredPEM, expiredKey)
+
+	futurePEM, futureKey := mustGenerate(t, now.Add(time.Hour), 24*time.Hour)
+	futurePort := mustServe(t, futurePEM, futureKey)
+
+	otherPEM, _ := mustGenerate(t, now, time.Hour)
+
+	var (
+		unknown  x509.UnknownAuthorityError
+		hostname x509.HostnameError
+		invalid  x509.CertificateInvalidError
+	)
+	tests := []struct {
+		name       string
+		trust      []byte
+		serverName string // what the client checks, if not the host it dialed
+		url        string
+		wantErr    any // a pointer to the error type, or nil
+	}{
+		{"trusted by name", certPEM, "", "https://localhost:" + port, nil},
+		{"trusted by IP", certPEM, "", "https://127.0.0.1:" + port, nil},
+		{"another certificate", otherPEM, "", "https://localhost:" + port, &unknown},
+		{"another name", certPEM, "example.com", "https://localhost:" + port, &hostname},
+		{"expired", expiredPEM, "", "https://localhost:" + expiredPort, &invalid},
+		{"not valid yet", futurePEM, "", "https://localhost:" + futurePort, &invalid},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, err := newClient(tt.trust)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.serverName != "" {
+				client = cloneClient(client, func(c *tls.Config) { c.ServerName = tt.serverName })
+			}
+
+			resp, err := client.Get(tt.url)
+			if tt.wantErr == nil {
+				if err != nil {
+					t.Fatalf("want success; got %v", err)
+				}
+				resp.Body.Close()
+				if resp.TLS == nil || resp.TLS.Version < tls.VersionTLS12 {
+					t.Errorf("want TLS 1.2 or later; got %+v", resp.TLS)
+				}
+				return
+			}
+			if !errors.As(err, tt.wantErr) {
+				t.Fatalf("want %T; got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestMinVersion(t *testing.T) {
+	certPEM, keyPEM := mustGenerate(t, time.Now().Add(-time.Hour), time.Hour*2)
+	port := mustServe(t, certPEM, keyPEM)
+	client, err := newClient(certPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		max uint16
+		ok  bool
+	}{
+		{tls.VersionTLS11, false},
+		{tls.VersionTLS12, true},
+		{tls.VersionTLS13, true},
+	}
+	for _, tt := range tests {
+		c := cloneClient(client, func(c *tls.Config) {
+			c.MinVersion = tls.VersionTLS10
+			c.MaxVersion = tt.max
+		})
+		resp, err := c.Get("https://localhost:" + port)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if (err == nil) != tt.ok {
+			t.Errorf("%s: want ok %t; got %v", tls.VersionName(tt.max), tt.ok, err)
+		}
+	}
+}
+
+func TestNewClientRejectsEmptyPEM(t *testing.T) {
+	if _, err := newClient([]byte("not a certificate")); err == nil {
+		t.Error("want an error")
+	}
+}
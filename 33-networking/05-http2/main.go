@@ -0,0 +1,258 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// server counts the connections its clients open
+type server struct {
+	conns atomic.Int64
+}
+
+func (s *server) connState(_ net.Conn, state http.ConnState) {
+	if state == http.StateNew {
+		s.conns.Add(1)
+	}
+}
+
+func (s *server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /proto", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, r.Proto)
+	})
+
+	mux.HandleFunc("GET /slow", func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(50 * time.Millisecond)
+		fmt.Fprint(w, "done")
+	})
+
+	// /stream sends n lines, one every 20ms. Each is flushed, so the
+	// client sees it at once
+	mux.HandleFunc("GET /stream", func(w http.ResponseWriter, r *http.Request) {
+		n, _ := strconv.Atoi(r.URL.Query().Get("n"))
+		rc := http.NewResponseController(w)
+		for i := range n {
+			select {
+			case <-time.After(20 * time.Millisecond):
+			case <-r.Context().Done():
+				return
+			}
+			fmt.Fprintf(w, "tick %d\n", i+1)
+			rc.Flush()
+		}
+	})
+
+	// /page sends a 103 Early Hints response first: the browser can
+	// start loading the stylesheet while the server builds the page
+	mux.HandleFunc("GET /page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Link", "</style.css>; rel=preload; as=style")
+		w.WriteHeader(http.StatusEarlyHints)
+
+		time.Sleep(20 * time.Millisecond) // building the page
+		fmt.Fprint(w, "<html>...</html>")
+	})
+
+	return mux
+}
+
+// startTLS serves HTTPS, and offers both HTTP/2 and HTTP/1.1 during
+// the handshake
+func startTLS(s *server) *httptest.Server {
+	ts := httptest.NewUnstartedServer(s.routes())
+	ts.Config.ConnState = s.connState
+	ts.EnableHTTP2 = true
+	// ALPN: the protocols the server offers, in order of preference.
+	// httptest offers only "h2" unless told otherwise
+	ts.TLS = &tls.Config{NextProtos: []string{"h2", "http/1.1"}}
+	ts.StartTLS()
+	return ts
+}
+
+// startH2C serves HTTP/1.1 and HTTP/2 without TLS
+func startH2C(s *server) *httptest.Server {
+	ts := httptest.NewUnstartedServer(s.routes())
+	ts.Config.ConnState = s.connState
+	ts.Config.Protocols = new(http.Protocols)
+	ts.Config.Protocols.SetHTTP1(true)
+	ts.Config.Protocols.SetUnencryptedHTTP2(true)
+	ts.Start()
+	return ts
+}
+
+// Protocols a client can speak
+const (
+	http1 = 1 << iota
+	http2
+	h2c // HTTP/2 without TLS
+)
+
+// client returns a client that trusts ts's certificate, and speaks
+// only the protocols in protos. maxConns limits its connections to the
+// server, if it's not 0
+func client(ts *httptest.Server, protos, maxConns int) *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.MaxConnsPerHost = maxConns
+
+	if cert := ts.Certificate(); cert != nil {
+		pool := x509.NewCertPool()
+		pool.AddCert(cert)
+		transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	}
+
+	transport.Protocols = new(http.Protocols)
+	transport.Protocols.SetHTTP1(protos&http1 != 0)
+	transport.Protocols.SetHTTP2(protos&http2 != 0)
+	transport.Protocols.SetUnencryptedHTTP2(protos&h2c != 0)
+	return &http.Client{Transport: transport, Timeout: 5 * time.Second}
+}
+
+func main() {
+	fmt.Println("HTTP/2, h2c, and Protocol Negotiation")
+	fmt.Println("=====================================")
+	fmt.Println()
+
+	s := &server{}
+	tlsServer := startTLS(s)
+	defer tlsServer.Close()
+
+	// Example 1: ALPN
+	fmt.Println("1. Over TLS, the handshake picks the protocol (ALPN):")
+	for _, c := range []struct {
+		name   string
+		protos int
+	}{
+		{"offers h2 and http/1.1", http1 | http2},
+		{"offers http/1.1 only", http1},
+	} {
+		resp, err := client(tlsServer, c.protos, 0).Get(tlsServer.URL + "/proto")
+		if err != nil {
+			fmt.Println("   error:", err)
+			continue
+		}
+		resp.Body.Close()
+		alpn := resp.TLS.NegotiatedProtocol
+		if alpn == "" {
+			alpn = "none" // HTTP/1.1 is the default
+		}
+		fmt.Printf("   a client that %-22s -> %s (ALPN: %s)\n", c.name, resp.Proto, alpn)
+	}
+	fmt.Println()
+
+	// Example 2: h2c
+	fmt.Println("2. Without TLS there is no handshake, so the client must know:")
+	h2cServer := startH2C(&server{})
+	defer h2cServer.Close()
+	for _, c := range []struct {
+		name   string
+		protos int
+	}{
+		{"default client", http1 | http2},
+		{"h2c client", h2c},
+	} {
+		resp, err := client(h2cServer, c.protos, 0).Get(h2cServer.URL + "/proto")
+		if err != nil {
+			fmt.Println("   error:", err)
+			continue
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("   %-15s -> the server saw %s\n", c.name, body)
+	}
+	fmt.Println()
+
+	// Example 3: Multiplexing
+	fmt.Println("3. Ten requests at once, each taking 50ms:")
+	// Without a limit, HTTP/2 would dial ten connections too: they all
+	// start before the first one is ready to share
+	for _, c := range []struct {
+		name     string
+		protos   int
+		maxConns int
+	}{
+		{"HTTP/1.1", http1, 0},
+		{"HTTP/1.1, 1 conn", http1, 1},
+		{"HTTP/2, 1 conn", http2, 1},
+	} {
+		took, conns := concurrently(s, client(tlsServer, c.protos, c.maxConns), 10, tlsServer.URL+"/slow")
+		fmt.Printf("   %-17s %-6v connections: %d\n", c.name+":", took, conns)
+	}
+	fmt.Println()
+
+	// Example 4: Streams
+	fmt.Println("4. Three streams of 5 ticks each, one connection:")
+	for _, c := range []struct {
+		name   string
+		protos int
+	}{
+		{"HTTP/1.1", http1},
+		{"HTTP/2", http2},
+	} {
+		took, conns := concurrently(s, client(tlsServer, c.protos, 1), 3, tlsServer.URL+"/stream?n=5")
+		fmt.Printf("   %-17s %-6v connections: %d\n", c.name+":", took, conns)
+	}
+	fmt.Println()
+
+	// Example 5: Early hints
+	fmt.Println("5. Server push is gone; 103 Early Hints replaced it:")
+	earlyHints(client(tlsServer, http2, 0), tlsServer.URL+"/page")
+}
+
+// concurrently makes n requests at once, reads each body to the end, and
+// reports how long that took, and how many connections it opened
+func concurrently(s *server, c *http.Client, n int, url string) (time.Duration, int64) {
+	before := s.conns.Load()
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			resp, err := c.Get(url)
+			if err != nil {
+				fmt.Println("   error:", err)
+				return
+			}
+			defer resp.Body.Close()
+			io.Copy(io.Discard, resp.Body)
+		})
+	}
+	wg.Wait()
+
+	c.CloseIdleConnections()
+	return time.Since(start).Round(10 * time.Millisecond), s.conns.Load() - before
+}
+
+// earlyHints prints the 1xx responses that arrive before the final one.
+// The client doesn't return them; a trace hook sees them
+func earlyHints(c *http.Client, url string) {
+	start := time.Now()
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			fmt.Printf("   after %-5v %d Link: %s\n", time.Since(start).Round(10*time.Millisecond), code, header.Get("Link"))
+			return nil
+		},
+	}
+	req, _ := http.NewRequest("GET", url, nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := c.Do(req)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	fmt.Printf("   after %-5v %d %s\n", time.Since(start).Round(10*time.Millisecond), resp.StatusCode, body)
+}
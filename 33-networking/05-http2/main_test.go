@@ -0,0 +1,130 @@
+package main
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"testing"
+)
+
+func TestProtocols(t *testing.T) {
+	tlsServer := startTLS(&server{})
+	defer tlsServer.Close()
+	h2cServer := startH2C(&server{})
+	defer h2cServer.Close()
+
+	tests := []struct {
+		name   string
+		ts     *httptest.Server
+		protos int
+		want   string
+	}{
+		{"TLS, both offered", tlsServer, http1 | http2, "HTTP/2.0"},
+		{"TLS, h2 only", tlsServer, http2, "HTTP/2.0"},
+		{"TLS, http/1.1 only", tlsServer, http1, "HTTP/1.1"},
+		{"cleartext, default", h2cServer, http1 | http2, "HTTP/1.1"},
+		{"cleartext, h2c", h2cServer, h2c, "HTTP/2.0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := client(tt.ts, tt.protos, 0).Get(tt.ts.URL + "/proto")
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.Proto != tt.want {
+				t.Errorf("want %s; got %s", tt.want, resp.Proto)
+			}
+		})
+	}
+}
+
+func TestMultiplexing(t *testing.T) {
+	tests := []struct {
+		name      string
+		protos    int
+		maxConns  int
+		wantConns int64
+	}{
+		{"HTTP/1.1", http1, 0, 10},
+		{"HTTP/1.1, 1 conn", http1, 1, 1},
+		{"HTTP/2, 1 conn", http2, 1, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &server{}
+			ts := startTLS(s)
+			defer ts.Close()
+
+			if _, conns := concurrently(s, client(ts, tt.protos, tt.maxConns), 10, ts.URL+"/slow"); conns != tt.wantConns {
+				t.Errorf("want %d connections; got %d", tt.wantConns, conns)
+			}
+		})
+	}
+}
+
+// An HTTP/2 connection carries two streams at the same time: the second
+// response starts while the first is still arriving. Over HTTP/1.1, it
+// would have to wait for the first to end
+func TestStreamsInterleave(t *testing.T) {
+	ts := startTLS(&server{})
+	defer ts.Close()
+	c := client(ts, http2, 1)
+
+	first, err := c.Get(ts.URL + "/stream?n=100")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer first.Body.Close()
+	firstLines := bufio.NewScanner(first.Body)
+	firstLines.Scan()
+
+	second, err := c.Get(ts.URL + "/stream?n=1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer second.Body.Close()
+	secondLines := bufio.NewScanner(second.Body)
+	if !secondLines.Scan() || secondLines.Text() != "tick 1" {
+		t.Errorf("want the second stream to start; got %q, %v", secondLines.Text(), secondLines.Err())
+	}
+
+	if !firstLines.Scan() || firstLines.Text() != "tick 2" {
+		t.Errorf("want the first stream to go on; got %q, %v", firstLines.Text(), firstLines.Err())
+	}
+}
+
+func TestEarlyHints(t *testing.T) {
+	ts := startTLS(&server{})
+	defer ts.Close()
+
+	var codes []int
+	var link string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			codes = append(codes, code)
+			link = header.Get("Link")
+			return nil
+		},
+	}
+	req, _ := http.NewRequest("GET", ts.URL+"/page", nil)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+
+	resp, err := client(ts, http2, 0).Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if len(codes) != 1 || codes[0] != http.StatusEarlyHints {
+		t.Errorf("want one 103; got %v", codes)
+	}
+	if link != "</style.css>; rel=preload; as=style" {
+		t.Errorf("want the preload link; got %q", link)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("want 200 after the hints; got %d", resp.StatusCode)
+	}
+}
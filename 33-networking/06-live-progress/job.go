@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Progress is a job's state at one moment. Every transport sends it as
+// JSON
+type Progress struct {
+	Version int       `json:"version"` // goes up by one with every update
+	Percent int       `json:"percent"`
+	Step    string    `json:"step"`
+	Done    bool      `json:"done"`
+	Sent    time.Time `json:"sent"` // when it was published, to measure latency
+}
+
+// Job is the backend all three transports share. A worker sends
+// updates on a channel; Job keeps the latest one, and wakes everyone
+// waiting for it.
+//
+// Watchers get the latest state, not every update: a watcher that falls
+// behind skips the versions it missed. For progress, that is what you
+// want; a watcher never sees a stale percentage
+type Job struct {
+	mu      sync.Mutex
+	cur     Progress
+	changed chan struct{} // closed, and replaced, on every update
+	cancel  context.CancelFunc
+}
+
+// Start runs a job that goes through steps, one every tick
+func Start(steps []string, tick time.Duration) *Job {
+	ctx, cancel := context.WithCancel(context.Background())
+	updates := make(chan Progress)
+	go work(ctx, steps, tick, updates)
+	return Track(updates, cancel)
+}
+
+// Track publishes every update from updates, until the channel is
+// closed. cancel, if not nil, is what Cancel calls
+func Track(updates <-chan Progress, cancel context.CancelFunc) *Job {
+	j := &Job{changed: make(chan struct{}), cancel: cancel}
+	go func() {
+		for p := range updates {
+			j.publish(p)
+		}
+	}()
+	return j
+}
+
+func (j *Job) publish(p Progress) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	p.Version = j.cur.Version + 1
+	p.Sent = time.Now()
+	j.cur = p
+	close(j.changed) // wakes every waiter at once
+	j.changed = make(chan struct{})
+}
+
+// Next returns the first state newer than version after, waiting for
+// one until ctx is done. A finished job has nothing newer coming, so
+// Next returns its last state at once
+func (j *Job) Next(ctx context.Context, after int) (Progress, error) {
+	for {
+		j.mu.Lock()
+		cur, changed := j.cur, j.changed
+		j.mu.Unlock()
+
+		if cur.Version > after || cur.Done {
+			return cur, nil
+		}
+		select {
+		case <-changed:
+		case <-ctx.Done():
+			return cur, ctx.Err()
+		}
+	}
+}
+
+// Cancel stops the job's worker, if it has one
+func (j *Job) Cancel() {
+	if j.cancel != nil {
+		j.cancel()
+	}
+}
+
+// work pretends to do a job, and reports its progress on updates. It
+// closes updates when it's done, or canceled
+func work(ctx context.Context, steps []string, tick time.Duration, updates chan<- Progress) {
+	defer close(updates)
+
+	for i, step := range steps {
+		updates <- Progress{Percent: i * 100 / len(steps), Step: step}
+		select {
+		case <-time.After(tick):
+		case <-ctx.Done():
+			updates <- Progress{Percent: i * 100 / len(steps), Step: "canceled", Done: true}
+			return
+		}
+	}
+	updates <- Progress{Percent: 100, Step: "finished", Done: true}
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// newMux serves one job three ways
+func newMux(j *Job) http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("GET /poll", pollHandler(j, pollWait))
+	mux.Handle("GET /events", sseHandler(j, sseKeepalive))
+	mux.Handle("GET /ws", wsHandler(j))
+	return mux
+}
+
+var steps = []string{"download", "unpack", "resize", "upload", "clean up"}
+
+// watcher is any of the three clients, watching the job served at base.
+// It calls seen with every state it gets
+type watcher func(ctx context.Context, base string, seen func(Progress)) error
+
+func pollWith(client *http.Client) watcher {
+	return func(ctx context.Context, base string, seen func(Progress)) error {
+		return watchPoll(ctx, client, base, seen)
+	}
+}
+
+func sse(ctx context.Context, base string, seen func(Progress)) error {
+	return watchSSE(ctx, http.DefaultClient, base, seen)
+}
+
+func ws(ctx context.Context, base string, seen func(Progress)) error {
+	return watchWS(ctx, "ws"+strings.TrimPrefix(base, "http"), func(p Progress) bool {
+		seen(p)
+		return false
+	})
+}
+
+func main() {
+	fmt.Println("Live Progress: Long Polling vs SSE vs WebSockets")
+	fmt.Println("================================================")
+	fmt.Println()
+
+	// Example 1: One job, three transports
+	fmt.Println("1. Three watchers on one job, one per transport:")
+	job := Start(steps, 100*time.Millisecond)
+	srv := httptest.NewServer(newMux(job))
+
+	transports := []struct {
+		name  string
+		watch watcher
+	}{
+		{"poll", pollWith(http.DefaultClient)},
+		{"sse", sse},
+		{"ws", ws},
+	}
+	lines := make([]string, len(transports))
+	var wg sync.WaitGroup
+	for i, t := range transports {
+		wg.Go(func() {
+			var seen []string
+			err := t.watch(context.Background(), srv.URL, func(p Progress) {
+				seen = append(seen, fmt.Sprintf("%d%%", p.Percent))
+			})
+			lines[i] = fmt.Sprintf("%-4s %s, %s", t.name, strings.Join(seen, " "), errOrDone(err))
+		})
+	}
+	wg.Wait()
+	for _, line := range lines {
+		fmt.Println("  ", line)
+	}
+	srv.Close()
+	fmt.Println()
+
+	// Example 2: The SSE wire format, resumed with Last-Event-ID
+	fmt.Println("2. An SSE stream, resumed after event 4:")
+	job = Start(steps, 50*time.Millisecond)
+	srv = httptest.NewServer(newMux(job))
+	req, _ := http.NewRequest("GET", srv.URL+"/events", nil)
+	req.Header.Set("Last-Event-ID", "4")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		log.Fatal(err)
+	}
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		if i := strings.Index(line, `,"sent"`); i > 0 {
+			line = line[:i] + ",...}" // the timestamp changes on every run
+		}
+		fmt.Println(strings.TrimRight("   "+line, " "))
+	}
+	resp.Body.Close()
+	srv.Close()
+
+	// Example 3: Talking back
+	fmt.Println("3. A WebSocket client cancels the job on the same connection:")
+	job = Start(steps, 50*time.Millisecond)
+	srv = httptest.NewServer(newMux(job))
+	err = watchWS(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"), func(p Progress) bool {
+		fmt.Printf("   %3d%% %s\n", p.Percent, p.Step)
+		return p.Percent >= 40 && !p.Done
+	})
+	fmt.Printf("   %v\n", errOrDone(err))
+	srv.Close()
+	fmt.Println()
+
+	// Example 4: What each one costs
+	const watchers, updates = 200, 20
+	fmt.Printf("4. %d watchers, %d updates, 20ms apart:\n", watchers, updates)
+	fmt.Printf("   %-12s %6s %9s %9s %6s %8s %8s\n", "", "conns", "requests", "KB", "seen", "p50", "p99")
+
+	// A poller reuses its connection between polls, if the transport
+	// keeps enough idle ones around: the default keeps 2 per host
+	pooled := &http.Client{Transport: &http.Transport{MaxIdleConnsPerHost: watchers}}
+	for _, w := range []struct {
+		name  string
+		watch watcher
+	}{
+		{"long polling", pollWith(pooled)},
+		{"sse", sse},
+		{"ws", ws},
+	} {
+		r := measure(watchers, updates, 20*time.Millisecond, w.watch)
+		fmt.Printf("   %-12s %6d %9d %9.0f %5.0f%% %8v %8v\n", w.name,
+			r.conns, r.requests, float64(r.bytes)/1024, r.seen*100,
+			r.p50.Round(10*time.Microsecond), r.p99.Round(10*time.Microsecond))
+	}
+	fmt.Println()
+	fmt.Println("   seen: the share of updates each watcher got; a poller misses")
+	fmt.Println("   those published while its next request is on the way")
+}
+
+func errOrDone(err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return "done"
+}
+
+// result is what measure saw, on the server and on the watchers
+type result struct {
+	conns, requests, bytes int64
+	seen                   float64 // the share of updates watchers got
+	p50, p99               time.Duration
+}
+
+// measure serves a job with n updates, tick apart, to watchers
+// watchers, and counts the connections, requests, and bytes on the
+// server, and how late each update reached each watcher
+func measure(watchers, n int, tick time.Duration, watch watcher) result {
+	var m meter
+	updates := make(chan Progress)
+	job := Track(updates, nil)
+
+	mux := newMux(job)
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		m.requests.Add(1)
+		mux.ServeHTTP(w, r)
+	}))
+	srv.Listener = &countingListener{Listener: srv.Listener, m: &m}
+	srv.Config.ConnState = func(_ net.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			m.conns.Add(1)
+		}
+	}
+	srv.Start()
+	defer srv.Close()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		wg        sync.WaitGroup
+		ready     sync.WaitGroup
+	)
+	ready.Add(watchers)
+	for range watchers {
+		wg.Go(func() {
+			first := true
+			watch(context.Background(), srv.URL, func(p Progress) {
+				if first { // the state before the first update
+					first = false
+					ready.Done()
+					return
+				}
+				mu.Lock()
+				latencies = append(latencies, time.Since(p.Sent))
+				mu.Unlock()
+			})
+			if first { // it failed before it was ready
+				ready.Done()
+			}
+		})
+	}
+
+	updates <- Progress{Step: "waiting"}
+	ready.Wait() // every watcher is connected
+	for i := range n {
+		time.Sleep(tick)
+		updates <- Progress{Percent: (i + 1) * 100 / n, Done: i == n-1}
+	}
+	close(updates)
+	wg.Wait()
+
+	r := result{
+		conns:    m.conns.Load(),
+		requests: m.requests.Load(),
+		bytes:    m.bytes.Load(),
+		seen:     float64(len(latencies)) / float64(watchers*n),
+	}
+	if len(latencies) > 0 {
+		slices.Sort(latencies)
+		r.p50 = latencies[len(latencies)/2]
+		r.p99 = latencies[len(latencies)*99/100]
+	}
+	return r
+}
+
+// meter counts what a server handles
+type meter struct {
+	conns, requests, bytes atomic.Int64
+}
+
+// countingListener counts the bytes read and written on every
+// connection it accepts, including hijacked WebSocket connections
+type countingListener struct {
+	net.Listener
+	m *meter
+}
+
+func (l *countingListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &countingConn{Conn: c, m: l.m}, nil
+}
+
+type countingConn struct {
+	net.Conn
+	m *meter
+}
+
+func (c *countingConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	c.m.bytes.Add(int64(n))
+	return n, err
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	c.m.bytes.Add(int64(n))
+	return n, err
+}
@@ -0,0 +1,330 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+func TestJobNext(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		updates := make(chan Progress)
+		job := Track(updates, nil)
+
+		got := make(chan Progress)
+		go func() {
+			p, _ := job.Next(context.Background(), 0)
+			got <- p
+		}()
+		synctest.Wait()
+		select {
+		case p := <-got:
+			t.Fatalf("want Next to wait for an update; got %+v", p)
+		default:
+		}
+
+		updates <- Progress{Percent: 10}
+		if p := <-got; p.Version != 1 || p.Percent != 10 {
+			t.Errorf("want version 1 at 10%%; got %+v", p)
+		}
+
+		// Nothing newer than 1 comes within the timeout
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		p, err := job.Next(ctx, 1)
+		if !errors.Is(err, context.DeadlineExceeded) || p.Version != 1 {
+			t.Errorf("want the current state and DeadlineExceeded; got %+v, %v", p, err)
+		}
+
+		// A watcher that fell behind gets the latest state, not each one
+		updates <- Progress{Percent: 20}
+		updates <- Progress{Percent: 30}
+		synctest.Wait()
+		if p, _ := job.Next(context.Background(), 1); p.Version != 3 || p.Percent != 30 {
+			t.Errorf("want version 3 at 30%%; got %+v", p)
+		}
+
+		// A finished job answers at once, even for a version it never had
+		updates <- Progress{Percent: 100, Done: true}
+		close(updates)
+		synctest.Wait()
+		if p, err := job.Next(context.Background(), 99); err != nil || !p.Done {
+			t.Errorf("want the final state; got %+v, %v", p, err)
+		}
+	})
+}
+
+func TestStartAndCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+		job := Start([]string{"a", "b", "c", "d"}, time.Second)
+
+		var percents []int
+		for after := 0; ; {
+			p, _ := job.Next(context.Background(), after)
+			percents = append(percents, p.Percent)
+			if p.Done {
+				break
+			}
+			after = p.Version
+		}
+		if got := time.Since(start); got != 4*time.Second {
+			t.Errorf("want 4 steps of 1s; took %v", got)
+		}
+		if want := []int{0, 25, 50, 75, 100}; !slices.Equal(percents, want) {
+			t.Errorf("want %v; got %v", want, percents)
+		}
+
+		job = Start([]string{"a", "b", "c", "d"}, time.Second)
+		time.Sleep(1500 * time.Millisecond)
+		job.Cancel()
+		p, _ := job.Next(context.Background(), 2)
+		if !p.Done || p.Step != "canceled" || p.Percent != 25 {
+			t.Errorf("want canceled at 25%%; got %+v", p)
+		}
+	})
+}
+
+func TestPollHandler(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		updates := make(chan Progress)
+		defer close(updates)
+		job := Track(updates, nil)
+		h := pollHandler(job, 25*time.Second)
+		updates <- Progress{Percent: 10}
+
+		poll := func(after string) (*httptest.ResponseRecorder, time.Duration) {
+			start := time.Now()
+			w := httptest.NewRecorder()
+			h(w, httptest.NewRequest("GET", "/poll?after="+after, nil))
+			return w, time.Since(start)
+		}
+
+		// A newer state is there already: no wait
+		if w, took := poll("0"); w.Code != 200 || took != 0 || !strings.Contains(w.Body.String(), `"version":1`) {
+			t.Errorf("want version 1 at once; got %d %s after %v", w.Code, w.Body, took)
+		}
+
+		// Nothing new for the whole wait: 204
+		if w, took := poll("1"); w.Code != http.StatusNoContent || took != 25*time.Second {
+			t.Errorf("want 204 after 25s; got %d after %v", w.Code, took)
+		}
+
+		// An update during the wait answers the poll right away
+		go func() {
+			time.Sleep(3 * time.Second)
+			updates <- Progress{Percent: 50}
+		}()
+		if w, took := poll("1"); w.Code != 200 || took != 3*time.Second || !strings.Contains(w.Body.String(), `"percent":50`) {
+			t.Errorf("want 50%% after 3s; got %d %s after %v", w.Code, w.Body, took)
+		}
+
+		// A bad version is 0: the client gets the current state
+		if w, _ := poll("abc"); w.Code != 200 || !strings.Contains(w.Body.String(), `"version":2`) {
+			t.Errorf("want the current state; got %d %s", w.Code, w.Body)
+		}
+	})
+}
+
+func TestSSEHandler(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		updates := make(chan Progress)
+		defer close(updates)
+		job := Track(updates, nil)
+		updates <- Progress{Percent: 10, Step: "one"}
+		updates <- Progress{Percent: 20, Step: "two"}
+
+		w := httptest.NewRecorder()
+		r := httptest.NewRequest("GET", "/events", nil)
+		r.Header.Set("Last-Event-ID", "1") // resume after event 1
+		done := make(chan struct{})
+		go func() {
+			sseHandler(job, 15*time.Second)(w, r)
+			close(done)
+		}()
+
+		time.Sleep(20 * time.Second) // one keepalive, at 15s after event 2
+		updates <- Progress{Percent: 100, Step: "end", Done: true}
+		<-done
+
+		if ct := w.Header().Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("want text/event-stream; got %q", ct)
+		}
+		events := strings.Split(strings.TrimSuffix(w.Body.String(), "\n\n"), "\n\n")
+		if len(events) != 3 {
+			t.Fatalf("want 3 events; got %q", events)
+		}
+		for i, want := range []string{
+			"id: 2\nevent: progress\ndata: {\"version\":2,\"percent\":20,\"step\":\"two\"",
+			": keepalive",
+			"id: 3\nevent: done\ndata: {\"version\":3,\"percent\":100,\"step\":\"end\",\"done\":true",
+		} {
+			if !strings.HasPrefix(events[i], want) {
+				t.Errorf("event %d: want %q; got %q", i, want, events[i])
+			}
+		}
+	})
+}
+
+func TestSSEHandlerClientGone(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		updates := make(chan Progress)
+		defer close(updates)
+		job := Track(updates, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+		go func() {
+			sseHandler(job, 15*time.Second)(httptest.NewRecorder(), httptest.NewRequestWithContext(ctx, "GET", "/events", nil))
+			close(done)
+		}()
+
+		time.Sleep(time.Minute)
+		cancel()
+		synctest.Wait()
+		select {
+		case <-done:
+		default:
+			t.Error("want the handler to return when the client goes")
+		}
+	})
+}
+
+func TestWatchSSEParsing(t *testing.T) {
+	tests := []struct {
+		name   string
+		stream string
+		seen   int
+		err    error
+	}{
+		{"events and comments", ": hi\n\nid: 1\ndata: {\"version\":1}\n\n: keepalive\n\nevent: done\ndata: {\"version\":2,\"done\":true}\n\n", 2, nil},
+		{"data on two lines", "data: {\"version\":1,\ndata: \"done\":true}\n\n", 1, nil},
+		{"no space after the colon", "data:{\"done\":true}\n\n", 1, nil},
+		{"cut off", "data: {\"version\":1}\n\n", 1, io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				io.WriteString(w, tt.stream)
+			}))
+			defer srv.Close()
+
+			seen := 0
+			err := watchSSE(context.Background(), srv.Client(), srv.URL, func(Progress) { seen++ })
+			if seen != tt.seen || !errors.Is(err, tt.err) {
+				t.Errorf("want %d events and %v; got %d and %v", tt.seen, tt.err, seen, err)
+			}
+		})
+	}
+}
+
+// TestWatchers runs every client against one job, over a real server
+func TestWatchers(t *testing.T) {
+	job := Start([]string{"a", "b", "c"}, 10*time.Millisecond)
+	srv := httptest.NewServer(newMux(job))
+	t.Cleanup(srv.Close) // after the parallel subtests, not before
+
+	for name, watch := range map[string]watcher{
+		"poll": pollWith(srv.Client()),
+		"sse":  sse,
+		"ws":   ws,
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			var got []Progress
+			err := watch(context.Background(), srv.URL, func(p Progress) { got = append(got, p) })
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := 1; i < len(got); i++ {
+				if got[i].Version <= got[i-1].Version {
+					t.Errorf("want versions to go up; got %d after %d", got[i].Version, got[i-1].Version)
+				}
+			}
+			if last := got[len(got)-1]; !last.Done || last.Percent != 100 {
+				t.Errorf("want the job finished; got %+v", last)
+			}
+		})
+	}
+}
+
+func TestWatchPollStops(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	if err := watchPoll(context.Background(), srv.Client(), srv.URL, func(Progress) {}); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("want a 404 error; got %v", err)
+	}
+
+	job := Track(make(chan Progress), nil) // never changes
+	srv = httptest.NewServer(newMux(job))
+	defer srv.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := watchPoll(ctx, srv.Client(), srv.URL, func(Progress) {}); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("want DeadlineExceeded; got %v", err)
+	}
+}
+
+func TestWebSocketCancel(t *testing.T) {
+	job := Start([]string{"a", "b", "c", "d"}, 20*time.Millisecond)
+	srv := httptest.NewServer(newMux(job))
+	defer srv.Close()
+
+	var last Progress
+	err := watchWS(context.Background(), "ws"+strings.TrimPrefix(srv.URL, "http"), func(p Progress) bool {
+		last = p
+		return true // cancel at the first state
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !last.Done || last.Step != "canceled" || last.Percent == 100 {
+		t.Errorf("want the job canceled early; got %+v", last)
+	}
+}
+
+func TestWebSocketCrossOrigin(t *testing.T) {
+	srv := httptest.NewServer(newMux(Track(make(chan Progress), nil)))
+	defer srv.Close()
+
+	_, err := websocket.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/ws", "", "https://evil.example")
+	if err == nil {
+		t.Error("want a connection from another origin refused")
+	}
+}
+
+// BenchmarkUpdate measures how long one update takes to reach one
+// watcher, with each transport
+func BenchmarkUpdate(b *testing.B) {
+	for name, watch := range map[string]watcher{
+		"poll": pollWith(&http.Client{}),
+		"sse":  sse,
+		"ws":   ws,
+	} {
+		b.Run(name, func(b *testing.B) {
+			updates := make(chan Progress)
+			srv := httptest.NewServer(newMux(Track(updates, nil)))
+			defer srv.Close()
+
+			seen := make(chan struct{})
+			go watch(context.Background(), srv.URL, func(Progress) { seen <- struct{}{} })
+			updates <- Progress{}
+			<-seen // connected
+
+			for b.Loop() {
+				updates <- Progress{}
+				<-seen
+			}
+			updates <- Progress{Done: true}
+			<-seen
+		})
+	}
+}
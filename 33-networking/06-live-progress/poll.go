@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// pollWait is how long a long poll holds a request open. It must stay
+// under the idle timeouts of proxies and load balancers, often 30 to 60
+// seconds, or they cut the request first
+const pollWait = 25 * time.Second
+
+// pollHandler answers GET /poll?after=N with the first state newer than
+// version N. If nothing changes for wait, it answers 204, and the
+// client asks again
+func pollHandler(j *Job, wait time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		after, err := strconv.Atoi(r.URL.Query().Get("after"))
+		if err != nil {
+			after = 0
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), wait)
+		defer cancel()
+		p, err := j.Next(ctx, after)
+		switch {
+		case errors.Is(err, context.DeadlineExceeded):
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case err != nil:
+			return // the client is gone
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(p)
+	}
+}
+
+// watchPoll long-polls base until the job is done, calling seen with
+// every state it gets. Each state costs a whole request: headers, and,
+// if the connection was closed, a new TCP handshake
+func watchPoll(ctx context.Context, client *http.Client, base string, seen func(Progress)) error {
+	after := 0
+	for {
+		url := base + "/poll?after=" + strconv.Itoa(after)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+
+		var p Progress
+		switch resp.StatusCode {
+		case http.StatusNoContent:
+			resp.Body.Close()
+			continue // nothing new: ask again
+		case http.StatusOK:
+			err = json.NewDecoder(resp.Body).Decode(&p)
+			resp.Body.Close()
+		default:
+			resp.Body.Close()
+			err = fmt.Errorf("poll: %s", resp.Status)
+		}
+		if err != nil {
+			return err
+		}
+
+		seen(p)
+		if p.Done {
+			return nil
+		}
+		after = p.Version
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sseKeepalive is how often an idle stream gets a comment line, so that
+// proxies don't close it for being idle
+const sseKeepalive = 15 * time.Second
+
+// sseHandler streams every state as a Server-Sent Event, on one
+// response that stays open until the job is done. A browser that
+// reconnects sends the last id it saw in Last-Event-ID, and the stream
+// carries on from there
+func sseHandler(j *Job, keepalive time.Duration) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rc := http.NewResponseController(w)
+		// The server's WriteTimeout would cut a long stream off
+		rc.SetWriteDeadline(time.Time{})
+
+		after, _ := strconv.Atoi(r.Header.Get("Last-Event-ID"))
+
+		h := w.Header()
+		h.Set("Content-Type", "text/event-stream")
+		h.Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		rc.Flush()
+
+		for {
+			ctx, cancel := context.WithTimeout(r.Context(), keepalive)
+			p, err := j.Next(ctx, after)
+			cancel()
+
+			switch {
+			case r.Context().Err() != nil:
+				return // the client is gone
+			case errors.Is(err, context.DeadlineExceeded):
+				io.WriteString(w, ": keepalive\n\n") // a comment; clients skip it
+			default:
+				data, _ := json.Marshal(p)
+				event := "progress"
+				if p.Done {
+					event = "done"
+				}
+				fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", p.Version, event, data)
+				after = p.Version
+			}
+
+			// Without a flush, events wait in the server's buffer
+			if err := rc.Flush(); err != nil || p.Done {
+				return
+			}
+		}
+	}
+}
+
+// watchSSE reads the event stream at base until the job is done,
+// calling seen with every state. It parses the stream format by hand:
+// lines of "field: value", and a blank line to end each event
+func watchSSE(ctx context.Context, client *http.Client, base string, seen func(Progress)) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", base+"/events", nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("events: %s", resp.Status)
+	}
+
+	var data []string
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case line == "": // the end of an event
+			if len(data) == 0 {
+				continue
+			}
+			var p Progress
+			if err := json.Unmarshal([]byte(strings.Join(data, "\n")), &p); err != nil {
+				return err
+			}
+			data = data[:0]
+			seen(p)
+			if p.Done {
+				return nil
+			}
+		case strings.HasPrefix(line, ":"): // a comment
+		default:
+			field, value, _ := strings.Cut(line, ":")
+			if field == "data" {
+				data = append(data, strings.TrimPrefix(value, " "))
+			}
+			// This client needs only data; a browser's EventSource
+			// also keeps id, for Last-Event-ID, and event, for its
+			// listeners
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF // the stream ended before the job did
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// wsWriteWait is how long a write to a WebSocket client may take
+const wsWriteWait = 5 * time.Second
+
+// command is what a WebSocket client can send. Only a WebSocket has a
+// way back: with polling or SSE, canceling would be another request
+type command struct {
+	Type string `json:"type"` // "cancel"
+}
+
+// wsHandler sends every state over a WebSocket, and reads commands
+// from the client on the same connection
+func wsHandler(j *Job) http.Handler {
+	return websocket.Server{
+		Handshake: sameOrigin,
+		Handler: func(conn *websocket.Conn) {
+			defer conn.Close()
+			conn.MaxPayloadBytes = 1 << 10
+
+			ctx, cancel := context.WithCancel(conn.Request().Context())
+			defer cancel()
+
+			// The reader. It's also how the handler learns the client
+			// has gone: the read fails, and cancel stops the writer
+			go func() {
+				defer cancel()
+				for {
+					var c command
+					if err := websocket.JSON.Receive(conn, &c); err != nil {
+						return
+					}
+					if c.Type == "cancel" {
+						j.Cancel()
+					}
+				}
+			}()
+
+			after := 0
+			for {
+				p, err := j.Next(ctx, after)
+				if err != nil {
+					return
+				}
+				conn.SetWriteDeadline(time.Now().Add(wsWriteWait))
+				if err := websocket.JSON.Send(conn, p); err != nil || p.Done {
+					return
+				}
+				after = p.Version
+			}
+		},
+	}
+}
+
+// watchWS reads states from the WebSocket at base, a ws:// URL, until
+// the job is done. If seen returns true, it asks the server to cancel
+// the job, on the same connection
+func watchWS(ctx context.Context, base string, seen func(Progress) (cancel bool)) error {
+	origin := "http://" + strings.TrimPrefix(base, "ws://")
+	config, err := websocket.NewConfig(base+"/ws", origin)
+	if err != nil {
+		return err
+	}
+	conn, err := config.DialContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	// Receive doesn't take a context; closing the connection is what
+	// stops it
+	stop := context.AfterFunc(ctx, func() { conn.Close() })
+	defer stop()
+
+	for {
+		var p Progress
+		if err := websocket.JSON.Receive(conn, &p); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return err
+		}
+		if seen(p) {
+			if err := websocket.JSON.Send(conn, command{Type: "cancel"}); err != nil {
+				return err
+			}
+		}
+		if p.Done {
+			return nil
+		}
+	}
+}
+
+// sameOrigin rejects connections that a page from another site opened,
+// as in the chat lesson: a WebSocket isn't covered by the same-origin
+// policy, and here, it can cancel the job
+func sameOrigin(config *websocket.Config, req *http.Request) error {
+	origin := req.Header.Get("Origin")
+	if origin == "" {
+		return nil
+	}
+	u, err := url.Parse(origin)
+	if err != nil || u.Host != req.Host {
+		return &websocket.ProtocolError{ErrorString: "cross-origin connection refused"}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// ---------------------------------------------------------
+// EXERCISE: A Minimal WebSocket Server
+//
+//  The chat in 01-websocket-chat uses golang.org/x/net/websocket.
+//  Implement the server side of RFC 6455 yourself, with only the
+//  standard library, and prove it works with a client you didn't
+//  write.
+//
+//  1- Write acceptKey(key string) string: the SHA-1 of the key
+//     followed by "258EAFA5-E914-47DA-95CA-C5AB0DC85B11",
+//     in base64 (section 1.3)
+//
+//  2- Write Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error)
+//     - Check the handshake: GET, "Connection" includes "Upgrade",
+//       "Upgrade: websocket", version 13, and a 16-byte key.
+//       Otherwise answer 400
+//     - Hijack the connection, and write the 101 response yourself
+//
+//  3- Write readFrame and writeFrame (section 5.2)
+//     - Lengths of 0-125, 126 (16 bits follow), 127 (64 bits follow)
+//     - Client frames must be masked; server frames never are
+//     - Reject reserved bits, and control frames that are long or
+//       fragmented
+//
+//  4- Write (*Conn).ReadMessage() (opcode byte, data []byte, err error)
+//     - Join fragmented messages
+//     - Answer pings with pongs, even in the middle of a message
+//     - Echo a close frame, and return io.EOF
+//
+//  5- Write an echo handler, and talk to it with the client from
+//     golang.org/x/net/websocket
+//
+// HINTS
+//
+//  - io.ReadFull reads exactly as many bytes as you ask for
+//  - encoding/binary's BigEndian reads and appends the lengths
+//  - Unmasking is payload[i] ^= mask[i%4]
+//  - Limit the payload size before you allocate it
+//
+// EXPECTED OUTPUT
+//
+//  Test 1: The accept key from RFC 6455
+//  s3pPLMBiTxaQ9kYGzzhZRbK+xOo=
+//
+//  Test 2: Echo, with the x/net/websocket client
+//  sent 5 bytes, got back 5: same=true
+//  sent 14 bytes, got back 14: same=true
+//  sent 200 bytes, got back 200: same=true
+//
+//  Test 3: A plain HTTP request
+//  400 Bad Request: websocket: Connection must include Upgrade
+//
+// ---------------------------------------------------------
+
+package main
+
+func main() {
+}
@@ -0,0 +1,320 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/websocket"
+)
+
+// RFC 6455, section 1.3: the server proves it speaks WebSocket by
+// hashing the client's key with this fixed GUID
+const guid = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+// Opcodes, section 5.2
+const (
+	opContinuation = 0x0
+	opText         = 0x1
+	opBinary       = 0x2
+	opClose        = 0x8
+	opPing         = 0x9
+	opPong         = 0xA
+)
+
+// maxPayload limits one message, so a client can't make the server
+// allocate whatever length it claims
+const maxPayload = 1 << 20
+
+// acceptKey computes Sec-WebSocket-Accept from Sec-WebSocket-Key
+func acceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + guid))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// Conn is the server side of a WebSocket connection
+type Conn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// Upgrade checks the handshake request, takes over the connection from
+// net/http, and answers 101 Switching Protocols
+func Upgrade(w http.ResponseWriter, r *http.Request) (*Conn, error) {
+	if err := checkHandshake(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return nil, err
+	}
+
+	// After Hijack, net/http no longer touches the connection: the
+	// response, and everything after it, is written by hand
+	conn, rw, err := http.NewResponseController(w).Hijack()
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return nil, err
+	}
+
+	fmt.Fprintf(rw, "HTTP/1.1 101 Switching Protocols\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: Upgrade\r\n"+
+		"Sec-WebSocket-Accept: %s\r\n\r\n", acceptKey(r.Header.Get("Sec-WebSocket-Key")))
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &Conn{conn: conn, rw: rw}, nil
+}
+
+// checkHandshake validates the request, section 4.2.1
+func checkHandshake(r *http.Request) error {
+	switch {
+	case r.Method != http.MethodGet:
+		return errors.New("websocket: method must be GET")
+	case !headerHasToken(r.Header, "Connection", "upgrade"):
+		return errors.New("websocket: Connection must include Upgrade")
+	case !headerHasToken(r.Header, "Upgrade", "websocket"):
+		return errors.New("websocket: Upgrade must be websocket")
+	case r.Header.Get("Sec-WebSocket-Version") != "13":
+		return errors.New("websocket: version must be 13")
+	}
+	key, err := base64.StdEncoding.DecodeString(r.Header.Get("Sec-WebSocket-Key"))
+	if err != nil || len(key) != 16 {
+		return errors.New("websocket: Sec-WebSocket-Key must be 16 bytes in base64")
+	}
+	return nil
+}
+
+// headerHasToken reports whether a comma-separated header has token,
+// ignoring case: browsers send "Connection: keep-alive, Upgrade"
+func headerHasToken(h http.Header, name, token string) bool {
+	for _, v := range h.Values(name) {
+		for t := range strings.SplitSeq(v, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// frame is one WebSocket frame, section 5.2
+type frame struct {
+	fin     bool
+	opcode  byte
+	payload []byte
+}
+
+// readFrame reads one frame that a client sent. Client frames must be
+// masked; the payload is returned unmasked
+func readFrame(r io.Reader) (frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return frame{}, err
+	}
+
+	f := frame{fin: head[0]&0x80 != 0, opcode: head[0] & 0x0F}
+	if head[0]&0x70 != 0 {
+		return frame{}, errors.New("websocket: reserved bits set")
+	}
+	if head[1]&0x80 == 0 {
+		return frame{}, errors.New("websocket: client frame not masked")
+	}
+
+	// 0-125 is the length; 126 and 127 say it follows in 2 or 8 bytes
+	length := uint64(head[1] & 0x7F)
+	switch length {
+	case 126:
+		var ext [2]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, err
+		}
+		length = uint64(binary.BigEndian.Uint16(ext[:]))
+	case 127:
+		var ext [8]byte
+		if _, err := io.ReadFull(r, ext[:]); err != nil {
+			return frame{}, err
+		}
+		length = binary.BigEndian.Uint64(ext[:])
+	}
+
+	if f.opcode >= opClose && (length > 125 || !f.fin) {
+		return frame{}, errors.New("websocket: control frames must be short and unfragmented")
+	}
+	if length > maxPayload {
+		return frame{}, fmt.Errorf("websocket: frame of %d bytes is too large", length)
+	}
+
+	var mask [4]byte
+	if _, err := io.ReadFull(r, mask[:]); err != nil {
+		return frame{}, err
+	}
+	f.payload = make([]byte, length)
+	if _, err := io.ReadFull(r, f.payload); err != nil {
+		return frame{}, err
+	}
+	for i := range f.payload {
+		f.payload[i] ^= mask[i%4]
+	}
+	return f, nil
+}
+
+// writeFrame writes one unfragmented frame. Server frames are never
+// masked
+func writeFrame(w io.Writer, opcode byte, payload []byte) error {
+	head := []byte{0x80 | opcode, 0}
+	switch n := len(payload); {
+	case n <= 125:
+		head[1] = byte(n)
+	case n <= 0xFFFF:
+		head[1] = 126
+		head = binary.BigEndian.AppendUint16(head, uint16(n))
+	default:
+		head[1] = 127
+		head = binary.BigEndian.AppendUint64(head, uint64(n))
+	}
+
+	if _, err := w.Write(head); err != nil {
+		return err
+	}
+	_, err := w.Write(payload)
+	return err
+}
+
+// ReadMessage returns the next text or binary message, joining its
+// fragments. It answers pings, and returns io.EOF once the client
+// closes the connection
+func (c *Conn) ReadMessage() (opcode byte, data []byte, err error) {
+	for {
+		f, err := readFrame(c.rw)
+		if err != nil {
+			return 0, nil, err
+		}
+
+		switch f.opcode {
+		case opPing:
+			if err := c.write(opPong, f.payload); err != nil {
+				return 0, nil, err
+			}
+		case opPong:
+			// Unsolicited pongs are allowed, and mean nothing
+		case opClose:
+			// Echo the status code back, section 5.5.1
+			c.write(opClose, f.payload[:min(2, len(f.payload))])
+			return 0, nil, io.EOF
+		case opText, opBinary:
+			if opcode != 0 {
+				return 0, nil, errors.New("websocket: new message inside a fragmented one")
+			}
+			opcode = f.opcode
+			data = f.payload
+		case opContinuation:
+			if opcode == 0 {
+				return 0, nil, errors.New("websocket: continuation with no message")
+			}
+			if len(data)+len(f.payload) > maxPayload {
+				return 0, nil, errors.New("websocket: message is too large")
+			}
+			data = append(data, f.payload...)
+		default:
+			return 0, nil, fmt.Errorf("websocket: unknown opcode %#x", f.opcode)
+		}
+
+		if opcode != 0 && f.fin && f.opcode <= opBinary {
+			if opcode == opText && !utf8.Valid(data) {
+				return 0, nil, errors.New("websocket: text message is not UTF-8")
+			}
+			return opcode, data, nil
+		}
+	}
+}
+
+// WriteMessage sends data as one text or binary message
+func (c *Conn) WriteMessage(opcode byte, data []byte) error {
+	return c.write(opcode, data)
+}
+
+func (c *Conn) write(opcode byte, payload []byte) error {
+	if err := writeFrame(c.rw, opcode, payload); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// Close sends a normal closure, 1000, and closes the connection
+func (c *Conn) Close() error {
+	c.write(opClose, binary.BigEndian.AppendUint16(nil, 1000))
+	return c.conn.Close()
+}
+
+// echo sends every message back
+func echo(w http.ResponseWriter, r *http.Request) {
+	c, err := Upgrade(w, r)
+	if err != nil {
+		return
+	}
+	defer c.Close()
+
+	for {
+		op, data, err := c.ReadMessage()
+		if err != nil {
+			return
+		}
+		if err := c.WriteMessage(op, data); err != nil {
+			return
+		}
+	}
+}
+
+func main() {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		fmt.Println("listen:", err)
+		return
+	}
+	go http.Serve(ln, http.HandlerFunc(echo))
+	addr := ln.Addr().String()
+
+	fmt.Println("Test 1: The accept key from RFC 6455")
+	fmt.Println(acceptKey("dGhlIHNhbXBsZSBub25jZQ=="))
+	fmt.Println()
+
+	// A client from another implementation proves the server follows
+	// the protocol, not just its own idea of it
+	fmt.Println("Test 2: Echo, with the x/net/websocket client")
+	ws, err := websocket.Dial("ws://"+addr+"/", "", "http://"+addr)
+	if err != nil {
+		fmt.Println("dial:", err)
+		return
+	}
+	defer ws.Close()
+
+	for _, msg := range []string{"hello", "héllo, wörld", strings.Repeat("long ", 40)} {
+		websocket.Message.Send(ws, msg)
+
+		var reply string
+		if err := websocket.Message.Receive(ws, &reply); err != nil {
+			fmt.Println("receive:", err)
+			return
+		}
+		fmt.Printf("sent %d bytes, got back %d: same=%v\n", len(msg), len(reply), reply == msg)
+	}
+	fmt.Println()
+
+	fmt.Println("Test 3: A plain HTTP request")
+	resp, err := http.Get("http://" + addr + "/")
+	if err != nil {
+		fmt.Println("get:", err)
+		return
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Printf("%s: %s", resp.Status, body)
+}
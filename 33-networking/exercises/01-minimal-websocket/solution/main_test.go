@@ -0,0 +1,277 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"golang.org/x/net/websocket"
+)
+
+// clientFrame builds a frame the way a client must send it: masked
+func clientFrame(fin bool, opcode byte, payload []byte) []byte {
+	b := []byte{opcode, 0x80}
+	if fin {
+		b[0] |= 0x80
+	}
+	switch n := len(payload); {
+	case n <= 125:
+		b[1] |= byte(n)
+	case n <= 0xFFFF:
+		b[1] |= 126
+		b = binary.BigEndian.AppendUint16(b, uint16(n))
+	default:
+		b[1] |= 127
+		b = binary.BigEndian.AppendUint64(b, uint64(n))
+	}
+
+	mask := []byte{0x37, 0xfa, 0x21, 0x3d}
+	b = append(b, mask...)
+	for i, c := range payload {
+		b = append(b, c^mask[i%4])
+	}
+	return b
+}
+
+func TestAcceptKey(t *testing.T) {
+	// The example from RFC 6455, section 1.3
+	if got := acceptKey("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Errorf("got %q", got)
+	}
+}
+
+func TestReadFrame(t *testing.T) {
+	long := bytes.Repeat([]byte("x"), 300)
+	huge := bytes.Repeat([]byte("y"), 70000)
+
+	tests := []struct {
+		name    string
+		input   []byte
+		want    string
+		wantErr bool
+	}{
+		{"short", clientFrame(true, opText, []byte("Hello")), "Hello", false},
+		{"empty", clientFrame(true, opText, nil), "", false},
+		{"16-bit length", clientFrame(true, opText, long), string(long), false},
+		{"64-bit length", clientFrame(true, opBinary, huge), string(huge), false},
+
+		// The unmasked "Hello" from RFC 6455, section 5.7
+		{"unmasked", []byte{0x81, 0x05, 'H', 'e', 'l', 'l', 'o'}, "", true},
+		{"reserved bits", append([]byte{0xC1}, clientFrame(true, opText, nil)[1:]...), "", true},
+		{"long ping", clientFrame(true, opPing, long), "", true},
+		{"fragmented ping", clientFrame(false, opPing, nil), "", true},
+		{"truncated", clientFrame(true, opText, []byte("Hello"))[:8], "", true},
+		{"too large", []byte{0x82, 0xFF, 0, 0, 0, 0, 0xFF, 0, 0, 0}, "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, err := readFrame(bytes.NewReader(tt.input))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("want error %v; got %v", tt.wantErr, err)
+			}
+			if !tt.wantErr && string(f.payload) != tt.want {
+				t.Errorf("want %d bytes; got %d", len(tt.want), len(f.payload))
+			}
+		})
+	}
+}
+
+func TestWriteFrame(t *testing.T) {
+	tests := []struct {
+		size     int
+		wantHead []byte
+	}{
+		{5, []byte{0x81, 5}},
+		{125, []byte{0x81, 125}},
+		{126, []byte{0x81, 126, 0, 126}},
+		{65535, []byte{0x81, 126, 0xFF, 0xFF}},
+		{65536, []byte{0x81, 127, 0, 0, 0, 0, 0, 1, 0, 0}},
+	}
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		writeFrame(&buf, opText, make([]byte, tt.size))
+
+		if head := buf.Bytes()[:len(tt.wantHead)]; !bytes.Equal(head, tt.wantHead) {
+			t.Errorf("%d bytes: want header % x; got % x", tt.size, tt.wantHead, head)
+		}
+		if buf.Len() != len(tt.wantHead)+tt.size {
+			t.Errorf("%d bytes: want %d in total; got %d", tt.size, len(tt.wantHead)+tt.size, buf.Len())
+		}
+	}
+}
+
+// pipeConn returns a Conn whose client side is the returned net.Conn
+func pipeConn(t *testing.T) (*Conn, net.Conn) {
+	server, client := net.Pipe()
+	t.Cleanup(func() { server.Close(); client.Close() })
+	rw := bufio.NewReadWriter(bufio.NewReader(server), bufio.NewWriter(server))
+	return &Conn{conn: server, rw: rw}, client
+}
+
+func TestReadMessageFragmentsAndPing(t *testing.T) {
+	c, client := pipeConn(t)
+
+	// A ping may arrive between the fragments of a message
+	go func() {
+		client.Write(clientFrame(false, opText, []byte("Hel")))
+		client.Write(clientFrame(true, opPing, []byte("are you there?")))
+		client.Write(clientFrame(true, opContinuation, []byte("lo")))
+	}()
+
+	type result struct {
+		op   byte
+		data string
+		err  error
+	}
+	done := make(chan result)
+	go func() {
+		op, data, err := c.ReadMessage()
+		done <- result{op, string(data), err}
+	}()
+
+	// The pong goes out while the message is still incomplete
+	pong, err := readServerFrame(client)
+	if err != nil || pong.opcode != opPong || string(pong.payload) != "are you there?" {
+		t.Fatalf("want a pong with the ping's payload; got %+v, %v", pong, err)
+	}
+
+	r := <-done
+	if r.err != nil || r.op != opText || r.data != "Hello" {
+		t.Errorf("want text %q; got %d %q %v", "Hello", r.op, r.data, r.err)
+	}
+}
+
+func TestReadMessageClose(t *testing.T) {
+	c, client := pipeConn(t)
+
+	go client.Write(clientFrame(true, opClose, []byte{0x03, 0xE8})) // 1000
+
+	done := make(chan error)
+	go func() {
+		_, _, err := c.ReadMessage()
+		done <- err
+	}()
+
+	reply, err := readServerFrame(client)
+	if err != nil || reply.opcode != opClose || !bytes.Equal(reply.payload, []byte{0x03, 0xE8}) {
+		t.Errorf("want the close code echoed; got %+v, %v", reply, err)
+	}
+	if err := <-done; err != io.EOF {
+		t.Errorf("want io.EOF; got %v", err)
+	}
+}
+
+func TestReadMessageRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		frames [][]byte
+	}{
+		{"continuation first", [][]byte{clientFrame(true, opContinuation, []byte("x"))}},
+		{"text inside text", [][]byte{clientFrame(false, opText, []byte("a")), clientFrame(true, opText, []byte("b"))}},
+		{"invalid UTF-8", [][]byte{clientFrame(true, opText, []byte{0xff, 0xfe})}},
+		{"unknown opcode", [][]byte{clientFrame(true, 0x3, nil)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, client := pipeConn(t)
+			go func() {
+				for _, f := range tt.frames {
+					client.Write(f)
+				}
+			}()
+
+			if _, _, err := c.ReadMessage(); err == nil {
+				t.Error("want an error")
+			}
+		})
+	}
+}
+
+// readServerFrame reads an unmasked frame, as a client would
+func readServerFrame(r io.Reader) (frame, error) {
+	var head [2]byte
+	if _, err := io.ReadFull(r, head[:]); err != nil {
+		return frame{}, err
+	}
+	f := frame{fin: head[0]&0x80 != 0, opcode: head[0] & 0x0F, payload: make([]byte, head[1]&0x7F)}
+	_, err := io.ReadFull(r, f.payload)
+	return f, err
+}
+
+func TestUpgradeRejects(t *testing.T) {
+	valid := func() *http.Request {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.Header.Set("Connection", "keep-alive, Upgrade")
+		r.Header.Set("Upgrade", "websocket")
+		r.Header.Set("Sec-WebSocket-Version", "13")
+		r.Header.Set("Sec-WebSocket-Key", "dGhlIHNhbXBsZSBub25jZQ==")
+		return r
+	}
+	if err := checkHandshake(valid()); err != nil {
+		t.Fatalf("want a valid handshake; got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		change func(r *http.Request)
+	}{
+		{"POST", func(r *http.Request) { r.Method = "POST" }},
+		{"no Connection", func(r *http.Request) { r.Header.Del("Connection") }},
+		{"wrong Upgrade", func(r *http.Request) { r.Header.Set("Upgrade", "h2c") }},
+		{"old version", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Version", "8") }},
+		{"no key", func(r *http.Request) { r.Header.Del("Sec-WebSocket-Key") }},
+		{"short key", func(r *http.Request) { r.Header.Set("Sec-WebSocket-Key", "c2hvcnQ=") }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := valid()
+			tt.change(r)
+
+			w := httptest.NewRecorder()
+			if _, err := Upgrade(w, r); err == nil {
+				t.Error("want an error")
+			}
+			if w.Code != http.StatusBadRequest {
+				t.Errorf("want 400; got %d", w.Code)
+			}
+		})
+	}
+}
+
+func TestEchoWithAnotherClient(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(echo))
+	defer ts.Close()
+
+	ws, err := websocket.Dial("ws"+strings.TrimPrefix(ts.URL, "http")+"/", "", ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ws.Close()
+
+	for _, msg := range []string{"hello", strings.Repeat("ü", 100), strings.Repeat("z", 70000)} {
+		if err := websocket.Message.Send(ws, msg); err != nil {
+			t.Fatal(err)
+		}
+		var reply string
+		if err := websocket.Message.Receive(ws, &reply); err != nil {
+			t.Fatal(err)
+		}
+		if reply != msg {
+			t.Errorf("want %d bytes back; got %d", len(msg), len(reply))
+		}
+	}
+
+	// Binary messages come back as binary
+	data := []byte{0, 1, 2, 0xff}
+	websocket.Message.Send(ws, data)
+	var reply []byte
+	if err := websocket.Message.Receive(ws, &reply); err != nil || !bytes.Equal(reply, data) {
+		t.Errorf("want % x; got % x, %v", data, reply, err)
+	}
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"strconv"
+)
+
+// RowError is a row that couldn't be decoded. Decode yields it, and
+// carries on with the next row: one bad row in a million shouldn't stop
+// the other 999,999
+type RowError struct {
+	Line   int    // the line the row starts on, counting from 1
+	Column string // the column that failed; empty if the whole row did
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if e.Column == "" {
+		return fmt.Sprintf("line %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("line %d, column %q: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error { return e.Err }
+
+// Decode streams the rows of a CSV file into values of type T, one row
+// at a time. The first line is the header, and T is a struct whose
+// fields name their columns with a tag:
+//
+//	type Order struct {
+//		ID     int     `csv:"order_id"`
+//		Amount float64 `csv:"amount"`
+//		Note   string  `csv:"-"` // not in the file
+//	}
+//
+// A field without a tag uses its own name. Every field's column must be
+// in the header; extra columns are ignored. An empty cell leaves the
+// zero value.
+//
+// A row that has the wrong number of fields, or a cell that doesn't
+// parse, is yielded as a *RowError, and decoding goes on. Any other
+// error ends it.
+func Decode[T any](r io.Reader) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		cr := csv.NewReader(skipBOM(r))
+		// The slice Read returns is reused for every row. Decode copies
+		// what it needs into T, so it never keeps it
+		cr.ReuseRecord = true
+
+		header, err := cr.Read()
+		if err == io.EOF {
+			return // an empty file has no rows
+		}
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		cols, err := columns(reflect.TypeFor[T](), header)
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+
+		for {
+			// FieldsPerRecord is 0, so the header sets the number of
+			// fields. A ragged row comes back with ErrFieldCount, and
+			// the reader carries on after it
+			record, err := cr.Read()
+			if err == io.EOF {
+				return
+			}
+			var pe *csv.ParseError
+			if errors.As(err, &pe) {
+				if !yield(zero, &RowError{Line: pe.StartLine, Err: pe.Err}) {
+					return
+				}
+				continue
+			}
+			if err != nil {
+				yield(zero, err)
+				return
+			}
+
+			var v T
+			if err := decodeRow(reflect.ValueOf(&v).Elem(), cols, record); err != nil {
+				err.Line, _ = cr.FieldPos(0)
+				if !yield(zero, err) {
+					return
+				}
+				continue
+			}
+			if !yield(v, nil) {
+				return
+			}
+		}
+	}
+}
+
+// column is where one column of the file goes in T
+type column struct {
+	name  string
+	index int // in the record
+	field int // in the struct
+}
+
+// columns matches T's fields to the header, once per file, so that each
+// row is only a loop over the result
+func columns(t reflect.Type, header []string) ([]column, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("decode: %s is not a struct", t)
+	}
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		if _, dup := index[name]; !dup {
+			index[name] = i
+		}
+	}
+
+	var cols []column
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name := f.Tag.Get("csv")
+		if !f.IsExported() || name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		if !canParse(f.Type) {
+			return nil, fmt.Errorf("decode: field %s: can't parse a %s", f.Name, f.Type)
+		}
+		j, ok := index[name]
+		if !ok {
+			return nil, fmt.Errorf("decode: no column %q for field %s", name, f.Name)
+		}
+		cols = append(cols, column{name: name, index: j, field: i})
+	}
+	return cols, nil
+}
+
+var textUnmarshaler = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+func canParse(t reflect.Type) bool {
+	if reflect.PointerTo(t).Implements(textUnmarshaler) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// decodeRow sets the fields of v, a struct, from one record
+func decodeRow(v reflect.Value, cols []column, record []string) *RowError {
+	for _, c := range cols {
+		s := record[c.index]
+		if s == "" {
+			continue
+		}
+		if err := parse(v.Field(c.field), s); err != nil {
+			return &RowError{Column: c.name, Err: err}
+		}
+	}
+	return nil
+}
+
+// parse sets v from the text of one cell. A type with its own
+// UnmarshalText, like time.Time, parses itself
+func parse(v reflect.Value, s string) error {
+	if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return u.UnmarshalText([]byte(s))
+	}
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	}
+	return nil
+}
+
+// bom is the UTF-8 byte order mark. Excel and Windows tools put it at
+// the start of the files they save, where it would end up in the name
+// of the first column
+var bom = []byte("\xef\xbb\xbf")
+
+// skipBOM returns a reader that skips a byte order mark at the start
+// of r, if there is one
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if b, err := br.Peek(len(bom)); err == nil && bytes.Equal(b, bom) {
+		br.Discard(len(bom))
+	}
+	return br
+}
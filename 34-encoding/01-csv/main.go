@@ -0,0 +1,238 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Order is one row of an orders export
+type Order struct {
+	ID       int     `csv:"order_id"`
+	Date     Date    `csv:"date"`
+	Customer string  `csv:"customer"`
+	Country  string  `csv:"country"`
+	Amount   float64 `csv:"amount"`
+	Paid     bool    `csv:"paid"`
+}
+
+// Date is a day, written 2006-01-02. time.Time parses itself too, but
+// only as RFC 3339, with a time of day; a type of its own decides its
+// own format
+type Date struct{ time.Time }
+
+func (d *Date) UnmarshalText(b []byte) error {
+	t, err := time.Parse(time.DateOnly, string(b))
+	if err != nil {
+		return err
+	}
+	d.Time = t
+	return nil
+}
+
+func (d Date) String() string { return d.Format(time.DateOnly) }
+
+// stats is what filter did
+type stats struct {
+	Read, Written int
+	Skipped       []error // the rows that didn't decode
+}
+
+// filter streams the orders in r, and writes those keep accepts to w as
+// CSV. It holds one row in memory at a time, however big r is
+func filter(w io.Writer, r io.Reader, keep func(Order) bool) (stats, error) {
+	var s stats
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"order_id", "date", "customer", "amount"})
+
+	for o, err := range Decode[Order](r) {
+		var rerr *RowError
+		if errors.As(err, &rerr) {
+			s.Skipped = append(s.Skipped, rerr)
+			continue
+		}
+		if err != nil {
+			return s, err
+		}
+		s.Read++
+		if !keep(o) {
+			continue
+		}
+		s.Written++
+		cw.Write([]string{
+			strconv.Itoa(o.ID),
+			o.Date.String(),
+			o.Customer, // quoted by the writer if it needs to be
+			strconv.FormatFloat(o.Amount, 'f', 2, 64),
+		})
+	}
+
+	// The writer buffers, and Write doesn't report errors: Flush and
+	// Error do
+	cw.Flush()
+	return s, cw.Error()
+}
+
+// bigUnpaid is the filter the examples use
+func bigUnpaid(o Order) bool { return !o.Paid && o.Amount >= 100 }
+
+const orders = `order_id,date,customer,country,amount,paid
+1001,2025-03-01,Ada Lovelace,GB,129.90,true
+1002,2025-03-01,"Hopper, Grace",US,310.00,false
+1003,2025-03-02,Ken Thompson,US,45.50,false
+1004,2025-03-02,"Rob ""Commander"" Pike",CA,150.00,false
+1005,2025-03-03,Barbara Liskov,US,99.99,false
+`
+
+func main() {
+	fmt.Println("encoding/csv: Streaming and Struct Mapping")
+	fmt.Println("==========================================")
+	fmt.Println()
+
+	// Example 1: Records are []string
+	fmt.Println("1. csv.Reader handles the quoting; a record is a []string:")
+	cr := csv.NewReader(strings.NewReader(`name,quote
+# a comment line
+Pike,"Clear is better
+than clever."
+Thompson,"When in doubt, use ""brute force""."
+`))
+	cr.Comment = '#'
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			log.Fatal(err)
+		}
+		line, _ := cr.FieldPos(0)
+		fmt.Printf("   line %d: %q\n", line, record)
+	}
+	fmt.Println()
+
+	// Example 2: Rows to structs
+	fmt.Println("2. Decode[Order] maps each row to a struct, by column name:")
+	for o, err := range Decode[Order](strings.NewReader(orders)) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   #%d %s %-24s %s %7.2f paid=%t\n", o.ID, o.Date, o.Customer, o.Country, o.Amount, o.Paid)
+	}
+	fmt.Println()
+
+	// Example 3: Files from the real world
+	fmt.Println("3. A BOM, a ragged row, and a bad cell:")
+	messy := "\xef\xbb\xbf" + `order_id,date,customer,country,amount,paid
+2001,2025-04-01,Niklaus Wirth,CH,80.00,true
+2002,2025-04-01,Edsger Dijkstra,NL
+2003,2025-04-02,Tony Hoare,GB,12.50 EUR,false
+2004,2025-04-02,Donald Knuth,US,2.56,true
+`
+	plain := csv.NewReader(strings.NewReader(messy))
+	header, _ := plain.Read()
+	fmt.Printf("   without skipping the BOM, the first column is %q\n", header[0])
+	for o, err := range Decode[Order](strings.NewReader(messy)) {
+		if err != nil {
+			fmt.Printf("   skipped: %v\n", err)
+			continue
+		}
+		fmt.Printf("   #%d %s\n", o.ID, o.Customer)
+	}
+	fmt.Println()
+
+	// Example 4: Filtered output
+	fmt.Println("4. Unpaid orders of 100 or more, written back out as CSV:")
+	var out strings.Builder
+	s, err := filter(&out, strings.NewReader(orders), bigUnpaid)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for line := range strings.Lines(out.String()) {
+		fmt.Print("   ", line)
+	}
+	fmt.Printf("   read %d, wrote %d, skipped %d\n", s.Read, s.Written, len(s.Skipped))
+	fmt.Println()
+
+	// Example 5: A file too big to load
+	const rows = 500_000
+	fmt.Printf("5. %d rows, streamed vs read all at once:\n", rows)
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(generate(pw, rows)) }()
+	peak := heapPeak(func() {
+		s, err = filter(io.Discard, pr, bigUnpaid)
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   %-9s %d rows, peak heap %3d MB (kept %d)\n", "Decode", s.Read, peak>>20, s.Written)
+
+	pr, pw = io.Pipe()
+	go func() { pw.CloseWithError(generate(pw, rows)) }()
+	var all [][]string
+	peak = heapPeak(func() {
+		all, err = csv.NewReader(pr).ReadAll()
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   %-9s %d rows, peak heap %3d MB\n", "ReadAll", len(all)-1, peak>>20)
+}
+
+// generate writes an orders file with n rows to w. The same rows come
+// out on every run
+func generate(w io.Writer, n int) error {
+	rng := rand.New(rand.NewPCG(1, 2))
+	countries := []string{"US", "GB", "DE", "FR", "JP", "BR"}
+	day := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"order_id", "date", "customer", "country", "amount", "paid"})
+	for i := range n {
+		cw.Write([]string{
+			strconv.Itoa(100_000 + i),
+			day.AddDate(0, 0, i/2000).Format(time.DateOnly),
+			"customer " + strconv.Itoa(rng.IntN(50_000)),
+			countries[rng.IntN(len(countries))],
+			strconv.FormatFloat(float64(rng.IntN(50_000))/100, 'f', 2, 64),
+			strconv.FormatBool(rng.IntN(4) > 0),
+		})
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// heapPeak runs f, and reports the most heap in use while it ran,
+// checked every millisecond
+func heapPeak(f func()) uint64 {
+	runtime.GC()
+	done := make(chan struct{})
+	peak := make(chan uint64)
+	go func() {
+		var m runtime.MemStats
+		var most uint64
+		tick := time.NewTicker(time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				runtime.ReadMemStats(&m) // once more, at the end
+				peak <- max(most, m.HeapAlloc)
+				return
+			case <-tick.C:
+				runtime.ReadMemStats(&m)
+				most = max(most, m.HeapAlloc)
+			}
+		}
+	}()
+	f()
+	close(done)
+	return <-peak
+}
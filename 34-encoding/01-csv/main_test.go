@@ -0,0 +1,250 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+func open(t *testing.T, name string) io.Reader {
+	t.Helper()
+	f, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { f.Close() })
+	return f
+}
+
+func day(s string) Date {
+	t, _ := time.Parse(time.DateOnly, s)
+	return Date{t}
+}
+
+// decodeAll collects every row, and every error, Decode yields
+func decodeAll[T any](r io.Reader) (rows []T, errs []error) {
+	for v, err := range Decode[T](r) {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		rows = append(rows, v)
+	}
+	return rows, errs
+}
+
+func TestDecode(t *testing.T) {
+	want := []Order{
+		{1001, day("2025-03-01"), "Ada Lovelace", "GB", 129.90, true},
+		{1002, day("2025-03-01"), "Hopper, Grace", "US", 310, false},
+		{1003, day("2025-03-02"), "Ken Thompson", "US", 45.50, false},
+		{1004, day("2025-03-02"), `Rob "Commander" Pike`, "CA", 150, false},
+		{1005, day("2025-03-03"), "Barbara Liskov", "US", 99.99, false},
+		{1006, day("2025-03-03"), "Dennis\nRitchie", "US", 100, false},
+		{1007, day("2025-03-04"), "Frances Allen", "US", 0, false}, // an empty cell
+	}
+
+	// The same rows, saved by Excel: a BOM, and \r\n line endings
+	for _, name := range []string{"orders.csv", "orders_bom_crlf.csv"} {
+		t.Run(name, func(t *testing.T) {
+			got, errs := decodeAll[Order](open(t, name))
+			if errs != nil {
+				t.Fatal(errs)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("got:\n%v\nwant:\n%v", got, want)
+			}
+		})
+	}
+}
+
+func TestDecodeRowErrors(t *testing.T) {
+	tests := []struct {
+		file string
+		ids  []int      // the rows that decode
+		errs []RowError // the rows that don't, without Err
+	}{
+		{
+			file: "ragged.csv",
+			ids:  []int{2001, 2004},
+			errs: []RowError{{Line: 3}, {Line: 4}},
+		},
+		{
+			file: "bad_cells.csv",
+			ids:  []int{3001, 3006},
+			errs: []RowError{
+				{Line: 3, Column: "date"},
+				{Line: 4, Column: "amount"},
+				{Line: 5, Column: "paid"},
+				{Line: 6}, // a quote in an unquoted field
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			rows, errs := decodeAll[Order](open(t, tt.file))
+
+			var ids []int
+			for _, o := range rows {
+				ids = append(ids, o.ID)
+			}
+			if !slices.Equal(ids, tt.ids) {
+				t.Errorf("want rows %v; got %v", tt.ids, ids)
+			}
+
+			if len(errs) != len(tt.errs) {
+				t.Fatalf("want %d errors; got %v", len(tt.errs), errs)
+			}
+			for i, err := range errs {
+				var rerr *RowError
+				if !errors.As(err, &rerr) {
+					t.Fatalf("want a *RowError; got %T: %v", err, err)
+				}
+				if rerr.Line != tt.errs[i].Line || rerr.Column != tt.errs[i].Column {
+					t.Errorf("want line %d, column %q; got %v", tt.errs[i].Line, tt.errs[i].Column, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeHeaderErrors(t *testing.T) {
+	// A missing column is a mistake in the file or in the struct, not in
+	// one row: Decode stops at once
+	_, errs := decodeAll[Order](open(t, "missing_column.csv"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), `no column "paid"`) {
+		t.Errorf("want one error for the paid column; got %v", errs)
+	}
+
+	_, errs = decodeAll[struct{ Tags []string }](strings.NewReader("Tags\na\n"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "can't parse") {
+		t.Errorf("want one error for the slice field; got %v", errs)
+	}
+
+	_, errs = decodeAll[string](strings.NewReader("a\nb\n"))
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "not a struct") {
+		t.Errorf("want one error for a non-struct; got %v", errs)
+	}
+
+	rows, errs := decodeAll[Order](open(t, "empty.csv"))
+	if rows != nil || errs != nil {
+		t.Errorf("want nothing from an empty file; got %v, %v", rows, errs)
+	}
+}
+
+func TestDecodeFieldNames(t *testing.T) {
+	type row struct {
+		Name   string // no tag: the field's own name
+		Skip   string `csv:"-"` // never read
+		hidden string // unexported: never read
+		Count  uint8  `csv:"n"` // out of range for a uint8 below
+	}
+	rows, errs := decodeAll[row](strings.NewReader("n,Name,Skip,hidden\n7,a,x,y\n300,b,x,y\n"))
+	if want := []row{{Name: "a", Count: 7}}; !slices.Equal(rows, want) {
+		t.Errorf("want %v; got %v", want, rows)
+	}
+	if len(errs) != 1 || !strings.Contains(errs[0].Error(), "out of range") {
+		t.Errorf("want 300 out of range for a uint8; got %v", errs)
+	}
+}
+
+func TestDecodeStopsEarly(t *testing.T) {
+	n := 0
+	for range Decode[Order](open(t, "orders.csv")) {
+		n++
+		if n == 2 {
+			break // Decode must not call yield again
+		}
+	}
+	if n != 2 {
+		t.Errorf("want 2 rows; got %d", n)
+	}
+}
+
+// TestFilter checks the output against testdata/orders_filtered.csv. If
+// it changes on purpose, run: go test -update
+func TestFilter(t *testing.T) {
+	var out bytes.Buffer
+	s, err := filter(&out, open(t, "orders.csv"), bigUnpaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Read != 7 || s.Written != 3 || s.Skipped != nil {
+		t.Errorf("want 7 read, 3 written, none skipped; got %+v", s)
+	}
+
+	golden := filepath.Join("testdata", "orders_filtered.csv")
+	if *update {
+		if err := os.WriteFile(golden, out.Bytes(), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), want) {
+		t.Errorf("the output changed; if that's on purpose, run go test -update\ngot:\n%s", out.Bytes())
+	}
+}
+
+func TestFilterSkips(t *testing.T) {
+	s, err := filter(io.Discard, open(t, "bad_cells.csv"), bigUnpaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Read != 2 || len(s.Skipped) != 4 {
+		t.Errorf("want 2 read and 4 skipped; got %+v", s)
+	}
+}
+
+// failingWriter fails every write, like a full disk
+type failingWriter struct{}
+
+func (failingWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func TestFilterWriteError(t *testing.T) {
+	_, err := filter(failingWriter{}, open(t, "orders.csv"), bigUnpaid)
+	if err == nil || err.Error() != "disk full" {
+		t.Errorf("want the write error from Flush; got %v", err)
+	}
+}
+
+func TestFilterStreams(t *testing.T) {
+	const rows = 20_000
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(generate(pw, rows)) }()
+
+	s, err := filter(io.Discard, pr, bigUnpaid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Read != rows || s.Written == 0 || s.Written == rows {
+		t.Errorf("want %d read, and some written; got %+v", rows, s)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	var buf bytes.Buffer
+	if err := generate(&buf, 1000); err != nil {
+		b.Fatal(err)
+	}
+	b.SetBytes(int64(buf.Len()))
+	b.ReportAllocs()
+	for b.Loop() {
+		for _, err := range Decode[Order](bytes.NewReader(buf.Bytes())) {
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+)
+
+// codec is one way to turn a value into bytes, and back
+type codec struct {
+	name      string
+	marshal   func(v any) ([]byte, error)
+	unmarshal func(data []byte, v any) error
+}
+
+// codecs are the ones the examples and benchmarks compare. codec_v2.go
+// adds JSON v2, when it's built
+var codecs = []codec{
+	{"gob", gobMarshal, gobUnmarshal},
+	{"json v1", json.Marshal, json.Unmarshal},
+}
+
+// gobMarshal encodes one value on its own, so the bytes carry its type
+// too, as a gob stream of one value does
+func gobMarshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	err := gob.NewEncoder(&buf).Encode(v)
+	return buf.Bytes(), err
+}
+
+func gobUnmarshal(data []byte, v any) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
@@ -0,0 +1,17 @@
+//go:build goexperiment.jsonv2 && !go1.27
+
+package main
+
+import jsonv2 "encoding/json/v2"
+
+// JSON v2 is an experiment in Go 1.25 and 1.26, so this file is only
+// built with GOEXPERIMENT=jsonv2. From Go 1.27 it's stable, but only for
+// modules that say go 1.27 in go.mod, and this one says 1.25: on Go 1.27,
+// run it with GOTOOLCHAIN=go1.26.0
+func init() {
+	codecs = append(codecs, codec{
+		name:      "json v2",
+		marshal:   func(v any) ([]byte, error) { return jsonv2.Marshal(v) },
+		unmarshal: func(data []byte, v any) error { return jsonv2.Unmarshal(data, v) },
+	})
+}
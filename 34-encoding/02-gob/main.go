@@ -0,0 +1,259 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"time"
+)
+
+// Status is where a task is on the board
+type Status string
+
+// User and Task are the types of the HTTP servers section, with the
+// same JSON tags. Gob ignores the tags: it matches fields by name
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+type Task struct {
+	ID        int64     `json:"id"`
+	Title     string    `json:"title"`
+	Status    Status    `json:"status"`
+	Priority  int       `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Event is something that happened on the board. An interface value
+// travels with the name of its concrete type, and the decoder must know
+// that name: every type sent as an Event is registered, below
+type Event interface{ event() }
+
+type TaskCreated struct {
+	Task Task
+	By   User
+}
+
+type TaskMoved struct {
+	ID       int64
+	From, To Status
+	By       User
+}
+
+// TaskDeleted is an Event too, but it isn't registered, on purpose
+type TaskDeleted struct{ ID int64 }
+
+func (TaskCreated) event() {}
+func (TaskMoved) event()   {}
+func (TaskDeleted) event() {}
+
+func init() {
+	gob.Register(TaskCreated{})
+	gob.Register(TaskMoved{})
+}
+
+// Message is what the feed sends. Gob can't encode a bare interface
+// value, only one held in a field, or behind a pointer
+type Message struct {
+	Seq   int
+	Event Event
+}
+
+var (
+	ada  = User{ID: 1, Name: "Ada Lovelace", Email: "ada@example.com"}
+	day  = time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	task = Task{ID: 42, Title: "Write the gob lesson", Status: "doing", Priority: 2, CreatedAt: day, UpdatedAt: day.Add(time.Hour)}
+)
+
+func main() {
+	fmt.Println("encoding/gob: Go-to-Go Serialization")
+	fmt.Println("====================================")
+	fmt.Println()
+
+	// Example 1: Types are sent once per stream
+	fmt.Println("1. One encoder, the same task twice:")
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	enc.Encode(task)
+	first := buf.Len()
+	enc.Encode(task)
+	second := buf.Len() - first
+	data, _ := json.Marshal(task)
+	fmt.Printf("   gob, first:  %3d bytes (the type, then the value)\n", first)
+	fmt.Printf("   gob, second: %3d bytes (the value only)\n", second)
+	fmt.Printf("   JSON, each:  %3d bytes\n", len(data))
+
+	dec := gob.NewDecoder(&buf)
+	var got Task
+	dec.Decode(&got)
+	fmt.Printf("   decoded: %q, %s, updated %s\n", got.Title, got.Status, got.UpdatedAt.Format(time.Kitchen))
+	fmt.Println()
+
+	// Example 2: Matching by field name
+	fmt.Println("2. Decoding into a newer version of Task:")
+	type TaskV2 struct { // Priority is gone; Due and Tags are new
+		ID     int64
+		Title  string
+		Status Status
+		Due    time.Time
+		Tags   []string
+	}
+	var v2 TaskV2
+	err := decodeAs(task, &v2)
+	fmt.Printf("   %d %q %s, Due zero: %t, Tags: %v, err: %v\n", v2.ID, v2.Title, v2.Status, v2.Due.IsZero(), v2.Tags, err)
+
+	type TaskBad struct{ ID string } // a field that changed type
+	err = decodeAs(task, &TaskBad{})
+	fmt.Printf("   into an ID string: %v\n", err)
+
+	type Nothing struct{ Owner string } // no field in common
+	err = decodeAs(task, &Nothing{})
+	fmt.Printf("   into no common field: %v\n", err)
+	fmt.Println()
+
+	// Example 3: Interfaces
+	fmt.Println("3. Events in an interface field:")
+	buf.Reset()
+	enc = gob.NewEncoder(&buf)
+	for i, ev := range []Event{
+		TaskCreated{Task: task, By: ada},
+		TaskMoved{ID: 42, From: "doing", To: "done", By: ada},
+		TaskDeleted{ID: 42},
+	} {
+		if err := enc.Encode(Message{Seq: i + 1, Event: ev}); err != nil {
+			fmt.Printf("   send %d: %v\n", i+1, err)
+		}
+	}
+	dec = gob.NewDecoder(&buf)
+	for {
+		var m Message
+		if err := dec.Decode(&m); err != nil {
+			break
+		}
+		fmt.Printf("   got %d: %T\n", m.Seq, m.Event)
+	}
+	fmt.Println()
+
+	// Example 4: A stream over a connection
+	const n = 1000
+	fmt.Printf("4. %d events over a connection:\n", n)
+	for _, perMessage := range []bool{false, true} {
+		sent, received, err := feed(n, perMessage)
+		if err != nil {
+			log.Fatal(err)
+		}
+		how := "one encoder for the stream: "
+		if perMessage {
+			how = "a new encoder per message:  "
+		}
+		fmt.Printf("   %s %6d bytes, %d received\n", how, sent, received)
+	}
+	fmt.Println()
+
+	// Example 5: Sizes
+	tasks := make([]Task, n)
+	for i := range tasks {
+		tasks[i] = task
+		tasks[i].ID = int64(i + 1)
+	}
+	fmt.Printf("5. Bytes for %d tasks:\n", n)
+	for _, c := range codecs {
+		b, err := c.marshal(tasks)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   %-7s %6d\n", c.name, len(b))
+	}
+	fmt.Println("   (go test -bench . compares their speed)")
+}
+
+// decodeAs encodes v with gob, and decodes it into into, a pointer to
+// another type
+func decodeAs(v, into any) error {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	return gob.NewDecoder(&buf).Decode(into)
+}
+
+// feed sends n events from one end of a connection to the other, and
+// reports the bytes sent, and the events received. With perMessage, the
+// sender makes a new encoder for every message, and so sends the types
+// every time; the receiver must make a new decoder for each one too
+func feed(n int, perMessage bool) (sent int64, received int, err error) {
+	server, client := net.Pipe()
+	counted := &countingWriter{w: server}
+
+	go func() {
+		defer server.Close()
+		enc := gob.NewEncoder(counted)
+		for i := range n {
+			if perMessage {
+				enc = gob.NewEncoder(counted)
+			}
+			ev := TaskMoved{ID: int64(i), From: "todo", To: "doing", By: ada}
+			if err := enc.Encode(Message{Seq: i + 1, Event: ev}); err != nil {
+				return
+			}
+		}
+	}()
+
+	// A decoder reads ahead, into its own buffer, unless its reader is
+	// an io.ByteReader; then it reads exactly one message at a time,
+	// which is what a fresh decoder per message needs
+	r := io.Reader(client)
+	if perMessage {
+		r = &byteReader{r: client}
+	}
+	dec := gob.NewDecoder(r)
+	for {
+		if perMessage {
+			dec = gob.NewDecoder(r)
+		}
+		var m Message
+		err := dec.Decode(&m)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return counted.n, received, err
+		}
+		received++
+	}
+	client.Close()
+	return counted.n, received, nil
+}
+
+type countingWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// byteReader adds ReadByte to a reader, a byte at a time, with no
+// buffer to read past the end of a message
+type byteReader struct {
+	r   io.Reader
+	one [1]byte
+}
+
+func (b *byteReader) Read(p []byte) (int, error) { return b.r.Read(p) }
+
+func (b *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(b.r, b.one[:])
+	return b.one[0], err
+}
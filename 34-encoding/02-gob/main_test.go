@@ -0,0 +1,225 @@
+package main
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+	"io"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func manyTasks(n int) []Task {
+	tasks := make([]Task, n)
+	for i := range tasks {
+		tasks[i] = task
+		tasks[i].ID = int64(i + 1)
+	}
+	return tasks
+}
+
+func TestRoundTrip(t *testing.T) {
+	want := manyTasks(3)
+	for _, c := range codecs {
+		t.Run(c.name, func(t *testing.T) {
+			data, err := c.marshal(want)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []Task
+			if err := c.unmarshal(data, &got); err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, want) {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestEvents(t *testing.T) {
+	sent := []Message{
+		{1, TaskCreated{Task: task, By: ada}},
+		{2, TaskMoved{ID: 42, From: "doing", To: "done", By: ada}},
+	}
+	var buf bytes.Buffer
+	enc := gob.NewEncoder(&buf)
+	for _, m := range sent {
+		if err := enc.Encode(m); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dec := gob.NewDecoder(&buf)
+	for _, want := range sent {
+		var got Message
+		if err := dec.Decode(&got); err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("got %+v; want %+v", got, want)
+		}
+	}
+	if err := dec.Decode(new(Message)); err != io.EOF {
+		t.Errorf("want io.EOF at the end; got %v", err)
+	}
+}
+
+func TestUnregisteredEvent(t *testing.T) {
+	err := gob.NewEncoder(io.Discard).Encode(Message{Event: TaskDeleted{ID: 1}})
+	if err == nil || !strings.Contains(err.Error(), "not registered") {
+		t.Errorf("want a not registered error; got %v", err)
+	}
+}
+
+func TestVersionSkew(t *testing.T) {
+	// Fields match by name: missing ones stay zero, extra ones are
+	// dropped
+	var older struct {
+		ID    int32 // a smaller int still fits 42
+		Title string
+		Owner string
+	}
+	if err := decodeAs(task, &older); err != nil {
+		t.Fatal(err)
+	}
+	if older.ID != 42 || older.Title != task.Title || older.Owner != "" {
+		t.Errorf("got %+v", older)
+	}
+
+	tests := []struct {
+		name string
+		into any
+		err  string
+	}{
+		{"changed type", &struct{ Title int }{}, "wrong type"},
+		{"no common field", &struct{ Owner string }{}, "no fields matched"},
+		{"int too small", &struct{ ID int8 }{}, "out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := task
+			v.ID = 1000 // doesn't fit an int8
+			err := decodeAs(v, tt.into)
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("want an error with %q; got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestFeed(t *testing.T) {
+	const n = 50
+	once, _, err := feed(n, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	each, received, err := feed(n, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if received != n {
+		t.Errorf("want %d messages; got %d", n, received)
+	}
+	if each <= 2*once {
+		t.Errorf("want the types sent every time to cost more; got %d vs %d bytes", each, once)
+	}
+}
+
+// TestDecoderPerStream shows why the two ends must agree: a decoder
+// that reads several encoders' streams gets each one's types again
+func TestDecoderPerStream(t *testing.T) {
+	server, client := net.Pipe()
+	defer client.Close()
+	go func() {
+		defer server.Close()
+		for i := range 2 {
+			gob.NewEncoder(server).Encode(Message{Seq: i, Event: TaskMoved{}})
+		}
+	}()
+
+	dec := gob.NewDecoder(client)
+	var m Message
+	if err := dec.Decode(&m); err != nil {
+		t.Fatal(err)
+	}
+	err := dec.Decode(&m)
+	if err == nil || !strings.Contains(err.Error(), "duplicate type") {
+		t.Errorf("want a duplicate type error; got %v", err)
+	}
+}
+
+// BenchmarkMarshal and BenchmarkUnmarshal compare the codecs on one
+// task, and on a batch of 100. wire-bytes is the size of the encoded value
+func BenchmarkMarshal(b *testing.B) {
+	for _, size := range []int{1, 100} {
+		tasks := manyTasks(size)
+		for _, c := range codecs {
+			b.Run(c.name+"/"+batch(size), func(b *testing.B) {
+				b.ReportAllocs()
+				var n int
+				for b.Loop() {
+					data, err := c.marshal(tasks)
+					if err != nil {
+						b.Fatal(err)
+					}
+					n = len(data)
+				}
+				b.ReportMetric(float64(n), "wire-bytes")
+			})
+		}
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	for _, size := range []int{1, 100} {
+		tasks := manyTasks(size)
+		for _, c := range codecs {
+			data, err := c.marshal(tasks)
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.Run(c.name+"/"+batch(size), func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					var got []Task
+					if err := c.unmarshal(data, &got); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkStream sends one task at a time on a long-lived stream,
+// where gob has sent its types already
+func BenchmarkStream(b *testing.B) {
+	b.Run("gob", func(b *testing.B) {
+		b.ReportAllocs()
+		enc := gob.NewEncoder(io.Discard)
+		for b.Loop() {
+			if err := enc.Encode(task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("json v1", func(b *testing.B) {
+		b.ReportAllocs()
+		enc := json.NewEncoder(io.Discard)
+		for b.Loop() {
+			if err := enc.Encode(task); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func batch(n int) string {
+	if n == 1 {
+		return "one"
+	}
+	return "batch"
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// Celsius has its MarshalJSON on the pointer, a common mistake: the
+// json package only calls it when it has a pointer, or can take one
+type Celsius float64
+
+func (c *Celsius) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(strconv.FormatFloat(float64(*c), 'f', 1, 64) + "°C")), nil
+}
+
+func main() {
+	fmt.Println("Custom JSON: MarshalJSON and UnmarshalJSON")
+	fmt.Println("==========================================")
+	fmt.Println()
+
+	// Example 1: The defaults, and what a method changes
+	fmt.Println("1. A time.Duration vs a Duration:")
+	show(struct {
+		Default time.Duration `json:"default"`
+		Custom  Duration      `json:"custom"`
+	}{5 * time.Second, Duration(5 * time.Second)})
+	fmt.Println()
+
+	// Example 2: Accepting more than one form
+	fmt.Println("2. Durations in, as people and old clients send them:")
+	for _, in := range []string{`"5s"`, `"1m30s"`, `"250ms"`, `5000000000`, `null`, `"5 sec"`, `true`} {
+		var d Duration
+		err := json.Unmarshal([]byte(in), &d)
+		fmt.Printf("   %-12s -> %-7v err: %v\n", in, d, err)
+	}
+	fmt.Println()
+
+	// Example 3: An enum
+	fmt.Println("3. Status: a number in Go, a name in JSON:")
+	show(map[Status]int{Todo: 4, Doing: 2, Done: 7})
+	for _, in := range []string{`"doing"`, `"blocked"`, `1`} {
+		var s Status
+		err := json.Unmarshal([]byte(in), &s)
+		fmt.Printf("   %-9s -> %v, err: %v\n", in, s, err)
+	}
+	_, err := json.Marshal(Status(9))
+	fmt.Printf("   marshal Status(9): %v\n", err)
+	fmt.Println()
+
+	// Example 4: Times in other formats
+	fmt.Println("4. A date, Unix seconds, and the default RFC 3339:")
+	created := time.Date(2025, 3, 1, 9, 30, 0, 0, time.UTC)
+	show(struct {
+		Due     Date      `json:"due"`
+		Created UnixTime  `json:"created"`
+		Updated time.Time `json:"updated"`
+	}{Date{created}, UnixTime{created}, created})
+	show(struct {
+		Due Date `json:"due,omitzero"` // IsZero comes from the time.Time in Date
+	}{})
+	var d Date
+	err = json.Unmarshal([]byte(`"01/03/2025"`), &d)
+	fmt.Printf("   bad date: %v\n", err)
+	fmt.Println()
+
+	// Example 5: Unknown fields survive a round trip
+	fmt.Println("5. A task from a newer client, read and written back:")
+	in := `{"id":7,"title":"Ship it","status":"doing","timeout":"30s","created":1740821400,"labels":["release"],"owner":{"id":3}}`
+	var t Task
+	if err := json.Unmarshal([]byte(in), &t); err != nil {
+		log.Fatal(err)
+	}
+	t.Status = Done
+	fmt.Printf("   known: #%d %q %v, timeout %v, created %s\n", t.ID, t.Title, t.Status, t.Timeout, t.Created.Format(time.DateTime))
+	for _, name := range slices.Sorted(maps.Keys(t.Extra)) {
+		fmt.Printf("   kept:  %s = %s\n", name, t.Extra[name])
+	}
+	show(t)
+	fmt.Println()
+
+	// Example 6: Pointer receivers
+	fmt.Println("6. MarshalJSON on a pointer is skipped for values it can't address:")
+	temp := Celsius(21.5)
+	show(&temp)
+	show(temp)
+	show(map[string]Celsius{"office": temp})
+	show(struct{ Office Celsius }{temp})
+	show(&struct{ Office Celsius }{temp})
+}
+
+func show(v any) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		fmt.Printf("   error: %v\n", err)
+		return
+	}
+	fmt.Printf("   %s\n", data)
+}
@@ -0,0 +1,250 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Duration
+		err  string
+	}{
+		{`"5s"`, Duration(5 * time.Second), ""},
+		{`"1h2m"`, Duration(time.Hour + 2*time.Minute), ""},
+		{`"-1.5s"`, Duration(-1500 * time.Millisecond), ""},
+		{`1500`, Duration(1500), ""}, // nanoseconds, as time.Duration wrote them
+		{`"5 sec"`, 0, "unknown unit"},
+		{`""`, 0, "invalid duration"},
+		{`1.5`, 0, "not a number of nanoseconds"},
+		{`{}`, 0, "not a number of nanoseconds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			var got Duration
+			err := json.Unmarshal([]byte(tt.in), &got)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Fatalf("want an error with %q; got %v", tt.err, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Fatalf("want %v; got %v, %v", tt.want, got, err)
+			}
+
+			// And back: always the string form
+			data, _ := json.Marshal(got)
+			if want := `"` + time.Duration(tt.want).String() + `"`; string(data) != want {
+				t.Errorf("want %s; got %s", want, data)
+			}
+		})
+	}
+}
+
+func TestNullLeavesTheValue(t *testing.T) {
+	v := struct {
+		D Duration
+		T UnixTime
+		A Date
+	}{D: 1, T: UnixTime{time.Unix(1, 0)}, A: Date{time.Unix(1, 0)}}
+	want := v
+	if err := json.Unmarshal([]byte(`{"D":null,"T":null,"A":null}`), &v); err != nil {
+		t.Fatal(err)
+	}
+	if v != want {
+		t.Errorf("want null to leave %+v; got %+v", want, v)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	for _, s := range []Status{Todo, Doing, Done} {
+		data, err := json.Marshal(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got Status
+		if err := json.Unmarshal(data, &got); err != nil || got != s {
+			t.Errorf("%v: round trip through %s gave %v, %v", s, data, got, err)
+		}
+	}
+
+	for _, in := range []string{`"blocked"`, `"Doing"`, `1`, `null`} {
+		got := Doing
+		err := json.Unmarshal([]byte(in), &got)
+		if in == "null" {
+			if err != nil || got != Doing {
+				t.Errorf("want null to leave doing; got %v, %v", got, err)
+			}
+			continue
+		}
+		if err == nil {
+			t.Errorf("%s: want an error; got %v", in, got)
+		}
+	}
+
+	if _, err := json.Marshal(Status(-1)); err == nil {
+		t.Error("want an error for a status with no name")
+	}
+}
+
+func TestStatusMapKeys(t *testing.T) {
+	data, err := json.Marshal(map[Status]int{Todo: 1, Done: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"done":2,"todo":1}`; string(data) != want {
+		t.Errorf("want %s; got %s", want, data)
+	}
+
+	var got map[Status]int
+	if err := json.Unmarshal(data, &got); err != nil || got[Done] != 2 {
+		t.Errorf("want done: 2; got %v, %v", got, err)
+	}
+}
+
+func TestTimes(t *testing.T) {
+	day := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+	v := struct {
+		Due     Date     `json:"due,omitzero"`
+		Created UnixTime `json:"created"`
+	}{Date{day}, UnixTime{day.Add(90 * time.Minute)}}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"due":"2025-03-01","created":1740792600}`; string(data) != want {
+		t.Errorf("want %s; got %s", want, data)
+	}
+
+	got := v
+	got.Due, got.Created = Date{}, UnixTime{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !got.Due.Equal(v.Due.Time) || !got.Created.Equal(v.Created.Time) {
+		t.Errorf("want %+v; got %+v", v, got)
+	}
+
+	v.Due = Date{}
+	data, _ = json.Marshal(v)
+	if strings.Contains(string(data), "due") {
+		t.Errorf("want a zero date left out; got %s", data)
+	}
+
+	for _, in := range []string{`{"due":"2025-3-1"}`, `{"due":20250301}`, `{"created":"1740793200"}`} {
+		if err := json.Unmarshal([]byte(in), &got); err == nil {
+			t.Errorf("%s: want an error", in)
+		}
+	}
+}
+
+func TestTaskKeepsUnknownFields(t *testing.T) {
+	in := `{"id":1,"title":"a","status":"doing","timeout":"1s","created":0,"labels":["x"],"owner":{"id":3,"name":"Ada"}}`
+	var task Task
+	if err := json.Unmarshal([]byte(in), &task); err != nil {
+		t.Fatal(err)
+	}
+	if task.Status != Doing || task.Timeout != Duration(time.Second) {
+		t.Errorf("want the known fields decoded; got %+v", task)
+	}
+	if len(task.Extra) != 2 || string(task.Extra["owner"]) != `{"id":3,"name":"Ada"}` {
+		t.Errorf("want labels and owner kept as they were; got %s", task.Extra)
+	}
+
+	out, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameJSON(t, in, string(out)) {
+		t.Errorf("want the same document back\nin:  %s\nout: %s", in, out)
+	}
+}
+
+// TestTaskKnownFields checks the list UnmarshalJSON removes against
+// Task's tags: a field added to Task and not to the list would be kept
+// twice
+func TestTaskKnownFields(t *testing.T) {
+	full := Task{ID: 1, Title: "a", Status: Done, Timeout: 1, Due: Date{time.Unix(0, 0)}, Created: UnixTime{time.Unix(0, 0)}}
+	data, err := json.Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Task
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Extra != nil {
+		t.Errorf("want no unknown fields in %s; got %s", data, got.Extra)
+	}
+
+	typ := reflect.TypeFor[Task]()
+	for i := range typ.NumField() {
+		name, _, _ := strings.Cut(typ.Field(i).Tag.Get("json"), ",")
+		if name != "-" && !strings.Contains(string(data), `"`+name+`"`) {
+			t.Errorf("field %s isn't in %s", name, data)
+		}
+	}
+}
+
+func TestTaskKnownFieldsWin(t *testing.T) {
+	task := Task{Title: "real", Extra: map[string]json.RawMessage{"title": json.RawMessage(`"stale"`)}}
+	data, err := json.Marshal(task)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"title":"real"`) {
+		t.Errorf("want the field to win over Extra; got %s", data)
+	}
+}
+
+func TestTaskErrors(t *testing.T) {
+	for _, in := range []string{`{"status":"blocked"}`, `{"timeout":"soon"}`, `[1,2]`, `{"id":`} {
+		var task Task
+		if err := json.Unmarshal([]byte(in), &task); err == nil {
+			t.Errorf("%s: want an error", in)
+		}
+	}
+}
+
+func TestPointerReceiver(t *testing.T) {
+	c := Celsius(20)
+	tests := []struct {
+		name string
+		v    any
+		want string
+	}{
+		{"pointer", &c, `"20.0°C"`},
+		{"value", c, `20`},
+		{"map value", map[string]Celsius{"a": c}, `{"a":20}`},
+		{"field of a pointer", &struct{ C Celsius }{c}, `{"C":"20.0°C"}`},
+		{"slice element", []Celsius{c}, `["20.0°C"]`}, // slice elements are addressable
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := json.Marshal(tt.v)
+			if err != nil || string(data) != tt.want {
+				t.Errorf("want %s; got %s, %v", tt.want, data, err)
+			}
+		})
+	}
+}
+
+// sameJSON reports whether a and b hold the same JSON, whatever the
+// order of their keys
+func sameJSON(t *testing.T, a, b string) bool {
+	t.Helper()
+	var va, vb any
+	if err := json.Unmarshal([]byte(a), &va); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(b), &vb); err != nil {
+		t.Fatal(err)
+	}
+	return reflect.DeepEqual(va, vb)
+}
@@ -0,0 +1,63 @@
+package main
+
+import "encoding/json"
+
+// Task is a task as this version of the program knows it. Extra keeps
+// the fields it doesn't know, so that reading a task and writing it
+// back doesn't drop what a newer client added
+type Task struct {
+	ID      int64    `json:"id"`
+	Title   string   `json:"title"`
+	Status  Status   `json:"status"`
+	Timeout Duration `json:"timeout"`
+	Due     Date     `json:"due,omitzero"`
+	Created UnixTime `json:"created"`
+
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+// taskFields is Task without its methods. Calling json.Unmarshal on a
+// *Task inside Task's own UnmarshalJSON would call UnmarshalJSON again,
+// forever; a taskFields has the same fields and tags, and no methods
+type taskFields Task
+
+func (t *Task) UnmarshalJSON(data []byte) error {
+	if err := json.Unmarshal(data, (*taskFields)(t)); err != nil {
+		return err
+	}
+
+	// A second pass, over the same bytes, for the names Task doesn't
+	// have. A RawMessage holds a value's bytes, undecoded
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for _, known := range []string{"id", "title", "status", "timeout", "due", "created"} {
+		delete(all, known)
+	}
+	t.Extra = nil
+	if len(all) > 0 {
+		t.Extra = all
+	}
+	return nil
+}
+
+func (t Task) MarshalJSON() ([]byte, error) {
+	data, err := json.Marshal(taskFields(t))
+	if err != nil || len(t.Extra) == 0 {
+		return data, err
+	}
+
+	// Put the unknown fields back. A map is written with its keys
+	// sorted, so the output doesn't keep the input's order
+	var all map[string]json.RawMessage
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	for name, value := range t.Extra {
+		if _, known := all[name]; !known {
+			all[name] = value
+		}
+	}
+	return json.Marshal(all)
+}
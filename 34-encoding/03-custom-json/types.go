@@ -0,0 +1,130 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Duration is a time.Duration that's written in JSON as "5s", instead
+// of as 5000000000 nanoseconds
+type Duration time.Duration
+
+// MarshalJSON has a value receiver, so it works for a Duration and for
+// a *Duration alike
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON accepts "5s", "1m30s", and the like. It also accepts a
+// plain number of nanoseconds, which is what the same field held before
+// it was a Duration, so that old documents still load
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil // as the json package does: null leaves the value
+	}
+	if len(data) > 0 && data[0] != '"' {
+		n, err := strconv.ParseInt(string(data), 10, 64)
+		if err != nil {
+			return fmt.Errorf("duration: %s is not a number of nanoseconds", data)
+		}
+		*d = Duration(n)
+		return nil
+	}
+
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return fmt.Errorf("duration: %w", err)
+	}
+	*d = Duration(v)
+	return nil
+}
+
+func (d Duration) String() string { return time.Duration(d).String() }
+
+// Status is where a task is on the board. In Go it's a number; in JSON
+// it's a name, so the numbers can change without breaking clients
+type Status int
+
+const (
+	Todo Status = iota // the zero value is a valid status
+	Doing
+	Done
+)
+
+var statusNames = []string{"todo", "doing", "done"}
+
+func (s Status) String() string {
+	if s < 0 || int(s) >= len(statusNames) {
+		return "Status(" + strconv.Itoa(int(s)) + ")"
+	}
+	return statusNames[s]
+}
+
+// MarshalText makes a Status a JSON string, and also lets it be a map
+// key, which MarshalJSON wouldn't. The json package adds the quotes
+func (s Status) MarshalText() ([]byte, error) {
+	if s < 0 || int(s) >= len(statusNames) {
+		return nil, fmt.Errorf("status: no name for %d", int(s))
+	}
+	return []byte(statusNames[s]), nil
+}
+
+func (s *Status) UnmarshalText(text []byte) error {
+	for i, name := range statusNames {
+		if string(text) == name {
+			*s = Status(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("status: unknown %q, want todo, doing, or done", text)
+}
+
+// Date is a day, written "2006-01-02". time.Time writes RFC 3339, with a
+// time of day and a zone, which is wrong for a due date
+type Date struct{ time.Time }
+
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.Format(time.DateOnly))
+}
+
+func (d *Date) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("date: want a string: %w", err)
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return fmt.Errorf("date: %w", err)
+	}
+	d.Time = t
+	return nil
+}
+
+// UnixTime is a time written as seconds since 1970, as many APIs send
+// it. Only the seconds survive a round trip
+type UnixTime struct{ time.Time }
+
+func (t UnixTime) MarshalJSON() ([]byte, error) {
+	return strconv.AppendInt(nil, t.Unix(), 10), nil
+}
+
+func (t *UnixTime) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	sec, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		return fmt.Errorf("unix time: %s is not a number of seconds", data)
+	}
+	t.Time = time.Unix(sec, 0).UTC()
+	return nil
+}
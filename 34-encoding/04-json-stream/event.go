@@ -0,0 +1,89 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"time"
+)
+
+// Event is one element of the big array: something a user did
+type Event struct {
+	ID     int       `json:"id"`
+	Type   string    `json:"type"`
+	User   string    `json:"user"`
+	Amount float64   `json:"amount"`
+	Tags   []string  `json:"tags"`
+	At     time.Time `json:"at"`
+}
+
+// stats is what every streamer works out from the array: small, however
+// big the array is
+type stats struct {
+	Events    int
+	Purchases int
+	Revenue   float64 // the sum of the purchases' amounts
+}
+
+// add counts one event
+func (s *stats) add(typ string, amount float64) {
+	s.Events++
+	if typ == "purchase" {
+		s.Purchases++
+		s.Revenue += amount
+	}
+}
+
+// generate writes a JSON array of events to w, until it has written at
+// least size bytes. The same events come out on every run
+func generate(w io.Writer, size int64) error {
+	rng := rand.New(rand.NewPCG(1, 2))
+	types := []string{"view", "view", "view", "click", "click", "purchase"}
+	tags := []string{"mobile", "web", "promo", "returning", "new"}
+	start := time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	bw := bufio.NewWriter(w)
+	var buf []byte
+	var written int64
+	buf = append(buf, '[')
+	for id := 1; written < size; id++ {
+		if id > 1 {
+			buf = append(buf, ",\n"...)
+		}
+		typ := types[rng.IntN(len(types))]
+		buf = append(buf, `{"id":`...)
+		buf = strconv.AppendInt(buf, int64(id), 10)
+		buf = append(buf, `,"type":"`...)
+		buf = append(buf, typ...)
+		buf = append(buf, `","user":"user `...)
+		buf = strconv.AppendInt(buf, int64(rng.IntN(100_000)), 10)
+		buf = append(buf, `","amount":`...)
+		amount := 0.0
+		if typ == "purchase" {
+			amount = float64(rng.IntN(20_000)) / 100
+		}
+		buf = strconv.AppendFloat(buf, amount, 'f', -1, 64)
+		buf = append(buf, `,"tags":[`...)
+		for i := range rng.IntN(3) {
+			if i > 0 {
+				buf = append(buf, ',')
+			}
+			buf = strconv.AppendQuote(buf, tags[rng.IntN(len(tags))])
+		}
+		buf = append(buf, `],"at":"`...)
+		buf = start.Add(time.Duration(id)*time.Second).AppendFormat(buf, time.RFC3339)
+		buf = append(buf, `"}`...)
+
+		n, err := bw.Write(buf)
+		if err != nil {
+			return err
+		}
+		written += int64(n)
+		buf = buf[:0]
+	}
+	if _, err := bw.WriteString("]\n"); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"runtime"
+	"strings"
+	"time"
+)
+
+const small = `[
+  {"id":1,"type":"view","user":"ada","amount":0,"tags":["web"],"at":"2025-03-01T09:00:00Z"},
+  {"id":2,"type":"purchase","user":"ada","amount":19.99,"tags":[],"at":"2025-03-01T09:01:00Z"},
+  {"id":3,"type":"purchase","user":"ken","amount":5.01,"tags":["mobile","promo"],"at":"2025-03-01T09:02:00Z"}
+]`
+
+func main() {
+	mb := flag.Int64("mb", 200, "the size of the generated array, in MB")
+	all := flag.Bool("all", true, "also load the whole array into memory, to compare")
+	flag.Parse()
+
+	fmt.Println("Streaming Huge JSON with Tokens")
+	fmt.Println("===============================")
+	fmt.Println()
+
+	// Example 1: What a token is
+	fmt.Println("1. The first event, as v1 tokens:")
+	dec := json.NewDecoder(strings.NewReader(small))
+	for range 17 { // the [, then the first event: its {, 6 members, and }
+		tok, err := dec.Token()
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   %-9T %v\n", tok, tok)
+	}
+	fmt.Println()
+
+	// Example 2: Every streamer, the same answer
+	fmt.Println("2. The same small array, through every streamer:")
+	for _, st := range streamers {
+		s, err := st.run(strings.NewReader(small))
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   %-19s %+v\n", st.name, s)
+	}
+	if len(streamers) == 2 {
+		fmt.Println("   (run with GOTOOLCHAIN=go1.26.0 GOEXPERIMENT=jsonv2 to add the jsontext ones)")
+	}
+	fmt.Println()
+
+	// Example 3: Errors say where
+	fmt.Println("3. Broken input:")
+	for _, in := range []string{`{"id":1}`, `[{"id":1,"type":"view"},{"id":2,`, `[{"type":"purchase","amount":"12"}]`} {
+		_, err := streamers[1].run(strings.NewReader(in))
+		fmt.Printf("   %-36s %v\n", in, err)
+	}
+	fmt.Println()
+
+	// Example 4: An array too big to load
+	fmt.Printf("4. A %d MB array, generated as it's read:\n", *mb)
+	fmt.Printf("   %-19s %9s %10s %10s %8s\n", "", "events", "peak heap", "allocated", "time")
+	run := func(name string, f func(io.Reader) (stats, error)) {
+		pr, pw := io.Pipe()
+		go func() { pw.CloseWithError(generate(pw, *mb<<20)) }()
+		var s stats
+		var err error
+		start := time.Now()
+		m := measure(func() { s, err = f(pr) })
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   %-19s %9d %7d MB %7d MB %8v\n", name, s.Events, m.peak>>20, m.allocated>>20, time.Since(start).Round(time.Millisecond))
+	}
+	for _, st := range streamers {
+		run(st.name, st.run)
+	}
+	if *all {
+		run("load all", loadAll)
+	}
+}
+
+// usage is what measure saw
+type usage struct {
+	peak      uint64 // the most heap in use, above what was in use before
+	allocated uint64 // every byte allocated, even if it was freed again
+}
+
+// measure runs f, and reports its memory use: the allocations from
+// runtime.MemStats before and after, and the peak heap, checked every
+// millisecond while it ran
+func measure(f func()) usage {
+	runtime.GC()
+	var before runtime.MemStats
+	runtime.ReadMemStats(&before)
+
+	done := make(chan struct{})
+	peak := make(chan uint64)
+	go func() {
+		var m runtime.MemStats
+		var most uint64
+		tick := time.NewTicker(time.Millisecond)
+		defer tick.Stop()
+		for {
+			select {
+			case <-done:
+				runtime.ReadMemStats(&m) // once more, at the end
+				peak <- max(most, m.HeapAlloc)
+				return
+			case <-tick.C:
+				runtime.ReadMemStats(&m)
+				most = max(most, m.HeapAlloc)
+			}
+		}
+	}()
+	f()
+	close(done)
+	most := <-peak
+
+	var after runtime.MemStats
+	runtime.ReadMemStats(&after)
+	return usage{
+		peak:      most - min(most, before.HeapAlloc),
+		allocated: after.TotalAlloc - before.TotalAlloc,
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+)
+
+// events is a small generated array, shared by the tests
+func events(t testing.TB, size int64) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := generate(&buf, size); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestGenerate(t *testing.T) {
+	a, b := events(t, 64<<10), events(t, 64<<10)
+	if !bytes.Equal(a, b) {
+		t.Error("want the same events on every run")
+	}
+	if len(a) < 64<<10 {
+		t.Errorf("want at least %d bytes; got %d", 64<<10, len(a))
+	}
+	var all []Event
+	if err := json.Unmarshal(a, &all); err != nil {
+		t.Fatal(err)
+	}
+	if all[0].ID != 1 || all[len(all)-1].ID != len(all) || all[0].At.IsZero() {
+		t.Errorf("want events numbered from 1, with times; got %+v ... %+v", all[0], all[len(all)-1])
+	}
+}
+
+func TestStreamersAgree(t *testing.T) {
+	data := events(t, 1<<20)
+	want, err := loadAll(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want.Purchases == 0 || want.Purchases == want.Events {
+		t.Fatalf("want a mix of events; got %+v", want)
+	}
+
+	for _, st := range streamers {
+		t.Run(st.name, func(t *testing.T) {
+			got, err := st.run(bytes.NewReader(data))
+			if err != nil || got != want {
+				t.Errorf("want %+v; got %+v, %v", want, got, err)
+			}
+
+			got, err = st.run(strings.NewReader(small))
+			if err != nil || got.Events != 3 || got.Purchases != 2 {
+				t.Errorf("small: want 3 events, 2 purchases; got %+v, %v", got, err)
+			}
+
+			for _, in := range []string{`[]`, " [ ]\n"} {
+				got, err := st.run(strings.NewReader(in))
+				if err != nil || got != (stats{}) {
+					t.Errorf("%q: want no events; got %+v, %v", in, got, err)
+				}
+			}
+		})
+	}
+}
+
+func TestStreamersErrors(t *testing.T) {
+	inputs := []string{
+		``,
+		`{"id":1}`,
+		`[1,2]`,
+		`[{"id":1}`,
+		`[{"id":1},{"id":2,`,
+		`[{"type":"purchase","amount":"12"}]`,
+		`[{"type":7}]`,
+		`[{"tags":["a",]}]`,
+	}
+	for _, st := range streamers {
+		for _, in := range inputs {
+			if s, err := st.run(strings.NewReader(in)); err == nil {
+				t.Errorf("%s: %q: want an error; got %+v", st.name, in, s)
+			}
+		}
+	}
+}
+
+func TestErrorsSayWhichEvent(t *testing.T) {
+	in := `[{"type":"view"},{"type":"view"},{"type":"purchase","amount":true}]`
+	for _, st := range streamers {
+		_, err := st.run(strings.NewReader(in))
+		if err == nil || !strings.Contains(err.Error(), "event 3") {
+			t.Errorf("%s: want an error about event 3; got %v", st.name, err)
+		}
+	}
+}
+
+// TestStreamingMemory checks the point of the lesson: the heap a streamer
+// needs doesn't grow with the array, and loading it all does
+func TestStreamingMemory(t *testing.T) {
+	if testing.Short() {
+		t.Skip("generates 10 MB of JSON for each streamer")
+	}
+	peak := func(f func(io.Reader) (stats, error)) uint64 {
+		pr, pw := io.Pipe()
+		go func() { pw.CloseWithError(generate(pw, 10<<20)) }()
+		var err error
+		m := measure(func() { _, err = f(pr) })
+		if err != nil {
+			t.Fatal(err)
+		}
+		return m.peak
+	}
+
+	all := peak(loadAll)
+	for _, st := range streamers {
+		if got := peak(st.run); got > all/4 {
+			t.Errorf("%s: want a peak well under load all's %d MB; got %d MB", st.name, all>>20, got>>20)
+		}
+	}
+}
+
+func BenchmarkStreamers(b *testing.B) {
+	data := events(b, 4<<20)
+	bench := func(name string, f func(io.Reader) (stats, error)) {
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			for b.Loop() {
+				if _, err := f(bytes.NewReader(data)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+	for _, st := range streamers {
+		bench(st.name, st.run)
+	}
+	bench("load all", loadAll)
+}
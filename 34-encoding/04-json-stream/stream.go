@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// streamer is one way to work out the stats of a JSON array of events
+type streamer struct {
+	name string
+	run  func(r io.Reader) (stats, error)
+}
+
+// streamers are the ones the examples and benchmarks compare.
+// stream_v2.go adds the jsontext ones, when it's built
+var streamers = []streamer{
+	{"v1 Decode", decodeV1},
+	{"v1 Token", tokensV1},
+}
+
+// decodeV1 reads the [ as a token, then decodes one element at a time
+// into the same Event. Only one element is in memory at once
+func decodeV1(r io.Reader) (stats, error) {
+	var s stats
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return s, err
+	}
+	for dec.More() {
+		var e Event // a new one: Decode would keep the old Tags
+		if err := dec.Decode(&e); err != nil {
+			return s, fmt.Errorf("event %d: %w", s.Events+1, err)
+		}
+		s.add(e.Type, e.Amount)
+	}
+	return s, expectDelim(dec, ']')
+}
+
+// tokensV1 reads every element token by token, with no struct at all,
+// and skips the members it doesn't need
+func tokensV1(r io.Reader) (stats, error) {
+	var s stats
+	dec := json.NewDecoder(r)
+	if err := expectDelim(dec, '['); err != nil {
+		return s, err
+	}
+	for dec.More() {
+		typ, amount, err := eventTokensV1(dec)
+		if err != nil {
+			return s, fmt.Errorf("event %d: %w", s.Events+1, err)
+		}
+		s.add(typ, amount)
+	}
+	return s, expectDelim(dec, ']')
+}
+
+// eventTokensV1 reads one event, from its { to its }, and keeps the two
+// members the stats need
+func eventTokensV1(dec *json.Decoder) (typ string, amount float64, err error) {
+	if err := expectDelim(dec, '{'); err != nil {
+		return "", 0, err
+	}
+	for dec.More() {
+		name, err := dec.Token() // a member name, as a string
+		if err != nil {
+			return "", 0, err
+		}
+		switch name {
+		case "type", "amount":
+			tok, err := dec.Token()
+			if err != nil {
+				return "", 0, err
+			}
+			var ok bool
+			if name == "type" {
+				typ, ok = tok.(string)
+			} else {
+				amount, ok = tok.(float64)
+			}
+			if !ok {
+				return "", 0, fmt.Errorf("%s: unexpected %#v", name, tok)
+			}
+		default:
+			if err := skipValue(dec); err != nil {
+				return "", 0, err
+			}
+		}
+	}
+	return typ, amount, expectDelim(dec, '}')
+}
+
+// skipValue reads one whole value and drops it. v1 has no way to skip,
+// so it counts brackets
+func skipValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		switch tok {
+		case json.Delim('['), json.Delim('{'):
+			depth++
+		case json.Delim(']'), json.Delim('}'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if tok != want {
+		return fmt.Errorf("want %v, got %v", want, tok)
+	}
+	return nil
+}
+
+// loadAll is what streaming avoids: every byte, then every event, in
+// memory at once
+func loadAll(r io.Reader) (stats, error) {
+	var s stats
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return s, err
+	}
+	var events []Event
+	if err := json.Unmarshal(data, &events); err != nil {
+		return s, err
+	}
+	for _, e := range events {
+		s.add(e.Type, e.Amount)
+	}
+	return s, nil
+}
@@ -0,0 +1,114 @@
+//go:build goexperiment.jsonv2 && !go1.27
+
+package main
+
+import (
+	"encoding/json/jsontext"
+	"encoding/json/v2"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// JSON v2 is an experiment in Go 1.25 and 1.26, so this file is only
+// built with GOEXPERIMENT=jsonv2. From Go 1.27 it's stable, but only for
+// modules that say go 1.27 in go.mod, and this one says 1.25: on Go 1.27,
+// run it with GOTOOLCHAIN=go1.26.0
+func init() {
+	streamers = append(streamers,
+		streamer{"v2 UnmarshalDecode", decodeV2},
+		streamer{"jsontext tokens", tokensV2},
+	)
+}
+
+// decodeV2 is decodeV1 with v2: the decoder is a jsontext.Decoder, and
+// json.UnmarshalDecode reads one value from it
+func decodeV2(r io.Reader) (stats, error) {
+	var s stats
+	dec := jsontext.NewDecoder(r)
+	if err := expectKind(dec, '['); err != nil {
+		return s, err
+	}
+	for dec.PeekKind() != ']' {
+		var e Event
+		if err := json.UnmarshalDecode(dec, &e); err != nil {
+			return s, fmt.Errorf("event %d: %w", s.Events+1, err)
+		}
+		s.add(e.Type, e.Amount)
+	}
+	return s, expectKind(dec, ']')
+}
+
+// tokensV2 is tokensV1 with jsontext. A Token isn't an interface, so
+// reading one doesn't allocate, and SkipValue replaces the bracket
+// counting
+func tokensV2(r io.Reader) (stats, error) {
+	var s stats
+	dec := jsontext.NewDecoder(r)
+	if err := expectKind(dec, '['); err != nil {
+		return s, err
+	}
+	for dec.PeekKind() != ']' {
+		typ, amount, err := eventTokensV2(dec)
+		if err != nil {
+			return s, fmt.Errorf("event %d: %w", s.Events+1, err)
+		}
+		s.add(typ, amount)
+	}
+	return s, expectKind(dec, ']')
+}
+
+func eventTokensV2(dec *jsontext.Decoder) (typ string, amount float64, err error) {
+	if err := expectKind(dec, '{'); err != nil {
+		return "", 0, err
+	}
+	for dec.PeekKind() != '}' {
+		name, err := dec.ReadToken()
+		if err != nil {
+			return "", 0, err
+		}
+		switch name.String() {
+		case "type":
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return "", 0, err
+			}
+			if tok.Kind() != '"' {
+				return "", 0, fmt.Errorf("type: unexpected %v", tok)
+			}
+			typ = tok.String()
+		case "amount":
+			tok, err := dec.ReadToken()
+			if err != nil {
+				return "", 0, err
+			}
+			if tok.Kind() != '0' {
+				return "", 0, fmt.Errorf("amount: unexpected %v", tok)
+			}
+			// Token.String is a number's text. Float would do, but its
+			// signature changed when v2 left the experiment
+			if amount, err = strconv.ParseFloat(tok.String(), 64); err != nil {
+				return "", 0, err
+			}
+		default:
+			if err := dec.SkipValue(); err != nil {
+				return "", 0, err
+			}
+		}
+	}
+	return typ, amount, expectKind(dec, '}')
+}
+
+func expectKind(dec *jsontext.Decoder, want jsontext.Kind) error {
+	tok, err := dec.ReadToken()
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if tok.Kind() != want {
+		return fmt.Errorf("want %v, got %v", want, tok.Kind())
+	}
+	return nil
+}
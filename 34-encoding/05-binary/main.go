@@ -0,0 +1,122 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strings"
+	"unsafe"
+)
+
+// padded shows the difference between Go's memory layout and the
+// encoding: Go pads B to a 4-byte boundary, binary doesn't
+type padded struct {
+	A uint8
+	B uint32
+}
+
+// notFixed can't be encoded by binary.Write: an int's size depends on
+// the machine, and a string's on its contents
+type notFixed struct {
+	N    int
+	Name string
+}
+
+// tone is a sine wave at hz, as 16-bit mono samples
+func tone(hz float64, sampleRate, n int) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		v := math.Sin(2 * math.Pi * hz * float64(i) / float64(sampleRate))
+		samples[i] = int16(v * math.MaxInt16 / 2) // at half volume
+	}
+	return samples
+}
+
+func main() {
+	fmt.Println("encoding/binary: Fixed-Format Binary Files")
+	fmt.Println("==========================================")
+	fmt.Println()
+
+	// Example 1: Byte order
+	fmt.Println("1. 0x01020304 as four bytes:")
+	fmt.Printf("   BigEndian:    % x (network order, and most file formats)\n", binary.BigEndian.AppendUint32(nil, 0x01020304))
+	fmt.Printf("   LittleEndian: % x (x86, ARM, and WAV files)\n", binary.LittleEndian.AppendUint32(nil, 0x01020304))
+	fmt.Printf("   read back as the wrong one: %#x\n", binary.LittleEndian.Uint32([]byte{1, 2, 3, 4}))
+	fmt.Println()
+
+	// Example 2: Structs
+	fmt.Println("2. Structs, in memory and encoded:")
+	fmt.Printf("   padded:    unsafe.Sizeof = %d, binary.Size = %d\n", unsafe.Sizeof(padded{}), binary.Size(padded{}))
+	fmt.Printf("   wavHeader: unsafe.Sizeof = %d, binary.Size = %d\n", unsafe.Sizeof(wavHeader{}), binary.Size(wavHeader{}))
+	fmt.Printf("   notFixed:  binary.Size = %d\n", binary.Size(notFixed{}))
+	err := binary.Write(io.Discard, binary.LittleEndian, notFixed{N: 1})
+	fmt.Printf("   binary.Write(notFixed): %v\n", err)
+	fmt.Println()
+
+	// Example 3: A WAV file
+	fmt.Println("3. A WAV file: a 440 Hz tone, 8000 samples a second, mono:")
+	var file bytes.Buffer
+	sound := Sound{SampleRate: 8000, Channels: 1, Samples: tone(440, 8000, 8000/100)}
+	if err := WriteWAV(&file, sound); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   %d bytes: a 44-byte header and %d samples\n", file.Len(), len(sound.Samples))
+	for line := range strings.Lines(hex.Dump(file.Bytes()[:48])) {
+		fmt.Print("   ", line)
+	}
+	got, err := ReadWAV(bytes.NewReader(file.Bytes()))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   read back: %d Hz, %d channel(s), samples %v...\n", got.SampleRate, got.Channels, got.Samples[:4])
+
+	corrupt := bytes.Clone(file.Bytes())
+	binary.LittleEndian.PutUint32(corrupt[40:], math.MaxUint32) // DataSize
+	_, err = ReadWAV(bytes.NewReader(corrupt))
+	fmt.Printf("   a header that claims 4 GB: %v\n", err)
+	fmt.Println()
+
+	// Example 4: Varints
+	fmt.Println("4. Varints: small numbers take fewer bytes:")
+	for _, n := range []uint64{1, 127, 128, 16_383, 16_384, 1 << 32} {
+		fmt.Printf("   uvarint %-10d % x\n", n, binary.AppendUvarint(nil, n))
+	}
+	for _, n := range []int64{-1, 1, -64, 64} {
+		fmt.Printf("   varint  %-10d % x\n", n, binary.AppendVarint(nil, n))
+	}
+	fmt.Println()
+
+	// Example 5: A log of varint-framed records
+	fmt.Println("5. An append-only log of records:")
+	records := []Record{
+		{Time: 1740821400000, Key: "user:1", Value: []byte("ada")},
+		{Time: 1740821400250, Key: "user:2", Value: []byte("grace")},
+		{Time: 1740821401000, Key: "user:1", Value: nil}, // a deletion
+	}
+	var data []byte
+	var prev int64
+	for _, r := range records {
+		data = appendRecord(data, r, prev)
+		prev = r.Time
+	}
+	fixed := 0
+	for _, r := range records {
+		fixed += 8 + 4 + len(r.Key) + 4 + len(r.Value) // int64 time, uint32 lengths
+	}
+	fmt.Printf("   %d records in %d bytes (%d with fixed-size fields)\n", len(records), len(data), fixed)
+	fmt.Printf("   % x\n", data)
+
+	read, err := readLog(bytes.NewReader(data))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, r := range read {
+		fmt.Printf("   %d %-7s %q\n", r.Time, r.Key, r.Value)
+	}
+	read, err = readLog(bytes.NewReader(data[:len(data)-2]))
+	fmt.Printf("   cut short: %d records, err: %v\n", len(read), err)
+}
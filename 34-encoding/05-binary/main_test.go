@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestWAVRoundTrip(t *testing.T) {
+	sounds := []Sound{
+		{SampleRate: 8000, Channels: 1, Samples: tone(440, 8000, 800)},
+		{SampleRate: 44100, Channels: 2, Samples: []int16{math.MinInt16, math.MaxInt16, -1, 0}},
+		{SampleRate: 22050, Channels: 1, Samples: []int16{}}, // silence, with no samples
+	}
+	for _, s := range sounds {
+		var buf bytes.Buffer
+		if err := WriteWAV(&buf, s); err != nil {
+			t.Fatal(err)
+		}
+		if want := 44 + 2*len(s.Samples); buf.Len() != want {
+			t.Errorf("want %d bytes; got %d", want, buf.Len())
+		}
+		got, err := ReadWAV(&buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.SampleRate != s.SampleRate || got.Channels != s.Channels || !slices.Equal(got.Samples, s.Samples) {
+			t.Errorf("want %d Hz, %d channels, %d samples; got %d, %d, %d",
+				s.SampleRate, s.Channels, len(s.Samples), got.SampleRate, got.Channels, len(got.Samples))
+		}
+	}
+}
+
+// TestWAVHeaderBytes checks the header against the bytes of the format,
+// written out by hand: a struct field in the wrong order or of the
+// wrong size would still round-trip, but no other program could read it
+func TestWAVHeaderBytes(t *testing.T) {
+	var buf bytes.Buffer
+	if err := WriteWAV(&buf, Sound{SampleRate: 8000, Channels: 2, Samples: []int16{1, -2}}); err != nil {
+		t.Fatal(err)
+	}
+	want := "52494646" + "28000000" + "57415645" + // RIFF, 40, WAVE
+		"666d7420" + "10000000" + "0100" + "0200" + // fmt, 16, PCM, 2 channels
+		"401f0000" + "007d0000" + "0400" + "1000" + // 8000 Hz, 32000 bytes/s, 4, 16 bits
+		"64617461" + "04000000" + // data, 4
+		"0100" + "feff" // 1, -2
+	if got := hex.EncodeToString(buf.Bytes()); got != want {
+		t.Errorf("want\n%s\ngot\n%s", want, got)
+	}
+}
+
+func TestReadWAVErrors(t *testing.T) {
+	var good bytes.Buffer
+	WriteWAV(&good, Sound{SampleRate: 8000, Channels: 1, Samples: []int16{1, 2, 3}})
+
+	// change returns good's bytes, changed by f
+	change := func(f func(b []byte) []byte) []byte {
+		return f(bytes.Clone(good.Bytes()))
+	}
+	tests := []struct {
+		name string
+		in   []byte
+		want error
+	}{
+		{"empty", nil, ErrNotWAV},
+		{"short header", good.Bytes()[:20], ErrNotWAV},
+		{"not RIFF", change(func(b []byte) []byte { copy(b, "RIFX"); return b }), ErrNotWAV},
+		{"8 bits", change(func(b []byte) []byte { b[34] = 8; return b }), ErrUnsupported},
+		{"not PCM", change(func(b []byte) []byte { b[20] = 3; return b }), ErrUnsupported},
+		{"a LIST chunk first", change(func(b []byte) []byte { copy(b[36:], "LIST"); return b }), ErrUnsupported},
+		{"huge data size", change(func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[40:], math.MaxUint32)
+			return b
+		}), ErrNotWAV},
+		{"samples cut short", good.Bytes()[:good.Len()-1], io.ErrUnexpectedEOF},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ReadWAV(bytes.NewReader(tt.in))
+			if !errors.Is(err, tt.want) {
+				t.Errorf("want %v; got %v", tt.want, err)
+			}
+		})
+	}
+
+	if err := WriteWAV(io.Discard, Sound{SampleRate: 8000, Channels: 2, Samples: []int16{1}}); err == nil {
+		t.Error("want an error for a stereo sound with an odd number of samples")
+	}
+}
+
+func TestBinaryStructLimits(t *testing.T) {
+	if got := binary.Size(padded{}); got != 5 {
+		t.Errorf("want 5 bytes, with no padding; got %d", got)
+	}
+	if got := binary.Size(notFixed{}); got != -1 {
+		t.Errorf("want -1 for a struct with an int; got %d", got)
+	}
+	if err := binary.Write(io.Discard, binary.LittleEndian, notFixed{}); err == nil {
+		t.Error("want an error writing an int and a string")
+	}
+}
+
+func TestRecordRoundTrip(t *testing.T) {
+	records := []Record{
+		{Time: 1740821400000, Key: "a", Value: []byte("1")},
+		{Time: 1740821399000, Key: "b", Value: bytes.Repeat([]byte("x"), 300)}, // earlier, and long
+		{Time: 1740821399000, Key: "", Value: nil},
+	}
+	var data []byte
+	var prev int64
+	for _, r := range records {
+		data = appendRecord(data, r, prev)
+		prev = r.Time
+	}
+
+	got, err := readLog(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != len(records) {
+		t.Fatalf("want %d records; got %d", len(records), len(got))
+	}
+	for i, r := range records {
+		if got[i].Time != r.Time || got[i].Key != r.Key || !bytes.Equal(got[i].Value, r.Value) {
+			t.Errorf("record %d: want %+v; got %+v", i, r, got[i])
+		}
+	}
+}
+
+func TestRecordSizes(t *testing.T) {
+	// A record 100 ms after the last, with a short key and value, fits
+	// in 2 + 1+3 + 1+5 bytes
+	data := appendRecord(nil, Record{Time: 1100, Key: "key", Value: []byte("value")}, 1000)
+	if len(data) != 12 {
+		t.Errorf("want 12 bytes; got %d: % x", len(data), data)
+	}
+}
+
+func TestReadLogErrors(t *testing.T) {
+	good := appendRecord(nil, Record{Time: 1, Key: "key", Value: []byte("value")}, 0)
+
+	// Every cut but the whole record is an error; the record before it
+	// is still returned
+	two := appendRecord(bytes.Clone(good), Record{Time: 2, Key: "k", Value: []byte("v")}, 1)
+	for n := len(good) + 1; n < len(two); n++ {
+		got, err := readLog(bytes.NewReader(two[:n]))
+		if !errors.Is(err, io.ErrUnexpectedEOF) || len(got) != 1 {
+			t.Errorf("cut at %d: want 1 record and ErrUnexpectedEOF; got %d, %v", n, len(got), err)
+		}
+	}
+
+	huge := binary.AppendVarint(nil, 0)
+	huge = binary.AppendUvarint(huge, maxField+1)
+	if _, err := readLog(bytes.NewReader(huge)); err == nil {
+		t.Error("want an error for a key over the limit")
+	}
+
+	overflow := bytes.Repeat([]byte{0xff}, 11) // a varint longer than 64 bits
+	if _, err := readLog(bytes.NewReader(overflow)); err == nil {
+		t.Error("want an error for a varint that overflows")
+	}
+}
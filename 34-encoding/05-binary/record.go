@@ -0,0 +1,96 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Record is one entry of an append-only log: a key, a value, and when
+// it was written
+type Record struct {
+	Time  int64 // Unix milliseconds
+	Key   string
+	Value []byte
+}
+
+// appendRecord appends r to buf, and returns the longer slice, in the
+// style of strconv.AppendInt. Each part takes only the bytes it needs:
+//
+//	varint   time, as the change from prev
+//	uvarint  len(key), then the key
+//	uvarint  len(value), then the value
+func appendRecord(buf []byte, r Record, prev int64) []byte {
+	buf = binary.AppendVarint(buf, r.Time-prev) // signed: the clock can go back
+	buf = binary.AppendUvarint(buf, uint64(len(r.Key)))
+	buf = append(buf, r.Key...)
+	buf = binary.AppendUvarint(buf, uint64(len(r.Value)))
+	return append(buf, r.Value...)
+}
+
+// maxField is the longest key or value readRecord accepts
+const maxField = 1 << 20
+
+// readRecord reads a record that follows one written at prev. At the
+// end of the log, between records, it returns io.EOF
+func readRecord(r *bufio.Reader, prev int64) (Record, error) {
+	delta, err := binary.ReadVarint(r)
+	if err != nil {
+		return Record{}, err // io.EOF here is a clean end
+	}
+	key, err := readField(r)
+	if err != nil {
+		return Record{}, fmt.Errorf("record key: %w", err)
+	}
+	value, err := readField(r)
+	if err != nil {
+		return Record{}, fmt.Errorf("record value: %w", err)
+	}
+	return Record{Time: prev + delta, Key: string(key), Value: value}, nil
+}
+
+// readField reads a uvarint length, then that many bytes
+func readField(r *bufio.Reader) ([]byte, error) {
+	n, err := binary.ReadUvarint(r)
+	if err != nil {
+		return nil, noEOF(err)
+	}
+	if n > maxField {
+		return nil, fmt.Errorf("length %d is over the limit of %d", n, maxField)
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return nil, noEOF(err)
+	}
+	return b, nil
+}
+
+// noEOF turns io.EOF into io.ErrUnexpectedEOF: in the middle of a
+// record, the end of the input means it was cut short
+func noEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// readLog reads every record in r. A record that's cut short is an
+// error, with the records before it
+func readLog(r io.Reader) ([]Record, error) {
+	br := bufio.NewReader(r)
+	var records []Record
+	var prev int64
+	for {
+		rec, err := readRecord(br, prev)
+		if err == io.EOF {
+			return records, nil
+		}
+		if err != nil {
+			return records, fmt.Errorf("record %d: %w", len(records)+1, err)
+		}
+		records = append(records, rec)
+		prev = rec.Time
+	}
+}
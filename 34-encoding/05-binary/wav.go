@@ -0,0 +1,110 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// wavHeader is the 44-byte header of a plain PCM WAV file, field for
+// field. binary.Read and binary.Write handle a struct like this one
+// because every field has a fixed size: no int, string, or slice. They
+// also don't pad between fields as a C compiler might, so the struct is
+// the bytes of the file exactly
+type wavHeader struct {
+	RIFF [4]byte // "RIFF"
+	Size uint32  // the size of the file, minus these 8 bytes
+	WAVE [4]byte // "WAVE"
+
+	Fmt           [4]byte // "fmt "
+	FmtSize       uint32  // 16, the size of the fields up to Data
+	Format        uint16  // 1 is PCM: no compression
+	Channels      uint16
+	SampleRate    uint32 // samples a second, for each channel
+	ByteRate      uint32 // SampleRate * BlockAlign
+	BlockAlign    uint16 // the bytes of one sample, for every channel
+	BitsPerSample uint16
+
+	Data     [4]byte // "data"
+	DataSize uint32  // the size of the samples that follow
+}
+
+// Sound is 16-bit PCM audio: for two channels, the samples alternate
+// left, right, left, right
+type Sound struct {
+	SampleRate int
+	Channels   int
+	Samples    []int16
+}
+
+var (
+	ErrNotWAV      = errors.New("wav: not a WAV file")
+	ErrUnsupported = errors.New("wav: unsupported format")
+)
+
+// WriteWAV writes s to w as a WAV file. WAV is little endian, as every
+// RIFF format is
+func WriteWAV(w io.Writer, s Sound) error {
+	if s.Channels < 1 || s.SampleRate < 1 || len(s.Samples)%s.Channels != 0 {
+		return fmt.Errorf("wav: bad sound: %d channels, %d Hz, %d samples", s.Channels, s.SampleRate, len(s.Samples))
+	}
+	dataSize := 2 * len(s.Samples)
+	h := wavHeader{
+		RIFF:          [4]byte{'R', 'I', 'F', 'F'},
+		Size:          uint32(36 + dataSize),
+		WAVE:          [4]byte{'W', 'A', 'V', 'E'},
+		Fmt:           [4]byte{'f', 'm', 't', ' '},
+		FmtSize:       16,
+		Format:        1,
+		Channels:      uint16(s.Channels),
+		SampleRate:    uint32(s.SampleRate),
+		ByteRate:      uint32(s.SampleRate * s.Channels * 2),
+		BlockAlign:    uint16(s.Channels * 2),
+		BitsPerSample: 16,
+		Data:          [4]byte{'d', 'a', 't', 'a'},
+		DataSize:      uint32(dataSize),
+	}
+	if err := binary.Write(w, binary.LittleEndian, h); err != nil {
+		return err
+	}
+	// A slice of fixed-size values is fine too: it writes each in turn
+	return binary.Write(w, binary.LittleEndian, s.Samples)
+}
+
+// ReadWAV reads a WAV file written by WriteWAV, or by any program that
+// writes the plain 44-byte header
+func ReadWAV(r io.Reader) (Sound, error) {
+	var h wavHeader
+	if err := binary.Read(r, binary.LittleEndian, &h); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return Sound{}, fmt.Errorf("%w: too short", ErrNotWAV)
+		}
+		return Sound{}, err
+	}
+	if string(h.RIFF[:]) != "RIFF" || string(h.WAVE[:]) != "WAVE" {
+		return Sound{}, ErrNotWAV
+	}
+	if string(h.Fmt[:]) != "fmt " || h.FmtSize != 16 || string(h.Data[:]) != "data" {
+		// Many files have more chunks, or a longer fmt chunk. A fixed
+		// struct can't describe those: see the README
+		return Sound{}, fmt.Errorf("%w: the chunks aren't fmt then data", ErrUnsupported)
+	}
+	if h.Format != 1 || h.BitsPerSample != 16 || h.Channels == 0 {
+		return Sound{}, fmt.Errorf("%w: format %d, %d bits, %d channels", ErrUnsupported, h.Format, h.BitsPerSample, h.Channels)
+	}
+	// The header says how many samples follow. Check it before trusting
+	// it with an allocation that size: a broken file can claim 4 GB
+	if h.DataSize%uint32(2*h.Channels) != 0 || h.DataSize > maxDataSize {
+		return Sound{}, fmt.Errorf("%w: %d bytes of samples", ErrNotWAV, h.DataSize)
+	}
+	samples := make([]int16, h.DataSize/2)
+	if err := binary.Read(r, binary.LittleEndian, samples); err != nil {
+		return Sound{}, fmt.Errorf("wav: reading %d samples: %w", len(samples), err)
+	}
+	return Sound{SampleRate: int(h.SampleRate), Channels: int(h.Channels), Samples: samples}, nil
+}
+
+// maxDataSize is the most audio ReadWAV will allocate for: about three
+// minutes of CD-quality stereo
+const maxDataSize = 32 << 20
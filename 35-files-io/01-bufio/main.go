@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const text = `Clear is better than clever.
+A little copying is better than a little dependency.
+
+Errors are values.
+Don't just check errors, handle them gracefully.
+
+
+Documentation is for users.`
+
+func main() {
+	fmt.Println("bufio: Scanner, Reader, and Writer")
+	fmt.Println("==================================")
+	fmt.Println()
+
+	// Example 1: Built-in split functions
+	fmt.Println("1. One input, three split functions:")
+	for _, split := range []struct {
+		name string
+		fn   bufio.SplitFunc
+	}{
+		{"ScanLines", bufio.ScanLines},
+		{"ScanWords", bufio.ScanWords},
+		{"ScanRunes", bufio.ScanRunes},
+	} {
+		sc := bufio.NewScanner(strings.NewReader("héllo, wörld\nbye"))
+		sc.Split(split.fn)
+		var tokens []string
+		for sc.Scan() {
+			tokens = append(tokens, sc.Text())
+		}
+		fmt.Printf("   %-9s %q\n", split.name, tokens)
+	}
+	fmt.Println()
+
+	// Example 2: A split function of your own
+	fmt.Println("2. scanParagraphs, a custom split function:")
+	sc := bufio.NewScanner(strings.NewReader(text))
+	sc.Split(scanParagraphs)
+	for i := 1; sc.Scan(); i++ {
+		fmt.Printf("   paragraph %d: %q\n", i, sc.Text())
+	}
+	fmt.Println()
+
+	// Example 3: The token size limit
+	fmt.Println("3. A line longer than 64 KB:")
+	long := "first line\n" + strings.Repeat("x", 100_000) + "\nlast line\n"
+	sc = bufio.NewScanner(strings.NewReader(long))
+	lines := 0
+	for sc.Scan() {
+		lines++
+	}
+	fmt.Printf("   default buffer:  %d line(s), then err: %v\n", lines, sc.Err())
+
+	sc = bufio.NewScanner(strings.NewReader(long))
+	sc.Buffer(make([]byte, 0, 64*1024), 1024*1024) // start at 64 KB, grow to 1 MB
+	lines = 0
+	for sc.Scan() {
+		lines++
+	}
+	fmt.Printf("   Buffer(_, 1MB):  %d line(s), err: %v\n", lines, sc.Err())
+
+	n, err := longestLine(strings.NewReader(long))
+	fmt.Printf("   bufio.Reader:    the longest line is %d bytes, err: %v\n", n, err)
+	fmt.Println()
+
+	// Example 4: bufio.Reader: look ahead, and read runes
+	fmt.Println("4. bufio.Reader's Peek, ReadString, and ReadRune:")
+	br := bufio.NewReader(strings.NewReader("#!/bin/sh\necho héllo\n"))
+	magic, _ := br.Peek(2) // look without consuming
+	fmt.Printf("   Peek(2) = %q, a script: %t\n", magic, string(magic) == "#!")
+	first, _ := br.ReadString('\n')
+	fmt.Printf("   ReadString = %q (Peek didn't consume the #!)\n", first)
+	var runes []string
+	for {
+		r, size, err := br.ReadRune()
+		if err != nil {
+			break
+		}
+		if size > 1 {
+			runes = append(runes, fmt.Sprintf("%c=%d bytes", r, size))
+		}
+	}
+	fmt.Printf("   ReadRune found %v\n", runes)
+	fmt.Println()
+
+	// Example 5: bufio.Writer, and Flush
+	fmt.Println("5. bufio.Writer holds what you write until it's full, or flushed:")
+	var out strings.Builder
+	bw := bufio.NewWriter(&out)
+	fmt.Fprintln(bw, "hello")
+	fmt.Printf("   after Fprintln: %d bytes out\n", out.Len())
+	if err := bw.Flush(); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   after Flush:    %d bytes out\n", out.Len())
+
+	direct := &countingWriter{w: io.Discard}
+	counted := &countingWriter{w: io.Discard}
+	bw = bufio.NewWriter(counted)
+	for i := range 10_000 {
+		fmt.Fprintf(direct, "line %d\n", i)
+		fmt.Fprintf(bw, "line %d\n", i)
+	}
+	bw.Flush()
+	fmt.Printf("   10000 lines: %d writes unbuffered, %d buffered\n", direct.writes, counted.writes)
+	fmt.Println()
+
+	// Example 6: The cost of a system call per write, and per read
+	fmt.Println("6. A 2 MB file, written and read with and without bufio:")
+	dir, err := os.MkdirTemp("", "bufio")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "lines.txt")
+
+	for _, buffered := range []bool{false, true} {
+		start := time.Now()
+		if err := writeLines(path, 100_000, buffered); err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   write, buffered=%-5t %v\n", buffered, time.Since(start).Round(time.Millisecond))
+	}
+	for _, buffered := range []bool{false, true} {
+		start := time.Now()
+		n, err := countLines(path, buffered)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   read,  buffered=%-5t %v (%d lines)\n", buffered, time.Since(start).Round(time.Millisecond), n)
+	}
+}
+
+// writeLines writes n numbered lines to a new file at path, through a
+// bufio.Writer or straight to the file
+func writeLines(path string, n int, buffered bool) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	// Close can fail too: the last write to a file may only fail there
+	defer func() { err = errors.Join(err, f.Close()) }()
+
+	var w io.Writer = f
+	var bw *bufio.Writer
+	if buffered {
+		bw = bufio.NewWriter(f)
+		w = bw
+	}
+	for i := range n {
+		if _, err := fmt.Fprintf(w, "line %06d of the file\n", i); err != nil {
+			return err
+		}
+	}
+	if bw != nil {
+		return bw.Flush() // without it, the last few KB are lost
+	}
+	return nil
+}
+
+// countLines counts the lines in the file at path, reading it through a
+// bufio.Reader or a byte at a time from the file
+func countLines(path string, buffered bool) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	var r io.ByteReader = oneByteReader{f}
+	if buffered {
+		r = bufio.NewReader(f)
+	}
+	lines := 0
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return lines, nil
+		}
+		if err != nil {
+			return lines, err
+		}
+		if b == '\n' {
+			lines++
+		}
+	}
+}
+
+// oneByteReader reads a byte at a time from r, with a Read call for
+// each: a system call per byte, for a file
+type oneByteReader struct{ r io.Reader }
+
+func (o oneByteReader) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(o.r, b[:])
+	return b[0], err
+}
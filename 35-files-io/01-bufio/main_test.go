@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func paragraphs(t *testing.T, r io.Reader) []string {
+	t.Helper()
+	sc := bufio.NewScanner(r)
+	sc.Split(scanParagraphs)
+	var got []string
+	for sc.Scan() {
+		got = append(got, sc.Text())
+	}
+	if err := sc.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return got
+}
+
+func TestScanParagraphs(t *testing.T) {
+	tests := []struct {
+		name, in string
+		want     []string
+	}{
+		{"empty", "", nil},
+		{"only blank lines", "\n\n\n", nil},
+		{"one line", "a", []string{"a"}},
+		{"one paragraph", "a\nb\n", []string{"a\nb"}},
+		{"two", "a\nb\n\nc\n", []string{"a\nb", "c"}},
+		{"many blank lines", "\n\na\n\n\n\nb", []string{"a", "b"}},
+		{"crlf", "a\r\nb\r\n\r\nc\r\n", []string{"a\r\nb", "c"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := paragraphs(t, strings.NewReader(tt.in)); !slices.Equal(got, tt.want) {
+				t.Errorf("want %q; got %q", tt.want, got)
+			}
+
+			// A byte at a time, the split function sees every partial
+			// paragraph, and must ask for more
+			if got := paragraphs(t, iotest.OneByteReader(strings.NewReader(tt.in))); !slices.Equal(got, tt.want) {
+				t.Errorf("a byte at a time: want %q; got %q", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestTokenTooLong(t *testing.T) {
+	long := "a\n" + strings.Repeat("x", bufio.MaxScanTokenSize) + "\nb\n"
+
+	sc := bufio.NewScanner(strings.NewReader(long))
+	var lines []string
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if !errors.Is(sc.Err(), bufio.ErrTooLong) || len(lines) != 1 {
+		t.Errorf("want one line, then ErrTooLong; got %d, %v", len(lines), sc.Err())
+	}
+
+	sc = bufio.NewScanner(strings.NewReader(long))
+	sc.Buffer(nil, 2*bufio.MaxScanTokenSize)
+	lines = lines[:0]
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	if sc.Err() != nil || len(lines) != 3 || len(lines[1]) != bufio.MaxScanTokenSize {
+		t.Errorf("want 3 lines with a bigger buffer; got %d, %v", len(lines), sc.Err())
+	}
+}
+
+func TestLongestLine(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int
+	}{
+		{"", 0},
+		{"abc", 3},
+		{"a\nabcd\r\nab\n", 4},
+		{"a\n" + strings.Repeat("x", 1<<20), 1 << 20}, // far past any buffer
+	}
+	for _, tt := range tests {
+		got, err := longestLine(strings.NewReader(tt.in))
+		if err != nil || got != tt.want {
+			t.Errorf("%.20q: want %d; got %d, %v", tt.in, tt.want, got, err)
+		}
+	}
+
+	_, err := longestLine(iotest.ErrReader(io.ErrClosedPipe))
+	if !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("want the reader's error; got %v", err)
+	}
+}
+
+func TestWriterNeedsFlush(t *testing.T) {
+	var out strings.Builder
+	cw := &countingWriter{w: &out}
+	bw := bufio.NewWriterSize(cw, 16)
+	bw.WriteString("0123456789")
+	if out.Len() != 0 || cw.writes != 0 {
+		t.Errorf("want nothing written before the buffer fills; got %q", out.String())
+	}
+	bw.WriteString("0123456789") // fills the buffer of 16
+	bw.Flush()
+	if out.String() != strings.Repeat("0123456789", 2) || cw.writes != 2 {
+		t.Errorf("want everything in 2 writes; got %q in %d", out.String(), cw.writes)
+	}
+}
+
+func TestWriteAndCountLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lines.txt")
+	for _, wb := range []bool{false, true} {
+		if err := writeLines(path, 1000, wb); err != nil {
+			t.Fatal(err)
+		}
+		for _, rb := range []bool{false, true} {
+			n, err := countLines(path, rb)
+			if err != nil || n != 1000 {
+				t.Errorf("written buffered=%t, read buffered=%t: want 1000 lines; got %d, %v", wb, rb, n, err)
+			}
+		}
+	}
+
+	if _, err := countLines(filepath.Join(t.TempDir(), "missing"), true); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want ErrNotExist; got %v", err)
+	}
+}
+
+func BenchmarkWriteLines(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "lines.txt")
+	for _, buffered := range []bool{false, true} {
+		name := "unbuffered"
+		if buffered {
+			name = "buffered"
+		}
+		b.Run(name, func(b *testing.B) {
+			for b.Loop() {
+				if err := writeLines(path, 10_000, buffered); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkCountLines(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "lines.txt")
+	if err := writeLines(path, 10_000, true); err != nil {
+		b.Fatal(err)
+	}
+	info, _ := os.Stat(path)
+	for _, buffered := range []bool{false, true} {
+		name := "unbuffered"
+		if buffered {
+			name = "buffered"
+		}
+		b.Run(name, func(b *testing.B) {
+			b.SetBytes(info.Size())
+			for b.Loop() {
+				if _, err := countLines(path, buffered); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+)
+
+// scanParagraphs is a bufio.SplitFunc: its tokens are paragraphs, split
+// by one or more blank lines.
+//
+// A split function gets the unread data so far, and says how much of it
+// to consume and which token it holds. Returning 0, nil, nil asks for
+// more data; the Scanner calls again with a bigger slice
+func scanParagraphs(data []byte, atEOF bool) (advance int, token []byte, err error) {
+	// Skip blank lines before the paragraph
+	start := 0
+	for start < len(data) && (data[start] == '\n' || data[start] == '\r') {
+		start++
+	}
+	if i := bytes.Index(data[start:], []byte("\n\n")); i >= 0 {
+		return start + i + 2, dropCR(data[start : start+i]), nil
+	}
+	if i := bytes.Index(data[start:], []byte("\n\r\n")); i >= 0 {
+		return start + i + 3, dropCR(data[start : start+i]), nil
+	}
+	if atEOF && start < len(data) {
+		// The last paragraph, with no blank line after it
+		return len(data), dropCR(bytes.TrimRight(data[start:], "\r\n")), nil
+	}
+	if atEOF {
+		return len(data), nil, nil // only blank lines were left
+	}
+	return start, nil, nil
+}
+
+// dropCR removes a \r from the end of a line, as bufio.ScanLines does
+func dropCR(b []byte) []byte {
+	return bytes.TrimSuffix(b, []byte("\r"))
+}
+
+// countingWriter counts the Write calls that reach w. For a file or a
+// socket, each one is a system call
+type countingWriter struct {
+	w      io.Writer
+	writes int
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	c.writes++
+	return c.w.Write(p)
+}
+
+// longestLine returns the length of the longest line in r. It reads
+// with a bufio.Reader, whose ReadString has no limit on a line's length:
+// it grows the string for as long as the line goes on
+func longestLine(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	longest := 0
+	for {
+		line, err := br.ReadString('\n')
+		longest = max(longest, len(strings.TrimRight(line, "\r\n")))
+		if err == io.EOF {
+			return longest, nil
+		}
+		if err != nil {
+			return longest, err
+		}
+	}
+}
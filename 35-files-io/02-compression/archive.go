@@ -0,0 +1,190 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+)
+
+// writeTarGz writes every file in fsys to w as a .tar.gz, one file at a
+// time: nothing is held in memory but the file being copied
+func writeTarGz(w io.Writer, fsys fs.FS) error {
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	if err := tw.AddFS(fsys); err != nil { // Go 1.22
+		return err
+	}
+	// Both write a footer on Close, the tar writer's first
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// writeZip writes every file in fsys to w as a .zip
+func writeZip(w io.Writer, fsys fs.FS) error {
+	zw := zip.NewWriter(w)
+	if err := zw.AddFS(fsys); err != nil { // Go 1.22
+		return err
+	}
+	return zw.Close() // writes the central directory, the zip's index
+}
+
+// Limits bound what an extract may write: an archive from anyone else is
+// untrusted input
+type Limits struct {
+	Files int   // the most files
+	Bytes int64 // the most bytes, for all the files together
+}
+
+var (
+	ErrTooLarge = errors.New("archive: over the size limit")
+	ErrUnsafe   = errors.New("archive: unsafe entry")
+)
+
+// extractTarGz extracts a .tar.gz from r into dir, as it's read. Every
+// path goes through an os.Root, so "../x" and absolute paths can't
+// escape dir, and only files and directories are created: a link could
+// point anywhere
+func extractTarGz(r io.Reader, dir string, lim Limits) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	root, err := os.OpenRoot(dir) // Go 1.24
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	tr := tar.NewReader(gz)
+	budget := lim.Bytes
+	for files := 0; ; files++ {
+		h, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if files == lim.Files {
+			return fmt.Errorf("%w: more than %d files", ErrTooLarge, lim.Files)
+		}
+
+		switch h.Typeflag {
+		case tar.TypeDir:
+			if err := root.MkdirAll(h.Name, 0o755); err != nil { // Go 1.25
+				return fmt.Errorf("%w: %v", ErrUnsafe, err)
+			}
+		case tar.TypeReg:
+			n, err := extractFile(root, h.Name, tr, budget)
+			if err != nil {
+				return err
+			}
+			budget -= n
+		default:
+			return fmt.Errorf("%w: %s is not a file or a directory", ErrUnsafe, h.Name)
+		}
+	}
+}
+
+// extractZip extracts a .zip. Its index is at the end, so the zip reader
+// needs an io.ReaderAt and the size: a whole file, not a stream
+func extractZip(r io.ReaderAt, size int64, dir string, lim Limits) error {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return err
+	}
+	if len(zr.File) > lim.Files {
+		return fmt.Errorf("%w: %d files, more than %d", ErrTooLarge, len(zr.File), lim.Files)
+	}
+	root, err := os.OpenRoot(dir)
+	if err != nil {
+		return err
+	}
+	defer root.Close()
+
+	budget := lim.Bytes
+	for _, f := range zr.File {
+		mode := f.Mode()
+		if mode.IsDir() {
+			if err := root.MkdirAll(f.Name, 0o755); err != nil {
+				return fmt.Errorf("%w: %v", ErrUnsafe, err)
+			}
+			continue
+		}
+		if !mode.IsRegular() {
+			return fmt.Errorf("%w: %s is not a file or a directory", ErrUnsafe, f.Name)
+		}
+		// The index says how big each file is, but the index can lie:
+		// extractFile counts what's really there
+		if f.UncompressedSize64 > uint64(budget) {
+			return fmt.Errorf("%w: %s says it's %d bytes", ErrTooLarge, f.Name, f.UncompressedSize64)
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return err
+		}
+		n, err := extractFile(root, f.Name, rc, budget)
+		rc.Close()
+		if err != nil {
+			return err
+		}
+		budget -= n
+	}
+	return nil
+}
+
+// extractFile copies r to a new file at name, in root, and fails once
+// more than budget bytes come out of r
+func extractFile(root *os.Root, name string, r io.Reader, budget int64) (n int64, err error) {
+	if err := root.MkdirAll(path.Dir(name), 0o755); err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnsafe, err)
+	}
+	// O_EXCL: an archive with the same name twice mustn't overwrite a
+	// file it already wrote
+	f, err := root.OpenFile(name, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrUnsafe, err)
+	}
+	defer func() { err = errors.Join(err, f.Close()) }()
+
+	// One byte more than the budget tells "exactly the budget" apart
+	// from "over it"
+	n, err = io.Copy(f, io.LimitReader(r, budget+1))
+	if err != nil {
+		return n, err
+	}
+	if n > budget {
+		return n, fmt.Errorf("%w: %s", ErrTooLarge, name)
+	}
+	return n, nil
+}
+
+// gunzip decompresses r, and fails once more than limit bytes come out.
+// A few KB of gzip can hold gigabytes of zeros: without the limit,
+// io.ReadAll would try to hold them all
+func gunzip(r io.Reader, limit int64) ([]byte, error) {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gz.Close()
+
+	data, err := io.ReadAll(io.LimitReader(gz, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrTooLarge, limit)
+	}
+	return data, nil
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/rand"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing/fstest"
+	"time"
+)
+
+// project is a small tree of files, in memory, to archive
+var project = fstest.MapFS{
+	"README.md":         {Data: []byte(strings.Repeat("# Project\n\nA project with some files.\n", 50))},
+	"cmd/app/main.go":   {Data: []byte("package main\n\nfunc main() {}\n")},
+	"internal/store.go": {Data: []byte(strings.Repeat("// store keeps things\n", 200))},
+	"data/empty":        {Mode: fs.ModeDir | 0o755},
+}
+
+func main() {
+	fmt.Println("Compression and Archives: gzip, zip, and tar")
+	fmt.Println("============================================")
+	fmt.Println()
+
+	// Example 1: What compresses
+	fmt.Println("1. gzip on text, and on random bytes, at three levels:")
+	text := bytes.Repeat([]byte(`{"id":1,"name":"Ada Lovelace","email":"ada@example.com"},`), 20_000)
+	random := make([]byte, len(text))
+	rand.Read(random)
+	for _, in := range []struct {
+		name string
+		data []byte
+	}{{"JSON", text}, {"random", random}} {
+		for _, level := range []int{gzip.BestSpeed, gzip.DefaultCompression, gzip.BestCompression} {
+			var buf bytes.Buffer
+			start := time.Now()
+			gz, _ := gzip.NewWriterLevel(&buf, level)
+			gz.Write(in.data)
+			gz.Close()
+			fmt.Printf("   %-6s level %2d: %7d -> %7d bytes (%5.1f%%) in %v\n", in.name, level,
+				len(in.data), buf.Len(), 100*float64(buf.Len())/float64(len(in.data)), time.Since(start).Round(100*time.Microsecond))
+		}
+	}
+	fmt.Println()
+
+	// Example 2: A gzip middleware
+	fmt.Println("2. The Gzip middleware, with a minimum size of 1 KB:")
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /big", func(w http.ResponseWriter, r *http.Request) { w.Write(text[:100_000]) })
+	mux.HandleFunc("GET /small", func(w http.ResponseWriter, r *http.Request) { w.Write(text[:100]) })
+	mux.HandleFunc("GET /image", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "image/png")
+		w.Write(random[:100_000])
+	})
+	srv := httptest.NewServer(Gzip(1024)(mux))
+	defer srv.Close()
+
+	for _, req := range []struct{ path, accept string }{
+		{"/big", ""}, // the client's transport asks for gzip, and decompresses
+		{"/big", "gzip"},
+		{"/big", "gzip;q=0"},
+		{"/small", "gzip"},
+		{"/image", "gzip"},
+	} {
+		r, _ := http.NewRequest("GET", srv.URL+req.path, nil)
+		if req.accept != "" {
+			r.Header.Set("Accept-Encoding", req.accept) // asked for by hand: no decompressing
+		}
+		resp, err := http.DefaultClient.Do(r)
+		if err != nil {
+			log.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("   %-6s Accept-Encoding %-10q -> %-6q %6d bytes (uncompressed by the client: %t)\n",
+			req.path, req.accept, resp.Header.Get("Content-Encoding"), len(body), resp.Uncompressed)
+	}
+	fmt.Println()
+
+	// Example 3: A .tar.gz, written and extracted as a stream
+	fmt.Println("3. A .tar.gz through a pipe: written on one side, extracted on the other:")
+	dir, err := os.MkdirTemp("", "archives")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	pr, pw := io.Pipe()
+	go func() { pw.CloseWithError(writeTarGz(pw, project)) }()
+	counted := &countingReader{r: pr}
+	out := filepath.Join(dir, "from-tar")
+	os.Mkdir(out, 0o755)
+	if err := extractTarGz(counted, out, Limits{Files: 100, Bytes: 1 << 20}); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   %d compressed bytes in, and out came:\n", counted.n)
+	printTree(out)
+	fmt.Println()
+
+	// Example 4: A .zip
+	fmt.Println("4. A .zip, which needs the whole file to read:")
+	var zipped bytes.Buffer
+	if err := writeZip(&zipped, project); err != nil {
+		log.Fatal(err)
+	}
+	zr, _ := zip.NewReader(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()))
+	for _, f := range zr.File {
+		fmt.Printf("   %-18s %5d -> %4d bytes\n", f.Name, f.UncompressedSize64, f.CompressedSize64)
+	}
+	out = filepath.Join(dir, "from-zip")
+	os.Mkdir(out, 0o755)
+	if err := extractZip(bytes.NewReader(zipped.Bytes()), int64(zipped.Len()), out, Limits{Files: 100, Bytes: 1 << 20}); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   extracted %d bytes\n", treeSize(out))
+	fmt.Println()
+
+	// Example 5: Archives from someone else
+	fmt.Println("5. Hostile archives:")
+	bomb := gzipOf(bytes.NewReader(make([]byte, 100<<20))) // 100 MB of zeros
+	_, err = gunzip(bytes.NewReader(bomb), 10<<20)
+	fmt.Printf("   %d KB of gzip, read with a 10 MB limit: %v\n", len(bomb)>>10, err)
+
+	for _, evil := range []struct {
+		name string
+		h    tar.Header
+	}{
+		{"a path out of the directory", tar.Header{Name: "../../evil.sh", Typeflag: tar.TypeReg, Size: 4, Mode: 0o755}},
+		{"an absolute path", tar.Header{Name: "/etc/evil", Typeflag: tar.TypeReg, Size: 4, Mode: 0o644}},
+		{"a symlink", tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+	} {
+		target := filepath.Join(dir, "evil")
+		os.RemoveAll(target)
+		os.Mkdir(target, 0o755)
+		err := extractTarGz(bytes.NewReader(tarGzOf(evil.h, "evil")), target, Limits{Files: 10, Bytes: 1 << 20})
+		fmt.Printf("   %-27s %v\n", evil.name+":", err)
+	}
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// gzipOf compresses r into memory
+func gzipOf(r io.Reader) []byte {
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	io.Copy(gz, r)
+	gz.Close()
+	return buf.Bytes()
+}
+
+// tarGzOf is a .tar.gz with one entry, h, and data as its contents
+func tarGzOf(h tar.Header, data string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	tw.WriteHeader(&h)
+	if h.Typeflag == tar.TypeReg {
+		io.WriteString(tw, data[:h.Size])
+	}
+	tw.Close()
+	return gzipOf(&buf)
+}
+
+// printTree prints the files under dir, with their sizes
+func printTree(dir string) {
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || p == dir {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, p)
+		if d.IsDir() {
+			fmt.Printf("   %s/\n", filepath.ToSlash(rel))
+			return nil
+		}
+		info, _ := d.Info()
+		fmt.Printf("   %-18s %5d bytes\n", filepath.ToSlash(rel), info.Size())
+		return nil
+	})
+}
+
+// treeSize is the total size of the files under dir
+func treeSize(dir string) int64 {
+	var total int64
+	filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err == nil && d.Type().IsRegular() {
+			info, _ := d.Info()
+			total += info.Size()
+		}
+		return err
+	})
+	return total
+}
@@ -0,0 +1,366 @@
+package main
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestAccepts(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{"", false},
+		{"gzip", true},
+		{"GZIP", true},
+		{"deflate, gzip;q=0.5", true},
+		{"br, deflate", false},
+		{"gzip;q=0", false},
+		{"gzip; q=0.0, *", false}, // named, and refused: * doesn't override
+		{"*", true},
+		{"*;q=0", false},
+		{"gzip;q=x", false},
+	}
+	for _, tt := range tests {
+		if got := accepts(tt.header, "gzip"); got != tt.want {
+			t.Errorf("accepts(%q): want %t; got %t", tt.header, tt.want, got)
+		}
+	}
+}
+
+// serve runs h behind Gzip(1024), with an Accept-Encoding of accept
+func serve(h http.HandlerFunc, accept string) *httptest.ResponseRecorder {
+	r := httptest.NewRequest("GET", "/", nil)
+	if accept != "" {
+		r.Header.Set("Accept-Encoding", accept)
+	}
+	w := httptest.NewRecorder()
+	Gzip(1024)(h).ServeHTTP(w, r)
+	return w
+}
+
+func ungzip(t *testing.T, b []byte) string {
+	t.Helper()
+	gz, err := gzip.NewReader(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestGzipMiddleware(t *testing.T) {
+	big := strings.Repeat("hello, gzip\n", 1000)
+	write := func(s string) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Length", "999") // wrong once compressed
+			io.WriteString(w, s[:len(s)/2])         // in two writes, to
+			io.WriteString(w, s[len(s)/2:])         // cross the minimum size
+		}
+	}
+
+	w := serve(write(big), "gzip")
+	if w.Header().Get("Content-Encoding") != "gzip" || w.Header().Get("Content-Length") != "" {
+		t.Errorf("want gzip and no Content-Length; got %v", w.Header())
+	}
+	if got := ungzip(t, w.Body.Bytes()); got != big {
+		t.Errorf("want the body back; got %d bytes", len(got))
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") {
+		t.Errorf("want the type sniffed from the plain body; got %q", ct)
+	}
+	if w.Header().Get("Vary") != "Accept-Encoding" {
+		t.Errorf("want Vary: Accept-Encoding; got %v", w.Header())
+	}
+
+	for _, tc := range []struct {
+		name, accept, body string
+	}{
+		{"not accepted", "", big},
+		{"refused", "gzip;q=0", big},
+		{"too small", "gzip", "hello"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			w := serve(write(tc.body), tc.accept)
+			if w.Header().Get("Content-Encoding") != "" || w.Body.String() != tc.body {
+				t.Errorf("want the body as it is; got %v, %d bytes", w.Header(), w.Body.Len())
+			}
+		})
+	}
+}
+
+func TestGzipSkips(t *testing.T) {
+	big := bytes.Repeat([]byte{0}, 10_000)
+	tests := []struct {
+		name string
+		h    http.HandlerFunc
+		want string // the Content-Encoding
+	}{
+		{"an image", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/jpeg")
+			w.Write(big)
+		}, ""},
+		{"an SVG image, which is text", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "image/svg+xml")
+			w.Write(big)
+		}, "gzip"},
+		{"compressed already", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Encoding", "br")
+			w.Write(big)
+		}, "br"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serve(tt.h, "gzip, br")
+			if got := w.Header().Get("Content-Encoding"); got != tt.want {
+				t.Errorf("want Content-Encoding %q; got %q", tt.want, got)
+			}
+		})
+	}
+
+	w := serve(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) }, "gzip")
+	if w.Code != http.StatusNoContent || w.Header().Get("Content-Encoding") != "" || w.Body.Len() != 0 {
+		t.Errorf("want a plain 204; got %d, %v, %q", w.Code, w.Header(), w.Body)
+	}
+
+	w = serve(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		io.WriteString(w, "not found")
+	}, "gzip")
+	if w.Code != http.StatusNotFound || w.Body.String() != "not found" {
+		t.Errorf("want the status kept with a small body; got %d, %q", w.Code, w.Body)
+	}
+}
+
+func TestGzipFlush(t *testing.T) {
+	w := serve(func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "event 1\n")
+		if err := http.NewResponseController(w).Flush(); err != nil {
+			t.Error(err)
+		}
+		io.WriteString(w, "event 2\n")
+	}, "gzip")
+
+	if !w.Flushed || w.Header().Get("Content-Encoding") != "gzip" {
+		t.Errorf("want a flushed, compressed stream; got flushed=%t, %v", w.Flushed, w.Header())
+	}
+	if got := ungzip(t, w.Body.Bytes()); got != "event 1\nevent 2\n" {
+		t.Errorf("want both events; got %q", got)
+	}
+}
+
+// TestGzipPanic checks that a handler that panics before writing leaves
+// the response alone, for a recovering middleware to send a 500
+func TestGzipPanic(t *testing.T) {
+	recoverer := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				if recover() != nil {
+					http.Error(w, "internal error", http.StatusInternalServerError)
+				}
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+	h := recoverer(Gzip(0)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})))
+	r := httptest.NewRequest("GET", "/", nil)
+	r.Header.Set("Accept-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Content-Encoding") != "" {
+		t.Errorf("want a plain 500; got %d, %v", w.Code, w.Header())
+	}
+}
+
+// sameTree checks that dir holds exactly the files in project
+func sameTree(t *testing.T, dir string) {
+	t.Helper()
+	got := os.DirFS(dir)
+	fs.WalkDir(project, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			t.Fatal(err)
+		}
+		info, err := fs.Stat(got, p)
+		if err != nil {
+			t.Errorf("%s: %v", p, err)
+			return nil
+		}
+		if info.IsDir() != d.IsDir() {
+			t.Errorf("%s: want a directory: %t", p, d.IsDir())
+		}
+		if !d.IsDir() {
+			want, _ := fs.ReadFile(project, p)
+			data, _ := fs.ReadFile(got, p)
+			if !bytes.Equal(data, want) {
+				t.Errorf("%s: want %d bytes; got %d", p, len(want), len(data))
+			}
+		}
+		return nil
+	})
+	if want, got := treeSize(dir), int64(6329); want != got {
+		t.Errorf("want %d bytes extracted; got %d", want, got)
+	}
+}
+
+var roomy = Limits{Files: 100, Bytes: 1 << 20}
+
+func TestTarGzRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeTarGz(&buf, project); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := extractTarGz(&buf, dir, roomy); err != nil {
+		t.Fatal(err)
+	}
+	sameTree(t, dir)
+}
+
+func TestZipRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := writeZip(&buf, project); err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := extractZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), dir, roomy); err != nil {
+		t.Fatal(err)
+	}
+	sameTree(t, dir)
+}
+
+func TestExtractLimits(t *testing.T) {
+	var buf bytes.Buffer
+	writeTarGz(&buf, project)
+	tgz := buf.Bytes()
+	buf.Reset()
+	writeZip(&buf, project)
+	zipped := buf.Bytes()
+
+	for _, lim := range []Limits{
+		{Files: 3, Bytes: 1 << 20},
+		{Files: 100, Bytes: 6328}, // a byte short
+	} {
+		err := extractTarGz(bytes.NewReader(tgz), t.TempDir(), lim)
+		if !errors.Is(err, ErrTooLarge) {
+			t.Errorf("tar, %+v: want ErrTooLarge; got %v", lim, err)
+		}
+		err = extractZip(bytes.NewReader(zipped), int64(len(zipped)), t.TempDir(), lim)
+		if !errors.Is(err, ErrTooLarge) {
+			t.Errorf("zip, %+v: want ErrTooLarge; got %v", lim, err)
+		}
+	}
+
+	exact := Limits{Files: 100, Bytes: 6329}
+	if err := extractTarGz(bytes.NewReader(tgz), t.TempDir(), exact); err != nil {
+		t.Errorf("want exactly the limit to fit; got %v", err)
+	}
+}
+
+func TestExtractTarUnsafe(t *testing.T) {
+	tests := []struct {
+		name string
+		h    tar.Header
+	}{
+		{"parent", tar.Header{Name: "../evil", Typeflag: tar.TypeReg, Size: 4}},
+		{"deep parent", tar.Header{Name: "a/../../evil", Typeflag: tar.TypeReg, Size: 4}},
+		{"absolute", tar.Header{Name: "/tmp/evil", Typeflag: tar.TypeReg, Size: 4}},
+		{"directory outside", tar.Header{Name: "../evildir/", Typeflag: tar.TypeDir}},
+		{"symlink", tar.Header{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "/etc/passwd"}},
+		{"hard link", tar.Header{Name: "link", Typeflag: tar.TypeLink, Linkname: "/etc/passwd"}},
+		{"device", tar.Header{Name: "dev", Typeflag: tar.TypeChar}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			parent := t.TempDir()
+			dir := filepath.Join(parent, "out")
+			os.Mkdir(dir, 0o755)
+
+			err := extractTarGz(bytes.NewReader(tarGzOf(tt.h, "evil")), dir, roomy)
+			if !errors.Is(err, ErrUnsafe) {
+				t.Errorf("want ErrUnsafe; got %v", err)
+			}
+			for _, name := range []string{"evil", "evildir"} {
+				if _, err := os.Stat(filepath.Join(parent, name)); err == nil {
+					t.Errorf("%s was written outside the directory", name)
+				}
+			}
+		})
+	}
+
+	// The same name twice must not overwrite the first
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, data := range []string{"first", "second"} {
+		tw.WriteHeader(&tar.Header{Name: "a", Typeflag: tar.TypeReg, Size: int64(len(data)), Mode: 0o644})
+		io.WriteString(tw, data)
+	}
+	tw.Close()
+	dir := t.TempDir()
+	if err := extractTarGz(bytes.NewReader(gzipOf(&buf)), dir, roomy); !errors.Is(err, ErrUnsafe) {
+		t.Errorf("want ErrUnsafe for a name twice; got %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(dir, "a")); string(data) != "first" {
+		t.Errorf("want the first file kept; got %q", data)
+	}
+}
+
+func TestExtractZipUnsafe(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	w, _ := zw.Create("../evil")
+	io.WriteString(w, "evil")
+	zw.Close()
+
+	parent := t.TempDir()
+	dir := filepath.Join(parent, "out")
+	os.Mkdir(dir, 0o755)
+	err := extractZip(bytes.NewReader(buf.Bytes()), int64(buf.Len()), dir, roomy)
+	if !errors.Is(err, ErrUnsafe) {
+		t.Errorf("want ErrUnsafe; got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(parent, "evil")); err == nil {
+		t.Error("evil was written outside the directory")
+	}
+}
+
+func TestGunzip(t *testing.T) {
+	data := gzipOf(strings.NewReader("0123456789"))
+	if got, err := gunzip(bytes.NewReader(data), 10); err != nil || string(got) != "0123456789" {
+		t.Errorf("want exactly the limit to fit; got %q, %v", got, err)
+	}
+	if _, err := gunzip(bytes.NewReader(data), 9); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("want ErrTooLarge a byte over; got %v", err)
+	}
+
+	bomb := gzipOf(bytes.NewReader(make([]byte, 16<<20)))
+	if len(bomb) > 64<<10 {
+		t.Fatalf("want a bomb of a few KB; got %d bytes", len(bomb))
+	}
+	if _, err := gunzip(bytes.NewReader(bomb), 1<<20); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("want ErrTooLarge; got %v", err)
+	}
+
+	if _, err := gunzip(strings.NewReader("not gzip"), 1<<20); err == nil {
+		t.Error("want an error for input that isn't gzip")
+	}
+	if _, err := gunzip(bytes.NewReader(data[:len(data)-4]), 1<<20); err == nil {
+		t.Error("want an error for gzip that's cut short")
+	}
+}
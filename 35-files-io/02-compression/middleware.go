@@ -0,0 +1,179 @@
+package main
+
+import (
+	"compress/gzip"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Gzip compresses responses of at least minSize bytes, for clients that
+// accept gzip. It's the Gzip of the middleware lesson, with what a real
+// server adds: pooled writers, a size below which compressing isn't worth
+// it, q=0 in Accept-Encoding, and streaming with Flush
+func Gzip(minSize int) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// Caches must store the two versions separately
+			w.Header().Add("Vary", "Accept-Encoding")
+
+			if !accepts(r.Header.Get("Accept-Encoding"), "gzip") {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gw := &gzipWriter{ResponseWriter: w, minSize: minSize, status: http.StatusOK}
+			next.ServeHTTP(gw, r)
+			// Not deferred: if next panics before writing, nothing has
+			// been sent, and a recovering middleware can still send a 500
+			gw.close()
+		})
+	}
+}
+
+// accepts reports whether an Accept-Encoding header allows coding.
+// "gzip;q=0" is a refusal, and "*" accepts anything not named
+func accepts(header, coding string) bool {
+	star := false
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		ok := true
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			v, err := strconv.ParseFloat(q, 64)
+			ok = err == nil && v > 0
+		}
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case coding:
+			return ok
+		case "*":
+			star = ok
+		}
+	}
+	return star
+}
+
+// gzipWriters are reused: each one holds hundreds of KB of compression
+// state, too much to allocate for every response
+var gzipWriters = sync.Pool{
+	New: func() any { return gzip.NewWriter(nil) },
+}
+
+// gzipWriter holds back the first minSize bytes of the body. If the body
+// ends first, it's sent as it is; otherwise it's compressed
+type gzipWriter struct {
+	http.ResponseWriter
+	minSize int
+
+	status  int
+	buf     []byte
+	started bool         // the header is sent
+	gz      *gzip.Writer // nil for a plain body
+}
+
+func (w *gzipWriter) WriteHeader(status int) {
+	if w.started {
+		w.ResponseWriter.WriteHeader(status) // let net/http log it
+		return
+	}
+	w.status = status
+	// 1xx, 204, and 304 responses have no body to compress
+	if status < 200 || status == http.StatusNoContent || status == http.StatusNotModified {
+		w.start(false)
+	}
+}
+
+func (w *gzipWriter) Write(p []byte) (int, error) {
+	if !w.started {
+		w.buf = append(w.buf, p...)
+		if len(w.buf) < w.minSize {
+			return len(p), nil
+		}
+		if err := w.start(true); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+	if w.gz != nil {
+		return w.gz.Write(p)
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// start sends the header, and what's been held back, compressed or not
+func (w *gzipWriter) start(compress bool) error {
+	w.started = true
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		// net/http would sniff the type from compressed bytes, so
+		// sniff it here, from the plain ones
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	if compress && h.Get("Content-Encoding") == "" && compressible(h.Get("Content-Type")) {
+		h.Del("Content-Length") // the handler's is the uncompressed length
+		h.Set("Content-Encoding", "gzip")
+		w.gz = gzipWriters.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	buf := w.buf
+	w.buf = nil
+	if len(buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.gz != nil {
+		_, err = w.gz.Write(buf)
+	} else {
+		_, err = w.ResponseWriter.Write(buf)
+	}
+	return err
+}
+
+// compressible is false for types that are compressed already, where
+// gzip costs time and saves nothing
+func compressible(contentType string) bool {
+	switch {
+	case strings.HasPrefix(contentType, "image/") && !strings.HasPrefix(contentType, "image/svg"),
+		strings.HasPrefix(contentType, "video/"),
+		strings.HasPrefix(contentType, "audio/"),
+		strings.HasPrefix(contentType, "application/zip"),
+		strings.HasPrefix(contentType, "application/gzip"),
+		strings.HasPrefix(contentType, "application/x-gzip"):
+		return false
+	}
+	return true
+}
+
+// Flush sends what's been written so far. A handler that flushes is
+// streaming, so the body is compressed whatever its size, and the gzip
+// writer flushes its own buffer first
+func (w *gzipWriter) Flush() {
+	if !w.started {
+		w.start(true)
+	}
+	if w.gz != nil {
+		w.gz.Flush()
+	}
+	http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Unwrap lets http.ResponseController reach the writer underneath, for
+// the methods the wrapper hides
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close sends a body smaller than minSize as it is, or writes the gzip
+// footer, and returns the writer to the pool
+func (w *gzipWriter) close() {
+	if !w.started {
+		w.start(false)
+	}
+	if w.gz != nil {
+		w.gz.Close()
+		w.gz.Reset(nil) // don't keep the response alive from the pool
+		gzipWriters.Put(w.gz)
+		w.gz = nil
+	}
+}
@@ -0,0 +1,213 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/workerpool"
+)
+
+// File is a file that's big enough
+type File struct {
+	Path string
+	Size int64
+}
+
+// Options say what to look for, and where not to
+type Options struct {
+	MinSize     int64
+	Skip        []string // names of directories not to walk into, like .git
+	FollowLinks bool     // walk into linked directories, and count linked files
+}
+
+// Result is what a search found. An unreadable directory or file doesn't
+// stop a search: its error is kept, and the search goes on
+type Result struct {
+	Files []File // the biggest first
+	Errs  []error
+}
+
+func (r *Result) sort() {
+	slices.SortFunc(r.Files, func(a, b File) int {
+		return cmp.Or(cmp.Compare(b.Size, a.Size), strings.Compare(a.Path, b.Path))
+	})
+}
+
+// walk walks root with filepath.WalkDir, and calls file for every regular
+// file: for a link to a file too, if opt.FollowLinks. It's the part both
+// searches share; what they do with each file is what differs
+func walk(root string, opt Options, file func(path string, link bool), fail func(error)) {
+	guard := newLinkGuard(root)
+
+	// walkDir walks dir, and reports its paths as if it were at as: a
+	// linked directory is walked where it really is, and reported where
+	// the link is
+	var walkDir func(dir, as string)
+	walkDir = func(dir, as string) {
+		filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if rel, _ := filepath.Rel(dir, path); as != dir {
+				path = filepath.Join(as, rel)
+			}
+			if err != nil {
+				fail(err) // and go on: a nil return skips only this directory
+				return nil
+			}
+			switch {
+			case d.IsDir():
+				if path != as && slices.Contains(opt.Skip, d.Name()) {
+					return fs.SkipDir
+				}
+			case d.Type()&fs.ModeSymlink != 0:
+				if !opt.FollowLinks {
+					return nil
+				}
+				// WalkDir never follows a link, not even one it's given
+				// as its root. os.Stat and EvalSymlinks do
+				info, err := os.Stat(path)
+				if err != nil {
+					fail(err) // a link to nothing
+					return nil
+				}
+				switch {
+				case info.IsDir():
+					if slices.Contains(opt.Skip, d.Name()) {
+						return nil
+					}
+					if target, ok := guard.enter(path); ok {
+						walkDir(target, path)
+					}
+				case info.Mode().IsRegular():
+					file(path, true)
+				}
+			case d.Type().IsRegular():
+				file(path, false)
+			}
+			return nil
+		})
+	}
+	walkDir(root, root)
+}
+
+// statDelay is added to every stat, to act like a slow disk or a network
+// file system, where a stat waits far longer than on a local SSD
+var statDelay time.Duration
+
+// size is the size of the file at path, or of the file a link points to
+func size(path string, link bool) (int64, error) {
+	time.Sleep(statDelay)
+	stat := os.Lstat
+	if link {
+		stat = os.Stat
+	}
+	info, err := stat(path)
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+// findSequential does everything in one goroutine: it reads each
+// directory, then stats each file in it, one after the other
+func findSequential(root string, opt Options) Result {
+	var res Result
+	walk(root, opt, func(path string, link bool) {
+		n, err := size(path, link)
+		if err != nil {
+			res.Errs = append(res.Errs, err)
+			return
+		}
+		if n >= opt.MinSize {
+			res.Files = append(res.Files, File{path, n})
+		}
+	}, func(err error) {
+		res.Errs = append(res.Errs, err)
+	})
+	res.sort()
+	return res
+}
+
+// statJob is a file for a worker to stat
+type statJob struct {
+	path string
+	link bool
+}
+
+// findPool walks in one goroutine, as findSequential does, and hands
+// every file to a pool of workers to stat. A stat is a system call that
+// waits for the disk; with workers, several can wait at once
+func findPool(root string, opt Options, workers int) Result {
+	pool := workerpool.New(context.Background(), workers, func(_ context.Context, j statJob) (int64, error) {
+		return size(j.path, j.link)
+	})
+
+	var (
+		mu   sync.Mutex
+		errs []error
+	)
+	go func() {
+		walk(root, opt, func(path string, link bool) {
+			pool.Submit(statJob{path, link})
+		}, func(err error) {
+			mu.Lock()
+			errs = append(errs, err)
+			mu.Unlock()
+		})
+		pool.Drain()
+	}()
+
+	var res Result
+	for r := range pool.Results() {
+		switch {
+		case r.Err != nil:
+			res.Errs = append(res.Errs, r.Err)
+		case r.Value >= opt.MinSize:
+			res.Files = append(res.Files, File{r.Input.path, r.Value})
+		}
+	}
+	// Results is closed after Drain, so the walk is over
+	mu.Lock()
+	res.Errs = append(res.Errs, errs...)
+	mu.Unlock()
+	res.sort()
+	return res
+}
+
+// linkGuard stops a walk from following links in a circle. It remembers
+// where every linked directory really is, and refuses one it has walked,
+// or one that contains the link: a link to a parent
+type linkGuard struct {
+	seen map[string]bool
+}
+
+func newLinkGuard(root string) *linkGuard {
+	g := &linkGuard{seen: make(map[string]bool)}
+	if real, err := filepath.EvalSymlinks(root); err == nil {
+		g.seen[real] = true
+	}
+	return g
+}
+
+// enter reports whether to walk the directory that the link at path
+// points to, and where that directory really is
+func (g *linkGuard) enter(path string) (string, bool) {
+	target, err := filepath.EvalSymlinks(path)
+	if err != nil || g.seen[target] {
+		return "", false
+	}
+	here, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", false
+	}
+	if rel, err := filepath.Rel(target, here); err == nil && filepath.IsLocal(rel) {
+		return "", false // the link is inside its own target
+	}
+	g.seen[target] = true
+	return target, true
+}
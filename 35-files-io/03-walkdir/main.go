@@ -0,0 +1,199 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"time"
+)
+
+func main() {
+	root := flag.String("root", "", "search this directory, instead of running the examples")
+	minMB := flag.Int64("min", 100, "with -root, the smallest file to report, in MB")
+	follow := flag.Bool("follow", false, "with -root, follow symbolic links")
+	workers := flag.Int("workers", 4*runtime.GOMAXPROCS(0), "the number of stat workers")
+	flag.Parse()
+
+	if *root != "" {
+		res := findPool(*root, Options{MinSize: *minMB << 20, Skip: []string{".git"}, FollowLinks: *follow}, *workers)
+		for _, f := range res.Files {
+			fmt.Printf("%8s  %s\n", human(f.Size), f.Path)
+		}
+		for _, err := range res.Errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		return
+	}
+
+	fmt.Println("Walking Directory Trees: filepath.WalkDir")
+	fmt.Println("=========================================")
+	fmt.Println()
+
+	dir, err := os.MkdirTemp("", "walkdir")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// Example 1: WalkDir, in order
+	fmt.Println("1. A small tree, as WalkDir visits it:")
+	small := filepath.Join(dir, "small")
+	must(makeSmall(small))
+	filepath.WalkDir(small, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(small, path)
+		fmt.Printf("   %-10v %s\n", d.Type(), filepath.ToSlash(rel))
+		return nil
+	})
+	fmt.Println()
+
+	// Example 2: Skipping, and links
+	fmt.Println("2. Files of 1 KB or more, skipping .git, with and without links:")
+	for _, follow := range []bool{false, true} {
+		res := findSequential(small, Options{MinSize: 1 << 10, Skip: []string{".git"}, FollowLinks: follow})
+		fmt.Printf("   FollowLinks=%t:\n", follow)
+		for _, f := range res.Files {
+			rel, _ := filepath.Rel(small, f.Path)
+			fmt.Printf("      %-22s %s\n", filepath.ToSlash(rel), human(f.Size))
+		}
+		for _, err := range res.Errs {
+			fmt.Printf("      error: %v\n", err)
+		}
+	}
+	fmt.Println()
+
+	// Example 3: Sequential vs a pool of workers
+	big := filepath.Join(dir, "big")
+	n, err := makeBig(big, 300, 60)
+	must(err)
+	fmt.Printf("3. %d files in 300 directories:\n", n)
+	opt := Options{MinSize: 10 << 20, Skip: []string{".git"}}
+	start := time.Now()
+	res := findSequential(big, opt)
+	fmt.Printf("   %-12s %3d found in %v\n", "sequential", len(res.Files), time.Since(start).Round(time.Millisecond))
+	for _, w := range []int{2, 8, 32} {
+		start = time.Now()
+		res = findPool(big, opt, w)
+		fmt.Printf("   %-12s %3d found in %v\n", strconv.Itoa(w)+" workers", len(res.Files), time.Since(start).Round(time.Millisecond))
+	}
+	fmt.Println("   the biggest:")
+	for _, f := range res.Files[:3] {
+		rel, _ := filepath.Rel(big, f.Path)
+		fmt.Printf("      %-22s %s\n", filepath.ToSlash(rel), human(f.Size))
+	}
+	fmt.Println()
+
+	// Example 4: The same, when a stat has to wait
+	// A sleep this short takes about a millisecond on many systems, so
+	// this tree is smaller
+	medium := filepath.Join(dir, "medium")
+	n, err = makeBig(medium, 40, 50)
+	must(err)
+	statDelay = 100 * time.Microsecond
+	fmt.Printf("4. %d files, with %v added to every stat, like a network file system:\n", n, statDelay)
+	start = time.Now()
+	res = findSequential(medium, opt)
+	fmt.Printf("   %-12s %3d found in %v\n", "sequential", len(res.Files), time.Since(start).Round(time.Millisecond))
+	for _, w := range []int{2, 8, 32} {
+		start = time.Now()
+		res = findPool(medium, opt, w)
+		fmt.Printf("   %-12s %3d found in %v\n", strconv.Itoa(w)+" workers", len(res.Files), time.Since(start).Round(time.Millisecond))
+	}
+}
+
+// makeSmall makes a tree with a skipped directory, and three links: to a
+// file, to a directory, and to the tree's own root
+func makeSmall(dir string) error {
+	files := map[string]int64{
+		"README.md":         300,
+		"data/users.csv":    40 << 10,
+		"data/raw/dump.bin": 3 << 20,
+		".git/objects/pack": 9 << 20,
+		"src/main.go":       2 << 10,
+	}
+	for name, size := range files {
+		if err := makeFile(filepath.Join(dir, name), size); err != nil {
+			return err
+		}
+	}
+	outside := filepath.Join(filepath.Dir(dir), "outside")
+	if err := makeFile(filepath.Join(outside, "shared.db"), 5<<20); err != nil {
+		return err
+	}
+	links := map[string]string{
+		"latest.bin":  "data/raw/dump.bin",
+		"shared":      "../outside",
+		"src/loop":    "..", // back to the root: a circle
+		"src/missing": "nowhere",
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(dir, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// makeBig makes dirs directories of perDir files each, with random sizes:
+// mostly small, a few big. It reports how many files it made
+func makeBig(dir string, dirs, perDir int) (int, error) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	n := 0
+	for d := range dirs {
+		sub := filepath.Join(dir, fmt.Sprintf("%02d", d%30), fmt.Sprintf("dir%03d", d))
+		for f := range perDir {
+			size := rng.Int64N(64 << 10)
+			if rng.IntN(500) == 0 {
+				size = rng.Int64N(1 << 30) // a big one
+			}
+			if err := makeFile(filepath.Join(sub, fmt.Sprintf("file%02d", f)), size); err != nil {
+				return n, err
+			}
+			n++
+		}
+	}
+	return n, nil
+}
+
+// makeFile makes a file of size bytes, and its directory. Truncate makes
+// it sparse: it has the size, but takes no space on most file systems
+func makeFile(path string, size int64) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.Truncate(size); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+func human(n int64) string {
+	switch {
+	case n >= 1<<30:
+		return fmt.Sprintf("%.1f GB", float64(n)/(1<<30))
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	}
+	return fmt.Sprintf("%d B", n)
+}
+
+func must(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// finders are the two searches, which must agree on everything
+var finders = []struct {
+	name string
+	find func(root string, opt Options) Result
+}{
+	{"sequential", findSequential},
+	{"pool", func(root string, opt Options) Result { return findPool(root, opt, 4) }},
+}
+
+// relPaths returns the paths in res, relative to root
+func relPaths(t *testing.T, root string, res Result) []string {
+	t.Helper()
+	var paths []string
+	for _, f := range res.Files {
+		rel, err := filepath.Rel(root, f.Path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	return paths
+}
+
+func TestFind(t *testing.T) {
+	root := filepath.Join(t.TempDir(), "small")
+	if err := makeSmall(root); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		opt  Options
+		want []string // biggest first
+		errs int
+	}{
+		{"everything", Options{}, []string{".git/objects/pack", "data/raw/dump.bin", "data/users.csv", "src/main.go", "README.md"}, 0},
+		{"skip .git", Options{Skip: []string{".git"}}, []string{"data/raw/dump.bin", "data/users.csv", "src/main.go", "README.md"}, 0},
+		{"min size", Options{MinSize: 1 << 20, Skip: []string{".git"}}, []string{"data/raw/dump.bin"}, 0},
+		{"skip a nested name", Options{MinSize: 1 << 20, Skip: []string{"raw", ".git"}}, nil, 0},
+		{"follow links", Options{MinSize: 1 << 10, Skip: []string{".git"}, FollowLinks: true},
+			[]string{"shared/shared.db", "data/raw/dump.bin", "latest.bin", "data/users.csv", "src/main.go"}, 1}, // src/missing
+		{"skip a linked directory by name", Options{MinSize: 1 << 10, Skip: []string{".git", "shared"}, FollowLinks: true},
+			[]string{"data/raw/dump.bin", "latest.bin", "data/users.csv", "src/main.go"}, 1},
+	}
+	for _, tt := range tests {
+		for _, f := range finders {
+			t.Run(tt.name+"/"+f.name, func(t *testing.T) {
+				res := f.find(root, tt.opt)
+				if got := relPaths(t, root, res); !slices.Equal(got, tt.want) {
+					t.Errorf("want %q; got %q", tt.want, got)
+				}
+				if len(res.Errs) != tt.errs {
+					t.Errorf("want %d errors; got %v", tt.errs, res.Errs)
+				}
+			})
+		}
+	}
+}
+
+func TestLinkCycles(t *testing.T) {
+	root := t.TempDir()
+	if err := makeFile(filepath.Join(root, "a", "b", "file"), 10); err != nil {
+		t.Fatal(err)
+	}
+	links := map[string]string{
+		"a/b/up":   "..",    // a parent
+		"a/b/self": ".",     // itself
+		"a/b/root": "../..", // the root
+		"again":    "a",     // a second name for a: walked once,
+		"again2":   "a",     // but not twice
+	}
+	for name, target := range links {
+		if err := os.Symlink(target, filepath.Join(root, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	want := []string{"a/b/file", "again/b/file"}
+	for _, f := range finders {
+		res := f.find(root, Options{FollowLinks: true})
+		if got := relPaths(t, root, res); !slices.Equal(got, want) {
+			t.Errorf("%s: want %q; got %q", f.name, want, got)
+		}
+	}
+}
+
+func TestUnreadable(t *testing.T) {
+	if os.Getuid() == 0 {
+		t.Skip("root can read any directory")
+	}
+	root := t.TempDir()
+	makeFile(filepath.Join(root, "ok", "file"), 10)
+	makeFile(filepath.Join(root, "locked", "file"), 10)
+	os.Chmod(filepath.Join(root, "locked"), 0)
+	t.Cleanup(func() { os.Chmod(filepath.Join(root, "locked"), 0o755) })
+
+	for _, f := range finders {
+		res := f.find(root, Options{})
+		if got := relPaths(t, root, res); !slices.Equal(got, []string{"ok/file"}) || len(res.Errs) != 1 {
+			t.Errorf("%s: want ok/file and one error; got %q, %v", f.name, got, res.Errs)
+		}
+	}
+}
+
+func TestMissingRoot(t *testing.T) {
+	for _, f := range finders {
+		res := f.find(filepath.Join(t.TempDir(), "missing"), Options{})
+		if len(res.Files) != 0 || len(res.Errs) != 1 || !os.IsNotExist(res.Errs[0]) {
+			t.Errorf("%s: want one not-exist error; got %+v", f.name, res)
+		}
+	}
+}
+
+func TestFindersAgree(t *testing.T) {
+	root := t.TempDir()
+	if _, err := makeBig(root, 20, 25); err != nil {
+		t.Fatal(err)
+	}
+	want := findSequential(root, Options{})
+	if len(want.Files) != 500 {
+		t.Fatalf("want 500 files; got %d", len(want.Files))
+	}
+	for _, w := range []int{1, 3, 16} {
+		got := findPool(root, Options{}, w)
+		if !slices.Equal(got.Files, want.Files) {
+			t.Errorf("%d workers: want the same %d files; got %d", w, len(want.Files), len(got.Files))
+		}
+	}
+}
+
+func BenchmarkFind(b *testing.B) {
+	root := b.TempDir()
+	if _, err := makeBig(root, 50, 40); err != nil {
+		b.Fatal(err)
+	}
+	opt := Options{MinSize: 1 << 20}
+	b.Run("sequential", func(b *testing.B) {
+		for b.Loop() {
+			findSequential(root, opt)
+		}
+	})
+	for _, w := range []int{4, 32} {
+		b.Run(fmt.Sprintf("pool-%d", w), func(b *testing.B) {
+			for b.Loop() {
+				findPool(root, opt, w)
+			}
+		})
+	}
+}
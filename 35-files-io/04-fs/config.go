@@ -0,0 +1,57 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+)
+
+// Config is the site's settings
+type Config struct {
+	Title   string `json:"title"`
+	Author  string `json:"author"`
+	PerPage int    `json:"per_page"`
+}
+
+// LoadConfig reads config.json from fsys, then every file in config.d in
+// name order, each overriding the fields it sets. A deployment adds a file
+// instead of editing the one the program ships with.
+//
+// It never touches the disk itself: fsys may be a directory, files
+// embedded in the program, or a map in a test
+func LoadConfig(fsys fs.FS) (Config, error) {
+	cfg := Config{PerPage: 10}
+
+	// Not fs.Glob: it ignores errors reading a directory, and an
+	// unreadable config.d would quietly drop every override
+	names := []string{"config.json"}
+	entries, err := fs.ReadDir(fsys, "config.d")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return Config{}, err
+	}
+	for _, e := range entries { // sorted by name
+		if e.Type().IsRegular() && path.Ext(e.Name()) == ".json" {
+			names = append(names, "config.d/"+e.Name())
+		}
+	}
+	for _, name := range names {
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return Config{}, err // an *fs.PathError: it names the file
+		}
+		// Unmarshal sets only the fields the file has, and keeps the rest
+		if err := json.Unmarshal(data, &cfg); err != nil {
+			return Config{}, fmt.Errorf("%s: %w", name, err)
+		}
+	}
+
+	switch {
+	case cfg.Title == "":
+		return Config{}, errors.New("config: a title is required")
+	case cfg.PerPage < 1:
+		return Config{}, fmt.Errorf("config: per_page is %d; want 1 or more", cfg.PerPage)
+	}
+	return cfg, nil
+}
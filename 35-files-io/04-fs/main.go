@@ -0,0 +1,157 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing/fstest"
+)
+
+func main() {
+	fmt.Println("The fs.FS Interface: Code That Doesn't Know Where Files Are")
+	fmt.Println("===========================================================")
+	fmt.Println()
+
+	// Example 1: One loader, three file systems
+	fmt.Println("1. LoadConfig, on three file systems:")
+	root, err := os.OpenRoot("site")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer root.Close()
+	for _, f := range []struct {
+		name string
+		fsys fs.FS
+	}{
+		{`os.DirFS("site")`, os.DirFS("site")},
+		{`root.FS()`, root.FS()},
+		{`fstest.MapFS`, memSite},
+	} {
+		cfg, err := LoadConfig(f.fsys)
+		fmt.Printf("   %-18s %+v %v\n", f.name, cfg, errOrNil(err))
+	}
+	fmt.Println()
+
+	// Example 2: Names in an fs.FS
+	fmt.Println("2. What an fs.FS accepts as a name:")
+	for _, name := range []string{"notes/paths.txt", ".", "/notes/paths.txt", "notes/../config.json", `notes\paths.txt`, "notes/"} {
+		_, err := fs.Stat(os.DirFS("site"), name)
+		fmt.Printf("   %-22s ValidPath=%-5t %v\n", name, fs.ValidPath(name), errOrNil(err))
+	}
+	fmt.Println()
+
+	// Example 3: Templates from a map
+	fmt.Println("3. A page rendered from templates in an fstest.MapFS:")
+	site, err := NewSite(memSite)
+	if err != nil {
+		log.Fatal(err)
+	}
+	n, err := site.Note("hello")
+	if err != nil {
+		log.Fatal(err)
+	}
+	var page strings.Builder
+	site.Render(&page, "note.html", struct {
+		Config
+		Note
+	}{site.cfg, n})
+	for line := range strings.Lines(page.String()) {
+		fmt.Print("   ", line)
+	}
+	fmt.Println()
+
+	// Example 4: Serving a directory
+	fmt.Println(`4. Serving os.DirFS("site"):`)
+	site, err = NewSite(os.DirFS("site"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	srv := httptest.NewServer(site.Handler())
+	defer srv.Close()
+	for _, path := range []string{"/", "/notes/paths", "/notes/nope", "/notes/..%2Fconfig", "/static/style.css", "/static/..%2Fconfig.json"} {
+		resp, err := http.Get(srv.URL + path)
+		if err != nil {
+			log.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		fmt.Printf("   GET %-25s %d %-25s %4d bytes\n", path, resp.StatusCode, resp.Header.Get("Content-Type"), len(body))
+	}
+	fmt.Println()
+
+	// Example 5: Errors, and checking a file system
+	fmt.Println("5. Error paths, without touching the disk:")
+	broken := []struct {
+		name string
+		fsys fs.FS
+	}{
+		{"no config.json", fstest.MapFS{}},
+		{"bad JSON", fstest.MapFS{"config.json": {Data: []byte(`{"title": }`)}}},
+		{"no title", fstest.MapFS{"config.json": {Data: []byte(`{"author": "Gopher"}`)}}},
+		{"unreadable config.d", failFS{memSite, "config.d", fs.ErrPermission}},
+		{"broken template", withFile(memSite, "templates/note.html", `{{template "head" .Config}`)},
+	}
+	for _, b := range broken {
+		_, err := NewSite(b.fsys)
+		fmt.Printf("   %-20s %v\n", b.name+":", err)
+	}
+	err = fstest.TestFS(os.DirFS("site"), "config.json", "templates/index.html", "templates/note.html", "static/style.css")
+	fmt.Printf("   fstest.TestFS(site): %v\n", errOrNil(err))
+}
+
+// memSite is a whole site in memory: the files NewSite needs, and a
+// config.d file that overrides the title
+var memSite = fstest.MapFS{
+	"config.json":            {Data: []byte(`{"title": "Notes", "author": "Gopher", "per_page": 2}`)},
+	"config.d/50-local.json": {Data: []byte(`{"title": "Notes (local)"}`)},
+	"templates/base.html": {Data: []byte(
+		`{{define "head"}}<title>{{.Title}}</title>` + "\n" + `{{end}}{{define "foot"}}<footer>{{.Author}}</footer>{{end}}`)},
+	"templates/index.html": {Data: []byte(`{{template "head" .}}{{range .Notes}}<a href="/notes/{{.Name}}">{{.Title}}</a>` + "\n" + `{{end}}`)},
+	"templates/note.html":  {Data: []byte(`{{template "head" .Config}}<h1>{{.Note.Title}}</h1>` + "\n" + `<p>{{.Body}}</p>` + "\n" + `{{template "foot" .Config}}` + "\n")},
+	"notes/hello.txt":      {Data: []byte("Hello, <fs.FS>\nThis note was never on a disk.\n")},
+	"static/style.css":     {Data: []byte("body { margin: 2em; }\n")},
+}
+
+// withFile is fsys with one file added or replaced. A MapFS is a map:
+// copying it is cheap, and the original is left alone
+func withFile(fsys fstest.MapFS, name, data string) fstest.MapFS {
+	m := make(fstest.MapFS, len(fsys)+1)
+	for k, v := range fsys {
+		m[k] = v
+	}
+	m[name] = &fstest.MapFile{Data: []byte(data)}
+	return m
+}
+
+// failFS is a file system where opening one name fails with err: an error
+// that's hard to get from a real disk, on demand. It embeds fs.FS, so it
+// has only Open, and fs.ReadFile and fs.Glob go through it
+type failFS struct {
+	fs.FS
+	name string
+	err  error
+}
+
+func (f failFS) Open(name string) (fs.File, error) {
+	if name == f.name {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: f.err}
+	}
+	return f.FS.Open(name)
+}
+
+func errOrNil(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	var pe *fs.PathError
+	if errors.As(err, &pe) {
+		return pe.Err.Error()
+	}
+	return err.Error()
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"errors"
+	"io/fs"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	base := `{"title": "Notes", "author": "Gopher"}`
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  Config
+	}{
+		{"defaults", map[string]string{"config.json": base}, Config{"Notes", "Gopher", 10}},
+		{"an override", map[string]string{
+			"config.json":         base,
+			"config.d/local.json": `{"per_page": 3}`,
+		}, Config{"Notes", "Gopher", 3}},
+		{"overrides in name order", map[string]string{
+			"config.json":      base,
+			"config.d/20.json": `{"title": "Twenty"}`,
+			"config.d/10.json": `{"title": "Ten", "author": "Ten"}`,
+		}, Config{"Twenty", "Ten", 10}},
+		{"only JSON files", map[string]string{
+			"config.json":           base,
+			"config.d/README":       `not JSON`,
+			"config.d/old.json.bak": `{"title": "Old"}`,
+			"config.d/dir.json/x":   `{"title": "In a directory"}`,
+		}, Config{"Notes", "Gopher", 10}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := LoadConfig(mapFS(tt.files))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("want %+v; got %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestLoadConfigErrors(t *testing.T) {
+	good := mapFS(map[string]string{"config.json": `{"title": "Notes"}`})
+	tests := []struct {
+		name string
+		fsys fs.FS
+		want string
+	}{
+		{"no config.json", fstest.MapFS{}, "open config.json"},
+		{"bad JSON", mapFS(map[string]string{"config.json": `{"title": "Notes",}`}), "config.json: invalid character"},
+		{"bad override", withFile(good, "config.d/x.json", `{"per_page": "ten"}`), "config.d/x.json: json: cannot unmarshal"},
+		{"no title", withFile(good, "config.d/x.json", `{"title": ""}`), "a title is required"},
+		{"zero per page", withFile(good, "config.d/x.json", `{"per_page": 0}`), "per_page is 0"},
+		{"unreadable config.d", failFS{good, "config.d", fs.ErrPermission}, "permission denied"},
+		{"unreadable override", failFS{withFile(good, "config.d/x.json", `{}`), "config.d/x.json", fs.ErrPermission}, "permission denied"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := LoadConfig(tt.fsys)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("want an error with %q; got %v", tt.want, err)
+			}
+		})
+	}
+
+	// the error is the file system's own, for errors.Is to find
+	_, err := LoadConfig(failFS{good, "config.d", fs.ErrPermission})
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Errorf("want fs.ErrPermission; got %v", err)
+	}
+}
+
+// mapFS is a MapFS with the given files
+func mapFS(files map[string]string) fstest.MapFS {
+	m := make(fstest.MapFS)
+	for name, data := range files {
+		m[name] = &fstest.MapFile{Data: []byte(data)}
+	}
+	return m
+}
+
+// get serves path from a site on fsys
+func get(t *testing.T, fsys fs.FS, path string) *httptest.ResponseRecorder {
+	t.Helper()
+	site, err := NewSite(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := httptest.NewRecorder()
+	site.Handler().ServeHTTP(w, httptest.NewRequest("GET", path, nil))
+	return w
+}
+
+func TestHandler(t *testing.T) {
+	fsys := withFile(memSite, "notes/zebra.txt", "Zebra\nstripes")
+	fsys = withFile(fsys, "notes/apple.txt", "Apple\nred")
+	tests := []struct {
+		path   string
+		status int
+		want   string // in the body
+	}{
+		{"/", 200, "<title>Notes (local)</title>"},
+		{"/", 200, `<a href="/notes/apple">Apple</a>`},
+		{"/notes/hello", 200, "<h1>Hello, &lt;fs.FS&gt;</h1>"},
+		{"/notes/zebra", 200, "<p>stripes</p>"},
+		{"/notes/nope", 404, "not found"},
+		{"/notes/..%2Fconfig", 404, "not found"},
+		{"/notes/..%2F..%2Fetc%2Fpasswd", 404, "not found"},
+		{"/static/style.css", 200, "margin"},
+		{"/static/..%2Fconfig.json", 404, "not found"},
+		{"/config.json", 404, "not found"},
+	}
+	for _, tt := range tests {
+		w := get(t, fsys, tt.path)
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("GET %s: want %d with %q; got %d:\n%s", tt.path, tt.status, tt.want, w.Code, w.Body)
+		}
+	}
+
+	// per_page is 2: apple and hello, not zebra
+	if body := get(t, fsys, "/").Body.String(); strings.Contains(body, "Zebra") {
+		t.Errorf("want 2 notes on the index; got:\n%s", body)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	// a note that's there but can't be read is the server's fault
+	w := get(t, failFS{memSite, "notes/hello.txt", fs.ErrPermission}, "/notes/hello")
+	if w.Code != 500 || strings.Contains(w.Body.String(), "permission") {
+		t.Errorf("want a 500 that doesn't say why; got %d: %s", w.Code, w.Body)
+	}
+
+	// a template that fails while executing, not while parsing
+	fsys := withFile(memSite, "templates/note.html", `{{template "head" .Config}}{{.Missing}}`)
+	w = get(t, fsys, "/notes/hello")
+	if w.Code != 500 || strings.Contains(w.Body.String(), "<title>") {
+		t.Errorf("want a 500 and no half page; got %d: %s", w.Code, w.Body)
+	}
+}
+
+func TestNewSiteErrors(t *testing.T) {
+	for name, fsys := range map[string]fs.FS{
+		"broken template": withFile(memSite, "templates/index.html", `{{if}}`),
+		"no templates":    failFS{memSite, "templates", fs.ErrPermission},
+		"no config":       failFS{memSite, "config.json", fs.ErrNotExist},
+	} {
+		if _, err := NewSite(fsys); err == nil {
+			t.Errorf("%s: want an error", name)
+		}
+	}
+}
+
+func TestNotesAreLive(t *testing.T) {
+	fsys := withFile(memSite, "notes/a.txt", "A\nfirst")
+	site, err := NewSite(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := site.Handler()
+
+	fsys["notes/b.txt"] = &fstest.MapFile{Data: []byte("B\nadded later")}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/notes/b", nil))
+	if w.Code != 200 || !strings.Contains(w.Body.String(), "added later") {
+		t.Errorf("want the note added after NewSite; got %d: %s", w.Code, w.Body)
+	}
+}
+
+// TestSiteDir checks the site directory that main serves
+func TestSiteDir(t *testing.T) {
+	fsys := os.DirFS("site")
+	if err := fstest.TestFS(fsys, "config.json", "templates/index.html", "templates/note.html", "static/style.css"); err != nil {
+		t.Fatal(err)
+	}
+	for _, path := range []string{"/", "/notes/interfaces", "/notes/paths", "/static/style.css"} {
+		if w := get(t, fsys, path); w.Code != 200 {
+			t.Errorf("GET %s: want 200; got %d: %s", path, w.Code, w.Body)
+		}
+	}
+	// os.DirFS says fs.ErrInvalid for "notes/../config.txt", not
+	// fs.ErrNotExist, as a MapFS does
+	if w := get(t, fsys, "/notes/..%2Fconfig"); w.Code != 404 {
+		t.Errorf("want a 404 for a note outside notes/; got %d", w.Code)
+	}
+}
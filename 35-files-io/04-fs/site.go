@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"html/template"
+	"io"
+	"io/fs"
+	"log"
+	"net/http"
+	"path"
+	"strings"
+)
+
+// Note is a text file in notes/: its first line is the title
+type Note struct {
+	Name  string
+	Title string
+	Body  string
+}
+
+// Site is a small notes site. Everything it reads, config, templates,
+// notes, and static files, comes from one fs.FS, so the same code runs
+// on a directory, on files embedded in the program, or on a test's map
+type Site struct {
+	fsys  fs.FS
+	cfg   Config
+	pages *template.Template
+}
+
+// NewSite loads the config and parses the templates, so that a broken
+// file stops the program at startup, not at the first request
+func NewSite(fsys fs.FS) (*Site, error) {
+	cfg, err := LoadConfig(fsys)
+	if err != nil {
+		return nil, err
+	}
+	pages, err := template.ParseFS(fsys, "templates/*.html")
+	if err != nil {
+		return nil, err
+	}
+	return &Site{fsys: fsys, cfg: cfg, pages: pages}, nil
+}
+
+// Notes reads the notes on every call, so a note added to a directory
+// shows up without a restart
+func (s *Site) Notes() ([]Note, error) {
+	names, err := fs.Glob(s.fsys, "notes/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	var notes []Note
+	for _, name := range names {
+		n, err := s.Note(strings.TrimSuffix(path.Base(name), ".txt"))
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, n)
+	}
+	return notes, nil
+}
+
+// Note reads one note. name comes from a URL, and can be anything, like
+// "../config". Every fs.FS refuses a path with ".." in it, but os.DirFS
+// says fs.ErrInvalid, which would be a 500: checking first makes it a
+// missing note, whatever the file system
+func (s *Site) Note(name string) (Note, error) {
+	file := "notes/" + name + ".txt"
+	if !fs.ValidPath(file) {
+		return Note{}, &fs.PathError{Op: "open", Path: file, Err: fs.ErrNotExist}
+	}
+	data, err := fs.ReadFile(s.fsys, file)
+	if err != nil {
+		return Note{}, err
+	}
+	title, body, _ := strings.Cut(string(data), "\n")
+	return Note{Name: name, Title: title, Body: strings.TrimSpace(body)}, nil
+}
+
+// Render executes a page into a buffer first: a template that fails
+// halfway writes nothing
+func (s *Site) Render(w io.Writer, page string, data any) error {
+	var buf bytes.Buffer
+	if err := s.pages.ExecuteTemplate(&buf, page, data); err != nil {
+		return err
+	}
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func (s *Site) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("GET /notes/{name}", s.note)
+
+	// fs.Sub is the static directory as a file system of its own:
+	// FileServerFS can't serve anything outside it, like config.json
+	static, err := fs.Sub(s.fsys, "static")
+	if err != nil {
+		panic(err) // only for a name fs.ValidPath rejects
+	}
+	mux.Handle("GET /static/", http.StripPrefix("/static/", http.FileServerFS(static)))
+	return mux
+}
+
+func (s *Site) index(w http.ResponseWriter, r *http.Request) {
+	notes, err := s.Notes()
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	s.render(w, r, "index.html", struct {
+		Config
+		Notes []Note
+	}{s.cfg, notes[:min(len(notes), s.cfg.PerPage)]})
+}
+
+func (s *Site) note(w http.ResponseWriter, r *http.Request) {
+	n, err := s.Note(r.PathValue("name"))
+	if err != nil {
+		s.fail(w, r, err)
+		return
+	}
+	s.render(w, r, "note.html", struct {
+		Config
+		Note
+	}{s.cfg, n})
+}
+
+func (s *Site) render(w http.ResponseWriter, r *http.Request, page string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.Render(w, page, data); err != nil {
+		s.fail(w, r, err) // nothing has been written, so there's room for the error
+	}
+}
+
+// fail sends a 404 for a file that isn't there, and a 500 for anything
+// else. fs.ErrNotExist is the same error from every fs.FS, so this
+// doesn't care which file system it is
+func (s *Site) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, fs.ErrNotExist) {
+		http.NotFound(w, r)
+		return
+	}
+	log.Printf("site: %v", err)
+	http.Error(w, "internal error", http.StatusInternalServerError)
+}
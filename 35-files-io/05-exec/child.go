@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// childEnv, set to 1, makes this program run as a child instead: one of
+// the small commands below. The examples and tests run them, instead of
+// sleep, grep, and wc, which not every system has. A test binary runs
+// them too, from TestMain
+const childEnv = "EXEC_LESSON_CHILD"
+
+// child runs the command in args, and returns its exit code
+func child(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		fmt.Fprintln(stderr, "child: no command")
+		return 2
+	}
+	arg := func(i int) string {
+		if i < len(args) {
+			return args[i]
+		}
+		return ""
+	}
+	num := func(i int) int {
+		n, _ := strconv.Atoi(arg(i))
+		return n
+	}
+	dur := func(i int) time.Duration {
+		d, _ := time.ParseDuration(arg(i))
+		return d
+	}
+
+	switch args[0] {
+	case "talk": // to stdout and stderr, in turns
+		for i := 1; i <= 3; i++ {
+			fmt.Fprintf(stdout, "out %d\n", i)
+			fmt.Fprintf(stderr, "err %d\n", i)
+		}
+	case "fail": // fail CODE MESSAGE
+		fmt.Fprintln(stderr, arg(2))
+		return num(1)
+	case "sleep": // sleep DURATION
+		time.Sleep(dur(1))
+	case "count": // count N DELAY: 1 to N, a line every DELAY
+		for i := 1; i <= num(1); i++ {
+			time.Sleep(dur(2))
+			fmt.Fprintln(stdout, i)
+		}
+	case "gen": // gen N: N numbered lines, as fast as it can
+		w := bufio.NewWriter(stdout)
+		for i := 1; i <= num(1); i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+		}
+		if err := w.Flush(); err != nil {
+			return 1
+		}
+	case "grep": // grep WORD: the lines of stdin with WORD in them
+		sc := bufio.NewScanner(stdin)
+		for sc.Scan() {
+			if strings.Contains(sc.Text(), arg(1)) {
+				fmt.Fprintln(stdout, sc.Text())
+			}
+		}
+	case "head": // head N: the first N lines of stdin, then stop reading
+		sc := bufio.NewScanner(stdin)
+		for i := 0; i < num(1) && sc.Scan(); i++ {
+			fmt.Fprintln(stdout, sc.Text())
+		}
+	case "wc": // wc: the number of lines in stdin
+		n := 0
+		sc := bufio.NewScanner(stdin)
+		for sc.Scan() {
+			n++
+		}
+		fmt.Fprintln(stdout, n)
+	case "env": // env NAME...: each variable, or (unset)
+		for _, name := range args[1:] {
+			v, ok := os.LookupEnv(name)
+			if !ok {
+				v = "(unset)"
+			}
+			fmt.Fprintf(stdout, "%s=%s\n", name, v)
+		}
+	case "orphan": // orphan DURATION: start a child that sleeps, holding our stdout, and exit
+		exe, _ := os.Executable()
+		cmd := exec.Command(exe, "sleep", arg(1))
+		cmd.Env = append(os.Environ(), childEnv+"=1")
+		cmd.Stdout = stdout
+		if err := cmd.Start(); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 1
+		}
+		fmt.Fprintln(stdout, "started", cmd.Process.Pid)
+	default:
+		fmt.Fprintf(stderr, "child: unknown command %q\n", args[0])
+		return 2
+	}
+	return 0
+}
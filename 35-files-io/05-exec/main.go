@@ -0,0 +1,138 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+func main() {
+	if os.Getenv(childEnv) == "1" {
+		os.Exit(child(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+
+	fmt.Println("Running External Commands: os/exec")
+	fmt.Println("==================================")
+	fmt.Println()
+
+	ctx := context.Background()
+
+	// Example 1: stdout and stderr, apart
+	fmt.Println("1. A command that writes to stdout and stderr:")
+	res, err := Run(ctx, self(ctx, "talk"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("   stdout: %q\n   stderr: %q\n", res.Stdout, res.Stderr)
+	out, _ := self(ctx, "talk").CombinedOutput()
+	fmt.Printf("   CombinedOutput: %q\n", out)
+	fmt.Println()
+
+	// Example 2: How commands end
+	fmt.Println("2. Exit codes, and the errors that go with them:")
+	for _, cmd := range []*exec.Cmd{
+		self(ctx, "fail", "0", ""),
+		self(ctx, "fail", "1", "something went wrong"),
+		self(ctx, "fail", "3", "no such user"),
+		command(ctx, "no-such-command-anywhere"),
+	} {
+		res, err := Run(ctx, cmd)
+		var exitErr *exec.ExitError
+		switch {
+		case err == nil:
+			fmt.Printf("   code %2d  ok\n", res.Code)
+		case errors.As(err, &exitErr):
+			fmt.Printf("   code %2d  ExitError %q, stderr %q\n", res.Code, err, strings.TrimSpace(res.Stderr))
+		case errors.Is(err, exec.ErrNotFound):
+			fmt.Printf("   code %2d  not found: %v\n", res.Code, err)
+		default:
+			fmt.Printf("   code %2d  %v\n", res.Code, err)
+		}
+	}
+	fmt.Println()
+
+	// Example 3: Timeouts
+	fmt.Println("3. Timeouts:")
+	tctx, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	start := time.Now()
+	res, err = Run(tctx, self(tctx, "sleep", "10s"))
+	cancel()
+	fmt.Printf("   sleep 10s, 200ms timeout: code %d, %v, after %v\n", res.Code, err, since(start))
+	fmt.Printf("      errors.Is(err, context.DeadlineExceeded): %t\n", errors.Is(err, context.DeadlineExceeded))
+
+	for _, delay := range []time.Duration{0, 300 * time.Millisecond} {
+		cmd := self(ctx, "orphan", "2s")
+		cmd.WaitDelay = delay
+		start := time.Now()
+		_, err := cmd.Output()
+		fmt.Printf("   a grandchild holds stdout, WaitDelay %-6v %v, after %v\n", delay, errOK(err), since(start))
+	}
+	fmt.Println()
+
+	// Example 4: Streaming
+	fmt.Println("4. Streaming output, line by line:")
+	lines := make(chan string)
+	done := make(chan error)
+	start = time.Now()
+	go func() { done <- Stream(ctx, self(ctx, "count", "4", "150ms"), lines) }()
+	for line := range lines {
+		fmt.Printf("   %v: %s\n", since(start), line)
+	}
+	fmt.Printf("   Stream: %v\n", errOK(<-done))
+	fmt.Println()
+
+	// Example 5: Pipelines
+	fmt.Println("5. Pipelines:")
+	var sb strings.Builder
+	last := self(ctx, "wc")
+	last.Stdout = &sb
+	err = Pipeline(self(ctx, "gen", "100000"), self(ctx, "grep", "777"), last)
+	fmt.Printf("   gen 100000 | grep 777 | wc: %s, %v\n", strings.TrimSpace(sb.String()), errOK(err))
+
+	sb.Reset()
+	last = self(ctx, "head", "2")
+	last.Stdout = &sb
+	start = time.Now()
+	err = Pipeline(self(ctx, "gen", "100000000"), last)
+	fmt.Printf("   gen 100000000 | head 2: %q, after %v\n", sb.String(), since(start))
+	fmt.Printf("      %v\n", err)
+	fmt.Println()
+
+	// Example 6: The environment
+	fmt.Println("6. The environment:")
+	os.Setenv("GREETING", "hello from the parent")
+	for _, e := range []struct {
+		name string
+		env  func(env []string) []string
+	}{
+		{"inherited", func(env []string) []string { return env }},
+		{"one added", func(env []string) []string { return append(env, "PORT=8080") }},
+		{"one replaced", func(env []string) []string { return append(env, "GREETING=hi") }},
+		{"only ours", func(env []string) []string { return []string{childEnv + "=1", "PORT=9090"} }},
+	} {
+		cmd := self(ctx, "env", "GREETING", "PORT", "HOME")
+		cmd.Env = e.env(cmd.Env)
+		res, err := Run(ctx, cmd)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("   %-13s %s\n", e.name+":", strings.ReplaceAll(strings.TrimSpace(res.Stdout), "\n", "  "))
+	}
+}
+
+// since is the time since start, rounded for printing
+func since(start time.Time) time.Duration {
+	return time.Since(start).Round(10 * time.Millisecond)
+}
+
+func errOK(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return err.Error()
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestMain lets the test binary run as a child too: self runs
+// os.Executable, which in a test is the test binary, not the program
+func TestMain(m *testing.M) {
+	if os.Getenv(childEnv) == "1" {
+		os.Exit(child(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+	}
+	os.Exit(m.Run())
+}
+
+func TestRun(t *testing.T) {
+	ctx := t.Context()
+	tests := []struct {
+		args   []string
+		code   int
+		stdout string
+		stderr string
+	}{
+		{[]string{"talk"}, 0, "out 1\nout 2\nout 3\n", "err 1\nerr 2\nerr 3\n"},
+		{[]string{"fail", "0", ""}, 0, "", "\n"},
+		{[]string{"fail", "7", "bad input"}, 7, "", "bad input\n"},
+		{[]string{"nonsense"}, 2, "", "child: unknown command \"nonsense\"\n"},
+	}
+	for _, tt := range tests {
+		res, err := Run(ctx, self(ctx, tt.args...))
+		if res.Code != tt.code || res.Stdout != tt.stdout || res.Stderr != tt.stderr {
+			t.Errorf("%v: want %d, %q, %q; got %+v", tt.args, tt.code, tt.stdout, tt.stderr, res)
+		}
+
+		var exitErr *exec.ExitError
+		switch {
+		case tt.code == 0 && err != nil:
+			t.Errorf("%v: want no error; got %v", tt.args, err)
+		case tt.code != 0 && !errors.As(err, &exitErr):
+			t.Errorf("%v: want an *exec.ExitError; got %v", tt.args, err)
+		case tt.code != 0 && exitErr.ExitCode() != tt.code:
+			t.Errorf("%v: want ExitError code %d; got %d", tt.args, tt.code, exitErr.ExitCode())
+		}
+	}
+}
+
+func TestRunNotFound(t *testing.T) {
+	ctx := t.Context()
+	res, err := Run(ctx, command(ctx, "no-such-command-anywhere"))
+	if !errors.Is(err, exec.ErrNotFound) || res.Code != -1 {
+		t.Errorf("want exec.ErrNotFound and code -1; got %v, %d", err, res.Code)
+	}
+}
+
+func TestRunTimeout(t *testing.T) {
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	res, err := Run(ctx, self(ctx, "sleep", "10s"))
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("want the command killed at the timeout; it took %v", elapsed)
+	}
+	var exitErr *exec.ExitError
+	if !errors.Is(err, context.DeadlineExceeded) || !errors.As(err, &exitErr) {
+		t.Errorf("want DeadlineExceeded and an *exec.ExitError; got %v", err)
+	}
+	if res.Code != -1 {
+		t.Errorf("want code -1 for a killed command; got %d", res.Code)
+	}
+}
+
+func TestWaitDelay(t *testing.T) {
+	// orphan exits at once, but the grandchild it starts holds its stdout
+	// open. Without a WaitDelay, Output would wait the whole 5 seconds
+	ctx := t.Context()
+	cmd := self(ctx, "orphan", "5s")
+	cmd.WaitDelay = 100 * time.Millisecond
+
+	start := time.Now()
+	out, err := cmd.Output()
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("want Output to return after WaitDelay; it took %v", elapsed)
+	}
+	if !errors.Is(err, exec.ErrWaitDelay) || !strings.HasPrefix(string(out), "started") {
+		t.Errorf("want ErrWaitDelay and what the child wrote; got %v, %q", err, out)
+	}
+}
+
+func TestStream(t *testing.T) {
+	ctx := t.Context()
+	lines := make(chan string)
+	done := make(chan error, 1)
+	go func() { done <- Stream(ctx, self(ctx, "count", "3", "200ms"), lines) }()
+
+	// the first line must come long before the command ends: 2 and 3
+	// take another 400ms
+	if first := <-lines; first != "1" {
+		t.Fatalf("want 1 first; got %q", first)
+	}
+	firstAt := time.Now()
+	var rest []string
+	for line := range lines {
+		rest = append(rest, line)
+	}
+	if err := <-done; err != nil {
+		t.Errorf("want no error; got %v", err)
+	}
+	if !slices.Equal(rest, []string{"2", "3"}) {
+		t.Errorf("want 2 and 3; got %q", rest)
+	}
+	if early := time.Since(firstAt); early < 300*time.Millisecond {
+		t.Errorf("want the first line as it's written; it came only %v before the end", early)
+	}
+}
+
+func TestStreamCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(t.Context())
+	lines := make(chan string)
+	done := make(chan error, 1)
+	go func() { done <- Stream(ctx, self(ctx, "count", "1000", "10ms"), lines) }()
+
+	<-lines
+	cancel() // and never read again
+
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("want context.Canceled; got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Stream didn't return after the cancel")
+	}
+	for range lines { // closed, after at most one more line
+	}
+}
+
+func TestStreamExitCode(t *testing.T) {
+	ctx := t.Context()
+	lines := make(chan string)
+	done := make(chan error, 1)
+	go func() { done <- Stream(ctx, self(ctx, "fail", "4", "oops"), lines) }()
+	for range lines {
+	}
+	var exitErr *exec.ExitError
+	if err := <-done; !errors.As(err, &exitErr) || exitErr.ExitCode() != 4 {
+		t.Errorf("want exit code 4; got %v", err)
+	}
+}
+
+func TestPipeline(t *testing.T) {
+	ctx := t.Context()
+	var out strings.Builder
+	first := self(ctx, "grep", "o")
+	// Two lines match, and head reads both: if it stopped before the end,
+	// grep could die of a broken pipe
+	first.Stdin = strings.NewReader("one\ntwo\nthree\n")
+	last := self(ctx, "wc")
+	last.Stdout = &out
+
+	if err := Pipeline(first, self(ctx, "head", "2"), last); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(out.String()); got != "2" {
+		t.Errorf("want 2; got %q", got)
+	}
+}
+
+func TestPipelineFailures(t *testing.T) {
+	ctx := t.Context()
+
+	// pipefail: the middle command fails, and the last one succeeds. gen
+	// may die of a broken pipe too, and the rightmost failure is reported
+	for range 10 {
+		err := Pipeline(self(ctx, "gen", "10"), self(ctx, "fail", "5", ""), self(ctx, "wc"))
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) || exitErr.ExitCode() != 5 || !strings.HasPrefix(err.Error(), "command 2 of 3:") {
+			t.Fatalf("want command 2 to fail with 5; got %v", err)
+		}
+	}
+
+	// head stops reading, and gen, with far more to write, must not hang
+	start := time.Now()
+	var out strings.Builder
+	last := self(ctx, "head", "1")
+	last.Stdout = &out
+	Pipeline(self(ctx, "gen", "100000000"), last)
+	if time.Since(start) > 10*time.Second || out.String() != "line 1\n" {
+		t.Errorf("want line 1, quickly; got %q after %v", out.String(), time.Since(start))
+	}
+
+	// a command that can't start: the ones started before it are stopped
+	err := Pipeline(self(ctx, "sleep", "10s"), command(ctx, "no-such-command-anywhere"))
+	if !errors.Is(err, exec.ErrNotFound) {
+		t.Errorf("want exec.ErrNotFound; got %v", err)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	t.Setenv("GREETING", "parent")
+	ctx := t.Context()
+	tests := []struct {
+		name string
+		env  func([]string) []string
+		want string
+	}{
+		{"inherited", func(env []string) []string { return env }, "GREETING=parent\nPORT=(unset)\n"},
+		{"added", func(env []string) []string { return append(env, "PORT=1") }, "GREETING=parent\nPORT=1\n"},
+		{"the last one wins", func(env []string) []string { return append(env, "GREETING=a", "GREETING=b") }, "GREETING=b\nPORT=(unset)\n"},
+		{"only ours", func([]string) []string { return []string{childEnv + "=1", "PORT=2"} }, "GREETING=(unset)\nPORT=2\n"},
+	}
+	for _, tt := range tests {
+		cmd := self(ctx, "env", "GREETING", "PORT")
+		cmd.Env = tt.env(cmd.Env)
+		res, err := Run(ctx, cmd)
+		if err != nil || res.Stdout != tt.want {
+			t.Errorf("%s: want %q; got %q, %v", tt.name, tt.want, res.Stdout, err)
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// command is exec.CommandContext with a WaitDelay. When ctx is done, the
+// process is killed; without a WaitDelay, Wait would still wait for every
+// process holding its stdout or stderr open, like a child it started
+func command(ctx context.Context, name string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.WaitDelay = time.Second
+	return cmd
+}
+
+// self is a command that runs this program again, as a child
+func self(ctx context.Context, args ...string) *exec.Cmd {
+	exe, err := os.Executable()
+	if err != nil {
+		exe = os.Args[0]
+	}
+	cmd := command(ctx, exe, args...)
+	cmd.Env = append(os.Environ(), childEnv+"=1")
+	return cmd
+}
+
+// Result is what a command wrote, and how it ended
+type Result struct {
+	Stdout string
+	Stderr string
+	Code   int // -1 if it was killed, or never started
+}
+
+// Run runs cmd, made with ctx, and captures its stdout and stderr apart.
+// The error is nil only for exit code 0. Otherwise it's an *exec.ExitError,
+// an *exec.Error if the program wasn't found, or, if ctx ended the
+// command, both ctx.Err() and the ExitError for the kill
+func Run(ctx context.Context, cmd *exec.Cmd) (Result, error) {
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	res := Result{Stdout: stdout.String(), Stderr: stderr.String(), Code: -1}
+	if cmd.ProcessState != nil {
+		res.Code = cmd.ProcessState.ExitCode()
+	}
+	return res, why(ctx, err)
+}
+
+// why adds ctx's error to err, if ctx is done. A command killed because
+// of ctx fails with "signal: killed", which doesn't say why
+func why(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return fmt.Errorf("%w: %w", ctx.Err(), err)
+	}
+	return err
+}
+
+// Stream runs cmd, made with ctx, and sends each line of its stdout on
+// lines as soon as it's written, not when the command ends. It closes
+// lines at the end, and returns what Wait returns. If ctx ends first, it
+// stops sending, and the command is killed
+func Stream(ctx context.Context, cmd *exec.Cmd, lines chan<- string) error {
+	defer close(lines)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+
+	sc := bufio.NewScanner(out)
+	for sc.Scan() {
+		select {
+		case lines <- sc.Text():
+		case <-ctx.Done():
+			return why(ctx, cmd.Wait())
+		}
+	}
+	// Wait closes the pipe, so every read must be done first
+	return why(ctx, errors.Join(sc.Err(), cmd.Wait()))
+}
+
+// Pipeline runs cmds as a shell runs cmd1 | cmd2 | cmd3: each one's stdout
+// is the next one's stdin. The data goes from process to process through
+// OS pipes, never through this program. Set the first command's Stdin and
+// the last one's Stdout, if they need any.
+//
+// Like bash with pipefail, it fails if any command fails, not only the
+// last, and reports the rightmost one that failed. A command that stops
+// reading early, like head, or that fails, makes the one before it die of
+// a broken pipe when it writes more; that's a consequence, not the cause
+func Pipeline(cmds ...*exec.Cmd) error {
+	var parentEnds []*os.File
+	defer func() {
+		for _, f := range parentEnds {
+			f.Close()
+		}
+	}()
+	for i := range len(cmds) - 1 {
+		r, w, err := os.Pipe()
+		if err != nil {
+			return err
+		}
+		cmds[i].Stdout = w
+		cmds[i+1].Stdin = r
+		parentEnds = append(parentEnds, r, w)
+	}
+
+	for i, cmd := range cmds {
+		if err := cmd.Start(); err != nil {
+			for _, started := range cmds[:i] {
+				started.Process.Kill()
+				started.Wait()
+			}
+			return err
+		}
+	}
+	// The children have their own copies of the pipes now. Until this
+	// program closes its own, a reader never sees the end of its input,
+	// and a writer never sees that its reader is gone
+	for _, f := range parentEnds {
+		f.Close()
+	}
+	parentEnds = nil
+
+	var last error
+	for i, cmd := range cmds {
+		if err := cmd.Wait(); err != nil {
+			last = fmt.Errorf("command %d of %d: %w", i+1, len(cmds), err)
+		}
+	}
+	return last
+}
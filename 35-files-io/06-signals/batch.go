@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"io"
+	"log"
+	"os"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/workerpool"
+)
+
+// errSkipped is the error for a job a worker took after the first
+// signal. Submit was already waiting for a free worker when it came
+var errSkipped = errors.New("skipped")
+
+// runBatch runs a batch of jobs on a worker pool, and stops in two stages
+// on a signal. It returns the exit code: 0 if every job that started
+// finished, and 1 if some had to be abandoned
+func runBatch(args []string, out io.Writer) int {
+	flags := flag.NewFlagSet("batch", flag.ContinueOnError)
+	jobs := flags.Int("jobs", 20, "the number of jobs")
+	work := flags.Duration("work", 500*time.Millisecond, "how long each job takes")
+	workers := flags.Int("workers", 3, "the number of workers")
+	grace := flags.Duration("grace", 5*time.Second, "how long to wait for started jobs after a signal")
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	// A log.Logger is safe to use from every worker at once
+	log := log.New(out, "", 0)
+
+	sd := NewShutdown(*grace, os.Interrupt, syscall.SIGTERM)
+	defer sd.Close()
+
+	// Stopping doesn't reach the pool: the pool's context is Abort. Its
+	// workers finish the jobs they have, until Abort is canceled too
+	var started atomic.Int64
+	pool := workerpool.New(sd.Abort, *workers, func(ctx context.Context, id int) (time.Duration, error) {
+		if sd.Stopping.Err() != nil {
+			return 0, errSkipped
+		}
+		started.Add(1)
+		log.Printf("job %d started", id)
+		select {
+		case <-time.After(*work):
+			return *work, nil
+		case <-ctx.Done():
+			return 0, context.Cause(ctx)
+		}
+	})
+	go func() {
+		for id := 1; id <= *jobs && sd.Stopping.Err() == nil; id++ {
+			if pool.Submit(id) != nil {
+				break
+			}
+		}
+		pool.Drain()
+	}()
+
+	report := make(chan struct{})
+	go func() {
+		defer close(report)
+		<-sd.Stopping.Done()
+		if errors.Is(context.Cause(sd.Stopping), context.Canceled) {
+			return // Close, at the end, with no signal
+		}
+		log.Printf("%v: finishing the jobs that have started (again to quit now)\n", context.Cause(sd.Stopping))
+		<-sd.Abort.Done()
+		if cause := context.Cause(sd.Abort); !errors.Is(cause, context.Canceled) {
+			log.Printf("%v: quitting now\n", cause)
+		}
+	}()
+
+	log.Printf("ready: %d jobs, %d workers\n", *jobs, *workers)
+	var done int
+	for r := range pool.Results() {
+		if r.Err == nil {
+			done++
+			log.Printf("job %d done\n", r.Input)
+		}
+	}
+	sd.Close()
+	<-report
+
+	// An aborted job's result may never be sent: the pool's workers stop
+	// when its context is canceled. started counts every job
+	abandoned := int(started.Load()) - done
+	log.Printf("%d done, %d abandoned, %d not started\n", done, abandoned, *jobs-int(started.Load()))
+	if abandoned > 0 {
+		return 1
+	}
+	return 0
+}
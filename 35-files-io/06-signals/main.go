@@ -0,0 +1,148 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		os.Exit(runBatch(os.Args[2:], os.Stdout))
+	}
+
+	fmt.Println("OS Signals and Coordinated Shutdown")
+	fmt.Println("===================================")
+	fmt.Println()
+
+	// Example 1: signal.Notify
+	fmt.Println("1. signal.Notify: a signal as a value on a channel:")
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
+	must(raise(os.Interrupt))
+	fmt.Printf("   received %q, and the program is still running\n", <-sigs)
+	must(raise(syscall.SIGTERM))
+	fmt.Printf("   received %q\n", <-sigs)
+	signal.Stop(sigs)
+	fmt.Println()
+
+	// Example 2: signal.NotifyContext
+	fmt.Println("2. signal.NotifyContext: a signal as a canceled context:")
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM)
+	must(raise(syscall.SIGTERM))
+	select {
+	case <-ctx.Done():
+		fmt.Printf("   ctx.Err(): %v\n", ctx.Err())
+	case <-time.After(time.Second):
+		fmt.Println("   no signal")
+	}
+	stop() // the default again: another SIGTERM would end the program
+	fmt.Println()
+
+	// Example 3: Ignore
+	fmt.Println("3. Ignoring a signal, and listening to it again:")
+	signal.Ignore(syscall.SIGHUP)
+	fmt.Printf("   after Ignore: Ignored(SIGHUP) = %t\n", signal.Ignored(syscall.SIGHUP))
+	must(raise(syscall.SIGHUP)) // by default, SIGHUP ends a program
+	time.Sleep(50 * time.Millisecond)
+	fmt.Println("   sent SIGHUP, and the program is still running")
+	signal.Notify(sigs, syscall.SIGHUP)
+	fmt.Printf("   after Notify: Ignored(SIGHUP) = %t\n", signal.Ignored(syscall.SIGHUP))
+	must(raise(syscall.SIGHUP))
+	fmt.Printf("   received %q\n", <-sigs)
+	signal.Stop(sigs)
+	fmt.Println()
+
+	// Example 4: One Ctrl+C
+	fmt.Println("4. A batch on a worker pool, and one Ctrl+C once 3 jobs have started:")
+	runChild([]string{"-jobs", "12", "-work", "300ms"}, send{"started", 3, os.Interrupt})
+	fmt.Println()
+
+	// Example 5: Two
+	fmt.Println("5. Ctrl+C twice: the second one doesn't wait:")
+	runChild([]string{"-jobs", "12", "-work", "300ms"},
+		send{"started", 3, os.Interrupt}, send{"(again to quit now)", 1, os.Interrupt})
+	fmt.Println()
+
+	// Example 6: A deadline
+	fmt.Println("6. SIGTERM, with jobs that take longer than the grace period:")
+	runChild([]string{"-jobs", "12", "-work", "2s", "-grace", "300ms"}, send{"started", 3, syscall.SIGTERM})
+}
+
+// raise sends sig to this program, as kill -s does from a shell
+func raise(sig os.Signal) error {
+	p, err := os.FindProcess(os.Getpid())
+	if err != nil {
+		return err
+	}
+	return p.Signal(sig)
+}
+
+// send is a signal for child to send once it has seen n lines ending
+// with after, counting from the last signal it sent
+type send struct {
+	after string
+	n     int
+	sig   os.Signal
+}
+
+// child runs this program's batch as another process, with args. It
+// calls show with every line the batch writes, and with every signal it
+// sends the batch. It returns the batch's exit code
+func child(args []string, show func(string), sends ...send) (int, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return 0, err
+	}
+	cmd := exec.Command(exe, append([]string{"batch"}, args...)...)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return 0, err
+	}
+	if err := cmd.Start(); err != nil {
+		return 0, err
+	}
+
+	seen := 0
+	sc := bufio.NewScanner(out)
+	for sc.Scan() {
+		show(sc.Text())
+		if len(sends) == 0 || !strings.HasSuffix(sc.Text(), sends[0].after) {
+			continue
+		}
+		if seen++; seen == sends[0].n {
+			show("<- " + sends[0].sig.String())
+			if err := cmd.Process.Signal(sends[0].sig); err != nil {
+				cmd.Process.Kill()
+				cmd.Wait()
+				return 0, err
+			}
+			sends, seen = sends[1:], 0
+		}
+	}
+	cmd.Wait()
+	return cmd.ProcessState.ExitCode(), nil
+}
+
+// runChild runs child, and prints what it shows, with the time
+func runChild(args []string, sends ...send) {
+	start := time.Now()
+	code, err := child(args, func(line string) {
+		fmt.Printf("   %6v  %s\n", time.Since(start).Round(10*time.Millisecond), line)
+	}, sends...)
+	must(err)
+	fmt.Printf("   exit code %d\n", code)
+}
+
+func must(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,145 @@
+//go:build unix
+
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// TestMain runs the batch when a test runs the test binary as a child
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && os.Args[1] == "batch" {
+		os.Exit(runBatch(os.Args[2:], os.Stdout))
+	}
+	os.Exit(m.Run())
+}
+
+// done waits for ctx, and fails the test if it takes too long
+func done(t *testing.T, ctx context.Context, what string) {
+	t.Helper()
+	select {
+	case <-ctx.Done():
+	case <-time.After(5 * time.Second):
+		t.Fatalf("%s: not canceled", what)
+	}
+}
+
+func TestShutdown(t *testing.T) {
+	sd := NewShutdown(time.Minute, syscall.SIGUSR1)
+	defer sd.Close()
+
+	if err := raise(syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	done(t, sd.Stopping, "Stopping")
+	if got := context.Cause(sd.Stopping).Error(); got != "got user defined signal 1" {
+		t.Errorf("want the signal as the cause; got %q", got)
+	}
+	if sd.Abort.Err() != nil {
+		t.Fatal("want Abort to wait for a second signal")
+	}
+
+	if err := raise(syscall.SIGUSR1); err != nil {
+		t.Fatal(err)
+	}
+	done(t, sd.Abort, "Abort")
+	if got := context.Cause(sd.Abort).Error(); got != "got user defined signal 1 again" {
+		t.Errorf("want the second signal as the cause; got %q", got)
+	}
+}
+
+func TestShutdownGrace(t *testing.T) {
+	sd := NewShutdown(100*time.Millisecond, syscall.SIGUSR2)
+	defer sd.Close()
+
+	if err := raise(syscall.SIGUSR2); err != nil {
+		t.Fatal(err)
+	}
+	done(t, sd.Stopping, "Stopping")
+	done(t, sd.Abort, "Abort")
+	if got := context.Cause(sd.Abort).Error(); got != "still stopping after 100ms" {
+		t.Errorf("want the grace period as the cause; got %q", got)
+	}
+}
+
+func TestShutdownClose(t *testing.T) {
+	sd := NewShutdown(time.Minute, syscall.SIGUSR1)
+	sd.Close()
+	sd.Close() // twice is fine
+
+	for _, ctx := range []context.Context{sd.Stopping, sd.Abort} {
+		if !errors.Is(context.Cause(ctx), context.Canceled) {
+			t.Errorf("want context.Canceled after Close; got %v", context.Cause(ctx))
+		}
+	}
+}
+
+// TestBatch runs the batch as another process, and sends it signals, as
+// a terminal or a container runtime would
+func TestBatch(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs child processes")
+	}
+	tests := []struct {
+		name  string
+		args  []string
+		sends []send
+		code  int
+		want  []string // lines, in this order
+	}{
+		{
+			"no signal", []string{"-jobs", "5", "-work", "10ms"}, nil, 0,
+			[]string{"ready: 5 jobs, 3 workers", "5 done, 0 abandoned, 0 not started"},
+		},
+		{
+			"one interrupt", []string{"-jobs", "12", "-work", "1s"},
+			[]send{{"started", 3, os.Interrupt}}, 0,
+			[]string{"<- interrupt", "got interrupt: finishing the jobs that have started (again to quit now)", "3 done, 0 abandoned, 9 not started"},
+		},
+		{
+			"two interrupts", []string{"-jobs", "12", "-work", "10s"},
+			[]send{{"started", 3, os.Interrupt}, {"(again to quit now)", 1, os.Interrupt}}, 1,
+			[]string{"got interrupt again: quitting now", "0 done, 3 abandoned, 9 not started"},
+		},
+		{
+			"SIGTERM and a deadline", []string{"-jobs", "12", "-work", "10s", "-grace", "200ms"},
+			[]send{{"started", 3, syscall.SIGTERM}}, 1,
+			[]string{"got terminated: finishing", "still stopping after 200ms: quitting now", "0 done, 3 abandoned, 9 not started"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var lines []string
+			start := time.Now()
+			code, err := child(tt.args, func(line string) { lines = append(lines, line) }, tt.sends...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("want the batch to stop at once; it took %v", elapsed)
+			}
+			if code != tt.code {
+				t.Errorf("want exit code %d; got %d", tt.code, code)
+			}
+
+			rest := lines
+			for _, want := range tt.want {
+				i := 0
+				for i < len(rest) && !strings.HasPrefix(rest[i], want) {
+					i++
+				}
+				if i == len(rest) {
+					t.Fatalf("want a line %q, in order; got:\n%s", want, strings.Join(lines, "\n"))
+				}
+				rest = rest[i+1:]
+			}
+		})
+	}
+}
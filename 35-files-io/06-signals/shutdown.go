@@ -0,0 +1,74 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+)
+
+// Shutdown turns signals into a stop in two stages. The first signal
+// cancels Stopping: take no new work, and finish what has started. A
+// second signal, or the grace period running out, cancels Abort: give up
+// on what has started too.
+//
+// After that, the signals get their default behavior back, and one more
+// Ctrl+C kills the program, however stuck it is
+type Shutdown struct {
+	Stopping context.Context
+	Abort    context.Context
+
+	stop, abort context.CancelCauseFunc
+	sigs        chan os.Signal
+	done        chan struct{}
+	once        sync.Once
+}
+
+// NewShutdown starts listening for sigs, which are usually os.Interrupt
+// (Ctrl+C) and syscall.SIGTERM (what kill, Docker, and Kubernetes send)
+func NewShutdown(grace time.Duration, sigs ...os.Signal) *Shutdown {
+	s := &Shutdown{
+		// Notify never blocks to deliver a signal: with no room in the
+		// channel, the signal is dropped
+		sigs: make(chan os.Signal, 1),
+		done: make(chan struct{}),
+	}
+	s.Stopping, s.stop = context.WithCancelCause(context.Background())
+	s.Abort, s.abort = context.WithCancelCause(context.Background())
+
+	signal.Notify(s.sigs, sigs...)
+	go s.watch(grace)
+	return s
+}
+
+func (s *Shutdown) watch(grace time.Duration) {
+	// From here on, a signal does what it would without Notify
+	defer signal.Stop(s.sigs)
+
+	var sig os.Signal
+	select {
+	case sig = <-s.sigs:
+	case <-s.done:
+		return
+	}
+	s.stop(fmt.Errorf("got %v", sig))
+
+	timer := time.NewTimer(grace)
+	defer timer.Stop()
+	select {
+	case sig = <-s.sigs:
+		s.abort(fmt.Errorf("got %v again", sig))
+	case <-timer.C:
+		s.abort(fmt.Errorf("still stopping after %v", grace))
+	case <-s.done:
+	}
+}
+
+// Close stops listening for signals, and cancels both contexts
+func (s *Shutdown) Close() {
+	s.once.Do(func() { close(s.done) })
+	s.stop(nil)
+	s.abort(nil)
+}
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+)
+
+// WriteFileAtomic writes data to path so that path always holds the old
+// contents or the new ones: never a mix, and never a truncated file, even
+// if the program crashes or the machine loses power halfway
+func WriteFileAtomic(path string, data []byte, perm fs.FileMode) error {
+	return writeAtomic(path, perm, func(w io.Writer) error {
+		_, err := w.Write(data)
+		return err
+	})
+}
+
+// writeAtomic is WriteFileAtomic, with write filling the file
+func writeAtomic(path string, perm fs.FileMode, write func(io.Writer) error) (err error) {
+	// The temporary file goes next to path, not in os.TempDir: a rename
+	// is atomic only within one file system
+	dir := filepath.Dir(path)
+	f, err := os.CreateTemp(dir, tempPattern(path))
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if err := write(f); err != nil {
+		return err
+	}
+	// CreateTemp creates the file with 0600
+	if err := f.Chmod(perm); err != nil {
+		return err
+	}
+	// Without a Sync, the rename can reach the disk before the data, and
+	// a power cut leaves an empty file under the new name
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), path); err != nil {
+		return err
+	}
+	return syncDir(dir)
+}
+
+// syncDir makes a rename in dir durable: the rename changed the directory,
+// not the file, and a power cut can undo a change that's only in memory
+func syncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil // Windows can't open a directory to sync it
+	}
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	return d.Sync()
+}
+
+// tempPattern names the temporary files for path: hidden, and easy to
+// find again
+func tempPattern(path string) string {
+	return "." + filepath.Base(path) + ".tmp-*"
+}
+
+// removeTemps removes temporary files that writers of path left behind
+// when they crashed. Only call it while holding path's lock: otherwise,
+// one of them may belong to a writer that's still writing
+func removeTemps(path string) error {
+	temps, err := filepath.Glob(filepath.Join(filepath.Dir(path), tempPattern(path)))
+	if err != nil {
+		return err
+	}
+	for _, t := range temps {
+		if err := os.Remove(t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrLocked means another process holds the lock
+var ErrLocked = errors.New("locked by another process")
+
+// Lock is an advisory lock on a lock file. Advisory means that it stops
+// only programs that ask for the lock: anything can still write the file
+// it protects.
+//
+// It's an OS lock on an open file, not the file's existence: the OS
+// releases it when the process exits, however it exits, so a crash never
+// leaves a stale lock behind
+type Lock struct {
+	f *os.File
+}
+
+// Acquire locks the lock file at path, creating it if it's missing. If
+// another process holds it, Acquire tries again until wait has passed
+func Acquire(path string, wait time.Duration) (*Lock, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(wait)
+	for {
+		ok, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, err
+		}
+		if ok {
+			break
+		}
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("%s: %w (pid %s)", path, ErrLocked, holder(path))
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	// The process ID is only for people: it says who to wait for
+	if err := f.Truncate(0); err == nil {
+		f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{f: f}, nil
+}
+
+// Release unlocks the lock. It leaves the lock file in place: removing
+// it would let a process lock a new file, while another still holds the
+// old one, removed, and both think they have the lock
+func (l *Lock) Release() error {
+	return errors.Join(unlock(l.f), l.f.Close())
+}
+
+// holder is the process ID in the lock file at path, or "?"
+func holder(path string) string {
+	data, err := os.ReadFile(path)
+	if pid := strings.TrimSpace(string(data)); err == nil && pid != "" {
+		return pid
+	}
+	return "?"
+}
@@ -0,0 +1,18 @@
+//go:build !unix
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// tryLock needs flock, which only Unix systems have. On Windows,
+// golang.org/x/sys/windows.LockFileEx does the same job
+func tryLock(f *os.File) (bool, error) {
+	return false, errors.ErrUnsupported
+}
+
+func unlock(f *os.File) error {
+	return errors.ErrUnsupported
+}
@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+func main() {
+	if len(os.Args) > 1 {
+		if err := command(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	fmt.Println("Atomic File Writes and Lock Files")
+	fmt.Println("=================================")
+	fmt.Println()
+
+	dir, err := os.MkdirTemp("", "atomic")
+	must(err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "progress.json")
+
+	// Example 1: A crash in the middle of a plain write
+	fmt.Println("1. A crash halfway through rewriting progress.json in place:")
+	start(file, "01-get-started", "02-write-your-first-program")
+	must(crash("crash-in-place", file))
+	show(file)
+	fmt.Println()
+
+	// Example 2: The same crash, in an atomic write
+	fmt.Println("2. The same crash, writing a temporary file and renaming it:")
+	start(file, "01-get-started", "02-write-your-first-program")
+	must(crash("crash-atomic", file))
+	show(file)
+	temps, _ := filepath.Glob(filepath.Join(dir, ".progress.json.tmp-*"))
+	fmt.Printf("   left behind: %d temporary file(s)\n", len(temps))
+	must(Update(file, func(p *Progress) error { return nil }))
+	temps, _ = filepath.Glob(filepath.Join(dir, ".progress.json.tmp-*"))
+	fmt.Printf("   after the next Update: %d\n", len(temps))
+	fmt.Println()
+
+	// Example 3: Lost updates
+	fmt.Println("3. Eight programs mark a lesson each, at the same time:")
+	for _, lock := range []bool{false, true} {
+		os.Remove(file)
+		var wg sync.WaitGroup
+		for i := range 8 {
+			args := []string{"-file", file, "-slow", "50ms", fmt.Sprintf("lesson-%d", i+1)}
+			if !lock {
+				args = append([]string{"-nolock"}, args...)
+			}
+			wg.Go(func() { must(self("mark", args...).Run()) })
+		}
+		wg.Wait()
+		p, err := Load(file)
+		must(err)
+		fmt.Printf("   lock=%-5t %d of 8 lessons saved\n", lock, len(p.Done))
+	}
+	fmt.Println()
+
+	// Example 4: Waiting for the lock
+	fmt.Println("4. Another program holds the lock:")
+	holder := self("hold", file+".lock", "500ms")
+	out, err := holder.StdoutPipe()
+	must(err)
+	must(holder.Start())
+	bufio.NewReader(out).ReadString('\n') // "locked"
+
+	_, err = Acquire(file+".lock", 100*time.Millisecond)
+	fmt.Printf("   waiting 100ms: %v\n", strings.TrimPrefix(err.Error(), dir+string(filepath.Separator)))
+	begin := time.Now()
+	lock, err := Acquire(file+".lock", 2*time.Second)
+	must(err)
+	fmt.Printf("   waiting up to 2s: locked after %v, when it exited\n", time.Since(begin).Round(100*time.Millisecond))
+	lock.Release()
+	holder.Wait()
+
+	holder = self("hold", file+".lock", "1h")
+	out, err = holder.StdoutPipe()
+	must(err)
+	must(holder.Start())
+	bufio.NewReader(out).ReadString('\n')
+	holder.Process.Kill()
+	holder.Wait()
+	lock, err = Acquire(file+".lock", 0)
+	fmt.Printf("   a holder killed with SIGKILL: locked at once: %t\n", err == nil)
+	if err == nil {
+		lock.Release()
+	}
+}
+
+// command runs one of the program's commands. mark and list are the
+// progress tracker; the others are for the examples and the tests
+func command(name string, args []string) error {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	file := flags.String("file", "progress.json", "the progress file")
+	noLock := flags.Bool("nolock", false, "mark without the lock")
+	slow := flags.Duration("slow", 0, "wait this long between loading and saving, like a slower program")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	args = flags.Args()
+
+	switch name {
+	case "mark": // mark LESSON...
+		change := func(p *Progress) error {
+			time.Sleep(*slow)
+			for _, lesson := range args {
+				p.Done[lesson] = time.Now().UTC().Truncate(time.Second)
+			}
+			return nil
+		}
+		if !*noLock {
+			return Update(*file, change)
+		}
+		p, err := Load(*file)
+		if err != nil {
+			return err
+		}
+		change(p)
+		return p.Save(*file)
+
+	case "list":
+		p, err := Load(*file)
+		if err != nil {
+			return err
+		}
+		for _, lesson := range slices.Sorted(maps.Keys(p.Done)) {
+			fmt.Printf("%s  %s\n", p.Done[lesson].Format(time.DateOnly), lesson)
+		}
+		return nil
+
+	case "crash-in-place", "crash-atomic": // FILE: rewrite FILE, and stop halfway
+		data, err := os.ReadFile(args[0])
+		if err != nil {
+			return err
+		}
+		halfway := func(w io.Writer) error {
+			w.Write(data[:len(data)/2])
+			fmt.Println("halfway")
+			select {} // until killed
+		}
+		if name == "crash-atomic" {
+			return writeAtomic(args[0], 0o644, halfway)
+		}
+		f, err := os.Create(args[0]) // what os.WriteFile does: truncate, then write
+		if err != nil {
+			return err
+		}
+		return halfway(f)
+
+	case "hold": // hold LOCKFILE DURATION: hold a lock
+		d, err := time.ParseDuration(args[1])
+		if err != nil {
+			return err
+		}
+		lock, err := Acquire(args[0], 0)
+		if err != nil {
+			return err
+		}
+		fmt.Println("locked")
+		time.Sleep(d)
+		return lock.Release()
+	}
+	return fmt.Errorf("unknown command %q", name)
+}
+
+// self is a command that runs this program again, with a command
+func self(name string, args ...string) *exec.Cmd {
+	exe, err := os.Executable()
+	must(err)
+	return exec.Command(exe, append([]string{name}, args...)...)
+}
+
+// crash runs a crash command on file, and kills it once it's halfway
+func crash(name, file string) error {
+	cmd := self(name, file)
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return err
+	}
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	line, _ := bufio.NewReader(out).ReadString('\n')
+	if line != "halfway\n" {
+		cmd.Wait()
+		return fmt.Errorf("%s: want halfway; got %q", name, line)
+	}
+	cmd.Process.Kill() // like a crash, or a power cut: no deferred code runs
+	cmd.Wait()
+	return nil
+}
+
+// start writes a progress file with lessons done
+func start(file string, lessons ...string) {
+	p := &Progress{Done: make(map[string]time.Time)}
+	for _, l := range lessons {
+		p.Done[l] = time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+	}
+	must(p.Save(file))
+}
+
+// show prints what's in file, and whether it loads
+func show(file string) {
+	data, _ := os.ReadFile(file)
+	p, err := Load(file)
+	if err != nil {
+		fmt.Printf("   %d bytes: Load fails: %v\n", len(data), errors.Unwrap(err)) // without the path
+		return
+	}
+	fmt.Printf("   %d bytes: Load finds %d lessons\n", len(data), len(p.Done))
+}
+
+func must(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
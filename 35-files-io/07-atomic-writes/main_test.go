@@ -0,0 +1,247 @@
+//go:build unix
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMain runs a command when a test runs the test binary as a child,
+// as self does
+func TestMain(m *testing.M) {
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		if err := command(os.Args[1], os.Args[2:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// temps are the temporary files left next to path
+func temps(t *testing.T, path string) []string {
+	t.Helper()
+	names, err := filepath.Glob(filepath.Join(filepath.Dir(path), tempPattern(path)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return names
+}
+
+func TestWriteFileAtomic(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	for _, data := range []string{"first", "second, longer", ""} {
+		if err := WriteFileAtomic(path, []byte(data), 0o640); err != nil {
+			t.Fatal(err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil || string(got) != data {
+			t.Errorf("want %q; got %q, %v", data, got, err)
+		}
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Mode().Perm() != 0o640 {
+		t.Errorf("want 0640, not CreateTemp's 0600; got %v", info.Mode().Perm())
+	}
+	if left := temps(t, path); len(left) != 0 {
+		t.Errorf("want no temporary files; got %q", left)
+	}
+}
+
+func TestWriteAtomicFails(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.json")
+	if err := WriteFileAtomic(path, []byte("old"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	full := errors.New("no space left on device")
+	err := writeAtomic(path, 0o644, func(w io.Writer) error {
+		io.WriteString(w, "ne")
+		return full
+	})
+	if !errors.Is(err, full) {
+		t.Errorf("want the write's error; got %v", err)
+	}
+	if got, _ := os.ReadFile(path); string(got) != "old" {
+		t.Errorf("want the old contents; got %q", got)
+	}
+	if left := temps(t, path); len(left) != 0 {
+		t.Errorf("want the temporary file removed; got %q", left)
+	}
+
+	if err := WriteFileAtomic(filepath.Join(t.TempDir(), "missing", "data.json"), nil, 0o644); err == nil {
+		t.Error("want an error for a missing directory")
+	}
+}
+
+// TestCrash kills a process halfway through a write, and checks what's
+// left on disk
+func TestCrash(t *testing.T) {
+	tests := []struct {
+		command string
+		loads   bool
+		temps   int
+	}{
+		{"crash-in-place", false, 0},
+		{"crash-atomic", true, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.command, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "progress.json")
+			start(file, "a", "b", "c")
+			if err := crash(tt.command, file); err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := Load(file)
+			if tt.loads && (err != nil || len(p.Done) != 3) {
+				t.Errorf("want the 3 lessons from before the crash; got %v, %v", p, err)
+			}
+			if !tt.loads && err == nil {
+				t.Errorf("want a truncated file; got %v", p)
+			}
+			if left := temps(t, file); len(left) != tt.temps {
+				t.Errorf("want %d temporary files; got %q", tt.temps, left)
+			}
+		})
+	}
+}
+
+func TestUpdateRemovesTemps(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "progress.json")
+	start(file, "a")
+	if err := crash("crash-atomic", file); err != nil {
+		t.Fatal(err)
+	}
+	err := Update(file, func(p *Progress) error {
+		p.Done["b"] = time.Now()
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if left := temps(t, file); len(left) != 0 {
+		t.Errorf("want the crashed writer's file removed; got %q", left)
+	}
+	if p, err := Load(file); err != nil || len(p.Done) != 2 {
+		t.Errorf("want a and b; got %v, %v", p, err)
+	}
+}
+
+func TestUpdateChangeFails(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "progress.json")
+	start(file, "a")
+	before, _ := os.ReadFile(file)
+
+	bad := errors.New("bad lesson")
+	err := Update(file, func(p *Progress) error {
+		p.Done["b"] = time.Now()
+		return bad
+	})
+	if !errors.Is(err, bad) {
+		t.Errorf("want the change's error; got %v", err)
+	}
+	if after, _ := os.ReadFile(file); string(after) != string(before) {
+		t.Errorf("want nothing saved; got %s", after)
+	}
+}
+
+func TestLock(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.lock")
+	first, err := Acquire(path, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// flock locks an open file: a second open file, in this process or
+	// another, can't take it
+	_, err = Acquire(path, 50*time.Millisecond)
+	if !errors.Is(err, ErrLocked) || !strings.Contains(err.Error(), fmt.Sprint(os.Getpid())) {
+		t.Errorf("want ErrLocked, with the holder's pid; got %v", err)
+	}
+
+	time.AfterFunc(100*time.Millisecond, func() { first.Release() })
+	second, err := Acquire(path, 5*time.Second)
+	if err != nil {
+		t.Fatalf("want the lock once it's released; got %v", err)
+	}
+	second.Release()
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("want the lock file left in place; got %v", err)
+	}
+}
+
+func TestLockHolderKilled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "x.lock")
+	holder := self("hold", path, "1h")
+	out, err := holder.StdoutPipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := holder.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer holder.Wait()
+	defer holder.Process.Kill()
+	if line, _ := io.ReadAll(io.LimitReader(out, 7)); string(line) != "locked\n" {
+		t.Fatalf("want the holder locked; got %q", line)
+	}
+
+	if _, err := Acquire(path, 0); !errors.Is(err, ErrLocked) {
+		t.Fatalf("want ErrLocked while it runs; got %v", err)
+	}
+	holder.Process.Kill()
+	holder.Wait()
+	lock, err := Acquire(path, time.Second)
+	if err != nil {
+		t.Fatalf("want the lock after its holder was killed; got %v", err)
+	}
+	lock.Release()
+}
+
+func TestUpdateConcurrent(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "progress.json")
+
+	// goroutines in this process, and other processes, at once
+	var wg sync.WaitGroup
+	for i := range 20 {
+		wg.Go(func() {
+			err := Update(file, func(p *Progress) error {
+				p.Done[fmt.Sprint("goroutine-", i)] = time.Now()
+				return nil
+			})
+			if err != nil {
+				t.Error(err)
+			}
+		})
+	}
+	for i := range 5 {
+		wg.Go(func() {
+			if out, err := self("mark", "-file", file, "-slow", "10ms", fmt.Sprint("process-", i)).CombinedOutput(); err != nil {
+				t.Errorf("%v: %s", err, out)
+			}
+		})
+	}
+	wg.Wait()
+
+	p, err := Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(p.Done) != 25 {
+		t.Errorf("want 25 lessons; got %d", len(p.Done))
+	}
+}
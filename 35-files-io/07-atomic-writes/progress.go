@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// lockWait is how long Update waits for another process to finish
+var lockWait = 5 * time.Second
+
+// Progress is the lessons a learner has finished, and when
+type Progress struct {
+	Done map[string]time.Time `json:"done"`
+}
+
+// Load reads the progress file at path. A missing file is no progress yet
+func Load(path string) (*Progress, error) {
+	p := &Progress{Done: make(map[string]time.Time)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return p, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, p); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// Save writes p to path, atomically
+func (p *Progress) Save(path string) error {
+	data, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	return WriteFileAtomic(path, append(data, '\n'), 0o644)
+}
+
+// Update loads the progress at path, changes it, and saves it, holding
+// the lock file path+".lock" from start to end. Without the lock, two
+// programs can load at once, and both save: the second save loses the
+// first one's change, though each write was atomic
+func Update(path string, change func(*Progress) error) error {
+	lock, err := Acquire(path+".lock", lockWait)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	// With the lock held, no one else is writing: a temporary file is
+	// left from a writer that crashed
+	if err := removeTemps(path); err != nil {
+		return err
+	}
+	p, err := Load(path)
+	if err != nil {
+		return err
+	}
+	if err := change(p); err != nil {
+		return err
+	}
+	return p.Save(path)
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func main() {
+	fmt.Println("Temporary Files and Directories")
+	fmt.Println("===============================")
+	fmt.Println()
+
+	// Example 1: Names
+	fmt.Println("1. os.CreateTemp and os.MkdirTemp put a random string in the name:")
+	dir, err := os.MkdirTemp("", "lesson-*")
+	must(err)
+	defer os.RemoveAll(dir) // everything under it, in one call
+	fmt.Printf("   %-31s %s\n", `MkdirTemp("", "lesson-*")`, dir)
+	fmt.Printf("      in os.TempDir(): %s ($TMPDIR on Unix, %%TMP%% on Windows)\n", os.TempDir())
+	for _, pattern := range []string{"report-*.csv", "report-", "*.json"} {
+		f, err := os.CreateTemp(dir, pattern)
+		must(err)
+		f.Close()
+		fmt.Printf("   %-31s %s\n", fmt.Sprintf("CreateTemp(dir, %q)", pattern), filepath.Base(f.Name()))
+	}
+	fmt.Println("   The * is where the random part goes; with no *, it goes at the end")
+	fmt.Println()
+
+	// Example 2: Who cleans up
+	fmt.Println("2. Cleaning up is the caller's job:")
+	before := count(os.TempDir(), "leak-*")
+	for range 3 {
+		leaky()
+	}
+	fmt.Printf("   leaky() three times:   %d files left in os.TempDir()\n", count(os.TempDir(), "leak-*")-before)
+	for range 3 {
+		must(tidy())
+	}
+	fmt.Printf("   tidy() three times:    %d more\n", count(os.TempDir(), "leak-*")-before-3)
+	removeAll(os.TempDir(), "leak-*")
+	fmt.Println()
+
+	// Example 3: Spooling a stream to disk
+	fmt.Println("3. Spool: a stream that can be read only once, read twice:")
+	stream := strings.NewReader(strings.Repeat("a line of a big upload\n", 50_000)) // a request body, say
+	f, err := Spool(stream, dir, 10<<20)
+	must(err)
+	sum := sha256.New()
+	n, _ := io.Copy(sum, f)
+	fmt.Printf("   first pass:  %d bytes, sha256 %x...\n", n, sum.Sum(nil)[:6])
+	f.Seek(0, io.SeekStart)
+	lines := 0
+	for sc := bufio.NewScanner(f); sc.Scan(); lines++ {
+	}
+	fmt.Printf("   second pass: %d lines\n", lines)
+	must(Discard(f))
+
+	_, err = Spool(strings.NewReader(strings.Repeat("x", 2000)), dir, 1000)
+	fmt.Printf("   too large:   %v; %d spool files left\n", err, count(dir, "spool-*"))
+	fmt.Println()
+
+	// Example 4: Removing an open file
+	fmt.Println("4. Removing a file that's still open:")
+	f, err = os.CreateTemp(dir, "open-*")
+	must(err)
+	fmt.Fprint(f, "still here")
+	err = os.Remove(f.Name())
+	switch {
+	case err != nil:
+		fmt.Printf("   Remove: %v\n", err) // Windows
+	default:
+		f.Seek(0, io.SeekStart)
+		data, _ := io.ReadAll(f)
+		_, statErr := os.Stat(f.Name())
+		fmt.Printf("   on %s, Remove works: the name is gone (%v),\n", runtime.GOOS, statErr != nil)
+		fmt.Printf("   but the open file still reads %q, until it's closed\n", data)
+	}
+	f.Close()
+}
+
+// leaky makes a temporary file and forgets it
+func leaky() {
+	f, err := os.CreateTemp("", "leak-*")
+	if err != nil {
+		return
+	}
+	fmt.Fprintln(f, "scratch work")
+	f.Close()
+}
+
+// tidy does the same work, and removes the file when it's done
+func tidy() error {
+	f, err := os.CreateTemp("", "leak-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	defer f.Close() // deferred calls run last first: Close, then Remove
+
+	_, err = fmt.Fprintln(f, "scratch work")
+	return err
+}
+
+// count is the number of names in dir that match pattern
+func count(dir, pattern string) int {
+	names, _ := filepath.Glob(filepath.Join(dir, pattern))
+	return len(names)
+}
+
+func removeAll(dir, pattern string) {
+	names, _ := filepath.Glob(filepath.Join(dir, pattern))
+	for _, name := range names {
+		os.Remove(name)
+	}
+}
+
+func must(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// names lists what's in dir
+func names(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, e := range entries {
+		out = append(out, e.Name())
+	}
+	return out
+}
+
+func TestSpool(t *testing.T) {
+	// t.TempDir is a new, empty directory for this test, removed when it
+	// ends, pass or fail. Nothing outside it is touched
+	dir := t.TempDir()
+
+	f, err := Spool(strings.NewReader("hello, disk"), dir, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for range 2 { // the file reads again, after a rewind
+		data, err := io.ReadAll(f)
+		if err != nil || string(data) != "hello, disk" {
+			t.Errorf("want hello, disk; got %q, %v", data, err)
+		}
+		f.Seek(0, io.SeekStart)
+	}
+	if filepath.Dir(f.Name()) != dir {
+		t.Errorf("want the file in %s; got %s", dir, f.Name())
+	}
+
+	if err := Discard(f); err != nil {
+		t.Fatal(err)
+	}
+	if left := names(t, dir); len(left) != 0 {
+		t.Errorf("want nothing after Discard; got %q", left)
+	}
+}
+
+func TestSpoolLimit(t *testing.T) {
+	tests := []struct {
+		size int
+		ok   bool
+	}{
+		{0, true},
+		{999, true},
+		{1000, true},
+		{1001, false},
+		{1 << 20, false},
+	}
+	for _, tt := range tests {
+		dir := t.TempDir() // one per case: every call is a new directory
+		f, err := Spool(strings.NewReader(strings.Repeat("x", tt.size)), dir, 1000)
+		if tt.ok {
+			if err != nil {
+				t.Errorf("%d bytes: %v", tt.size, err)
+				continue
+			}
+			Discard(f)
+		} else if !errors.Is(err, ErrTooLarge) {
+			t.Errorf("%d bytes: want ErrTooLarge; got %v", tt.size, err)
+		}
+		if left := names(t, dir); len(left) != 0 {
+			t.Errorf("%d bytes: want nothing left; got %q", tt.size, left)
+		}
+	}
+}
+
+func TestSpoolFails(t *testing.T) {
+	dir := t.TempDir()
+
+	broken := errors.New("connection reset")
+	r := io.MultiReader(strings.NewReader("half an upload"), iotest.ErrReader(broken))
+	if _, err := Spool(r, dir, 1000); !errors.Is(err, broken) {
+		t.Errorf("want the reader's error; got %v", err)
+	}
+	if left := names(t, dir); len(left) != 0 {
+		t.Errorf("want the half-written file removed; got %q", left)
+	}
+
+	if _, err := Spool(strings.NewReader(""), filepath.Join(dir, "missing"), 1000); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("want ErrNotExist for a missing directory; got %v", err)
+	}
+}
+
+func TestSpoolDefaultDir(t *testing.T) {
+	// With dir "", Spool uses os.TempDir, which reads $TMPDIR on Unix.
+	// t.Setenv points it at a directory of the test's own, and restores
+	// it at the end
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	t.Setenv("TMP", dir) // Windows
+
+	f, err := Spool(strings.NewReader("x"), "", 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer Discard(f)
+	if filepath.Dir(f.Name()) != dir {
+		t.Errorf("want the file in %s; got %s", dir, f.Name())
+	}
+}
+
+func TestTidy(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TMPDIR", dir)
+	t.Setenv("TMP", dir)
+
+	if err := tidy(); err != nil {
+		t.Fatal(err)
+	}
+	if left := names(t, dir); len(left) != 0 {
+		t.Errorf("want tidy to leave nothing; got %q", left)
+	}
+	leaky()
+	if left := names(t, dir); len(left) != 1 {
+		t.Errorf("want leaky to leave a file; got %q", left)
+	}
+	// and t.TempDir removes it, at the end of the test
+}
+
+func TestCreateTempPattern(t *testing.T) {
+	dir := t.TempDir()
+	tests := []struct {
+		pattern        string
+		prefix, suffix string
+	}{
+		{"report-*.csv", "report-", ".csv"},
+		{"report-", "report-", ""},
+		{"*.json", "", ".json"},
+	}
+	for _, tt := range tests {
+		f, err := os.CreateTemp(dir, tt.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Close()
+		name := filepath.Base(f.Name())
+		if !strings.HasPrefix(name, tt.prefix) || !strings.HasSuffix(name, tt.suffix) || len(name) <= len(tt.prefix+tt.suffix) {
+			t.Errorf("%q: want %s<random>%s; got %s", tt.pattern, tt.prefix, tt.suffix, name)
+		}
+	}
+
+	if _, err := os.CreateTemp(dir, "a/b-*"); err == nil {
+		t.Error("want an error for a pattern with a path separator")
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// ErrTooLarge means the input was longer than Spool's limit
+var ErrTooLarge = errors.New("input too large")
+
+// Spool copies r into a new temporary file in dir, or in os.TempDir if
+// dir is "", and returns the file rewound to the start. r can be a
+// stream that's read only once, like a request body: the file can be
+// read again and again, and it can be far bigger than memory.
+//
+// The caller owns the file, and must Discard it. If Spool fails, it
+// removes what it created: nothing is left to clean up
+func Spool(r io.Reader, dir string, limit int64) (_ *os.File, err error) {
+	f, err := os.CreateTemp(dir, "spool-*.tmp")
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			Discard(f)
+		}
+	}()
+
+	// One byte past the limit, to tell "exactly the limit" from "over it"
+	n, err := io.Copy(f, io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if n > limit {
+		return nil, fmt.Errorf("spool: %w: over %d bytes", ErrTooLarge, limit)
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Discard closes and removes a temporary file. Close comes first:
+// Windows can't remove a file that's open
+func Discard(f *os.File) error {
+	return errors.Join(f.Close(), os.Remove(f.Name()))
+}
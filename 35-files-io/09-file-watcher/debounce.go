@@ -0,0 +1,101 @@
+package main
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/timing"
+)
+
+// Debounce collects events until none has come for quiet, then sends
+// them as one batch, with one event per file. Saving a file in an editor
+// can be several writes, or a write to a temporary file and a rename; a
+// build started on the first of them would see half the change.
+//
+// The channel is closed when events is closed, after a last batch with
+// whatever was pending, or when ctx is done
+func Debounce(ctx context.Context, events <-chan Event, quiet time.Duration) <-chan []Event {
+	batches := make(chan []Event)
+	go func() {
+		defer close(batches)
+
+		d := timing.NewDebouncer(ctx, quiet)
+		defer d.Stop()
+		// The debouncer only says when: pending belongs to this goroutine.
+		// Each wait is numbered, and its signal says which one ended. A
+		// timer can fire just as an event arrives, and its signal then
+		// comes after the event restarted the wait: it's stale
+		ready := make(chan int)
+		done := make(chan struct{})
+		defer close(done)
+		wait := 0
+		var pending []Event
+
+		send := func() bool {
+			if len(pending) == 0 {
+				return true
+			}
+			select {
+			case batches <- pending:
+				pending = nil
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case e, ok := <-events:
+				if !ok {
+					send()
+					return
+				}
+				pending = merge(pending, e)
+				wait++
+				n := wait
+				d.Do(func() {
+					select {
+					case ready <- n:
+					case <-done:
+					}
+				})
+			case n := <-ready:
+				if n != wait {
+					continue // stale: the wait restarted after it ended
+				}
+				if !send() {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return batches
+}
+
+// merge adds e to a batch, as one event per file: what happened between
+// the file's state before the batch and its state now
+func merge(batch []Event, e Event) []Event {
+	i := slices.IndexFunc(batch, func(b Event) bool { return b.Path == e.Path })
+	if i < 0 {
+		batch = append(batch, e)
+		slices.SortFunc(batch, func(a, b Event) int { return strings.Compare(a.Path, b.Path) })
+		return batch
+	}
+
+	switch before := batch[i].Op; {
+	case before == Created && e.Op == Removed:
+		return slices.Delete(batch, i, i+1) // it came and went
+	case before == Created:
+		// still new
+	case before == Removed && e.Op == Created:
+		batch[i].Op = Modified // replaced
+	default:
+		batch[i].Op = e.Op
+	}
+	return batch
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+func main() {
+	dir := flag.String("dir", "", "serve this directory with live reload, instead of running the examples")
+	addr := flag.String("addr", "localhost:8080", "with -dir, the address to serve on")
+	interval := flag.Duration("interval", 250*time.Millisecond, "how often to poll")
+	quiet := flag.Duration("quiet", 100*time.Millisecond, "how long changes must stop before a reload")
+	flag.Parse()
+
+	if *dir != "" {
+		serve(*dir, *addr, *interval, *quiet)
+		return
+	}
+
+	fmt.Println("A Polling File Watcher")
+	fmt.Println("======================")
+	fmt.Println()
+
+	tmp, err := os.MkdirTemp("", "watch")
+	must(err)
+	defer os.RemoveAll(tmp)
+	write := func(name, data string) { must(os.WriteFile(filepath.Join(tmp, name), []byte(data), 0o644)) }
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	start := time.Now()
+	at := func() time.Duration { return time.Since(start).Round(10 * time.Millisecond) }
+
+	// Example 1: Polling
+	fmt.Println("1. Polling every 50ms:")
+	write("notes.txt", "first")
+	events := Watch(ctx, os.DirFS(tmp), 50*time.Millisecond)
+	go func() {
+		time.Sleep(120 * time.Millisecond)
+		write("todo.txt", "buy milk")
+		time.Sleep(120 * time.Millisecond)
+		write("notes.txt", "second version")
+		time.Sleep(120 * time.Millisecond)
+		os.Remove(filepath.Join(tmp, "todo.txt"))
+	}()
+	start = time.Now()
+	for range 3 {
+		e := <-events
+		fmt.Printf("   %6v  %v\n", at(), e)
+	}
+	cancel()
+	fmt.Println()
+
+	// Example 2: Debouncing
+	fmt.Println("2. An editor saving a file: a burst of writes, and one batch:")
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	write("index.html", "")
+	write("style.css", "")
+	events = Watch(ctx, os.DirFS(tmp), 20*time.Millisecond)
+	batches := Debounce(ctx, events, 100*time.Millisecond)
+	go func() {
+		// a backup, then the page in three writes, then the backup
+		// removed: what many editors do on every save
+		write("index.html~", "old page")
+		for i, part := range []string{"<h1>", "<h1>Hello", "<h1>Hello</h1>"} {
+			time.Sleep(30 * time.Millisecond)
+			write("index.html", part)
+			write("style.css", fmt.Sprint("h1 { margin: ", i, "px }"))
+		}
+		os.Remove(filepath.Join(tmp, "index.html~"))
+	}()
+	start = time.Now()
+	batch := <-batches
+	fmt.Printf("   %6v  %v\n", at(), batch)
+	cancel()
+	fmt.Println()
+
+	// Example 3: Live reload
+	fmt.Println("3. Live reload: a page listening, and a file changed:")
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	lr := NewLiveReload(os.DirFS(tmp))
+	srv := httptest.NewServer(lr.Handler())
+	defer srv.Close()
+	go func() {
+		for batch := range Debounce(ctx, Watch(ctx, os.DirFS(tmp), 20*time.Millisecond), 50*time.Millisecond) {
+			lr.Reload(batch)
+		}
+	}()
+
+	resp, err := http.Get(srv.URL + "/")
+	must(err)
+	page, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	fmt.Printf("   GET /: %s\n", page)
+
+	resp, err = http.Get(srv.URL + "/_livereload")
+	must(err)
+	defer resp.Body.Close()
+	for lr.Clients() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	start = time.Now()
+	write("style.css", "h1 { color: teal }")
+	sc := bufio.NewScanner(resp.Body)
+	for sc.Scan() {
+		if line := sc.Text(); line != "" {
+			fmt.Printf("   %6v  the page gets %q, and reloads\n", at(), line)
+			break
+		}
+	}
+	cancel()
+	fmt.Println()
+
+	// Example 4: What a poll costs
+	fmt.Println("4. What a poll costs:")
+	for _, n := range []int{100, 1000, 10000} {
+		dir := filepath.Join(tmp, fmt.Sprint(n))
+		for i := range n {
+			sub := filepath.Join(dir, fmt.Sprint(i/100))
+			must(os.MkdirAll(sub, 0o755))
+			must(os.WriteFile(filepath.Join(sub, fmt.Sprint(i)), nil, 0o644))
+		}
+		start := time.Now()
+		snapshot(os.DirFS(dir))
+		fmt.Printf("   %5d files: %v a poll\n", n, time.Since(start).Round(10*time.Microsecond))
+	}
+}
+
+// serve serves dir on addr, and reloads the pages open in the browser
+// when a file in it changes
+func serve(dir, addr string, interval, quiet time.Duration) {
+	fsys := os.DirFS(dir)
+	lr := NewLiveReload(fsys)
+	go func() {
+		for batch := range Debounce(context.Background(), Watch(context.Background(), fsys, interval), quiet) {
+			log.Printf("reloading %d page(s): %v", lr.Clients(), batch)
+			lr.Reload(batch)
+		}
+	}()
+	log.Printf("serving %s on http://%s", dir, addr)
+	log.Fatal(http.ListenAndServe(addr, lr.Handler()))
+}
+
+func must(err error) {
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"testing/synctest"
+	"time"
+)
+
+// memFS is a MapFS that a test can change while Watch reads it
+type memFS struct {
+	mu sync.Mutex
+	m  fstest.MapFS
+}
+
+func newMemFS() *memFS { return &memFS{m: make(fstest.MapFS)} }
+
+func (f *memFS) Open(name string) (fs.File, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.m.Open(name)
+}
+
+// write replaces a file, with the time now as its ModTime: in a synctest
+// bubble, the bubble's fake time
+func (f *memFS) write(name, data string) {
+	f.writeAt(name, data, time.Now())
+}
+
+func (f *memFS) writeAt(name, data string, mod time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.m[name] = &fstest.MapFile{Data: []byte(data), ModTime: mod}
+}
+
+func (f *memFS) remove(name string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.m, name)
+}
+
+// at is an event and when it came, since the start of a test
+type at struct {
+	d time.Duration
+	e string
+}
+
+func (a at) String() string { return fmt.Sprintf("%v:%s", a.d, a.e) }
+
+// collect reads everything from ch until it's closed, with the time
+func collect[T any](ch <-chan T) []at {
+	start := time.Now()
+	var got []at
+	for v := range ch {
+		got = append(got, at{time.Since(start), fmt.Sprint(v)})
+	}
+	return got
+}
+
+func ms(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+func TestWatch(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		fsys := newMemFS()
+		fsys.write("old.txt", "here before")
+		ctx, cancel := context.WithTimeout(t.Context(), ms(1000))
+		defer cancel()
+		events := Watch(ctx, fsys, ms(100))
+
+		go func() {
+			time.Sleep(ms(50))
+			fsys.write("a.txt", "a")
+			fsys.write("dir/b.txt", "b")
+			time.Sleep(ms(200))
+			fsys.write("a.txt", "A") // same size, new time
+			time.Sleep(ms(200))
+			fsys.remove("old.txt")
+			fsys.remove("dir/b.txt")
+		}()
+
+		// Every change shows up at the next poll: 100ms, 300ms, 500ms
+		want := []at{
+			{ms(100), "created a.txt"},
+			{ms(100), "created dir/b.txt"},
+			{ms(300), "modified a.txt"},
+			{ms(500), "removed dir/b.txt"},
+			{ms(500), "removed old.txt"},
+		}
+		if got := collect(events); !slices.Equal(got, want) {
+			t.Errorf("want %v;\ngot  %v", want, got)
+		}
+	})
+}
+
+func TestWatchStamps(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		fsys := newMemFS()
+		mod := time.Now()
+		fsys.writeAt("f", "1234", mod)
+		ctx, cancel := context.WithTimeout(t.Context(), ms(450))
+		defer cancel()
+		events := Watch(ctx, fsys, ms(100))
+
+		go func() {
+			time.Sleep(ms(50))
+			fsys.writeAt("f", "5678", mod) // same size, same time: invisible to a poll
+			time.Sleep(ms(100))
+			fsys.writeAt("f", "12345", mod) // a new size
+			time.Sleep(ms(100))
+			fsys.writeAt("f", "12345", mod.Add(time.Second)) // a new time
+		}()
+
+		want := []at{{ms(200), "modified f"}, {ms(300), "modified f"}}
+		if got := collect(events); !slices.Equal(got, want) {
+			t.Errorf("want %v;\ngot  %v", want, got)
+		}
+	})
+}
+
+func TestWatchIgnores(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		fsys := newMemFS()
+		ctx, cancel := context.WithTimeout(t.Context(), ms(250))
+		defer cancel()
+		events := Watch(ctx, fsys, ms(100))
+
+		for _, name := range []string{".git/HEAD", ".page.html.swp", "#page.html#", "page.html~", "sub/.hidden", "page.html"} {
+			fsys.write(name, "x")
+		}
+		want := []at{{ms(100), "created page.html"}}
+		if got := collect(events); !slices.Equal(got, want) {
+			t.Errorf("want %v;\ngot  %v", want, got)
+		}
+	})
+}
+
+func TestWatchStops(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		events := Watch(ctx, newMemFS(), ms(100))
+		time.Sleep(ms(250))
+		cancel()
+		synctest.Wait()
+		select {
+		case _, ok := <-events:
+			if ok {
+				t.Error("want no events")
+			}
+		default:
+			t.Error("want the channel closed after cancel")
+		}
+	})
+}
+
+func TestMerge(t *testing.T) {
+	tests := []struct {
+		ops  []Op
+		want string
+	}{
+		{[]Op{Created, Modified, Modified}, "[created f]"},
+		{[]Op{Created, Removed}, "[]"},
+		{[]Op{Modified, Removed}, "[removed f]"},
+		{[]Op{Removed, Created}, "[modified f]"},
+		{[]Op{Modified, Modified}, "[modified f]"},
+		{[]Op{Created, Removed, Created}, "[created f]"},
+	}
+	for _, tt := range tests {
+		var batch []Event
+		for _, op := range tt.ops {
+			batch = merge(batch, Event{"f", op})
+		}
+		if got := fmt.Sprint(batch); got != tt.want {
+			t.Errorf("%v: want %s; got %s", tt.ops, tt.want, got)
+		}
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		events := make(chan Event)
+		batches := Debounce(t.Context(), events, ms(100))
+
+		go func() {
+			defer close(events)
+			// a burst: 5 events, 30ms apart
+			for i, e := range []Event{{"b", Created}, {"a", Modified}, {"b", Modified}, {"tmp", Created}, {"tmp", Removed}} {
+				if i > 0 {
+					time.Sleep(ms(30))
+				}
+				events <- e
+			}
+			time.Sleep(ms(500))
+			events <- Event{"a", Removed} // then one more, and the end
+		}()
+
+		want := []at{
+			{ms(4*30 + 100), "[modified a created b]"},
+			{ms(4*30 + 500), "[removed a]"}, // at close, not after quiet
+		}
+		if got := collect(batches); !slices.Equal(got, want) {
+			t.Errorf("want %v;\ngot  %v", want, got)
+		}
+	})
+}
+
+// A timer that fires at the moment an event arrives must not flush the
+// new event early. Which one the debouncer sees first varies, so try it
+// many times
+func TestDebounceStaleFire(t *testing.T) {
+	for range 50 {
+		synctest.Test(t, func(t *testing.T) {
+			events := make(chan Event)
+			batches := Debounce(t.Context(), events, ms(100))
+
+			go func() {
+				defer close(events)
+				events <- Event{"a", Created}
+				time.Sleep(ms(100)) // as the wait for a ends
+				events <- Event{"b", Created}
+				time.Sleep(ms(1000))
+			}()
+
+			for _, got := range collect(batches) {
+				if strings.Contains(got.e, "b") && got.d < ms(200) {
+					t.Fatalf("b flushed at %v, before 100ms of quiet", got.d)
+				}
+			}
+		})
+	}
+}
+
+func TestDebounceCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(t.Context())
+		events := make(chan Event)
+		batches := Debounce(ctx, events, ms(100))
+
+		events <- Event{"a", Created}
+		cancel()
+		if got := collect(batches); len(got) != 0 {
+			t.Errorf("want nothing after cancel; got %v", got)
+		}
+	})
+}
+
+func TestWatchAndDebounce(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		fsys := newMemFS()
+		fsys.write("page.html", "")
+		ctx, cancel := context.WithTimeout(t.Context(), ms(2000))
+		defer cancel()
+		batches := Debounce(ctx, Watch(ctx, fsys, ms(50)), ms(200))
+
+		// an editor's save, spread over three polls
+		go func() {
+			time.Sleep(ms(40))
+			fsys.write("page.html~", "backup")
+			for _, part := range []string{"<h1>", "<h1>Hi", "<h1>Hi</h1>"} {
+				time.Sleep(ms(40))
+				fsys.write("page.html", part)
+			}
+			fsys.remove("page.html~")
+		}()
+
+		// the last write is at 160ms, seen at 200ms, and quiet at 400ms
+		want := []at{{ms(400), "[modified page.html]"}}
+		if got := collect(batches); !slices.Equal(got, want) {
+			t.Errorf("want %v;\ngot  %v", want, got)
+		}
+	})
+}
+
+func TestLiveReloadPage(t *testing.T) {
+	fsys := fstest.MapFS{
+		"index.html":     {Data: []byte("<html><body><h1>Hi</h1></body></html>")},
+		"docs/bare.html": {Data: []byte("<p>no body tag</p>")},
+		"style.css":      {Data: []byte("h1 {}")},
+	}
+	h := NewLiveReload(fsys).Handler()
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/", 200, "<h1>Hi</h1>" + script + "</body></html>"},
+		{"/index.html", 200, script + "</body>"},
+		{"/docs/bare.html", 200, "<p>no body tag</p>" + script},
+		{"/style.css", 200, "h1 {}"},
+		{"/missing.html", 404, "not found"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", tt.path, nil))
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("GET %s: want %d with %q; got %d: %s", tt.path, tt.status, tt.want, w.Code, w.Body)
+		}
+	}
+}
+
+func TestLiveReloadEvents(t *testing.T) {
+	lr := NewLiveReload(fstest.MapFS{})
+	srv := httptest.NewServer(lr.Handler())
+	defer srv.Close()
+
+	var pages []*bufio.Reader
+	for range 2 {
+		resp, err := http.Get(srv.URL + "/_livereload")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+			t.Errorf("want an event stream; got %q", ct)
+		}
+		pages = append(pages, bufio.NewReader(resp.Body))
+	}
+	for lr.Clients() < 2 {
+		time.Sleep(time.Millisecond)
+	}
+
+	lr.Reload([]Event{{"a.css", Modified}, {"b.html", Created}})
+	for i, page := range pages {
+		line, err := page.ReadString('\n')
+		if err != nil || line != "data: a.css b.html\n" {
+			t.Errorf("page %d: want a reload; got %q, %v", i, line, err)
+		}
+	}
+
+	// a page that's closed stops listening
+	srv.CloseClientConnections()
+	for lr.Clients() > 0 {
+		time.Sleep(time.Millisecond)
+	}
+	lr.Reload(nil) // with no one to tell
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+	"sync"
+)
+
+// script is added to every HTML page: it listens for reloads, and
+// reloads the page
+const script = `<script>new EventSource("/_livereload").onmessage = () => location.reload()</script>`
+
+// LiveReload serves a directory for development, and reloads the pages
+// open in the browser when its files change
+type LiveReload struct {
+	fsys fs.FS
+
+	mu      sync.Mutex
+	clients map[chan string]bool
+}
+
+func NewLiveReload(fsys fs.FS) *LiveReload {
+	return &LiveReload{fsys: fsys, clients: make(map[chan string]bool)}
+}
+
+func (lr *LiveReload) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /_livereload", lr.events)
+	files := http.FileServerFS(lr.fsys)
+	mux.HandleFunc("GET /", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/")
+		if name == "" || strings.HasSuffix(name, "/") {
+			name += "index.html"
+		}
+		if path.Ext(name) != ".html" {
+			files.ServeHTTP(w, r)
+			return
+		}
+		lr.page(w, r, name)
+	})
+	return mux
+}
+
+// page serves an HTML page, with the script before </body>
+func (lr *LiveReload) page(w http.ResponseWriter, r *http.Request, name string) {
+	data, err := fs.ReadFile(lr.fsys, name)
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	if i := bytes.LastIndex(data, []byte("</body>")); i >= 0 {
+		data = append(data[:i:i], append([]byte(script), data[i:]...)...)
+	} else {
+		data = append(data, script...)
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.Write(data)
+}
+
+// events is a server-sent event stream, with an event for every reload
+func (lr *LiveReload) events(w http.ResponseWriter, r *http.Request) {
+	ch := make(chan string, 1)
+	lr.mu.Lock()
+	lr.clients[ch] = true
+	lr.mu.Unlock()
+	defer func() {
+		lr.mu.Lock()
+		delete(lr.clients, ch)
+		lr.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	http.NewResponseController(w).Flush()
+
+	for {
+		select {
+		case files := <-ch:
+			fmt.Fprintf(w, "data: %s\n\n", files)
+			http.NewResponseController(w).Flush()
+		case <-r.Context().Done():
+			return
+		}
+	}
+}
+
+// Reload tells every open page to reload, after the changes in batch
+func (lr *LiveReload) Reload(batch []Event) {
+	var files []string
+	for _, e := range batch {
+		files = append(files, e.Path)
+	}
+	msg := strings.Join(files, " ")
+
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	for ch := range lr.clients {
+		select {
+		case ch <- msg:
+		default: // it has a reload waiting already: one is enough
+		}
+	}
+}
+
+// Clients is the number of pages listening for reloads
+func (lr *LiveReload) Clients() int {
+	lr.mu.Lock()
+	defer lr.mu.Unlock()
+	return len(lr.clients)
+}
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Op is what happened to a file
+type Op int
+
+const (
+	Created Op = iota + 1
+	Modified
+	Removed
+)
+
+func (op Op) String() string {
+	switch op {
+	case Created:
+		return "created"
+	case Modified:
+		return "modified"
+	case Removed:
+		return "removed"
+	}
+	return "unknown"
+}
+
+// Event is a change to one file
+type Event struct {
+	Path string
+	Op   Op
+}
+
+func (e Event) String() string { return e.Op.String() + " " + e.Path }
+
+// stamp is what a poll knows about a file. A change to either is a
+// change to the file
+type stamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// Watch polls the files in fsys every interval, and sends a change to
+// any of them as an Event, until ctx is done. Then it closes the channel.
+//
+// Names that ignored reports are skipped, like .git, and the swap and
+// backup files editors write while saving. A poll that fails, say
+// because the directory is gone for a moment, is skipped too, and the
+// next one compares with the last poll that worked
+func Watch(ctx context.Context, fsys fs.FS, interval time.Duration) <-chan Event {
+	events := make(chan Event)
+	// The first snapshot is taken before Watch returns: a change made
+	// after that is never missed
+	last, _ := snapshot(fsys)
+	go func() {
+		defer close(events)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+
+			now, err := snapshot(fsys)
+			if err != nil {
+				continue
+			}
+			for _, e := range diff(last, now) {
+				select {
+				case events <- e:
+				case <-ctx.Done():
+					return
+				}
+			}
+			last = now
+		}
+	}()
+	return events
+}
+
+// snapshot stats every file in fsys
+func snapshot(fsys fs.FS) (map[string]stamp, error) {
+	files := make(map[string]stamp)
+	err := fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if path != "." && ignored(d.Name()) {
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil // removed since the directory was read
+		}
+		files[path] = stamp{info.ModTime(), info.Size()}
+		return nil
+	})
+	return files, err
+}
+
+// ignored reports whether to skip a file or directory: hidden ones, like
+// .git and Vim's .notes.txt.swp, and Emacs and Vim backups, like #notes.txt#
+// and notes.txt~
+func ignored(name string) bool {
+	return strings.HasPrefix(name, ".") || strings.HasPrefix(name, "#") || strings.HasSuffix(name, "~")
+}
+
+// diff is the changes from one snapshot to the next, in path order
+func diff(old, now map[string]stamp) []Event {
+	var events []Event
+	for path, s := range now {
+		switch o, ok := old[path]; {
+		case !ok:
+			events = append(events, Event{path, Created})
+		case !o.modTime.Equal(s.modTime) || o.size != s.size:
+			events = append(events, Event{path, Modified})
+		}
+	}
+	for path := range old {
+		if _, ok := now[path]; !ok {
+			events = append(events, Event{path, Removed})
+		}
+	}
+	slices.SortFunc(events, func(a, b Event) int { return strings.Compare(a.Path, b.Path) })
+	return events
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing/fstest"
+
+	"github.com/inancgumus/learngo/pkg/config"
+)
+
+//go:embed app.toml
+var appTOML []byte
+
+// files is the working directory of the examples
+var files = fstest.MapFS{
+	"app.toml": {Data: appTOML},
+	"prod.json": {Data: []byte(`{
+		"http": {"addr": ":443", "origins": ["https://app.example", "https://admin.example"]},
+		"db": {"url": "postgres://db.internal/app", "max_conns": 50}
+	}`)},
+	"broken.toml": {Data: []byte("[http]\ntimeout = soon\n[db]\nmax_conns = 500\n")},
+}
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "show" {
+		show(os.Args[2:])
+		return
+	}
+
+	fmt.Println("Configuration from Defaults, Files, Env, and Flags")
+	fmt.Println("==================================================")
+	fmt.Println()
+
+	// Example 1: Defaults and a file
+	fmt.Println("1. The defaults, and app.toml:")
+	run(env(), nil)
+	fmt.Println()
+
+	// Example 2: The environment beats the file
+	fmt.Println("2. APP_HTTP_ADDR=:9000 APP_DB_MAX_CONNS=20:")
+	run(env("APP_HTTP_ADDR=:9000", "APP_DB_MAX_CONNS=20"), nil)
+	fmt.Println()
+
+	// Example 3: Flags beat everything
+	fmt.Println("3. The same environment, and -http.addr :7000 -debug:")
+	run(env("APP_HTTP_ADDR=:9000", "APP_DB_MAX_CONNS=20"), []string{"-http.addr", ":7000", "-debug"})
+	fmt.Println()
+
+	// Example 4: Another file
+	fmt.Println("4. APP_CONFIG=prod.json, a JSON file instead:")
+	run(env("APP_CONFIG=prod.json"), nil)
+	fmt.Println()
+
+	// Example 5: Every mistake at once
+	fmt.Println("5. A broken file, a bad variable, and no db.url:")
+	run(env("APP_CONFIG=broken.toml", "APP_DEBUG=yes"), nil)
+	fmt.Println("   -config missing.toml:")
+	run(env(), []string{"-config", "missing.toml"})
+	fmt.Println()
+
+	// Example 6: Passing the settings on
+	fmt.Println("6. The settings go to constructors, not into a context:")
+	s, _, _ := Load(files, env(), nil)
+	srv := NewServer(s)
+	fmt.Printf("   NewServer(s): Addr=%s ReadTimeout=%v\n", srv.Addr, srv.ReadTimeout)
+}
+
+// run loads the settings, and prints each one and where it came from
+func run(getenv func(string) (string, bool), args []string) {
+	_, c, err := Load(files, getenv, args)
+	if err != nil {
+		fmt.Printf("   error:\n      %s\n", strings.ReplaceAll(err.Error(), "\n", "\n      "))
+		return
+	}
+	printSettings(c)
+}
+
+func printSettings(c *config.Config) {
+	for _, key := range []string{"http.addr", "http.timeout", "http.origins", "db.url", "db.max_conns", "debug"} {
+		s, _ := c.Lookup(key)
+		fmt.Printf("   %-13s %-42s %s\n", key, s.Raw, s.Origin)
+	}
+}
+
+// env is an environment with only vars in it, for os.LookupEnv
+func env(vars ...string) func(string) (string, bool) {
+	m := make(map[string]string)
+	for _, kv := range vars {
+		k, v, _ := strings.Cut(kv, "=")
+		m[k] = v
+	}
+	return func(name string) (string, bool) {
+		v, ok := m[name]
+		return v, ok
+	}
+}
+
+// NewServer takes its settings as an argument. Compare 04-context-values
+// in the context section, which pulls them out of a context
+func NewServer(s Settings) *http.Server {
+	return &http.Server{Addr: s.Addr, ReadTimeout: s.Timeout, WriteTimeout: s.Timeout}
+}
+
+// show loads the settings from the real environment, the current
+// directory, and args, and prints them: go run . show -debug
+func show(args []string) {
+	_, c, err := Load(os.DirFS("."), os.LookupEnv, args)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	printSettings(c)
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/config"
+)
+
+func TestLoad(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		args []string
+		want Settings
+	}{
+		{
+			"file", nil, nil,
+			Settings{":8080", 10 * time.Second, []string{"https://app.example"}, "postgres://localhost/app", 10, false},
+		},
+		{
+			"env", []string{"APP_HTTP_TIMEOUT=1m", "APP_HTTP_ORIGINS=a, b"}, nil,
+			Settings{":8080", time.Minute, []string{"a", "b"}, "postgres://localhost/app", 10, false},
+		},
+		{
+			"flags", []string{"APP_HTTP_ADDR=:9000", "APP_DEBUG=true"}, []string{"-http.addr=:7000", "-db.max_conns", "3"},
+			Settings{":7000", 10 * time.Second, []string{"https://app.example"}, "postgres://localhost/app", 3, true},
+		},
+		{
+			"json", []string{"APP_CONFIG=prod.json"}, nil,
+			Settings{":443", 5 * time.Second, []string{"https://app.example", "https://admin.example"}, "postgres://db.internal/app", 50, false},
+		},
+		{
+			"flag names the file", []string{"APP_CONFIG=missing.toml"}, []string{"-config", "prod.json"},
+			Settings{":443", 5 * time.Second, []string{"https://app.example", "https://admin.example"}, "postgres://db.internal/app", 50, false},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, _, err := Load(files, env(tt.env...), tt.args)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !equal(got, tt.want) {
+				t.Errorf("want %+v;\ngot  %+v", tt.want, got)
+			}
+		})
+	}
+}
+
+func equal(a, b Settings) bool {
+	return slices.Equal(a.Origins, b.Origins) &&
+		a.Addr == b.Addr && a.Timeout == b.Timeout && a.DBURL == b.DBURL &&
+		a.MaxConns == b.MaxConns && a.Debug == b.Debug
+}
+
+func TestLoadOptionalFile(t *testing.T) {
+	// No app.toml: the defaults and the environment are enough
+	got, _, err := Load(fstest.MapFS{}, env("APP_DB_URL=sqlite://app.db"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Addr != "localhost:8080" || got.DBURL != "sqlite://app.db" || got.MaxConns != 4 {
+		t.Errorf("want the defaults; got %+v", got)
+	}
+
+	// A file asked for by name must exist
+	if _, _, err := Load(files, env("APP_CONFIG=nope.toml"), nil); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("$APP_CONFIG: want a missing file reported; got %v", err)
+	}
+	if _, _, err := Load(files, env(), []string{"-config", "nope.toml"}); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("-config: want a missing file reported; got %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	_, _, err := Load(files, env("APP_CONFIG=broken.toml", "APP_DEBUG=yes"), nil)
+	want := []string{"http.timeout", "db.url", "debug", "db.max_conns"}
+	var got []string
+	for _, e := range err.(interface{ Unwrap() []error }).Unwrap() {
+		var ce *config.Error
+		if !errors.As(e, &ce) {
+			t.Fatalf("want *config.Error; got %T", e)
+		}
+		got = append(got, ce.Key)
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("want an error for each of %v; got %v", want, got)
+	}
+	if !errors.Is(err, config.ErrMissing) {
+		t.Error("want db.url missing")
+	}
+
+	if _, _, err := Load(files, env(), []string{"-nope"}); err == nil || !strings.Contains(err.Error(), "-nope") {
+		t.Errorf("want unknown flags refused; got %v", err)
+	}
+}
+
+func TestLoadFromDisk(t *testing.T) {
+	// The embedded app.toml is the one in this directory
+	got, c, err := Load(os.DirFS("."), env(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Addr != ":8080" {
+		t.Errorf("want app.toml read; got %+v", got)
+	}
+	if s, _ := c.Lookup("db.url"); s.Source != config.File || s.Origin != "app.toml:10" {
+		t.Errorf("want db.url from app.toml:10; got %+v", s)
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"io"
+	"io/fs"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/config"
+)
+
+// Settings is the program's configuration, read once at startup. The
+// parts of the program get the fields they need as arguments, not from
+// a context, and not from globals
+type Settings struct {
+	Addr     string
+	Timeout  time.Duration
+	Origins  []string
+	DBURL    string
+	MaxConns int
+	Debug    bool
+}
+
+// defaults are the values when nothing else sets them. db.url has none:
+// it's required
+var defaults = map[string]string{
+	"http.addr":    "localhost:8080",
+	"http.timeout": "5s",
+	"http.origins": "",
+	"db.max_conns": "4",
+	"debug":        "false",
+}
+
+// Load reads the settings from the defaults, the file, the environment,
+// and the flags in args, in that order of precedence. getenv is
+// os.LookupEnv in the program, and a map in the tests.
+//
+// The file is -config, or $APP_CONFIG, or app.toml. It's optional only
+// when no one asked for it by name
+func Load(fsys fs.FS, getenv func(string) (string, bool), args []string) (Settings, *config.Config, error) {
+	flags := flag.NewFlagSet("config", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	// The flags have empty defaults: a flag that isn't set must not hide
+	// the file and the environment
+	flags.String("config", "", "the settings file")
+	flags.String("http.addr", "", "the address to serve on")
+	flags.String("http.timeout", "", "the request timeout")
+	flags.String("http.origins", "", "the allowed origins, comma separated")
+	flags.String("db.url", "", "the database URL")
+	flags.String("db.max_conns", "", "the most database connections")
+	flags.Bool("debug", false, "debug logging")
+	if err := flags.Parse(args); err != nil {
+		return Settings{}, nil, err
+	}
+
+	c := config.New()
+	c.SetDefaults(defaults)
+	c.UseEnv("APP_", getenv)
+	c.LoadFlags(flags)
+
+	// The file's name is a setting too, from a flag or the environment
+	file, named := c.Lookup("config")
+	if !named {
+		file.Raw = "app.toml"
+	}
+	if err := c.LoadFile(fsys, file.Raw); err != nil && (named || !errors.Is(err, fs.ErrNotExist)) {
+		return Settings{}, c, err
+	}
+
+	b := config.NewBinder(c)
+	s := Settings{
+		Addr:     config.Bind[string](b, "http.addr"),
+		Timeout:  config.Bind[time.Duration](b, "http.timeout"),
+		Origins:  config.Bind[[]string](b, "http.origins"),
+		DBURL:    config.Bind[string](b, "db.url"),
+		MaxConns: config.Bind[int](b, "db.max_conns"),
+		Debug:    config.Bind[bool](b, "debug"),
+	}
+	b.Check("http.timeout", s.Timeout > 0, "must be more than 0")
+	b.Check("db.max_conns", s.MaxConns >= 1 && s.MaxConns <= 100, "must be from 1 to 100")
+	return s, c, b.Err()
+}
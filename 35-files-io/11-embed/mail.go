@@ -0,0 +1,65 @@
+package main
+
+import (
+	"embed"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"text/template"
+)
+
+// templates are the emails, and the partials they share. The
+// directory has templates/emails/_draft.txt too, which isn't embedded:
+// embedding a directory leaves out names that start with . or _, unless
+// the pattern starts with all:
+//
+//go:embed templates
+var templates embed.FS
+
+//go:embed all:templates
+var allTemplates embed.FS
+
+// Mailer renders emails from the templates in a file system: the
+// embedded one in production, or a directory on disk while editing them
+type Mailer struct {
+	emails map[string]*template.Template
+}
+
+// NewMailer parses one template set per email: the partials, and the
+// email. Every email defines "subject", so in one shared set, the last
+// one parsed would win for all of them.
+//
+// A template that doesn't parse stops the program at startup, not when
+// the first email is sent
+func NewMailer(fsys fs.FS) (*Mailer, error) {
+	names, err := fs.Glob(fsys, "templates/emails/*.txt")
+	if err != nil {
+		return nil, err
+	}
+	m := &Mailer{emails: make(map[string]*template.Template)}
+	for _, name := range names {
+		base := path.Base(name)
+		t, err := template.New(base).ParseFS(fsys, "templates/partials/*.txt", name)
+		if err != nil {
+			return nil, err
+		}
+		m.emails[strings.TrimSuffix(base, ".txt")] = t
+	}
+	return m, nil
+}
+
+// Render writes the email name, with its subject first
+func (m *Mailer) Render(w io.Writer, name string, data any) error {
+	t, ok := m.emails[name]
+	if !ok {
+		return fmt.Errorf("no email %q", name)
+	}
+	var subject strings.Builder
+	if err := t.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return err
+	}
+	fmt.Fprintf(w, "Subject: %s\n\n", subject.String())
+	return t.ExecuteTemplate(w, name+".txt", data)
+}
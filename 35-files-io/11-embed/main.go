@@ -0,0 +1,148 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+
+	_ "modernc.org/sqlite" // a pure-Go SQLite driver, registered as "sqlite"
+)
+
+func main() {
+	fmt.Println("go:embed Beyond Static Files")
+	fmt.Println("============================")
+	fmt.Println()
+
+	// Example 1: What's in the binary
+	fmt.Println("1. Embedded in the binary:")
+	for _, fsys := range []fs.FS{migrations, templates} {
+		fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+			if err == nil && !d.IsDir() {
+				info, _ := d.Info()
+				fmt.Printf("   %-36s %4d bytes\n", name, info.Size())
+			}
+			return err
+		})
+	}
+	fmt.Println()
+
+	// Example 2: Migrations
+	fmt.Println("2. Migrating a new SQLite database, twice:")
+	dir, err := os.MkdirTemp("", "embed")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	migrate(filepath.Join(dir, "app.db"))
+	fmt.Println()
+
+	// Example 3: Templates
+	fmt.Println("3. An email from the embedded templates:")
+	mailer, err := NewMailer(templates)
+	if err != nil {
+		log.Fatal(err)
+	}
+	var email strings.Builder
+	mailer.Render(&email, "digest", map[string]any{
+		"Name":  "Ada",
+		"Posts": []string{"Embedding files", "Escaping </script>"},
+	})
+	printIndented(email.String())
+	fmt.Println()
+
+	// Example 4: Build time against run time
+	fmt.Println("4. A template edited on disk, after the build:")
+	editTemplates(dir)
+	fmt.Println()
+
+	// Example 5: What embed leaves out, and what it doesn't keep
+	fmt.Println("5. The fine print:")
+	for _, f := range []struct {
+		pattern string
+		fsys    fs.FS
+	}{{"templates", templates}, {"all:templates", allTemplates}} {
+		names, _ := fs.Glob(f.fsys, "templates/emails/*")
+		fmt.Printf("   //go:embed %-14s emails: %s\n", f.pattern, strings.Join(names, " "))
+	}
+	info, _ := fs.Stat(templates, "templates/emails/welcome.txt")
+	fmt.Printf("   ModTime of an embedded file: %v (IsZero=%t)\n", info.ModTime(), info.ModTime().IsZero())
+	fmt.Printf("   Mode of an embedded file:    %v, and embed.FS has no way to write\n", info.Mode())
+}
+
+// migrate applies the embedded migrations to a new database, and again
+// to the same one; then it pretends a newer binary migrated it
+func migrate(path string) {
+	ctx := context.Background()
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer db.Close()
+
+	ms, err := LoadMigrations(migrations)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for run := 1; run <= 2; run++ {
+		applied, err := Migrate(ctx, db, ms)
+		fmt.Printf("   run %d: applied %v, error: %v\n", run, applied, err)
+	}
+
+	var columns []string
+	rows, err := db.QueryContext(ctx, `SELECT name FROM pragma_table_info('users')`)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for rows.Next() {
+		var c string
+		rows.Scan(&c)
+		columns = append(columns, c)
+	}
+	rows.Close()
+	fmt.Printf("   users has columns %v\n", columns)
+
+	db.ExecContext(ctx, `INSERT INTO schema_migrations VALUES (4, 'later')`)
+	_, err = Migrate(ctx, db, ms)
+	fmt.Printf("   after a newer binary: %v\n", err)
+}
+
+// editTemplates copies the embedded templates to dir, changes one, and
+// renders it from both. The embedded copy is what was there at build
+// time; only os.DirFS sees the edit
+func editTemplates(dir string) {
+	if err := os.CopyFS(dir, templates); err != nil {
+		log.Fatal(err)
+	}
+	welcome := filepath.Join(dir, "templates", "emails", "welcome.txt")
+	data, _ := os.ReadFile(welcome)
+	data = []byte(strings.Replace(string(data), "Welcome, {{.Name}}", "Welcome aboard, {{.Name}}!", 1))
+	if err := os.WriteFile(welcome, data, 0o644); err != nil {
+		log.Fatal(err)
+	}
+
+	for _, f := range []struct {
+		name string
+		fsys fs.FS
+	}{{"embedded ", templates}, {"os.DirFS ", os.DirFS(dir)}} {
+		m, err := NewMailer(f.fsys)
+		if err != nil {
+			log.Fatal(err)
+		}
+		var email strings.Builder
+		m.Render(&email, "welcome", map[string]string{"Name": "Ada", "URL": "https://blog.example/new"})
+		subject, _, _ := strings.Cut(email.String(), "\n")
+		fmt.Printf("   %s %s\n", f.name, subject)
+	}
+}
+
+// printIndented prints s, three spaces in
+func printIndented(s string) {
+	for line := range strings.Lines(s) {
+		fmt.Print("   ", line)
+	}
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// fixtures are the test's inputs and expected outputs. Embedded in the
+// test binary, they're there whatever directory the test runs in, and
+// they're never in the program's binary
+//
+//go:embed testdata
+var fixtures embed.FS
+
+func fixture(t *testing.T, dir string) fs.FS {
+	t.Helper()
+	sub, err := fs.Sub(fixtures, "testdata/"+dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sub
+}
+
+func openDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("sqlite", filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	ms, err := LoadMigrations(migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, m := range ms {
+		got = append(got, m.String())
+	}
+	want := []string{"001_create_users", "002_create_posts", "003_add_user_email"}
+	if !slices.Equal(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
+
+func TestMigrate(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+	ms, err := LoadMigrations(migrations)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := Migrate(ctx, db, ms[:2])
+	if err != nil || len(applied) != 2 {
+		t.Fatalf("want 2 applied; got %v, %v", applied, err)
+	}
+	// A new binary, with one more migration
+	applied, err = Migrate(ctx, db, ms)
+	if err != nil || len(applied) != 1 || applied[0].Version != 3 {
+		t.Fatalf("want only 003 applied; got %v, %v", applied, err)
+	}
+	if applied, err := Migrate(ctx, db, ms); err != nil || len(applied) != 0 {
+		t.Fatalf("want nothing to do; got %v, %v", applied, err)
+	}
+
+	// The schema is all there: the index from 002, the column from 003
+	var n int
+	db.QueryRowContext(ctx, `SELECT count(*) FROM sqlite_master WHERE name = 'posts_user_id'`).Scan(&n)
+	if n != 1 {
+		t.Error("want the index from 002")
+	}
+	if _, err := db.ExecContext(ctx, `INSERT INTO users (name, email) VALUES ('ada', 'ada@example.com')`); err != nil {
+		t.Errorf("want the email column from 003: %v", err)
+	}
+
+	// An older binary refuses to run against it
+	if _, err := Migrate(ctx, db, ms[:1]); !errors.Is(err, ErrNewerSchema) {
+		t.Errorf("want ErrNewerSchema; got %v", err)
+	}
+}
+
+func TestMigrateFailure(t *testing.T) {
+	ctx := t.Context()
+	db := openDB(t)
+	ms, err := LoadMigrations(fixture(t, "broken"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := Migrate(ctx, db, ms)
+	if err == nil || !strings.HasPrefix(err.Error(), "migration 002_create_b: ") {
+		t.Fatalf("want 002 to fail; got %v", err)
+	}
+	if len(applied) != 1 {
+		t.Errorf("want 001 applied; got %v", applied)
+	}
+
+	// 002's first statement was rolled back with the rest of it
+	var tables []string
+	rows, err := db.QueryContext(ctx, `SELECT name FROM sqlite_master WHERE type = 'table' ORDER BY name`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		rows.Scan(&name)
+		tables = append(tables, name)
+	}
+	if want := []string{"a", "schema_migrations"}; !slices.Equal(tables, want) {
+		t.Errorf("want tables %v; got %v", want, tables)
+	}
+	var version int
+	db.QueryRowContext(ctx, `SELECT max(version) FROM schema_migrations`).Scan(&version)
+	if version != 1 {
+		t.Errorf("want version 1 recorded; got %d", version)
+	}
+}
+
+func TestLoadMigrationsErrors(t *testing.T) {
+	tests := []struct {
+		dir  string
+		want string
+	}{
+		{"dupe", "migration 001_uno.sql: version 1 is 001_one.sql too"},
+		{"badname", "migration 1-users.sql: want a name like 001_create_users.sql"},
+	}
+	for _, tt := range tests {
+		if _, err := LoadMigrations(fixture(t, tt.dir)); err == nil || err.Error() != tt.want {
+			t.Errorf("%s: want %q; got %v", tt.dir, tt.want, err)
+		}
+	}
+}
+
+func TestEmails(t *testing.T) {
+	m, err := NewMailer(templates)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		email  string
+		data   any
+		golden string
+	}{
+		{"welcome", map[string]string{"Name": "Ada", "URL": "https://blog.example/new"}, "welcome.golden"},
+		{"digest", map[string]any{"Name": "Ada", "Posts": []string{}}, "digest-empty.golden"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			want, err := fs.ReadFile(fixtures, "testdata/"+tt.golden)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got strings.Builder
+			if err := m.Render(&got, tt.email, tt.data); err != nil {
+				t.Fatal(err)
+			}
+			if got.String() != string(want) {
+				t.Errorf("want:\n%s\ngot:\n%s", want, got.String())
+			}
+		})
+	}
+
+	if err := m.Render(&strings.Builder{}, "_draft", nil); err == nil {
+		t.Error("want _draft left out of the embed")
+	}
+}
+
+func TestMailerParseError(t *testing.T) {
+	fsys := fstest.MapFS{
+		"templates/partials/signature.txt": {Data: []byte(`{{define "signature"}}--{{end}}`)},
+		"templates/emails/broken.txt":      {Data: []byte(`{{define "subject"}}{{.Name}{{end}}`)},
+	}
+	if _, err := NewMailer(fsys); err == nil || !strings.Contains(err.Error(), "broken.txt") {
+		t.Errorf("want the broken template reported at startup; got %v", err)
+	}
+}
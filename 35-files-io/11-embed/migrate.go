@@ -0,0 +1,126 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// migrations are the schema changes, in the binary. The binary that
+// runs against a database carries the exact schema it expects: there's
+// no directory to deploy next to it, and no way to ship a binary with
+// the migrations of another version
+//
+//go:embed migrations/*.sql
+var migrations embed.FS
+
+// Migration is one schema change, from a file named like
+// 002_create_posts.sql
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+func (m Migration) String() string { return fmt.Sprintf("%03d_%s", m.Version, m.Name) }
+
+var migrationName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.sql$`)
+
+// LoadMigrations reads the .sql files in the migrations directory of
+// fsys, sorted by version. A name that doesn't look like 001_name.sql,
+// or a version used twice, is an error: better at startup than half way
+// through a migration
+func LoadMigrations(fsys fs.FS) ([]Migration, error) {
+	names, err := fs.Glob(fsys, "migrations/*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	var ms []Migration
+	seen := make(map[int]string)
+	for _, name := range names {
+		base := path.Base(name)
+		match := migrationName.FindStringSubmatch(base)
+		if match == nil {
+			return nil, fmt.Errorf("migration %s: want a name like 001_create_users.sql", base)
+		}
+		version, _ := strconv.Atoi(match[1])
+		if other, ok := seen[version]; ok {
+			return nil, fmt.Errorf("migration %s: version %d is %s too", base, version, other)
+		}
+		seen[version] = base
+
+		data, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+		ms = append(ms, Migration{Version: version, Name: match[2], SQL: string(data)})
+	}
+	slices.SortFunc(ms, func(a, b Migration) int { return a.Version - b.Version })
+	return ms, nil
+}
+
+// ErrNewerSchema means the database was migrated by a newer binary: this
+// one doesn't know the schema it would be running against
+var ErrNewerSchema = errors.New("the database is newer than this program")
+
+// Migrate applies the migrations that db hasn't had yet, each in its own
+// transaction, and records them in schema_migrations. It returns the
+// ones it applied. Running it again applies nothing
+func Migrate(ctx context.Context, db *sql.DB, ms []Migration) ([]Migration, error) {
+	_, err := db.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version    INTEGER PRIMARY KEY,
+		applied_at TEXT NOT NULL
+	)`)
+	if err != nil {
+		return nil, err
+	}
+
+	var current sql.NullInt64
+	if err := db.QueryRowContext(ctx, `SELECT max(version) FROM schema_migrations`).Scan(&current); err != nil {
+		return nil, err
+	}
+	if len(ms) > 0 && current.Int64 > int64(ms[len(ms)-1].Version) {
+		return nil, fmt.Errorf("%w: it's at version %d, and the last migration here is %s", ErrNewerSchema, current.Int64, ms[len(ms)-1])
+	}
+
+	var applied []Migration
+	for _, m := range ms {
+		if int64(m.Version) <= current.Int64 {
+			continue
+		}
+		if err := apply(ctx, db, m); err != nil {
+			return applied, fmt.Errorf("migration %s: %w", m, err)
+		}
+		applied = append(applied, m)
+	}
+	return applied, nil
+}
+
+// apply runs m and records it in one transaction: if either fails,
+// neither happened, and the next Migrate tries m again
+func apply(ctx context.Context, db *sql.DB, m Migration) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // a no-op after Commit
+
+	if _, err := tx.ExecContext(ctx, m.SQL); err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`,
+		m.Version, time.Now().UTC().Format(time.RFC3339))
+	if err != nil {
+		return err
+	}
+	return tx.Commit()
+}
@@ -0,0 +1 @@
+CREATE TABLE a (id INTEGER PRIMARY KEY);
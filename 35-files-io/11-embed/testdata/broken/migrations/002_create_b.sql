@@ -0,0 +1,2 @@
+CREATE TABLE b (id INTEGER PRIMARY KEY);
+CREATE TABL oops;
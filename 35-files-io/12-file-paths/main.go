@@ -0,0 +1,109 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+func main() {
+	fmt.Println("Cross-Platform File Paths: filepath and path")
+	fmt.Println("============================================")
+	fmt.Println()
+
+	// Example 1: Two packages
+	fmt.Println("1. path for slash-separated names, filepath for this OS:")
+	fmt.Printf("   path.Join(\"static\", \"css\", \"site.css\")     = %s\n", path.Join("static", "css", "site.css"))
+	fmt.Printf("   filepath.Join(\"static\", \"css\", \"site.css\") = %s\n", filepath.Join("static", "css", "site.css"))
+	fmt.Printf("   on %s: Separator %q, ListSeparator %q\n", runtime.GOOS, filepath.Separator, filepath.ListSeparator)
+	fmt.Println()
+
+	// Example 2: Clean
+	fmt.Println("2. filepath.Clean, and what it doesn't know:")
+	for _, p := range []string{"a//b/./c/", "a/b/../../c", "../../x", "/../x", "", "a/link/.."} {
+		fmt.Printf("   %-14q -> %q\n", p, filepath.Clean(p))
+	}
+	fmt.Println()
+
+	// Example 3: Rel and Abs
+	fmt.Println("3. filepath.Rel and filepath.Abs:")
+	for _, rel := range [][2]string{{"/srv/site", "/srv/site/css/a.css"}, {"/srv/site", "/srv/logs"}, {"/srv/site", "relative"}} {
+		r, err := filepath.Rel(rel[0], rel[1])
+		fmt.Printf("   Rel(%q, %q) = %q %v\n", rel[0], rel[1], r, errOrNil(err))
+	}
+	wd, _ := os.Getwd()
+	abs, _ := filepath.Abs("notes/../README.md")
+	fmt.Printf("   Abs(\"notes/../README.md\") = <wd>%s\n", strings.TrimPrefix(abs, wd))
+	fmt.Println()
+
+	// Example 4: Names from outside
+	fmt.Println("4. Names from users, through Localize and IsLocal:")
+	for _, name := range []string{"css/site.css", "a/../b", "../etc/passwd", "/etc/passwd", `a\..\..\x`, "C:/Windows", "NUL"} {
+		local, err := filepath.Localize(name)
+		fmt.Printf("   %-15q Localize=%-16q %-13v IsLocal=%t\n", name, local, errOrNil(err), filepath.IsLocal(name))
+	}
+	fmt.Println()
+
+	// Example 5: Joining a user's name to a directory
+	fmt.Println("5. filepath.Join against SafeJoin, under /srv/public:")
+	for _, name := range []string{"css/site.css", "css/../index.html", "../secret.txt", "css/../../secret.txt", "/etc/passwd"} {
+		safe, err := SafeJoin("/srv/public", name)
+		if err != nil {
+			safe = "refused"
+		}
+		fmt.Printf("   %-22q Join=%-26s SafeJoin=%s\n", name, naiveJoin("/srv/public", name), safe)
+	}
+	fmt.Println()
+
+	// Example 6: Inside or not
+	fmt.Println("6. Is it inside /srv/public? HasPrefix against Rel:")
+	for _, target := range []string{"/srv/public/a.txt", "/srv/public", "/srv/public-secrets/key", "/srv/public/../x"} {
+		in, _ := Within("/srv/public", target)
+		fmt.Printf("   %-26s HasPrefix=%-5t Within=%t\n", target, prefixWithin("/srv/public", target), in)
+	}
+	fmt.Println()
+
+	// Example 7: What a lexical check can't see
+	fmt.Println("7. A symbolic link, past SafeJoin, and stopped by os.Root:")
+	symlinks()
+}
+
+// symlinks makes public/out, a link to ../secret.txt. SafeJoin allows
+// "out": the name is fine, it's the file system that leads out
+func symlinks() {
+	dir, err := os.MkdirTemp("", "paths")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	public := filepath.Join(dir, "public")
+	os.Mkdir(public, 0o755)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("the password"), 0o644)
+	if err := os.Symlink(filepath.Join("..", "secret.txt"), filepath.Join(public, "out")); err != nil {
+		fmt.Println("   no symbolic links here:", err)
+		return
+	}
+
+	p, _ := SafeJoin(public, "out")
+	data, err := os.ReadFile(p)
+	fmt.Printf("   os.ReadFile(SafeJoin(public, \"out\")) = %q %v\n", data, errOrNil(err))
+
+	root, err := os.OpenRoot(public)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer root.Close()
+	data, err = root.ReadFile("out")
+	fmt.Printf("   root.ReadFile(\"out\")                 = %q %v\n", data, errOrNil(err))
+}
+
+func errOrNil(err error) string {
+	if err == nil {
+		return "ok"
+	}
+	return err.Error()
+}
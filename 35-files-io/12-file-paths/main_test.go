@@ -0,0 +1,188 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+// pick returns the result expected on this system. The tables below
+// have a column for each, so they say what's different on Windows even
+// when they run on Linux
+func pick(unix, windows string) string {
+	if runtime.GOOS == "windows" {
+		return windows
+	}
+	return unix
+}
+
+func TestFilepath(t *testing.T) {
+	errored := func(s string, err error) string {
+		if err != nil {
+			return "error"
+		}
+		return s
+	}
+	rel := func(base, target string) string { return errored(filepath.Rel(base, target)) }
+	localize := func(name string) string { return errored(filepath.Localize(name)) }
+	boolean := func(b bool) string {
+		if b {
+			return "true"
+		}
+		return "false"
+	}
+
+	tests := []struct {
+		call    string
+		got     string
+		unix    string
+		windows string
+	}{
+		{`Join("a", "b", "c.txt")`, filepath.Join("a", "b", "c.txt"), "a/b/c.txt", `a\b\c.txt`},
+		{`Join("a/", "/b")`, filepath.Join("a/", "/b"), "a/b", `a\b`},
+		{`Join("C:", "dir")`, filepath.Join("C:", "dir"), "C:/dir", "C:dir"}, // dir in C:'s current directory
+		{`Clean("a//b/../c/")`, filepath.Clean("a//b/../c/"), "a/c", `a\c`},
+		{`Clean("/../x")`, filepath.Clean("/../x"), "/x", `\x`},
+		{`Clean("")`, filepath.Clean(""), ".", "."},
+		{`Dir("a/b/c.txt")`, filepath.Dir("a/b/c.txt"), "a/b", `a\b`},
+		{`Base("a/b/c.txt")`, filepath.Base("a/b/c.txt"), "c.txt", "c.txt"},
+		{`Base("a\\b\\c.txt")`, filepath.Base(`a\b\c.txt`), `a\b\c.txt`, "c.txt"}, // a backslash is a name's character on Unix
+		{`Ext("archive.tar.gz")`, filepath.Ext("archive.tar.gz"), ".gz", ".gz"},
+		{`Rel("a", "a/b/c")`, rel("a", "a/b/c"), "b/c", `b\c`},
+		{`Rel("a/b", "a/c")`, rel("a/b", "a/c"), "../c", `..\c`},
+		{`Rel("/a", "b")`, rel("/a", "b"), "error", "error"},
+		{`ToSlash("a/b")`, filepath.ToSlash(filepath.Join("a", "b")), "a/b", "a/b"},
+		{`FromSlash("a/b")`, filepath.FromSlash("a/b"), "a/b", `a\b`},
+		{`VolumeName("C:\\x")`, filepath.VolumeName(`C:\x`), "", "C:"},
+		{`VolumeName("\\\\host\\share\\x")`, filepath.VolumeName(`\\host\share\x`), "", `\\host\share`},
+		{`IsAbs("/etc")`, boolean(filepath.IsAbs("/etc")), "true", "false"}, // no drive: relative to the current one
+		{`IsAbs("C:\\Windows")`, boolean(filepath.IsAbs(`C:\Windows`)), "false", "true"},
+		{`IsLocal("a/../b")`, boolean(filepath.IsLocal("a/../b")), "true", "true"},
+		{`IsLocal("../b")`, boolean(filepath.IsLocal("../b")), "false", "false"},
+		{`IsLocal("NUL")`, boolean(filepath.IsLocal("NUL")), "true", "false"}, // a device on Windows
+		{`IsLocal("C:x")`, boolean(filepath.IsLocal("C:x")), "true", "false"},
+		{`Localize("a/b")`, localize("a/b"), "a/b", `a\b`},
+		{`Localize("a\\b")`, localize(`a\b`), `a\b`, "error"},
+		{`Localize("a:b")`, localize("a:b"), "a:b", "error"},
+		{`Localize("com1/x")`, localize("com1/x"), "com1/x", "error"},
+		{`Localize("a/../b")`, localize("a/../b"), "error", "error"}, // not an fs.ValidPath
+		{`Localize("/a")`, localize("/a"), "error", "error"},
+	}
+	for _, tt := range tests {
+		if want := pick(tt.unix, tt.windows); tt.got != want {
+			t.Errorf("%s: want %q; got %q", tt.call, want, tt.got)
+		}
+	}
+}
+
+func TestSafeJoin(t *testing.T) {
+	tests := []struct {
+		name    string
+		unix    string // "" is refused
+		windows string
+	}{
+		{"css/site.css", "base/css/site.css", `base\css\site.css`},
+		{"css/../index.html", "base/index.html", `base\index.html`},
+		{"", "base", "base"},
+		{".", "base", "base"},
+		{"a//b/", "base/a/b", `base\a\b`},
+		{"../secret.txt", "", ""},
+		{"css/../../secret.txt", "", ""},
+		{"..", "", ""},
+		{"/etc/passwd", "", ""},
+		{"a\x00b", "", ""},
+		// Names that only Windows reads as paths, or devices
+		{`..\..\secret.txt`, `base/..\..\secret.txt`, ""},
+		{"C:/Windows/win.ini", "base/C:/Windows/win.ini", ""},
+		{"nul", "base/nul", ""},
+	}
+	for _, tt := range tests {
+		want := pick(tt.unix, tt.windows)
+		got, err := SafeJoin("base", tt.name)
+		switch {
+		case want == "" && !errors.Is(err, ErrUnsafePath):
+			t.Errorf("%q: want ErrUnsafePath; got %q, %v", tt.name, got, err)
+		case want != "" && (err != nil || got != want):
+			t.Errorf("%q: want %q; got %q, %v", tt.name, want, got, err)
+		}
+	}
+}
+
+func TestWithin(t *testing.T) {
+	base := filepath.FromSlash("/srv/public")
+	tests := []struct {
+		target string
+		want   bool
+	}{
+		{"/srv/public/a.txt", true},
+		{"/srv/public", true},
+		{"/srv/public/", true},
+		{"/srv/public/css/../a.txt", true},
+		{"/srv/public/../x", false},
+		{"/srv/public-secrets/key", false}, // the HasPrefix bug
+		{"/srv", false},
+	}
+	for _, tt := range tests {
+		got, err := Within(base, filepath.FromSlash(tt.target))
+		if err != nil || got != tt.want {
+			t.Errorf("%s: want %t; got %t, %v", tt.target, tt.want, got, err)
+		}
+	}
+	if !prefixWithin(base, filepath.FromSlash("/srv/public-secrets/key")) {
+		t.Error("want the HasPrefix check fooled")
+	}
+
+	// Relative paths are made absolute first
+	if in, err := Within(".", "testdata/../main.go"); !in || err != nil {
+		t.Errorf("want a relative path inside .; got %t, %v", in, err)
+	}
+}
+
+func TestKey(t *testing.T) {
+	base := filepath.Join("srv", "files")
+	tests := []struct {
+		path string
+		want string // "" is an error
+	}{
+		{filepath.Join(base, "docs", "a.txt"), "docs/a.txt"}, // slashes on every system
+		{base, "."},
+		{filepath.Join("srv", "other"), ""},
+	}
+	for _, tt := range tests {
+		got, err := Key(base, tt.path)
+		if tt.want == "" && err == nil || tt.want != "" && got != tt.want {
+			t.Errorf("%s: want %q; got %q, %v", tt.path, tt.want, got, err)
+		}
+	}
+}
+
+func TestSymlinkEscape(t *testing.T) {
+	dir := t.TempDir()
+	public := filepath.Join(dir, "public")
+	os.Mkdir(public, 0o755)
+	os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0o644)
+	if err := os.Symlink(filepath.Join("..", "secret.txt"), filepath.Join(public, "out")); err != nil {
+		t.Skip("no symbolic links:", err)
+	}
+
+	// SafeJoin checks names, and "out" is a fine name
+	p, err := SafeJoin(public, "out")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(p); string(data) != "secret" {
+		t.Errorf("want the lexical check to miss the link; got %q", data)
+	}
+
+	// os.Root checks where the link leads
+	root, err := os.OpenRoot(public)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer root.Close()
+	if _, err := root.ReadFile("out"); err == nil {
+		t.Error("want os.Root to refuse the link out")
+	}
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// ErrUnsafePath is the error for a name that would leave its base
+// directory, or that isn't a file name on this system
+var ErrUnsafePath = errors.New("unsafe path")
+
+// SafeJoin joins name to base. name comes from a user: a URL path, a
+// name in an archive, a key in a JSON request. It's slash-separated,
+// like every name that isn't the OS's own, and it must stay inside base.
+//
+// path.Clean removes the ".." that stay inside, like "a/../b". Then
+// filepath.Localize turns what's left into a local name, and refuses
+// anything that escapes, is absolute, or can't be a file name here: a
+// backslash, a colon, or NUL on Windows.
+//
+// The check is lexical. A symbolic link inside base can still point out
+// of it; open the result with os.Root to stop that too
+func SafeJoin(base, name string) (string, error) {
+	local, err := filepath.Localize(path.Clean(name))
+	if err != nil {
+		return "", fmt.Errorf("%q: %w", name, ErrUnsafePath)
+	}
+	return filepath.Join(base, local), nil
+}
+
+// Within reports whether target is base or inside it. Both are OS paths,
+// and both are made absolute first, so "." and "/home/ada" can be
+// compared.
+//
+// strings.HasPrefix(target, base) is the bug this replaces: it says
+// /srv/public-secrets is inside /srv/public
+func Within(base, target string) (bool, error) {
+	base, err := filepath.Abs(base)
+	if err != nil {
+		return false, err
+	}
+	target, err = filepath.Abs(target)
+	if err != nil {
+		return false, err
+	}
+	rel, err := filepath.Rel(base, target)
+	if err != nil {
+		return false, nil // on different volumes, like C: and D:
+	}
+	return filepath.IsLocal(rel), nil
+}
+
+// Key returns p relative to base, with forward slashes: a name to store
+// in a database, send in a URL, or write into a zip file, that means the
+// same file on every system
+func Key(base, p string) (string, error) {
+	rel, err := filepath.Rel(base, p)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%s is not inside %s: %w", p, base, ErrUnsafePath)
+	}
+	return filepath.ToSlash(rel), nil
+}
+
+// naiveJoin is what SafeJoin replaces. filepath.Join cleans its result,
+// and cleaning follows the ".." out of base
+func naiveJoin(base, name string) string {
+	return filepath.Join(base, name)
+}
+
+// prefixWithin is what Within replaces
+func prefixWithin(base, target string) bool {
+	return strings.HasPrefix(filepath.Clean(target), filepath.Clean(base))
+}
@@ -0,0 +1,55 @@
+// ---------------------------------------------------------
+// EXERCISE: Word Count
+//
+//  Write a small wc: count the lines, words, and bytes in files.
+//  Then measure what bufio is worth, on a file big enough to tell.
+//
+//  1- Write count(r io.ByteReader) (Counts, error)
+//     - Counts has Lines, Words, and Bytes
+//     - A line ends with '\n'; a word is a run of bytes that
+//       aren't spaces, tabs, or newlines
+//     - Read one byte at a time, to the end
+//
+//  2- Write countFile(path string, buffered bool) (Counts, error)
+//     - Buffered: count(bufio.NewReader(f))
+//     - Unbuffered: a ByteReader that calls f.Read for every byte
+//
+//  3- Write generate(path string, size int64) error
+//     - Random words from a short list, 1 to 12 to a line, until
+//       the file has size bytes
+//     - Seed the generator, so every run writes the same file
+//     - Write it through a bufio.Writer, and don't forget Flush
+//
+//  4- With file names as arguments, print the counts like wc does.
+//     With none, generate a 50 MB file and count it buffered, and
+//     a 1 MB file and count it unbuffered, with the MB/s of each
+//
+//  5- Write a benchmark for countFile, buffered and unbuffered,
+//     with b.SetBytes so that it reports MB/s
+//
+// HINTS
+//
+//  - Counting words means noticing where one starts: a non-space
+//    byte after a space, or at the start
+//  - A [256]bool table is a fast way to ask "is this a space?"
+//  - io.ReadFull(f, b[:1]) reads exactly one byte
+//  - os.MkdirTemp and os.RemoveAll keep the big files out of the way
+//
+// EXPECTED OUTPUT
+//
+//  Your counts depend on your words; the speeds are from one machine
+//
+//  Test 1: A generated 50 MB file, buffered
+//  1301135 lines, 8456825 words, 52428854 bytes
+//  95 MB/s
+//
+//  Test 2: A 1 MB file, a system call per byte
+//  26058 lines, 169230 words, 1048636 bytes
+//  1.5 MB/s
+//
+// ---------------------------------------------------------
+
+package main
+
+func main() {
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Counts are what wc reports
+type Counts struct {
+	Lines, Words, Bytes int64
+}
+
+// isSpace is true for the bytes wc splits words on
+var isSpace = [256]bool{' ': true, '\t': true, '\n': true, '\v': true, '\f': true, '\r': true}
+
+// count reads r to the end, a byte at a time. Whether that's fast
+// depends on r: a bufio.Reader hands out bytes from its buffer, a bare
+// file makes a system call for each one
+func count(r io.ByteReader) (Counts, error) {
+	var c Counts
+	inWord := false
+	for {
+		b, err := r.ReadByte()
+		if err == io.EOF {
+			return c, nil
+		}
+		if err != nil {
+			return c, err
+		}
+		c.Bytes++
+		if b == '\n' {
+			c.Lines++
+		}
+		if isSpace[b] {
+			inWord = false
+		} else if !inWord {
+			inWord = true
+			c.Words++
+		}
+	}
+}
+
+// countFile counts the file at path, through a bufio.Reader or not
+func countFile(path string, buffered bool) (Counts, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return Counts{}, err
+	}
+	defer f.Close()
+
+	if buffered {
+		return count(bufio.NewReader(f))
+	}
+	return count(unbuffered{f})
+}
+
+// unbuffered reads one byte per Read call
+type unbuffered struct{ r io.Reader }
+
+func (u unbuffered) ReadByte() (byte, error) {
+	var b [1]byte
+	_, err := io.ReadFull(u.r, b[:])
+	return b[0], err
+}
+
+// generate writes a file of random words, of at least size bytes, to
+// path. The same words come out on every run
+func generate(path string, size int64) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() { err = errors.Join(err, f.Close()) }()
+
+	words := []string{"gopher", "channel", "goroutine", "interface", "slice", "map", "defer", "panic", "go", "a"}
+	rng := rand.New(rand.NewPCG(1, 2))
+	w := bufio.NewWriter(f)
+	var n int64
+	for n < size {
+		line := 1 + rng.IntN(12)
+		for i := range line {
+			if i > 0 {
+				w.WriteByte(' ')
+				n++
+			}
+			word := words[rng.IntN(len(words))]
+			w.WriteString(word)
+			n += int64(len(word))
+		}
+		w.WriteByte('\n')
+		n++
+	}
+	return w.Flush()
+}
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: wc [file ...]")
+		fmt.Fprintln(os.Stderr, "with no files, it counts a generated 50 MB file")
+	}
+	flag.Parse()
+
+	if flag.NArg() > 0 {
+		var total Counts
+		for _, path := range flag.Args() {
+			c, err := countFile(path, true)
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Printf("%8d %8d %9d %s\n", c.Lines, c.Words, c.Bytes, path)
+			total.Lines += c.Lines
+			total.Words += c.Words
+			total.Bytes += c.Bytes
+		}
+		if flag.NArg() > 1 {
+			fmt.Printf("%8d %8d %9d total\n", total.Lines, total.Words, total.Bytes)
+		}
+		return
+	}
+
+	dir, err := os.MkdirTemp("", "wc")
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fmt.Println("Test 1: A generated 50 MB file, buffered")
+	big := filepath.Join(dir, "big.txt")
+	if err := generate(big, 50<<20); err != nil {
+		log.Fatal(err)
+	}
+	start := time.Now()
+	c, err := countFile(big, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d lines, %d words, %d bytes\n", c.Lines, c.Words, c.Bytes)
+	fmt.Printf("%.0f MB/s\n", float64(c.Bytes)/(1<<20)/time.Since(start).Seconds())
+	fmt.Println()
+
+	fmt.Println("Test 2: A 1 MB file, a system call per byte")
+	small := filepath.Join(dir, "small.txt")
+	if err := generate(small, 1<<20); err != nil {
+		log.Fatal(err)
+	}
+	start = time.Now()
+	c, err = countFile(small, false)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d lines, %d words, %d bytes\n", c.Lines, c.Words, c.Bytes)
+	fmt.Printf("%.1f MB/s\n", float64(c.Bytes)/(1<<20)/time.Since(start).Seconds())
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Counts
+	}{
+		{"", Counts{0, 0, 0}},
+		{"\n", Counts{1, 0, 1}},
+		{"one", Counts{0, 1, 3}},
+		{"one two\n", Counts{1, 2, 8}},
+		{"  lead and trail  \n\n", Counts{2, 3, 20}},
+		{"tabs\tand\r\nCRLF\r\n", Counts{2, 3, 16}},
+		{"héllo wörld\n", Counts{1, 2, 14}}, // bytes, not runes
+	}
+	for _, tt := range tests {
+		for _, r := range []struct {
+			name string
+			r    func(string) io.ByteReader
+		}{
+			{"buffered", func(s string) io.ByteReader {
+				return bufio.NewReader(strings.NewReader(s))
+			}},
+			{"unbuffered", func(s string) io.ByteReader {
+				return unbuffered{iotest.OneByteReader(strings.NewReader(s))}
+			}},
+		} {
+			got, err := count(r.r(tt.in))
+			if err != nil || got != tt.want {
+				t.Errorf("%s %q: want %+v; got %+v, %v", r.name, tt.in, tt.want, got, err)
+			}
+		}
+	}
+}
+
+func TestCountFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "words.txt")
+	if err := generate(path, 256<<10); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Counts{
+		Lines: int64(strings.Count(string(data), "\n")),
+		Words: int64(len(strings.Fields(string(data)))),
+		Bytes: int64(len(data)),
+	}
+
+	for _, buffered := range []bool{true, false} {
+		got, err := countFile(path, buffered)
+		if err != nil || got != want {
+			t.Errorf("buffered=%t: want %+v; got %+v, %v", buffered, want, got, err)
+		}
+	}
+
+	if _, err := countFile(filepath.Join(t.TempDir(), "missing"), true); err == nil {
+		t.Error("want an error for a missing file")
+	}
+}
+
+func BenchmarkCountFile(b *testing.B) {
+	path := filepath.Join(b.TempDir(), "words.txt")
+	if err := generate(path, 1<<20); err != nil {
+		b.Fatal(err)
+	}
+	for _, bc := range []struct {
+		name     string
+		buffered bool
+	}{
+		{"unbuffered", false},
+		{"buffered", true},
+	} {
+		b.Run(bc.name, func(b *testing.B) {
+			b.SetBytes(1 << 20)
+			for b.Loop() {
+				if _, err := countFile(path, bc.buffered); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
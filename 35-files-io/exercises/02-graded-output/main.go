@@ -0,0 +1,58 @@
+// ---------------------------------------------------------
+// EXERCISE: Graded Output
+//
+//  The exercises in this course end with an EXPECTED OUTPUT block,
+//  for people to read. Make one a grader can read too: embed the
+//  source file, pull the block out of its comment, and compare it
+//  with what the program prints.
+//
+//  1- Write the program to grade, in report.go
+//     - Embed poem.txt in a string with //go:embed
+//     - run(w io.Writer) prints the number of words, the number of
+//       different words, and the 5 most common, most common first,
+//       then alphabetically. Words are runs of letters, lower cased
+//     - Put its EXPECTED OUTPUT block in report.go's comment,
+//       like this one
+//
+//  2- Embed report.go itself, in grade.go
+//
+//  3- Write Expected(src string) (string, error)
+//     - The comment lines after "// EXPECTED OUTPUT", up to a
+//       dashed line or the end of the comment
+//     - Remove the "//" and the indentation the lines share, and
+//       the blank lines before and after
+//     - ErrNoExpected if there's no block
+//
+//  4- Write Grade(want, got string) []Mismatch
+//     - Compare line by line; report the line number, and both lines
+//     - Spaces at the end of a line don't count
+//
+//  5- "go run . grade" prints PASS, or the lines that differ and
+//     FAIL, with exit status 1. And write the same check as a test
+//
+// HINTS
+//
+//  - A //go:embed directive can name a .go file, even one in the
+//    same package
+//  - strings.Lines and strings.CutPrefix do most of the parsing
+//  - Change a count in the block, and check that the grader fails
+//
+// EXPECTED OUTPUT
+//
+//  go run .
+//    words: 33, different: 13
+//      the      7
+//      a        4
+//      and      3
+//      burrow   3
+//      had      3
+//
+//  go run . grade
+//    PASS
+//
+// ---------------------------------------------------------
+
+package main
+
+func main() {
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	_ "embed"
+	"errors"
+	"strings"
+)
+
+// reportSource is report.go itself. The EXPECTED OUTPUT block stays in
+// the comment, where people read it, and the grader reads the same
+// block: there's no second copy to forget to update
+//
+//go:embed report.go
+var reportSource string
+
+// ErrNoExpected is the error for a file without an EXPECTED OUTPUT block
+var ErrNoExpected = errors.New("no EXPECTED OUTPUT block")
+
+// Expected returns the EXPECTED OUTPUT block of an exercise comment in
+// src: the comment lines after "// EXPECTED OUTPUT", up to the dashed
+// line or the end of the comment. The "//" and the indentation the lines
+// share are removed, and so are blank lines around the block
+func Expected(src string) (string, error) {
+	var lines []string
+	in := false
+	for line := range strings.Lines(src) {
+		line = strings.TrimRight(line, " \t\r\n")
+		text, comment := strings.CutPrefix(line, "//")
+		switch {
+		case !in:
+			in = comment && strings.TrimSpace(text) == "EXPECTED OUTPUT"
+			continue
+		case !comment || strings.HasPrefix(strings.TrimSpace(text), "---"):
+			return block(lines), nil
+		}
+		lines = append(lines, text)
+	}
+	if !in {
+		return "", ErrNoExpected
+	}
+	return block(lines), nil
+}
+
+// block removes the indentation the lines share, and the blank lines at
+// both ends
+func block(lines []string) string {
+	for len(lines) > 0 && strings.TrimSpace(lines[0]) == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	indent := -1
+	for _, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if n := len(line) - len(strings.TrimLeft(line, " ")); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	var b strings.Builder
+	for _, line := range lines {
+		if len(line) >= indent {
+			line = line[max(indent, 0):]
+		} else {
+			line = ""
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
+
+// Mismatch is a line of output that isn't what's expected. Line counts
+// from 1
+type Mismatch struct {
+	Line      int
+	Want, Got string
+}
+
+// Grade compares got with want line by line. Spaces at the ends of
+// lines don't count: nobody can see them in a comment
+func Grade(want, got string) []Mismatch {
+	wl := strings.Split(strings.TrimRight(want, "\n"), "\n")
+	gl := strings.Split(strings.TrimRight(got, "\n"), "\n")
+	var ms []Mismatch
+	for i := range max(len(wl), len(gl)) {
+		var w, g string
+		if i < len(wl) {
+			w = strings.TrimRight(wl[i], " ")
+		}
+		if i < len(gl) {
+			g = strings.TrimRight(gl[i], " ")
+		}
+		if w != g {
+			ms = append(ms, Mismatch{Line: i + 1, Want: w, Got: g})
+		}
+	}
+	return ms
+}
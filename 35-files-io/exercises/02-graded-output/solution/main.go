@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "grade" {
+		os.Exit(grade())
+	}
+	run(os.Stdout)
+}
+
+// grade runs the report, and compares its output with the EXPECTED
+// OUTPUT block in report.go, as the binary embedded it
+func grade() int {
+	want, err := Expected(reportSource)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "report.go:", err)
+		return 2
+	}
+	var got strings.Builder
+	run(&got)
+
+	ms := Grade(want, got.String())
+	if len(ms) == 0 {
+		fmt.Println("PASS")
+		return 0
+	}
+	for _, m := range ms {
+		fmt.Printf("line %d:\n  want %q\n  got  %q\n", m.Line, m.Want, m.Got)
+	}
+	fmt.Println("FAIL")
+	return 1
+}
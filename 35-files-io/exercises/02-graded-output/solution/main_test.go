@@ -0,0 +1,83 @@
+package main
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestReport is the grader as a test: go test fails when the report and
+// its EXPECTED OUTPUT block disagree
+func TestReport(t *testing.T) {
+	want, err := Expected(reportSource)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got strings.Builder
+	run(&got)
+	for _, m := range Grade(want, got.String()) {
+		t.Errorf("line %d: want %q; got %q", m.Line, m.Want, m.Got)
+	}
+}
+
+func TestExpected(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{
+			"repo style",
+			"// ---\n// EXERCISE: x\n//\n// EXPECTED OUTPUT\n//  a: 1\n//    b\n// ---------\n\npackage main\n",
+			"a: 1\n  b\n",
+		},
+		{
+			"blank lines",
+			"// EXPECTED OUTPUT\n//\n//  one\n//\n//  two\n//\n// ---\n",
+			"one\n\ntwo\n",
+		},
+		{
+			"ends with the comment",
+			"// EXPECTED OUTPUT\n//   x\n//   y\npackage main\n// z\n",
+			"x\ny\n",
+		},
+		{
+			"ends with the file",
+			"// EXPECTED OUTPUT\n//  last",
+			"last\n",
+		},
+		{"empty", "// EXPECTED OUTPUT\n// ---\n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Expected(tt.src)
+			if err != nil || got != tt.want {
+				t.Errorf("want %q; got %q, %v", tt.want, got, err)
+			}
+		})
+	}
+
+	if _, err := Expected("package main\n// EXPECTED: nothing\n"); !errors.Is(err, ErrNoExpected) {
+		t.Errorf("want ErrNoExpected; got %v", err)
+	}
+}
+
+func TestGrade(t *testing.T) {
+	want := "a\nb\nc\n"
+	tests := []struct {
+		got  string
+		want []Mismatch
+	}{
+		{"a\nb\nc\n", nil},
+		{"a  \nb\nc", nil}, // trailing spaces, and no last newline
+		{"a\nB\nc\n", []Mismatch{{2, "b", "B"}}},
+		{"a\nb\n", []Mismatch{{3, "c", ""}}},
+		{"a\nb\nc\nd\n", []Mismatch{{4, "", "d"}}},
+	}
+	for _, tt := range tests {
+		if got := Grade(want, tt.got); !slices.Equal(got, tt.want) {
+			t.Errorf("%q: want %v; got %v", tt.got, tt.want, got)
+		}
+	}
+}
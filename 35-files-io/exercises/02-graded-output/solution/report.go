@@ -0,0 +1,53 @@
+// ---------------------------------------------------------
+// The program to grade: the most common words in poem.txt.
+//
+// EXPECTED OUTPUT
+//
+//  words: 33, different: 13
+//    the      7
+//    a        4
+//    and      3
+//    burrow   3
+//    had      3
+//
+// ---------------------------------------------------------
+
+package main
+
+import (
+	_ "embed"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+//go:embed poem.txt
+var poem string
+
+// run prints the report to w. main prints it to stdout, and the grader
+// to a buffer
+func run(w io.Writer) {
+	words := strings.FieldsFunc(strings.ToLower(poem), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	counts := make(map[string]int)
+	for _, word := range words {
+		counts[word]++
+	}
+
+	// Most common first, then in alphabetical order
+	top := slices.SortedFunc(maps.Keys(counts), func(a, b string) int {
+		if counts[a] != counts[b] {
+			return counts[b] - counts[a]
+		}
+		return strings.Compare(a, b)
+	})
+
+	fmt.Fprintf(w, "words: %d, different: %d\n", len(words), len(counts))
+	for _, word := range top[:5] {
+		fmt.Fprintf(w, "  %-8s %d\n", word, counts[word])
+	}
+}
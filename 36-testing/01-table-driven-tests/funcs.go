@@ -0,0 +1,38 @@
+package main
+
+// Filter, Map, and Reduce are the eager versions from
+// 28-generics/01-generic-functions. The tests in main_test.go pin down
+// what they promise, including the cases a quick check in main misses
+
+// Filter returns a new slice containing only elements that satisfy the predicate
+// The result is never nil, even when nothing is kept
+func Filter[T any](slice []T, predicate func(T) bool) []T {
+	result := make([]T, 0)
+	for _, v := range slice {
+		if predicate(v) {
+			result = append(result, v)
+		}
+	}
+	return result
+}
+
+// Map transforms a slice by applying a function to each element, in order
+// Takes a slice of type T and a function that converts T to U
+// Returns a new slice of type U, the same length as the input
+func Map[T, U any](slice []T, fn func(T) U) []U {
+	result := make([]U, len(slice))
+	for i, v := range slice {
+		result[i] = fn(v)
+	}
+	return result
+}
+
+// Reduce aggregates slice elements into a single value, from left to right
+// Takes an initial accumulator value and a function that combines the accumulator with each element
+func Reduce[T, U any](slice []T, initial U, fn func(U, T) U) U {
+	acc := initial
+	for _, v := range slice {
+		acc = fn(acc, v)
+	}
+	return acc
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	fmt.Println("Table-Driven Tests")
+	fmt.Println("==================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+}
+
+// Example 1: A table is data and one loop
+func example1() {
+	fmt.Println("1. A table: one row per case, and one loop that checks them all:")
+	isEven := func(n int) bool { return n%2 == 0 }
+	tests := []struct {
+		name string
+		in   []int
+		want []int
+	}{
+		{"some kept", []int{1, 2, 3, 4, 5, 6}, []int{2, 4, 6}},
+		{"none kept", []int{1, 3, 5}, []int{}},
+		{"nil", nil, []int{}},
+		{"order kept", []int{8, 1, 6, 2}, []int{8, 6, 2}},
+	}
+	for _, tt := range tests {
+		got := Filter(tt.in, isEven)
+		result := "ok"
+		if !slices.Equal(got, tt.want) {
+			result = "FAIL"
+		}
+		fmt.Printf("   %-11s Filter(%-14v isEven) = %-7s %s\n", tt.name, fmt.Sprint(tt.in)+",", fmt.Sprint(got), result)
+	}
+	fmt.Println("   A new case is one more line; main_test.go runs each as a subtest")
+	fmt.Println()
+}
+
+// Example 2: The edge cases a quick check misses
+func example2() {
+	fmt.Println("2. nil and empty: what the \"nil\" rows pin down:")
+	var none []int
+	kept := Filter(none, func(int) bool { return true })
+	mapped := Map(none, strconv.Itoa)
+	a, _ := json.Marshal(none)
+	b, _ := json.Marshal(kept)
+	c, _ := json.Marshal(mapped)
+	fmt.Printf("   the input as JSON:       %s\n", a)
+	fmt.Printf("   Filter's result as JSON: %s (never nil)\n", b)
+	fmt.Printf("   Map's result as JSON:    %s (make with length 0 isn't nil)\n", c)
+	fmt.Println("   A client that expects a list breaks on null: a test row keeps it []")
+	fmt.Println()
+}
+
+// Example 3: A case that tells two implementations apart
+func example3() {
+	fmt.Println("3. Reduce folds from the left; a sum can't show it, digits can:")
+	in := []int{1, 2, 3}
+	sum := func(acc, n int) int { return acc + n }
+	digits := func(acc, n int) int { return acc*10 + n }
+	backwards := func(fn func(int, int) int) int {
+		acc := 0
+		for _, v := range slices.Backward(in) {
+			acc = fn(acc, v)
+		}
+		return acc
+	}
+	fmt.Printf("   %-8s Reduce: %-4d backwards: %d\n", "sum", Reduce(in, 0, sum), backwards(sum))
+	fmt.Printf("   %-8s Reduce: %-4d backwards: %d\n", "digits", Reduce(in, 0, digits), backwards(digits))
+	fmt.Println("   A table with only sums passes a broken Reduce: pick cases that fail it")
+	fmt.Println()
+}
+
+// Example 4: Running one case
+func example4() {
+	fmt.Println("4. t.Run names a subtest TestName/case; spaces become underscores:")
+	for _, name := range []string{"some kept", "nil", "left to right"} {
+		test := "TestFilter"
+		if name == "left to right" {
+			test = "TestReduce"
+		}
+		fmt.Printf("   %-24q go test -run '%s/%s'\n", name, test, strings.ReplaceAll(name, " ", "_"))
+	}
+	fmt.Println("   -run takes a regular expression per level: 'TestFilter/nil' runs one row")
+}
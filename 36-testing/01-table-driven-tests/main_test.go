@@ -0,0 +1,178 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// equal fails the test if got and want differ. t.Helper makes a failure
+// point at the caller's line, not at the t.Errorf in here
+func equal[T comparable](t *testing.T, got, want []T) {
+	t.Helper()
+	if !slices.Equal(got, want) {
+		t.Errorf("got %v; want %v", got, want)
+	}
+}
+
+// spy wraps fn and counts its calls. When the test ends, after all its
+// subtests, parallel or not, a cleanup checks that fn was called want
+// times. The cleanup is a helper too, so a failure points at the line
+// that called spy. The count is atomic: parallel subtests call it at once
+func spy[T, U any](t *testing.T, want int64, fn func(T) U) func(T) U {
+	t.Helper()
+	var calls atomic.Int64
+	t.Cleanup(func() {
+		t.Helper()
+		if got := calls.Load(); got != want {
+			t.Errorf("called %d times; want %d", got, want)
+		}
+	})
+	return func(v T) U {
+		calls.Add(1)
+		return fn(v)
+	}
+}
+
+func isEven(n int) bool { return n%2 == 0 }
+
+func TestFilter(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		keep func(int) bool
+		want []int
+	}{
+		{"some kept", []int{1, 2, 3, 4, 5, 6}, isEven, []int{2, 4, 6}},
+		{"all kept", []int{2, 4}, isEven, []int{2, 4}},
+		{"none kept", []int{1, 3, 5}, isEven, []int{}},
+		{"empty", []int{}, isEven, []int{}},
+		{"nil", nil, isEven, []int{}},
+		{"order kept", []int{9, 1, 8, 2}, func(n int) bool { return n > 1 }, []int{9, 8, 2}},
+		{"duplicates kept", []int{2, 2, 3, 2}, isEven, []int{2, 2, 2}},
+	}
+	for _, tt := range tests {
+		// Since Go 1.22, every iteration has its own tt: the parallel
+		// subtests below don't need the old tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := Filter(tt.in, tt.keep)
+			equal(t, got, tt.want)
+			if got == nil {
+				t.Error("got nil; want an empty slice") // it encodes as [], not null
+			}
+		})
+	}
+}
+
+func TestFilterDoesNotChangeInput(t *testing.T) {
+	in := []int{1, 2, 3, 4}
+	Filter(in, isEven)
+	equal(t, in, []int{1, 2, 3, 4})
+}
+
+func TestMap(t *testing.T) {
+	tests := []struct {
+		name string
+		in   []int
+		fn   func(int) string
+		want []string
+	}{
+		{"itoa", []int{1, 20, 300}, strconv.Itoa, []string{"1", "20", "300"}},
+		{"same length", []int{7, 7, 7}, func(int) string { return "x" }, []string{"x", "x", "x"}},
+		{"negative", []int{-1, 0}, strconv.Itoa, []string{"-1", "0"}},
+		{"empty", []int{}, strconv.Itoa, []string{}},
+		{"nil", nil, strconv.Itoa, []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// Map must call fn once per element: no more, no less
+			fn := spy(t, int64(len(tt.in)), tt.fn)
+			equal(t, Map(tt.in, fn), tt.want)
+		})
+	}
+}
+
+func TestMapOrder(t *testing.T) {
+	var seen []string
+	Map([]string{"a", "b", "c"}, func(s string) int {
+		seen = append(seen, s)
+		return len(s)
+	})
+	equal(t, seen, []string{"a", "b", "c"})
+}
+
+func TestReduce(t *testing.T) {
+	// A sum is the same in any order: a Reduce that walked the slice
+	// backwards would pass it. Appending digits is not
+	sum := func(acc, n int) int { return acc + n }
+	digits := func(acc, n int) int { return acc*10 + n }
+
+	tests := []struct {
+		name    string
+		in      []int
+		initial int
+		fn      func(int, int) int
+		want    int
+	}{
+		{"sum", []int{1, 2, 3, 4}, 0, sum, 10},
+		{"initial counts", []int{1, 2, 3, 4}, 100, sum, 110},
+		{"left to right", []int{1, 2, 3}, 0, digits, 123}, // backwards is 321
+		{"one element", []int{5}, 4, digits, 45},
+		{"empty returns initial", []int{}, 42, sum, 42},
+		{"nil returns initial", nil, 42, sum, 42},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := Reduce(tt.in, tt.initial, tt.fn); got != tt.want {
+				t.Errorf("Reduce(%v, %d) = %d; want %d", tt.in, tt.initial, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReduceChangesType(t *testing.T) {
+	got := Reduce([]int{1, 2, 3}, "", func(acc string, n int) string {
+		return acc + strconv.Itoa(n)
+	})
+	if got != "123" {
+		t.Errorf("got %q; want %q", got, "123")
+	}
+}
+
+// TestPipeline shares one spy between parallel subtests. Its cleanup
+// runs when the parent test is done, which is after the parallel
+// subtests: they only start once the parent's function returns. A defer
+// would run before any of them
+func TestPipeline(t *testing.T) {
+	words := []string{"go", "test", "table", "driven", "subtest"}
+	long := spy(t, 3*int64(len(words)), func(s string) bool { return len(s) > 4 })
+
+	tests := []struct {
+		name string
+		run  func() string
+		want string
+	}{
+		{"filter", func() string {
+			return strings.Join(Filter(words, long), " ")
+		}, "table driven subtest"},
+		{"filter then map", func() string {
+			return strings.Join(Map(Filter(words, long), strings.ToUpper), " ")
+		}, "TABLE DRIVEN SUBTEST"},
+		{"filter then reduce", func() string {
+			return Reduce(Filter(words, long), "", func(acc, s string) string { return acc + s[:1] })
+		}, "tds"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tt.run(); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
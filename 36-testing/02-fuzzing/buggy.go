@@ -0,0 +1,27 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+)
+
+// parseBuggy is the first version of Parse, kept to show what fuzzing
+// found in it. Each comment is a bug; Parse in semver.go fixes all of them
+func parseBuggy(s string) (Version, error) {
+	core, pre, _ := strings.Cut(s, "-") // "1.2.3-" has an empty pre-release
+	parts := strings.Split(core, ".")   // "1.2.3.4" has a part too many
+
+	major, err := strconv.Atoi(parts[0]) // Atoi takes "+1" and "01"
+	if err != nil {
+		return Version{}, err
+	}
+	minor, err := strconv.Atoi(parts[1]) // panics on "1": there's no parts[1]
+	if err != nil {
+		return Version{}, err
+	}
+	patch, err := strconv.Atoi(parts[2])
+	if err != nil {
+		return Version{}, err
+	}
+	return Version{Major: major, Minor: minor, Patch: patch, Pre: pre}, nil
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func main() {
+	fmt.Println("Fuzzing with testing.F")
+	fmt.Println("======================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+}
+
+// Example 1: The inputs someone thought of
+func example1() {
+	fmt.Println("1. Both parsers pass the inputs a person would try:")
+	for _, s := range []string{"1.2.3", "0.10.200", "1.0.0-rc.1"} {
+		fmt.Printf("   %-12s buggy: %-26s fixed: %s\n", s, try(parseBuggy, s), try(Parse, s))
+	}
+	fmt.Println()
+}
+
+// Example 2: The inputs the fuzzer found
+func example2() {
+	fmt.Println("2. The inputs the fuzzer found, one run at a time:")
+	for _, s := range []string{"0", "0.0.00", "0.0.0.", "0.0.0-"} {
+		fmt.Printf("   %-12q buggy: %-26s fixed: %s\n", s, try(parseBuggy, s), try(Parse, s))
+	}
+	fmt.Println("   Each one prints back as something else, or doesn't return at all")
+	fmt.Println()
+}
+
+// Example 3: A failing input on disk
+func example3() {
+	fmt.Println("3. Each failing input is a file in testdata/fuzz/FuzzParse:")
+	entries, err := os.ReadDir(filepath.Join("testdata", "fuzz", "FuzzParse"))
+	if err != nil {
+		fmt.Printf("   %v (run this from the lesson's directory)\n", err)
+		fmt.Println()
+		return
+	}
+	for _, e := range entries {
+		data, err := os.ReadFile(filepath.Join("testdata", "fuzz", "FuzzParse", e.Name()))
+		if err != nil {
+			continue
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		fmt.Printf("   %s: %s | %s\n", e.Name(), lines[0], strings.Join(lines[1:], " "))
+	}
+	fmt.Println("   go test runs them every time, so a fixed bug stays fixed")
+	fmt.Println()
+}
+
+// Example 4: What a property can't see
+func example4() {
+	fmt.Println("4. The fuzz target checks properties, not the spec:")
+	for _, s := range []string{"1.2.3-β", "1.2.3-rc..1"} {
+		fmt.Printf("   %-14q buggy: %-26s fixed: %s\n", s, try(parseBuggy, s), try(Parse, s))
+	}
+	fmt.Println("   The buggy parser prints these back unchanged, so the fuzzer is happy;")
+	fmt.Println("   TestParse is where they're rejected")
+}
+
+// try runs parse, and reports a panic instead of crashing
+func try(parse func(string) (Version, error), s string) (result string) {
+	defer func() {
+		if r := recover(); r != nil {
+			result = "PANIC"
+		}
+	}()
+	v, err := parse(s)
+	if err != nil {
+		return "error"
+	}
+	if v.String() != s {
+		return fmt.Sprintf("%s (not %q)", v, s)
+	}
+	return v.String()
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"testing"
+)
+
+var buggy = flag.Bool("buggy", false, "run FuzzParseBuggy, which fails")
+
+// fuzzParse is the fuzz target for a parser. Besides not panicking, a
+// version it accepts must print back as the same string
+func fuzzParse(f *testing.F, parse func(string) (Version, error)) {
+	// The seed corpus: inputs the fuzzer starts from and mutates. With
+	// no -fuzz flag, go test runs these, and the files in
+	// testdata/fuzz/FuzzXxx, as ordinary tests
+	for _, s := range []string{"1.2.3", "0.0.0", "10.20.30", "1.0.0-rc.1", "1.0.0-alpha-beta"} {
+		f.Add(s)
+	}
+	f.Fuzz(func(t *testing.T, s string) {
+		v, err := parse(s)
+		if err != nil {
+			return
+		}
+		if got := v.String(); got != s {
+			t.Errorf("parse(%q).String() = %q", s, got)
+		}
+		if v.Major < 0 || v.Minor < 0 || v.Patch < 0 {
+			t.Errorf("parse(%q) = %+v: negative number", s, v)
+		}
+	})
+}
+
+func FuzzParse(f *testing.F) {
+	fuzzParse(f, Parse)
+}
+
+// FuzzParseBuggy fuzzes the buggy parser. It fails, so it runs only
+// with -buggy
+func FuzzParseBuggy(f *testing.F) {
+	if !*buggy {
+		f.Skip("the buggy parser fails; run with -buggy")
+	}
+	fuzzParse(f, parseBuggy)
+}
+
+// TestParse checks what the fuzz target can't: a property says nothing
+// about which inputs are valid, only how valid ones behave
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Version
+		ok   bool
+	}{
+		{"1.2.3", Version{1, 2, 3, ""}, true},
+		{"0.10.200", Version{0, 10, 200, ""}, true},
+		{"1.0.0-rc.1", Version{1, 0, 0, "rc.1"}, true},
+		{"1.0.0-x-y.2", Version{1, 0, 0, "x-y.2"}, true},
+		{"", Version{}, false},
+		{"1.2", Version{}, false},
+		{"1.2.3.4", Version{}, false},
+		{"01.2.3", Version{}, false},
+		{"+1.2.3", Version{}, false},
+		{"1.2.3-", Version{}, false},
+		{"1.2.3-rc..1", Version{}, false},
+		{"1.2.3-β", Version{}, false},
+		{"1.2.3+build", Version{}, false},
+		{"99999999999999999999.0.0", Version{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if tt.ok && (err != nil || got != tt.want) {
+				t.Errorf("Parse(%q) = %+v, %v; want %+v", tt.in, got, err, tt.want)
+			}
+			if !tt.ok && !errors.Is(err, ErrSyntax) {
+				t.Errorf("Parse(%q) = %+v, %v; want ErrSyntax", tt.in, got, err)
+			}
+		})
+	}
+}
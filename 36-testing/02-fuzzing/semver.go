@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Version is a semantic version: MAJOR.MINOR.PATCH, and an optional
+// pre-release after a hyphen, like 1.4.0-rc.1. Build metadata after a
+// + isn't supported
+type Version struct {
+	Major, Minor, Patch int
+	Pre                 string
+}
+
+// String returns v in the form Parse reads
+func (v Version) String() string {
+	s := fmt.Sprintf("%d.%d.%d", v.Major, v.Minor, v.Patch)
+	if v.Pre != "" {
+		s += "-" + v.Pre
+	}
+	return s
+}
+
+// ErrSyntax is the error for a string that isn't a version
+var ErrSyntax = errors.New("invalid version")
+
+// Parse parses a version, like 1.4.0 or 1.4.0-rc.1. It accepts only the
+// one way to write each version, so Parse(s).String() is s again
+func Parse(s string) (Version, error) {
+	core, pre, hasPre := strings.Cut(s, "-")
+	parts := strings.Split(core, ".")
+	if len(parts) != 3 {
+		return Version{}, fmt.Errorf("%w %q: want MAJOR.MINOR.PATCH", ErrSyntax, s)
+	}
+
+	var nums [3]int
+	for i, p := range parts {
+		n, err := number(p)
+		if err != nil {
+			return Version{}, fmt.Errorf("%w %q: %v", ErrSyntax, s, err)
+		}
+		nums[i] = n
+	}
+
+	if hasPre {
+		for id := range strings.SplitSeq(pre, ".") {
+			if err := identifier(id); err != nil {
+				return Version{}, fmt.Errorf("%w %q: pre-release: %v", ErrSyntax, s, err)
+			}
+		}
+	}
+	return Version{Major: nums[0], Minor: nums[1], Patch: nums[2], Pre: pre}, nil
+}
+
+// number parses a version number: digits only, and no leading zeros.
+// strconv.Atoi alone accepts "+1" and "01"
+func number(s string) (int, error) {
+	if s == "" {
+		return 0, errors.New("empty number")
+	}
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return 0, fmt.Errorf("%q is not a number", s)
+		}
+	}
+	if len(s) > 1 && s[0] == '0' {
+		return 0, fmt.Errorf("%q has a leading zero", s)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%q is too big", s)
+	}
+	return n, nil
+}
+
+// identifier checks one dot-separated part of a pre-release
+func identifier(s string) error {
+	if s == "" {
+		return errors.New("empty identifier")
+	}
+	for _, c := range s {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || c == '-') {
+			return fmt.Errorf("%q: want letters, digits, and hyphens", s)
+		}
+	}
+	return nil
+}
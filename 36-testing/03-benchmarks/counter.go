@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// Counter is a value that is read far more often than it's written: the
+// workload 29-concurrency/04-mutexes says an RWMutex is for
+type Counter interface {
+	Inc()
+	Value() int
+}
+
+// MutexCounter locks for reads and writes alike
+type MutexCounter struct {
+	mu sync.Mutex
+	n  int
+}
+
+func (c *MutexCounter) Inc() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n++
+}
+
+func (c *MutexCounter) Value() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// RWMutexCounter lets reads share the lock
+type RWMutexCounter struct {
+	mu sync.RWMutex
+	n  int
+}
+
+func (c *RWMutexCounter) Inc() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.n++
+}
+
+func (c *RWMutexCounter) Value() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.n
+}
+
+// AtomicCounter doesn't lock at all
+type AtomicCounter struct {
+	n atomic.Int64
+}
+
+func (c *AtomicCounter) Inc()       { c.n.Add(1) }
+func (c *AtomicCounter) Value() int { return int(c.n.Load()) }
+
+// counters makes each kind of Counter by name
+var counters = map[string]func() Counter{
+	"mutex":   func() Counter { return &MutexCounter{} },
+	"rwmutex": func() Counter { return &RWMutexCounter{} },
+	"atomic":  func() Counter { return &AtomicCounter{} },
+}
+
+// readRatios are the percentages of reads the counter benchmarks try
+var readRatios = []int{50, 90, 99}
+
+// benchCounter returns a benchmark of c from many goroutines at once,
+// with reads percent of the operations reads, and the rest writes. It
+// is used by the benchmarks in main_test.go and by main
+func benchCounter(c Counter, reads int) func(b *testing.B) {
+	return func(b *testing.B) {
+		// RunParallel starts GOMAXPROCS goroutines and shares b.N
+		// operations between them. It has its own loop, pb.Next, in
+		// place of b.Loop
+		b.RunParallel(func(pb *testing.PB) {
+			for i := 0; pb.Next(); i++ {
+				if i%100 < reads {
+					c.Value()
+				} else {
+					c.Inc()
+				}
+			}
+		})
+	}
+}
+
+func ratioName(reads int) string { return fmt.Sprintf("reads=%d%%", reads) }
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+// clamp is small enough to inline, and the benchmarks call it with
+// constants: the kind of call a compiler can work out, or drop
+func clamp(v, lo, hi int) int {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// benchClampN is the old way to write a benchmark. clamp is inlined, its
+// result is unused, and the compiler removes it: this times an empty loop
+func benchClampN(b *testing.B) {
+	for i := 0; i < b.N; i++ {
+		clamp(150, 0, 100)
+	}
+}
+
+// benchClampLoop is the Go 1.24 way. b.Loop keeps the calls in its body,
+// and their arguments and results, from being optimized away
+func benchClampLoop(b *testing.B) {
+	for b.Loop() {
+		clamp(150, 0, 100)
+	}
+}
+
+// setupRuns returns a benchmark that counts how many times its setup
+// runs. With b.N, testing calls the function again for each b.N it
+// tries, and the setup runs every time; with b.Loop, it runs once
+func setupRuns(loop bool, runs *int) func(b *testing.B) {
+	return func(b *testing.B) {
+		*runs++
+		users := makeUsers(1000) // the setup
+		if loop {
+			for b.Loop() {
+				clamp(len(users), 0, 100)
+			}
+			return
+		}
+		b.ResetTimer() // without it, the setup is timed too
+		for i := 0; i < b.N; i++ {
+			clamp(len(users), 0, 100)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+func main() {
+	// testing.Benchmark reads -test.benchtime, which only exists after
+	// testing.Init. A second per benchmark is more than this needs
+	testing.Init()
+	flag.Set("test.benchtime", "200ms")
+
+	fmt.Println("Benchmarks with b.Loop")
+	fmt.Println("======================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+	example5()
+}
+
+// Example 1: Timing by hand
+func example1() {
+	fmt.Println("1. Timing by hand, the way 04-mutexes did, three times:")
+	for run := 1; run <= 3; run++ {
+		fmt.Printf("   run %d: mutex %-10v rwmutex %v\n", run,
+			timeByHand(&MutexCounter{}).Round(time.Microsecond),
+			timeByHand(&RWMutexCounter{}).Round(time.Microsecond))
+	}
+	fmt.Println("   One run of 1010 goroutines times starting them more than the locks,")
+	fmt.Println("   and the order changes from run to run")
+	fmt.Println()
+}
+
+// timeByHand is 04-mutexes' old performanceComparison: 1000 reads and
+// 10 writes, each in its own goroutine, timed once
+func timeByHand(c Counter) time.Duration {
+	start := time.Now()
+	var wg sync.WaitGroup
+	for range 1000 {
+		wg.Go(func() { c.Value() })
+	}
+	for range 10 {
+		wg.Go(func() { c.Inc() })
+	}
+	wg.Wait()
+	return time.Since(start)
+}
+
+// Example 2: What b.Loop fixes
+func example2() {
+	fmt.Println("2. b.Loop against the old for i := 0; i < b.N; i++ loop:")
+	benchutil.Write(indent(), []benchutil.Result{
+		benchutil.Run("clamp, b.N", benchClampN),
+		benchutil.Run("clamp, b.Loop", benchClampLoop),
+	})
+	var withN, withLoop int
+	testing.Benchmark(setupRuns(false, &withN))
+	testing.Benchmark(setupRuns(true, &withLoop))
+	fmt.Printf("   The b.N loop timed nothing: the call was inlined and dropped\n")
+	fmt.Printf("   Setup ran %d times with b.N, and %d time with b.Loop\n", withN, withLoop)
+	fmt.Println()
+}
+
+// Example 3: RunParallel, with sub-benchmarks per read ratio
+func example3() {
+	fmt.Printf("3. Counters under b.RunParallel, GOMAXPROCS=%d, by share of reads:\n", runtime.GOMAXPROCS(0))
+	var results []benchutil.Result
+	for _, reads := range readRatios {
+		for _, name := range []string{"mutex", "rwmutex", "atomic"} {
+			results = append(results, benchutil.Run(name+"/"+ratioName(reads), benchCounter(counters[name](), reads)))
+		}
+	}
+	benchutil.Write(indent(), results)
+	fmt.Println("   \"vs first\" is against mutex/reads=50%")
+	fmt.Println()
+}
+
+// Example 4: Sub-benchmarks across input sizes
+func example4() {
+	fmt.Println("4. encoding/json across input sizes, with b.SetBytes and b.ReportAllocs:")
+	for _, bench := range []struct {
+		name string
+		fn   func(n int) func(b *testing.B)
+	}{
+		{"Marshal", benchMarshal},
+		{"Unmarshal", benchUnmarshal},
+	} {
+		var results []benchutil.Result
+		for _, n := range sizes {
+			results = append(results, benchutil.Run(bench.name+"/"+sizeName(n), bench.fn(n)))
+		}
+		benchutil.Write(indent(), results)
+	}
+	fmt.Println("   Time grows with the size, and MB/s stays roughly flat: the cost is linear.")
+	fmt.Println("   Marshal allocates the same 3 times for any size; Unmarshal, per user")
+	fmt.Println()
+}
+
+// Example 5: Comparing two runs
+func example5() {
+	fmt.Println("5. Two saved runs of go test -bench Counter -count 6, compared:")
+	old, err := parseFile(filepath.Join("testdata", "mutex.txt"))
+	if err != nil {
+		fmt.Printf("   %v (run this from the lesson's directory)\n", err)
+		return
+	}
+	new, err := parseFile(filepath.Join("testdata", "rwmutex.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	benchutil.Compare(indent(), old, new)
+	fmt.Println("   old is -counter mutex, new is -counter rwmutex, on one CPU")
+}
+
+func parseFile(name string) ([]benchutil.Result, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return benchutil.Parse(f)
+}
+
+// indent returns a writer that indents each line like the rest of the
+// output
+func indent() *indentWriter { return &indentWriter{} }
+
+type indentWriter struct{ mid bool }
+
+func (w *indentWriter) Write(p []byte) (int, error) {
+	for _, c := range p {
+		if !w.mid {
+			os.Stdout.WriteString("   ")
+		}
+		os.Stdout.Write([]byte{c})
+		w.mid = c != '\n'
+	}
+	return len(p), nil
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"slices"
+	"sync"
+	"testing"
+)
+
+var counter = flag.String("counter", "mutex", "the Counter BenchmarkCounter measures: mutex, rwmutex, or atomic")
+
+func TestCounters(t *testing.T) {
+	for name, newCounter := range counters {
+		t.Run(name, func(t *testing.T) {
+			c := newCounter()
+			var wg sync.WaitGroup
+			for range 50 {
+				wg.Go(func() {
+					for range 100 {
+						c.Inc()
+						c.Value()
+					}
+				})
+			}
+			wg.Wait()
+			if got := c.Value(); got != 5000 {
+				t.Errorf("got %d; want 5000", got)
+			}
+		})
+	}
+}
+
+func TestMakeUsersRoundTrip(t *testing.T) {
+	users := makeUsers(100)
+	data, err := json.Marshal(users)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []User
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.EqualFunc(got, users, func(a, b User) bool {
+		return a.ID == b.ID && a.Email == b.Email && slices.Equal(a.Tags, b.Tags)
+	}) {
+		t.Error("the users changed in a round trip")
+	}
+}
+
+func TestSetupRuns(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs two benchmarks")
+	}
+	var withN, withLoop int
+	testing.Benchmark(setupRuns(false, &withN))
+	testing.Benchmark(setupRuns(true, &withLoop))
+	if withLoop != 1 {
+		t.Errorf("with b.Loop, the setup ran %d times; want 1", withLoop)
+	}
+	if withN < 2 {
+		t.Errorf("with b.N, the setup ran %d times; want more than once", withN)
+	}
+}
+
+// BenchmarkCounter measures the Counter named by -counter, so two runs
+// with different -counter flags have the same benchmark names, and
+// benchstat can compare them:
+//
+//	go test -run XXX -bench Counter -count 6 -counter mutex > old.txt
+//	go test -run XXX -bench Counter -count 6 -counter rwmutex > new.txt
+//	benchstat old.txt new.txt
+func BenchmarkCounter(b *testing.B) {
+	newCounter, ok := counters[*counter]
+	if !ok {
+		b.Fatalf("no counter %q", *counter)
+	}
+	for _, reads := range readRatios {
+		b.Run(ratioName(reads), benchCounter(newCounter(), reads))
+	}
+}
+
+// BenchmarkMarshal and BenchmarkUnmarshal are sub-benchmarks across
+// input sizes: BenchmarkMarshal/users=100, and so on
+func BenchmarkMarshal(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(sizeName(n), benchMarshal(n))
+	}
+}
+
+func BenchmarkUnmarshal(b *testing.B) {
+	for _, n := range sizes {
+		b.Run(sizeName(n), benchUnmarshal(n))
+	}
+}
+
+// BenchmarkClamp shows what the old loop measures: nothing
+func BenchmarkClamp(b *testing.B) {
+	b.Run("b.N", benchClampN)
+	b.Run("b.Loop", benchClampLoop)
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// User is the same record 31-modern-stdlib/01-json-v2 encodes
+type User struct {
+	ID     int      `json:"id"`
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Age    int      `json:"age,omitempty"`
+	Tags   []string `json:"tags,omitempty"`
+	Active bool     `json:"active"`
+}
+
+// makeUsers returns n users, different enough that nothing is cached
+func makeUsers(n int) []User {
+	users := make([]User, n)
+	for i := range users {
+		users[i] = User{
+			ID:     i + 1,
+			Name:   fmt.Sprintf("User %d", i+1),
+			Email:  fmt.Sprintf("user%d@example.com", i+1),
+			Age:    20 + i%50,
+			Tags:   []string{"go", "json"}[:1+i%2],
+			Active: i%2 == 0,
+		}
+	}
+	return users
+}
+
+// sizes are the numbers of users the JSON benchmarks encode
+var sizes = []int{1, 100, 10_000}
+
+func sizeName(n int) string { return fmt.Sprintf("users=%d", n) }
+
+// benchMarshal and benchUnmarshal benchmark encoding/json on n users.
+// The setup before b.Loop isn't timed
+func benchMarshal(n int) func(b *testing.B) {
+	return func(b *testing.B) {
+		users := makeUsers(n)
+		data, err := json.Marshal(users)
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(data))) // reports MB/s
+		b.ReportAllocs()
+		for b.Loop() {
+			if _, err := json.Marshal(users); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func benchUnmarshal(n int) func(b *testing.B) {
+	return func(b *testing.B) {
+		data, err := json.Marshal(makeUsers(n))
+		if err != nil {
+			b.Fatal(err)
+		}
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+		for b.Loop() {
+			var users []User
+			if err := json.Unmarshal(data, &users); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
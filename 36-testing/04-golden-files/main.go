@@ -0,0 +1,103 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/inancgumus/learngo/internal/golden"
+)
+
+func main() {
+	fmt.Println("Golden Files")
+	fmt.Println("============")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+}
+
+// Example 1: Output too big to check field by field
+func example1() {
+	fmt.Println("1. A report, as a test would see it:")
+	printIndented(NewReport(sampleOrders).Text())
+	fmt.Println()
+}
+
+// Example 2: Normalizing what changes on every run
+func example2() {
+	fmt.Println("2. Two reports of the same orders, before and after normalizing:")
+	a, b := NewReport(sampleOrders).Text(), NewReport(sampleOrders).Text()
+	fmt.Printf("   raw:        %s", firstLine(a))
+	fmt.Printf("               %s", firstLine(b))
+	fmt.Printf("   equal: %t\n", bytes.Equal(a, b))
+
+	a, b = reportIDs(golden.Timestamps(a)), reportIDs(golden.Timestamps(b))
+	fmt.Printf("   normalized: %s", firstLine(a))
+	fmt.Printf("   equal: %t\n", bytes.Equal(a, b))
+	fmt.Println()
+}
+
+// Example 3: The diff when the output changes
+func example3() {
+	fmt.Println("3. The diff a failing test prints, after a price change:")
+	want, err := os.ReadFile(filepath.Join("testdata", "text", "sample.txt"))
+	if err != nil {
+		fmt.Printf("   %v (run this from the lesson's directory)\n", err)
+		fmt.Println()
+		return
+	}
+
+	orders := append([]Order(nil), sampleOrders...)
+	orders[1].Total = 19.99
+	got := NewReport(orders).Text()
+	got = reportIDs(golden.Timestamps(got))
+
+	printIndented([]byte(golden.Diff(want, got)))
+	fmt.Println("   If the change is on purpose: go test -update, then review git diff")
+	fmt.Println()
+}
+
+// Example 4: The golden files
+func example4() {
+	fmt.Println("4. The golden files, written by go test -update:")
+	err := fs.WalkDir(os.DirFS("testdata"), ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("   testdata/%-18s %4d bytes\n", path, info.Size())
+		return nil
+	})
+	if err != nil {
+		fmt.Printf("   %v (run this from the lesson's directory)\n", err)
+	}
+}
+
+// reportIDs replaces the random part of a report ID, like Timestamps
+// replaces the time, so that every run writes the same bytes
+var reportIDs = golden.Replace(`rep-[0-9a-f]{8}`, "rep-<id>")
+
+func firstLine(b []byte) string {
+	line, _, _ := bytes.Cut(b, []byte("\n"))
+	return string(line) + "\n"
+}
+
+// printIndented prints b, three spaces in
+func printIndented(b []byte) {
+	for line := range strings.Lines(string(b)) {
+		if line == "\n" {
+			fmt.Println()
+			continue
+		}
+		fmt.Print("   ", line)
+	}
+}
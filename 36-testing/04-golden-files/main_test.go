@@ -0,0 +1,46 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/golden"
+)
+
+func TestText(t *testing.T) {
+	tests := []struct {
+		name   string
+		orders []Order
+	}{
+		{"sample", sampleOrders},
+		{"one", sampleOrders[:1]},
+		{"empty", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewReport(tt.orders).Text()
+			golden.Assert(t, "text/"+tt.name+".txt", got, golden.Timestamps, reportIDs)
+		})
+	}
+}
+
+func TestJSON(t *testing.T) {
+	got, err := NewReport(sampleOrders).JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Indent first: the file is one field per line, and so is the diff
+	golden.Assert(t, "report.json", got, golden.IndentJSON, golden.Timestamps, reportIDs)
+}
+
+// TestNormalized checks what the golden files rely on: two reports of
+// the same orders differ, but not after normalizing
+func TestNormalized(t *testing.T) {
+	a, b := NewReport(sampleOrders), NewReport(sampleOrders)
+	if a.ID == b.ID {
+		t.Fatalf("want a new ID per report; got %s twice", a.ID)
+	}
+	norm := func(b []byte) string { return string(reportIDs(golden.Timestamps(b))) }
+	if got, want := norm(a.Text()), norm(b.Text()); got != want {
+		t.Errorf("want the same text after normalizing:\n%s", golden.Diff([]byte(want), []byte(got)))
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"text/tabwriter"
+	"time"
+)
+
+// Order is one row of a report
+type Order struct {
+	ID       int     `json:"id"`
+	Customer string  `json:"customer"`
+	Items    int     `json:"items"`
+	Total    float64 `json:"total"`
+}
+
+// Report sums up orders. Its ID and time are new every time one is
+// made, which is what a golden file has to work around
+type Report struct {
+	ID        string    `json:"id"`
+	Generated time.Time `json:"generated"`
+	Orders    []Order   `json:"orders"`
+	Items     int       `json:"items"`
+	Total     float64   `json:"total"`
+}
+
+// NewReport sums up orders, with a random ID and the current time
+func NewReport(orders []Order) Report {
+	id := make([]byte, 4)
+	rand.Read(id)
+
+	r := Report{
+		ID:        "rep-" + hex.EncodeToString(id),
+		Generated: time.Now().UTC(),
+		Orders:    orders,
+	}
+	for _, o := range orders {
+		r.Items += o.Items
+		r.Total += o.Total
+	}
+	return r
+}
+
+// Text renders r as a table, for a terminal
+func (r Report) Text() []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "Report %s, generated %s\n\n", r.ID, r.Generated.Format(time.RFC3339))
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "order\tcustomer\titems\ttotal\t")
+	for _, o := range r.Orders {
+		fmt.Fprintf(tw, "%d\t%s\t%d\t%.2f\t\n", o.ID, o.Customer, o.Items, o.Total)
+	}
+	fmt.Fprintf(tw, "\t%d orders\t%d\t%.2f\t\n", len(r.Orders), r.Items, r.Total)
+	tw.Flush()
+	return b.Bytes()
+}
+
+// JSON renders r as compact JSON, for an API
+func (r Report) JSON() ([]byte, error) {
+	return json.Marshal(r)
+}
+
+var sampleOrders = []Order{
+	{1001, "ada", 3, 42.50},
+	{1002, "grace", 1, 9.99},
+	{1003, "linus", 12, 130.00},
+}
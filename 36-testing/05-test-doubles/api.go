@@ -0,0 +1,187 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// User is what the API stores, and what it sends and receives as JSON
+type User struct {
+	ID    int    `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+// validate reports the first problem with a user sent by a client
+func (u User) validate() error {
+	switch {
+	case strings.TrimSpace(u.Name) == "":
+		return errors.New("name is required")
+	case !strings.Contains(u.Email, "@"):
+		return errors.New("email must contain @")
+	}
+	return nil
+}
+
+// ErrNotFound is the error for an ID no user has. Stores may wrap it
+var ErrNotFound = errors.New("user not found")
+
+// UserStore is what the handlers need from storage, and nothing more.
+// It's declared here, by the code that uses it, so that a database, the
+// fake, the spy, and the stub can all stand in. Unlike the store of
+// 32-http-servers/03-json-api, every method can fail: a real one talks
+// to a database over the network
+type UserStore interface {
+	List(ctx context.Context) ([]User, error)
+	Get(ctx context.Context, id int) (User, error)
+	Create(ctx context.Context, u User) (User, error)
+	Delete(ctx context.Context, id int) error
+}
+
+// API holds the handlers' dependencies
+type API struct {
+	store UserStore
+	log   *slog.Logger
+}
+
+func NewAPI(store UserStore, log *slog.Logger) *API {
+	return &API{store: store, log: log}
+}
+
+// Handler returns the API's routes
+func (a *API) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/users", a.listUsers)
+	mux.HandleFunc("POST /api/users", a.createUser)
+	mux.HandleFunc("GET /api/users/{id}", a.getUser)
+	mux.HandleFunc("DELETE /api/users/{id}", a.deleteUser)
+	return mux
+}
+
+func (a *API) listUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := a.store.List(r.Context())
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+func (a *API) createUser(w http.ResponseWriter, r *http.Request) {
+	var u User
+	if err := readJSON(w, r, &u); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := u.validate(); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	u.ID = 0 // the store picks the ID
+	u, err := a.store.Create(r.Context(), u)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	w.Header().Set("Location", fmt.Sprintf("/api/users/%d", u.ID))
+	writeJSON(w, http.StatusCreated, u)
+}
+
+func (a *API) getUser(w http.ResponseWriter, r *http.Request) {
+	id, err := userID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	u, err := a.store.Get(r.Context(), id)
+	if err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, u)
+}
+
+func (a *API) deleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := userID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := a.store.Delete(r.Context(), id); err != nil {
+		a.fail(w, r, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// fail answers a store error. A missing user is the client's problem,
+// and gets a 404. Anything else is ours: the client gets a plain 500,
+// and the real error, which may name hosts and tables, goes to the log
+func (a *API) fail(w http.ResponseWriter, r *http.Request, err error) {
+	if errors.Is(err, ErrNotFound) {
+		writeError(w, http.StatusNotFound, ErrNotFound.Error())
+		return
+	}
+	a.log.Error("store failed", "method", r.Method, "path", r.URL.Path, "err", err)
+	writeError(w, http.StatusInternalServerError, "internal error")
+}
+
+// userID reads the {id} path value. The pattern matches any segment,
+// so it may not be a number
+func userID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil || id < 1 {
+		return 0, errors.New("id must be a positive number")
+	}
+	return id, nil
+}
+
+// maxBodyBytes limits how much of a request body readJSON reads
+const maxBodyBytes = 1 << 20
+
+// readJSON decodes the request body into dst. It rejects bodies larger
+// than maxBodyBytes, fields dst doesn't have, and anything after the
+// first JSON value
+func readJSON(w http.ResponseWriter, r *http.Request, dst any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+
+	dec := json.NewDecoder(r.Body)
+	dec.DisallowUnknownFields()
+
+	if err := dec.Decode(dst); err != nil {
+		var tooLarge *http.MaxBytesError
+		switch {
+		case errors.Is(err, io.EOF):
+			return errors.New("body is empty")
+		case errors.As(err, &tooLarge):
+			return fmt.Errorf("body is larger than %d bytes", tooLarge.Limit)
+		}
+		return fmt.Errorf("bad JSON: %w", err)
+	}
+	if dec.More() {
+		return errors.New("body must hold a single JSON value")
+	}
+	return nil
+}
+
+// writeJSON sends v as JSON with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError sends errors in one shape, so clients can parse them
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, map[string]string{"error": msg})
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// The three test doubles below stand in for a database-backed
+// UserStore. Usually they'd be in a _test.go file, or the fake in its
+// own package when the tests of other packages need it too. Here main
+// uses them as well, to show what they do
+
+// Every double must implement the whole interface. These lines fail to
+// compile if one doesn't, which is clearer than the error at the first
+// place one is used
+var (
+	_ UserStore = (*FakeStore)(nil)
+	_ UserStore = (*SpyStore)(nil)
+	_ UserStore = StubStore{}
+)
+
+// FakeStore is a working UserStore that keeps users in a map. It
+// behaves like the real one, only it's fast and starts empty: handler
+// tests can create, read, and delete, and check the results through
+// HTTP. Like a database, it gives up once ctx is done
+type FakeStore struct {
+	mu     sync.Mutex
+	users  map[int]User
+	nextID int
+}
+
+// NewFakeStore returns a store holding users, with IDs from 1
+func NewFakeStore(users ...User) *FakeStore {
+	s := &FakeStore{users: make(map[int]User), nextID: 1}
+	for _, u := range users {
+		s.Create(context.Background(), u)
+	}
+	return s
+}
+
+// List returns every user, ordered by ID
+func (s *FakeStore) List(ctx context.Context) ([]User, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	users := make([]User, 0, len(s.users))
+	for _, u := range s.users {
+		users = append(users, u)
+	}
+	slices.SortFunc(users, func(a, b User) int { return cmp.Compare(a.ID, b.ID) })
+	return users, nil
+}
+
+func (s *FakeStore) Get(ctx context.Context, id int) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u, ok := s.users[id]
+	if !ok {
+		return User{}, fmt.Errorf("get %d: %w", id, ErrNotFound)
+	}
+	return u, nil
+}
+
+// Create stores u under a new ID, and returns it with the ID set
+func (s *FakeStore) Create(ctx context.Context, u User) (User, error) {
+	if err := ctx.Err(); err != nil {
+		return User{}, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	u.ID = s.nextID
+	s.nextID++
+	s.users[u.ID] = u
+	return u, nil
+}
+
+func (s *FakeStore) Delete(ctx context.Context, id int) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[id]; !ok {
+		return fmt.Errorf("delete %d: %w", id, ErrNotFound)
+	}
+	delete(s.users, id)
+	return nil
+}
+
+// SpyStore wraps another UserStore, and records each call before
+// passing it on. A test reads the calls to check how the handler used
+// the store: which methods, with what, and how many times. What the
+// client saw is in the response; the spy shows what happened behind it
+type SpyStore struct {
+	UserStore // the store that answers the calls
+
+	mu    sync.Mutex
+	calls []string
+}
+
+// Calls returns the calls so far, like "Get(2)", in order
+func (s *SpyStore) Calls() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.calls)
+}
+
+func (s *SpyStore) record(format string, args ...any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, fmt.Sprintf(format, args...))
+}
+
+func (s *SpyStore) List(ctx context.Context) ([]User, error) {
+	s.record("List()")
+	return s.UserStore.List(ctx)
+}
+
+func (s *SpyStore) Get(ctx context.Context, id int) (User, error) {
+	s.record("Get(%d)", id)
+	return s.UserStore.Get(ctx, id)
+}
+
+func (s *SpyStore) Create(ctx context.Context, u User) (User, error) {
+	s.record("Create(%+v)", u)
+	return s.UserStore.Create(ctx, u)
+}
+
+func (s *SpyStore) Delete(ctx context.Context, id int) error {
+	s.record("Delete(%d)", id)
+	return s.UserStore.Delete(ctx, id)
+}
+
+// StubStore answers every call with canned values, and Err. It does no
+// work and keeps no state. It's for the answers a fake can't give on
+// cue: a lost connection, a timeout, a disk that's full
+type StubStore struct {
+	User  User
+	Users []User
+	Err   error
+}
+
+func (s StubStore) List(context.Context) ([]User, error)       { return s.Users, s.Err }
+func (s StubStore) Get(context.Context, int) (User, error)     { return s.User, s.Err }
+func (s StubStore) Create(context.Context, User) (User, error) { return s.User, s.Err }
+func (s StubStore) Delete(context.Context, int) error          { return s.Err }
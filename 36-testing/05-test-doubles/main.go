@@ -0,0 +1,123 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+)
+
+func main() {
+	fmt.Println("Test Doubles: Fakes, Spies, and Stubs")
+	fmt.Println("=====================================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+}
+
+// Example 1: A fake, a store that works
+func example1() {
+	fmt.Println("1. The handlers on a fake store, which keeps what they store:")
+	h := NewAPI(NewFakeStore(), logger()).Handler()
+	send(h, "POST", "/api/users", `{"name":"Ada","email":"ada@example.com"}`)
+	send(h, "GET", "/api/users/1", "")
+	send(h, "DELETE", "/api/users/1", "")
+	send(h, "GET", "/api/users/1", "")
+	fmt.Println()
+}
+
+// Example 2: A spy, recording the calls behind each response
+func example2() {
+	fmt.Println("2. A spy around the fake records what the handlers ask of it:")
+	spy := &SpyStore{UserStore: NewFakeStore(User{Name: "Ada", Email: "ada@example.com"})}
+	h := NewAPI(spy, logger()).Handler()
+
+	requests := []struct{ method, target, body string }{
+		{"POST", "/api/users", `{"id":7,"name":"Grace","email":"grace@example.com"}`},
+		{"POST", "/api/users", `{"name":"Grace","email":"grace"}`},
+		{"GET", "/api/users/abc", ""},
+		{"DELETE", "/api/users/1", ""},
+	}
+	for _, r := range requests {
+		before := len(spy.Calls())
+		w := do(h, r.method, r.target, r.body)
+		calls := spy.Calls()[before:]
+		if len(calls) == 0 {
+			calls = []string{"no calls"}
+		}
+		fmt.Printf("   %-6s %-14s %d  %s\n", r.method, r.target, w.Code, strings.Join(calls, ", "))
+	}
+	fmt.Println()
+}
+
+// Example 3: A stub, failing on cue
+func example3() {
+	fmt.Println("3. A stub that fails: the client gets a 500, the log gets the error:")
+	stub := StubStore{Err: errors.New("dial tcp 10.0.0.5:5432: connection refused")}
+	h := NewAPI(stub, logger()).Handler()
+	send(h, "GET", "/api/users", "")
+	send(h, "GET", "/api/users/1", "")
+	fmt.Println()
+}
+
+// Example 4: Which double to use
+func example4() {
+	fmt.Println("4. Which double answers which question:")
+	rows := [][2]string{
+		{"fake", "does the handler work, end to end, with a store that works?"},
+		{"spy", "what did the handler ask the store, and how many times?"},
+		{"stub", "what does the client see when the store fails?"},
+	}
+	for _, r := range rows {
+		fmt.Printf("   %-5s %s\n", r[0], r[1])
+	}
+	fmt.Println("   All three are plain Go types: no mocking library, no generated code")
+}
+
+// do sends a request straight to h, and returns the recorded response
+func do(h http.Handler, method, target, body string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(method, target, strings.NewReader(body)))
+	return w
+}
+
+// send makes a request and prints the status, the Location header, and
+// the body
+func send(h http.Handler, method, target, body string) {
+	w := do(h, method, target, body)
+	fmt.Printf("   %-6s %-14s %d", method, target, w.Code)
+	if loc := w.Header().Get("Location"); loc != "" {
+		fmt.Printf(" [Location: %s]", loc)
+	}
+	if b := strings.TrimSpace(w.Body.String()); b != "" {
+		fmt.Printf(" %s", b)
+	}
+	fmt.Println()
+}
+
+// logger prints log lines three spaces in, without the time, so they
+// line up with the output around them
+func logger() *slog.Logger {
+	return slog.New(slog.NewTextHandler(indented{}, &slog.HandlerOptions{
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if a.Key == slog.TimeKey && len(groups) == 0 {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// indented writes to standard output, with "   log: " before each write
+type indented struct{}
+
+func (indented) Write(p []byte) (int, error) {
+	fmt.Print("   log: ")
+	return os.Stdout.Write(p)
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+)
+
+var (
+	ada   = User{Name: "Ada", Email: "ada@example.com"}
+	linus = User{Name: "Linus", Email: "linus@example.com"}
+)
+
+// newAPI returns the API's handler on store. The log goes to the test's
+// output, where it shows only if the test fails, or with -v
+func newAPI(t *testing.T, store UserStore) http.Handler {
+	t.Helper()
+	return NewAPI(store, slog.New(slog.NewTextHandler(t.Output(), nil))).Handler()
+}
+
+// TestAPI runs the handlers on the fake: a working store, so each case
+// checks a real result, like the ID a new user gets
+func TestAPI(t *testing.T) {
+	tests := []struct {
+		name     string
+		method   string
+		target   string
+		body     string
+		wantCode int
+		wantBody string // compared after trimming the trailing newline
+	}{
+		{"list", "GET", "/api/users", "", 200,
+			`[{"id":1,"name":"Ada","email":"ada@example.com"},{"id":2,"name":"Linus","email":"linus@example.com"}]`},
+		{"get", "GET", "/api/users/2", "", 200, `{"id":2,"name":"Linus","email":"linus@example.com"}`},
+		{"get missing", "GET", "/api/users/9", "", 404, `{"error":"user not found"}`},
+		{"get bad id", "GET", "/api/users/abc", "", 400, `{"error":"id must be a positive number"}`},
+		{"create", "POST", "/api/users", `{"name":"Grace","email":"grace@example.com"}`, 201,
+			`{"id":3,"name":"Grace","email":"grace@example.com"}`},
+		{"create ignores id", "POST", "/api/users", `{"id":1,"name":"Grace","email":"grace@example.com"}`, 201,
+			`{"id":3,"name":"Grace","email":"grace@example.com"}`},
+		{"create bad JSON", "POST", "/api/users", `{"name":`, 400, `{"error":"bad JSON: unexpected EOF"}`},
+		{"create no name", "POST", "/api/users", `{"name":" ","email":"grace@example.com"}`, 422,
+			`{"error":"name is required"}`},
+		{"delete", "DELETE", "/api/users/1", "", 204, ""},
+		{"delete missing", "DELETE", "/api/users/9", "", 404, `{"error":"user not found"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// A new fake per case: cases that create and delete don't
+			// see each other's changes, and can run in parallel
+			h := newAPI(t, NewFakeStore(ada, linus))
+
+			w := do(h, tt.method, tt.target, tt.body)
+			if w.Code != tt.wantCode {
+				t.Errorf("status = %d; want %d", w.Code, tt.wantCode)
+			}
+			if got := strings.TrimSuffix(w.Body.String(), "\n"); got != tt.wantBody {
+				t.Errorf("body = %s; want %s", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+// TestCreateThenDelete follows one user through the API. Only a fake
+// can do this: it keeps what the earlier requests stored
+func TestCreateThenDelete(t *testing.T) {
+	h := newAPI(t, NewFakeStore())
+
+	w := do(h, "POST", "/api/users", `{"name":"Grace","email":"grace@example.com"}`)
+	loc := w.Header().Get("Location")
+	if w.Code != 201 || loc != "/api/users/1" {
+		t.Fatalf("create: %d at %q; want 201 at /api/users/1", w.Code, loc)
+	}
+	if w := do(h, "GET", loc, ""); w.Code != 200 {
+		t.Errorf("get after create: %d; want 200", w.Code)
+	}
+	if w := do(h, "DELETE", loc, ""); w.Code != 204 {
+		t.Errorf("delete: %d; want 204", w.Code)
+	}
+	if w := do(h, "GET", loc, ""); w.Code != 404 {
+		t.Errorf("get after delete: %d; want 404", w.Code)
+	}
+}
+
+// TestStoreCalls checks what the handlers ask of the store, through a
+// spy around the fake. The responses are in TestAPI; these are the
+// calls behind them
+func TestStoreCalls(t *testing.T) {
+	tests := []struct {
+		name   string
+		method string
+		target string
+		body   string
+		want   []string
+	}{
+		{"list", "GET", "/api/users", "", []string{"List()"}},
+		{"get", "GET", "/api/users/2", "", []string{"Get(2)"}},
+		// The client's id doesn't reach the store
+		{"create", "POST", "/api/users", `{"id":7,"name":"Grace","email":"grace@example.com"}`,
+			[]string{"Create({ID:0 Name:Grace Email:grace@example.com})"}},
+		// Delete reports a missing user itself: no Get first
+		{"delete", "DELETE", "/api/users/1", "", []string{"Delete(1)"}},
+		{"delete missing", "DELETE", "/api/users/9", "", []string{"Delete(9)"}},
+
+		// A bad request is answered before the store is asked
+		{"bad id", "GET", "/api/users/abc", "", nil},
+		{"bad JSON", "POST", "/api/users", `{"name":`, nil},
+		{"invalid user", "POST", "/api/users", `{"name":"Grace","email":"grace"}`, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			spy := &SpyStore{UserStore: NewFakeStore(ada, linus)}
+			do(newAPI(t, spy), tt.method, tt.target, tt.body)
+
+			if got := spy.Calls(); !slices.Equal(got, tt.want) {
+				t.Errorf("calls = %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestStoreErrors makes every call fail, with a stub, and checks what
+// the client sees and what the log gets. A fake that worked would never
+// take these paths
+func TestStoreErrors(t *testing.T) {
+	routes := []struct{ method, target, body string }{
+		{"GET", "/api/users", ""},
+		{"GET", "/api/users/1", ""},
+		{"POST", "/api/users", `{"name":"Grace","email":"grace@example.com"}`},
+		{"DELETE", "/api/users/1", ""},
+	}
+	errs := []struct {
+		name string
+		err  error
+	}{
+		{"down", errors.New("dial tcp 10.0.0.5:5432: connection refused")},
+		{"timeout", fmt.Errorf("query users: %w", context.DeadlineExceeded)},
+	}
+	for _, e := range errs {
+		for _, r := range routes {
+			t.Run(e.name+"/"+r.method+" "+r.target, func(t *testing.T) {
+				var log bytes.Buffer
+				h := NewAPI(StubStore{Err: e.err}, slog.New(slog.NewTextHandler(&log, nil))).Handler()
+
+				w := do(h, r.method, r.target, r.body)
+				if w.Code != 500 {
+					t.Errorf("status = %d; want 500", w.Code)
+				}
+				// The real error goes to the log, and never to the client
+				if got := strings.TrimSpace(w.Body.String()); got != `{"error":"internal error"}` {
+					t.Errorf("body = %s; want only \"internal error\"", got)
+				}
+				if !strings.Contains(log.String(), e.err.Error()) {
+					t.Errorf("want %q in the log:\n%s", e.err, &log)
+				}
+			})
+		}
+	}
+}
+
+// TestStoreNotFound checks that a store may wrap ErrNotFound with
+// details of its own, and the client still gets a 404
+func TestStoreNotFound(t *testing.T) {
+	h := newAPI(t, StubStore{Err: fmt.Errorf("select user 1: %w", ErrNotFound)})
+	for _, method := range []string{"GET", "DELETE"} {
+		w := do(h, method, "/api/users/1", "")
+		if got := strings.TrimSpace(w.Body.String()); w.Code != 404 || got != `{"error":"user not found"}` {
+			t.Errorf("%s: %d %s; want 404 and user not found", method, w.Code, got)
+		}
+	}
+}
+
+// TestStubValues checks that the handlers send what the store returns,
+// and don't make up values of their own
+func TestStubValues(t *testing.T) {
+	stub := StubStore{
+		User:  User{ID: 42, Name: "Grace", Email: "grace@example.com"},
+		Users: []User{},
+	}
+	h := newAPI(t, stub)
+
+	if got := strings.TrimSpace(do(h, "GET", "/api/users", "").Body.String()); got != "[]" {
+		t.Errorf("no users: body = %s; want []", got)
+	}
+	w := do(h, "POST", "/api/users", `{"name":"Grace","email":"grace@example.com"}`)
+	if loc := w.Header().Get("Location"); loc != "/api/users/42" {
+		t.Errorf("Location = %q; want the store's ID, /api/users/42", loc)
+	}
+}
+
+// TestFakeStore checks the fake against what a UserStore promises. A
+// fake that's wrong makes the handler tests pass for the wrong reasons;
+// a real store would run the same checks, against a test database
+func TestFakeStore(t *testing.T) {
+	ctx := t.Context()
+	s := NewFakeStore()
+
+	a, _ := s.Create(ctx, User{ID: 9, Name: "Ada"})
+	b, _ := s.Create(ctx, User{Name: "Linus"})
+	if a.ID != 1 || b.ID != 2 {
+		t.Errorf("IDs = %d, %d; want 1, 2, whatever the user had", a.ID, b.ID)
+	}
+	if got, err := s.Get(ctx, 2); err != nil || got != b {
+		t.Errorf("Get(2) = %v, %v; want %v", got, err, b)
+	}
+	if err := s.Delete(ctx, 1); err != nil {
+		t.Errorf("Delete(1) = %v", err)
+	}
+	if _, err := s.Get(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: err = %v; want ErrNotFound", err)
+	}
+	if err := s.Delete(ctx, 1); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Delete twice: err = %v; want ErrNotFound", err)
+	}
+	if users, _ := s.List(ctx); !slices.Equal(users, []User{b}) {
+		t.Errorf("List = %v; want %v", users, []User{b})
+	}
+
+	done, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := s.Get(done, 2); !errors.Is(err, context.Canceled) {
+		t.Errorf("Get with a canceled context: err = %v; want context.Canceled", err)
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"hash/crc32"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// A count starts n goroutines that each add one to a shared counter,
+// and returns the counter once they're done. Each version below is one
+// step from the one before it
+
+// countRacy is example 6 of 29-concurrency/01-goroutines-basics. Every
+// goroutine reads the counter, sleeps, and writes back what it read plus
+// one. They all read before any writes, so most updates are lost
+func countRacy(n int) int {
+	counter := 0
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			temp := counter // read
+			time.Sleep(time.Millisecond)
+			counter = temp + 1 // write: another goroutine may have written since the read
+		})
+	}
+	wg.Wait()
+	return counter
+}
+
+// countIncrement drops the sleep, and writes counter++. The gap between
+// the read and the write is now a few instructions, so the result is
+// usually right. It's still a race: ++ reads, adds, and writes, and
+// nothing stops two goroutines from doing that at once
+func countIncrement(n int) int {
+	counter := 0
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			counter++
+		})
+	}
+	wg.Wait()
+	return counter
+}
+
+// countMutex holds a lock from the read to the write, so only one
+// goroutine at a time runs them. The sleep is back, inside the lock:
+// the goroutines now take turns, and it takes n milliseconds
+func countMutex(n int) int {
+	counter := 0
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			mu.Lock()
+			defer mu.Unlock()
+			temp := counter
+			time.Sleep(time.Millisecond)
+			counter = temp + 1
+		})
+	}
+	wg.Wait()
+	return counter
+}
+
+// countAtomic adds with one indivisible instruction. No lock, and no
+// read-then-write for another goroutine to get between
+func countAtomic(n int) int {
+	var counter atomic.Int64
+	var wg sync.WaitGroup
+	for range n {
+		wg.Go(func() {
+			counter.Add(1)
+		})
+	}
+	wg.Wait()
+	return int(counter.Load())
+}
+
+// countLogged has the race of countIncrement, in a goroutine that
+// does some work and logs before and after it, like real code does. The
+// logger locks a mutex for each line. When one goroutine runs from
+// start to end before the next starts, the race detector sees the first
+// one's "done" line happen before the second one's "start", so its
+// counter++ before the second's, and reports nothing. Only a run where
+// two goroutines overlap shows the race
+func countLogged(n int, log *log.Logger) int {
+	counter := 0
+	var wg sync.WaitGroup
+	for i := range n {
+		wg.Go(func() {
+			log.Printf("worker %d: start", i)
+			sum := work(i)
+			counter++
+			log.Printf("worker %d: done, %08x", i, sum)
+		})
+	}
+	wg.Wait()
+	return counter
+}
+
+// work stands in for whatever a goroutine does before it counts: here,
+// a few milliseconds of checksums
+func work(seed int) uint32 {
+	sum := uint32(seed)
+	for range 20_000 {
+		sum = crc32.ChecksumIEEE(strconv.AppendUint(nil, uint64(sum), 10))
+	}
+	return sum
+}
+
+// counts are the versions, in order. racy marks the two with a data
+// race: their tests run only with -racy
+var counts = []struct {
+	name  string
+	count func(n int) int
+	racy  bool
+}{
+	{"racy", countRacy, true},
+	{"increment", countIncrement, true},
+	{"mutex", countMutex, false},
+	{"atomic", countAtomic, false},
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"time"
+)
+
+// goroutines is how many goroutines each count starts
+const goroutines = 50
+
+func main() {
+	fmt.Println("The Race Detector")
+	fmt.Println("=================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+	example5()
+}
+
+// Example 1: Is the detector on?
+func example1() {
+	fmt.Println("1. The race detector:")
+	if raceEnabled {
+		fmt.Println("   on: each race below is reported once, on standard error")
+		fmt.Println("   and the program exits with status 66")
+	} else {
+		fmt.Println("   off: run this with go run -race . to see the reports")
+	}
+	fmt.Println()
+}
+
+// Example 2: The racy counter
+func example2() {
+	fmt.Println("2. Read, sleep, write back, in 50 goroutines:")
+	run("racy", countRacy)
+	fmt.Println("   Every goroutine reads 0 before any writes, so most updates are lost")
+	fmt.Println()
+}
+
+// Example 3: Right answer, still a race
+func example3() {
+	fmt.Println("3. counter++ without the sleep:")
+	run("increment", countIncrement)
+	fmt.Println("   Usually right, and still a data race: only -race can tell")
+	fmt.Println()
+}
+
+// Example 4: The fixes
+func example4() {
+	fmt.Println("4. Fixed, with a mutex and with an atomic:")
+	run("mutex", countMutex)
+	run("atomic", countAtomic)
+	fmt.Println()
+}
+
+// Example 5: A race that hides
+func example5() {
+	fmt.Println("5. counter++ between two log lines:")
+	var buf bytes.Buffer
+	got := countLogged(4, log.New(&buf, "", 0))
+	fmt.Printf("   count = %d, want 4\n", got)
+	fmt.Println("   -race reports it only when two goroutines overlap;")
+	fmt.Println("   the lesson's stress helper runs it until they do")
+}
+
+// run calls count three times, and prints the results and the time
+func run(name string, count func(n int) int) {
+	start := time.Now()
+	var results []int
+	for range 3 {
+		results = append(results, count(goroutines))
+	}
+	fmt.Printf("   %-10s %v  want %d  (%v)\n", name, results, goroutines, time.Since(start).Round(time.Millisecond))
+}
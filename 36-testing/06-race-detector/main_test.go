@@ -0,0 +1,65 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"log"
+	"testing"
+)
+
+// The racy versions fail under -race, every time. They run only with
+// -racy, so that go test -race ./... stays green:
+//
+//	go test -race -racy -run 'TestCount/increment'
+var racy = flag.Bool("racy", false, "also test the versions with a data race")
+
+// stress runs fn as n parallel subtests. The race detector only sees
+// accesses that happen while it watches: a race on a path that runs
+// once, at a moment when nothing else does, can go unseen. Running the
+// same code many times, side by side, gives it more chances to overlap
+func stress(t *testing.T, n int, fn func(t *testing.T)) {
+	t.Helper()
+	for i := range n {
+		t.Run(fmt.Sprintf("run%d", i), func(t *testing.T) {
+			t.Parallel()
+			fn(t)
+		})
+	}
+}
+
+func TestCount(t *testing.T) {
+	const goroutines = 50
+	for _, c := range counts {
+		t.Run(c.name, func(t *testing.T) {
+			if c.racy && !*racy {
+				t.Skip("has a data race; run with -racy")
+			}
+			if got := c.count(goroutines); got != goroutines {
+				t.Errorf("count = %d; want %d", got, goroutines)
+			}
+		})
+	}
+}
+
+// TestCountLogged runs countLogged once, and then under stress. Whether
+// -race sees its race depends on how the goroutines are scheduled:
+//
+//	go test -race -racy -cpu 4 -run 'TestCountLogged/once'
+//	go test -race -racy -cpu 4 -run 'TestCountLogged/stress'
+func TestCountLogged(t *testing.T) {
+	if !*racy {
+		t.Skip("has a data race; run with -racy")
+	}
+	const goroutines = 4
+	check := func(t *testing.T) {
+		// Not io.Discard: a Logger that discards skips its lock, and
+		// then there's nothing to hide the race
+		var buf bytes.Buffer
+		if got := countLogged(goroutines, log.New(&buf, "", 0)); got != goroutines {
+			t.Errorf("count = %d; want %d", got, goroutines)
+		}
+	}
+	t.Run("once", check)
+	t.Run("stress", func(t *testing.T) { stress(t, 20, check) })
+}
@@ -0,0 +1,7 @@
+//go:build !race
+
+package main
+
+// raceEnabled reports whether the program was built with -race, which
+// sets the race build tag
+const raceEnabled = false
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"fmt"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/inancgumus/learngo/36-testing/07-test-organization/wordcount"
+	"github.com/inancgumus/learngo/internal/coverage"
+)
+
+func main() {
+	fmt.Println("Test Organization and Coverage")
+	fmt.Println("==============================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+}
+
+const text = "The test fails, and the gopher is happy: a test that fails first is a test that can fail."
+
+// Example 1: The package under test
+func example1() {
+	fmt.Println("1. wordcount, as main uses it:")
+	fmt.Printf("   %q\n", text)
+	for _, e := range wordcount.New(wordcount.English...).Top(text, 3) {
+		fmt.Printf("   %-7s %d\n", e.Word, e.Count)
+	}
+	fmt.Println()
+}
+
+// Example 2: Settings in a value, not in the package
+func example2() {
+	fmt.Println("2. Two counters, each with its own stop words:")
+	counters := []struct {
+		name string
+		c    *wordcount.Counter
+	}{
+		{"none", wordcount.New()},
+		{"English", wordcount.New(wordcount.English...)},
+		{"English+test", wordcount.New(append(slices.Clone(wordcount.English), "test")...)},
+	}
+	for _, c := range counters {
+		fmt.Printf("   %-13s %v\n", c.name, c.c.Top(text, 3))
+	}
+	fmt.Println("   No counter changes another, so tests of each can run in parallel")
+	fmt.Println()
+}
+
+// Example 3: Which package each file is in
+func example3() {
+	fmt.Println("3. The files of wordcount, and the package each one declares:")
+	files, err := filepath.Glob(filepath.Join("wordcount", "*.go"))
+	if err != nil || len(files) == 0 {
+		fmt.Println("   no files found (run this from the lesson's directory)")
+		fmt.Println()
+		return
+	}
+	for _, name := range files {
+		f, err := parser.ParseFile(token.NewFileSet(), name, nil, parser.PackageClauseOnly)
+		if err != nil {
+			fmt.Println("  ", err)
+			continue
+		}
+		fmt.Printf("   %-20s package %-15s %s\n", filepath.Base(name), f.Name.Name, sees(name, f.Name.Name))
+	}
+	fmt.Println()
+}
+
+// sees says what the code in a file can use
+func sees(file, pkg string) string {
+	switch {
+	case !strings.HasSuffix(file, "_test.go"):
+		return "the code"
+	case strings.HasSuffix(pkg, "_test"):
+		return "exported names only, plus export_test.go"
+	}
+	return "everything, unexported too"
+}
+
+// Example 4: What a coverage profile records
+func example4() {
+	fmt.Println("4. A saved profile of the internal tests alone, testdata/internal.out:")
+	f, err := os.Open(filepath.Join("testdata", "internal.out"))
+	if err != nil {
+		fmt.Printf("   %v (run this from the lesson's directory)\n", err)
+		return
+	}
+	defer f.Close()
+
+	p, err := coverage.Parse(f)
+	if err != nil {
+		fmt.Println("  ", err)
+		return
+	}
+	for _, pkg := range p.Packages() {
+		fmt.Printf("   %s: %d of %d statements, %.1f%%\n", filepath.Base(pkg.Path), pkg.Covered, pkg.Statements, pkg.Percent())
+	}
+	// Blocks a line or two apart, with only a closing brace between
+	// them, are merged into one span
+	type span struct{ start, end, stmts int }
+	var spans []span
+	for _, b := range p.Blocks {
+		if b.Count > 0 {
+			continue
+		}
+		if n := len(spans); n > 0 && b.StartLine <= spans[n-1].end+2 {
+			spans[n-1].end = b.EndLine
+			spans[n-1].stmts += b.NumStmt
+			continue
+		}
+		spans = append(spans, span{b.StartLine, b.EndLine, b.NumStmt})
+	}
+	fmt.Println("   Lines of wordcount.go that no internal test ran:")
+	for _, s := range spans {
+		fmt.Printf("     %d-%d, %d statements\n", s.start, s.end, s.stmts)
+	}
+	fmt.Println("   They're Count and Top; the external tests run them, and the two reach 100%")
+}
@@ -0,0 +1,6 @@
+package wordcount
+
+// Words exports words for the external tests in package wordcount_test.
+// This file is compiled only by go test, so Words isn't part of the
+// package's API.
+var Words = words
@@ -0,0 +1,87 @@
+// Package wordcount counts the words in a text, and finds the most
+// common ones.
+//
+// It's laid out to be tested: the splitting is a small unexported
+// function with its own tests, and every setting is a field of a
+// Counter, not a package variable, so tests with different settings can
+// run in parallel.
+package wordcount
+
+import (
+	"cmp"
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// Entry is a word and how many times it appears.
+type Entry struct {
+	Word  string
+	Count int
+}
+
+// Counter counts words, skipping its stop words. Its methods don't
+// change it, so one Counter can be used by many goroutines.
+type Counter struct {
+	stop map[string]bool
+}
+
+// New returns a Counter that skips the given stop words, in any case.
+func New(stopWords ...string) *Counter {
+	c := &Counter{stop: make(map[string]bool, len(stopWords))}
+	for _, w := range stopWords {
+		c.stop[normalize(w)] = true
+	}
+	return c
+}
+
+// English is a short list of common English words that say little about
+// a text.
+var English = []string{"a", "an", "and", "are", "as", "at", "be", "by", "for", "in", "is", "it", "of", "on", "or", "that", "the", "to", "with"}
+
+// Count returns how many times each word appears in text, not counting
+// stop words. Words are compared in lower case.
+func (c *Counter) Count(text string) map[string]int {
+	counts := make(map[string]int)
+	for _, w := range words(text) {
+		if !c.stop[w] {
+			counts[w]++
+		}
+	}
+	return counts
+}
+
+// Top returns the n most common words in text, most common first. Words
+// with the same count are in alphabetical order.
+func (c *Counter) Top(text string, n int) []Entry {
+	counts := c.Count(text)
+	entries := make([]Entry, 0, len(counts))
+	for _, w := range slices.Sorted(maps.Keys(counts)) {
+		entries = append(entries, Entry{w, counts[w]})
+	}
+	slices.SortStableFunc(entries, func(a, b Entry) int { return cmp.Compare(b.Count, a.Count) })
+	return entries[:min(n, len(entries))]
+}
+
+// words splits text into normalized words. A word is letters and
+// digits, with apostrophes inside it, like "don't" and "gopher's".
+func words(text string) []string {
+	fields := strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\'' && r != '’'
+	})
+	var ws []string
+	for _, f := range fields {
+		if w := normalize(f); w != "" {
+			ws = append(ws, w)
+		}
+	}
+	return ws
+}
+
+// normalize lowercases w, turns curly apostrophes into straight ones,
+// and trims the apostrophes around it, like quotes.
+func normalize(w string) string {
+	w = strings.ReplaceAll(strings.ToLower(w), "’", "'")
+	return strings.Trim(w, "'")
+}
@@ -0,0 +1,103 @@
+package wordcount_test
+
+import (
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/inancgumus/learngo/36-testing/07-test-organization/wordcount"
+)
+
+// These tests are in package wordcount_test, and import wordcount like
+// any other package does. They can use only its exported API, so they
+// test what a user sees, and they keep passing when the internals change
+
+// gopherText reads testdata/gopher.txt once, for every test that needs
+// it. Parallel tests may call it at the same moment: sync.OnceValue
+// makes the others wait for the first. They only read the text, so
+// sharing it is safe
+var gopherText = sync.OnceValue(func() string {
+	b, err := os.ReadFile("testdata/gopher.txt")
+	if err != nil {
+		panic(err)
+	}
+	return string(b)
+})
+
+func TestCount(t *testing.T) {
+	tests := []struct {
+		name string
+		stop []string
+		text string
+		want map[string]int
+	}{
+		{"no stop words", nil, "the go test", map[string]int{"the": 1, "go": 1, "test": 1}},
+		{"stop words", wordcount.English, "the go test", map[string]int{"go": 1, "test": 1}},
+		{"stop words in any case", []string{"GO"}, "Go go gO", map[string]int{}},
+		{"empty", nil, "", map[string]int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			// Each case makes its own Counter with its own stop words. If
+			// they were a package variable, a case that changed it would
+			// change it for the cases running beside it
+			got := wordcount.New(tt.stop...).Count(tt.text)
+			if !equalCounts(got, tt.want) {
+				t.Errorf("Count(%q) = %v; want %v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTop(t *testing.T) {
+	english := wordcount.New(wordcount.English...) // shared, and never changed
+
+	tests := []struct {
+		name string
+		n    int
+		want []wordcount.Entry
+	}{
+		{"top 2", 2, []wordcount.Entry{{"test", 7}, {"gopher", 5}}},
+		{"ties in order", 5, []wordcount.Entry{{"test", 7}, {"gopher", 5}, {"code", 3}, {"doesn't", 3}, {"tests", 3}}},
+		{"zero", 0, []wordcount.Entry{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := english.Top(gopherText(), tt.n); !slices.Equal(got, tt.want) {
+				t.Errorf("Top(%d) = %v; want %v", tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTopAll(t *testing.T) {
+	t.Parallel()
+	text := gopherText()
+	entries := wordcount.New().Top(text, 1000)
+
+	// With no stop words, the counts add up to the number of words.
+	// Words comes from export_test.go: an unexported function, exported
+	// to this package only
+	total := 0
+	for _, e := range entries {
+		total += e.Count
+	}
+	if want := len(wordcount.Words(text)); total != want {
+		t.Errorf("counts add up to %d; want %d words", total, want)
+	}
+}
+
+func equalCounts(a, b map[string]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for k, v := range a {
+		if b[k] != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,46 @@
+package wordcount
+
+import (
+	"slices"
+	"testing"
+)
+
+// These tests are in package wordcount, so they can call the unexported
+// words and normalize directly. They test the details the API hides
+
+func TestWords(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want []string
+	}{
+		{"spaces", "go  test\tcover\n", []string{"go", "test", "cover"}},
+		{"punctuation", "go, test; cover!", []string{"go", "test", "cover"}},
+		{"case", "Go GO go", []string{"go", "go", "go"}},
+		{"digits", "go 1.25", []string{"go", "1", "25"}},
+		{"apostrophe inside", "don't", []string{"don't"}},
+		{"curly apostrophe", "gopher’s", []string{"gopher's"}},
+		{"quotes around", "'quoted'", []string{"quoted"}},
+		{"only apostrophes", "'' '", nil},
+		{"letters", "çay über", []string{"çay", "über"}},
+		{"empty", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			if got := words(tt.in); !slices.Equal(got, tt.want) {
+				t.Errorf("words(%q) = %q; want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	// Stop words are normalized like the words of a text
+	c := New("The", "’Tis'")
+	for _, w := range []string{"the", "tis"} {
+		if !c.stop[w] {
+			t.Errorf("want %q stored as a stop word; got %v", w, c.stop)
+		}
+	}
+}
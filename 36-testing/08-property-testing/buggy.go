@@ -0,0 +1,59 @@
+package main
+
+// buggyDeque is generics.Deque for ints, with a bug in grow: it copies
+// the old buffer as it is, and doesn't move the front item back to index
+// 0. That's only wrong when the items wrap around the end of the buffer,
+// which takes a PushFront, and then enough pushes to fill the buffer.
+// A handful of hand-written cases can easily miss that
+type buggyDeque struct {
+	buf  []int
+	head int
+	n    int
+}
+
+func (d *buggyDeque) Len() int { return d.n }
+
+func (d *buggyDeque) PushBack(item int) {
+	d.grow()
+	d.buf[d.index(d.n)] = item
+	d.n++
+}
+
+func (d *buggyDeque) PushFront(item int) {
+	d.grow()
+	d.head = d.index(len(d.buf) - 1)
+	d.buf[d.head] = item
+	d.n++
+}
+
+func (d *buggyDeque) PopFront() (int, bool) {
+	if d.n == 0 {
+		return 0, false
+	}
+	item := d.buf[d.head]
+	d.head = d.index(1)
+	d.n--
+	return item, true
+}
+
+func (d *buggyDeque) PopBack() (int, bool) {
+	if d.n == 0 {
+		return 0, false
+	}
+	item := d.buf[d.index(d.n-1)]
+	d.n--
+	return item, true
+}
+
+func (d *buggyDeque) index(i int) int {
+	return (d.head + i) & (len(d.buf) - 1)
+}
+
+func (d *buggyDeque) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+	buf := make([]int, max(8, 2*len(d.buf)))
+	copy(buf, d.buf) // BUG: the items may wrap around the end
+	d.buf = buf
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"slices"
+	"testing/quick"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+func main() {
+	fmt.Println("Property-Based Testing with testing/quick")
+	fmt.Println("=========================================")
+	fmt.Println()
+
+	example1()
+	example2()
+	found := example3()
+	example4(found)
+}
+
+// Example 1: A property, checked on random inputs
+func example1() {
+	fmt.Println("1. reverse(reverse(s)) == s, on 100 random slices:")
+	var tried [][]int8
+	err := quick.Check(func(s []int8) bool {
+		tried = append(tried, s)
+		return slices.Equal(reverse(reverse(s)), s)
+	}, nil)
+	fmt.Printf("   err = %v, after %d inputs\n", err, len(tried))
+	for _, s := range tried[:3] {
+		fmt.Printf("   len %2d: %v\n", len(s), s[:min(len(s), 8)])
+	}
+	fmt.Println()
+}
+
+// Example 2: Generators for the inputs quick can't make well
+func example2() {
+	fmt.Println("2. Inputs from hand-written generators:")
+	v, _ := quick.Value(reflect.TypeFor[[]int](), rand.New(rand.NewSource(1)))
+	fmt.Printf("   quick's own []int: %v ...\n", v.Interface().([]int)[:2])
+
+	r := rand.New(rand.NewSource(2))
+	a := small{}.Generate(r, 10).Interface().(small)
+	b := small{}.Generate(r, 10).Interface().(small)
+	inter := generics.NewSet(a...).Intersection(generics.NewSet(b...))
+	fmt.Printf("   small: %v and %v share %v\n", a, b, generics.Sorted(inter))
+
+	s := ops{}.Generate(r, 5).Interface().(ops)
+	fmt.Printf("   ops:   %v\n", s)
+	fmt.Println()
+}
+
+// Example 3: A property finds a bug
+func example3() ops {
+	fmt.Println("3. The deque property against the buggy deque:")
+	fails := func(s ops) bool { return run(&buggyDeque{}, s) != nil }
+	err := quick.Check(func(s ops) bool { return !fails(s) }, &quick.Config{
+		MaxCount: 1000,
+		Rand:     rand.New(rand.NewSource(1)), // the same inputs on every run
+	})
+
+	var ce *quick.CheckError
+	if !errors.As(err, &ce) {
+		fmt.Println("   no failure found:", err)
+		return nil
+	}
+	found := ce.In[0].(ops)
+	fmt.Printf("   input %d failed: %d calls\n", ce.Count, len(found))
+	fmt.Printf("   %v\n", run(&buggyDeque{}, found))
+	fmt.Println()
+	return found
+}
+
+// Example 4: Shrinking by hand
+func example4(found ops) {
+	if found == nil {
+		return
+	}
+	fails := func(s ops) bool { return run(&buggyDeque{}, s) != nil }
+
+	fmt.Println("4. The same input, shrunk by dropping calls while it still fails:")
+	shrunk := shrink(found, fails)
+	fmt.Printf("   %d calls: %v\n", len(shrunk), shrunk)
+	fmt.Printf("   %v\n", run(&buggyDeque{}, shrunk))
+	fmt.Printf("   generics.Deque passes it: %v\n", run(&generics.Deque[int]{}, shrunk) == nil)
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"math/rand"
+	"slices"
+	"testing"
+	"testing/quick"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+// The buggy deque fails TestBuggyDeque. It runs only with -buggy:
+//
+//	go test -run TestBuggyDeque -buggy
+var buggy = flag.Bool("buggy", false, "also check the buggy deque")
+
+// check runs quick.Check on property, and fails the test with the input
+// that broke it. quick tries 100 random inputs by default; 1000 costs
+// little here
+func check(t *testing.T, property any) {
+	t.Helper()
+	if err := quick.Check(property, &quick.Config{MaxCount: 1000}); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestReverseTwice(t *testing.T) {
+	check(t, func(s []int) bool {
+		return slices.Equal(reverse(reverse(s)), s)
+	})
+}
+
+// TestReverseMirrors pins down what reverse does. reverse(reverse(s))
+// == s alone would pass a reverse that returned s unchanged
+func TestReverseMirrors(t *testing.T) {
+	check(t, func(s []string) bool {
+		r := reverse(s)
+		if len(r) != len(s) {
+			return false
+		}
+		for i := range s {
+			if r[i] != s[len(s)-1-i] {
+				return false
+			}
+		}
+		return true
+	})
+}
+
+// TestFilterSubset checks FilterSeq with a keep function made from a
+// random number: quick can't make random functions, but it can make
+// the number that picks one
+func TestFilterSubset(t *testing.T) {
+	check(t, func(s []int, m uint8) bool {
+		keep := func(v int) bool { return v%(int(m)+1) == 0 }
+		got := slices.Collect(generics.FilterSeq(slices.Values(s), keep))
+
+		// Every item of got is kept, and in s, in the same order: got
+		// is a subsequence of s. The items of s left out aren't kept
+		i := 0
+		for _, v := range s {
+			if i < len(got) && got[i] == v {
+				i++
+			} else if keep(v) {
+				return false
+			}
+		}
+		return i == len(got)
+	})
+}
+
+// TestFilterMatchesLoop compares FilterSeq with the obvious loop, for
+// the same inputs. quick.CheckEqual calls both functions, and fails
+// when their results differ
+func TestFilterMatchesLoop(t *testing.T) {
+	even := func(v int) bool { return v%2 == 0 }
+	filterSeq := func(s []int) []int {
+		return slices.Collect(generics.FilterSeq(slices.Values(s), even))
+	}
+	loop := func(s []int) []int {
+		var out []int
+		for _, v := range s {
+			if even(v) {
+				out = append(out, v)
+			}
+		}
+		return out
+	}
+	if err := quick.CheckEqual(filterSeq, loop, nil); err != nil {
+		t.Error(err)
+	}
+}
+
+// TestStackRoundTrip uses a Deque as a stack: pushing s then popping
+// everything gives s backwards, and leaves the stack empty
+func TestStackRoundTrip(t *testing.T) {
+	check(t, func(s []int) bool {
+		var stack generics.Deque[int]
+		for _, v := range s {
+			stack.PushBack(v)
+		}
+		var popped []int
+		for {
+			v, ok := stack.PopBack()
+			if !ok {
+				break
+			}
+			popped = append(popped, v)
+		}
+		return stack.Len() == 0 && slices.Equal(popped, reverse(s))
+	})
+}
+
+// TestPushPop checks that a push and a pop undo each other, on a stack
+// that already holds s
+func TestPushPop(t *testing.T) {
+	check(t, func(s []int, v int) bool {
+		var stack generics.Deque[int]
+		for _, x := range s {
+			stack.PushBack(x)
+		}
+		stack.PushBack(v)
+		got, ok := stack.PopBack()
+		return ok && got == v && slices.Equal(slices.Collect(stack.Iter()), s)
+	})
+}
+
+func TestSetAlgebra(t *testing.T) {
+	check(t, func(a, b small) bool {
+		sa, sb := generics.NewSet(a...), generics.NewSet(b...)
+		union, inter, diff := sa.Union(sb), sa.Intersection(sb), sa.Difference(sb)
+
+		for v := range 10 {
+			inA, inB := sa.Contains(v), sb.Contains(v)
+			if union.Contains(v) != (inA || inB) ||
+				inter.Contains(v) != (inA && inB) ||
+				diff.Contains(v) != (inA && !inB) {
+				return false
+			}
+		}
+		return union.Len() == sa.Len()+sb.Len()-inter.Len()
+	})
+}
+
+// TestDequeOps is the model test of pkg/generics, with random sequences
+// of calls from the ops generator
+func TestDequeOps(t *testing.T) {
+	check(t, func(s ops) bool {
+		return run(&generics.Deque[int]{}, s) == nil
+	})
+}
+
+// TestBuggyDeque runs the same property against the buggy deque. When
+// quick finds a failing sequence, the test shrinks it before reporting
+func TestBuggyDeque(t *testing.T) {
+	if !*buggy {
+		t.Skip("fails on purpose; run with -buggy")
+	}
+	fails := func(s ops) bool { return run(&buggyDeque{}, s) != nil }
+
+	err := quick.Check(func(s ops) bool { return !fails(s) }, &quick.Config{MaxCount: 1000})
+	var ce *quick.CheckError
+	if !errors.As(err, &ce) {
+		t.Fatalf("quick found no failing sequence: %v", err)
+	}
+	found := ce.In[0].(ops)
+	shrunk := shrink(found, fails)
+	t.Errorf("quick found %d calls; shrunk to %d:\n%v\n%v", len(found), len(shrunk), shrunk, run(&buggyDeque{}, shrunk))
+}
+
+// TestShrink checks the shrinker on a sequence the buggy deque fails:
+// the result still fails, and dropping any one call makes it pass
+func TestShrink(t *testing.T) {
+	fails := func(s ops) bool { return run(&buggyDeque{}, s) != nil }
+
+	r := rand.New(rand.NewSource(1))
+	var s ops
+	for s = nil; !fails(s); {
+		s = ops{}.Generate(r, 50).Interface().(ops)
+	}
+	shrunk := shrink(s, fails)
+
+	if !fails(shrunk) {
+		t.Fatalf("shrunk sequence passes: %v", shrunk)
+	}
+	for i := range shrunk {
+		if shorter := slices.Delete(slices.Clone(shrunk), i, i+1); fails(shorter) {
+			t.Errorf("still fails without call %d, %v: not shrunk all the way", i+1, shrunk[i])
+		}
+	}
+	t.Logf("%d calls shrunk to %d: %v", len(s), len(shrunk), shrunk)
+}
+
+// TestDequeRegressions keeps the sequences that broke a deque once, as
+// fixed cases. A random run may never find them again. The first is the
+// shrunk sequence that broke buggyDeque's grow
+func TestDequeRegressions(t *testing.T) {
+	tests := []ops{
+		{{pushFront, 49}, {pushFront, 50}, {pushBack, 51}, {pushFront, 52}, {pushFront, 53},
+			{pushFront, 56}, {pushBack, 57}, {pushBack, 58}, {pushBack, 63}},
+	}
+	for _, s := range tests {
+		if err := run(&generics.Deque[int]{}, s); err != nil {
+			t.Errorf("%v: %v", s, err)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"strings"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+// reverse returns the items of s in reverse order, by pushing each one
+// at the front of a deque. It works the deque's wrap-around: the first
+// PushFront already wraps to the end of the buffer
+func reverse[T any](s []T) []T {
+	var d generics.Deque[T]
+	for _, v := range s {
+		d.PushFront(v)
+	}
+	out := make([]T, 0, len(s))
+	for v := range d.Iter() {
+		out = append(out, v)
+	}
+	return out
+}
+
+// small is a slice of ints from 0 to 9. quick's ints are spread over
+// the whole int range, so two random slices almost never share an item:
+// every intersection would be empty, and the properties below would
+// pass without testing much
+type small []int
+
+func (small) Generate(r *rand.Rand, size int) reflect.Value {
+	s := make(small, r.Intn(size+1))
+	for i := range s {
+		s[i] = r.Intn(10)
+	}
+	return reflect.ValueOf(s)
+}
+
+// deque is what the operation tests need of a deque: generics.Deque and
+// buggyDeque both have it
+type deque interface {
+	PushBack(int)
+	PushFront(int)
+	PopBack() (int, bool)
+	PopFront() (int, bool)
+	Len() int
+}
+
+type opKind uint8
+
+const (
+	pushBack opKind = iota
+	pushFront
+	popBack
+	popFront
+)
+
+// op is one call on a deque. Pushes push value; pops ignore it
+type op struct {
+	kind  opKind
+	value int
+}
+
+func (o op) String() string {
+	switch o.kind {
+	case pushBack:
+		return fmt.Sprintf("PushBack(%d)", o.value)
+	case pushFront:
+		return fmt.Sprintf("PushFront(%d)", o.value)
+	case popBack:
+		return "PopBack()"
+	}
+	return "PopFront()"
+}
+
+// ops is a sequence of calls. testing/quick can't make a useful one on
+// its own: it would fill the unexported fields with nothing, and pick
+// pushes and pops equally often, so the deque would rarely grow past a
+// few items. Generate makes quick call this instead
+type ops []op
+
+// Generate returns up to 2*size calls, two pushes for every pop, so
+// that the deque grows and its buffer wraps around. The values count up
+// from 1, which makes a failing sequence easy to follow
+func (ops) Generate(r *rand.Rand, size int) reflect.Value {
+	s := make(ops, r.Intn(2*size+1))
+	for i := range s {
+		s[i] = op{kind: pushBack, value: i + 1}
+		switch r.Intn(6) {
+		case 0, 1:
+		case 2, 3:
+			s[i].kind = pushFront
+		case 4:
+			s[i] = op{kind: popBack}
+		case 5:
+			s[i] = op{kind: popFront}
+		}
+	}
+	return reflect.ValueOf(s)
+}
+
+func (s ops) String() string {
+	names := make([]string, len(s))
+	for i, o := range s {
+		names[i] = o.String()
+	}
+	return strings.Join(names, ", ")
+}
+
+// run calls s on d, and on a slice that does what a deque should. It
+// returns an error for the first call where the two differ, and checks
+// the items left at the end. This is the property: a deque does what
+// the slice does, for any sequence of calls
+func run(d deque, s ops) error {
+	var model []int
+	for i, o := range s {
+		switch o.kind {
+		case pushBack:
+			d.PushBack(o.value)
+			model = append(model, o.value)
+		case pushFront:
+			d.PushFront(o.value)
+			model = append([]int{o.value}, model...)
+		case popBack, popFront:
+			var got int
+			var ok bool
+			want, wantOK := 0, len(model) > 0
+			if o.kind == popBack {
+				got, ok = d.PopBack()
+				if wantOK {
+					want, model = model[len(model)-1], model[:len(model)-1]
+				}
+			} else {
+				got, ok = d.PopFront()
+				if wantOK {
+					want, model = model[0], model[1:]
+				}
+			}
+			if got != want || ok != wantOK {
+				return fmt.Errorf("call %d, %v = %d, %t; want %d, %t", i+1, o, got, ok, want, wantOK)
+			}
+		}
+		if d.Len() != len(model) {
+			return fmt.Errorf("after call %d, %v: Len = %d; want %d", i+1, o, d.Len(), len(model))
+		}
+	}
+
+	for i, want := range model {
+		if got, _ := d.PopFront(); got != want {
+			return fmt.Errorf("after all calls: item %d is %d; want %d, in %v", i, got, want, model)
+		}
+	}
+	return nil
+}
+
+// shrink makes a failing sequence smaller. testing/quick reports the
+// first input that failed, which is long and mostly noise; it doesn't
+// shrink it the way other property testing libraries do. shrink does it
+// by hand: it drops one call at a time, and keeps the shorter sequence
+// whenever it still fails. It stops when dropping any one call makes
+// the sequence pass
+func shrink(s ops, fails func(ops) bool) ops {
+	for removed := true; removed; {
+		removed = false
+		for i := 0; i < len(s); i++ {
+			shorter := append(s[:i:i], s[i+1:]...)
+			if fails(shorter) {
+				s, removed = shorter, true
+				i--
+			}
+		}
+	}
+	return s
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/inancgumus/learngo/36-testing/09-example-functions/slug"
+)
+
+func main() {
+	fmt.Println("Example Functions")
+	fmt.Println("=================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+}
+
+// Example 1: A print-based demo, and the same demo as an example
+func example1() {
+	fmt.Println("1. A demo in main, and the same demo as an example:")
+	fmt.Println("   main prints, and nobody checks:")
+	fmt.Printf("      slug.Make(%q) = %q\n", "  Testing in Go: Examples  ",
+		slug.Make("  Testing in Go: Examples  "))
+
+	pkg, fset, err := load("slug")
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	for _, p := range placements(pkg) {
+		if p.ex.Name != "Make" {
+			continue
+		}
+		fmt.Println("   an example prints, and go test checks:")
+		fmt.Printf("      func Example%s() ", p.ex.Name)
+		fmt.Print(strings.TrimPrefix(indent(source(fset, p.ex.Code), "      "), "      "))
+		fmt.Printf("      // Output: %s", p.ex.Output)
+	}
+	fmt.Println()
+}
+
+// Example 2: Where godoc puts each example
+func example2() {
+	fmt.Println("2. The examples of package slug, as godoc attaches them:")
+	pkg, _, err := load("slug")
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	for _, p := range placements(pkg) {
+		fmt.Printf("   %-28s -> %-20s %s\n", "Example"+p.ex.Name, p.where, describe(p.ex))
+	}
+	fmt.Println()
+}
+
+// Example 3: How go test compares the output
+func example3() {
+	fmt.Println("3. How go test compares output with the comment:")
+	tests := []struct {
+		name, got, want string
+		unordered       bool
+	}{
+		{"same text", "a\nb\n", "a\nb", false},
+		{"extra blank lines", "\n\na\nb\n\n", "a\nb", false},
+		{"space inside a line", "a  b\n", "a b", false},
+		{"lines swapped", "b\na\n", "a\nb", false},
+		{"swapped, unordered", "b\na\n", "a\nb", true},
+		{"missing, unordered", "a\n", "a\nb", true},
+	}
+	for _, tt := range tests {
+		fmt.Printf("   %-20s %-16q vs %-8q -> %v\n", tt.name+":", tt.got, tt.want,
+			outputMatches(tt.got, tt.want, tt.unordered))
+	}
+	fmt.Println()
+}
+
+// Example 4: The examples of the course's public packages
+func example4() {
+	fmt.Println("4. Examples in the course's public packages:")
+	for _, dir := range []string{"../../pkg/generics", "../../pkg/retry", "../../pkg/workerpool"} {
+		pkg, _, err := load(dir)
+		if err != nil {
+			fmt.Println("   error:", err)
+			return
+		}
+		var checked, unordered int
+		ps := placements(pkg)
+		for _, p := range ps {
+			if p.ex.Output != "" || p.ex.EmptyOutput {
+				checked++
+			}
+			if p.ex.Unordered {
+				unordered++
+			}
+		}
+		with, total := documented(pkg)
+		fmt.Printf("   %-10s %2d examples, %2d checked, %d unordered; examples for %2d of %2d identifiers\n",
+			pkg.Name, len(ps), checked, unordered, with, total)
+	}
+	fmt.Println()
+}
+
+// load parses the Go files in dir, tests included, the way godoc does
+func load(dir string) (*doc.Package, *token.FileSet, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return nil, nil, err
+	}
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, path := range paths {
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, f)
+	}
+	pkg, err := doc.NewFromFiles(fset, files, "example.com/"+filepath.Base(dir))
+	return pkg, fset, err
+}
+
+// placement is an example and the identifier it documents
+type placement struct {
+	ex    *doc.Example
+	where string
+}
+
+// placements lists every example of pkg, with where godoc shows it
+func placements(pkg *doc.Package) []placement {
+	var ps []placement
+	add := func(where string, exs []*doc.Example) {
+		for _, ex := range exs {
+			ps = append(ps, placement{ex, where})
+		}
+	}
+	add("package "+pkg.Name, pkg.Examples)
+	for _, f := range pkg.Funcs {
+		add("func "+f.Name, f.Examples)
+	}
+	for _, t := range pkg.Types {
+		add("type "+t.Name, t.Examples)
+		for _, f := range t.Funcs {
+			add("func "+f.Name, f.Examples)
+		}
+		for _, m := range t.Methods {
+			add("method "+t.Name+"."+m.Name, m.Examples)
+		}
+	}
+	return ps
+}
+
+// documented counts the exported functions, types, and methods of pkg,
+// and how many of them have at least one example
+func documented(pkg *doc.Package) (with, total int) {
+	count := func(exs []*doc.Example) {
+		total++
+		if len(exs) > 0 {
+			with++
+		}
+	}
+	for _, f := range pkg.Funcs {
+		count(f.Examples)
+	}
+	for _, t := range pkg.Types {
+		count(t.Examples)
+		for _, f := range t.Funcs {
+			count(f.Examples)
+		}
+		for _, m := range t.Methods {
+			count(m.Examples)
+		}
+	}
+	return with, total
+}
+
+// describe says how go test treats ex
+func describe(ex *doc.Example) string {
+	s := "checked"
+	switch {
+	case ex.Unordered:
+		s = "checked, unordered"
+	case ex.Output == "" && !ex.EmptyOutput:
+		s = "compiled, not run"
+	}
+	if ex.Suffix != "" {
+		s += fmt.Sprintf(", suffix %q", ex.Suffix)
+	}
+	return s
+}
+
+// outputMatches compares the way the testing package does: both sides
+// are trimmed of leading and trailing white space, and with unordered,
+// their lines are sorted first
+func outputMatches(got, want string, unordered bool) bool {
+	got, want = strings.TrimSpace(got), strings.TrimSpace(want)
+	if unordered {
+		got, want = sortLines(got), sortLines(want)
+	}
+	return got == want
+}
+
+func sortLines(s string) string {
+	lines := strings.Split(s, "\n")
+	slices.Sort(lines)
+	return strings.Join(lines, "\n")
+}
+
+// source formats an example's body, without the blank lines left where
+// its output comment was
+func source(fset *token.FileSet, node ast.Node) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, node); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	var b strings.Builder
+	for line := range strings.Lines(buf.String() + "\n") {
+		if strings.TrimSpace(line) != "" {
+			b.WriteString(line)
+		}
+	}
+	return b.String()
+}
+
+// indent prefixes every line of s
+func indent(s, prefix string) string {
+	var b strings.Builder
+	for line := range strings.Lines(s) {
+		b.WriteString(prefix + line)
+	}
+	return b.String()
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"testing"
+)
+
+func TestOutputMatches(t *testing.T) {
+	tests := []struct {
+		got, want string
+		unordered bool
+		match     bool
+	}{
+		{"a\nb\n", "a\nb", false, true},
+		{"\n a\nb \n", "a\nb", false, true},
+		{"a  b", "a b", false, false},
+		{"b\na", "a\nb", false, false},
+		{"b\na", "a\nb", true, true},
+		{"a\na", "a", true, false},
+		{"", "", false, true},
+	}
+	for _, tt := range tests {
+		if got := outputMatches(tt.got, tt.want, tt.unordered); got != tt.match {
+			t.Errorf("outputMatches(%q, %q, %v) = %v, want %v",
+				tt.got, tt.want, tt.unordered, got, tt.match)
+		}
+	}
+}
+
+func TestPlacements(t *testing.T) {
+	pkg, _, err := load("slug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{
+		"":                      "package slug",
+		"Make":                  "func Make",
+		"Make_unicode":          "func Make",
+		"Slugger":               "type Slugger",
+		"Slugger_Make_maxLen":   "method Slugger.Make",
+		"Slugger_Make_noOutput": "method Slugger.Make",
+	}
+	ps := placements(pkg)
+	if len(ps) != len(want) {
+		t.Errorf("got %d examples, want %d", len(ps), len(want))
+	}
+	for _, p := range ps {
+		if w, ok := want[p.ex.Name]; !ok || p.where != w {
+			t.Errorf("Example%s is on %q, want %q", p.ex.Name, p.where, w)
+		}
+	}
+}
+
+// TestNoOrphanExamples fails when an example's name matches nothing in
+// its package: godoc drops such examples without a word, and only a
+// full go vet reports them, not the few checks go test runs
+func TestNoOrphanExamples(t *testing.T) {
+	for _, dir := range []string{"slug", "../../pkg/generics", "../../pkg/retry", "../../pkg/workerpool"} {
+		pkg, _, err := load(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		tests, _ := filepath.Glob(filepath.Join(dir, "*_test.go"))
+		fset := token.NewFileSet()
+		var files []*ast.File
+		for _, path := range tests {
+			f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+			if err != nil {
+				t.Fatal(err)
+			}
+			files = append(files, f)
+		}
+
+		all, placed := len(doc.Examples(files...)), len(placements(pkg))
+		if all != placed {
+			t.Errorf("%s: %d examples, but godoc shows %d", dir, all, placed)
+		}
+	}
+}
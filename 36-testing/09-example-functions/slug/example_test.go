@@ -0,0 +1,54 @@
+package slug_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/inancgumus/learngo/36-testing/09-example-functions/slug"
+)
+
+// Example is the package example: godoc shows it in the overview, above
+// the index.
+func Example() {
+	for _, title := range []string{"Hello, World!", "Go 1.25 Release Notes"} {
+		fmt.Printf("/posts/%s\n", slug.Make(title))
+	}
+	// Output:
+	// /posts/hello-world
+	// /posts/go-1-25-release-notes
+}
+
+// ExampleMake documents the function Make.
+func ExampleMake() {
+	fmt.Println(slug.Make("  Testing in Go: Examples  "))
+	// Output: testing-in-go-examples
+}
+
+// A suffix after an underscore, starting with a lowercase letter, adds
+// a second example to the same identifier. Godoc titles it "Unicode".
+func ExampleMake_unicode() {
+	fmt.Println(slug.Make("Crème Brûlée"))
+	// Output: crème-brûlée
+}
+
+// ExampleSlugger documents the type Slugger.
+func ExampleSlugger() {
+	sl := slug.Slugger{Sep: "_"}
+	fmt.Println(sl.Make("Table-Driven Tests"))
+	// Output: table_driven_tests
+}
+
+// ExampleSlugger_Make_maxLen documents the method Slugger.Make; maxLen
+// is the suffix.
+func ExampleSlugger_Make_maxLen() {
+	sl := slug.Slugger{MaxLen: 12}
+	fmt.Println(sl.Make("Property-Based Testing"))
+	// Output: property
+}
+
+// An example without an output comment is compiled but never run, for
+// code whose output changes from run to run, like this one's.
+func ExampleSlugger_Make_noOutput() {
+	sl := slug.Slugger{MaxLen: 20}
+	fmt.Println(sl.Make("Backup " + time.Now().Format(time.DateOnly)))
+}
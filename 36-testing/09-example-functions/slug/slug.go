@@ -0,0 +1,56 @@
+// Package slug turns titles into URL path segments, like
+// "Hello, World!" into "hello-world".
+//
+// It exists to be documented: its example_test.go has one example of
+// each kind that godoc renders, and go test checks their output.
+package slug
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Make returns the slug of s with the default settings: lowercase words
+// joined by hyphens, and no length limit.
+func Make(s string) string {
+	return Slugger{}.Make(s)
+}
+
+// Slugger makes slugs with custom settings. The zero value uses "-" as
+// the separator and no length limit.
+type Slugger struct {
+	// Sep joins the words. Empty means "-".
+	Sep string
+	// MaxLen is the longest slug Make returns, in bytes. Whole words are
+	// dropped from the end to fit. Zero means no limit.
+	MaxLen int
+}
+
+// Make returns the slug of s: its letters and digits, lowercased, with
+// every run of other characters replaced by one separator.
+func (sl Slugger) Make(s string) string {
+	sep := sl.Sep
+	if sep == "" {
+		sep = "-"
+	}
+
+	words := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var b strings.Builder
+	for _, w := range words {
+		n := len(w)
+		if b.Len() > 0 {
+			n += len(sep)
+		}
+		if sl.MaxLen > 0 && b.Len()+n > sl.MaxLen {
+			break
+		}
+		if b.Len() > 0 {
+			b.WriteString(sep)
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
@@ -0,0 +1,42 @@
+package slug_test
+
+import (
+	"testing"
+
+	"github.com/inancgumus/learngo/36-testing/09-example-functions/slug"
+)
+
+func TestMake(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"", ""},
+		{"Go", "go"},
+		{"Hello, World!", "hello-world"},
+		{"  --Go 1.25--  ", "go-1-25"},
+		{"Crème Brûlée", "crème-brûlée"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := slug.Make(tt.in); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSluggerMaxLen(t *testing.T) {
+	sl := slug.Slugger{Sep: "_", MaxLen: 10}
+	tests := []struct {
+		in, want string
+	}{
+		{"one two", "one_two"},
+		{"one two three", "one_two"},
+		{"abcdefghij", "abcdefghij"},
+		{"abcdefghijk", ""},
+	}
+	for _, tt := range tests {
+		if got := sl.Make(tt.in); got != tt.want {
+			t.Errorf("Make(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
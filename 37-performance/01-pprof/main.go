@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// entries is how many log lines the report has: enough for the slow
+// version to run for about a second, so the profiler gets samples
+const entries = 40_000
+
+func main() {
+	serve := flag.String("serve", "", "serve net/http/pprof on this address, under load, until Ctrl+C")
+	flag.Parse()
+	if *serve != "" {
+		if err := serveLoad(*serve); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	fmt.Println("Profiling with pprof")
+	fmt.Println("====================")
+	fmt.Println()
+
+	dir := filepath.Join(os.TempDir(), "learngo-pprof")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		log.Fatal(err)
+	}
+
+	example1(dir)
+	example2(dir)
+	example3(dir)
+	example4(dir)
+	example5(dir)
+
+	fmt.Println("Profiles are in", dir)
+}
+
+// Example 1: Timing the slow and the fast report
+func example1(dir string) {
+	fmt.Printf("1. Writing a report of %d entries to a file:\n", entries)
+	es := makeEntries(entries, 1)
+	for _, v := range []struct {
+		name  string
+		write func(io.Writer, []entry) error
+	}{
+		{"slow", writeReportSlow},
+		{"fast", writeReportFast},
+	} {
+		path := filepath.Join(dir, "report-"+v.name+".txt")
+		start := time.Now()
+		if err := writeFile(path, es, v.write); err != nil {
+			fmt.Println("   error:", err)
+			return
+		}
+		fmt.Printf("   %s: %v\n", v.name, time.Since(start).Round(time.Millisecond))
+	}
+
+	slow, _ := os.ReadFile(filepath.Join(dir, "report-slow.txt"))
+	fast, _ := os.ReadFile(filepath.Join(dir, "report-fast.txt"))
+	fmt.Printf("   same %d bytes: %v\n", len(slow), bytes.Equal(slow, fast))
+	fmt.Println()
+}
+
+// Example 2: A CPU profile with runtime/pprof
+func example2(dir string) {
+	fmt.Println("2. The slow report's CPU profile, go tool pprof -top:")
+	es := makeEntries(entries, 1)
+	path := filepath.Join(dir, "cpu-slow.prof")
+	err := profileCPU(path, func() {
+		writeFile(filepath.Join(dir, "report-slow.txt"), es, writeReportSlow)
+	})
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+
+	out, err := pprofTool("-top", "-nodecount=8", path)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	printTable(out)
+	fmt.Println()
+}
+
+// Example 3: The data behind a flame graph
+func example3(dir string) {
+	fmt.Println("3. The same profile folded into stacks, as a flame graph draws it:")
+	out, err := pprofTool("-traces", filepath.Join(dir, "cpu-slow.prof"))
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	stacks, err := fold(strings.NewReader(out), "main.writeReportSlow", 3)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	for _, s := range stacks[:min(len(stacks), 6)] {
+		fmt.Printf("   %6v  %s\n", s.Took, strings.Join(s.Frames, " > "))
+	}
+	fmt.Println()
+}
+
+// Example 4: Heap profiles, before and after
+func example4(dir string) {
+	fmt.Println("4. Bytes allocated by each version, from the allocs profile:")
+	es := makeEntries(entries, 1)
+	path := filepath.Join(dir, "allocs.prof")
+	err := profileAllocs(path, func() {
+		writeReportSlow(io.Discard, es)
+		writeReportFast(io.Discard, es)
+	})
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+
+	out, err := pprofTool("-top", "-cum", "-sample_index=alloc_space",
+		"-focus=profileAllocs", "-show=writeReport", "-nodefraction=0",
+		"-relative_percentages", path)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	printTable(out)
+	fmt.Println()
+}
+
+// Example 5: Profiles over HTTP with net/http/pprof
+func example5(dir string) {
+	fmt.Println("5. A CPU profile fetched from /debug/pprof/profile:")
+	ln, err := net.Listen("tcp", "localhost:0")
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	srv := &http.Server{Handler: pprofMux()}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go load(ctx)
+
+	url := "http://" + ln.Addr().String() + "/debug/pprof/profile?seconds=1"
+	fmt.Println("   GET", url)
+	path := filepath.Join(dir, "cpu-http.prof")
+	if err := download(url, path); err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	cancel()
+
+	out, err := pprofTool("-top", "-nodecount=4", path)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	printTable(out)
+	fmt.Println()
+}
+
+// writeFile writes the report of es to a new file at path, with write
+func writeFile(path string, es []entry, write func(io.Writer, []entry) error) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := write(f, es); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// pprofMux serves the net/http/pprof handlers. Importing the package
+// registers them on http.DefaultServeMux too; a mux of its own keeps
+// them off the public one
+func pprofMux() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// load writes slow reports until ctx is done, to give the profiler
+// something to see
+func load(ctx context.Context) {
+	es := makeEntries(entries/4, 2)
+	for ctx.Err() == nil {
+		writeReportSlow(io.Discard, es)
+	}
+}
+
+// serveLoad serves pprofMux on addr, with load running, until Ctrl+C
+func serveLoad(addr string) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go load(ctx)
+
+	srv := &http.Server{Addr: addr, Handler: pprofMux()}
+	go func() {
+		<-ctx.Done()
+		srv.Close()
+	}()
+	log.Printf("pprof on http://%s/debug/pprof/, Ctrl+C to stop", addr)
+	if err := srv.ListenAndServe(); !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// download saves the body of a GET of url to path
+func download(url, path string) error {
+	resp, err := http.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// printTable prints the table of pprof's -top output, from its header
+// line on, without the file and build details above it
+func printTable(out string) {
+	table := false
+	for line := range strings.Lines(out) {
+		if strings.Contains(line, "flat%") {
+			table = true
+		}
+		if table {
+			fmt.Print("   ", line)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestReportsMatch(t *testing.T) {
+	for _, n := range []int{0, 1, 100, 5000} {
+		es := makeEntries(n, uint64(n))
+		var slow, fast bytes.Buffer
+		if err := writeReportSlow(&slow, es); err != nil {
+			t.Fatal(err)
+		}
+		if err := writeReportFast(&fast, es); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(slow.Bytes(), fast.Bytes()) {
+			t.Errorf("n=%d: the reports differ:\nslow:\n%.300s\nfast:\n%.300s", n, slow.Bytes(), fast.Bytes())
+		}
+	}
+}
+
+func TestReportFormat(t *testing.T) {
+	es := []entry{
+		{"GET", "/", 200, 1500 * time.Microsecond},
+		{"POST", "/login", 500, 2 * time.Millisecond},
+	}
+	var buf bytes.Buffer
+	if err := writeReportFast(&buf, es); err != nil {
+		t.Fatal(err)
+	}
+	want := "GET / 200 1.5ms\nPOST /login 500 2ms\nfailed:\n#1 POST /login 500\n"
+	if got := buf.String(); got != want {
+		t.Errorf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+// traces is the shape of go tool pprof -traces output, cut down
+const traces = `File: 01-pprof
+Type: cpu
+-----------+-------------------------------------------------------
+      30ms   internal/runtime/syscall/linux.Syscall6
+             os.(*File).Write
+             fmt.Fprintf
+             main.writeReportSlow
+             main.main
+-----------+-------------------------------------------------------
+      20ms   runtime.memmove
+             runtime.concatstrings
+             main.writeReportSlow
+             main.main
+-----------+-------------------------------------------------------
+      50ms   syscall.Syscall
+             os.(*File).Write
+             fmt.Fprintf
+             main.writeReportSlow
+             main.main
+-----------+-------------------------------------------------------
+      10ms   runtime.scanObject
+             runtime.gcBgMarkWorker
+-----------+-------------------------------------------------------
+      10ms   internal/strconv.Itoa (inline)
+             strconv.Itoa (inline)
+             main.writeReportSlow
+             main.main
+-----------+-------------------------------------------------------
+`
+
+func TestFold(t *testing.T) {
+	stacks, err := fold(strings.NewReader(traces), "main.writeReportSlow", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []stack{
+		{[]string{"main.writeReportSlow", "fmt.Fprintf"}, 80 * time.Millisecond},
+		{[]string{"main.writeReportSlow", "runtime.concatstrings"}, 20 * time.Millisecond},
+		{[]string{"main.writeReportSlow", "strconv.Itoa"}, 10 * time.Millisecond},
+		{[]string{"runtime.gcBgMarkWorker", "runtime.scanObject"}, 10 * time.Millisecond},
+	}
+	if !slices.EqualFunc(stacks, want, func(a, b stack) bool {
+		return a.Took == b.Took && slices.Equal(a.Frames, b.Frames)
+	}) {
+		t.Errorf("got %v\nwant %v", stacks, want)
+	}
+}
+
+func TestFoldBadTime(t *testing.T) {
+	_, err := fold(strings.NewReader("-----\n      30parsecs   main.main\n"), "main.main", 1)
+	if err == nil {
+		t.Error("fold accepted a bad duration")
+	}
+}
+
+func BenchmarkReport(b *testing.B) {
+	for _, n := range []int{1000, 10_000} {
+		es := makeEntries(n, 1)
+		for _, v := range []struct {
+			name  string
+			write func(io.Writer, []entry) error
+		}{
+			{"slow", writeReportSlow},
+			{"fast", writeReportFast},
+		} {
+			b.Run(fmt.Sprintf("%s/entries=%d", v.name, n), func(b *testing.B) {
+				// A file, not a buffer: unbuffered writes cost a system
+				// call each, and only a file shows it
+				f, err := os.Create(filepath.Join(b.TempDir(), "report.txt"))
+				if err != nil {
+					b.Fatal(err)
+				}
+				defer f.Close()
+
+				b.ReportAllocs()
+				for b.Loop() {
+					f.Seek(0, io.SeekStart)
+					v.write(f, es)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"runtime/pprof"
+	"slices"
+	"strings"
+	"time"
+)
+
+// profileCPU runs fn with the CPU profiler on, and writes the profile
+// to path
+func profileCPU(path string, fn func()) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := pprof.StartCPUProfile(f); err != nil {
+		return err
+	}
+	fn()
+	pprof.StopCPUProfile()
+	return f.Close()
+}
+
+// profileAllocs runs fn, and writes the allocs profile to path. The
+// profile counts every allocation since the program started; the ones
+// made by fn are those under profileAllocs in their stacks
+func profileAllocs(path string, fn func()) error {
+	fn()
+	// The profile is updated by garbage collections; one more makes
+	// sure fn's allocations are in it
+	runtime.GC()
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if err := pprof.Lookup("allocs").WriteTo(f, 0); err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// pprofTool runs go tool pprof with args, and returns its output
+func pprofTool(args ...string) (string, error) {
+	out, err := exec.Command("go", append([]string{"tool", "pprof"}, args...)...).CombinedOutput()
+	if err != nil {
+		return "", fmt.Errorf("go tool pprof: %w\n%s", err, out)
+	}
+	return string(out), nil
+}
+
+// stack is a call stack, from the root, and the time spent in it
+type stack struct {
+	Frames []string
+	Took   time.Duration
+}
+
+func (s stack) String() string {
+	return strings.Join(s.Frames, ";") + " " + s.Took.String()
+}
+
+// fold reads the output of go tool pprof -traces and turns it into the
+// data a flame graph draws: one line per stack, root first, with its
+// total time.
+//
+// Each stack starts at root, or at its own root if root isn't in it,
+// and is cut to depth frames, so that the stacks that only differ
+// deeper down are added up. The biggest come first.
+func fold(r io.Reader, root string, depth int) ([]stack, error) {
+	totals := map[string]time.Duration{}
+	var frames []string
+	var took time.Duration
+
+	flush := func() {
+		if len(frames) == 0 {
+			return
+		}
+		slices.Reverse(frames) // pprof lists the leaf first
+		// Short names of inlined calls can repeat, like strconv.Itoa
+		// calling internal/strconv.Itoa
+		frames = slices.Compact(frames)
+		if i := slices.Index(frames, root); i >= 0 {
+			frames = frames[i:]
+		}
+		frames = frames[:min(len(frames), depth)]
+		totals[strings.Join(frames, ";")] += took
+		frames = frames[:0]
+	}
+
+	sc := bufio.NewScanner(r)
+	for sc.Scan() {
+		line := sc.Text()
+		switch {
+		case strings.HasPrefix(line, "-----"):
+			flush()
+			took = 0
+		case strings.HasPrefix(line, " "):
+			fields := strings.Fields(line)
+			if len(frames) == 0 {
+				// The first line of a stack has its time
+				d, err := time.ParseDuration(fields[0])
+				if err != nil {
+					return nil, fmt.Errorf("fold: %q: %w", line, err)
+				}
+				took, fields = d, fields[1:]
+			}
+			frames = append(frames, shortName(fields[0]))
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	flush()
+
+	stacks := make([]stack, 0, len(totals))
+	for s, d := range totals {
+		stacks = append(stacks, stack{strings.Split(s, ";"), d})
+	}
+	slices.SortFunc(stacks, func(a, b stack) int {
+		return cmp.Or(cmp.Compare(b.Took, a.Took),
+			slices.Compare(a.Frames, b.Frames))
+	})
+	return stacks, nil
+}
+
+// shortName drops the import path from a function name:
+// internal/poll.(*FD).Write becomes poll.(*FD).Write
+func shortName(fn string) string {
+	return fn[strings.LastIndex(fn, "/")+1:]
+}
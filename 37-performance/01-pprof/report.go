@@ -0,0 +1,90 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// entry is one line of an access log
+type entry struct {
+	Method string
+	Path   string
+	Status int
+	Took   time.Duration
+}
+
+// makeEntries returns n entries, the same ones for the same seed
+func makeEntries(n int, seed uint64) []entry {
+	r := rand.New(rand.NewPCG(seed, seed))
+	methods := []string{"GET", "GET", "GET", "POST", "DELETE"}
+	paths := []string{"/", "/users", "/users/42", "/orders", "/health", "/login"}
+	statuses := []int{200, 200, 200, 201, 304, 404, 500}
+
+	entries := make([]entry, n)
+	for i := range entries {
+		entries[i] = entry{
+			Method: methods[r.IntN(len(methods))],
+			Path:   paths[r.IntN(len(paths))],
+			Status: statuses[r.IntN(len(statuses))],
+			Took:   time.Duration(r.IntN(5000)) * time.Microsecond,
+		}
+	}
+	return entries
+}
+
+// writeReportSlow writes every entry, and then a summary of the failed
+// requests. It has the two mistakes this lesson profiles: every field
+// is its own write to w, and the summary grows with +=, which copies
+// the whole string each time
+func writeReportSlow(w io.Writer, entries []entry) error {
+	for _, e := range entries {
+		fmt.Fprintf(w, "%s ", e.Method)
+		fmt.Fprintf(w, "%s ", e.Path)
+		fmt.Fprintf(w, "%d ", e.Status)
+		if _, err := fmt.Fprintf(w, "%v\n", e.Took); err != nil {
+			return err
+		}
+	}
+
+	summary := ""
+	for i, e := range entries {
+		if e.Status >= 400 {
+			summary += "#" + strconv.Itoa(i) + " " + e.Method + " " + e.Path +
+				" " + strconv.Itoa(e.Status) + "\n"
+		}
+	}
+	_, err := io.WriteString(w, "failed:\n"+summary)
+	return err
+}
+
+// writeReportFast writes the same bytes as writeReportSlow. A
+// bufio.Writer turns the small writes into a few large ones, and a
+// strings.Builder grows the summary in place
+func writeReportFast(w io.Writer, entries []entry) error {
+	bw := bufio.NewWriterSize(w, 64<<10)
+	for _, e := range entries {
+		bw.WriteString(e.Method)
+		bw.WriteByte(' ')
+		bw.WriteString(e.Path)
+		bw.WriteByte(' ')
+		bw.WriteString(strconv.Itoa(e.Status))
+		bw.WriteByte(' ')
+		bw.WriteString(e.Took.String())
+		bw.WriteByte('\n')
+	}
+
+	var summary strings.Builder
+	for i, e := range entries {
+		if e.Status >= 400 {
+			fmt.Fprintf(&summary, "#%d %s %s %d\n", i, e.Method, e.Path, e.Status)
+		}
+	}
+	bw.WriteString("failed:\n")
+	bw.WriteString(summary.String())
+	return bw.Flush()
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"time"
+
+	"golang.org/x/exp/trace"
+)
+
+// stats is what a trace says about the pool's workers: the goroutines
+// that ran the tasks
+type stats struct {
+	Workers int
+	Tasks   int
+	Regions map[string]span // time in each region type, by its name
+	Logs    []string        // the trace.Log messages, in order
+
+	Running time.Duration            // time the workers were running
+	Latency []time.Duration          // each wait from runnable to running, sorted
+	Blocked map[string]time.Duration // time the workers waited, by reason
+}
+
+// span is how many times something happened, and for how long in total
+type span struct {
+	Count int
+	Total time.Duration
+}
+
+// analyze reads a trace and adds up what its workers did. It reads the
+// trace twice: once to find the workers, once to follow them
+func analyze(r io.Reader) (*stats, error) {
+	events, err := readEvents(r)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := map[trace.GoID]bool{}
+	for _, ev := range events {
+		if ev.Kind() == trace.EventRegionBegin {
+			workers[ev.Goroutine()] = true
+		}
+	}
+
+	s := &stats{
+		Workers: len(workers),
+		Regions: map[string]span{},
+		Blocked: map[string]time.Duration{},
+	}
+	// since is when each worker entered its state, and why, for waits
+	type state struct {
+		at     trace.Time
+		reason string
+	}
+	since := map[trace.GoID]state{}
+	open := map[trace.GoID][]trace.Time{} // region begin times, innermost last
+
+	for _, ev := range events {
+		switch ev.Kind() {
+		case trace.EventTaskBegin:
+			s.Tasks++
+
+		case trace.EventLog:
+			l := ev.Log()
+			s.Logs = append(s.Logs, l.Category+"="+l.Message)
+
+		case trace.EventRegionBegin:
+			g := ev.Goroutine()
+			open[g] = append(open[g], ev.Time())
+
+		case trace.EventRegionEnd:
+			g := ev.Goroutine()
+			if n := len(open[g]); n > 0 {
+				r := s.Regions[ev.Region().Type]
+				r.Count++
+				r.Total += ev.Time().Sub(open[g][n-1])
+				s.Regions[ev.Region().Type] = r
+				open[g] = open[g][:n-1]
+			}
+
+		case trace.EventStateTransition:
+			st := ev.StateTransition()
+			if st.Resource.Kind != trace.ResourceGoroutine {
+				continue
+			}
+			g := st.Resource.Goroutine()
+			if !workers[g] {
+				continue
+			}
+			from, to := st.Goroutine()
+			if prev, ok := since[g]; ok {
+				d := ev.Time().Sub(prev.at)
+				switch from {
+				case trace.GoRunnable:
+					s.Latency = append(s.Latency, d)
+				case trace.GoRunning:
+					s.Running += d
+				case trace.GoWaiting:
+					s.Blocked[prev.reason] += d
+				}
+			}
+			since[g] = state{ev.Time(), st.Reason}
+			if to == trace.GoNotExist {
+				delete(since, g)
+			}
+		}
+	}
+	slices.Sort(s.Latency)
+	return s, nil
+}
+
+// readEvents reads every event of a trace
+func readEvents(r io.Reader) ([]trace.Event, error) {
+	tr, err := trace.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	var events []trace.Event
+	for {
+		ev, err := tr.ReadEvent()
+		if errors.Is(err, io.EOF) {
+			return events, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, ev)
+	}
+}
+
+// percentile returns the p'th percentile of sorted durations, or zero
+// if there are none
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p / 100 * float64(len(sorted)-1))
+	return sorted[i]
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"time"
+)
+
+// images is how many images each run processes
+const images = 40
+
+// run is one traced run of the pool
+type run struct {
+	workers int
+	took    time.Duration
+	path    string
+	stats   *stats
+}
+
+func main() {
+	fmt.Println("The Execution Tracer")
+	fmt.Println("====================")
+	fmt.Println()
+
+	dir := filepath.Join(os.TempDir(), "learngo-trace")
+	runs, err := example1(dir)
+	if err != nil {
+		log.Fatal(err)
+	}
+	example2(runs[0])
+	example3(runs)
+	example4(runs)
+
+	fmt.Println("Open a trace in the browser with:")
+	for _, r := range runs {
+		fmt.Println("   go tool trace", r.path)
+	}
+}
+
+// Example 1: Tracing two runs of the worker pool
+func example1(dir string) ([]run, error) {
+	fmt.Printf("1. %d images, with 2 and with 8 workers, on 2 Ps:\n", images)
+	// The same number of Ps on every machine, so that the runs compare
+	defer runtime.GOMAXPROCS(runtime.GOMAXPROCS(2))
+
+	var runs []run
+	for _, workers := range []int{2, 8} {
+		r := run{workers: workers}
+		path, err := traceRun(dir, fmt.Sprintf("pool-%dw", workers), func() error {
+			var err error
+			r.took, err = runPool(images, workers)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		r.path = path
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		r.stats, err = analyze(f)
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		info, _ := os.Stat(path)
+		fmt.Printf("   %d workers: %v, trace of %d KiB in %s\n",
+			workers, r.took.Round(time.Millisecond), info.Size()>>10, filepath.Base(path))
+		runs = append(runs, r)
+	}
+	fmt.Println()
+	return runs, nil
+}
+
+// Example 2: Tasks, regions, and logs
+func example2(r run) {
+	fmt.Printf("2. What the annotations recorded, with %d workers:\n", r.workers)
+	s := r.stats
+	fmt.Printf("   %d tasks, by %d worker goroutines\n", s.Tasks, s.Workers)
+	for _, name := range []string{"load", "resize", "save"} {
+		reg := s.Regions[name]
+		fmt.Printf("   region %-6s x%d, %9v in all, %8v each\n", name, reg.Count,
+			reg.Total.Round(time.Microsecond), (reg.Total / time.Duration(max(reg.Count, 1))).Round(time.Microsecond))
+	}
+	fmt.Printf("   logs: %v ...\n", s.Logs[:min(len(s.Logs), 3)])
+	fmt.Println()
+}
+
+// Example 3: Scheduler latency
+func example3(runs []run) {
+	fmt.Println("3. Scheduler latency, from runnable to running:")
+	for _, r := range runs {
+		lat := r.stats.Latency
+		var total time.Duration
+		for _, d := range lat {
+			total += d
+		}
+		fmt.Printf("   %d workers: %3d waits, p50 %7v, p99 %7v, max %7v, %7v in all\n",
+			r.workers, len(lat), percentile(lat, 50).Round(time.Microsecond),
+			percentile(lat, 99).Round(time.Microsecond), percentile(lat, 100).Round(time.Microsecond),
+			total.Round(time.Microsecond))
+	}
+	fmt.Println()
+}
+
+// Example 4: What blocked the workers
+func example4(runs []run) {
+	fmt.Println("4. Time the workers spent running, and blocked, by reason:")
+	for _, r := range runs {
+		fmt.Printf("   %d workers: running %v", r.workers, r.stats.Running.Round(time.Millisecond))
+		for _, reason := range slices.Sorted(maps.Keys(r.stats.Blocked)) {
+			fmt.Printf(", %s %v", reason, r.stats.Blocked[reason].Round(100*time.Microsecond))
+		}
+		fmt.Println()
+	}
+	fmt.Println()
+}
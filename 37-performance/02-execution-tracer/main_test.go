@@ -0,0 +1,98 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"runtime/trace"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestAnalyze(t *testing.T) {
+	var buf bytes.Buffer
+	if err := trace.Start(&buf); err != nil {
+		t.Fatal(err)
+	}
+	_, err := runPool(6, 2)
+	trace.Stop()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := analyze(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Workers != 2 || s.Tasks != 6 {
+		t.Errorf("got %d workers and %d tasks, want 2 and 6", s.Workers, s.Tasks)
+	}
+	for _, name := range []string{"load", "resize", "save"} {
+		if r := s.Regions[name]; r.Count != 6 || r.Total <= 0 {
+			t.Errorf("region %s: got %+v, want 6 of them", name, r)
+		}
+	}
+	if len(s.Logs) != 6 || !slices.Contains(s.Logs, "image=image01.jpg") {
+		t.Errorf("logs = %v", s.Logs)
+	}
+	// load sleeps for 2ms, six times, on two workers
+	if s.Blocked["sleep"] < 6*time.Millisecond {
+		t.Errorf("blocked on sleep for %v, want at least 6ms", s.Blocked["sleep"])
+	}
+	if s.Running <= 0 || !slices.IsSorted(s.Latency) {
+		t.Errorf("running %v, latencies sorted: %v", s.Running, slices.IsSorted(s.Latency))
+	}
+}
+
+func TestAnalyzeNotATrace(t *testing.T) {
+	if _, err := analyze(bytes.NewReader([]byte("not a trace"))); err == nil {
+		t.Error("analyze accepted a file that is not a trace")
+	}
+}
+
+func TestTraceRun(t *testing.T) {
+	dir := t.TempDir()
+	var paths []string
+	for range 2 {
+		path, err := traceRun(dir, "run", func() error {
+			time.Sleep(time.Millisecond)
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	if paths[0] == paths[1] {
+		t.Errorf("both runs wrote %s", paths[0])
+	}
+	for _, path := range paths {
+		f, err := os.Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = readEvents(f)
+		f.Close()
+		if err != nil {
+			t.Errorf("%s: %v", path, err)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	ds := []time.Duration{1, 2, 3, 4, 5, 6, 7, 8, 9, 10}
+	tests := []struct {
+		p    float64
+		want time.Duration
+	}{
+		{0, 1}, {50, 5}, {99, 9}, {100, 10},
+	}
+	for _, tt := range tests {
+		if got := percentile(ds, tt.p); got != tt.want {
+			t.Errorf("percentile(%v) = %v, want %v", tt.p, got, tt.want)
+		}
+	}
+	if got := percentile(nil, 50); got != 0 {
+		t.Errorf("percentile(nil) = %v, want 0", got)
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"hash/crc32"
+	"runtime/trace"
+	"sync"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/workerpool"
+)
+
+// image is a job of the image processor from the worker pool lesson
+type image struct {
+	ID   int
+	Name string
+}
+
+// processor turns images into thumbnails. Every image is a task in the
+// trace, and its three steps are regions in it
+type processor struct {
+	disk sync.Mutex // a device that one goroutine writes to at a time
+	pix  []byte     // the pixels resize works on
+}
+
+func newProcessor() *processor {
+	return &processor{pix: make([]byte, 64<<10)}
+}
+
+// process loads, resizes, and saves one image. Loading waits, like a
+// read from disk; resizing uses the CPU; saving holds the disk lock
+func (p *processor) process(ctx context.Context, img image) (uint32, error) {
+	ctx, task := trace.NewTask(ctx, "thumbnail")
+	defer task.End()
+	trace.Log(ctx, "image", img.Name)
+
+	trace.WithRegion(ctx, "load", func() {
+		time.Sleep(2 * time.Millisecond)
+	})
+
+	var sum uint32
+	trace.WithRegion(ctx, "resize", func() {
+		for range 500 {
+			sum = crc32.Update(sum, crc32.IEEETable, p.pix)
+		}
+	})
+
+	trace.WithRegion(ctx, "save", func() {
+		p.disk.Lock()
+		defer p.disk.Unlock()
+		time.Sleep(500 * time.Microsecond)
+	})
+	return sum, nil
+}
+
+// runPool processes n images with the given number of workers, using
+// pkg/workerpool, and returns how long it took
+func runPool(n, workers int) (time.Duration, error) {
+	p := newProcessor()
+	pool := workerpool.New(context.Background(), workers, p.process)
+
+	start := time.Now()
+	go func() {
+		for i := range n {
+			if err := pool.Submit(image{ID: i + 1, Name: fmt.Sprintf("image%02d.jpg", i+1)}); err != nil {
+				break
+			}
+		}
+		pool.Drain()
+	}()
+
+	var err error
+	for r := range pool.Results() {
+		if r.Err != nil && err == nil {
+			err = r.Err
+		}
+	}
+	return time.Since(start), err
+}
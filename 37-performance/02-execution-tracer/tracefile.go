@@ -0,0 +1,34 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/trace"
+	"time"
+)
+
+// traceRun runs fn with the execution tracer on, and writes the trace to
+// a new file in dir named after the run and the time it started, so
+// that runs never overwrite each other. It returns the file's path
+func traceRun(dir, name string, fn func() error) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.trace", name, time.Now().Format("20060102-150405.000000")))
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o644)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	if err := trace.Start(f); err != nil {
+		return "", err
+	}
+	err = fn()
+	trace.Stop()
+	if err != nil {
+		return "", err
+	}
+	return path, f.Close()
+}
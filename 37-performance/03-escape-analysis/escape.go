@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// The pairs below do the same work, and differ in whether a value
+// escapes to the heap. The //go:noinline directives keep the calls as
+// written: inlining a small function into its caller can let its
+// values stay on the caller's stack, which hides the difference.
+
+// point is a small value, cheap to copy
+type point struct{ X, Y int }
+
+// Pair 1: returning a pointer, and returning a value
+
+//go:noinline
+func newPointPtr(x, y int) *point {
+	return &point{x, y} // outlives the call: on the heap
+}
+
+//go:noinline
+func newPoint(x, y int) point {
+	return point{x, y} // copied out to the caller: no heap
+}
+
+// newPointPtrInlined is newPointPtr without the directive. Inlined
+// into a caller that doesn't keep the pointer, its point stays on the
+// caller's stack
+func newPointPtrInlined(x, y int) *point {
+	return &point{x, y}
+}
+
+// Pair 2: boxing into an interface
+
+// logger drops its messages unless it's enabled
+type logger struct {
+	w       io.Writer
+	enabled bool
+}
+
+// logf boxes every argument into an interface before it can check
+// whether the logger is on: the arguments escape, because fmt keeps
+// them
+//
+//go:noinline
+func (l *logger) logf(format string, args ...any) {
+	if l.enabled {
+		fmt.Fprintf(l.w, format, args...)
+	}
+}
+
+// logInt takes the concrete type, and converts only when it writes
+//
+//go:noinline
+func (l *logger) logInt(msg string, n int) {
+	if l.enabled {
+		fmt.Fprintln(l.w, msg, strconv.Itoa(n))
+	}
+}
+
+// Pair 3: closures
+
+// counter returns a closure that outlives counter, so the n it
+// captures must live on the heap
+//
+//go:noinline
+func counter() func() int {
+	n := 0
+	return func() int {
+		n++
+		return n
+	}
+}
+
+// countTo uses a closure only while it runs: the closure, and the n it
+// captures, stay on the stack
+//
+//go:noinline
+func countTo(limit int) int {
+	n := 0
+	inc := func() { n++ }
+	for range limit {
+		inc()
+	}
+	return n
+}
+
+// Pair 4: buffers
+
+// sumDigitsMake sizes its buffer at run time; the compiler can't put
+// a slice of unknown size on the stack
+//
+//go:noinline
+func sumDigitsMake(v, size int) int {
+	buf := make([]byte, 0, size)
+	return sumDigits(strconv.AppendInt(buf, int64(v), 10))
+}
+
+// sumDigitsArray uses an array of a size known at compile time
+//
+//go:noinline
+func sumDigitsArray(v int) int {
+	var buf [20]byte // enough for any int64
+	return sumDigits(strconv.AppendInt(buf[:0], int64(v), 10))
+}
+
+func sumDigits(b []byte) int {
+	sum := 0
+	for _, c := range b {
+		if c >= '0' && c <= '9' {
+			sum += int(c - '0')
+		}
+	}
+	return sum
+}
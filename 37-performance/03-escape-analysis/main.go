@@ -0,0 +1,126 @@
+package main
+
+import (
+	"cmp"
+	"fmt"
+	"io"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// Values the compiler can't know in advance: constants are boxed into
+// interfaces at compile time, and would hide the allocations
+var (
+	big   = 1000
+	small = 7
+)
+
+// sink keeps results alive, so the calls below aren't optimized away
+var sink int
+
+func main() {
+	fmt.Println("Escape Analysis")
+	fmt.Println("===============")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+	example5()
+}
+
+// Example 1: Returning a pointer, and returning a value
+func example1() {
+	fmt.Println("1. Allocations per call, pointer or value:")
+	allocs("newPointPtr", func() { sink = newPointPtr(1, 2).X })
+	allocs("newPoint", func() { sink = newPoint(1, 2).X })
+	allocs("newPointPtrInlined", func() { sink = newPointPtrInlined(1, 2).X })
+	fmt.Println()
+}
+
+// Example 2: Boxing into an interface
+func example2() {
+	fmt.Println("2. A disabled logger, called with an int:")
+	l := &logger{w: io.Discard}
+	allocs("logf(\"n=%d\", 1000)", func() { l.logf("n=%d", big) })
+	allocs("logf(\"n=%d\", 7)", func() { l.logf("n=%d", small) })
+	allocs("logInt(\"n\", 1000)", func() { l.logInt("n", big) })
+	fmt.Println()
+}
+
+// Example 3: Closures
+func example3() {
+	fmt.Println("3. Closures that escape, and closures that don't:")
+	next := counter()
+	allocs("counter()", func() { next = counter() })
+	allocs("next()", func() { sink = next() })
+	allocs("countTo(10)", func() { sink = countTo(10) })
+	fmt.Println()
+}
+
+// Example 4: Buffers
+func example4() {
+	fmt.Println("4. Buffers sized at run time, and at compile time:")
+	allocs("make([]byte, 0, 20)", func() { sink = sumDigitsMake(123456, 20) })
+	allocs("make([]byte, 0, 64)", func() { sink = sumDigitsMake(123456, 64) })
+	allocs("var buf [20]byte", func() { sink = sumDigitsArray(123456) })
+	fmt.Println()
+}
+
+// Example 5: What the compiler says
+func example5() {
+	fmt.Println("5. go build -gcflags=-m, the lines about the heap:")
+	out, err := exec.Command("go", "build", "-gcflags=-m", "-o", "/dev/null", ".").CombinedOutput()
+	if err != nil {
+		fmt.Printf("   error: %v\n%s", err, out)
+		return
+	}
+	// The functions, and the calls in this file where inlining and
+	// boxing happen
+	for _, line := range escapeLines(string(out)) {
+		if strings.HasPrefix(line, "escape.go") ||
+			strings.Contains(line, "&point") || strings.Contains(line, "big") || strings.Contains(line, "small") {
+			fmt.Println("  ", line)
+		}
+	}
+	fmt.Println()
+}
+
+// allocs prints how many times fn allocates, on average
+func allocs(name string, fn func()) {
+	fmt.Printf("   %-22s %v allocs\n", name, testing.AllocsPerRun(1000, fn))
+}
+
+// escapeLines returns the lines of -gcflags=-m output that say where a
+// value lives, the heap or not, or that a parameter leaks, sorted by
+// file and line
+func escapeLines(out string) []string {
+	var lines []string
+	for line := range strings.Lines(out) {
+		line = strings.TrimPrefix(strings.TrimSpace(line), "./")
+		if strings.Contains(line, "heap") || strings.Contains(line, "does not escape") ||
+			strings.Contains(line, "leaking param") {
+			lines = append(lines, line)
+		}
+	}
+	slices.SortStableFunc(lines, func(a, b string) int {
+		return cmp.Or(strings.Compare(fileOf(a), fileOf(b)), cmp.Compare(lineOf(a), lineOf(b)))
+	})
+	return lines
+}
+
+// fileOf and lineOf split a position like escape.go:21:9: message
+func fileOf(s string) string {
+	file, _, _ := strings.Cut(s, ":")
+	return file
+}
+
+func lineOf(s string) int {
+	_, rest, _ := strings.Cut(s, ":")
+	n, _ := strconv.Atoi(strings.SplitN(rest, ":", 2)[0])
+	return n
+}
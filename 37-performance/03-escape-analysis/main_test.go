@@ -0,0 +1,134 @@
+package main
+
+import (
+	"io"
+	"slices"
+	"testing"
+)
+
+// TestAllocs pins the allocation counts the lesson claims, so that a
+// change that makes a value escape fails a test
+func TestAllocs(t *testing.T) {
+	l := &logger{w: io.Discard}
+	next := counter()
+
+	tests := []struct {
+		name string
+		fn   func()
+		want float64
+	}{
+		{"newPointPtr", func() { sink = newPointPtr(1, 2).X }, 1},
+		{"newPoint", func() { sink = newPoint(1, 2).X }, 0},
+		{"newPointPtrInlined", func() { sink = newPointPtrInlined(1, 2).X }, 0},
+		{"logf big", func() { l.logf("n=%d", big) }, 1},
+		{"logf small", func() { l.logf("n=%d", small) }, 0},
+		{"logInt", func() { l.logInt("n", big) }, 0},
+		{"counter", func() { next = counter() }, 2},
+		{"next", func() { sink = next() }, 0},
+		{"countTo", func() { sink = countTo(10) }, 0},
+		{"make 20", func() { sink = sumDigitsMake(123456, 20) }, 0},
+		{"make 64", func() { sink = sumDigitsMake(123456, 64) }, 1},
+		{"array", func() { sink = sumDigitsArray(123456) }, 0},
+	}
+	for _, tt := range tests {
+		if got := testing.AllocsPerRun(100, tt.fn); got != tt.want {
+			t.Errorf("%s: %v allocs, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSameResults(t *testing.T) {
+	if *newPointPtr(3, 4) != newPoint(3, 4) {
+		t.Error("newPointPtr and newPoint disagree")
+	}
+	next := counter()
+	next()
+	if got := next(); got != 2 {
+		t.Errorf("counter: second call = %d, want 2", got)
+	}
+	if got := countTo(5); got != 5 {
+		t.Errorf("countTo(5) = %d", got)
+	}
+	for _, v := range []int{0, 7, -123456, 9223372036854775807} {
+		if a, b := sumDigitsMake(v, 64), sumDigitsArray(v); a != b {
+			t.Errorf("sumDigits(%d): %d with make, %d with an array", v, a, b)
+		}
+	}
+}
+
+func TestEscapeLines(t *testing.T) {
+	out := `# example
+./main.go:41:65: &point{...} does not escape
+./escape.go:33:9: &point{...} escapes to heap
+./escape.go:21:9: &point{...} escapes to heap
+./escape.go:19:6: can inline newPoint
+./escape.go:100:2: moved to heap: n
+./escape.go:49:38: leaking param content: args
+`
+	want := []string{
+		"escape.go:21:9: &point{...} escapes to heap",
+		"escape.go:33:9: &point{...} escapes to heap",
+		"escape.go:49:38: leaking param content: args",
+		"escape.go:100:2: moved to heap: n",
+		"main.go:41:65: &point{...} does not escape",
+	}
+	if got := escapeLines(out); !slices.Equal(got, want) {
+		t.Errorf("got:\n%q\nwant:\n%q", got, want)
+	}
+}
+
+func BenchmarkPoint(b *testing.B) {
+	b.Run("pointer", func(b *testing.B) {
+		for b.Loop() {
+			sink = newPointPtr(1, 2).X
+		}
+	})
+	b.Run("value", func(b *testing.B) {
+		for b.Loop() {
+			sink = newPoint(1, 2).X
+		}
+	})
+}
+
+func BenchmarkLog(b *testing.B) {
+	l := &logger{w: io.Discard}
+	b.Run("logf", func(b *testing.B) {
+		for b.Loop() {
+			l.logf("n=%d", big)
+		}
+	})
+	b.Run("logInt", func(b *testing.B) {
+		for b.Loop() {
+			l.logInt("n", big)
+		}
+	})
+}
+
+func BenchmarkClosure(b *testing.B) {
+	b.Run("escaping", func(b *testing.B) {
+		for b.Loop() {
+			next := counter()
+			for range 10 {
+				sink = next()
+			}
+		}
+	})
+	b.Run("local", func(b *testing.B) {
+		for b.Loop() {
+			sink = countTo(10)
+		}
+	})
+}
+
+func BenchmarkDigits(b *testing.B) {
+	b.Run("make", func(b *testing.B) {
+		for b.Loop() {
+			sink = sumDigitsMake(123456, 64)
+		}
+	})
+	b.Run("array", func(b *testing.B) {
+		for b.Loop() {
+			sink = sumDigitsArray(123456)
+		}
+	})
+}
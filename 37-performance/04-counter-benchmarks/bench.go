@@ -0,0 +1,62 @@
+package main
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// goroutineCounts are the columns of the lesson's tables
+var goroutineCounts = []int{1, 4, 16, 64}
+
+// bench returns a benchmark of the counter from newCounter, used by
+// goroutines goroutines at once, where reads percent of the operations
+// are reads.
+//
+// b.RunParallel starts GOMAXPROCS goroutines, times b.SetParallelism,
+// so how many it runs depends on the machine. To compare goroutine
+// counts themselves, bench starts its own and splits b.N between them.
+// b.Loop can't be shared between goroutines: this is one of the places
+// b.N is still the loop count
+func bench(newCounter func() Counter, goroutines, reads int) func(b *testing.B) {
+	return func(b *testing.B) {
+		c := newCounter()
+		if cl, ok := c.(interface{ Close() }); ok {
+			defer cl.Close()
+		}
+
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for g := range goroutines {
+			n := b.N / goroutines
+			if g < b.N%goroutines {
+				n++
+			}
+			wg.Go(func() {
+				<-start
+				for i := range n {
+					if i%100 < reads {
+						_ = c.Value()
+					} else {
+						c.Inc()
+					}
+				}
+			})
+		}
+		b.ResetTimer() // starting the goroutines isn't what's measured
+		close(start)
+		wg.Wait()
+		b.StopTimer()
+
+		// a fast counter that loses counts is no use
+		if got := c.Value(); reads == 0 && got != int64(b.N) {
+			b.Fatalf("counted %d, want %d", got, b.N)
+		}
+	}
+}
+
+// goroutinesName names a sub-benchmark by its goroutine count, the way
+// the tables' columns are named
+func goroutinesName(n int) string {
+	return fmt.Sprintf("goroutines=%d", n)
+}
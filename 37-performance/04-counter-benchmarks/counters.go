@@ -0,0 +1,147 @@
+package main
+
+import (
+	"math/rand/v2"
+	"sync"
+	"sync/atomic"
+)
+
+// Counter is a count that many goroutines change and read at once
+type Counter interface {
+	Inc()
+	Value() int64
+}
+
+// mutexCounter takes one lock for every read and write
+type mutexCounter struct {
+	mu sync.Mutex
+	n  int64
+}
+
+func (c *mutexCounter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *mutexCounter) Value() int64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.n
+}
+
+// rwMutexCounter lets readers share the lock
+type rwMutexCounter struct {
+	mu sync.RWMutex
+	n  int64
+}
+
+func (c *rwMutexCounter) Inc() {
+	c.mu.Lock()
+	c.n++
+	c.mu.Unlock()
+}
+
+func (c *rwMutexCounter) Value() int64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.n
+}
+
+// atomicCounter is one atomic add: no lock, but every core still
+// fights over the same cache line
+type atomicCounter struct {
+	n atomic.Int64
+}
+
+func (c *atomicCounter) Inc()         { c.n.Add(1) }
+func (c *atomicCounter) Value() int64 { return c.n.Load() }
+
+// cacheLine is the size of a cache line on amd64 and arm64
+const cacheLine = 64
+
+// shard is one part of a shardedCounter, padded to a cache line of its
+// own. Without the padding, neighboring shards share a line, and cores
+// that write to different shards still slow each other down: false
+// sharing
+type shard struct {
+	n atomic.Int64
+	_ [cacheLine - 8]byte
+}
+
+// shardedCounter spreads increments over shards, so that goroutines
+// rarely write to the same one. Inc is cheap, and Value pays for it: it
+// adds up every shard, and isn't a snapshot of a single moment
+type shardedCounter struct {
+	shards []shard
+}
+
+func newShardedCounter(n int) *shardedCounter {
+	return &shardedCounter{shards: make([]shard, n)}
+}
+
+// Inc picks a shard at random. Goroutines have no ID to pick one by,
+// and the top-level functions of math/rand/v2 don't take a lock
+func (c *shardedCounter) Inc() {
+	c.shards[rand.N(len(c.shards))].n.Add(1)
+}
+
+func (c *shardedCounter) Value() int64 {
+	var n int64
+	for i := range c.shards {
+		n += c.shards[i].n.Load()
+	}
+	return n
+}
+
+// chanCounter keeps the count in one goroutine, and the others ask it
+// over channels: share memory by communicating. Close stops it
+type chanCounter struct {
+	inc  chan struct{}
+	read chan int64
+	done chan struct{}
+}
+
+func newChanCounter() *chanCounter {
+	c := &chanCounter{
+		inc:  make(chan struct{}),
+		read: make(chan int64),
+		done: make(chan struct{}),
+	}
+	go c.run()
+	return c
+}
+
+// run owns n: no other goroutine touches it
+func (c *chanCounter) run() {
+	var n int64
+	for {
+		select {
+		case <-c.inc:
+			n++
+		case c.read <- n:
+		case <-c.done:
+			return
+		}
+	}
+}
+
+func (c *chanCounter) Inc()         { c.inc <- struct{}{} }
+func (c *chanCounter) Value() int64 { return <-c.read }
+func (c *chanCounter) Close()       { close(c.done) }
+
+// impl is a Counter implementation, by name
+type impl struct {
+	name string
+	new  func() Counter
+}
+
+// impls are the counters the lesson compares, in the order the tables
+// show them
+var impls = []impl{
+	{"mutex", func() Counter { return &mutexCounter{} }},
+	{"rwmutex", func() Counter { return &rwMutexCounter{} }},
+	{"atomic", func() Counter { return &atomicCounter{} }},
+	{"sharded", func() Counter { return newShardedCounter(16) }},
+	{"channel", func() Counter { return newChanCounter() }},
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+func main() {
+	testing.Init() // makes -test.benchtime settable
+	flag.Set("test.benchtime", "100ms")
+	in := flag.String("table", "", "read go test -bench output from this file, or - for stdin, and print it as a Markdown table")
+	flag.Parse()
+	if *in != "" {
+		if err := markdownTable(os.Stdout, *in); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	fmt.Println("Counter Benchmarks")
+	fmt.Println("==================")
+	fmt.Println()
+
+	example1()
+	incs := example2()
+	example3()
+	example4(incs)
+}
+
+// Example 1: Every counter counts the same
+func example1() {
+	const goroutines, each = 8, 10_000
+	fmt.Printf("1. %d goroutines, %d increments each:\n", goroutines, each)
+	for _, im := range impls {
+		c := im.new()
+		var wg sync.WaitGroup
+		for range goroutines {
+			wg.Go(func() {
+				for range each {
+					c.Inc()
+				}
+			})
+		}
+		wg.Wait()
+		fmt.Printf("   %-8s %d\n", im.name, c.Value())
+		if cl, ok := c.(interface{ Close() }); ok {
+			cl.Close()
+		}
+	}
+	fmt.Println()
+}
+
+// Example 2: Increments, by number of goroutines
+func example2() []benchutil.Result {
+	fmt.Printf("2. Time per increment, GOMAXPROCS=%d:\n", runtime.GOMAXPROCS(0))
+	results := run(0)
+	printIndented(newTable(results).writeText)
+	fmt.Println()
+	return results
+}
+
+// Example 3: Mostly reads
+func example3() {
+	fmt.Println("3. Time per operation, 90% reads:")
+	printIndented(newTable(run(90)).writeText)
+	fmt.Println()
+}
+
+// Example 4: The results as Markdown
+func example4(results []benchutil.Result) {
+	fmt.Println("4. Example 2 as a Markdown table, fastest in bold:")
+	printIndented(newTable(results).writeMarkdown)
+}
+
+// run benchmarks every counter with each goroutine count, where reads
+// percent of the operations are reads
+func run(reads int) []benchutil.Result {
+	var results []benchutil.Result
+	for _, im := range impls {
+		for _, n := range goroutineCounts {
+			results = append(results,
+				benchutil.Run(im.name+"/"+goroutinesName(n), bench(im.new, n, reads)))
+		}
+	}
+	return results
+}
+
+// markdownTable reads the output of go test -bench from a file, or
+// stdin for -, and writes it to w as Markdown, a table for each
+// top-level benchmark
+func markdownTable(w io.Writer, name string) error {
+	r := io.Reader(os.Stdin)
+	if name != "-" {
+		f, err := os.Open(name)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+	results, err := benchutil.Parse(r)
+	if err != nil {
+		return err
+	}
+	if len(results) == 0 {
+		return fmt.Errorf("no benchmark results in %s", name)
+	}
+	for i, g := range groupResults(results) {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "### %s\n\n", g.name)
+		if err := newTable(g.results).writeMarkdown(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// printIndented calls write, and prints what it writes indented under
+// the example's title
+func printIndented(write func(io.Writer) error) {
+	var buf bytes.Buffer
+	if err := write(&buf); err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	for line := range strings.Lines(buf.String()) {
+		fmt.Print("   ", line)
+	}
+}
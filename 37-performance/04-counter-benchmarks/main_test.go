@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strings"
+	"sync"
+	"testing"
+	"time"
+	"unsafe"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+// TestCounters checks that no counter loses an increment. Run it with
+// -race: a counter that isn't safe fails here even when its total is
+// right
+func TestCounters(t *testing.T) {
+	const goroutines, each = 8, 1000
+	for _, im := range impls {
+		t.Run(im.name, func(t *testing.T) {
+			c := im.new()
+			if cl, ok := c.(interface{ Close() }); ok {
+				defer cl.Close()
+			}
+			var wg sync.WaitGroup
+			for range goroutines {
+				wg.Go(func() {
+					for i := range each {
+						c.Inc()
+						if i%10 == 0 {
+							_ = c.Value()
+						}
+					}
+				})
+			}
+			wg.Wait()
+			if got := c.Value(); got != goroutines*each {
+				t.Errorf("Value() = %d, want %d", got, goroutines*each)
+			}
+		})
+	}
+}
+
+func TestShardSize(t *testing.T) {
+	if got := unsafe.Sizeof(shard{}); got != cacheLine {
+		t.Errorf("a shard is %d bytes, want %d", got, cacheLine)
+	}
+}
+
+func TestTable(t *testing.T) {
+	results := []benchutil.Result{
+		result("BenchmarkInc/mutex/goroutines=1", 20),
+		result("BenchmarkInc/mutex/goroutines=4", 30),
+		result("BenchmarkInc/atomic/goroutines=1", 5),
+		result("BenchmarkInc/atomic/goroutines=1", 7),
+		result("BenchmarkInc/atomic/goroutines=1", 100), // a noisy run
+		result("BenchmarkInc/atomic/goroutines=4", 40),
+	}
+	groups := groupResults(results)
+	if len(groups) != 1 || groups[0].name != "Inc" {
+		t.Fatalf("groups = %v, want one named Inc", groups)
+	}
+	tab := newTable(groups[0].results)
+	if got, want := strings.Join(tab.rows, ","), "mutex,atomic"; got != want {
+		t.Errorf("rows = %s, want %s", got, want)
+	}
+	if got, want := strings.Join(tab.cols, ","), "goroutines=1,goroutines=4"; got != want {
+		t.Errorf("cols = %s, want %s", got, want)
+	}
+	if got := tab.cell("atomic", "goroutines=1"); got != 7 {
+		t.Errorf("median = %v, want 7", got)
+	}
+
+	var b strings.Builder
+	if err := tab.writeMarkdown(&b); err != nil {
+		t.Fatal(err)
+	}
+	want := `| time/op | goroutines=1 | goroutines=4 |
+|---|---:|---:|
+| mutex | 20ns | **30ns** |
+| atomic | **7ns** | 40ns |
+`
+	if b.String() != want {
+		t.Errorf("markdown:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
+
+// result makes a result of one op that took ns nanoseconds
+func result(name string, ns int) benchutil.Result {
+	var r benchutil.Result
+	r.Name = name
+	r.N = 1
+	r.T = time.Duration(ns)
+	return r
+}
+
+// BenchmarkInc compares the counters under increments alone. Turn the
+// output into a table with:
+//
+//	go test -bench Inc | go run . -table -
+func BenchmarkInc(b *testing.B) {
+	benchAll(b, 0)
+}
+
+// BenchmarkReadMostly is BenchmarkInc with 90% reads
+func BenchmarkReadMostly(b *testing.B) {
+	benchAll(b, 90)
+}
+
+func benchAll(b *testing.B, reads int) {
+	for _, im := range impls {
+		for _, n := range goroutineCounts {
+			b.Run(im.name+"/"+goroutinesName(n), bench(im.new, n, reads))
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+// table lays benchmark results out as a grid: a row for each benchmark,
+// and a column for each value of its last parameter, like goroutines=16
+type table struct {
+	rows, cols []string
+	ns         map[[2]string][]float64 // times per op, by row and column
+}
+
+// group is the results of one top-level benchmark, like BenchmarkInc
+type group struct {
+	name    string
+	results []benchutil.Result
+}
+
+// groupResults splits the output of go test -bench by top-level
+// benchmark, and trims that benchmark's name from each result. Each
+// group becomes a table of its own: times from different benchmarks
+// measure different things, and don't belong in one column
+func groupResults(results []benchutil.Result) []group {
+	var groups []group
+	for _, r := range results {
+		name, rest, _ := strings.Cut(strings.TrimPrefix(r.Name, "Benchmark"), "/")
+		i := slices.IndexFunc(groups, func(g group) bool { return g.name == name })
+		if i < 0 {
+			groups = append(groups, group{name: name})
+			i = len(groups) - 1
+		}
+		r.Name = rest
+		groups[i].results = append(groups[i].results, r)
+	}
+	return groups
+}
+
+// newTable splits each result's name at its last slash, into a row and
+// a column. Rows and columns keep the order they first appear in
+func newTable(results []benchutil.Result) table {
+	t := table{ns: make(map[[2]string][]float64)}
+	for _, r := range results {
+		row, col, ok := cutLast(r.Name, "/")
+		if !ok {
+			row, col = r.Name, ""
+		}
+		if !slices.Contains(t.rows, row) {
+			t.rows = append(t.rows, row)
+		}
+		if !slices.Contains(t.cols, col) {
+			t.cols = append(t.cols, col)
+		}
+		key := [2]string{row, col}
+		t.ns[key] = append(t.ns[key], r.NsPerOp())
+	}
+	return t
+}
+
+// cutLast is strings.Cut at the last sep instead of the first
+func cutLast(s, sep string) (before, after string, found bool) {
+	i := strings.LastIndex(s, sep)
+	if i < 0 {
+		return s, "", false
+	}
+	return s[:i], s[i+len(sep):], true
+}
+
+// cell returns the median time per op of a row and column, from every
+// run of it with -count, or 0 if there were none
+func (t table) cell(row, col string) float64 {
+	v := slices.Sorted(slices.Values(t.ns[[2]string{row, col}]))
+	switch {
+	case len(v) == 0:
+		return 0
+	case len(v)%2 == 1:
+		return v[len(v)/2]
+	default:
+		return (v[len(v)/2-1] + v[len(v)/2]) / 2
+	}
+}
+
+// fastest returns the row with the lowest time in a column
+func (t table) fastest(col string) string {
+	var best string
+	for _, row := range t.rows {
+		ns := t.cell(row, col)
+		if ns > 0 && (best == "" || ns < t.cell(best, col)) {
+			best = row
+		}
+	}
+	return best
+}
+
+// format returns a cell as a duration, or - if it's missing
+func (t table) format(row, col string) string {
+	ns := t.cell(row, col)
+	if ns == 0 {
+		return "-"
+	}
+	return benchutil.Duration(ns)
+}
+
+// writeText writes the table aligned for a terminal
+func (t table) writeText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	for _, col := range t.cols {
+		fmt.Fprintf(tw, "\t%s", col)
+	}
+	fmt.Fprintln(tw, "\t")
+	for _, row := range t.rows {
+		fmt.Fprint(tw, row)
+		for _, col := range t.cols {
+			fmt.Fprintf(tw, "\t%s", t.format(row, col))
+		}
+		fmt.Fprintln(tw, "\t")
+	}
+	return tw.Flush()
+}
+
+// writeMarkdown writes the table in Markdown, for a README or an issue,
+// with the fastest time in each column in bold
+func (t table) writeMarkdown(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString("| time/op |")
+	for _, col := range t.cols {
+		fmt.Fprintf(&b, " %s |", col)
+	}
+	b.WriteString("\n|---|")
+	for range t.cols {
+		b.WriteString("---:|")
+	}
+	b.WriteString("\n")
+	for _, row := range t.rows {
+		fmt.Fprintf(&b, "| %s |", row)
+		for _, col := range t.cols {
+			cell := t.format(row, col)
+			if row == t.fastest(col) {
+				cell = "**" + cell + "**"
+			}
+			fmt.Fprintf(&b, " %s |", cell)
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Each join function returns the parts separated by ", ", like
+// strings.Join, and builds it a different way
+
+// joinConcat adds to a string in a loop. A string can't change, so every
+// += copies everything built so far into a new one: the work grows with
+// the square of the parts
+func joinConcat(parts []string) string {
+	var s string
+	for i, p := range parts {
+		if i > 0 {
+			s += ", "
+		}
+		s += p
+	}
+	return s
+}
+
+// joinSprintf is joinConcat through fmt, which also has to parse the
+// format and box its arguments into interfaces
+func joinSprintf(parts []string) string {
+	var s string
+	for i, p := range parts {
+		if i == 0 {
+			s = p
+			continue
+		}
+		s = fmt.Sprintf("%s, %s", s, p)
+	}
+	return s
+}
+
+// joinBuilder appends to a strings.Builder, which grows its buffer by
+// doubling, like append, and returns it as a string without a copy
+func joinBuilder(parts []string) string {
+	var b strings.Builder
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// joinBuilderGrow is joinBuilder with the final size reserved first:
+// one allocation, and no copies as it grows
+func joinBuilderGrow(parts []string) string {
+	var b strings.Builder
+	b.Grow(joinedLen(parts))
+	for i, p := range parts {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		b.WriteString(p)
+	}
+	return b.String()
+}
+
+// joinBuffer appends to a bytes.Buffer. It grows like a Builder, but
+// String copies the bytes into a new string
+func joinBuffer(parts []string) string {
+	var buf bytes.Buffer
+	for i, p := range parts {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		buf.WriteString(p)
+	}
+	return buf.String()
+}
+
+// joinStrings is the standard library's version: it measures first,
+// and then fills a Builder grown to the right size
+func joinStrings(parts []string) string {
+	return strings.Join(parts, ", ")
+}
+
+// joinedLen is the length of the parts joined with ", "
+func joinedLen(parts []string) int {
+	if len(parts) == 0 {
+		return 0
+	}
+	n := 2 * (len(parts) - 1)
+	for _, p := range parts {
+		n += len(p)
+	}
+	return n
+}
+
+// user is a record to format as one line of a log
+type user struct {
+	name  string
+	id    int
+	score float64
+}
+
+// formatSprintf formats a user with fmt.Sprintf
+func formatSprintf(u user) string {
+	return fmt.Sprintf("user=%s id=%d score=%.2f", u.name, u.id, u.score)
+}
+
+// formatAppend formats the same line by appending to a byte slice on
+// the stack, with strconv instead of fmt. The only allocation is the
+// string at the end
+func formatAppend(u user) string {
+	var buf [64]byte
+	b := append(buf[:0], "user="...)
+	b = append(b, u.name...)
+	b = append(b, " id="...)
+	b = strconv.AppendInt(b, int64(u.id), 10)
+	b = append(b, " score="...)
+	b = strconv.AppendFloat(b, u.score, 'f', 2, 64)
+	return string(b)
+}
+
+// Each count function returns how many spaces s has
+
+// countBytes looks at each byte. A space is one byte in UTF-8, and no
+// byte of a multi-byte rune can be mistaken for one
+func countBytes(s string) int {
+	n := 0
+	for i := 0; i < len(s); i++ {
+		if s[i] == ' ' {
+			n++
+		}
+	}
+	return n
+}
+
+// countRange decodes each rune with for range, which has to check how
+// many bytes each one takes
+func countRange(s string) int {
+	n := 0
+	for _, r := range s {
+		if r == ' ' {
+			n++
+		}
+	}
+	return n
+}
+
+// countRuneSlice converts s to a []rune first: a new slice, four bytes
+// for every rune
+func countRuneSlice(s string) int {
+	n := 0
+	for _, r := range []rune(s) {
+		if r == ' ' {
+			n++
+		}
+	}
+	return n
+}
+
+// runeCountSlice and runeCountUTF8 count the runes in s, with and
+// without a []rune
+func runeCountSlice(s string) int { return len([]rune(s)) }
+func runeCountUTF8(s string) int  { return utf8.RuneCountInString(s) }
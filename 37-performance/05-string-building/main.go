@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+// joins are the ways to join strings the lesson compares
+var joins = []struct {
+	name string
+	join func([]string) string
+}{
+	{"+= in a loop", joinConcat},
+	{"fmt.Sprintf", joinSprintf},
+	{"strings.Builder", joinBuilder},
+	{"Builder with Grow", joinBuilderGrow},
+	{"bytes.Buffer", joinBuffer},
+	{"strings.Join", joinStrings},
+}
+
+// counts are the ways to count spaces, a byte or a rune at a time
+var counts = []struct {
+	name  string
+	count func(string) int
+}{
+	{"bytes", countBytes},
+	{"for range", countRange},
+	{"[]rune", countRuneSlice},
+}
+
+// Text to count spaces in, 1000 bytes each: one all ASCII, and one with
+// runes of two and three bytes
+var (
+	ascii = strings.Repeat("the quick brown fox jumps over the lazy dog.  ", 22)[:1000]
+	mixed = strings.Repeat("öykü çiçek 日本語 ", 40)[:1000]
+)
+
+// sink keeps results alive, so the calls below aren't optimized away
+var sink string
+
+func main() {
+	testing.Init() // makes -test.benchtime settable
+	flag.Set("test.benchtime", "100ms")
+	flag.Parse()
+
+	fmt.Println("String Building")
+	fmt.Println("===============")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+	example5()
+}
+
+// Example 1: Same string, different allocations
+func example1() {
+	parts := makeParts(100)
+	fmt.Printf("1. Joining %d parts, allocations per call:\n", len(parts))
+	for _, j := range joins {
+		if j.join(parts) != strings.Join(parts, ", ") {
+			fmt.Printf("   %-18s wrong result\n", j.name)
+			continue
+		}
+		fmt.Printf("   %-18s %v allocs\n", j.name, testing.AllocsPerRun(100, func() { sink = j.join(parts) }))
+	}
+	fmt.Println()
+}
+
+// Example 2: Time, with 10 parts
+func example2() {
+	fmt.Println("2. Joining 10 parts:")
+	printResults(benchJoins(makeParts(10)))
+	fmt.Println()
+}
+
+// Example 3: Time, with 1000 parts
+func example3() {
+	fmt.Println("3. Joining 1000 parts:")
+	printResults(benchJoins(makeParts(1000)))
+	fmt.Println()
+}
+
+// Example 4: Formatting one line
+func example4() {
+	u := user{name: "gopher", id: 4211, score: 97.5}
+	fmt.Printf("4. Formatting %q:\n", formatAppend(u))
+	printResults([]benchutil.Result{
+		benchutil.Run("fmt.Sprintf", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				sink = formatSprintf(u)
+			}
+		}),
+		benchutil.Run("strconv.Append", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				sink = formatAppend(u)
+			}
+		}),
+	})
+	fmt.Println()
+}
+
+// Example 5: Bytes and runes
+func example5() {
+	fmt.Println("5. Counting spaces and runes in 1000 bytes of text:")
+	var results []benchutil.Result
+	for _, text := range []struct{ name, s string }{{"ascii", ascii}, {"mixed", mixed}} {
+		for _, c := range counts {
+			results = append(results, benchutil.Run(text.name+"/"+c.name, func(b *testing.B) {
+				b.ReportAllocs()
+				for b.Loop() {
+					c.count(text.s)
+				}
+			}))
+		}
+	}
+	results = append(results,
+		benchutil.Run("mixed/utf8.RuneCountInString", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				runeCountUTF8(mixed)
+			}
+		}),
+		benchutil.Run("mixed/len([]rune(s))", func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				runeCountSlice(mixed)
+			}
+		}))
+	printResults(results)
+}
+
+// benchJoins benchmarks every way to join parts
+func benchJoins(parts []string) []benchutil.Result {
+	var results []benchutil.Result
+	for _, j := range joins {
+		results = append(results, benchutil.Run(j.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for b.Loop() {
+				sink = j.join(parts)
+			}
+		}))
+	}
+	return results
+}
+
+// makeParts returns n short strings: item0, item1, and so on
+func makeParts(n int) []string {
+	parts := make([]string, n)
+	for i := range parts {
+		parts[i] = "item" + strconv.Itoa(i)
+	}
+	return parts
+}
+
+// printResults prints results as a table, indented under the example's
+// title
+func printResults(results []benchutil.Result) {
+	var buf bytes.Buffer
+	benchutil.Write(&buf, results)
+	for line := range strings.Lines(buf.String()) {
+		fmt.Print("   ", line)
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestJoins(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 50} {
+		parts := makeParts(n)
+		want := strings.Join(parts, ", ")
+		for _, j := range joins {
+			if got := j.join(parts); got != want {
+				t.Errorf("%s with %d parts = %q, want %q", j.name, n, got, want)
+			}
+		}
+		if got := joinedLen(parts); got != len(want) {
+			t.Errorf("joinedLen with %d parts = %d, want %d", n, got, len(want))
+		}
+	}
+}
+
+func TestFormat(t *testing.T) {
+	for _, u := range []user{
+		{"gopher", 4211, 97.5},
+		{"", 0, 0},
+		{"a much longer name than the buffer on the stack has room for, twice over", -1, 1e6},
+	} {
+		if a, b := formatSprintf(u), formatAppend(u); a != b {
+			t.Errorf("Sprintf %q, Append %q", a, b)
+		}
+	}
+}
+
+func TestCounts(t *testing.T) {
+	for _, s := range []string{"", " ", "no-spaces", "a b  c", "öykü çiçek 日本語 ", ascii, mixed, "\xff \xfe"} {
+		want := strings.Count(s, " ")
+		for _, c := range counts {
+			if got := c.count(s); got != want {
+				t.Errorf("%s(%q) = %d, want %d", c.name, s, got, want)
+			}
+		}
+		if a, b := runeCountSlice(s), runeCountUTF8(s); a != b {
+			t.Errorf("rune count of %q: %d with []rune, %d with utf8", s, a, b)
+		}
+	}
+}
+
+// TestAllocs pins the allocation counts the lesson claims
+func TestAllocs(t *testing.T) {
+	parts := makeParts(100)
+	u := user{"gopher", 4211, 97.5}
+	tests := []struct {
+		name string
+		fn   func()
+		want float64
+	}{
+		{"Builder with Grow", func() { sink = joinBuilderGrow(parts) }, 1},
+		{"strings.Join", func() { sink = joinStrings(parts) }, 1},
+		{"formatAppend", func() { sink = formatAppend(u) }, 1},
+		{"countBytes", func() { countBytes(mixed) }, 0},
+		{"countRange", func() { countRange(mixed) }, 0},
+		{"countRuneSlice", func() { countRuneSlice(mixed) }, 1},
+		{"len([]rune(s))", func() { runeCountSlice(mixed) }, 0},
+	}
+	if raceEnabled {
+		tests[len(tests)-1].want = 1
+	}
+	for _, tt := range tests {
+		if got := testing.AllocsPerRun(100, tt.fn); got != tt.want {
+			t.Errorf("%s: %v allocs, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func BenchmarkJoin(b *testing.B) {
+	for _, n := range []int{10, 100, 1000} {
+		parts := makeParts(n)
+		for _, j := range joins {
+			b.Run(strings.ReplaceAll(j.name, " ", "_")+"/parts="+strconv.Itoa(n), func(b *testing.B) {
+				for b.Loop() {
+					sink = j.join(parts)
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkFormat(b *testing.B) {
+	u := user{"gopher", 4211, 97.5}
+	b.Run("sprintf", func(b *testing.B) {
+		for b.Loop() {
+			sink = formatSprintf(u)
+		}
+	})
+	b.Run("append", func(b *testing.B) {
+		for b.Loop() {
+			sink = formatAppend(u)
+		}
+	})
+}
+
+func BenchmarkCount(b *testing.B) {
+	for _, text := range []struct{ name, s string }{{"ascii", ascii}, {"mixed", mixed}} {
+		for _, c := range counts {
+			b.Run(text.name+"/"+strings.ReplaceAll(c.name, " ", "_"), func(b *testing.B) {
+				b.SetBytes(int64(len(text.s)))
+				for b.Loop() {
+					c.count(text.s)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !race
+
+package main
+
+// raceEnabled reports whether the tests were built with -race. The
+// race detector instruments the code, and the compiler then skips
+// rewrites like len([]rune(s)) into a count that doesn't allocate
+const raceEnabled = false
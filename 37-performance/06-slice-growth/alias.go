@@ -0,0 +1,32 @@
+package main
+
+import "slices"
+
+// Slices share backing arrays. Each bug below comes from a slice that
+// still points into an array that something else uses, and each fix
+// gives it an array of its own, or a capacity that forces append to
+// make one
+
+// withDefaultBuggy returns tags with "default" added. When tags has
+// spare capacity, append writes into it, over the caller's elements
+// past the end of tags
+func withDefaultBuggy(tags []string) []string {
+	return append(tags, "default")
+}
+
+// withDefault clips tags to its length first, so append has no spare
+// capacity to write into, and copies to a new array
+func withDefault(tags []string) []string {
+	return append(slices.Clip(tags), "default")
+}
+
+// headBuggy returns the first n elements of s. They share s's backing
+// array, so while the result is alive, the whole array stays in memory
+func headBuggy(s []int, n int) []int {
+	return s[:n]
+}
+
+// head copies the first n elements, and lets the rest of s be freed
+func head(s []int, n int) []int {
+	return slices.Clone(s[:n])
+}
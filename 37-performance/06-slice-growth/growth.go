@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// step is a slice's length and capacity after an append that grew it
+type step struct {
+	len, cap int
+}
+
+// escaped holds each slice growth builds, so that it escapes
+var escaped any
+
+// growth appends n zero values to a nil slice, one at a time, and
+// records each time append had to grow it. The slice escapes to the
+// heap, where it grows by the runtime's rules. One that doesn't escape
+// may start in a 32-byte array on the stack instead, as in traceAppends
+func growth[T any](n int) []step {
+	var s []T
+	var steps []step
+	var zero T
+	for range n {
+		s = append(s, zero)
+		if len(steps) == 0 || cap(s) != steps[len(steps)-1].cap {
+			steps = append(steps, step{len(s), cap(s)})
+			escaped = s
+		}
+	}
+	return steps
+}
+
+// writeGrowth writes steps as a table, with how much each one grew the
+// capacity and the bytes of the new backing array, for elements of
+// size bytes
+func writeGrowth(w io.Writer, steps []step, size int) {
+	fmt.Fprintf(w, "%8s %8s %8s %10s\n", "len", "cap", "growth", "array")
+	for i, s := range steps {
+		growth := "-"
+		if i > 0 {
+			growth = fmt.Sprintf("%.2fx", float64(s.cap)/float64(steps[i-1].cap))
+		}
+		fmt.Fprintf(w, "%8d %8d %8s %10s\n", s.len, s.cap, growth, fmt.Sprintf("%d B", s.cap*size))
+	}
+}
+
+// maxShown is the widest capacity show draws in full
+const maxShown = 32
+
+// show draws a slice's length and capacity: a # for each element in
+// the length, and a . for each one in the capacity past it
+//
+//	len=3  cap=4  [###.]
+func show[T any](s []T) string {
+	used, spare := len(s), cap(s)-len(s)
+	more := ""
+	if used+spare > maxShown {
+		used = min(used, maxShown)
+		spare = maxShown - used
+		more = "…"
+	}
+	return fmt.Sprintf("len=%-3d cap=%-3d [%s%s%s]",
+		len(s), cap(s), strings.Repeat("#", used), strings.Repeat(".", spare), more)
+}
+
+// traceAppends appends 1 to n to a nil slice, and draws it after each
+// append, marking the ones that moved it to a new backing array
+func traceAppends(w io.Writer, n int) {
+	var s []int
+	for i := 1; i <= n; i++ {
+		old := cap(s)
+		s = append(s, i)
+		moved := ""
+		if cap(s) != old {
+			moved = "  new array"
+		}
+		fmt.Fprintf(w, "append %-3d %s%s\n", i, show(s), moved)
+	}
+}
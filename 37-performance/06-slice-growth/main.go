@@ -0,0 +1,170 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+// record is a 24-byte element, to compare with 1- and 8-byte ones
+type record struct {
+	id, size, score int
+}
+
+// sink keeps results alive, so the calls below aren't optimized away
+var sink []int
+
+func main() {
+	testing.Init() // makes -test.benchtime settable
+	flag.Set("test.benchtime", "100ms")
+	flag.Parse()
+
+	fmt.Println("Slice Preallocation and Growth")
+	fmt.Println("==============================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+	example5()
+	example6()
+}
+
+// Example 1: Watching append
+func example1() {
+	fmt.Println("1. Appending 1 to 10 to a nil []int:")
+	var buf bytes.Buffer
+	traceAppends(&buf, 10)
+	printIndented(buf.String())
+	fmt.Println()
+}
+
+// Example 2: How capacity grows
+func example2() {
+	fmt.Println("2. Every time a []int grew, appending 3000 elements:")
+	steps := growth[int](3000)
+	var buf bytes.Buffer
+	writeGrowth(&buf, steps, 8)
+	printIndented(buf.String())
+	fmt.Println()
+}
+
+// Example 3: Element size changes the capacities
+func example3() {
+	fmt.Println("3. Capacities while appending 1000 elements, by element size:")
+	capsOf := func(steps []step) string {
+		var b strings.Builder
+		for i, s := range steps {
+			if i > 0 {
+				b.WriteByte(' ')
+			}
+			fmt.Fprint(&b, s.cap)
+		}
+		return b.String()
+	}
+	bytes := growth[byte](1000)
+	ints := growth[int](1000)
+	records := growth[record](1000)
+	fmt.Printf("   %-14s %s\n", "[]byte", capsOf(bytes))
+	fmt.Printf("   %-14s %s\n", "[]int", capsOf(ints))
+	fmt.Printf("   %-14s %s\n", "[]record (24B)", capsOf(records))
+	fmt.Println()
+}
+
+// Example 4: Preallocating
+func example4() {
+	const n = 10_000
+	batches := make([][]int, 100)
+	for i := range batches {
+		batches[i] = fillMakeLen(n / len(batches))
+	}
+
+	fmt.Printf("4. Building a []int of %d elements:\n", n)
+	printResults([]benchutil.Result{
+		bench("append to nil", func() { sink = fillAppend(n) }),
+		bench("make(0, n), append", func() { sink = fillMakeCap(n) }),
+		bench("make(n), index", func() { sink = fillMakeLen(n) }),
+		bench("append 100 batches", func() { sink = appendBatches(nil, batches) }),
+		bench("slices.Grow, batches", func() { sink = appendBatchesGrow(nil, batches) }),
+	})
+	fmt.Println()
+}
+
+// Example 5: Copying a slice
+func example5() {
+	s := fillMakeLen(1000)
+	fmt.Printf("5. Copying a []int of %d elements:\n", len(s))
+	printResults([]benchutil.Result{
+		bench("append(nil, s...)", func() { sink = cloneAppend(s) }),
+		bench("make, copy", func() { sink = cloneCopy(s) }),
+		bench("slices.Clone", func() { sink = cloneSlices(s) }),
+	})
+	fmt.Printf("   capacities: %d, %d, %d\n", cap(cloneAppend(s)), cap(cloneCopy(s)), cap(cloneSlices(s)))
+	fmt.Println()
+}
+
+// Example 6: Aliasing bugs
+func example6() {
+	fmt.Println("6. Slices that share a backing array:")
+
+	// Two appends to the same slice
+	base := make([]int, 3, 8)
+	a := append(base, 1)
+	b := append(base, 2)
+	fmt.Println("   a := append(base, 1); b := append(base, 2)")
+	fmt.Printf("     a %s  a[3]=%d, not 1\n", show(a), a[3])
+	base = base[:3:3] // a full slice expression: capacity = length
+	a = append(base, 1)
+	b = append(base, 2)
+	fmt.Println("   with base[:3:3]")
+	fmt.Printf("     a %s  a[3]=%d\n", show(a), a[3])
+	fmt.Printf("     b %s  b[3]=%d\n", show(b), b[3])
+
+	// A function that appends to its argument
+	all := []string{"go", "sql", "web"}
+	tags := withDefaultBuggy(all[:2])
+	fmt.Println("   withDefaultBuggy(all[:2])")
+	fmt.Printf("     tags %q, and all is now %q\n", tags, all)
+	all = []string{"go", "sql", "web"}
+	tags = withDefault(all[:2])
+	fmt.Println("   withDefault(all[:2]), with slices.Clip")
+	fmt.Printf("     tags %q, and all is still %q\n", tags, all)
+
+	// A small slice of a large array
+	big := make([]int, 1_000_000)
+	fmt.Println("   the first 10 of a million ints")
+	fmt.Printf("     headBuggy %s  keeps %d bytes alive\n", show(headBuggy(big, 10)), cap(headBuggy(big, 10))*8)
+	fmt.Printf("     head      %s  keeps %d bytes alive\n", show(head(big, 10)), cap(head(big, 10))*8)
+}
+
+// bench benchmarks fn, reporting its allocations
+func bench(name string, fn func()) benchutil.Result {
+	return benchutil.Run(name, func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			fn()
+		}
+	})
+}
+
+// printResults prints results as a table, indented under the example's
+// title
+func printResults(results []benchutil.Result) {
+	var buf bytes.Buffer
+	if err := benchutil.Write(&buf, results); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	printIndented(buf.String())
+}
+
+func printIndented(s string) {
+	for line := range strings.Lines(s) {
+		fmt.Print("   ", line)
+	}
+}
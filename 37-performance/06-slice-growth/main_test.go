@@ -0,0 +1,190 @@
+package main
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestGrowth checks the rules the lesson describes: a slice grows only
+// when it's full, to at least twice its capacity while it's small, and
+// by less once it's large
+func TestGrowth(t *testing.T) {
+	steps := growth[int](5000)
+	for i := 1; i < len(steps); i++ {
+		prev, s := steps[i-1], steps[i]
+		if s.len != prev.cap+1 {
+			t.Errorf("grew at len %d, want at %d, when cap %d was full", s.len, prev.cap+1, prev.cap)
+		}
+		factor := float64(s.cap) / float64(prev.cap)
+		switch {
+		case prev.cap < 256 && factor < 2:
+			t.Errorf("cap %d grew to %d: less than double", prev.cap, s.cap)
+		case prev.cap >= 1024 && factor >= 2:
+			t.Errorf("cap %d grew to %d: large slices should grow by less than double", prev.cap, s.cap)
+		}
+	}
+}
+
+func TestShow(t *testing.T) {
+	tests := []struct {
+		s    []int
+		want string
+	}{
+		{nil, "len=0   cap=0   []"},
+		{make([]int, 3, 4), "len=3   cap=4   [###.]"},
+		{make([]int, 2, 100), "len=2   cap=100 [##" + strings.Repeat(".", maxShown-2) + "…]"},
+	}
+	for _, tt := range tests {
+		if got := show(tt.s); got != tt.want {
+			t.Errorf("show = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestTraceAppends(t *testing.T) {
+	var buf bytes.Buffer
+	traceAppends(&buf, 20)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 20 {
+		t.Fatalf("%d lines, want 20", len(lines))
+	}
+	if !strings.HasSuffix(lines[0], "new array") {
+		t.Errorf("the first append should allocate: %q", lines[0])
+	}
+}
+
+func TestFills(t *testing.T) {
+	for _, n := range []int{0, 1, 1000} {
+		want := fillMakeLen(n)
+		if got := fillAppend(n); !slices.Equal(got, want) {
+			t.Errorf("fillAppend(%d) differs", n)
+		}
+		if got := fillMakeCap(n); !slices.Equal(got, want) {
+			t.Errorf("fillMakeCap(%d) differs", n)
+		}
+	}
+
+	batches := [][]int{{1, 2}, nil, {3}, {4, 5, 6}}
+	dst := []int{0}
+	a, b := appendBatches(slices.Clone(dst), batches), appendBatchesGrow(slices.Clone(dst), batches)
+	if want := []int{0, 1, 2, 3, 4, 5, 6}; !slices.Equal(a, want) || !slices.Equal(b, want) {
+		t.Errorf("appendBatches = %v, appendBatchesGrow = %v, want %v", a, b, want)
+	}
+}
+
+func TestClones(t *testing.T) {
+	for name, clone := range map[string]func([]int) []int{
+		"append": cloneAppend, "copy": cloneCopy, "slices.Clone": cloneSlices,
+	} {
+		s := []int{1, 2, 3}
+		c := clone(s)
+		c[0] = 99
+		if !slices.Equal(s, []int{1, 2, 3}) || !slices.Equal(c, []int{99, 2, 3}) {
+			t.Errorf("%s: changing the copy changed the original: %v", name, s)
+		}
+	}
+}
+
+func TestAliasing(t *testing.T) {
+	all := []string{"go", "sql", "web"}
+	withDefaultBuggy(all[:2])
+	if all[2] != "default" {
+		t.Errorf("withDefaultBuggy should show the bug, all = %q", all)
+	}
+
+	all = []string{"go", "sql", "web"}
+	tags := withDefault(all[:2])
+	if all[2] != "web" || !slices.Equal(tags, []string{"go", "sql", "default"}) {
+		t.Errorf("withDefault: tags = %q, all = %q", tags, all)
+	}
+
+	big := make([]int, 1000)
+	if got := cap(headBuggy(big, 10)); got != 1000 {
+		t.Errorf("headBuggy: cap %d, want 1000", got)
+	}
+	if got := cap(head(big, 10)); got != 10 {
+		t.Errorf("head: cap %d, want 10", got)
+	}
+}
+
+// TestAllocs pins the allocation counts the lesson claims
+func TestAllocs(t *testing.T) {
+	batches := [][]int{make([]int, 100), make([]int, 100), make([]int, 100)}
+	tests := []struct {
+		name string
+		fn   func()
+		want float64
+	}{
+		{"fillMakeCap", func() { sink = fillMakeCap(10_000) }, 1},
+		{"fillMakeLen", func() { sink = fillMakeLen(10_000) }, 1},
+		{"appendBatchesGrow", func() { sink = appendBatchesGrow(nil, batches) }, 1},
+		{"cloneSlices", func() { sink = cloneSlices(batches[0]) }, 1},
+	}
+	if raceEnabled {
+		tests[2].want = 2
+	}
+	for _, tt := range tests {
+		if got := testing.AllocsPerRun(100, tt.fn); got != tt.want {
+			t.Errorf("%s: %v allocs, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := testing.AllocsPerRun(100, func() { sink = fillAppend(10_000) }); got < 10 {
+		t.Errorf("fillAppend: %v allocs, want one for each time it grows", got)
+	}
+}
+
+func BenchmarkFill(b *testing.B) {
+	const n = 10_000
+	for _, f := range []struct {
+		name string
+		fill func(int) []int
+	}{
+		{"append", fillAppend},
+		{"makeCap", fillMakeCap},
+		{"makeLen", fillMakeLen},
+	} {
+		b.Run(f.name, func(b *testing.B) {
+			for b.Loop() {
+				sink = f.fill(n)
+			}
+		})
+	}
+}
+
+func BenchmarkBatches(b *testing.B) {
+	batches := make([][]int, 100)
+	for i := range batches {
+		batches[i] = fillMakeLen(100)
+	}
+	b.Run("append", func(b *testing.B) {
+		for b.Loop() {
+			sink = appendBatches(nil, batches)
+		}
+	})
+	b.Run("grow", func(b *testing.B) {
+		for b.Loop() {
+			sink = appendBatchesGrow(nil, batches)
+		}
+	})
+}
+
+func BenchmarkClone(b *testing.B) {
+	s := fillMakeLen(1000)
+	b.Run("append", func(b *testing.B) {
+		for b.Loop() {
+			sink = cloneAppend(s)
+		}
+	})
+	b.Run("copy", func(b *testing.B) {
+		for b.Loop() {
+			sink = cloneCopy(s)
+		}
+	})
+	b.Run("slices.Clone", func(b *testing.B) {
+		for b.Loop() {
+			sink = cloneSlices(s)
+		}
+	})
+}
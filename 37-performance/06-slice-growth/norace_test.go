@@ -0,0 +1,9 @@
+//go:build !race
+
+package main
+
+// raceEnabled reports whether the tests were built with -race. The
+// race detector instruments the code, and the compiler then skips
+// rewrites like the append(s, make([]T, n)...) in slices.Grow, which
+// otherwise doesn't allocate the slice it appends
+const raceEnabled = false
@@ -0,0 +1,76 @@
+package main
+
+import "slices"
+
+// Each fill function returns the numbers 0 to n-1
+
+// fillAppend appends to a nil slice, which grows, and copies, as it goes
+func fillAppend(n int) []int {
+	var s []int
+	for i := range n {
+		s = append(s, i)
+	}
+	return s
+}
+
+// fillMakeCap allocates the capacity up front, and appends into it
+func fillMakeCap(n int) []int {
+	s := make([]int, 0, n)
+	for i := range n {
+		s = append(s, i)
+	}
+	return s
+}
+
+// fillMakeLen allocates the length up front, and assigns by index
+func fillMakeLen(n int) []int {
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	return s
+}
+
+// appendBatches appends every batch to dst, growing as it goes
+func appendBatches(dst []int, batches [][]int) []int {
+	for _, b := range batches {
+		dst = append(dst, b...)
+	}
+	return dst
+}
+
+// appendBatchesGrow counts first, and grows dst once with slices.Grow,
+// which guarantees room for that many more elements
+func appendBatchesGrow(dst []int, batches [][]int) []int {
+	n := 0
+	for _, b := range batches {
+		n += len(b)
+	}
+	dst = slices.Grow(dst, n)
+	for _, b := range batches {
+		dst = append(dst, b...)
+	}
+	return dst
+}
+
+// Each clone function returns a copy of s with a backing array of its
+// own
+
+// cloneAppend appends s to a nil slice. The capacity can come out larger
+// than the length: append rounds up to the allocator's size classes
+func cloneAppend(s []int) []int {
+	return append([]int(nil), s...)
+}
+
+// cloneCopy makes a slice of the same length and copies into it. The
+// compiler spots make followed by copy, and skips zeroing the new array
+func cloneCopy(s []int) []int {
+	c := make([]int, len(s))
+	copy(c, s)
+	return c
+}
+
+// cloneSlices is slices.Clone, which says what it does
+func cloneSlices(s []int) []int {
+	return slices.Clone(s)
+}
@@ -0,0 +1,206 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+// Sinks keep results alive, so the calls below aren't optimized away
+var (
+	sink  int
+	found bool
+)
+
+func main() {
+	testing.Init() // makes -test.benchtime settable
+	flag.Set("test.benchtime", "100ms")
+	flag.Parse()
+
+	fmt.Println("Map Performance")
+	fmt.Println("===============")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+	example5()
+	example6()
+}
+
+// Example 1: Inserting, with and without a size hint
+func example1() {
+	fmt.Println("1. Inserting n ints:")
+	var results []benchutil.Result
+	for _, n := range []int{1000, 100_000} {
+		name := "n=" + strconv.Itoa(n)
+		results = append(results,
+			bench(name+"/map{}", func() { _ = insert(n) }),
+			bench(name+"/make(map, n)", func() { _ = insertSized(n) }))
+	}
+	printResults(results)
+	fmt.Println()
+}
+
+// Example 2: Looking up and deleting, by size
+func example2() {
+	fmt.Println("2. One operation on a map of n ints:")
+	var results []benchutil.Result
+	for _, n := range []int{100, 10_000, 1_000_000} {
+		m := insertSized(n)
+		name := "n=" + strconv.Itoa(n)
+		i := 0
+		next := func() int { // 0 to n-1, round and round
+			if i++; i == n {
+				i = 0
+			}
+			return i
+		}
+		results = append(results,
+			bench(name+"/hit", func() { sink, found = m[next()] }),
+			bench(name+"/miss", func() { sink, found = m[n+next()] }),
+			bench(name+"/delete, insert", func() {
+				k := next()
+				delete(m, k)
+				m[k] = k
+			}))
+	}
+	printResults(results)
+	fmt.Println()
+}
+
+// Example 3: Memory, and what delete gives back
+func example3() {
+	fmt.Println("3. Heap kept alive by a map[int]int:")
+	for _, n := range []int{1000, 10_000, 100_000, 1_000_000} {
+		b := retained(func() map[int]int { return insert(n) })
+		fmt.Printf("   %9d entries %12s %6.1f bytes each\n", n, benchutil.Bytes(b), float64(b)/float64(n))
+	}
+	const n = 100_000
+	deleted := retained(func() map[int]int {
+		m := insert(n)
+		for k := range m {
+			delete(m, k)
+		}
+		return m
+	})
+	cleared := retained(func() map[int]int {
+		m := insert(n)
+		clear(m)
+		return m
+	})
+	fmt.Printf("   %d entries, all deleted: %s\n", n, benchutil.Bytes(deleted))
+	fmt.Printf("   %d entries, cleared:     %s\n", n, benchutil.Bytes(cleared))
+	fmt.Println()
+}
+
+// Example 4: Struct keys and string keys
+func example4() {
+	const n = 10_000
+	byPoint := make(map[point]int, n)
+	byString := make(map[string]int, n)
+	points := make([]point, n)
+	strs := make([]string, n)
+	for i := range points {
+		points[i] = point{int32(i % 100), int32(i / 100)}
+		strs[i] = pointString(points[i])
+		byPoint[points[i]] = i
+		byString[strs[i]] = i
+	}
+
+	fmt.Printf("4. Looking up one of %d points:\n", n)
+	i := 0
+	next := func() int {
+		if i++; i == n {
+			i = 0
+		}
+		return i
+	}
+	printResults([]benchutil.Result{
+		bench("map[point]", func() { sink = byPoint[points[next()]] }),
+		bench("map[string], string kept", func() { sink = byString[strs[next()]] }),
+		bench("map[string], string built", func() { sink = byString[pointString(points[next()])] }),
+	})
+	fmt.Println()
+}
+
+// Example 5: A slice of pairs instead of a map
+func example5() {
+	fmt.Println("5. Looking up the last key of n, in a map and in a slice of pairs:")
+	var results []benchutil.Result
+	for _, n := range []int{4, 8, 16, 64} {
+		ints := make([]pair[int, int], n)
+		strs := make([]pair[string, int], n)
+		byInt := make(map[int]int, n)
+		byString := make(map[string]int, n)
+		for i := range n {
+			ints[i] = pair[int, int]{i * 7, i}
+			strs[i] = pair[string, int]{"key" + strconv.Itoa(i), i}
+			byInt[ints[i].key] = i
+			byString[strs[i].key] = i
+		}
+		// the last key is the worst case for a linear search
+		ik, sk := ints[n-1].key, strs[n-1].key
+		name := "n=" + strconv.Itoa(n)
+		results = append(results,
+			bench(name+"/int/map", func() { sink, found = byInt[ik] }),
+			bench(name+"/int/[]pair", func() { sink, found = lookupPairs(ints, ik) }),
+			bench(name+"/string/map", func() { sink, found = byString[sk] }),
+			bench(name+"/string/[]pair", func() { sink, found = lookupPairs(strs, sk) }))
+	}
+	printResults(results)
+	fmt.Println()
+}
+
+// Example 6: Iterating
+func example6() {
+	const n = 10_000
+	m := insertSized(n)
+	s := make([]int, n)
+	for i := range s {
+		s[i] = i
+	}
+	fmt.Printf("6. Adding up %d values:\n", n)
+	printResults([]benchutil.Result{
+		bench("range map", func() {
+			t := 0
+			for _, v := range m {
+				t += v
+			}
+			sink = t
+		}),
+		bench("range slice", func() {
+			t := 0
+			for _, v := range s {
+				t += v
+			}
+			sink = t
+		}),
+	})
+}
+
+// bench benchmarks fn, reporting its allocations
+func bench(name string, fn func()) benchutil.Result {
+	return benchutil.Run(name, func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			fn()
+		}
+	})
+}
+
+// printResults prints results as a table, indented under the example's
+// title
+func printResults(results []benchutil.Result) {
+	var buf bytes.Buffer
+	benchutil.Write(&buf, results)
+	for line := range strings.Lines(buf.String()) {
+		fmt.Print("   ", line)
+	}
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"maps"
+	"strconv"
+	"testing"
+)
+
+func TestInsert(t *testing.T) {
+	for _, n := range []int{0, 1, 9, 5000} {
+		a, b := insert(n), insertSized(n)
+		if len(a) != n || !maps.Equal(a, b) {
+			t.Errorf("n=%d: insert and insertSized differ", n)
+		}
+	}
+}
+
+// TestSizeHint checks that a size hint saves the allocations of
+// growing: the hint is worth giving whenever the size is known
+func TestSizeHint(t *testing.T) {
+	grow := testing.AllocsPerRun(10, func() { _ = insert(10_000) })
+	sized := testing.AllocsPerRun(10, func() { _ = insertSized(10_000) })
+	if sized >= grow {
+		t.Errorf("with a hint: %v allocs, without: %v", sized, grow)
+	}
+}
+
+// TestDeleteKeepsMemory checks that a map doesn't shrink: deleting every
+// key, or clear, keeps its tables
+func TestDeleteKeepsMemory(t *testing.T) {
+	const n = 100_000
+	full := retained(func() map[int]int { return insert(n) })
+	deleted := retained(func() map[int]int {
+		m := insert(n)
+		for k := range m {
+			delete(m, k)
+		}
+		return m
+	})
+	cleared := retained(func() map[int]int {
+		m := insert(n)
+		clear(m)
+		return m
+	})
+	if deleted < full*9/10 || cleared < full*9/10 {
+		t.Errorf("full %d bytes, deleted %d, cleared %d: want about the same", full, deleted, cleared)
+	}
+}
+
+func TestPointString(t *testing.T) {
+	tests := []struct {
+		p    point
+		want string
+	}{
+		{point{0, 0}, "0,0"},
+		{point{12, -7}, "12,-7"},
+		{point{-2147483648, 2147483647}, "-2147483648,2147483647"},
+	}
+	for _, tt := range tests {
+		if got := pointString(tt.p); got != tt.want {
+			t.Errorf("pointString(%v) = %q, want %q", tt.p, got, tt.want)
+		}
+	}
+}
+
+func TestLookupPairs(t *testing.T) {
+	pairs := []pair[string, int]{{"a", 1}, {"b", 2}, {"a", 3}}
+	if v, ok := lookupPairs(pairs, "a"); !ok || v != 1 {
+		t.Errorf(`lookupPairs("a") = %d, %t, want the first, 1`, v, ok)
+	}
+	if v, ok := lookupPairs(pairs, "z"); ok || v != 0 {
+		t.Errorf(`lookupPairs("z") = %d, %t, want 0, false`, v, ok)
+	}
+}
+
+func BenchmarkInsert(b *testing.B) {
+	for _, n := range []int{1000, 100_000} {
+		b.Run("grow/n="+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				_ = insert(n)
+			}
+		})
+		b.Run("sized/n="+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				_ = insertSized(n)
+			}
+		})
+	}
+}
+
+func BenchmarkLookup(b *testing.B) {
+	for _, n := range []int{100, 10_000, 1_000_000} {
+		m := insertSized(n)
+		b.Run("hit/n="+strconv.Itoa(n), func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				sink, found = m[i]
+				if i++; i == n {
+					i = 0
+				}
+			}
+		})
+		b.Run("miss/n="+strconv.Itoa(n), func(b *testing.B) {
+			i := 0
+			for b.Loop() {
+				sink, found = m[n+i]
+				if i++; i == n {
+					i = 0
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkPairs(b *testing.B) {
+	for _, n := range []int{4, 8, 16, 64} {
+		pairs := make([]pair[int, int], n)
+		m := make(map[int]int, n)
+		for i := range pairs {
+			pairs[i] = pair[int, int]{i * 7, i}
+			m[i*7] = i
+		}
+		key := pairs[n-1].key
+		b.Run("map/n="+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				sink, found = m[key]
+			}
+		})
+		b.Run("pairs/n="+strconv.Itoa(n), func(b *testing.B) {
+			for b.Loop() {
+				sink, found = lookupPairs(pairs, key)
+			}
+		})
+	}
+}
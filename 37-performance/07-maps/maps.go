@@ -0,0 +1,71 @@
+package main
+
+import (
+	"runtime"
+	"strconv"
+)
+
+// fill puts the keys 0 to n-1 in m, and returns it
+func fill(m map[int]int, n int) map[int]int {
+	for i := range n {
+		m[i] = i
+	}
+	return m
+}
+
+// insert builds a map of n ints, letting it grow as it goes
+func insert(n int) map[int]int {
+	return fill(map[int]int{}, n)
+}
+
+// insertSized builds the same map with a size hint, so that make
+// allocates room for n entries up front
+func insertSized(n int) map[int]int {
+	return fill(make(map[int]int, n), n)
+}
+
+// point is a comparable struct, usable as a key as it is
+type point struct {
+	x, y int32
+}
+
+// pointString is the same point as a string key, the way code that
+// keys a map by fmt.Sprintf("%d,%d", x, y) does
+func pointString(p point) string {
+	var buf [24]byte
+	b := strconv.AppendInt(buf[:0], int64(p.x), 10)
+	b = append(b, ',')
+	b = strconv.AppendInt(b, int64(p.y), 10)
+	return string(b)
+}
+
+// pair is a key and its value, for small sets kept in a slice
+type pair[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// lookupPairs finds key in pairs with a linear search
+func lookupPairs[K comparable, V any](pairs []pair[K, V], key K) (V, bool) {
+	for _, p := range pairs {
+		if p.key == key {
+			return p.value, true
+		}
+	}
+	var zero V
+	return zero, false
+}
+
+// retained returns how many bytes of heap the value build returns keeps
+// alive, after a garbage collection. Small values get lost in the
+// runtime's own allocations; measure things of a few KB and up
+func retained[T any](build func() T) int64 {
+	var before, after runtime.MemStats
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	v := build()
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(v)
+	return int64(after.HeapAlloc) - int64(before.HeapAlloc)
+}
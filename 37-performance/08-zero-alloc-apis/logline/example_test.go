@@ -0,0 +1,31 @@
+package logline_test
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/inancgumus/learngo/37-performance/08-zero-alloc-apis/logline"
+)
+
+func ExampleAppend() {
+	t := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+
+	// One buffer, reused for every event: after the first, Append
+	// doesn't allocate
+	buf := make([]byte, 0, 256)
+	for _, status := range []int{200, 404} {
+		buf = logline.Append(buf[:0], t, logline.Info, "request",
+			logline.String("path", "/api"), logline.Int("status", status))
+		buf = append(buf, '\n')
+		os.Stdout.Write(buf)
+	}
+	// Output:
+	// {"time":"2026-10-18T09:30:00Z","level":"info","msg":"request","path":"/api","status":200}
+	// {"time":"2026-10-18T09:30:00Z","level":"info","msg":"request","path":"/api","status":404}
+}
+
+func ExampleLevel_String() {
+	fmt.Println(logline.Info, logline.Warn, logline.Error)
+	// Output: info warn error
+}
@@ -0,0 +1,149 @@
+// Package logline writes log events as JSON lines, without allocating.
+//
+// Its API is built for code that logs on every request: Append writes
+// into a buffer the caller owns, Attr values are typed instead of
+// boxed in interfaces, and Logger reuses its buffers with a sync.Pool.
+// The tests check each of them with testing.AllocsPerRun.
+package logline
+
+import (
+	"io"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Level is the importance of an event.
+type Level int8
+
+// The levels, from least to most important.
+const (
+	Info Level = iota
+	Warn
+	Error
+)
+
+var levelNames = [...]string{Info: "info", Warn: "warn", Error: "error"}
+
+// String returns the level's name, like "info".
+func (l Level) String() string {
+	if l < 0 || int(l) >= len(levelNames) {
+		return "level(" + strconv.Itoa(int(l)) + ")"
+	}
+	return levelNames[l]
+}
+
+// Append appends an event to dst as one line of JSON, without the
+// newline, and returns the extended buffer, like the strconv Append
+// functions. It allocates only if dst is too small.
+func Append(dst []byte, t time.Time, level Level, msg string, attrs ...Attr) []byte {
+	dst = append(dst, `{"time":"`...)
+	dst = t.AppendFormat(dst, time.RFC3339Nano)
+	dst = append(dst, `","level":"`...)
+	dst = append(dst, level.String()...)
+	dst = append(dst, `","msg":`...)
+	dst = appendString(dst, msg)
+	for _, a := range attrs {
+		dst = append(dst, ',')
+		dst = appendString(dst, a.Key)
+		dst = append(dst, ':')
+		dst = appendValue(dst, a.Value)
+	}
+	return append(dst, '}')
+}
+
+func appendValue(dst []byte, v Value) []byte {
+	switch v.kind {
+	case KindInt:
+		return strconv.AppendInt(dst, v.num, 10)
+	case KindBool:
+		return strconv.AppendBool(dst, v.num != 0)
+	case KindDuration:
+		return strconv.AppendFloat(dst, float64(v.num)/float64(time.Millisecond), 'f', -1, 64)
+	default:
+		return appendString(dst, v.str)
+	}
+}
+
+// appendString appends s as a JSON string. Quotes, backslashes, and
+// control characters are escaped, and invalid UTF-8 becomes U+FFFD, as
+// encoding/json does.
+func appendString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	dst = append(dst, '"')
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= utf8.RuneSelf {
+			r, size := utf8.DecodeRuneInString(s[i:])
+			if r == utf8.RuneError && size == 1 {
+				dst = append(dst, `�`...)
+			} else {
+				dst = append(dst, s[i:i+size]...)
+			}
+			i += size
+			continue
+		}
+		switch {
+		case c == '"' || c == '\\':
+			dst = append(dst, '\\', c)
+		case c == '\n':
+			dst = append(dst, '\\', 'n')
+		case c == '\r':
+			dst = append(dst, '\\', 'r')
+		case c == '\t':
+			dst = append(dst, '\\', 't')
+		case c < 0x20:
+			dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+		default:
+			dst = append(dst, c)
+		}
+		i++
+	}
+	return append(dst, '"')
+}
+
+// maxPooled is the largest buffer Logger puts back in its pool. One huge
+// event shouldn't keep a huge buffer alive for every event after it.
+const maxPooled = 16 << 10
+
+// bufPool holds buffers for Logger. It holds pointers to slices: a
+// slice header is three words, and putting one in the pool's interface
+// would allocate every time.
+var bufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 1024)
+		return &b
+	},
+}
+
+// Logger writes events to an io.Writer, one line each. It's safe for
+// use by many goroutines: each formats its event in a buffer of its
+// own, from a pool, and only the write is serialized.
+type Logger struct {
+	mu  sync.Mutex
+	w   io.Writer
+	now func() time.Time
+}
+
+// New returns a Logger that writes to w.
+func New(w io.Writer) *Logger {
+	return &Logger{w: w, now: time.Now}
+}
+
+// Log writes an event with the current time.
+func (l *Logger) Log(level Level, msg string, attrs ...Attr) error {
+	bp := bufPool.Get().(*[]byte)
+	b := Append((*bp)[:0], l.now(), level, msg, attrs...)
+	b = append(b, '\n')
+
+	l.mu.Lock()
+	_, err := l.w.Write(b)
+	l.mu.Unlock()
+
+	if cap(b) <= maxPooled {
+		*bp = b
+		bufPool.Put(bp)
+	}
+	return err
+}
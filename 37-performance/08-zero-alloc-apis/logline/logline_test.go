@@ -0,0 +1,124 @@
+package logline
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+	"sync"
+	"testing"
+	"time"
+)
+
+var when = time.Date(2026, 10, 18, 9, 30, 0, 500, time.UTC)
+
+func TestAppend(t *testing.T) {
+	got := string(Append([]byte("> "), when, Warn, "slow",
+		String("path", "/a"), Int("n", -3), Bool("ok", true), Duration("ms", 1500*time.Microsecond)))
+	want := `> {"time":"2026-10-18T09:30:00.0000005Z","level":"warn","msg":"slow","path":"/a","n":-3,"ok":true,"ms":1.5}`
+	if got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+// TestAppendString checks that appendString writes JSON that decodes to
+// the same string encoding/json's output does
+func TestAppendString(t *testing.T) {
+	for _, s := range []string{
+		"", "plain", `quote " and \ backslash`, "tab\tnewline\nreturn\r",
+		"\x00\x01\x1f", "crème brûlée, 日本語", "bad \xff utf-8", "<html> &  ",
+	} {
+		var got, want string
+		if err := json.Unmarshal(appendString(nil, s), &got); err != nil {
+			t.Errorf("%q: %v", s, err)
+			continue
+		}
+		b, _ := json.Marshal(s)
+		json.Unmarshal(b, &want)
+		if got != want {
+			t.Errorf("%q decodes to %q, want %q", s, got, want)
+		}
+	}
+}
+
+func TestLevelString(t *testing.T) {
+	for l, want := range map[Level]string{Info: "info", Warn: "warn", Error: "error", 7: "level(7)", -1: "level(-1)"} {
+		if got := l.String(); got != want {
+			t.Errorf("Level(%d) = %q, want %q", l, got, want)
+		}
+	}
+}
+
+// TestAllocs checks the promise in the package's name
+func TestAllocs(t *testing.T) {
+	buf := make([]byte, 0, 512)
+	l := New(io.Discard)
+	tests := []struct {
+		name string
+		fn   func()
+		want float64
+	}{
+		{"Append", func() {
+			buf = Append(buf[:0], when, Info, "request", String("path", "/api"), Int("status", 200), Duration("ms", time.Second))
+		}, 0},
+		{"Logger.Log", func() {
+			l.Log(Info, "request", String("path", "/api"), Int("status", 200), Duration("ms", time.Second))
+		}, 0},
+	}
+	for _, tt := range tests {
+		if raceEnabled && tt.name == "Logger.Log" {
+			continue // the pool drops buffers, and Log makes new ones
+		}
+		if got := testing.AllocsPerRun(1000, tt.fn); got != tt.want {
+			t.Errorf("%s: %v allocs, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestLoggerConcurrent logs from many goroutines at once, and checks
+// that every line is whole. Run it with -race
+func TestLoggerConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	l := New(&out)
+	l.now = func() time.Time { return when }
+
+	const goroutines, each = 8, 100
+	var wg sync.WaitGroup
+	for g := range goroutines {
+		wg.Go(func() {
+			for i := range each {
+				l.Log(Info, "tick", Int("g", g), Int("i", i))
+			}
+		})
+	}
+	wg.Wait()
+
+	lines := 0
+	sc := bufio.NewScanner(&out)
+	for sc.Scan() {
+		lines++
+		if !json.Valid(sc.Bytes()) {
+			t.Fatalf("line %d isn't JSON: %s", lines, sc.Bytes())
+		}
+	}
+	if lines != goroutines*each {
+		t.Errorf("%d lines, want %d", lines, goroutines*each)
+	}
+}
+
+func BenchmarkAppend(b *testing.B) {
+	buf := make([]byte, 0, 512)
+	for b.Loop() {
+		buf = Append(buf[:0], when, Info, "request", String("path", "/api"), Int("status", 200), Duration("ms", time.Second))
+	}
+}
+
+func BenchmarkLog(b *testing.B) {
+	l := New(io.Discard)
+	b.ReportAllocs()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			l.Log(Info, "request", String("path", "/api"), Int("status", 200), Duration("ms", time.Second))
+		}
+	})
+}
@@ -0,0 +1,8 @@
+//go:build race
+
+package logline
+
+// raceEnabled reports whether the tests were built with -race. The
+// race detector makes sync.Pool drop items at random, to shake out code
+// that counts on getting back what it put in
+const raceEnabled = true
@@ -0,0 +1,57 @@
+package logline
+
+import "time"
+
+// Kind is the type of a Value.
+type Kind uint8
+
+// The kinds of Value.
+const (
+	KindString Kind = iota
+	KindInt
+	KindBool
+	KindDuration
+)
+
+// Value is one of a few types of value, held without an interface.
+// Putting an int in an interface allocates, unless it's small; putting
+// one in a Value never does.
+type Value struct {
+	kind Kind
+	num  int64
+	str  string
+}
+
+// Kind returns the type of v.
+func (v Value) Kind() Kind { return v.kind }
+
+// Attr is a key and a value.
+type Attr struct {
+	Key   string
+	Value Value
+}
+
+// String returns an Attr for a string.
+func String(key, v string) Attr {
+	return Attr{key, Value{kind: KindString, str: v}}
+}
+
+// Int returns an Attr for an int.
+func Int(key string, v int) Attr {
+	return Attr{key, Value{kind: KindInt, num: int64(v)}}
+}
+
+// Bool returns an Attr for a bool.
+func Bool(key string, v bool) Attr {
+	var n int64
+	if v {
+		n = 1
+	}
+	return Attr{key, Value{kind: KindBool, num: n}}
+}
+
+// Duration returns an Attr for a time.Duration, written in
+// milliseconds.
+func Duration(key string, v time.Duration) Attr {
+	return Attr{key, Value{kind: KindDuration, num: int64(v)}}
+}
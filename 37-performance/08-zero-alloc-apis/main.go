@@ -0,0 +1,184 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inancgumus/learngo/37-performance/08-zero-alloc-apis/logline"
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+// The event every example logs. Package variables, so that the
+// compiler can't box constant values at compile time and hide the cost
+var (
+	when   = time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	path   = "/api/users"
+	status = 200
+	size   = 5120
+	took   = 1234500 * time.Nanosecond
+)
+
+func main() {
+	testing.Init() // makes -test.benchtime settable
+	flag.Set("test.benchtime", "100ms")
+	flag.Parse()
+
+	fmt.Println("Zero-Allocation APIs")
+	fmt.Println("====================")
+	fmt.Println()
+
+	example1()
+	example2()
+	example3()
+	example4()
+	example5()
+}
+
+// Example 1: The helper as first written
+func example1() {
+	fmt.Println("1. formatEvent, with a map and encoding/json:")
+	fmt.Println("  ", formatEvent(when, "info", "request", "path", path, "status", status, "bytes", size, "ms", took))
+	allocs("formatEvent", func() {
+		_ = formatEvent(when, "info", "request", "path", path, "status", status, "bytes", size, "ms", took)
+	})
+	fmt.Println()
+}
+
+// Example 2: An Append-style API
+func example2() {
+	fmt.Println("2. logline.Append, into a buffer the caller owns:")
+	buf := make([]byte, 0, 256)
+	buf = appendRequest(buf[:0])
+	fmt.Println("  ", string(buf))
+	allocs("Append, reused buffer", func() { buf = appendRequest(buf[:0]) })
+	allocs("Append(nil, ...)", func() { _ = appendRequest(nil) })
+	fmt.Println()
+}
+
+// Example 3: Boxing at the call site
+func example3() {
+	fmt.Println("3. Values passed as any, and as typed Attrs:")
+	buf := make([]byte, 0, 256)
+	allocs("appendAny", func() {
+		buf = appendAny(buf[:0], when, logline.Info, "request", "path", path, "status", status, "bytes", size, "ms", took)
+	})
+	allocs("Append with Attrs", func() { buf = appendRequest(buf[:0]) })
+	fmt.Println()
+}
+
+// Example 4: Reusing buffers with sync.Pool
+func example4() {
+	fmt.Println("4. Writing events to an io.Writer:")
+	allocs("a new buffer per event", func() {
+		io.Discard.Write(append(appendRequest(nil), '\n'))
+	})
+	allocs("make([]byte, 0, 1024)", func() {
+		buf := make([]byte, 0, 1024)
+		io.Discard.Write(append(appendRequest(buf), '\n'))
+	})
+	l := logline.New(io.Discard)
+	allocs("Logger.Log, pooled", func() {
+		l.Log(logline.Info, "request",
+			logline.String("path", path), logline.Int("status", status),
+			logline.Int("bytes", size), logline.Duration("ms", took))
+	})
+	fmt.Println()
+}
+
+// Example 5: What each step saves
+func example5() {
+	fmt.Println("5. Time per event:")
+	buf := make([]byte, 0, 256)
+	l := logline.New(io.Discard)
+	results := []benchutil.Result{
+		bench("formatEvent", func() {
+			_ = formatEvent(when, "info", "request", "path", path, "status", status, "bytes", size, "ms", took)
+		}),
+		bench("appendAny", func() {
+			buf = appendAny(buf[:0], when, logline.Info, "request", "path", path, "status", status, "bytes", size, "ms", took)
+		}),
+		bench("Append", func() { buf = appendRequest(buf[:0]) }),
+		bench("Logger.Log", func() {
+			l.Log(logline.Info, "request",
+				logline.String("path", path), logline.Int("status", status),
+				logline.Int("bytes", size), logline.Duration("ms", took))
+		}),
+	}
+	var out bytes.Buffer
+	benchutil.Write(&out, results)
+	for line := range strings.Lines(out.String()) {
+		fmt.Print("   ", line)
+	}
+}
+
+// formatEvent is the helper as first written: a map of boxed values,
+// marshaled by encoding/json. args are keys and values, in turn
+func formatEvent(t time.Time, level, msg string, args ...any) string {
+	m := map[string]any{"time": t.Format(time.RFC3339Nano), "level": level, "msg": msg}
+	for i := 0; i+1 < len(args); i += 2 {
+		key, _ := args[i].(string)
+		v := args[i+1]
+		if d, ok := v.(time.Duration); ok {
+			v = float64(d) / float64(time.Millisecond)
+		}
+		m[key] = v
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return fmt.Sprintf(`{"error":%q}`, err)
+	}
+	return string(b)
+}
+
+// appendAny is logline.Append with keys and values in turn, of any type,
+// like log/slog's convenience methods. It converts them to Attrs without
+// allocating, but the caller has already paid: values that don't fit in
+// an interface as they are were boxed to make the call
+func appendAny(dst []byte, t time.Time, level logline.Level, msg string, args ...any) []byte {
+	var buf [8]logline.Attr
+	attrs := buf[:0]
+	for i := 0; i+1 < len(args); i += 2 {
+		key, _ := args[i].(string)
+		switch v := args[i+1].(type) {
+		case string:
+			attrs = append(attrs, logline.String(key, v))
+		case int:
+			attrs = append(attrs, logline.Int(key, v))
+		case bool:
+			attrs = append(attrs, logline.Bool(key, v))
+		case time.Duration:
+			attrs = append(attrs, logline.Duration(key, v))
+		default:
+			attrs = append(attrs, logline.String(key, fmt.Sprint(v)))
+		}
+	}
+	return logline.Append(dst, t, level, msg, attrs...)
+}
+
+// appendRequest appends the lesson's event with typed Attrs
+func appendRequest(dst []byte) []byte {
+	return logline.Append(dst, when, logline.Info, "request",
+		logline.String("path", path), logline.Int("status", status),
+		logline.Int("bytes", size), logline.Duration("ms", took))
+}
+
+// allocs prints how many times fn allocates, on average
+func allocs(name string, fn func()) {
+	fmt.Printf("   %-24s %v allocs\n", name, testing.AllocsPerRun(1000, fn))
+}
+
+// bench benchmarks fn, reporting its allocations
+func bench(name string, fn func()) benchutil.Result {
+	return benchutil.Run(name, func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			fn()
+		}
+	})
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/inancgumus/learngo/37-performance/08-zero-alloc-apis/logline"
+)
+
+// TestSameEvent checks that every version writes the same event: the
+// same JSON object, if not in the same order
+func TestSameEvent(t *testing.T) {
+	decode := func(name string, b []byte) map[string]any {
+		var m map[string]any
+		if err := json.Unmarshal(b, &m); err != nil {
+			t.Fatalf("%s: %v\n%s", name, err, b)
+		}
+		return m
+	}
+	want := decode("formatEvent", []byte(formatEvent(when, "info", "request", "path", path, "status", status, "bytes", size, "ms", took)))
+	for name, b := range map[string][]byte{
+		"appendAny":     appendAny(nil, when, logline.Info, "request", "path", path, "status", status, "bytes", size, "ms", took),
+		"appendRequest": appendRequest(nil),
+	} {
+		if got := decode(name, b); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s:\n%v\nwant\n%v", name, got, want)
+		}
+	}
+}
+
+// TestAllocs pins the counts the lesson claims for the versions in
+// this package; logline's own tests pin the rest
+func TestAllocs(t *testing.T) {
+	buf := make([]byte, 0, 256)
+	if got := testing.AllocsPerRun(100, func() {
+		buf = appendAny(buf[:0], when, logline.Info, "request", "path", path, "status", status, "bytes", size, "ms", took)
+	}); got != 3 {
+		t.Errorf("appendAny: %v allocs, want 3: path, size, and took boxed", got)
+	}
+	if got := testing.AllocsPerRun(100, func() { buf = appendRequest(buf[:0]) }); got != 0 {
+		t.Errorf("appendRequest: %v allocs, want 0", got)
+	}
+}
+
+func BenchmarkFormatEvent(b *testing.B) {
+	for b.Loop() {
+		_ = formatEvent(when, "info", "request", "path", path, "status", status, "bytes", size, "ms", took)
+	}
+}
+
+func BenchmarkAppendAny(b *testing.B) {
+	buf := make([]byte, 0, 256)
+	for b.Loop() {
+		buf = appendAny(buf[:0], when, logline.Info, "request", "path", path, "status", status, "bytes", size, "ms", took)
+	}
+}
+
+func BenchmarkAppendRequest(b *testing.B) {
+	buf := make([]byte, 0, 256)
+	for b.Loop() {
+		buf = appendRequest(buf[:0])
+	}
+}
@@ -0,0 +1,101 @@
+// ---------------------------------------------------------
+// EXERCISE: Allocation-Free Tally
+//
+//  A game server sends a line every second with the points each
+//  player scored: "alice:3 bob:-2 carol:10". tallySlow below adds
+//  them to a map of totals. It runs for every line, so it's hot,
+//  and it allocates 7 times a line.
+//
+//  1- Write a benchmark for tallySlow, with b.ReportAllocs, and a
+//     test that prints testing.AllocsPerRun for it
+//
+//  2- Run go build -gcflags=-m and find the lines of tallySlow the
+//     compiler says escape to the heap
+//
+//  3- Write tally(line string, totals map[string]int) error: the
+//     same results and the same errors, and no allocations once
+//     every player is in the map
+//
+//  4- Add a test that fails if testing.AllocsPerRun for tally
+//     isn't 0, and a test that tally and tallySlow agree
+//
+// HINTS
+//
+//  - strings.Fields and strings.Split each return a new slice.
+//    strings.FieldsSeq (Go 1.24) yields the fields one at a time
+//  - strings.Cut splits at the first ":" without a slice: both
+//    halves are substrings of line, and share its bytes
+//  - debugf boxes its arguments into interfaces even when debug is
+//    off. Check debug before the call
+//  - Errors may allocate: a bad line is rare, and not the hot path
+//  - Adding a new player to the map allocates. That's fine: the
+//    players are the same from one line to the next
+//
+// EXPECTED OUTPUT
+//
+//  Test 1: Both versions agree
+//  alice: 3
+//  bob: 5
+//  carol: 260
+//  dave: 4
+//  same: true
+//
+//  Test 2: Allocations per line
+//  tallySlow: 7
+//  tally: 0
+//
+//  Test 3: Bad input
+//  tally: bad score "alice=3"
+//  tally: bad score "bob:lots": strconv.Atoi: parsing "lots": invalid syntax
+//
+// ---------------------------------------------------------
+
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+)
+
+// debug turns on a log line for every score. It's off, as it would be
+// in production
+var debug = false
+
+// lines are what a game server sends once a second: the points each
+// player scored
+var lines = []string{
+	"alice:3 bob:-2 carol:10",
+	"bob:7  alice:1",
+	"carol:250 dave:4 alice:-1",
+}
+
+// tallySlow adds the scores in line to totals
+func tallySlow(line string, totals map[string]int) error {
+	for _, field := range strings.Fields(line) {
+		parts := strings.Split(field, ":")
+		if len(parts) != 2 {
+			return fmt.Errorf("tally: bad score %q", field)
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return fmt.Errorf("tally: bad score %q: %w", field, err)
+		}
+		debugf("tally: %s %+d", parts[0], n)
+		totals[parts[0]] += n
+	}
+	return nil
+}
+
+// debugf logs when debug is on
+//
+//go:noinline
+func debugf(format string, args ...any) {
+	if debug {
+		log.Printf(format, args...)
+	}
+}
+
+func main() {
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// debug turns on a log line for every score. It's off, as it would be
+// in production
+var debug = false
+
+// lines are what a game server sends once a second: the points each
+// player scored
+var lines = []string{
+	"alice:3 bob:-2 carol:10",
+	"bob:7  alice:1",
+	"carol:250 dave:4 alice:-1",
+}
+
+// tallySlow adds the scores in line to totals: the version to fix.
+// strings.Fields and strings.Split return new slices, and the log
+// call boxes name and n into interfaces, even with debug off
+func tallySlow(line string, totals map[string]int) error {
+	for _, field := range strings.Fields(line) {
+		parts := strings.Split(field, ":")
+		if len(parts) != 2 {
+			return fmt.Errorf("tally: bad score %q", field)
+		}
+		n, err := strconv.Atoi(parts[1])
+		if err != nil {
+			return fmt.Errorf("tally: bad score %q: %w", field, err)
+		}
+		debugf("tally: %s %+d", parts[0], n)
+		totals[parts[0]] += n
+	}
+	return nil
+}
+
+// tally is tallySlow without allocations, for players already in
+// totals:
+//   - strings.FieldsSeq yields the fields one by one, without a slice
+//   - strings.Cut splits a field into two substrings of it
+//   - the debug check comes before the call, so nothing is boxed
+//   - errors still allocate, but only on the error path
+func tally(line string, totals map[string]int) error {
+	for field := range strings.FieldsSeq(line) {
+		name, score, ok := strings.Cut(field, ":")
+		if !ok {
+			return fmt.Errorf("tally: bad score %q", field)
+		}
+		n, err := strconv.Atoi(score)
+		if err != nil {
+			return fmt.Errorf("tally: bad score %q: %w", field, err)
+		}
+		if debug {
+			debugf("tally: %s %+d", name, n)
+		}
+		totals[name] += n
+	}
+	return nil
+}
+
+// debugf logs when debug is on
+//
+//go:noinline
+func debugf(format string, args ...any) {
+	if debug {
+		log.Printf(format, args...)
+	}
+}
+
+func main() {
+	fmt.Println("Allocation-Free Tally - Solution")
+	fmt.Println("================================")
+	fmt.Println()
+
+	fmt.Println("Test 1: Both versions agree")
+	slow, fast := map[string]int{}, map[string]int{}
+	for _, line := range lines {
+		if err := tallySlow(line, slow); err != nil {
+			log.Fatal(err)
+		}
+		if err := tally(line, fast); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(fast)) {
+		fmt.Printf("%s: %d\n", name, fast[name])
+	}
+	fmt.Println("same:", maps.Equal(slow, fast))
+	fmt.Println()
+
+	fmt.Println("Test 2: Allocations per line")
+	for _, v := range []struct {
+		name string
+		fn   func(string, map[string]int) error
+	}{
+		{"tallySlow", tallySlow},
+		{"tally", tally},
+	} {
+		allocs := testing.AllocsPerRun(100, func() {
+			for _, line := range lines {
+				v.fn(line, fast)
+			}
+		})
+		fmt.Printf("%s: %v\n", v.name, allocs/float64(len(lines)))
+	}
+	fmt.Println()
+
+	fmt.Println("Test 3: Bad input")
+	for _, line := range []string{"alice=3", "bob:lots"} {
+		fmt.Println(tally(line, fast))
+	}
+}
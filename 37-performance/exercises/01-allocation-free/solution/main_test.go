@@ -0,0 +1,74 @@
+package main
+
+import (
+	"maps"
+	"testing"
+)
+
+func TestTally(t *testing.T) {
+	totals := map[string]int{}
+	for _, line := range lines {
+		if err := tally(line, totals); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := map[string]int{"alice": 3, "bob": 5, "carol": 260, "dave": 4}
+	if !maps.Equal(totals, want) {
+		t.Errorf("got %v, want %v", totals, want)
+	}
+}
+
+func TestTallyMatchesSlow(t *testing.T) {
+	for _, line := range append(lines, "", "   ", "x:0", "a:1 a:2\ta:3\n") {
+		slow, fast := map[string]int{}, map[string]int{}
+		errSlow, errFast := tallySlow(line, slow), tally(line, fast)
+		if !maps.Equal(slow, fast) || (errSlow == nil) != (errFast == nil) {
+			t.Errorf("%q: slow %v, %v; fast %v, %v", line, slow, errSlow, fast, errFast)
+		}
+	}
+}
+
+func TestTallyErrors(t *testing.T) {
+	for _, line := range []string{"alice", "alice=3", "bob:lots", "bob:"} {
+		if err := tally(line, map[string]int{}); err == nil {
+			t.Errorf("%q: no error", line)
+		}
+	}
+}
+
+// TestTallyAllocs is the exercise's goal: once every player is in the
+// map, a line costs no allocations
+func TestTallyAllocs(t *testing.T) {
+	totals := map[string]int{}
+	for _, line := range lines {
+		tally(line, totals)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		for _, line := range lines {
+			tally(line, totals)
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("tally allocates %v times for %d lines, want 0", allocs, len(lines))
+	}
+}
+
+func BenchmarkTally(b *testing.B) {
+	for _, v := range []struct {
+		name string
+		fn   func(string, map[string]int) error
+	}{
+		{"slow", tallySlow},
+		{"fast", tally},
+	} {
+		b.Run(v.name, func(b *testing.B) {
+			totals := map[string]int{}
+			b.ReportAllocs()
+			for b.Loop() {
+				for _, line := range lines {
+					v.fn(line, totals)
+				}
+			}
+		})
+	}
+}
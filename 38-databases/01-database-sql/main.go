@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+)
+
+func main() {
+	fmt.Println("database/sql with SQLite")
+	fmt.Println("========================")
+	fmt.Println()
+
+	dir, err := os.MkdirTemp("", "learngo-db")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	s := example1(ctx, filepath.Join(dir, "users.db"))
+	if s == nil {
+		return
+	}
+	defer s.Close()
+
+	example2(ctx, s)
+	example3(ctx, s)
+	example4(ctx, s)
+	example5(ctx, s)
+}
+
+// Example 1: Opening a database
+func example1(ctx context.Context, path string) *Store {
+	fmt.Println("1. Opening a database:")
+	s, err := openStore(ctx, path)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return nil
+	}
+	var version string
+	if err := s.db.QueryRowContext(ctx, `SELECT sqlite_version()`).Scan(&version); err != nil {
+		fmt.Println("   error:", err)
+		return nil
+	}
+	fmt.Printf("   %s, SQLite %s\n", filepath.Base(path), version)
+	fmt.Printf("   connections open: %d, idle: %d\n", s.db.Stats().OpenConnections, s.db.Stats().Idle)
+	fmt.Println()
+	return s
+}
+
+// Example 2: ExecContext
+func example2(ctx context.Context, s *Store) {
+	fmt.Println("2. Inserting with ExecContext:")
+	for _, u := range []User{
+		{Name: "Ada", Email: "ada@example.com", Phone: sql.NullString{String: "555-0100", Valid: true}},
+		{Name: "Grace", Email: "grace@example.com"}, // no phone: NULL
+		{Name: "Linus", Email: "linus@example.com", Phone: sql.NullString{String: "555-0199", Valid: true}},
+	} {
+		id, err := s.CreateUser(ctx, u)
+		if err != nil {
+			fmt.Println("   error:", err)
+			continue
+		}
+		fmt.Printf("   %-6s id %d\n", u.Name, id)
+	}
+	_, err := s.CreateUser(ctx, User{Name: "Ada again", Email: "ada@example.com"})
+	fmt.Println("   the same email again:", err)
+	fmt.Println()
+}
+
+// Example 3: QueryContext and Scan
+func example3(ctx context.Context, s *Store) {
+	fmt.Println("3. Reading rows with QueryContext:")
+	users, err := s.ListUsers(ctx)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	for _, u := range users {
+		fmt.Printf("   %d  %-6s %-18s %s\n", u.ID, u.Name, u.Email, phone(u))
+	}
+	fmt.Println()
+}
+
+// Example 4: NULL
+func example4(ctx context.Context, s *Store) {
+	fmt.Println("4. Scanning NULL:")
+	const q = `SELECT phone FROM users WHERE name = 'Grace'`
+
+	var str string
+	err := s.db.QueryRowContext(ctx, q).Scan(&str)
+	fmt.Println("   into a string:    ", err)
+
+	var ns sql.NullString
+	err = s.db.QueryRowContext(ctx, q).Scan(&ns)
+	fmt.Printf("   into a NullString: %+v, err %v\n", ns, err)
+
+	var ptr *string
+	err = s.db.QueryRowContext(ctx, q).Scan(&ptr)
+	fmt.Printf("   into a *string:    %v, err %v\n", ptr, err)
+
+	err = s.db.QueryRowContext(ctx, `SELECT COALESCE(phone, '') FROM users WHERE name = 'Grace'`).Scan(&str)
+	fmt.Printf("   with COALESCE:     %q, err %v\n", str, err)
+
+	if err := s.UpdatePhone(ctx, 2, sql.NullString{String: "555-0142", Valid: true}); err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	u, err := s.GetUser(ctx, 2)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	fmt.Printf("   after UpdatePhone: %s %s\n", u.Name, phone(u))
+	fmt.Println()
+}
+
+// Example 5: No rows, and ErrNotFound
+func example5(ctx context.Context, s *Store) {
+	fmt.Println("5. A user that doesn't exist:")
+	_, err := s.GetUser(ctx, 42)
+	fmt.Println("   GetUser(42):", err)
+	fmt.Println("   errors.Is(err, apperr.ErrNotFound):", errors.Is(err, apperr.ErrNotFound))
+	fmt.Println("   errors.Is(err, sql.ErrNoRows):     ", errors.Is(err, sql.ErrNoRows))
+
+	err = s.UpdatePhone(ctx, 42, sql.NullString{})
+	fmt.Println("   UpdatePhone(42):", err)
+}
+
+// phone returns a user's phone, or - for NULL
+func phone(u User) string {
+	if !u.Phone.Valid {
+		return "-"
+	}
+	return u.Phone.String
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+)
+
+// newTestStore opens a store in a file of its own, removed after the
+// test. Not ":memory:": every connection in the pool would get a
+// database of its own
+func newTestStore(t *testing.T) *Store {
+	t.Helper()
+	s, err := openStore(t.Context(), filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func phoneOf(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: true}
+}
+
+func TestCreateAndGet(t *testing.T) {
+	s, ctx := newTestStore(t), t.Context()
+	for _, want := range []User{
+		{Name: "Ada", Email: "ada@example.com", Phone: phoneOf("555-0100")},
+		{Name: "Grace", Email: "grace@example.com"},
+		{Name: "O'Brien", Email: "x'); DROP TABLE users; --", Phone: phoneOf("")},
+	} {
+		id, err := s.CreateUser(ctx, want)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want.ID = id
+		got, err := s.GetUser(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("GetUser(%d) = %+v, want %+v", id, got, want)
+		}
+	}
+}
+
+func TestListUsers(t *testing.T) {
+	s, ctx := newTestStore(t), t.Context()
+	users, err := s.ListUsers(ctx)
+	if err != nil || len(users) != 0 {
+		t.Fatalf("empty table: %v, %v", users, err)
+	}
+	for _, email := range []string{"c@x", "a@x", "b@x"} {
+		if _, err := s.CreateUser(ctx, User{Name: email, Email: email}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	users, err = s.ListUsers(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 3 {
+		t.Fatalf("%d users, want 3", len(users))
+	}
+	for i, u := range users {
+		if u.ID != int64(i+1) {
+			t.Errorf("users[%d].ID = %d, want %d: not in order of ID", i, u.ID, i+1)
+		}
+	}
+}
+
+func TestDuplicateEmail(t *testing.T) {
+	s, ctx := newTestStore(t), t.Context()
+	if _, err := s.CreateUser(ctx, User{Name: "A", Email: "a@x"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.CreateUser(ctx, User{Name: "B", Email: "a@x"}); err == nil {
+		t.Error("a second user with the same email: no error")
+	}
+}
+
+func TestUpdatePhone(t *testing.T) {
+	s, ctx := newTestStore(t), t.Context()
+	id, err := s.CreateUser(ctx, User{Name: "A", Email: "a@x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, phone := range []sql.NullString{phoneOf("555-0100"), {}} {
+		if err := s.UpdatePhone(ctx, id, phone); err != nil {
+			t.Fatal(err)
+		}
+		u, err := s.GetUser(ctx, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if u.Phone != phone {
+			t.Errorf("phone = %+v, want %+v", u.Phone, phone)
+		}
+	}
+}
+
+// TestNotFound checks that a missing user is apperr.ErrNotFound, and
+// that database/sql's error doesn't leak to callers
+func TestNotFound(t *testing.T) {
+	s, ctx := newTestStore(t), t.Context()
+	_, getErr := s.GetUser(ctx, 42)
+	updateErr := s.UpdatePhone(ctx, 42, phoneOf("555-0100"))
+	for name, err := range map[string]error{"GetUser": getErr, "UpdatePhone": updateErr} {
+		if !errors.Is(err, apperr.ErrNotFound) {
+			t.Errorf("%s: %v, want apperr.ErrNotFound", name, err)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("%s: wraps sql.ErrNoRows", name)
+		}
+	}
+}
+
+func TestCanceledContext(t *testing.T) {
+	s := newTestStore(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+	if _, err := s.ListUsers(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("ListUsers with a canceled context: %v", err)
+	}
+}
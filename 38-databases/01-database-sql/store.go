@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+	_ "modernc.org/sqlite" // a pure-Go SQLite driver, registered as "sqlite"
+)
+
+// User is a row of the users table: the user of 32-http-servers, with
+// a phone number that may be NULL. sql.NullString holds a string, and
+// whether there is one
+type User struct {
+	ID    int64
+	Name  string
+	Email string
+	Phone sql.NullString
+}
+
+const schema = `
+CREATE TABLE IF NOT EXISTS users (
+	id    INTEGER PRIMARY KEY,
+	name  TEXT NOT NULL,
+	email TEXT NOT NULL UNIQUE,
+	phone TEXT
+)`
+
+// Store keeps users in a SQL database
+type Store struct {
+	db *sql.DB
+}
+
+// openStore opens the SQLite database at path, creating the file and
+// the table if they don't exist.
+//
+// A *sql.DB isn't a connection: it's a pool of them, safe for use by
+// many goroutines. Open one when the program starts, and share it
+func openStore(ctx context.Context, path string) (*Store, error) {
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, err
+	}
+	// Open only checks its arguments; PingContext connects
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the database, and every connection in the pool
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// CreateUser inserts u, ignoring its ID, and returns the ID the
+// database gave it
+func (s *Store) CreateUser(ctx context.Context, u User) (int64, error) {
+	// The ? placeholders send the values apart from the SQL, so a name
+	// like "x'); DROP TABLE users; --" is only a name. Never build SQL
+	// from values with fmt.Sprintf. u.Phone is sent as NULL when it
+	// isn't Valid
+	res, err := s.db.ExecContext(ctx,
+		`INSERT INTO users (name, email, phone) VALUES (?, ?, ?)`,
+		u.Name, u.Email, u.Phone)
+	if err != nil {
+		return 0, fmt.Errorf("create user %q: %w", u.Email, err)
+	}
+	return res.LastInsertId()
+}
+
+// GetUser returns the user with the given ID, or an error wrapping
+// apperr.ErrNotFound if there isn't one
+func (s *Store) GetUser(ctx context.Context, id int64) (User, error) {
+	row := s.db.QueryRowContext(ctx,
+		`SELECT id, name, email, phone FROM users WHERE id = ?`, id)
+	u, err := scanUser(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Callers check for the domain's error, not database/sql's:
+		// they shouldn't have to know where users are kept
+		return User{}, fmt.Errorf("user %d: %w", id, apperr.ErrNotFound)
+	}
+	if err != nil {
+		return User{}, fmt.Errorf("get user %d: %w", id, err)
+	}
+	return u, nil
+}
+
+// ListUsers returns every user, in order of ID
+func (s *Store) ListUsers(ctx context.Context) ([]User, error) {
+	rows, err := s.db.QueryContext(ctx,
+		`SELECT id, name, email, phone FROM users ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	// Close gives the connection back to the pool. Next closes the rows
+	// when it runs out, but not when the loop returns early
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("list users: %w", err)
+		}
+		users = append(users, u)
+	}
+	// Next returns false on an error too; Err tells the two apart
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("list users: %w", err)
+	}
+	return users, nil
+}
+
+// UpdatePhone sets a user's phone, or clears it to NULL if phone isn't
+// Valid. It returns an error wrapping apperr.ErrNotFound if no user
+// has the ID
+func (s *Store) UpdatePhone(ctx context.Context, id int64, phone sql.NullString) error {
+	res, err := s.db.ExecContext(ctx, `UPDATE users SET phone = ? WHERE id = ?`, phone, id)
+	if err != nil {
+		return fmt.Errorf("update user %d: %w", id, err)
+	}
+	// An UPDATE that matches no rows isn't an error to the database
+	n, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("update user %d: %w", id, err)
+	}
+	if n == 0 {
+		return fmt.Errorf("user %d: %w", id, apperr.ErrNotFound)
+	}
+	return nil
+}
+
+// scanner is what *sql.Row and *sql.Rows have in common
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// scanUser scans the columns id, name, email, and phone, in that order
+func scanUser(s scanner) (User, error) {
+	var u User
+	err := s.Scan(&u.ID, &u.Name, &u.Email, &u.Phone)
+	return u, err
+}
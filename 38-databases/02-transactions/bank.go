@@ -0,0 +1,190 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+	"github.com/inancgumus/learngo/pkg/retry"
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+)
+
+// ErrInsufficientFunds is returned by Transfer when the account to take
+// the money from doesn't have enough
+var ErrInsufficientFunds = errors.New("insufficient funds")
+
+const schema = `
+CREATE TABLE IF NOT EXISTS accounts (
+	id      INTEGER PRIMARY KEY,
+	owner   TEXT NOT NULL,
+	balance INTEGER NOT NULL CHECK (balance >= 0)
+)`
+
+// Bank keeps accounts in a SQL database. Balances are in cents
+type Bank struct {
+	db *sql.DB
+}
+
+// openDB opens the SQLite database at path.
+//
+// The pragmas run on every connection the pool opens. WAL lets readers
+// and one writer work at the same time, and busy_timeout makes a writer
+// wait up to a second for another one to finish, instead of failing.
+//
+// txlock says how transactions begin. "deferred" takes the write lock
+// at the first write, and a transaction that has read by then can't
+// wait for it: it fails at once with SQLITE_BUSY. "immediate" takes the
+// write lock at BEGIN, where busy_timeout applies
+func openDB(ctx context.Context, path, txlock string) (*sql.DB, error) {
+	dsn := "file:" + path + "?_pragma=journal_mode(WAL)&_pragma=busy_timeout(1000)&_txlock=" + txlock
+	db, err := sql.Open("sqlite", dsn)
+	if err != nil {
+		return nil, err
+	}
+	if err := db.PingContext(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("open %s: %w", path, err)
+	}
+	return db, nil
+}
+
+// openBank opens the bank at path, creating the file and the table if
+// they don't exist. Its transactions all write, so they begin immediate
+func openBank(ctx context.Context, path string) (*Bank, error) {
+	db, err := openDB(ctx, path, "immediate")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("create schema: %w", err)
+	}
+	return &Bank{db: db}, nil
+}
+
+// Close closes the database, and every connection in the pool
+func (b *Bank) Close() error {
+	return b.db.Close()
+}
+
+// Open creates an account and returns its ID
+func (b *Bank) Open(ctx context.Context, owner string, balance int64) (int64, error) {
+	res, err := b.db.ExecContext(ctx,
+		`INSERT INTO accounts (owner, balance) VALUES (?, ?)`, owner, balance)
+	if err != nil {
+		return 0, fmt.Errorf("open account for %s: %w", owner, err)
+	}
+	return res.LastInsertId()
+}
+
+// Balance returns the balance of an account, or an error wrapping
+// apperr.ErrNotFound if there isn't one
+func (b *Bank) Balance(ctx context.Context, id int64) (int64, error) {
+	return balance(ctx, b.db, id)
+}
+
+// querier is what *sql.DB and *sql.Tx have in common, so that balance
+// can read inside a transaction or outside of one
+type querier interface {
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+func balance(ctx context.Context, q querier, id int64) (int64, error) {
+	var n int64
+	err := q.QueryRowContext(ctx, `SELECT balance FROM accounts WHERE id = ?`, id).Scan(&n)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, fmt.Errorf("account %d: %w", id, apperr.ErrNotFound)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("balance of %d: %w", id, err)
+	}
+	return n, nil
+}
+
+// withTx runs fn in a transaction. It commits if fn returns nil, and
+// rolls back if fn returns an error or panics.
+//
+// The deferred Rollback is the safety net: after a Commit it does
+// nothing and returns sql.ErrTxDone, which is ignored
+func withTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return fmt.Errorf("begin: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit: %w", err)
+	}
+	return nil
+}
+
+// Transfer moves amount cents from one account to another. Either both
+// accounts change or neither does
+func (b *Bank) Transfer(ctx context.Context, from, to, amount int64) error {
+	return withTx(ctx, b.db, nil, func(tx *sql.Tx) error {
+		return transfer(ctx, tx, from, to, amount)
+	})
+}
+
+// transfer does the work of Transfer inside tx. Any error it returns
+// undoes everything it did, including the debit
+func transfer(ctx context.Context, tx *sql.Tx, from, to, amount int64) error {
+	bal, err := balance(ctx, tx, from)
+	if err != nil {
+		return err
+	}
+	if bal < amount {
+		return fmt.Errorf("transfer %d from %d: %w", amount, from, ErrInsufficientFunds)
+	}
+	if _, err := tx.ExecContext(ctx,
+		`UPDATE accounts SET balance = balance - ? WHERE id = ?`, amount, from); err != nil {
+		return fmt.Errorf("debit %d: %w", from, err)
+	}
+	res, err := tx.ExecContext(ctx,
+		`UPDATE accounts SET balance = balance + ? WHERE id = ?`, amount, to)
+	if err != nil {
+		return fmt.Errorf("credit %d: %w", to, err)
+	}
+	if n, err := res.RowsAffected(); err != nil {
+		return fmt.Errorf("credit %d: %w", to, err)
+	} else if n == 0 {
+		return fmt.Errorf("account %d: %w", to, apperr.ErrNotFound)
+	}
+	return nil
+}
+
+// TransferRetry is Transfer, run again when it fails because another
+// transaction got in the way. Each attempt is a new transaction: the
+// balance is read again, so the retry decides with fresh data.
+//
+// It gives up after 5 seconds, not after a number of attempts: under
+// load, one attempt may wait the whole busy_timeout for the lock
+func (b *Bank) TransferRetry(ctx context.Context, from, to, amount int64, opts ...retry.Option) error {
+	opts = append([]retry.Option{
+		retry.WithMaxAttempts(0),
+		retry.WithMaxElapsed(5 * time.Second),
+		retry.WithBackoff(2*time.Millisecond, 100*time.Millisecond),
+		retry.WithJitter(retry.FullJitter),
+		retry.WithRetryIf(isBusy),
+	}, opts...)
+	return retry.Do(ctx, func(ctx context.Context) error {
+		return b.Transfer(ctx, from, to, amount)
+	}, opts...)
+}
+
+// isBusy reports whether err is SQLite's serialization failure: the
+// database was locked by another writer, or changed since this
+// transaction started reading (SQLITE_BUSY_SNAPSHOT). The low byte of
+// an extended code is its primary code
+func isBusy(err error) bool {
+	var e *sqlite.Error
+	return errors.As(err, &e) && e.Code()&0xff == sqlite3.SQLITE_BUSY
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+func main() {
+	fmt.Println("Transactions with database/sql")
+	fmt.Println("==============================")
+	fmt.Println()
+
+	dir, err := os.MkdirTemp("", "learngo-tx")
+	if err != nil {
+		fmt.Println("temp dir:", err)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	path := filepath.Join(dir, "bank.db")
+	b, err := openBank(ctx, path)
+	if err != nil {
+		fmt.Println("open:", err)
+		return
+	}
+	defer b.Close()
+
+	// Another pool on the same file, whose transactions begin deferred,
+	// to show what goes wrong when they read before they write
+	deferred, err := openDB(ctx, path, "deferred")
+	if err != nil {
+		fmt.Println("open:", err)
+		return
+	}
+	defer deferred.Close()
+
+	ada, err := b.Open(ctx, "Ada", 10000)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	grace, err := b.Open(ctx, "Grace", 5000)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	example1(ctx, b, ada, grace)
+	example2(ctx, b, deferred, ada)
+	example3(ctx, b, deferred, ada)
+	example4(ctx, b, deferred, ada, grace)
+	example5(ctx, b, ada, grace)
+}
+
+// Example 1: Commit and rollback
+func example1(ctx context.Context, b *Bank, ada, grace int64) {
+	fmt.Println("1. Commit and rollback:")
+	printBalances(ctx, b, ada, grace)
+
+	fmt.Println("   Transfer(Ada → Grace, 25.00):", b.Transfer(ctx, ada, grace, 2500))
+	printBalances(ctx, b, ada, grace)
+
+	// Both fail after the transaction started; the second one after it
+	// had already taken the money from Ada
+	fmt.Println("   Transfer(Grace → Ada, 1000.00):", b.Transfer(ctx, grace, ada, 100000))
+	fmt.Println("   Transfer(Ada → 42, 10.00):", b.Transfer(ctx, ada, 42, 1000))
+	printBalances(ctx, b, ada, grace)
+	fmt.Println()
+}
+
+// Example 2: Isolation options
+func example2(ctx context.Context, b *Bank, deferred *sql.DB, ada int64) {
+	fmt.Println("2. Isolation options:")
+	for _, opts := range []*sql.TxOptions{
+		nil,
+		{Isolation: sql.LevelReadCommitted},
+		{Isolation: sql.LevelSerializable},
+		{ReadOnly: true},
+	} {
+		tx, err := b.db.BeginTx(ctx, opts)
+		if err != nil {
+			fmt.Printf("   BeginTx(%s): %v\n", describe(opts), err)
+			continue
+		}
+		// SQLite ignores Isolation, and ReadOnly only makes the
+		// transaction begin deferred, so this UPDATE works in all four
+		_, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = balance WHERE id = ?`, ada)
+		tx.Rollback()
+		fmt.Printf("   BeginTx(%s): ok, UPDATE err %v\n", describe(opts), err)
+	}
+
+	// A transaction reads from a snapshot taken at its first read, so it
+	// doesn't see what commits after that. It's deferred: an immediate
+	// one would hold the write lock, and the other client would wait
+	err := withTx(ctx, deferred, nil, func(tx *sql.Tx) error {
+		before, err := balance(ctx, tx, ada)
+		if err != nil {
+			return err
+		}
+		if _, err := b.db.ExecContext(ctx,
+			`UPDATE accounts SET balance = balance + 100 WHERE id = ?`, ada); err != nil {
+			return err
+		}
+		after, err := balance(ctx, tx, ada)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("   inside the transaction: %s, then %s\n", cents(before), cents(after))
+		return nil
+	})
+	if err != nil {
+		fmt.Println("   error:", err)
+	}
+	bal, _ := b.Balance(ctx, ada)
+	fmt.Printf("   after it:               %s\n", cents(bal))
+	fmt.Println()
+}
+
+// Example 3: A serialization failure
+func example3(ctx context.Context, b *Bank, deferred *sql.DB, ada int64) {
+	fmt.Println("3. A serialization failure:")
+	err := withTx(ctx, deferred, nil, func(tx *sql.Tx) error {
+		bal, err := balance(ctx, tx, ada)
+		if err != nil {
+			return err
+		}
+		// Another client deposits, after this transaction's snapshot
+		if _, err := b.db.ExecContext(ctx,
+			`UPDATE accounts SET balance = balance + 100 WHERE id = ?`, ada); err != nil {
+			return err
+		}
+		// Writing bal-500 would lose the deposit; SQLite refuses
+		_, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = ? WHERE id = ?`, bal-500, ada)
+		return err
+	})
+	fmt.Println("   withdraw:", err)
+	fmt.Println("   isBusy:", isBusy(err))
+	bal, _ := b.Balance(ctx, ada)
+	fmt.Printf("   Ada: %s, with the deposit and without the withdrawal\n", cents(bal))
+	fmt.Println()
+}
+
+// Example 4: Retrying with pkg/retry
+func example4(ctx context.Context, b *Bank, deferred *sql.DB, ada, grace int64) {
+	fmt.Println("4. Retrying with pkg/retry:")
+	attempts := 0
+	err := retry.Do(ctx, func(ctx context.Context) error {
+		attempts++
+		return withTx(ctx, deferred, nil, func(tx *sql.Tx) error {
+			bal, err := balance(ctx, tx, ada)
+			if err != nil {
+				return err
+			}
+			if attempts == 1 {
+				if _, err := b.db.ExecContext(ctx,
+					`UPDATE accounts SET balance = balance + 100 WHERE id = ?`, ada); err != nil {
+					return err
+				}
+			}
+			_, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = ? WHERE id = ?`, bal-500, ada)
+			return err
+		})
+	},
+		retry.WithBackoff(time.Millisecond, 10*time.Millisecond),
+		retry.WithRetryIf(isBusy),
+		retry.WithOnRetry(func(attempt int, err error, _ time.Duration) {
+			fmt.Printf("   attempt %d: %v\n", attempt, err)
+		}),
+	)
+	fmt.Printf("   withdraw: %v after %d attempts\n", err, attempts)
+	bal, _ := b.Balance(ctx, ada)
+	fmt.Printf("   Ada: %s, with the deposit and the withdrawal\n", cents(bal))
+
+	// Transfers back and forth from 8 goroutines, in immediate
+	// transactions: they wait for each other instead of failing
+	total := func() int64 {
+		a, _ := b.Balance(ctx, ada)
+		g, _ := b.Balance(ctx, grace)
+		return a + g
+	}
+	before := total()
+
+	var retries, failed atomic.Int64
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			from, to := ada, grace
+			if i%2 == 1 {
+				from, to = grace, ada
+			}
+			for range 25 {
+				err := b.TransferRetry(ctx, from, to, 1,
+					retry.WithOnRetry(func(int, error, time.Duration) { retries.Add(1) }))
+				if err != nil {
+					failed.Add(1)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	fmt.Printf("   200 concurrent transfers: %d retries, %d failed, total %s before and %s after\n",
+		retries.Load(), failed.Load(), cents(before), cents(total()))
+	fmt.Println()
+}
+
+// Example 5: A transaction whose context times out
+func example5(ctx context.Context, b *Bank, ada, grace int64) {
+	fmt.Println("5. A transaction whose context times out:")
+	before, _ := b.Balance(ctx, ada)
+
+	tctx, cancel := context.WithTimeout(ctx, 50*time.Millisecond)
+	defer cancel()
+
+	tx, err := b.db.BeginTx(tctx, nil)
+	if err != nil {
+		fmt.Println("   error:", err)
+		return
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(tctx,
+		`UPDATE accounts SET balance = balance - 1000 WHERE id = ?`, ada)
+	fmt.Println("   debit before the deadline:", err)
+
+	// Something slow happens while the transaction is open
+	<-tctx.Done()
+	time.Sleep(10 * time.Millisecond)
+
+	_, err = tx.ExecContext(tctx,
+		`UPDATE accounts SET balance = balance + 1000 WHERE id = ?`, grace)
+	fmt.Println("   credit after the deadline:", err)
+	err = tx.Commit()
+	fmt.Println("   Commit:", err)
+	fmt.Println("   errors.Is(err, sql.ErrTxDone):", errors.Is(err, sql.ErrTxDone))
+
+	after, _ := b.Balance(ctx, ada)
+	fmt.Printf("   Ada: %s before, %s after\n", cents(before), cents(after))
+	fmt.Println("   connections in use:", b.db.Stats().InUse)
+}
+
+// printBalances prints the balances of two accounts
+func printBalances(ctx context.Context, b *Bank, ids ...int64) {
+	fmt.Print("  ")
+	for _, id := range ids {
+		bal, err := b.Balance(ctx, id)
+		if err != nil {
+			fmt.Print(" ", err)
+			continue
+		}
+		fmt.Printf(" %d: %s", id, cents(bal))
+	}
+	fmt.Println()
+}
+
+// cents formats an amount in cents as dollars
+func cents(n int64) string {
+	return fmt.Sprintf("%d.%02d", n/100, n%100)
+}
+
+// describe formats TxOptions for printing
+func describe(opts *sql.TxOptions) string {
+	switch {
+	case opts == nil:
+		return "nil"
+	case opts.ReadOnly:
+		return "ReadOnly"
+	default:
+		return opts.Isolation.String()
+	}
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/inancgumus/learngo/27-error-handling/06-http-errors/apperr"
+)
+
+// newTestBank opens a bank in a file of its own, removed after the test,
+// with two accounts holding 100.00 and 50.00
+func newTestBank(t *testing.T) (b *Bank, ada, grace int64) {
+	t.Helper()
+	return newTestBankAt(t, filepath.Join(t.TempDir(), "test.db"))
+}
+
+func newTestBankAt(t *testing.T, path string) (b *Bank, ada, grace int64) {
+	t.Helper()
+	b, err := openBank(t.Context(), path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { b.Close() })
+
+	if ada, err = b.Open(t.Context(), "Ada", 10000); err != nil {
+		t.Fatal(err)
+	}
+	if grace, err = b.Open(t.Context(), "Grace", 5000); err != nil {
+		t.Fatal(err)
+	}
+	return b, ada, grace
+}
+
+// wantBalances fails the test if the accounts don't hold want
+func wantBalances(t *testing.T, b *Bank, ids []int64, want ...int64) {
+	t.Helper()
+	for i, id := range ids {
+		got, err := b.Balance(t.Context(), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want[i] {
+			t.Errorf("balance of %d = %d, want %d", id, got, want[i])
+		}
+	}
+}
+
+func TestTransfer(t *testing.T) {
+	b, ada, grace := newTestBank(t)
+	if err := b.Transfer(t.Context(), ada, grace, 2500); err != nil {
+		t.Fatal(err)
+	}
+	wantBalances(t, b, []int64{ada, grace}, 7500, 7500)
+}
+
+func TestTransferRollsBack(t *testing.T) {
+	b, ada, grace := newTestBank(t)
+	tests := []struct {
+		name     string
+		from, to int64
+		amount   int64
+		want     error
+	}{
+		{"insufficient funds", grace, ada, 5001, ErrInsufficientFunds},
+		{"missing sender", 42, ada, 1, apperr.ErrNotFound},
+		// Ada is debited before the credit fails, and the rollback undoes it
+		{"missing receiver", ada, 42, 1000, apperr.ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := b.Transfer(t.Context(), tt.from, tt.to, tt.amount)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Transfer = %v, want %v", err, tt.want)
+			}
+			wantBalances(t, b, []int64{ada, grace}, 10000, 5000)
+		})
+	}
+}
+
+func TestWithTxRollsBackOnPanic(t *testing.T) {
+	b, ada, grace := newTestBank(t)
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("withTx didn't panic")
+			}
+		}()
+		withTx(t.Context(), b.db, nil, func(tx *sql.Tx) error {
+			if err := transfer(t.Context(), tx, ada, grace, 1000); err != nil {
+				t.Error(err)
+			}
+			panic("boom")
+		})
+	}()
+	wantBalances(t, b, []int64{ada, grace}, 10000, 5000)
+	if n := b.db.Stats().InUse; n != 0 {
+		t.Errorf("%d connections in use, want 0", n)
+	}
+}
+
+// conflict runs a deferred transaction that reads Ada's balance, lets
+// the bank change it, and then writes what it read
+func conflict(ctx context.Context, b *Bank, deferred *sql.DB, ada int64, interfere bool) error {
+	return withTx(ctx, deferred, nil, func(tx *sql.Tx) error {
+		bal, err := balance(ctx, tx, ada)
+		if err != nil {
+			return err
+		}
+		if interfere {
+			if _, err := b.db.ExecContext(ctx,
+				`UPDATE accounts SET balance = balance + 100 WHERE id = ?`, ada); err != nil {
+				return err
+			}
+		}
+		_, err = tx.ExecContext(ctx, `UPDATE accounts SET balance = ? WHERE id = ?`, bal-500, ada)
+		return err
+	})
+}
+
+func TestIsBusy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.db")
+	b, ada, grace := newTestBankAt(t, path)
+	deferred, err := openDB(t.Context(), path, "deferred")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deferred.Close()
+
+	err = conflict(t.Context(), b, deferred, ada, true)
+	if !isBusy(err) {
+		t.Errorf("isBusy(%v) = false, want true", err)
+	}
+	// The deposit stays, and the write that would have lost it doesn't
+	wantBalances(t, b, []int64{ada}, 10100)
+
+	for _, err := range []error{
+		nil,
+		b.Transfer(t.Context(), grace, ada, 5001),
+		sql.ErrTxDone,
+	} {
+		if isBusy(err) {
+			t.Errorf("isBusy(%v) = true, want false", err)
+		}
+	}
+}
+
+func TestConcurrentTransfers(t *testing.T) {
+	b, ada, grace := newTestBank(t)
+
+	var failed atomic.Int64
+	var wg sync.WaitGroup
+	for i := range 8 {
+		wg.Go(func() {
+			from, to := ada, grace
+			if i%2 == 1 {
+				from, to = grace, ada
+			}
+			for range 10 {
+				if err := b.TransferRetry(t.Context(), from, to, 100); err != nil {
+					failed.Add(1)
+					t.Error(err)
+				}
+			}
+		})
+	}
+	wg.Wait()
+	if n := failed.Load(); n > 0 {
+		t.Fatalf("%d transfers failed", n)
+	}
+	// As many transfers went each way
+	wantBalances(t, b, []int64{ada, grace}, 10000, 5000)
+}
+
+func TestTransferRetryStopsOnOtherErrors(t *testing.T) {
+	b, ada, grace := newTestBank(t)
+	start := time.Now()
+	err := b.TransferRetry(t.Context(), grace, ada, 5001)
+	if !errors.Is(err, ErrInsufficientFunds) {
+		t.Fatalf("TransferRetry = %v, want %v", err, ErrInsufficientFunds)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("TransferRetry took %v: it retried an error that won't go away", d)
+	}
+}
+
+// A deadline ends a transaction the way cancel does, through ctx.Done;
+// canceling by hand keeps the test from depending on how fast it runs
+func TestDoneContextRollsBack(t *testing.T) {
+	b, ada, grace := newTestBank(t)
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+
+	tx, err := b.db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tx.Rollback()
+	if err := transfer(ctx, tx, ada, grace, 1000); err != nil {
+		t.Fatal(err)
+	}
+	cancel()
+
+	// database/sql rolls back from another goroutine when the context
+	// is done; give it time to
+	deadline := time.Now().Add(time.Second)
+	for b.db.Stats().InUse != 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	if err := tx.Commit(); !errors.Is(err, sql.ErrTxDone) && !errors.Is(err, context.Canceled) {
+		t.Errorf("Commit = %v, want %v", err, sql.ErrTxDone)
+	}
+	wantBalances(t, b, []int64{ada, grace}, 10000, 5000)
+}
@@ -0,0 +1,133 @@
+// Command coverreport runs go test with coverage on packages, and prints
+// the coverage of each one, so that a section's lessons can be compared
+// at a glance:
+//
+//	go run ./cmd/coverreport ./36-testing/...
+//	go run ./cmd/coverreport -min 70 ./36-testing/... ./internal/...
+//	go run ./cmd/coverreport -profile cover.out
+//
+// With -profile, it reads a profile that go test -coverprofile wrote,
+// instead of running the tests. With -min, it exits with status 1 if a
+// package is covered less than that percentage.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/inancgumus/learngo/internal/coverage"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "coverreport:", err)
+		os.Exit(1)
+	}
+}
+
+// errBelowMin is returned when a package is below -min. The table has
+// said which, so main only adds a line
+var errBelowMin = errors.New("coverage below -min")
+
+// run is main, with errors to return instead of exits, so that every
+// defer runs
+func run(args []string, out io.Writer) error {
+	fs := flag.NewFlagSet("coverreport", flag.ContinueOnError)
+	profile := fs.String("profile", "", "read this coverage profile instead of running go test")
+	minPct := fs.Float64("min", 0, "fail if a package's coverage is below this percentage")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var testErr error
+	if *profile == "" {
+		dir, err := os.MkdirTemp("", "coverreport")
+		if err != nil {
+			return err
+		}
+		defer os.RemoveAll(dir)
+
+		*profile = filepath.Join(dir, "cover.out")
+		testErr = goTest(*profile, fs.Args())
+	}
+
+	f, err := os.Open(*profile)
+	if err != nil {
+		return errors.Join(testErr, err)
+	}
+	defer f.Close()
+
+	p, err := coverage.Parse(f)
+	if err != nil {
+		return errors.Join(testErr, err)
+	}
+	if !write(out, p, modulePath(), *minPct) {
+		return errors.Join(testErr, errBelowMin)
+	}
+	return testErr
+}
+
+// goTest runs the tests of pkgs, or of the current directory, and
+// writes their coverage profile to profile. The tests' output goes to
+// standard error, so that the table is all of standard output. A failed
+// test is an error, but the profile is written anyway
+func goTest(profile string, pkgs []string) error {
+	if len(pkgs) == 0 {
+		pkgs = []string{"."}
+	}
+	args := append([]string{"test", "-coverprofile", profile}, pkgs...)
+	cmd := exec.Command("go", args...)
+	cmd.Stdout = os.Stderr
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("go test: %w", err)
+	}
+	return nil
+}
+
+// modulePath returns the path of the current module, to cut from the
+// front of package paths. Outside a module, it returns ""
+func modulePath() string {
+	out, err := exec.Command("go", "list", "-m").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// write prints a table of the packages in p and their total, and
+// reports whether all of them are covered at least minPct
+func write(w io.Writer, p *coverage.Profile, module string, minPct float64) bool {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	ok := true
+	fmt.Fprintln(tw, "package\tstatements\tcovered\tcoverage")
+	for _, pkg := range p.Packages() {
+		name := strings.TrimPrefix(strings.TrimPrefix(pkg.Path, module), "/")
+		if name == "" {
+			name = "."
+		}
+		mark := ""
+		if pkg.Percent() < minPct {
+			mark, ok = "  below -min", false
+		}
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%5.1f%% %s%s\n", name, pkg.Statements, pkg.Covered, pkg.Percent(), bar(pkg.Percent()), mark)
+	}
+	t := p.Total()
+	fmt.Fprintf(tw, "total\t%d\t%d\t%5.1f%% %s\n", t.Statements, t.Covered, t.Percent(), bar(t.Percent()))
+	return ok
+}
+
+// bar draws pct as ten cells, each 10%
+func bar(pct float64) string {
+	n := int(pct / 10)
+	return strings.Repeat("█", n) + strings.Repeat("░", 10-n)
+}
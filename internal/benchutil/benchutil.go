@@ -0,0 +1,112 @@
+// Package benchutil formats benchmark results for lessons that print
+// them from main, and compares two runs of go test -bench.
+//
+// testing.Benchmark runs a benchmark outside go test:
+//
+//	results := []benchutil.Result{
+//		benchutil.Run("mutex", benchMutex),
+//		benchutil.Run("rwmutex", benchRWMutex),
+//	}
+//	benchutil.Write(os.Stdout, results)
+//
+// Parse reads what go test -bench prints, and Compare puts two runs side
+// by side, the way benchstat does, without its statistics.
+package benchutil
+
+import (
+	"fmt"
+	"io"
+	"testing"
+	"text/tabwriter"
+)
+
+// Result is a benchmark's name and its result.
+type Result struct {
+	Name string
+	testing.BenchmarkResult
+}
+
+// Run runs f with testing.Benchmark, which picks b.N the way go test
+// does. Call testing.Init first to make -test.benchtime settable.
+func Run(name string, f func(b *testing.B)) Result {
+	return Result{Name: name, BenchmarkResult: testing.Benchmark(f)}
+}
+
+// NsPerOp returns the time per operation. Unlike the embedded method, it
+// keeps the fraction of a nanosecond.
+func (r Result) NsPerOp() float64 {
+	if r.N == 0 {
+		return 0
+	}
+	return float64(r.T.Nanoseconds()) / float64(r.N)
+}
+
+// Write writes results as an aligned table, with the time per operation,
+// the throughput if the benchmark called b.SetBytes, and the memory
+// allocated per operation. Each time is also shown relative to the
+// first result.
+func Write(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "\ttime/op\tvs first\tMB/s\tB/op\tallocs/op\t")
+	for _, r := range results {
+		mbs := "-"
+		if r.Bytes > 0 && r.NsPerOp() > 0 {
+			mbs = fmt.Sprintf("%.1f", float64(r.Bytes)*1e3/r.NsPerOp())
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t\n",
+			r.Name, Duration(r.NsPerOp()), ratio(results[0].NsPerOp(), r.NsPerOp()), mbs,
+			Bytes(r.AllocedBytesPerOp()), r.AllocsPerOp())
+	}
+	return tw.Flush()
+}
+
+// ratio returns how many times slower b is than a, or faster
+func ratio(a, b float64) string {
+	switch {
+	case a == 0 || b == 0:
+		return "-"
+	case b >= a:
+		return fmt.Sprintf("%.2fx", b/a)
+	default:
+		return fmt.Sprintf("1/%.2fx", a/b)
+	}
+}
+
+// Duration formats a number of nanoseconds with three significant
+// digits, in the unit that suits it: 95.1ns, 1.23µs, 45.6ms.
+func Duration(ns float64) string {
+	switch {
+	case ns < 1e3:
+		return sig3(ns) + "ns"
+	case ns < 1e6:
+		return sig3(ns/1e3) + "µs"
+	case ns < 1e9:
+		return sig3(ns/1e6) + "ms"
+	default:
+		return sig3(ns/1e9) + "s"
+	}
+}
+
+// Bytes formats a number of bytes in B, KiB, MiB, or GiB.
+func Bytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	v, suffix := float64(n)/unit, "KiB"
+	for _, s := range []string{"MiB", "GiB"} {
+		if v < unit {
+			break
+		}
+		v, suffix = v/unit, s
+	}
+	return sig3(v) + " " + suffix
+}
+
+// sig3 formats v with three significant digits, but never as 1e+03
+func sig3(v float64) string {
+	if v >= 999.5 {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.3g", v)
+}
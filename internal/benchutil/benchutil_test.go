@@ -0,0 +1,165 @@
+package benchutil_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/inancgumus/learngo/internal/benchutil"
+)
+
+func TestDuration(t *testing.T) {
+	tests := []struct {
+		ns   float64
+		want string
+	}{
+		{0.2513, "0.251ns"},
+		{95.123, "95.1ns"},
+		{1234.5, "1.23µs"},
+		{45_600_000, "45.6ms"},
+		{999.7, "1000ns"},
+		{2.5e9, "2.5s"},
+	}
+	for _, tt := range tests {
+		if got := benchutil.Duration(tt.ns); got != tt.want {
+			t.Errorf("Duration(%v) = %q; want %q", tt.ns, got, tt.want)
+		}
+	}
+}
+
+func TestBytes(t *testing.T) {
+	tests := []struct {
+		n    int64
+		want string
+	}{
+		{0, "0 B"},
+		{1023, "1023 B"},
+		{1536, "1.5 KiB"},
+		{5 << 20, "5 MiB"},
+		{1000 << 10, "1000 KiB"},
+		{3 << 30, "3 GiB"},
+	}
+	for _, tt := range tests {
+		if got := benchutil.Bytes(tt.n); got != tt.want {
+			t.Errorf("Bytes(%d) = %q; want %q", tt.n, got, tt.want)
+		}
+	}
+}
+
+func TestWrite(t *testing.T) {
+	results := []benchutil.Result{
+		{Name: "fast", BenchmarkResult: testing.BenchmarkResult{N: 1000, T: 100 * time.Microsecond}},
+		{Name: "slow", BenchmarkResult: testing.BenchmarkResult{N: 1000, T: 250 * time.Microsecond, Bytes: 1000, MemAllocs: 3000, MemBytes: 2048000}},
+	}
+	var sb strings.Builder
+	if err := benchutil.Write(&sb, results); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimRight(sb.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines; want 3:\n%s", len(lines), sb.String())
+	}
+	for i, fields := range [][]string{
+		{"time/op", "vs", "first", "MB/s", "B/op", "allocs/op"},
+		{"fast", "100ns", "1.00x", "-", "0", "B", "0"},
+		{"slow", "250ns", "2.50x", "4000.0", "2", "KiB", "3"},
+	} {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(fields, " ") {
+			t.Errorf("line %d = %q; want the fields %q", i, lines[i], fields)
+		}
+	}
+}
+
+const output = `goos: linux
+goarch: amd64
+pkg: example
+cpu: Some CPU @ 2.00GHz
+BenchmarkDecode/size=10-8         	  200000	      5000 ns/op	  20.00 MB/s	    4096 B/op	      12 allocs/op
+BenchmarkDecode/size=10-8         	  200000	      6000 ns/op	  16.67 MB/s	    4096 B/op	      12 allocs/op
+BenchmarkDecode/size=10-8         	  200000	      7000 ns/op	  14.29 MB/s	    4096 B/op	      12 allocs/op
+BenchmarkHash-8                   	1000000000	         0.2500 ns/op
+BenchmarkCustom-8                 	    1000	   1000000 ns/op	         3.000 hits/op
+PASS
+ok  	example	12.345s
+`
+
+func TestParse(t *testing.T) {
+	results, err := benchutil.Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 5 {
+		t.Fatalf("got %d results; want 5", len(results))
+	}
+
+	r := results[0]
+	if r.Name != "BenchmarkDecode/size=10" || r.N != 200000 {
+		t.Errorf("got %s with N=%d; want BenchmarkDecode/size=10 with N=200000", r.Name, r.N)
+	}
+	if r.NsPerOp() != 5000 || r.Bytes != 100 || r.AllocedBytesPerOp() != 4096 || r.AllocsPerOp() != 12 {
+		t.Errorf("got %v ns/op, %d bytes per op, %d B/op, %d allocs/op; want 5000, 100, 4096, 12",
+			r.NsPerOp(), r.Bytes, r.AllocedBytesPerOp(), r.AllocsPerOp())
+	}
+	if got := results[3].NsPerOp(); got != 0.25 {
+		t.Errorf("BenchmarkHash: got %v ns/op; want 0.25", got)
+	}
+	if got := results[4].Extra["hits/op"]; got != 3 {
+		t.Errorf("BenchmarkCustom: got %v hits/op; want 3", got)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	for _, line := range []string{
+		"BenchmarkX-8 many 100 ns/op",
+		"BenchmarkX-8 100 fast ns/op",
+		"BenchmarkX-8 100 100",
+	} {
+		if _, err := benchutil.Parse(strings.NewReader(line)); err == nil {
+			t.Errorf("Parse(%q): want an error", line)
+		}
+	}
+}
+
+func TestParseKeepsNamesWithoutProcs(t *testing.T) {
+	results, err := benchutil.Parse(strings.NewReader("BenchmarkSort/n=1-k 10 5 ns/op\nBenchmarkPlain 10 5 ns/op\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if results[0].Name != "BenchmarkSort/n=1-k" || results[1].Name != "BenchmarkPlain" {
+		t.Errorf("got %q and %q", results[0].Name, results[1].Name)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	old, err := benchutil.Parse(strings.NewReader(output))
+	if err != nil {
+		t.Fatal(err)
+	}
+	new, err := benchutil.Parse(strings.NewReader(`
+BenchmarkDecode/size=10-4  200000  3000 ns/op
+BenchmarkDecode/size=10-4  200000  2000 ns/op
+BenchmarkNew-4             100     10 ns/op
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sb strings.Builder
+	if err := benchutil.Compare(&sb, old, new); err != nil {
+		t.Fatal(err)
+	}
+	// Only the benchmark in both runs: the median of 5000, 6000, and
+	// 7000 against the median of 2000 and 3000
+	want := [][]string{
+		{"old", "time/op", "new", "time/op", "delta", "runs"},
+		{"BenchmarkDecode/size=10", "6µs", "2.5µs", "-58.3%", "3+2"},
+	}
+	lines := strings.Split(strings.TrimRight(sb.String(), "\n"), "\n")
+	if len(lines) != len(want) {
+		t.Fatalf("got:\n%s", sb.String())
+	}
+	for i := range want {
+		if got := strings.Fields(lines[i]); strings.Join(got, " ") != strings.Join(want[i], " ") {
+			t.Errorf("line %d = %q; want the fields %q", i, lines[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package benchutil
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Parse reads the output of go test -bench and returns a Result for
+// every benchmark line, in order. Other lines, like the goos: and PASS
+// lines, are skipped. The -8 that go test adds for GOMAXPROCS is removed
+// from each name, so runs on different machines line up.
+func Parse(r io.Reader) ([]Result, error) {
+	var results []Result
+	sc := bufio.NewScanner(r)
+	for n := 1; sc.Scan(); n++ {
+		line := sc.Text()
+		if !strings.HasPrefix(line, "Benchmark") {
+			continue
+		}
+		res, err := parseLine(line)
+		if err != nil {
+			return nil, fmt.Errorf("benchutil: line %d: %w", n, err)
+		}
+		results = append(results, res)
+	}
+	return results, sc.Err()
+}
+
+// parseLine parses a line like
+//
+//	BenchmarkDecode/size=100-8   12345   95123 ns/op   52.5 MB/s   4096 B/op   12 allocs/op
+func parseLine(line string) (Result, error) {
+	fields := strings.Fields(line)
+	if len(fields) < 4 || len(fields)%2 != 0 {
+		return Result{}, fmt.Errorf("want a name, a count, and value-unit pairs: %q", line)
+	}
+	var r Result
+	r.Name = trimProcs(fields[0])
+	var err error
+	if r.N, err = strconv.Atoi(fields[1]); err != nil {
+		return Result{}, fmt.Errorf("iterations: %w", err)
+	}
+
+	var nsPerOp, mbPerSec float64
+	for i := 2; i < len(fields); i += 2 {
+		v, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return Result{}, fmt.Errorf("%s: %w", fields[i+1], err)
+		}
+		switch unit := fields[i+1]; unit {
+		case "ns/op":
+			nsPerOp = v
+			r.T = time.Duration(v * float64(r.N))
+		case "MB/s":
+			mbPerSec = v
+		case "B/op":
+			r.MemBytes = uint64(v) * uint64(r.N)
+		case "allocs/op":
+			r.MemAllocs = uint64(v) * uint64(r.N)
+		default:
+			if r.Extra == nil {
+				r.Extra = make(map[string]float64)
+			}
+			r.Extra[unit] = v // a metric from b.ReportMetric
+		}
+	}
+	// go test prints MB/s, but a result keeps the bytes per op from
+	// b.SetBytes: MB/s is bytes per op over ns per op, times 1e3
+	r.Bytes = int64(mbPerSec*nsPerOp/1e3 + 0.5)
+	return r, nil
+}
+
+// trimProcs removes the -N suffix go test adds for GOMAXPROCS
+func trimProcs(name string) string {
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return name
+	}
+	if _, err := strconv.Atoi(name[i+1:]); err != nil {
+		return name
+	}
+	return name[:i]
+}
+
+// Compare writes a table of the benchmarks in both old and new, with
+// the median time per operation from each, and the change. Run each
+// side with -count 6 or more: one run of a benchmark is noise.
+//
+// Compare shows medians and nothing else. benchstat also reports how
+// much the runs varied, and whether a change is bigger than that
+// variation; use it before believing a few percent.
+func Compare(w io.Writer, old, new []Result) error {
+	olds, news := group(old), group(new)
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "\told time/op\tnew time/op\tdelta\truns\t")
+	for _, name := range names(old) {
+		o, ok := olds[name]
+		n, ok2 := news[name]
+		if !ok || !ok2 {
+			continue
+		}
+		mo, mn := median(o), median(n)
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%+.1f%%\t%d+%d\t\n",
+			name, Duration(mo), Duration(mn), (mn-mo)/mo*100, len(o), len(n))
+	}
+	return tw.Flush()
+}
+
+// group collects the time per operation of every run of each name
+func group(results []Result) map[string][]float64 {
+	m := make(map[string][]float64)
+	for _, r := range results {
+		m[r.Name] = append(m[r.Name], r.NsPerOp())
+	}
+	return m
+}
+
+// names returns each name once, in the order it first appears
+func names(results []Result) []string {
+	var out []string
+	for _, r := range results {
+		if !slices.Contains(out, r.Name) {
+			out = append(out, r.Name)
+		}
+	}
+	return out
+}
+
+func median(v []float64) float64 {
+	s := slices.Sorted(slices.Values(v))
+	if len(s)%2 == 1 {
+		return s[len(s)/2]
+	}
+	return (s[len(s)/2-1] + s[len(s)/2]) / 2
+}
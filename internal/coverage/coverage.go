@@ -0,0 +1,149 @@
+// Package coverage reads the profiles that go test -coverprofile writes,
+// and sums them up by package.
+//
+// A profile starts with the mode, then has one line per block of code:
+//
+//	mode: set
+//	github.com/inancgumus/learngo/36-testing/01-table-driven-tests/funcs.go:5.53,7.22 2 1
+//
+// That's the file, the block's start and end as line.column, its number
+// of statements, and how many times it ran; in set mode, 0 or 1.
+package coverage
+
+import (
+	"bufio"
+	"cmp"
+	"fmt"
+	"io"
+	"path"
+	"slices"
+	"strings"
+)
+
+// Block is one block of code in a profile.
+type Block struct {
+	File                string // the import path of the package, and the file name
+	StartLine, StartCol int
+	EndLine, EndCol     int
+	NumStmt             int
+	Count               int
+}
+
+// Profile is a parsed coverage profile.
+type Profile struct {
+	Mode   string // set, count, or atomic
+	Blocks []Block
+}
+
+// Parse reads a profile. A block can be in it more than once, when go
+// test ran several test binaries that include the same package, as with
+// -coverpkg. Parse merges those: in set mode, a block ran if any run
+// ran it, and in the other modes the counts add up.
+func Parse(r io.Reader) (*Profile, error) {
+	sc := bufio.NewScanner(r)
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("coverage: empty profile")
+	}
+	mode, ok := strings.CutPrefix(sc.Text(), "mode: ")
+	if !ok {
+		return nil, fmt.Errorf("coverage: line 1: want mode: ..., got %q", sc.Text())
+	}
+
+	p := &Profile{Mode: mode}
+	seen := make(map[Block]int) // a block, without its count, to its index in p.Blocks
+	for n := 2; sc.Scan(); n++ {
+		line := sc.Text()
+		if line == "" {
+			continue
+		}
+		b, err := parseBlock(line)
+		if err != nil {
+			return nil, fmt.Errorf("coverage: line %d: %w", n, err)
+		}
+
+		key := b
+		key.Count = 0
+		i, dup := seen[key]
+		switch {
+		case !dup:
+			seen[key] = len(p.Blocks)
+			p.Blocks = append(p.Blocks, b)
+		case mode == "set":
+			p.Blocks[i].Count = max(p.Blocks[i].Count, b.Count)
+		default:
+			p.Blocks[i].Count += b.Count
+		}
+	}
+	return p, sc.Err()
+}
+
+// parseBlock parses file:line.col,line.col statements count.
+func parseBlock(line string) (Block, error) {
+	var b Block
+	i := strings.LastIndexByte(line, ':')
+	if i < 0 {
+		return b, fmt.Errorf("no file name in %q", line)
+	}
+	b.File = line[:i]
+	_, err := fmt.Sscanf(line[i+1:], "%d.%d,%d.%d %d %d",
+		&b.StartLine, &b.StartCol, &b.EndLine, &b.EndCol, &b.NumStmt, &b.Count)
+	if err != nil {
+		return b, fmt.Errorf("bad block %q: %v", line[i+1:], err)
+	}
+	return b, nil
+}
+
+// Package is the coverage of one package.
+type Package struct {
+	Path       string // the import path
+	Statements int
+	Covered    int // statements in blocks that ran
+}
+
+// Percent returns the covered statements as a percentage. A package
+// with no statements is fully covered.
+func (p Package) Percent() float64 {
+	if p.Statements == 0 {
+		return 100
+	}
+	return 100 * float64(p.Covered) / float64(p.Statements)
+}
+
+// Packages sums up the blocks of p by package, ordered by import path.
+func (p *Profile) Packages() []Package {
+	byPath := make(map[string]*Package)
+	for _, b := range p.Blocks {
+		dir := path.Dir(b.File)
+		pkg := byPath[dir]
+		if pkg == nil {
+			pkg = &Package{Path: dir}
+			byPath[dir] = pkg
+		}
+		pkg.Statements += b.NumStmt
+		if b.Count > 0 {
+			pkg.Covered += b.NumStmt
+		}
+	}
+
+	pkgs := make([]Package, 0, len(byPath))
+	for _, pkg := range byPath {
+		pkgs = append(pkgs, *pkg)
+	}
+	slices.SortFunc(pkgs, func(a, b Package) int { return cmp.Compare(a.Path, b.Path) })
+	return pkgs
+}
+
+// Total sums up all the blocks of p, as one package with no path.
+func (p *Profile) Total() Package {
+	var t Package
+	for _, b := range p.Blocks {
+		t.Statements += b.NumStmt
+		if b.Count > 0 {
+			t.Covered += b.NumStmt
+		}
+	}
+	return t
+}
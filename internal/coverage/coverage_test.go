@@ -0,0 +1,108 @@
+package coverage_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/coverage"
+)
+
+const profile = `mode: set
+example.com/m/a/a.go:3.20,5.2 2 1
+example.com/m/a/a.go:7.20,9.2 2 0
+example.com/m/a/b.go:3.20,4.10 1 1
+example.com/m/a/b.go:4.10,6.3 3 0
+example.com/m/b/b.go:3.20,5.2 4 0
+`
+
+func TestPackages(t *testing.T) {
+	p, err := coverage.Parse(strings.NewReader(profile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Mode != "set" || len(p.Blocks) != 5 {
+		t.Fatalf("got mode %q and %d blocks; want set and 5", p.Mode, len(p.Blocks))
+	}
+
+	want := []coverage.Package{
+		{Path: "example.com/m/a", Statements: 8, Covered: 3},
+		{Path: "example.com/m/b", Statements: 4, Covered: 0},
+	}
+	got := p.Packages()
+	if len(got) != len(want) {
+		t.Fatalf("got %+v; want %+v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("package %d = %+v; want %+v", i, got[i], want[i])
+		}
+	}
+	if total := p.Total(); total.Statements != 12 || total.Covered != 3 {
+		t.Errorf("total = %+v; want 3 of 12 statements", total)
+	}
+}
+
+func TestParseMerges(t *testing.T) {
+	tests := []struct {
+		mode  string
+		count string // in the second of three runs; the others didn't run it
+		want  int
+	}{
+		{"set", "1", 1},
+		{"count", "5", 5},
+		{"atomic", "5", 5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			in := "mode: " + tt.mode + "\n" +
+				"example.com/m/a/a.go:3.20,5.2 2 0\n" +
+				"example.com/m/a/a.go:3.20,5.2 2 " + tt.count + "\n" +
+				"example.com/m/a/a.go:3.20,5.2 2 0\n"
+			p, err := coverage.Parse(strings.NewReader(in))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(p.Blocks) != 1 || p.Blocks[0].Count != tt.want {
+				t.Errorf("got %+v; want one block with count %d", p.Blocks, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"empty", "", "empty profile"},
+		{"no mode", "example.com/m/a/a.go:3.20,5.2 2 1\n", "line 1"},
+		{"no file", "mode: set\n3.20,5.2 2 1\n", "line 2"},
+		{"bad block", "mode: set\nexample.com/m/a/a.go:3.20-5.2 2 1\n", "line 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := coverage.Parse(strings.NewReader(tt.in))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v; want one about %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPercent(t *testing.T) {
+	tests := []struct {
+		pkg  coverage.Package
+		want float64
+	}{
+		{coverage.Package{Statements: 8, Covered: 2}, 25},
+		{coverage.Package{Statements: 3, Covered: 3}, 100},
+		{coverage.Package{Statements: 4, Covered: 0}, 0},
+		{coverage.Package{}, 100},
+	}
+	for _, tt := range tests {
+		if got := tt.pkg.Percent(); got != tt.want {
+			t.Errorf("%+v: got %v; want %v", tt.pkg, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package golden
+
+import (
+	"fmt"
+	"strings"
+)
+
+// context is how many unchanged lines Diff shows around a change
+const context = 2
+
+// Diff returns a line diff of want and got: lines only in want start
+// with "-", lines only in got with "+", and unchanged lines near a
+// change with a space. Lines far from any change are left out, and
+// each group of lines starts with its line numbers in want and got.
+func Diff(want, got []byte) string {
+	a := strings.SplitAfter(string(want), "\n")
+	b := strings.SplitAfter(string(got), "\n")
+	edits := diffLines(a, b)
+
+	var sb strings.Builder
+	last := -2 // the index of the last edit written
+	for i, e := range edits {
+		if e.op == ' ' && !near(edits, i) {
+			continue
+		}
+		if last != i-1 {
+			fmt.Fprintf(&sb, "@@ want line %d, got line %d @@\n", e.a+1, e.b+1)
+		}
+		line := e.line
+		if !strings.HasSuffix(line, "\n") {
+			line += "\n\\ no newline at the end\n"
+		}
+		sb.WriteByte(e.op)
+		sb.WriteString(line)
+		last = i
+	}
+	return sb.String()
+}
+
+// near reports whether an edit within context of i is a change
+func near(edits []edit, i int) bool {
+	for j := max(0, i-context); j <= min(len(edits)-1, i+context); j++ {
+		if edits[j].op != ' ' {
+			return true
+		}
+	}
+	return false
+}
+
+type edit struct {
+	op   byte // ' ', '-', or '+'
+	line string
+	a, b int // the line's index in want and got, or where it would be
+}
+
+// diffLines finds the longest common subsequence of a and b, and returns
+// the edits that turn a into b. It takes time and memory in proportion
+// to len(a)*len(b), which is fine for golden files
+func diffLines(a, b []string) []edit {
+	if len(a) > 0 && a[len(a)-1] == "" {
+		a = a[:len(a)-1]
+	}
+	if len(b) > 0 && b[len(b)-1] == "" {
+		b = b[:len(b)-1]
+	}
+
+	// lcs[i][j] is the length of the longest common subsequence of
+	// a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j], i, j})
+			j++
+		}
+	}
+	return edits
+}
@@ -0,0 +1,57 @@
+// Package golden compares a test's output with a file checked in under
+// testdata, a golden file. When the output changes on purpose, run the
+// tests with -update to rewrite the files, and review the change in the
+// diff:
+//
+//	go test -update
+//	git diff testdata/
+//
+// Output with parts that change on every run, like timestamps, is
+// normalized before it's compared or written:
+//
+//	golden.Assert(t, "list.json", body, golden.IndentJSON, golden.Timestamps)
+package golden
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata with the output of the tests")
+
+// Assert compares got with the file testdata/name, after applying each
+// normalizer to got in order. If they differ, the test fails with a
+// diff. With -update, Assert writes got to the file instead, creating
+// directories as needed.
+func Assert(t testing.TB, name string, got []byte, normalize ...Normalizer) {
+	t.Helper()
+	for _, n := range normalize {
+		got = n(got)
+	}
+
+	path := filepath.Join("testdata", name)
+	if *update {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v; run go test -update to create it", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("%s changed; if that's on purpose, run go test -update\n%s", path, Diff(want, got))
+	}
+}
+
+// Updating reports whether the tests run with -update. A test can use
+// it to skip checks that would fail while the files are rewritten.
+func Updating() bool { return *update }
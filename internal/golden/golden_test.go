@@ -0,0 +1,147 @@
+package golden_test
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/internal/golden"
+)
+
+// recorder is a testing.TB that keeps the errors instead of failing
+type recorder struct {
+	testing.TB
+	errors []string
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+// Fatalf stops the goroutine, like the real one: call the code under
+// test with run
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	runtime.Goexit()
+}
+
+// run calls f on its own goroutine, so that Fatalf can stop it
+func run(f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+	<-done
+}
+
+// setUpdate turns -update on for the rest of the test
+func setUpdate(t *testing.T) {
+	t.Helper()
+	if err := flag.Set("update", "true"); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { flag.Set("update", "false") })
+}
+
+func TestAssert(t *testing.T) {
+	t.Chdir(t.TempDir())
+	os.Mkdir("testdata", 0o755)
+	os.WriteFile(filepath.Join("testdata", "out.txt"), []byte("one\ntwo\n"), 0o644)
+
+	r := &recorder{TB: t}
+	golden.Assert(r, "out.txt", []byte("one\ntwo\n"))
+	if len(r.errors) != 0 {
+		t.Errorf("same output: got errors %q", r.errors)
+	}
+
+	golden.Assert(r, "out.txt", []byte("one\n2\n"))
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "-two\n+2\n") || !strings.Contains(r.errors[0], "go test -update") {
+		t.Errorf("changed output: got errors %q; want a diff and a hint", r.errors)
+	}
+}
+
+func TestAssertMissingFile(t *testing.T) {
+	t.Chdir(t.TempDir())
+	r := &recorder{TB: t}
+	run(func() { golden.Assert(r, "missing.txt", []byte("x")) })
+	if len(r.errors) != 1 || !strings.Contains(r.errors[0], "run go test -update to create it") {
+		t.Errorf("got errors %q", r.errors)
+	}
+}
+
+func TestAssertUpdate(t *testing.T) {
+	t.Chdir(t.TempDir())
+	setUpdate(t)
+	if !golden.Updating() {
+		t.Fatal("Updating() = false with -update")
+	}
+
+	golden.Assert(t, filepath.Join("pages", "home.html"), []byte("at 2025-03-14T09:26:53Z\n"), golden.Timestamps)
+	got, err := os.ReadFile(filepath.Join("testdata", "pages", "home.html"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != "at <timestamp>\n" {
+		t.Errorf("wrote %q; want the normalized output", got)
+	}
+}
+
+func TestNormalizers(t *testing.T) {
+	tests := []struct {
+		name string
+		norm golden.Normalizer
+		in   string
+		want string
+	}{
+		{"utc", golden.Timestamps, `"created_at":"2025-03-14T09:26:53Z"`, `"created_at":"<timestamp>"`},
+		{"fraction", golden.Timestamps, `2025-03-14T09:26:53.589793Z`, `<timestamp>`},
+		{"offset", golden.Timestamps, `at 2025-03-14T09:26:53+01:00.`, `at <timestamp>.`},
+		{"date only", golden.Timestamps, `2025-03-14`, `2025-03-14`},
+		{"replace", golden.Replace(`id=\d+`, "id=N"), "id=12 and id=345", "id=N and id=N"},
+		{"replace groups", golden.Replace(`took (\d+)ms`, "took ${1}?ms"), "took 12ms", "took 12?ms"},
+		{"indent", golden.IndentJSON, `{"a":[1,2],"b":{}}`, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}\n"},
+		{"not JSON", golden.IndentJSON, `{"a":`, `{"a":`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.norm([]byte(tt.in))); got != tt.want {
+				t.Errorf("got %q; want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	want := "1\n2\n3\n4\n5\n6\n7\n8\n9\n"
+	got := "1\n2\n3\nfour\n5\n6\n7\n8\n9\n10\n"
+	d := golden.Diff([]byte(want), []byte(got))
+	wantDiff := `@@ want line 2, got line 2 @@
+ 2
+ 3
+-4
++four
+ 5
+ 6
+@@ want line 8, got line 8 @@
+ 8
+ 9
++10
+`
+	if d != wantDiff {
+		t.Errorf("got:\n%s\nwant:\n%s", d, wantDiff)
+	}
+}
+
+func TestDiffNoNewline(t *testing.T) {
+	d := golden.Diff([]byte("a\n"), []byte("a"))
+	if !strings.Contains(d, "-a\n") || !strings.Contains(d, "+a\n\\ no newline at the end\n") {
+		t.Errorf("got:\n%s", d)
+	}
+}
@@ -0,0 +1,38 @@
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"regexp"
+)
+
+// A Normalizer rewrites output before it's compared: it replaces what
+// changes from run to run, like times and generated IDs, or reformats
+// it to make diffs easier to read.
+type Normalizer func([]byte) []byte
+
+// Replace returns a Normalizer that replaces every match of the regular
+// expression pattern with repl, which can refer to groups as $1.
+func Replace(pattern, repl string) Normalizer {
+	re := regexp.MustCompile(pattern)
+	return func(b []byte) []byte {
+		return re.ReplaceAll(b, []byte(repl))
+	}
+}
+
+// Timestamps replaces RFC 3339 times, like 2025-03-14T09:26:53.589Z, with
+// <timestamp>.
+var Timestamps = Replace(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`, "<timestamp>")
+
+// IndentJSON indents JSON two spaces per level and ends it with a
+// newline, so a change to one field is a change to one line of the
+// file. Output that isn't valid JSON is left as it is: the comparison
+// then shows it.
+func IndentJSON(b []byte) []byte {
+	var out bytes.Buffer
+	if err := json.Indent(&out, b, "", "  "); err != nil {
+		return b
+	}
+	out.WriteByte('\n')
+	return out.Bytes()
+}
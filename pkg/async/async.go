@@ -0,0 +1,149 @@
+// Package async models promise-style workflows with generics and
+// goroutines.
+//
+// A Future is the result of a function running in its own goroutine.
+// It can be awaited many times, by many goroutines, and combined with
+// All, Any, and Race:
+//
+//	user := async.Go(func() (User, error) { return fetchUser(id) })
+//	orders := async.Go(func() ([]Order, error) { return fetchOrders(id) })
+//
+//	u, err := user.Await(ctx)
+//	o, err := orders.Await(ctx)
+//
+// Compare this with the channel-based versions in 29-concurrency: a
+// future is a channel that is written exactly once and closed, plus a
+// place to keep the value so every reader sees it.
+package async
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrNoFutures is returned by Any and Race when called with no futures.
+var ErrNoFutures = errors.New("async: no futures given")
+
+// Future holds the eventual result of an asynchronous computation.
+type Future[T any] struct {
+	done chan struct{}
+	val  T
+	err  error
+}
+
+// Go runs fn in a new goroutine and returns a future for its result.
+// A panic in fn is recovered and reported as the future's error.
+func Go[T any](fn func() (T, error)) *Future[T] {
+	f := &Future[T]{done: make(chan struct{})}
+
+	go func() {
+		// Closing done publishes val and err to every waiter: the
+		// close happens after the writes, and receives from a closed
+		// channel happen after the close
+		defer close(f.done)
+		defer func() {
+			if r := recover(); r != nil {
+				f.err = fmt.Errorf("async: panic: %v", r)
+			}
+		}()
+
+		f.val, f.err = fn()
+	}()
+
+	return f
+}
+
+// Done returns a channel that is closed when the future has settled.
+func (f *Future[T]) Done() <-chan struct{} {
+	return f.done
+}
+
+// Await waits for the result or for ctx to be done, whichever comes
+// first. Giving up does not stop the underlying goroutine; it only
+// stops waiting for it.
+func (f *Future[T]) Await(ctx context.Context) (T, error) {
+	select {
+	case <-f.done:
+		return f.val, f.err
+	case <-ctx.Done():
+		var zero T
+		return zero, ctx.Err()
+	}
+}
+
+// settled is the outcome of one future, tagged with its position.
+type settled[T any] struct {
+	index int
+	val   T
+	err   error
+}
+
+// watch sends the outcome of every future on a channel as they settle.
+// The channel is buffered, so the watcher goroutines never block and
+// never leak, even if nobody reads all the outcomes.
+func watch[T any](fs []*Future[T]) <-chan settled[T] {
+	out := make(chan settled[T], len(fs))
+	for i, f := range fs {
+		go func() {
+			<-f.done
+			out <- settled[T]{index: i, val: f.val, err: f.err}
+		}()
+	}
+	return out
+}
+
+// All settles with every value, in the order of fs, once all futures
+// have succeeded. It fails as soon as any future fails.
+func All[T any](fs ...*Future[T]) *Future[[]T] {
+	return Go(func() ([]T, error) {
+		vals := make([]T, len(fs))
+		outcomes := watch(fs)
+
+		for range fs {
+			s := <-outcomes
+			if s.err != nil {
+				return nil, s.err
+			}
+			vals[s.index] = s.val
+		}
+		return vals, nil
+	})
+}
+
+// Any settles with the first value to succeed. If every future fails,
+// it fails with all of their errors joined.
+func Any[T any](fs ...*Future[T]) *Future[T] {
+	return Go(func() (T, error) {
+		var zero T
+		if len(fs) == 0 {
+			return zero, ErrNoFutures
+		}
+
+		errs := make([]error, len(fs))
+		outcomes := watch(fs)
+
+		for range fs {
+			s := <-outcomes
+			if s.err == nil {
+				return s.val, nil
+			}
+			errs[s.index] = s.err
+		}
+		return zero, errors.Join(errs...)
+	})
+}
+
+// Race settles like the first future to settle, whether it succeeded
+// or failed.
+func Race[T any](fs ...*Future[T]) *Future[T] {
+	return Go(func() (T, error) {
+		if len(fs) == 0 {
+			var zero T
+			return zero, ErrNoFutures
+		}
+
+		s := <-watch(fs)
+		return s.val, s.err
+	})
+}
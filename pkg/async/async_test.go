@@ -0,0 +1,194 @@
+package async_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/async"
+)
+
+// after returns a future that settles with v and err after d.
+//
+// Futures cannot be cancelled, so the bubble waits for every one of
+// them before the test returns, or synctest reports a deadlock.
+func after[T any](t *testing.T, d time.Duration, v T, err error) *async.Future[T] {
+	f := async.Go(func() (T, error) {
+		time.Sleep(d)
+		return v, err
+	})
+	t.Cleanup(func() { <-f.Done() })
+	return f
+}
+
+var errBoom = errors.New("boom")
+
+func TestAwait(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		f := after(t, time.Second, 42, nil)
+
+		got, err := f.Await(context.Background())
+		if err != nil || got != 42 {
+			t.Errorf("want 42, nil; got %d, %v", got, err)
+		}
+	})
+}
+
+func TestAwaitManyTimesFromManyGoroutines(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		f := after(t, time.Second, "done", nil)
+
+		var wg sync.WaitGroup
+		for range 5 {
+			wg.Go(func() {
+				if got, _ := f.Await(context.Background()); got != "done" {
+					t.Errorf("want %q; got %q", "done", got)
+				}
+			})
+		}
+		wg.Wait()
+	})
+}
+
+func TestAwaitContextTimeout(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		f := after(t, time.Hour, 1, nil)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if _, err := f.Await(ctx); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want DeadlineExceeded; got %v", err)
+		}
+
+		// The future itself is unaffected and can still be awaited
+		if got, _ := f.Await(context.Background()); got != 1 {
+			t.Errorf("want 1; got %d", got)
+		}
+	})
+}
+
+func TestPanicBecomesError(t *testing.T) {
+	f := async.Go(func() (int, error) { panic("oops") })
+
+	_, err := f.Await(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "oops") {
+		t.Errorf("want panic error; got %v", err)
+	}
+}
+
+func TestAll(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+
+		got, err := async.All(
+			after(t, 3*time.Second, 1, nil),
+			after(t, 1*time.Second, 2, nil),
+			after(t, 2*time.Second, 3, nil),
+		).Await(context.Background())
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Input order, not completion order
+		if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+			t.Errorf("want %v; got %v", want, got)
+		}
+		// Concurrent: as slow as the slowest, not the sum
+		if elapsed := time.Since(start); elapsed != 3*time.Second {
+			t.Errorf("want 3s; got %v", elapsed)
+		}
+	})
+}
+
+func TestAllFailsFast(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+
+		_, err := async.All(
+			after(t, time.Hour, 1, nil),
+			after(t, time.Second, 0, errBoom),
+		).Await(context.Background())
+
+		if !errors.Is(err, errBoom) {
+			t.Errorf("want errBoom; got %v", err)
+		}
+		if elapsed := time.Since(start); elapsed != time.Second {
+			t.Errorf("want failure after 1s; got %v", elapsed)
+		}
+	})
+}
+
+func TestAllEmpty(t *testing.T) {
+	got, err := async.All[int]().Await(context.Background())
+	if err != nil || len(got) != 0 {
+		t.Errorf("want empty, nil; got %v, %v", got, err)
+	}
+}
+
+func TestAnyFirstSuccess(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		got, err := async.Any(
+			after(t, 1*time.Second, "", errBoom),
+			after(t, 2*time.Second, "mirror-2", nil),
+			after(t, 3*time.Second, "mirror-3", nil),
+		).Await(context.Background())
+
+		if err != nil || got != "mirror-2" {
+			t.Errorf("want mirror-2, nil; got %q, %v", got, err)
+		}
+	})
+}
+
+func TestAnyAllFail(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		errOther := errors.New("other")
+
+		_, err := async.Any(
+			after(t, time.Second, 0, errBoom),
+			after(t, time.Second, 0, errOther),
+		).Await(context.Background())
+
+		if !errors.Is(err, errBoom) || !errors.Is(err, errOther) {
+			t.Errorf("want both errors joined; got %v", err)
+		}
+	})
+}
+
+func TestRaceFirstSettledWins(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		_, err := async.Race(
+			after(t, 2*time.Second, 1, nil),
+			after(t, 1*time.Second, 0, errBoom),
+		).Await(context.Background())
+
+		if !errors.Is(err, errBoom) {
+			t.Errorf("want the faster failure; got %v", err)
+		}
+
+		got, err := async.Race(
+			after(t, 1*time.Second, 1, nil),
+			after(t, 2*time.Second, 0, errBoom),
+		).Await(context.Background())
+
+		if err != nil || got != 1 {
+			t.Errorf("want the faster success; got %d, %v", got, err)
+		}
+	})
+}
+
+func TestNoFutures(t *testing.T) {
+	ctx := context.Background()
+
+	if _, err := async.Any[int]().Await(ctx); !errors.Is(err, async.ErrNoFutures) {
+		t.Errorf("Any: want ErrNoFutures; got %v", err)
+	}
+	if _, err := async.Race[int]().Await(ctx); !errors.Is(err, async.ErrNoFutures) {
+		t.Errorf("Race: want ErrNoFutures; got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package cache
+
+import (
+	"sync"
+	"time"
+)
+
+// Concurrent is an LRU that is safe for concurrent use.
+//
+// It uses a sync.Mutex, not a sync.RWMutex: Get moves the entry to the
+// front of the recency list, so every call writes.
+type Concurrent[K comparable, V any] struct {
+	mu  sync.Mutex
+	lru *LRU[K, V]
+}
+
+// NewConcurrent returns an empty concurrent cache. See New.
+func NewConcurrent[K comparable, V any](capacity int, opts ...Option) *Concurrent[K, V] {
+	return &Concurrent[K, V]{lru: New[K, V](capacity, opts...)}
+}
+
+// Get is LRU.Get.
+func (c *Concurrent[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Get(key)
+}
+
+// Peek is LRU.Peek.
+func (c *Concurrent[K, V]) Peek(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Peek(key)
+}
+
+// Put is LRU.Put.
+func (c *Concurrent[K, V]) Put(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.Put(key, value)
+}
+
+// PutWithTTL is LRU.PutWithTTL.
+func (c *Concurrent[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lru.PutWithTTL(key, value, ttl)
+}
+
+// Delete is LRU.Delete.
+func (c *Concurrent[K, V]) Delete(key K) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Delete(key)
+}
+
+// RemoveExpired is LRU.RemoveExpired.
+func (c *Concurrent[K, V]) RemoveExpired() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.RemoveExpired()
+}
+
+// Len is LRU.Len.
+func (c *Concurrent[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Keys is LRU.Keys.
+func (c *Concurrent[K, V]) Keys() []K {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Keys()
+}
+
+// Stats is LRU.Stats.
+func (c *Concurrent[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Stats()
+}
+
+// GetOrPut returns the value for key if it is cached. Otherwise it
+// stores and returns value. Doing both under one lock means two
+// goroutines can't both miss and then overwrite each other.
+func (c *Concurrent[K, V]) GetOrPut(key K, value V) (actual V, loaded bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if v, ok := c.lru.Get(key); ok {
+		return v, true
+	}
+	c.lru.Put(key, value)
+	return value, false
+}
@@ -0,0 +1,62 @@
+package cache_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/cache"
+)
+
+// Run with -race: every method is called from many goroutines at once
+func TestConcurrent(t *testing.T) {
+	c := cache.NewConcurrent[int, int](50)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 1000 {
+				key := (g*1000 + i) % 100
+				c.Put(key, i)
+				c.Get(key)
+				c.Peek(key + 1)
+				if i%10 == 0 {
+					c.Delete(key)
+					c.Keys()
+					c.RemoveExpired()
+				}
+			}
+		})
+	}
+	wg.Wait()
+
+	if c.Len() > 50 {
+		t.Errorf("want at most 50 entries; got %d", c.Len())
+	}
+	if s := c.Stats(); s.Hits+s.Misses != 8000 {
+		t.Errorf("want every Get counted; got %+v", s)
+	}
+}
+
+func TestGetOrPut(t *testing.T) {
+	c := cache.NewConcurrent[string, int](10)
+
+	var (
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		stored int
+	)
+	for i := range 20 {
+		wg.Go(func() {
+			if _, loaded := c.GetOrPut("k", i); !loaded {
+				mu.Lock()
+				stored++
+				mu.Unlock()
+			}
+		})
+	}
+	wg.Wait()
+
+	if stored != 1 {
+		t.Errorf("want exactly one goroutine to store the value; got %d", stored)
+	}
+}
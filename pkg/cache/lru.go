@@ -0,0 +1,236 @@
+// Package cache provides a generic least-recently-used cache with
+// optional expiry.
+//
+// An LRU holds at most a fixed number of entries. Adding one more evicts
+// the entry that was used least recently, so the entries a program
+// keeps asking for stay cached:
+//
+//	users := cache.New[int, User](1000, cache.WithTTL(time.Minute))
+//	users.Put(42, u)
+//	if u, ok := users.Get(42); ok { ... }
+//
+// LRU is not safe for concurrent use; even Get changes which entry is
+// the most recent. Concurrent wraps an LRU with a mutex.
+package cache
+
+import "time"
+
+// Stats counts how a cache was used.
+type Stats struct {
+	Hits      int // Get found a live entry
+	Misses    int // Get found nothing, or only an expired entry
+	Evictions int // entries removed to make room
+	Expired   int // entries removed because their TTL passed
+}
+
+// node is an entry in the recency list. The list is circular around a
+// sentinel root: root.next is the most recent entry, root.prev the
+// least recent.
+type node[K comparable, V any] struct {
+	key        K
+	value      V
+	expires    time.Time // zero means never
+	prev, next *node[K, V]
+}
+
+// LRU is a fixed-capacity cache that evicts the least recently used
+// entry. The zero value is not usable; call New.
+type LRU[K comparable, V any] struct {
+	capacity int
+	ttl      time.Duration
+	items    map[K]*node[K, V]
+	root     node[K, V]
+	stats    Stats
+}
+
+// options holds the settings changed by options.
+type options struct {
+	ttl time.Duration
+}
+
+// Option configures New.
+type Option func(*options)
+
+// WithTTL makes entries expire d after they were last put. Zero, the
+// default, means entries never expire. PutWithTTL overrides it for one
+// entry.
+func WithTTL(d time.Duration) Option {
+	return func(o *options) { o.ttl = d }
+}
+
+// New returns an empty cache that holds at most capacity entries. It
+// panics if capacity is less than one.
+func New[K comparable, V any](capacity int, opts ...Option) *LRU[K, V] {
+	if capacity < 1 {
+		panic("cache: capacity must be at least 1")
+	}
+
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	c := &LRU[K, V]{
+		capacity: capacity,
+		ttl:      o.ttl,
+		items:    make(map[K]*node[K, V], capacity),
+	}
+	c.root.next = &c.root
+	c.root.prev = &c.root
+	return c
+}
+
+// Get returns the value for key and marks it as the most recently used.
+// An expired entry is removed and reported as missing.
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	n, ok := c.items[key]
+	if ok && n.expired(time.Now()) {
+		c.remove(n)
+		c.stats.Expired++
+		ok = false
+	}
+	if !ok {
+		c.stats.Misses++
+		var zero V
+		return zero, false
+	}
+
+	c.stats.Hits++
+	c.moveToFront(n)
+	return n.value, true
+}
+
+// Peek returns the value for key like Get, but doesn't mark it as used
+// or count it in the stats.
+func (c *LRU[K, V]) Peek(key K) (V, bool) {
+	n, ok := c.items[key]
+	if !ok || n.expired(time.Now()) {
+		var zero V
+		return zero, false
+	}
+	return n.value, true
+}
+
+// Put adds or replaces the value for key, with the cache's TTL, and
+// marks it as the most recently used. If the cache is full, the least
+// recently used entry is evicted.
+func (c *LRU[K, V]) Put(key K, value V) {
+	c.PutWithTTL(key, value, c.ttl)
+}
+
+// PutWithTTL is like Put, but the entry expires after ttl instead of the
+// cache's TTL. Zero means it never expires.
+func (c *LRU[K, V]) PutWithTTL(key K, value V, ttl time.Duration) {
+	var expires time.Time
+	if ttl > 0 {
+		expires = time.Now().Add(ttl)
+	}
+
+	if n, ok := c.items[key]; ok {
+		n.value = value
+		n.expires = expires
+		c.moveToFront(n)
+		return
+	}
+
+	if len(c.items) >= c.capacity {
+		c.evict()
+	}
+
+	n := &node[K, V]{key: key, value: value, expires: expires}
+	c.items[key] = n
+	c.insertFront(n)
+}
+
+// Delete removes key from the cache, and reports whether it was there.
+func (c *LRU[K, V]) Delete(key K) bool {
+	n, ok := c.items[key]
+	if ok {
+		c.remove(n)
+	}
+	return ok
+}
+
+// RemoveExpired removes every expired entry, and returns how many it
+// removed. Expired entries are otherwise only removed when Get finds
+// them, or when they are evicted.
+func (c *LRU[K, V]) RemoveExpired() int {
+	now := time.Now()
+	removed := 0
+	for n := c.root.prev; n != &c.root; {
+		prev := n.prev
+		if n.expired(now) {
+			c.remove(n)
+			removed++
+		}
+		n = prev
+	}
+	c.stats.Expired += removed
+	return removed
+}
+
+// Len returns the number of entries, including expired ones that have
+// not been removed yet.
+func (c *LRU[K, V]) Len() int {
+	return len(c.items)
+}
+
+// Keys returns the keys from the most to the least recently used,
+// without expired ones.
+func (c *LRU[K, V]) Keys() []K {
+	now := time.Now()
+	keys := make([]K, 0, len(c.items))
+	for n := c.root.next; n != &c.root; n = n.next {
+		if !n.expired(now) {
+			keys = append(keys, n.key)
+		}
+	}
+	return keys
+}
+
+// Stats returns the counts since the cache was created.
+func (c *LRU[K, V]) Stats() Stats {
+	return c.stats
+}
+
+// evict removes the least recently used entry. An expired entry there
+// counts as expired, not evicted.
+func (c *LRU[K, V]) evict() {
+	n := c.root.prev
+	if n.expired(time.Now()) {
+		c.stats.Expired++
+	} else {
+		c.stats.Evictions++
+	}
+	c.remove(n)
+}
+
+func (n *node[K, V]) expired(now time.Time) bool {
+	return !n.expires.IsZero() && !now.Before(n.expires)
+}
+
+func (c *LRU[K, V]) insertFront(n *node[K, V]) {
+	n.prev = &c.root
+	n.next = c.root.next
+	c.root.next.prev = n
+	c.root.next = n
+}
+
+func (c *LRU[K, V]) unlink(n *node[K, V]) {
+	n.prev.next = n.next
+	n.next.prev = n.prev
+	n.prev, n.next = nil, nil
+}
+
+func (c *LRU[K, V]) moveToFront(n *node[K, V]) {
+	if c.root.next == n {
+		return
+	}
+	c.unlink(n)
+	c.insertFront(n)
+}
+
+func (c *LRU[K, V]) remove(n *node[K, V]) {
+	c.unlink(n)
+	delete(c.items, n.key)
+}
@@ -0,0 +1,200 @@
+package cache_test
+
+import (
+	"slices"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/cache"
+)
+
+func TestGetPut(t *testing.T) {
+	c := cache.New[string, int](2)
+
+	if _, ok := c.Get("a"); ok {
+		t.Error("empty cache: want a miss")
+	}
+
+	c.Put("a", 1)
+	c.Put("a", 2) // replaces, doesn't add
+
+	if v, ok := c.Get("a"); !ok || v != 2 {
+		t.Errorf("want 2, true; got %d, %v", v, ok)
+	}
+	if c.Len() != 1 {
+		t.Errorf("want len 1; got %d", c.Len())
+	}
+}
+
+func TestEvictsLeastRecentlyUsed(t *testing.T) {
+	c := cache.New[string, int](3)
+	c.Put("a", 1)
+	c.Put("b", 2)
+	c.Put("c", 3)
+
+	c.Get("a")    // a is now the most recent, b the least
+	c.Put("d", 4) // evicts b
+
+	if _, ok := c.Peek("b"); ok {
+		t.Error("want b evicted")
+	}
+	if want := []string{"d", "a", "c"}; !slices.Equal(c.Keys(), want) {
+		t.Errorf("want keys %v; got %v", want, c.Keys())
+	}
+
+	c.Put("c", 30) // replacing marks it as used too
+	c.Put("e", 5)  // evicts a
+
+	if want := []string{"e", "c", "d"}; !slices.Equal(c.Keys(), want) {
+		t.Errorf("want keys %v; got %v", want, c.Keys())
+	}
+	if got := c.Stats().Evictions; got != 2 {
+		t.Errorf("want 2 evictions; got %d", got)
+	}
+}
+
+func TestPeekDoesNotChangeOrder(t *testing.T) {
+	c := cache.New[int, int](2)
+	c.Put(1, 1)
+	c.Put(2, 2)
+
+	if v, ok := c.Peek(1); !ok || v != 1 {
+		t.Errorf("want 1, true; got %d, %v", v, ok)
+	}
+	c.Put(3, 3) // 1 is still the least recent
+
+	if _, ok := c.Peek(1); ok {
+		t.Error("want 1 evicted: Peek must not mark it as used")
+	}
+	if s := c.Stats(); s.Hits != 0 || s.Misses != 0 {
+		t.Errorf("want Peek not counted; got %+v", s)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	c := cache.New[int, string](2)
+	c.Put(1, "one")
+
+	if !c.Delete(1) {
+		t.Error("want Delete to report the key was there")
+	}
+	if c.Delete(1) {
+		t.Error("want Delete to report the key was gone")
+	}
+	if c.Len() != 0 || len(c.Keys()) != 0 {
+		t.Errorf("want an empty cache; got len %d, keys %v", c.Len(), c.Keys())
+	}
+
+	// The list must still work after removing its only entry
+	c.Put(2, "two")
+	c.Put(3, "three")
+	if want := []int{3, 2}; !slices.Equal(c.Keys(), want) {
+		t.Errorf("want keys %v; got %v", want, c.Keys())
+	}
+}
+
+func TestStats(t *testing.T) {
+	c := cache.New[int, int](1)
+	c.Put(1, 1)
+	c.Get(1) // hit
+	c.Get(2) // miss
+	c.Put(2, 2)
+	c.Get(1) // miss: evicted
+
+	want := cache.Stats{Hits: 1, Misses: 2, Evictions: 1}
+	if got := c.Stats(); got != want {
+		t.Errorf("want %+v; got %+v", want, got)
+	}
+}
+
+func TestTTL(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := cache.New[string, int](10, cache.WithTTL(time.Minute))
+		c.Put("a", 1)
+		c.PutWithTTL("b", 2, 2*time.Minute)
+		c.PutWithTTL("forever", 3, 0)
+
+		time.Sleep(59 * time.Second)
+		if _, ok := c.Get("a"); !ok {
+			t.Error("before the TTL: want a hit")
+		}
+
+		time.Sleep(time.Second)
+		if _, ok := c.Get("a"); ok {
+			t.Error("at the TTL: want a miss")
+		}
+		if _, ok := c.Peek("b"); !ok {
+			t.Error("b has its own, longer TTL: want it live")
+		}
+
+		time.Sleep(time.Hour)
+		if _, ok := c.Get("forever"); !ok {
+			t.Error("a TTL of zero: want it live forever")
+		}
+		if want := []string{"forever"}; !slices.Equal(c.Keys(), want) {
+			t.Errorf("want keys %v; got %v", want, c.Keys())
+		}
+
+		want := cache.Stats{Hits: 2, Misses: 1, Expired: 1}
+		if got := c.Stats(); got != want {
+			t.Errorf("want %+v; got %+v", want, got)
+		}
+	})
+}
+
+func TestPutResetsTTL(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := cache.New[string, int](10, cache.WithTTL(time.Minute))
+		c.Put("a", 1)
+
+		time.Sleep(50 * time.Second)
+		c.Put("a", 2)
+
+		time.Sleep(50 * time.Second)
+		if v, ok := c.Get("a"); !ok || v != 2 {
+			t.Errorf("want 2, true; got %d, %v", v, ok)
+		}
+	})
+}
+
+func TestRemoveExpired(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := cache.New[int, int](10)
+		c.PutWithTTL(1, 1, time.Second)
+		c.PutWithTTL(2, 2, time.Hour)
+		c.PutWithTTL(3, 3, time.Second)
+
+		time.Sleep(time.Second)
+		if n := c.RemoveExpired(); n != 2 {
+			t.Errorf("want 2 removed; got %d", n)
+		}
+		if c.Len() != 1 || c.Stats().Expired != 2 {
+			t.Errorf("want 1 left, 2 expired; got len %d, %+v", c.Len(), c.Stats())
+		}
+	})
+}
+
+func TestEvictingExpiredEntry(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := cache.New[int, int](1)
+		c.PutWithTTL(1, 1, time.Second)
+
+		time.Sleep(time.Second)
+		c.Put(2, 2)
+
+		want := cache.Stats{Expired: 1}
+		if got := c.Stats(); got != want {
+			t.Errorf("want the expired entry counted as expired; got %+v", got)
+		}
+	})
+}
+
+func TestNewPanicsOnZeroCapacity(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic")
+		}
+	}()
+	cache.New[int, int](0)
+}
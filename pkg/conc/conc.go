@@ -0,0 +1,114 @@
+// Package conc runs a function over a slice with bounded parallelism.
+//
+// Both helpers follow the same rules:
+//
+//   - At most limit calls to fn run at the same time. A limit of zero
+//     or less means no limit: one goroutine per item.
+//   - The first error cancels the context passed to fn and stops new
+//     items from starting. Calls already running finish, and the first
+//     error is returned.
+//   - Cancelling ctx has the same effect, and ctx.Err() is returned.
+//   - Neither helper returns before every goroutine it started has
+//     returned, so nothing leaks.
+//
+// Example:
+//
+//	sizes, err := conc.ConcurrentMap(ctx, urls, 4, func(ctx context.Context, url string) (int, error) {
+//		return fetchSize(ctx, url)
+//	})
+package conc
+
+import (
+	"context"
+	"sync"
+)
+
+// ForEachLimit calls fn for every item, running at most limit calls at
+// a time.
+func ForEachLimit[T any](ctx context.Context, items []T, limit int, fn func(context.Context, T) error) error {
+	return forEach(ctx, items, limit, func(ctx context.Context, _ int, item T) error {
+		return fn(ctx, item)
+	})
+}
+
+// ConcurrentMap calls fn for every item, running at most limit calls at
+// a time, and returns the results in the order of items.
+func ConcurrentMap[T, U any](ctx context.Context, items []T, limit int, fn func(context.Context, T) (U, error)) ([]U, error) {
+	// Each goroutine writes only its own index, so no lock is needed
+	results := make([]U, len(items))
+
+	err := forEach(ctx, items, limit, func(ctx context.Context, i int, item T) error {
+		v, err := fn(ctx, item)
+		if err != nil {
+			return err
+		}
+		results[i] = v
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// forEach is the shared implementation: it also passes each item's index.
+func forEach[T any](ctx context.Context, items []T, limit int, fn func(context.Context, int, T) error) error {
+	if limit <= 0 || limit > len(items) {
+		limit = len(items)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+	fail := func(err error) {
+		once.Do(func() {
+			firstErr = err
+			cancel()
+		})
+	}
+
+	// A buffered channel as a semaphore: a send takes a slot, a
+	// receive frees it
+	sem := make(chan struct{}, limit)
+
+	// stopped records that items were skipped, not just finished
+	stopped := false
+
+loop:
+	for i, item := range items {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			stopped = true
+			break loop
+		}
+
+		// A slot may free up in the same instant the context is
+		// cancelled; select picks randomly, so check again
+		if ctx.Err() != nil {
+			<-sem
+			stopped = true
+			break loop
+		}
+
+		wg.Go(func() {
+			defer func() { <-sem }()
+			if err := fn(ctx, i, item); err != nil {
+				fail(err)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	if firstErr == nil && stopped {
+		// Nothing failed, so the caller cancelled ctx
+		return ctx.Err()
+	}
+	return firstErr
+}
@@ -0,0 +1,173 @@
+package conc_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/conc"
+)
+
+var errBoom = errors.New("boom")
+
+// inFlight tracks how many calls run at once and the highest count seen
+type inFlight struct {
+	mu       sync.Mutex
+	now, max int
+}
+
+func (f *inFlight) enter() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now++
+	f.max = max(f.max, f.now)
+}
+
+func (f *inFlight) leave() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.now--
+}
+
+func TestConcurrentMapKeepsOrder(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		items := []int{5, 1, 4, 2, 3}
+
+		// Larger items take longer, so they finish out of order
+		got, err := conc.ConcurrentMap(context.Background(), items, 2,
+			func(ctx context.Context, n int) (int, error) {
+				time.Sleep(time.Duration(n) * time.Second)
+				return n * n, nil
+			})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := []int{25, 1, 16, 4, 9}; !slices.Equal(got, want) {
+			t.Errorf("want %v; got %v", want, got)
+		}
+	})
+}
+
+func TestLimitIsRespected(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var flight inFlight
+		items := make([]int, 20)
+
+		err := conc.ForEachLimit(context.Background(), items, 3,
+			func(ctx context.Context, _ int) error {
+				flight.enter()
+				defer flight.leave()
+				time.Sleep(time.Second)
+				return nil
+			})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flight.max != 3 {
+			t.Errorf("want at most 3 in flight; got %d", flight.max)
+		}
+	})
+}
+
+func TestNoLimit(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var flight inFlight
+		items := make([]int, 10)
+
+		start := time.Now()
+		err := conc.ForEachLimit(context.Background(), items, 0,
+			func(ctx context.Context, _ int) error {
+				flight.enter()
+				defer flight.leave()
+				time.Sleep(time.Second)
+				return nil
+			})
+
+		if err != nil {
+			t.Fatal(err)
+		}
+		if flight.max != len(items) {
+			t.Errorf("want all %d in flight; got %d", len(items), flight.max)
+		}
+		if elapsed := time.Since(start); elapsed != time.Second {
+			t.Errorf("want 1s; got %v", elapsed)
+		}
+	})
+}
+
+func TestStopsEarlyOnError(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var started atomic.Int32
+		items := []int{1, 2, 3, 4, 5, 6, 7, 8}
+
+		got, err := conc.ConcurrentMap(context.Background(), items, 2,
+			func(ctx context.Context, n int) (int, error) {
+				started.Add(1)
+				if n == 2 {
+					return 0, errBoom
+				}
+
+				// Others wait for work or cancellation
+				select {
+				case <-time.After(time.Hour):
+					return n, nil
+				case <-ctx.Done():
+					return 0, ctx.Err()
+				}
+			})
+
+		if !errors.Is(err, errBoom) {
+			t.Errorf("want errBoom; got %v", err)
+		}
+		if got != nil {
+			t.Errorf("want no results on error; got %v", got)
+		}
+		// 1 and 2 start; 2 fails and frees its slot, so at most 3
+		// can start before the cancellation is seen
+		if n := started.Load(); n > 3 {
+			t.Errorf("want at most 3 items started; got %d", n)
+		}
+	})
+}
+
+func TestStopsOnCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 1500*time.Millisecond)
+		defer cancel()
+
+		var done atomic.Int32
+		items := make([]int, 10)
+
+		err := conc.ForEachLimit(ctx, items, 1,
+			func(ctx context.Context, _ int) error {
+				time.Sleep(time.Second) // ignores ctx on purpose
+				done.Add(1)
+				return nil
+			})
+
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want DeadlineExceeded; got %v", err)
+		}
+		// The second call was already running at the deadline and is
+		// allowed to finish; no third call starts
+		if n := done.Load(); n != 2 {
+			t.Errorf("want 2 calls finished; got %d", n)
+		}
+	})
+}
+
+func TestEmpty(t *testing.T) {
+	got, err := conc.ConcurrentMap(context.Background(), []int(nil), 4,
+		func(ctx context.Context, n int) (int, error) { return n, nil })
+
+	if err != nil || len(got) != 0 {
+		t.Errorf("want empty, nil; got %v, %v", got, err)
+	}
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Binder reads many keys and keeps every error, instead of stopping at
+// the first one. A program that starts with three bad settings hears
+// about all three, not one per restart:
+//
+//	b := config.NewBinder(c)
+//	s := Settings{
+//		Addr:    config.Bind[string](b, "http.addr"),
+//		Timeout: config.Bind[time.Duration](b, "http.timeout"),
+//		DBURL:   config.Bind[string](b, "db.url"),
+//	}
+//	b.Check("http.timeout", s.Timeout > 0, "must be positive")
+//	if err := b.Err(); err != nil { ... }
+type Binder struct {
+	c      *Config
+	errs   []error
+	failed map[string]bool
+}
+
+// NewBinder returns a Binder that reads from c.
+func NewBinder(c *Config) *Binder {
+	return &Binder{c: c, failed: make(map[string]bool)}
+}
+
+// Bind returns key's value, like Get. If Get fails, Bind keeps the error
+// for Err, and returns the zero T.
+func Bind[T Value](b *Binder, key string) T {
+	v, err := Get[T](b.c, key)
+	if err != nil {
+		b.errs = append(b.errs, err)
+		b.failed[normalize(key)] = true
+	}
+	return v
+}
+
+// Check keeps an error for key if ok is false: a rule that parsing alone
+// can't check, like a port in range. If key failed already, Check does
+// nothing: the zero value Bind returned is not the user's mistake.
+func (b *Binder) Check(key string, ok bool, format string, args ...any) {
+	if ok || b.failed[normalize(key)] {
+		return
+	}
+	s, _ := b.c.Lookup(key)
+	b.errs = append(b.errs, &Error{Key: normalize(key), Setting: s, Err: fmt.Errorf(format, args...)})
+}
+
+// Err returns every error kept so far, joined with errors.Join, or nil.
+// Each one is an *Error.
+func (b *Binder) Err() error {
+	return errors.Join(b.errs...)
+}
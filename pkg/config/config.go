@@ -0,0 +1,234 @@
+// Package config reads settings from four sources and merges them by
+// precedence:
+//
+//	Default < File < Env < Flag
+//
+// A value from a higher source wins, whatever order the sources were
+// loaded in:
+//
+//	c := config.New()
+//	c.SetDefaults(map[string]string{"http.addr": ":8080", "http.timeout": "5s"})
+//	if err := c.LoadFile(os.DirFS("."), "app.toml"); err != nil { ... }
+//	c.UseEnv("APP_", os.LookupEnv)  // APP_HTTP_ADDR sets http.addr
+//	c.LoadFlags(flag.CommandLine)   // -http.addr sets http.addr
+//
+//	timeout, err := config.Get[time.Duration](c, "http.timeout")
+//
+// Keys are lower case and dotted, like "db.max_conns". A key with no
+// default is required: Get reports ErrMissing when no source sets it. A
+// Binder reads many keys and reports every bad one at once.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Source is where a value came from. A higher Source wins.
+type Source int
+
+const (
+	Default Source = iota
+	File
+	Env
+	Flag
+)
+
+// String returns the name of the source.
+func (s Source) String() string {
+	switch s {
+	case Default:
+		return "default"
+	case File:
+		return "file"
+	case Env:
+		return "env"
+	case Flag:
+		return "flag"
+	default:
+		return fmt.Sprintf("Source(%d)", int(s))
+	}
+}
+
+// Setting is a key's value, before it is parsed, and where it came from.
+type Setting struct {
+	Key    string
+	Raw    string
+	Source Source
+	// Origin says exactly where: "config.toml:3", "$APP_HTTP_ADDR", or
+	// "-http.addr"
+	Origin string
+}
+
+// Config holds the settings from every source. Load the sources, then
+// read the values; a Config is not safe to change while it is read.
+type Config struct {
+	layers [Flag + 1]map[string]Setting
+
+	// The environment is read lazily, on every lookup: a key's variable
+	// name is known only from the key
+	envPrefix string
+	lookupEnv func(string) (string, bool)
+}
+
+// New returns an empty Config.
+func New() *Config {
+	c := &Config{}
+	for i := range c.layers {
+		c.layers[i] = make(map[string]Setting)
+	}
+	return c
+}
+
+// Set sets key from src. Setting a key again from the same source
+// replaces the value: the last file loaded wins over the ones before it.
+func (c *Config) Set(src Source, key, value, origin string) {
+	key = normalize(key)
+	c.layers[src][key] = Setting{Key: key, Raw: value, Source: src, Origin: origin}
+}
+
+// SetDefaults sets the default for every key in defaults.
+func (c *Config) SetDefaults(defaults map[string]string) {
+	for key, value := range defaults {
+		c.Set(Default, key, value, "default")
+	}
+}
+
+// UseEnv reads the environment through lookup, usually os.LookupEnv. A
+// key's variable is the prefix and the key in upper case, with dots as
+// underscores: with the prefix "APP_", db.max_conns is APP_DB_MAX_CONNS.
+func (c *Config) UseEnv(prefix string, lookup func(string) (string, bool)) {
+	c.envPrefix, c.lookupEnv = prefix, lookup
+}
+
+// EnvName returns the environment variable for key.
+func (c *Config) EnvName(key string) string {
+	return c.envPrefix + strings.ToUpper(strings.ReplaceAll(normalize(key), ".", "_"))
+}
+
+// LoadFlags sets a key for every flag in fs that was set on the command
+// line. A flag's name is its key. Flags that weren't set are left out,
+// so their defaults don't hide the file and the environment; give a flag
+// an empty default, and put the real one in SetDefaults.
+func (c *Config) LoadFlags(fs *flag.FlagSet) {
+	fs.Visit(func(f *flag.Flag) {
+		c.Set(Flag, f.Name, f.Value.String(), "-"+f.Name)
+	})
+}
+
+// Lookup returns the setting for key from the highest source that sets
+// it.
+func (c *Config) Lookup(key string) (Setting, bool) {
+	key = normalize(key)
+	if s, ok := c.layers[Flag][key]; ok {
+		return s, true
+	}
+	if c.lookupEnv != nil {
+		name := c.EnvName(key)
+		if v, ok := c.lookupEnv(name); ok {
+			return Setting{Key: key, Raw: v, Source: Env, Origin: "$" + name}, true
+		}
+	}
+	for _, src := range []Source{File, Default} {
+		if s, ok := c.layers[src][key]; ok {
+			return s, true
+		}
+	}
+	return Setting{}, false
+}
+
+// Keys returns every key set by a default, a file, or a flag, sorted. A
+// key set only by the environment isn't listed.
+func (c *Config) Keys() []string {
+	var keys []string
+	for _, layer := range c.layers {
+		for key := range layer {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+func normalize(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
+
+// ErrMissing is the error for a key that no source sets.
+var ErrMissing = errors.New("required, but not set")
+
+// Error is a key that is missing, or whose value doesn't parse.
+type Error struct {
+	Key     string
+	Setting Setting // zero if the key is missing
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Setting.Origin == "" {
+		return e.Key + ": " + e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %q from %s: %v", e.Key, e.Setting.Raw, e.Setting.Origin, e.Err)
+}
+
+func (e *Error) Unwrap() error { return e.Err }
+
+// Value is the types Get can parse. A []string is a comma-separated
+// list.
+type Value interface {
+	string | bool | int | int64 | float64 | time.Duration | []string
+}
+
+// Get returns key's value, parsed as a T. The error is an *Error: it
+// wraps ErrMissing if no source sets key, or the parse error.
+func Get[T Value](c *Config, key string) (T, error) {
+	var v T
+	s, ok := c.Lookup(key)
+	if !ok {
+		return v, &Error{Key: normalize(key), Err: ErrMissing}
+	}
+	if err := parse(s.Raw, &v); err != nil {
+		return v, &Error{Key: s.Key, Setting: s, Err: err}
+	}
+	return v, nil
+}
+
+// parse parses raw into *v
+func parse[T Value](raw string, v *T) error {
+	raw = strings.TrimSpace(raw)
+	var err error
+	switch p := any(v).(type) {
+	case *string:
+		*p = raw
+	case *bool:
+		*p, err = strconv.ParseBool(raw)
+	case *int:
+		*p, err = strconv.Atoi(raw)
+	case *int64:
+		*p, err = strconv.ParseInt(raw, 10, 64)
+	case *float64:
+		*p, err = strconv.ParseFloat(raw, 64)
+	case *time.Duration:
+		*p, err = time.ParseDuration(raw)
+	case *[]string:
+		*p = nil
+		for item := range strings.SplitSeq(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*p = append(*p, item)
+			}
+		}
+	}
+
+	// "strconv.Atoi: parsing "x": invalid syntax" repeats what Error
+	// says already: keep "invalid syntax"
+	var ne *strconv.NumError
+	if errors.As(err, &ne) {
+		err = ne.Err
+	}
+	return err
+}
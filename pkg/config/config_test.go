@@ -0,0 +1,219 @@
+package config_test
+
+import (
+	"errors"
+	"flag"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/config"
+)
+
+// env is an environment for UseEnv
+func env(vars ...string) func(string) (string, bool) {
+	m := make(map[string]string)
+	for _, kv := range vars {
+		k, v, _ := strings.Cut(kv, "=")
+		m[k] = v
+	}
+	return func(name string) (string, bool) {
+		v, ok := m[name]
+		return v, ok
+	}
+}
+
+// layered returns a Config with port set by every source it's given:
+// "default", "file", "env", and "flag"
+func layered(t *testing.T, sources ...string) *config.Config {
+	t.Helper()
+	c := config.New()
+	for _, src := range sources {
+		switch src {
+		case "default":
+			c.SetDefaults(map[string]string{"port": "1"})
+		case "file":
+			fsys := fstest.MapFS{"app.toml": {Data: []byte("port = 2\n")}}
+			if err := c.LoadFile(fsys, "app.toml"); err != nil {
+				t.Fatal(err)
+			}
+		case "env":
+			c.UseEnv("APP_", env("APP_PORT=3"))
+		case "flag":
+			fs := flag.NewFlagSet("test", flag.ContinueOnError)
+			fs.String("port", "", "")
+			if err := fs.Parse([]string{"-port", "4"}); err != nil {
+				t.Fatal(err)
+			}
+			c.LoadFlags(fs)
+		}
+	}
+	return c
+}
+
+func TestPrecedence(t *testing.T) {
+	tests := []struct {
+		sources []string
+		want    int
+		from    string
+	}{
+		{[]string{"default"}, 1, "default"},
+		{[]string{"default", "file"}, 2, "app.toml:1"},
+		{[]string{"default", "file", "env"}, 3, "$APP_PORT"},
+		{[]string{"default", "file", "env", "flag"}, 4, "-port"},
+		{[]string{"default", "flag"}, 4, "-port"},
+		// The order of loading doesn't matter, only the source
+		{[]string{"flag", "env", "file", "default"}, 4, "-port"},
+		{[]string{"env", "file"}, 3, "$APP_PORT"},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.sources, ","), func(t *testing.T) {
+			c := layered(t, tt.sources...)
+			got, err := config.Get[int](c, "port")
+			if err != nil || got != tt.want {
+				t.Errorf("want %d; got %d, %v", tt.want, got, err)
+			}
+			if s, _ := c.Lookup("port"); s.Origin != tt.from {
+				t.Errorf("want it from %s; got %s", tt.from, s.Origin)
+			}
+		})
+	}
+}
+
+func TestUnsetFlagsDontOverride(t *testing.T) {
+	c := config.New()
+	c.SetDefaults(map[string]string{"port": "8080", "debug": "false"})
+
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.String("port", "9999", "a default that would hide the others")
+	fs.Bool("debug", false, "")
+	if err := fs.Parse([]string{"-debug"}); err != nil {
+		t.Fatal(err)
+	}
+	c.LoadFlags(fs)
+
+	if port, _ := config.Get[int](c, "port"); port != 8080 {
+		t.Errorf("want the default port; got %d", port)
+	}
+	if debug, _ := config.Get[bool](c, "debug"); !debug {
+		t.Error("want debug from the flag")
+	}
+}
+
+func TestEnvNames(t *testing.T) {
+	c := config.New()
+	c.UseEnv("APP_", env("APP_DB_MAX_CONNS=20", "APP_HTTP_ADDR=:9000", "HTTP_ADDR=:1"))
+
+	if got := c.EnvName("db.max_conns"); got != "APP_DB_MAX_CONNS" {
+		t.Errorf("want APP_DB_MAX_CONNS; got %s", got)
+	}
+	// The key finds its variable, so underscores in keys are fine
+	if n, err := config.Get[int](c, "db.max_conns"); n != 20 || err != nil {
+		t.Errorf("want 20; got %d, %v", n, err)
+	}
+	if addr, _ := config.Get[string](c, "HTTP.Addr"); addr != ":9000" {
+		t.Errorf("want keys in any case, and only prefixed variables; got %q", addr)
+	}
+}
+
+func TestGetTypes(t *testing.T) {
+	c := config.New()
+	c.SetDefaults(map[string]string{
+		"s": "  hello ", "b": "true", "i": "42", "i64": "10000000000",
+		"f": "0.5", "d": "1m30s", "list": "a, b,,c ",
+	})
+
+	check := func(name string, got, want any, err error) {
+		t.Helper()
+		if err != nil || !equal(got, want) {
+			t.Errorf("%s: want %v; got %v, %v", name, want, got, err)
+		}
+	}
+	s, err := config.Get[string](c, "s")
+	check("string", s, "hello", err)
+	b, err := config.Get[bool](c, "b")
+	check("bool", b, true, err)
+	i, err := config.Get[int](c, "i")
+	check("int", i, 42, err)
+	i64, err := config.Get[int64](c, "i64")
+	check("int64", i64, int64(10000000000), err)
+	f, err := config.Get[float64](c, "f")
+	check("float64", f, 0.5, err)
+	d, err := config.Get[time.Duration](c, "d")
+	check("Duration", d, 90*time.Second, err)
+	list, err := config.Get[[]string](c, "list")
+	check("[]string", list, []string{"a", "b", "c"}, err)
+}
+
+func equal(a, b any) bool {
+	if as, ok := a.([]string); ok {
+		return slices.Equal(as, b.([]string))
+	}
+	return a == b
+}
+
+func TestGetErrors(t *testing.T) {
+	c := config.New()
+	c.SetDefaults(map[string]string{"port": "eighty"})
+	c.UseEnv("APP_", env("APP_TIMEOUT=5"))
+
+	_, err := config.Get[int](c, "port")
+	if want := `port: "eighty" from default: invalid syntax`; err == nil || err.Error() != want {
+		t.Errorf("want %s; got %v", want, err)
+	}
+
+	_, err = config.Get[time.Duration](c, "timeout")
+	var ce *config.Error
+	if !errors.As(err, &ce) || ce.Setting.Source != config.Env || !strings.Contains(err.Error(), "from $APP_TIMEOUT") {
+		t.Errorf("want an *Error from the environment; got %v", err)
+	}
+
+	_, err = config.Get[string](c, "db.url")
+	if !errors.Is(err, config.ErrMissing) || err.Error() != "db.url: required, but not set" {
+		t.Errorf("want ErrMissing; got %v", err)
+	}
+}
+
+func TestBinder(t *testing.T) {
+	c := config.New()
+	c.SetDefaults(map[string]string{"http.addr": ":8080", "http.timeout": "soon", "db.conns": "-1"})
+
+	b := config.NewBinder(c)
+	addr := config.Bind[string](b, "http.addr")
+	timeout := config.Bind[time.Duration](b, "http.timeout")
+	url := config.Bind[string](b, "db.url")
+	conns := config.Bind[int](b, "db.conns")
+	b.Check("db.conns", conns > 0, "must be at least 1, not %d", conns)
+	b.Check("http.timeout", timeout > 0, "must be positive") // failed already: no second error
+
+	if addr != ":8080" || timeout != 0 || url != "" {
+		t.Errorf("want good values, and zeros for the bad ones; got %q %v %q", addr, timeout, url)
+	}
+
+	err := b.Err()
+	want := []string{
+		`http.timeout: "soon" from default: time: invalid duration "soon"`,
+		"db.url: required, but not set",
+		`db.conns: "-1" from default: must be at least 1, not -1`,
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Fatalf("want every error:\n%s\ngot:\n%v", strings.Join(want, "\n"), err)
+	}
+	if !errors.Is(err, config.ErrMissing) {
+		t.Error("want the joined error to match ErrMissing")
+	}
+
+	if err := config.NewBinder(c).Err(); err != nil {
+		t.Errorf("want nil with no errors; got %v", err)
+	}
+}
+
+func TestKeys(t *testing.T) {
+	c := layered(t, "default", "file", "flag")
+	c.SetDefaults(map[string]string{"a.b": "x"})
+	if got := c.Keys(); !slices.Equal(got, []string{"a.b", "port"}) {
+		t.Errorf("want every key once, sorted; got %v", got)
+	}
+}
@@ -0,0 +1,210 @@
+package config
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// LoadFile sets keys from the file name in fsys. A .json file is JSON;
+// any other file is a small subset of TOML:
+//
+//	# a comment
+//	name = "demo"
+//
+//	[http]
+//	addr    = ":8080"   # http.addr
+//	timeout = 5s        # bare values are fine too
+//	origins = ["a.example", "b.example"]
+//
+// Nested JSON objects are dotted keys too: {"http": {"addr": ":8080"}}
+// sets http.addr. Arrays become comma-separated lists, for Get[[]string].
+//
+// If the file doesn't exist, the error wraps fs.ErrNotExist; check for
+// it with errors.Is to make the file optional. Every line that doesn't
+// parse is reported, joined with errors.Join, and nothing from the file
+// is set.
+func (c *Config) LoadFile(fsys fs.FS, name string) error {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return fmt.Errorf("config: %w", err)
+	}
+
+	var settings []Setting
+	if path.Ext(name) == ".json" {
+		settings, err = parseJSON(name, data)
+	} else {
+		settings, err = parseTOML(name, data)
+	}
+	if err != nil {
+		return err
+	}
+	for _, s := range settings {
+		c.Set(File, s.Key, s.Raw, s.Origin)
+	}
+	return nil
+}
+
+func parseJSON(name string, data []byte) ([]Setting, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep 10000000000 from turning into 1e+10
+	var doc map[string]any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("config: %s: %w", name, err)
+	}
+
+	var settings []Setting
+	var errs []error
+	var walk func(key string, v any)
+	walk = func(key string, v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for k, inner := range v {
+				if key != "" {
+					k = key + "." + k
+				}
+				walk(k, inner)
+			}
+		case []any:
+			items := make([]string, 0, len(v))
+			for _, item := range v {
+				s, ok := scalar(item)
+				if !ok {
+					errs = append(errs, fmt.Errorf("config: %s: %s: want a list of values", name, key))
+					return
+				}
+				items = append(items, s)
+			}
+			settings = append(settings, Setting{Key: key, Raw: strings.Join(items, ","), Origin: name})
+		case nil:
+			// null is the same as not set
+		default:
+			s, _ := scalar(v)
+			settings = append(settings, Setting{Key: key, Raw: s, Origin: name})
+		}
+	}
+	walk("", doc)
+	return settings, errors.Join(errs...)
+}
+
+// scalar returns a JSON string, number, or bool as a string
+func scalar(v any) (string, bool) {
+	switch v := v.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+func parseTOML(name string, data []byte) ([]Setting, error) {
+	var settings []Setting
+	var errs []error
+	section := ""
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		origin := fmt.Sprintf("%s:%d", name, n)
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			inner, ok := strings.CutSuffix(stripComment(line), "]")
+			if !ok || strings.TrimSpace(inner[1:]) == "" {
+				errs = append(errs, fmt.Errorf("config: %s: want [section]", origin))
+				continue
+			}
+			section = strings.TrimSpace(inner[1:]) + "."
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			errs = append(errs, fmt.Errorf("config: %s: want key = value", origin))
+			continue
+		}
+		raw, err := parseValue(strings.TrimSpace(value))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("config: %s: %s: %w", origin, key, err))
+			continue
+		}
+		settings = append(settings, Setting{Key: section + key, Raw: raw, Origin: origin})
+	}
+	if err := sc.Err(); err != nil {
+		errs = append(errs, fmt.Errorf("config: %s: %w", name, err))
+	}
+	return settings, errors.Join(errs...)
+}
+
+// parseValue parses a quoted string, a list, or a bare value, and drops
+// a comment after it
+func parseValue(v string) (string, error) {
+	switch {
+	case strings.HasPrefix(v, `"`):
+		s, rest, err := unquote(v)
+		if err != nil {
+			return "", err
+		}
+		if rest = strings.TrimSpace(rest); rest != "" && rest[0] != '#' {
+			return "", fmt.Errorf("unexpected %q after the string", rest)
+		}
+		return s, nil
+
+	case strings.HasPrefix(v, "["):
+		var items []string
+		rest := strings.TrimSpace(v[1:])
+		for !strings.HasPrefix(rest, "]") {
+			var item string
+			var err error
+			if strings.HasPrefix(rest, `"`) {
+				item, rest, err = unquote(rest)
+			} else {
+				end := strings.IndexAny(rest, ",]")
+				if end < 0 {
+					return "", errors.New("unclosed list")
+				}
+				item, rest = strings.TrimSpace(rest[:end]), rest[end:]
+			}
+			if err != nil {
+				return "", err
+			}
+			items = append(items, item)
+			rest = strings.TrimSpace(rest)
+			rest, _ = strings.CutPrefix(rest, ",")
+			rest = strings.TrimSpace(rest)
+			if rest == "" {
+				return "", errors.New("unclosed list")
+			}
+		}
+		return strings.Join(items, ","), nil
+
+	default:
+		return strings.TrimSpace(stripComment(v)), nil
+	}
+}
+
+// unquote parses the quoted string at the start of v, and returns what
+// follows it
+func unquote(v string) (s, rest string, err error) {
+	prefix, err := strconv.QuotedPrefix(v)
+	if err != nil {
+		return "", "", errors.New("unclosed string")
+	}
+	s, err = strconv.Unquote(prefix)
+	return s, v[len(prefix):], err
+}
+
+func stripComment(v string) string {
+	v, _, _ = strings.Cut(v, "#")
+	return v
+}
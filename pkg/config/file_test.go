@@ -0,0 +1,143 @@
+package config_test
+
+import (
+	"errors"
+	"io/fs"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/inancgumus/learngo/pkg/config"
+)
+
+const toml = `# the app
+name = "demo # not a comment"
+debug = true   # a comment
+
+[http]
+addr    = ":8080"
+timeout = 5s
+origins = ["a.example", "b.example" ]
+ports   = [80, 443]
+
+[db]
+url = "postgres://localhost/app?sslmode=disable"
+`
+
+const jsonDoc = `{
+	"name": "demo # not a comment",
+	"debug": true,
+	"http": {
+		"addr": ":8080",
+		"timeout": "5s",
+		"origins": ["a.example", "b.example"],
+		"ports": [80, 443]
+	},
+	"db": {"url": "postgres://localhost/app?sslmode=disable", "pool": null},
+	"big": 10000000000
+}`
+
+func TestLoadFile(t *testing.T) {
+	want := map[string]string{
+		"name":         "demo # not a comment",
+		"debug":        "true",
+		"http.addr":    ":8080",
+		"http.timeout": "5s",
+		"http.origins": "a.example,b.example",
+		"http.ports":   "80,443",
+		"db.url":       "postgres://localhost/app?sslmode=disable",
+	}
+	fsys := fstest.MapFS{
+		"app.toml": {Data: []byte(toml)},
+		"app.json": {Data: []byte(jsonDoc)},
+	}
+
+	for _, name := range []string{"app.toml", "app.json"} {
+		t.Run(name, func(t *testing.T) {
+			c := config.New()
+			if err := c.LoadFile(fsys, name); err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range want {
+				s, ok := c.Lookup(key)
+				if !ok || s.Raw != value || s.Source != config.File {
+					t.Errorf("%s: want %q from the file; got %+v", key, value, s)
+				}
+			}
+			if _, ok := c.Lookup("db.pool"); ok {
+				t.Error("want null to be unset")
+			}
+		})
+	}
+
+	c := config.New()
+	c.LoadFile(fsys, "app.json")
+	if s, _ := c.Lookup("big"); s.Raw != "10000000000" {
+		t.Errorf("want big numbers kept as they are; got %q", s.Raw)
+	}
+	c.LoadFile(fsys, "app.toml")
+	if s, _ := c.Lookup("http.addr"); s.Origin != "app.toml:6" {
+		t.Errorf("want the line a value came from; got %q", s.Origin)
+	}
+}
+
+func TestLoadFileLaterWins(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.toml":  {Data: []byte("a = 1\nb = 1\n")},
+		"local.toml": {Data: []byte("b = 2\n")},
+	}
+	c := config.New()
+	for _, name := range []string{"base.toml", "local.toml"} {
+		if err := c.LoadFile(fsys, name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a, _ := c.Lookup("a")
+	b, _ := c.Lookup("b")
+	if a.Raw != "1" || b.Raw != "2" {
+		t.Errorf("want a=1 b=2; got a=%s b=%s", a.Raw, b.Raw)
+	}
+}
+
+func TestLoadFileErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"bad.toml": {Data: []byte(strings.Join([]string{
+			"good = 1",
+			"no equals sign",
+			`name = "unclosed`,
+			"[]",
+			`list = ["a", "b"`,
+			`s = "x" y`,
+			"= 3",
+		}, "\n"))},
+		"bad.json":  {Data: []byte(`{"a": 1,}`)},
+		"list.json": {Data: []byte(`{"a": [{"b": 1}]}`)},
+	}
+
+	c := config.New()
+	err := c.LoadFile(fsys, "bad.toml")
+	want := []string{
+		"config: bad.toml:2: want key = value",
+		"config: bad.toml:3: name: unclosed string",
+		"config: bad.toml:4: want [section]",
+		"config: bad.toml:5: list: unclosed list",
+		`config: bad.toml:6: s: unexpected "y" after the string`,
+		"config: bad.toml:7: want key = value",
+	}
+	if err == nil || err.Error() != strings.Join(want, "\n") {
+		t.Errorf("want every bad line:\n%s\ngot:\n%v", strings.Join(want, "\n"), err)
+	}
+	if _, ok := c.Lookup("good"); ok {
+		t.Error("want nothing set from a file with errors")
+	}
+
+	if err := c.LoadFile(fsys, "bad.json"); err == nil || !strings.HasPrefix(err.Error(), "config: bad.json: ") {
+		t.Errorf("want a JSON error; got %v", err)
+	}
+	if err := c.LoadFile(fsys, "list.json"); err == nil || err.Error() != "config: list.json: a: want a list of values" {
+		t.Errorf("want a list error; got %v", err)
+	}
+	if err := c.LoadFile(fsys, "missing.toml"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("want fs.ErrNotExist; got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+// Package ctxio makes io.Reader and io.Writer stop when a context ends.
+//
+// Most io code, including io.Copy, knows nothing about contexts: once a
+// copy starts, it runs until the source is drained. Wrapping either
+// side makes every Read or Write check the context first:
+//
+//	_, err := io.Copy(dst, ctxio.NewReader(ctx, src))
+//	if errors.Is(err, context.DeadlineExceeded) { ... }
+//
+// The check happens between calls. A single Read that is already
+// blocked, on a network connection for example, is not interrupted;
+// close the underlying reader from context.AfterFunc for that.
+package ctxio
+
+import (
+	"context"
+	"io"
+)
+
+// maxChunk is the largest slice passed to the underlying writer in one
+// call, so that one huge Write still checks the context regularly.
+const maxChunk = 32 * 1024
+
+// Reader is an io.Reader that fails once its context is done.
+type Reader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+// NewReader returns a Reader that reads from r until ctx is done.
+func NewReader(ctx context.Context, r io.Reader) *Reader {
+	return &Reader{ctx: ctx, r: r}
+}
+
+// Read reads from the underlying reader, or returns context.Cause of
+// the context if it is done.
+//
+// Reader deliberately has no WriteTo method, so io.Copy cannot take a
+// shortcut that skips these checks.
+func (r *Reader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, context.Cause(r.ctx)
+	}
+	return r.r.Read(p)
+}
+
+// Writer is an io.Writer that fails once its context is done.
+type Writer struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+// NewWriter returns a Writer that writes to w until ctx is done.
+func NewWriter(ctx context.Context, w io.Writer) *Writer {
+	return &Writer{ctx: ctx, w: w}
+}
+
+// Write writes p to the underlying writer in chunks, checking the
+// context before each one. If the context is done part way, it returns
+// the number of bytes already written and context.Cause of the context.
+func (w *Writer) Write(p []byte) (int, error) {
+	var written int
+
+	for len(p) > 0 {
+		if err := w.ctx.Err(); err != nil {
+			return written, context.Cause(w.ctx)
+		}
+
+		chunk := p[:min(len(p), maxChunk)]
+		n, err := w.w.Write(chunk)
+		written += n
+		if err != nil {
+			return written, err
+		}
+		p = p[n:]
+	}
+
+	return written, nil
+}
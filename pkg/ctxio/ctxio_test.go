@@ -0,0 +1,157 @@
+package ctxio_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/ctxio"
+)
+
+// hookReader calls hook before every Read of the wrapped reader
+type hookReader struct {
+	r    io.Reader
+	hook func()
+}
+
+func (h *hookReader) Read(p []byte) (int, error) {
+	h.hook()
+	return h.r.Read(p)
+}
+
+// hookWriter calls hook after every Write to the wrapped writer
+type hookWriter struct {
+	w    io.Writer
+	hook func()
+}
+
+func (h *hookWriter) Write(p []byte) (int, error) {
+	n, err := h.w.Write(p)
+	h.hook()
+	return n, err
+}
+
+func TestReaderCopiesEverything(t *testing.T) {
+	src := strings.Repeat("go", 100_000)
+
+	var dst bytes.Buffer
+	n, err := io.Copy(&dst, ctxio.NewReader(context.Background(), strings.NewReader(src)))
+
+	if err != nil || n != int64(len(src)) || dst.String() != src {
+		t.Errorf("want %d bytes, nil; got %d, %v", len(src), n, err)
+	}
+}
+
+func TestReaderStopsMidCopy(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel before the third read of the source
+	reads := 0
+	src := &hookReader{
+		r: io.LimitReader(zeros{}, 1<<20),
+		hook: func() {
+			reads++
+			if reads == 3 {
+				cancel()
+			}
+		},
+	}
+
+	// Hide bytes.Buffer's ReadFrom so the copy uses buf's size
+	var dst bytes.Buffer
+	buf := make([]byte, 1024)
+	n, err := io.CopyBuffer(struct{ io.Writer }{&dst}, ctxio.NewReader(ctx, src), buf)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want Canceled; got %v", err)
+	}
+	// The third read was already in progress when ctx was cancelled,
+	// so it completes; the fourth never starts
+	if n != 3*1024 {
+		t.Errorf("want 3072 bytes copied; got %d", n)
+	}
+}
+
+func TestReaderReturnsCause(t *testing.T) {
+	errStop := errors.New("user pressed stop")
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	cancel(errStop)
+
+	_, err := ctxio.NewReader(ctx, strings.NewReader("data")).Read(make([]byte, 4))
+	if !errors.Is(err, errStop) {
+		t.Errorf("want the cause; got %v", err)
+	}
+}
+
+func TestCopyCannotBypassChecks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// bytes.Reader has WriteTo, which io.Copy would use if the wrapper
+	// exposed it, and the context would never be checked
+	n, err := io.Copy(io.Discard, ctxio.NewReader(ctx, bytes.NewReader(make([]byte, 1024))))
+
+	if n != 0 || !errors.Is(err, context.Canceled) {
+		t.Errorf("want 0, Canceled; got %d, %v", n, err)
+	}
+}
+
+func TestWriterWritesEverything(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 100_000)
+
+	var dst bytes.Buffer
+	n, err := ctxio.NewWriter(context.Background(), &dst).Write(data)
+
+	if err != nil || n != len(data) || !bytes.Equal(dst.Bytes(), data) {
+		t.Errorf("want %d bytes, nil; got %d, %v", len(data), n, err)
+	}
+}
+
+func TestWriterStopsInsideLargeWrite(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// Cancel after the first chunk reaches the destination
+	var dst bytes.Buffer
+	w := ctxio.NewWriter(ctx, &hookWriter{w: &dst, hook: cancel})
+
+	data := make([]byte, 1<<20)
+	n, err := w.Write(data)
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("want Canceled; got %v", err)
+	}
+	if n == 0 || n >= len(data) {
+		t.Errorf("want a partial write; got %d of %d bytes", n, len(data))
+	}
+	if n != dst.Len() {
+		t.Errorf("reported %d bytes, but %d were written", n, dst.Len())
+	}
+}
+
+func TestWriterPropagatesWriteErrors(t *testing.T) {
+	errDisk := errors.New("disk full")
+
+	_, err := ctxio.NewWriter(context.Background(), failWriter{errDisk}).Write([]byte("x"))
+	if !errors.Is(err, errDisk) {
+		t.Errorf("want errDisk; got %v", err)
+	}
+}
+
+// zeros is an endless source of zero bytes
+type zeros struct{}
+
+func (zeros) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
+
+// failWriter fails every write with err
+type failWriter struct{ err error }
+
+func (f failWriter) Write([]byte) (int, error) { return 0, f.err }
@@ -0,0 +1,75 @@
+// Package ctxmeta stores request-scoped values in a context with keys
+// that carry their value's type.
+//
+// With plain context.WithValue every lookup is an interface{} followed
+// by a type assertion, and nothing stops two packages from using the
+// same key or a caller from storing the wrong type. A Key[T] fixes all
+// three: each key created by NewKey is unique, and the compiler checks
+// the values stored and returned:
+//
+//	var RequestID = ctxmeta.NewKey[string]("request-id")
+//
+//	ctx = ctxmeta.WithValue(ctx, RequestID, "req-42")
+//	id, ok := ctxmeta.Value(ctx, RequestID) // id is a string
+//
+// The values live in an ordinary context, so they pass through any code
+// that accepts a context.Context.
+package ctxmeta
+
+import "context"
+
+// Key identifies a value of type T in a context. Create keys with
+// NewKey; the zero Key is not usable.
+//
+// Copies of a Key are the same key, so a Key can be stored in a
+// package-level variable and shared.
+type Key[T any] struct {
+	id *keyID
+}
+
+// keyID gives every key its own identity: two keys are equal only if
+// they point to the same keyID, even when their names are the same.
+type keyID struct {
+	name string
+}
+
+// NewKey returns a new key for values of type T. The name is only used
+// for debugging; it does not need to be unique.
+func NewKey[T any](name string) Key[T] {
+	return Key[T]{id: &keyID{name: name}}
+}
+
+// String returns the key's name. The context package uses it when a
+// context is printed.
+func (k Key[T]) String() string {
+	if k.id == nil {
+		return "ctxmeta.Key(<zero>)"
+	}
+	return k.id.name
+}
+
+// WithValue returns a copy of ctx that holds v under k. It panics if k
+// is the zero Key.
+func WithValue[T any](ctx context.Context, k Key[T], v T) context.Context {
+	if k.id == nil {
+		panic("ctxmeta: WithValue with the zero Key")
+	}
+	return context.WithValue(ctx, k, v)
+}
+
+// Value returns the value stored under k in ctx and true, or the zero
+// value of T and false if there is none. When T is an interface type,
+// a stored nil is reported as none.
+func Value[T any](ctx context.Context, k Key[T]) (T, bool) {
+	v, ok := ctx.Value(k).(T)
+	return v, ok
+}
+
+// ValueOr returns the value stored under k in ctx, or def if there is
+// none.
+func ValueOr[T any](ctx context.Context, k Key[T], def T) T {
+	if v, ok := Value(ctx, k); ok {
+		return v
+	}
+	return def
+}
@@ -0,0 +1,127 @@
+package ctxmeta_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/ctxmeta"
+)
+
+func TestValueRoundTrip(t *testing.T) {
+	key := ctxmeta.NewKey[int]("answer")
+	ctx := ctxmeta.WithValue(context.Background(), key, 42)
+
+	got, ok := ctxmeta.Value(ctx, key)
+	if !ok || got != 42 {
+		t.Errorf("want 42, true; got %v, %v", got, ok)
+	}
+}
+
+func TestValueMissing(t *testing.T) {
+	key := ctxmeta.NewKey[string]("missing")
+
+	got, ok := ctxmeta.Value(context.Background(), key)
+	if ok || got != "" {
+		t.Errorf("want the zero value and false; got %q, %v", got, ok)
+	}
+	if got := ctxmeta.ValueOr(context.Background(), key, "default"); got != "default" {
+		t.Errorf("want default; got %q", got)
+	}
+}
+
+func TestKeysWithTheSameNameDoNotCollide(t *testing.T) {
+	a := ctxmeta.NewKey[string]("id")
+	b := ctxmeta.NewKey[string]("id")
+
+	ctx := ctxmeta.WithValue(context.Background(), a, "from a")
+	ctx = ctxmeta.WithValue(ctx, b, "from b")
+
+	if got, _ := ctxmeta.Value(ctx, a); got != "from a" {
+		t.Errorf("key a: want %q; got %q", "from a", got)
+	}
+	if got, _ := ctxmeta.Value(ctx, b); got != "from b" {
+		t.Errorf("key b: want %q; got %q", "from b", got)
+	}
+}
+
+func TestPlainStringKeyDoesNotCollide(t *testing.T) {
+	key := ctxmeta.NewKey[string]("user")
+
+	// A plain string key, as another package might use
+	ctx := context.WithValue(context.Background(), "user", "intruder")
+
+	if _, ok := ctxmeta.Value(ctx, key); ok {
+		t.Error("want a string key to be a different key")
+	}
+}
+
+func TestCopiesOfAKeyAreTheSameKey(t *testing.T) {
+	key := ctxmeta.NewKey[int]("n")
+	copied := key
+
+	ctx := ctxmeta.WithValue(context.Background(), key, 7)
+	if got, ok := ctxmeta.Value(ctx, copied); !ok || got != 7 {
+		t.Errorf("want 7, true; got %v, %v", got, ok)
+	}
+}
+
+func TestNearestValueWins(t *testing.T) {
+	key := ctxmeta.NewKey[string]("user")
+
+	parent := ctxmeta.WithValue(context.Background(), key, "parent")
+	child := ctxmeta.WithValue(parent, key, "child")
+
+	if got := ctxmeta.ValueOr(child, key, ""); got != "child" {
+		t.Errorf("child: want child; got %q", got)
+	}
+	if got := ctxmeta.ValueOr(parent, key, ""); got != "parent" {
+		t.Errorf("parent: want parent; got %q", got)
+	}
+}
+
+func TestValuesSurviveDerivedContexts(t *testing.T) {
+	key := ctxmeta.NewKey[[]string]("roles")
+	ctx := ctxmeta.WithValue(context.Background(), key, []string{"admin"})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	roles, ok := ctxmeta.Value(ctx, key)
+	if !ok || len(roles) != 1 || roles[0] != "admin" {
+		t.Errorf("want [admin], true; got %v, %v", roles, ok)
+	}
+}
+
+func TestNilInterfaceValue(t *testing.T) {
+	key := ctxmeta.NewKey[error]("err")
+	ctx := ctxmeta.WithValue(context.Background(), key, nil)
+
+	// A stored nil interface cannot be told apart from no value
+	if _, ok := ctxmeta.Value(ctx, key); ok {
+		t.Error("want a nil interface value to report false")
+	}
+}
+
+func TestZeroKeyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic for the zero Key")
+		}
+	}()
+	ctxmeta.WithValue(context.Background(), ctxmeta.Key[int]{}, 1)
+}
+
+func TestKeyString(t *testing.T) {
+	key := ctxmeta.NewKey[int]("request-id")
+	if got := key.String(); got != "request-id" {
+		t.Errorf("want request-id; got %q", got)
+	}
+
+	// The context package prints the key's name
+	ctx := ctxmeta.WithValue(context.Background(), key, 1)
+	if s := fmt.Sprint(ctx); !strings.Contains(s, "request-id") {
+		t.Errorf("want the key name in %q", s)
+	}
+}
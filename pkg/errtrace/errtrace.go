@@ -0,0 +1,142 @@
+// Package errtrace records where an error was created or first wrapped.
+//
+// Wrapping with fmt.Errorf("...: %w", err) tells the story of an error
+// in words, but not where it happened. New and Wrap also capture the
+// caller's stack, which the %+v verb prints:
+//
+//	func loadConfig(name string) ([]byte, error) {
+//		data, err := os.ReadFile(name)
+//		if err != nil {
+//			return nil, errtrace.Wrap(err)
+//		}
+//		return data, nil
+//	}
+//
+//	_, err := loadConfig("config.json")
+//	fmt.Printf("%+v\n", err)
+//	// open config.json: no such file or directory
+//	// main.loadConfig
+//	//	/app/main.go:42
+//	// ...
+//
+// The errors work with errors.Is, errors.As, and errors.Unwrap like any
+// other wrapped error. Only the first trace in a chain is kept: Wrap
+// returns an error that already carries one as it is, so the trace
+// always points to where the error started.
+package errtrace
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+)
+
+// maxDepth is the most frames captured for one error.
+const maxDepth = 32
+
+// Frame is one function call in a stack trace.
+type Frame struct {
+	Function string
+	File     string
+	Line     int
+}
+
+// String returns the frame as "function (file:line)".
+func (f Frame) String() string {
+	return fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+}
+
+// Error is an error with the stack where it was created.
+type Error struct {
+	msg string // set by New
+	err error  // set by Wrap
+	pcs []uintptr
+}
+
+// New returns an error with the message msg and the caller's stack.
+func New(msg string) error {
+	return &Error{msg: msg, pcs: callers()}
+}
+
+// Wrap returns err with the caller's stack. It returns nil if err is
+// nil, and err itself if err already carries a stack.
+//
+// Wrap adds no message; Error returns err's message unchanged.
+func Wrap(err error) error {
+	if err == nil {
+		return nil
+	}
+	var traced *Error
+	if errors.As(err, &traced) {
+		return err
+	}
+	return &Error{err: err, pcs: callers()}
+}
+
+// callers captures the stack of the function that called New or Wrap.
+func callers() []uintptr {
+	pcs := make([]uintptr, maxDepth)
+	// Skip runtime.Callers, callers, and New or Wrap
+	n := runtime.Callers(3, pcs)
+	return pcs[:n]
+}
+
+// Error returns the message, without the stack.
+func (e *Error) Error() string {
+	if e.err != nil {
+		return e.err.Error()
+	}
+	return e.msg
+}
+
+// Unwrap returns the wrapped error, or nil for an error made by New.
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Frames returns the captured stack, innermost call first.
+func (e *Error) Frames() []Frame {
+	var frames []Frame
+	it := runtime.CallersFrames(e.pcs)
+	for {
+		f, more := it.Next()
+		frames = append(frames, Frame{Function: f.Function, File: f.File, Line: f.Line})
+		if !more {
+			return frames
+		}
+	}
+}
+
+// Format implements fmt.Formatter. %s and %v print the message, %q
+// prints it quoted, and %+v prints the message followed by the stack.
+func (e *Error) Format(s fmt.State, verb rune) {
+	switch verb {
+	case 'v':
+		io.WriteString(s, e.Error())
+		if s.Flag('+') {
+			for _, f := range e.Frames() {
+				fmt.Fprintf(s, "\n%s\n\t%s:%d", f.Function, f.File, f.Line)
+			}
+		}
+	case 's':
+		io.WriteString(s, e.Error())
+	case 'q':
+		fmt.Fprintf(s, "%q", e.Error())
+	default:
+		fmt.Fprintf(s, "%%!%c(errtrace.Error=%s)", verb, e.Error())
+	}
+}
+
+// Frames returns the stack of the first error in err's chain that
+// carries one, or nil if none does.
+//
+// Use it when err was wrapped again with fmt.Errorf, whose %+v output
+// does not include the stack.
+func Frames(err error) []Frame {
+	var traced *Error
+	if errors.As(err, &traced) {
+		return traced.Frames()
+	}
+	return nil
+}
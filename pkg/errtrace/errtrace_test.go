@@ -0,0 +1,132 @@
+package errtrace_test
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/errtrace"
+)
+
+// origin creates an error two calls away from the test
+func origin() error { return errtrace.New("boom") }
+func middle() error { return origin() }
+
+func TestNewCapturesCaller(t *testing.T) {
+	frames := errtrace.Frames(middle())
+	if len(frames) < 3 {
+		t.Fatalf("want at least 3 frames; got %v", frames)
+	}
+
+	want := []string{"errtrace_test.origin", "errtrace_test.middle", "errtrace_test.TestNewCapturesCaller"}
+	for i, name := range want {
+		if !strings.HasSuffix(frames[i].Function, name) {
+			t.Errorf("frame %d: want %s; got %s", i, name, frames[i].Function)
+		}
+		if !strings.HasSuffix(frames[i].File, "errtrace_test.go") || frames[i].Line == 0 {
+			t.Errorf("frame %d: want a line in errtrace_test.go; got %s:%d", i, frames[i].File, frames[i].Line)
+		}
+	}
+}
+
+func TestWrapNil(t *testing.T) {
+	if err := errtrace.Wrap(nil); err != nil {
+		t.Errorf("want nil; got %v", err)
+	}
+}
+
+func TestWrapKeepsMessageAndChain(t *testing.T) {
+	_, err := os.ReadFile("does-not-exist.txt")
+	wrapped := errtrace.Wrap(err)
+
+	if wrapped.Error() != err.Error() {
+		t.Errorf("want message %q; got %q", err, wrapped)
+	}
+	if !errors.Is(wrapped, fs.ErrNotExist) {
+		t.Error("want errors.Is to find fs.ErrNotExist")
+	}
+	var pathErr *fs.PathError
+	if !errors.As(wrapped, &pathErr) || pathErr.Path != "does-not-exist.txt" {
+		t.Errorf("want errors.As to find the *fs.PathError; got %v", pathErr)
+	}
+	if errors.Unwrap(wrapped) != err {
+		t.Error("want Unwrap to return the original error")
+	}
+}
+
+func TestNewHasNothingToUnwrap(t *testing.T) {
+	if errors.Unwrap(errtrace.New("x")) != nil {
+		t.Error("want nil")
+	}
+}
+
+func TestIsWithSentinel(t *testing.T) {
+	errNotFound := errtrace.New("not found")
+	err := fmt.Errorf("loading user: %w", errNotFound)
+
+	if !errors.Is(err, errNotFound) {
+		t.Error("want errors.Is to match the traced sentinel")
+	}
+}
+
+func TestWrapKeepsFirstTrace(t *testing.T) {
+	first := middle()
+	again := errtrace.Wrap(fmt.Errorf("context: %w", first))
+
+	frames := errtrace.Frames(again)
+	if len(frames) == 0 || !strings.HasSuffix(frames[0].Function, ".origin") {
+		t.Errorf("want the trace from origin; got %v", frames)
+	}
+
+	// Nothing new was added: the %w wrapper is returned as is
+	if again.Error() != "context: boom" {
+		t.Errorf("want %q; got %q", "context: boom", again)
+	}
+}
+
+func TestFramesWithoutTrace(t *testing.T) {
+	if frames := errtrace.Frames(errors.New("plain")); frames != nil {
+		t.Errorf("want nil; got %v", frames)
+	}
+	if frames := errtrace.Frames(nil); frames != nil {
+		t.Errorf("want nil; got %v", frames)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	err := middle()
+
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%v", "boom"},
+		{"%s", "boom"},
+		{"%q", `"boom"`},
+	}
+	for _, tt := range tests {
+		if got := fmt.Sprintf(tt.format, err); got != tt.want {
+			t.Errorf("%s: want %s; got %s", tt.format, tt.want, got)
+		}
+	}
+
+	// %+v: the message, then a function line and a file:line line per frame
+	lines := strings.Split(fmt.Sprintf("%+v", err), "\n")
+	if lines[0] != "boom" {
+		t.Fatalf("want the message first; got %q", lines[0])
+	}
+	if len(lines) < 5 || !strings.HasSuffix(lines[1], ".origin") || !strings.HasPrefix(lines[2], "\t") || !strings.Contains(lines[2], "errtrace_test.go:") {
+		t.Errorf("unexpected stack:\n%s", strings.Join(lines, "\n"))
+	}
+}
+
+func TestPlusVOnlyOnTheTracedError(t *testing.T) {
+	// fmt's own wrapper does not know about the stack
+	err := fmt.Errorf("outer: %w", middle())
+	if got := fmt.Sprintf("%+v", err); got != "outer: boom" {
+		t.Errorf("want %q; got %q", "outer: boom", got)
+	}
+}
@@ -0,0 +1,206 @@
+package generics
+
+import "iter"
+
+// minDequeCap is the size of a deque's first buffer.
+const minDequeCap = 8
+
+// Deque is a double-ended queue: items can be added and removed at both
+// ends in amortized O(1). The zero value is an empty deque ready to use.
+//
+// The items live in a circular buffer that doubles when it is full, so
+// popping from the front never shifts the other items, unlike
+// s = s[1:] on a slice.
+//
+// A Deque is not safe for concurrent use.
+type Deque[T any] struct {
+	buf  []T // len(buf) is zero or a power of two
+	head int // index of the front item
+	n    int // number of items
+}
+
+// Len returns the number of items.
+func (d *Deque[T]) Len() int {
+	return d.n
+}
+
+// PushBack adds item at the back.
+func (d *Deque[T]) PushBack(item T) {
+	d.grow()
+	d.buf[d.index(d.n)] = item
+	d.n++
+}
+
+// PushFront adds item at the front.
+func (d *Deque[T]) PushFront(item T) {
+	d.grow()
+	d.head = d.index(len(d.buf) - 1) // one step back, wrapping around
+	d.buf[d.head] = item
+	d.n++
+}
+
+// PopFront removes and returns the front item. It returns the zero
+// value and false if the deque is empty.
+func (d *Deque[T]) PopFront() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	item := d.buf[d.head]
+	d.buf[d.head] = zero // don't keep a reference to a popped item
+	d.head = d.index(1)
+	d.n--
+	return item, true
+}
+
+// PopBack removes and returns the back item. It returns the zero value
+// and false if the deque is empty.
+func (d *Deque[T]) PopBack() (T, bool) {
+	var zero T
+	if d.n == 0 {
+		return zero, false
+	}
+	i := d.index(d.n - 1)
+	item := d.buf[i]
+	d.buf[i] = zero
+	d.n--
+	return item, true
+}
+
+// Front returns the front item without removing it.
+func (d *Deque[T]) Front() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.head], true
+}
+
+// Back returns the back item without removing it.
+func (d *Deque[T]) Back() (T, bool) {
+	if d.n == 0 {
+		var zero T
+		return zero, false
+	}
+	return d.buf[d.index(d.n-1)], true
+}
+
+// At returns the i'th item from the front. It panics if i is out of
+// range.
+func (d *Deque[T]) At(i int) T {
+	if i < 0 || i >= d.n {
+		panic("generics: Deque index out of range")
+	}
+	return d.buf[d.index(i)]
+}
+
+// Iter returns an iterator over the items, from front to back.
+func (d *Deque[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range d.n {
+			if !yield(d.buf[d.index(i)]) {
+				return
+			}
+		}
+	}
+}
+
+// index returns the position in buf of the i'th item from the front.
+// Since len(buf) is a power of two, a mask does the wrap-around.
+func (d *Deque[T]) index(i int) int {
+	return (d.head + i) & (len(d.buf) - 1)
+}
+
+// grow doubles the buffer if it is full, and moves the items to its
+// start in order.
+func (d *Deque[T]) grow() {
+	if d.n < len(d.buf) {
+		return
+	}
+
+	buf := make([]T, max(minDequeCap, 2*len(d.buf)))
+	// The items may wrap around the end: copy both parts
+	n := copy(buf, d.buf[d.head:])
+	copy(buf[n:], d.buf[:d.head])
+
+	d.buf = buf
+	d.head = 0
+}
+
+// Ring is a fixed-size circular buffer: a queue that never grows. When
+// it is full, Push refuses new items, and PushOverwrite drops the oldest
+// to make room.
+//
+// Refusing and dropping are the two ways to put a limit on a producer
+// that is faster than its consumer.
+//
+// A Ring is not safe for concurrent use.
+type Ring[T any] struct {
+	buf  []T
+	head int
+	n    int
+}
+
+// NewRing returns an empty ring that holds at most capacity items. It
+// panics if capacity is less than one.
+func NewRing[T any](capacity int) *Ring[T] {
+	if capacity < 1 {
+		panic("generics: Ring capacity must be at least 1")
+	}
+	return &Ring[T]{buf: make([]T, capacity)}
+}
+
+// Len returns the number of items.
+func (r *Ring[T]) Len() int { return r.n }
+
+// Cap returns the most items the ring can hold.
+func (r *Ring[T]) Cap() int { return len(r.buf) }
+
+// Full reports whether the ring holds Cap items.
+func (r *Ring[T]) Full() bool { return r.n == len(r.buf) }
+
+// Push adds item at the back, and reports whether there was room. A full
+// ring is left unchanged.
+func (r *Ring[T]) Push(item T) bool {
+	if r.Full() {
+		return false
+	}
+	r.buf[(r.head+r.n)%len(r.buf)] = item
+	r.n++
+	return true
+}
+
+// PushOverwrite adds item at the back. If the ring is full, it drops the
+// oldest item and returns it with true.
+func (r *Ring[T]) PushOverwrite(item T) (dropped T, ok bool) {
+	if r.Full() {
+		dropped, ok = r.Pop()
+	}
+	r.Push(item)
+	return dropped, ok
+}
+
+// Pop removes and returns the oldest item. It returns the zero value and
+// false if the ring is empty.
+func (r *Ring[T]) Pop() (T, bool) {
+	var zero T
+	if r.n == 0 {
+		return zero, false
+	}
+	item := r.buf[r.head]
+	r.buf[r.head] = zero
+	r.head = (r.head + 1) % len(r.buf)
+	r.n--
+	return item, true
+}
+
+// Iter returns an iterator over the items, from oldest to newest.
+func (r *Ring[T]) Iter() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for i := range r.n {
+			if !yield(r.buf[(r.head+i)%len(r.buf)]) {
+				return
+			}
+		}
+	}
+}
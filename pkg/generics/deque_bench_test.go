@@ -0,0 +1,91 @@
+package generics_test
+
+import (
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+// sliceStack is the Stack from 28-generics/02-generic-types. That lesson
+// is its own module, so the type is copied here to compare against.
+type sliceStack[T any] struct {
+	items []T
+}
+
+func (s *sliceStack[T]) Push(item T) {
+	s.items = append(s.items, item)
+}
+
+func (s *sliceStack[T]) Pop() (T, bool) {
+	if len(s.items) == 0 {
+		var zero T
+		return zero, false
+	}
+	index := len(s.items) - 1
+	item := s.items[index]
+	s.items = s.items[:index]
+	return item, true
+}
+
+// benchN is how many items each iteration pushes and then pops.
+const benchN = 1024
+
+// As a stack, both push and pop at one end: append is hard to beat.
+
+func BenchmarkStackSlice(b *testing.B) {
+	for b.Loop() {
+		var s sliceStack[int]
+		for i := range benchN {
+			s.Push(i)
+		}
+		for range benchN {
+			s.Pop()
+		}
+	}
+}
+
+func BenchmarkStackDeque(b *testing.B) {
+	for b.Loop() {
+		var d generics.Deque[int]
+		for i := range benchN {
+			d.PushBack(i)
+		}
+		for range benchN {
+			d.PopBack()
+		}
+	}
+}
+
+// As a queue, a slice pops with s = s[1:]. The front of the array is
+// never reused, so a long-running queue keeps reallocating. The deque
+// reuses its buffer.
+
+func BenchmarkQueueSlice(b *testing.B) {
+	var q []int
+	for b.Loop() {
+		for i := range benchN {
+			q = append(q, i)
+			q = q[1:]
+		}
+	}
+}
+
+func BenchmarkQueueDeque(b *testing.B) {
+	var d generics.Deque[int]
+	for b.Loop() {
+		for i := range benchN {
+			d.PushBack(i)
+			d.PopFront()
+		}
+	}
+}
+
+func BenchmarkQueueRing(b *testing.B) {
+	r := generics.NewRing[int](benchN)
+	for b.Loop() {
+		for i := range benchN {
+			r.Push(i)
+			r.Pop()
+		}
+	}
+}
@@ -0,0 +1,217 @@
+package generics_test
+
+import (
+	"math/rand/v2"
+	"slices"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+func TestDequeBothEnds(t *testing.T) {
+	var d generics.Deque[int] // the zero value is ready to use
+
+	d.PushBack(2)
+	d.PushBack(3)
+	d.PushFront(1)
+	d.PushFront(0)
+
+	if got := slices.Collect(d.Iter()); !slices.Equal(got, []int{0, 1, 2, 3}) {
+		t.Fatalf("want [0 1 2 3]; got %v", got)
+	}
+	if front, _ := d.Front(); front != 0 {
+		t.Errorf("want front 0; got %d", front)
+	}
+	if back, _ := d.Back(); back != 3 {
+		t.Errorf("want back 3; got %d", back)
+	}
+	if got := d.At(2); got != 2 {
+		t.Errorf("want At(2) = 2; got %d", got)
+	}
+
+	if v, _ := d.PopFront(); v != 0 {
+		t.Errorf("want PopFront 0; got %d", v)
+	}
+	if v, _ := d.PopBack(); v != 3 {
+		t.Errorf("want PopBack 3; got %d", v)
+	}
+	if d.Len() != 2 {
+		t.Errorf("want len 2; got %d", d.Len())
+	}
+}
+
+func TestDequeEmpty(t *testing.T) {
+	var d generics.Deque[string]
+
+	if _, ok := d.PopFront(); ok {
+		t.Error("want PopFront on empty to fail")
+	}
+	if _, ok := d.PopBack(); ok {
+		t.Error("want PopBack on empty to fail")
+	}
+	if _, ok := d.Front(); ok {
+		t.Error("want Front on empty to fail")
+	}
+	if _, ok := d.Back(); ok {
+		t.Error("want Back on empty to fail")
+	}
+}
+
+func TestDequeAtPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want At out of range to panic")
+		}
+	}()
+	var d generics.Deque[int]
+	d.PushBack(1)
+	d.At(1)
+}
+
+// TestDequeGrowWrapped grows the buffer while the items wrap around its
+// end, which is when grow has to copy two parts.
+func TestDequeGrowWrapped(t *testing.T) {
+	var d generics.Deque[int]
+	for i := range 6 {
+		d.PushBack(i)
+	}
+	for range 4 {
+		d.PopFront()
+	}
+	for i := 6; i < 20; i++ {
+		d.PushBack(i)
+	}
+
+	want := []int{4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19}
+	if got := slices.Collect(d.Iter()); !slices.Equal(got, want) {
+		t.Errorf("want %v\ngot  %v", want, got)
+	}
+}
+
+// TestDequeModel runs random operations on a deque and on a plain slice,
+// and checks that they always agree.
+func TestDequeModel(t *testing.T) {
+	r := rand.New(rand.NewPCG(1, 2))
+	var d generics.Deque[int]
+	var model []int
+
+	for i := range 10_000 {
+		switch r.IntN(4) {
+		case 0:
+			d.PushBack(i)
+			model = append(model, i)
+		case 1:
+			d.PushFront(i)
+			model = slices.Insert(model, 0, i)
+		case 2:
+			v, ok := d.PopFront()
+			if ok != (len(model) > 0) {
+				t.Fatalf("step %d: PopFront ok = %v with %d items", i, ok, len(model))
+			}
+			if ok {
+				if v != model[0] {
+					t.Fatalf("step %d: PopFront = %d; want %d", i, v, model[0])
+				}
+				model = model[1:]
+			}
+		case 3:
+			v, ok := d.PopBack()
+			if ok != (len(model) > 0) {
+				t.Fatalf("step %d: PopBack ok = %v with %d items", i, ok, len(model))
+			}
+			if ok {
+				if last := model[len(model)-1]; v != last {
+					t.Fatalf("step %d: PopBack = %d; want %d", i, v, last)
+				}
+				model = model[:len(model)-1]
+			}
+		}
+
+		if d.Len() != len(model) {
+			t.Fatalf("step %d: len %d; want %d", i, d.Len(), len(model))
+		}
+	}
+
+	if got := slices.Collect(d.Iter()); !slices.Equal(got, model) {
+		t.Errorf("final items differ:\nwant %v\ngot  %v", model, got)
+	}
+}
+
+func TestDequeIterStops(t *testing.T) {
+	var d generics.Deque[int]
+	for i := range 5 {
+		d.PushBack(i)
+	}
+
+	var got []int
+	for v := range d.Iter() {
+		if v == 2 {
+			break
+		}
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{0, 1}) {
+		t.Errorf("want [0 1]; got %v", got)
+	}
+}
+
+func TestRingPush(t *testing.T) {
+	r := generics.NewRing[int](3)
+
+	for i := 1; i <= 3; i++ {
+		if !r.Push(i) {
+			t.Fatalf("want room for %d", i)
+		}
+	}
+	if !r.Full() || r.Len() != 3 || r.Cap() != 3 {
+		t.Fatalf("want a full ring of 3; got len %d cap %d", r.Len(), r.Cap())
+	}
+	if r.Push(4) {
+		t.Error("want Push on a full ring to fail")
+	}
+	if got := slices.Collect(r.Iter()); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("want a refused push to leave [1 2 3]; got %v", got)
+	}
+
+	// Pop makes room, and the next Push wraps around
+	if v, _ := r.Pop(); v != 1 {
+		t.Errorf("want Pop 1; got %d", v)
+	}
+	r.Push(4)
+	if got := slices.Collect(r.Iter()); !slices.Equal(got, []int{2, 3, 4}) {
+		t.Errorf("want [2 3 4]; got %v", got)
+	}
+}
+
+func TestRingPushOverwrite(t *testing.T) {
+	r := generics.NewRing[string](2)
+
+	if _, dropped := r.PushOverwrite("a"); dropped {
+		t.Error("want nothing dropped while there is room")
+	}
+	r.PushOverwrite("b")
+
+	old, dropped := r.PushOverwrite("c")
+	if !dropped || old != "a" {
+		t.Errorf("want the oldest, a, dropped; got %q, %v", old, dropped)
+	}
+	if got := slices.Collect(r.Iter()); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("want [b c]; got %v", got)
+	}
+}
+
+func TestRingEmpty(t *testing.T) {
+	r := generics.NewRing[int](1)
+	if _, ok := r.Pop(); ok {
+		t.Error("want Pop on empty to fail")
+	}
+}
+
+func TestNewRingPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want NewRing(0) to panic")
+		}
+	}()
+	generics.NewRing[int](0)
+}
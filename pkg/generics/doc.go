@@ -0,0 +1,8 @@
+// Package generics holds the generic data structures built in the
+// 28-generics section, in a form other sections can import.
+//
+// The types follow the conventions of the standard library's slices and
+// maps packages: iteration returns iter.Seq values that work with
+// range, and functions that need more than comparable, such as sorting,
+// are plain functions instead of methods.
+package generics
@@ -0,0 +1,180 @@
+package generics_test
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+// Iterators from different types chain together: a set's items, sorted,
+// then filtered and transformed lazily.
+func Example() {
+	langs := generics.NewSet("go", "rust", "zig", "go", "c")
+
+	short := generics.FilterSeq(slices.Values(generics.Sorted(langs)),
+		func(s string) bool { return len(s) <= 2 })
+	upper := generics.MapSeq(short, strings.ToUpper)
+
+	fmt.Println(slices.Collect(upper))
+	// Output:
+	// [C GO]
+}
+
+func ExampleDeque() {
+	var d generics.Deque[string]
+	d.PushBack("b")
+	d.PushBack("c")
+	d.PushFront("a")
+
+	fmt.Println(slices.Collect(d.Iter()))
+
+	front, _ := d.PopFront()
+	back, _ := d.PopBack()
+	fmt.Println(front, back, d.Len())
+	// Output:
+	// [a b c]
+	// a c 1
+}
+
+// A deque is a stack when both pushes and pops use the same end.
+func ExampleDeque_stack() {
+	var stack generics.Deque[int]
+	for i := range 3 {
+		stack.PushBack(i)
+	}
+	for stack.Len() > 0 {
+		top, _ := stack.PopBack()
+		fmt.Print(top, " ")
+	}
+	fmt.Println()
+	// Output:
+	// 2 1 0
+}
+
+func ExampleRing_Push() {
+	r := generics.NewRing[int](2)
+	for i := range 3 {
+		fmt.Println(i, r.Push(i))
+	}
+	fmt.Println(slices.Collect(r.Iter()))
+	// Output:
+	// 0 true
+	// 1 true
+	// 2 false
+	// [0 1]
+}
+
+func ExampleRing_PushOverwrite() {
+	r := generics.NewRing[string](2)
+	for _, s := range []string{"a", "b", "c"} {
+		if dropped, ok := r.PushOverwrite(s); ok {
+			fmt.Println("dropped", dropped)
+		}
+	}
+	fmt.Println(slices.Collect(r.Iter()))
+	// Output:
+	// dropped a
+	// [b c]
+}
+
+func ExampleSet() {
+	s := generics.NewSet(1, 2, 3)
+	s.Add(3, 4)
+	s.Remove(1)
+
+	fmt.Println(s.Len(), s.Contains(1), s.Contains(4))
+	// Output:
+	// 3 false true
+}
+
+// A set has no order, so neither does its iterator. An unordered output
+// block accepts the lines in any order.
+func ExampleSet_Iter() {
+	s := generics.NewSet("x", "y", "z")
+	for item := range s.Iter() {
+		fmt.Println(item)
+	}
+	// Unordered output:
+	// z
+	// x
+	// y
+}
+
+func ExampleSet_Union() {
+	a := generics.NewSet(1, 2, 3)
+	b := generics.NewSet(3, 4)
+
+	fmt.Println(generics.Sorted(a.Union(b)))
+	fmt.Println(generics.Sorted(a.Intersection(b)))
+	fmt.Println(generics.Sorted(a.Difference(b)))
+	// Output:
+	// [1 2 3 4]
+	// [3]
+	// [1 2]
+}
+
+func ExampleSorted() {
+	s := generics.NewSet("pear", "apple", "fig")
+	fmt.Println(generics.Sorted(s))
+	// Output:
+	// [apple fig pear]
+}
+
+func ExampleFilterSeq() {
+	odd := generics.FilterSeq(slices.Values([]int{1, 2, 3, 4, 5}),
+		func(n int) bool { return n%2 == 1 })
+	fmt.Println(slices.Collect(odd))
+	// Output:
+	// [1 3 5]
+}
+
+func ExampleMapSeq() {
+	lengths := generics.MapSeq(slices.Values([]string{"a", "bb", "ccc"}),
+		func(s string) int { return len(s) })
+	fmt.Println(slices.Collect(lengths))
+	// Output:
+	// [1 2 3]
+}
+
+func ExampleReduceSeq() {
+	sum := generics.ReduceSeq(slices.Values([]int{1, 2, 3, 4}), 0,
+		func(acc, n int) int { return acc + n })
+	fmt.Println(sum)
+	// Output:
+	// 10
+}
+
+// Take stops an infinite iterator.
+func ExampleTake() {
+	powers := func(yield func(int) bool) {
+		for n := 1; yield(n); n *= 2 {
+		}
+	}
+	fmt.Println(slices.Collect(generics.Take(powers, 5)))
+	// Output:
+	// [1 2 4 8 16]
+}
+
+func ExampleZip() {
+	names := slices.Values([]string{"ann", "bob", "cid"})
+	ages := slices.Values([]int{31, 42})
+
+	for name, age := range generics.Zip(names, ages) {
+		fmt.Println(name, age)
+	}
+	// Output:
+	// ann 31
+	// bob 42
+}
+
+func ExampleChunk() {
+	for batch := range generics.Chunk(slices.Values([]int{1, 2, 3, 4, 5}), 2) {
+		fmt.Println(batch)
+	}
+	// Output:
+	// [1 2]
+	// [3 4]
+	// [5]
+}
@@ -0,0 +1,100 @@
+package generics
+
+import "iter"
+
+// FilterSeq returns an iterator over the items of seq that keep returns
+// true for. Like the other Seq functions, it does no work until the
+// result is ranged over, and then only as much as the loop asks for.
+func FilterSeq[T any](seq iter.Seq[T], keep func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if keep(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// MapSeq returns an iterator over fn applied to each item of seq.
+func MapSeq[T, U any](seq iter.Seq[T], fn func(T) U) iter.Seq[U] {
+	return func(yield func(U) bool) {
+		for v := range seq {
+			if !yield(fn(v)) {
+				return
+			}
+		}
+	}
+}
+
+// ReduceSeq combines the items of seq into one value, starting from
+// initial. It ranges over all of seq, so seq must be finite.
+func ReduceSeq[T, U any](seq iter.Seq[T], initial U, fn func(U, T) U) U {
+	acc := initial
+	for v := range seq {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// Take returns an iterator over the first n items of seq. It stops
+// ranging over seq after the n'th item, so seq can be infinite.
+func Take[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+		i := 0
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i == n {
+				return
+			}
+		}
+	}
+}
+
+// Zip returns an iterator over pairs of items from a and b, in step. It
+// stops when either one ends.
+func Zip[A, B any](a iter.Seq[A], b iter.Seq[B]) iter.Seq2[A, B] {
+	return func(yield func(A, B) bool) {
+		// Ranging over a drives the loop; b has to be pulled one item
+		// at a time
+		next, stop := iter.Pull(b)
+		defer stop()
+
+		for va := range a {
+			vb, ok := next()
+			if !ok || !yield(va, vb) {
+				return
+			}
+		}
+	}
+}
+
+// Chunk returns an iterator over consecutive slices of up to n items of
+// seq; only the last one can be shorter. Each chunk is a new slice, so
+// the loop can keep it. Chunk panics if n is less than one.
+func Chunk[T any](seq iter.Seq[T], n int) iter.Seq[[]T] {
+	if n < 1 {
+		panic("generics: Chunk size must be at least 1")
+	}
+	return func(yield func([]T) bool) {
+		chunk := make([]T, 0, n)
+		for v := range seq {
+			chunk = append(chunk, v)
+			if len(chunk) < n {
+				continue
+			}
+			if !yield(chunk) {
+				return
+			}
+			chunk = make([]T, 0, n)
+		}
+		if len(chunk) > 0 {
+			yield(chunk)
+		}
+	}
+}
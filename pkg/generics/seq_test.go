@@ -0,0 +1,154 @@
+package generics_test
+
+import (
+	"iter"
+	"slices"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+// naturals is an infinite iterator over 0, 1, 2, ... It counts how many
+// items were asked for, to check that the Seq functions stay lazy.
+func naturals(pulled *int) iter.Seq[int] {
+	return func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			*pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+}
+
+func TestFilterMapTake(t *testing.T) {
+	var pulled int
+	even := generics.FilterSeq(naturals(&pulled), func(n int) bool { return n%2 == 0 })
+	squares := generics.MapSeq(even, func(n int) int { return n * n })
+
+	got := slices.Collect(generics.Take(squares, 4))
+	if want := []int{0, 4, 16, 36}; !slices.Equal(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+	if pulled != 7 {
+		t.Errorf("want only 0 to 6 pulled from an infinite source; pulled %d", pulled)
+	}
+}
+
+func TestFilterSeqStops(t *testing.T) {
+	var got []int
+	for v := range generics.FilterSeq(slices.Values([]int{1, 2, 3, 4, 5}), func(int) bool { return true }) {
+		if v == 3 {
+			break
+		}
+		got = append(got, v)
+	}
+	if !slices.Equal(got, []int{1, 2}) {
+		t.Errorf("want [1 2]; got %v", got)
+	}
+}
+
+func TestMapSeqChangesType(t *testing.T) {
+	lengths := generics.MapSeq(slices.Values([]string{"a", "bb", "ccc"}), func(s string) int { return len(s) })
+	if got := slices.Collect(lengths); !slices.Equal(got, []int{1, 2, 3}) {
+		t.Errorf("want [1 2 3]; got %v", got)
+	}
+}
+
+func TestReduceSeq(t *testing.T) {
+	sum := generics.ReduceSeq(slices.Values([]int{1, 2, 3, 4}), 0, func(acc, n int) int { return acc + n })
+	if sum != 10 {
+		t.Errorf("want 10; got %d", sum)
+	}
+
+	empty := generics.ReduceSeq(slices.Values([]int(nil)), "start", func(acc string, n int) string { return "changed" })
+	if empty != "start" {
+		t.Errorf("want the initial value for an empty seq; got %q", empty)
+	}
+}
+
+func TestTake(t *testing.T) {
+	tests := []struct {
+		n    int
+		want []int
+	}{
+		{-1, nil},
+		{0, nil},
+		{2, []int{1, 2}},
+		{3, []int{1, 2, 3}},
+		{10, []int{1, 2, 3}},
+	}
+	for _, tt := range tests {
+		got := slices.Collect(generics.Take(slices.Values([]int{1, 2, 3}), tt.n))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("Take(%d): want %v; got %v", tt.n, tt.want, got)
+		}
+	}
+
+	var pulled int
+	for range generics.Take(naturals(&pulled), 0) {
+	}
+	if pulled != 0 {
+		t.Errorf("want Take(0) to pull nothing; pulled %d", pulled)
+	}
+}
+
+func TestZip(t *testing.T) {
+	names := slices.Values([]string{"ada", "bob", "cy"})
+	var pulled int
+
+	var got []string
+	for name, n := range generics.Zip(names, naturals(&pulled)) {
+		got = append(got, name+":"+string(rune('0'+n)))
+	}
+	if want := []string{"ada:0", "bob:1", "cy:2"}; !slices.Equal(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+
+	// The shorter side ends the zip, whichever side it is
+	var short []int
+	for n := range generics.Zip(naturals(&pulled), slices.Values([]bool{true})) {
+		short = append(short, n)
+	}
+	if !slices.Equal(short, []int{0}) {
+		t.Errorf("want [0]; got %v", short)
+	}
+}
+
+func TestZipStops(t *testing.T) {
+	var pulled int
+	for range generics.Zip(naturals(&pulled), naturals(&pulled)) {
+		break
+	}
+	// Both sides were stopped, or the pulled one would leak a goroutine
+	if pulled != 2 {
+		t.Errorf("want one item from each side; pulled %d", pulled)
+	}
+}
+
+func TestChunk(t *testing.T) {
+	got := slices.Collect(generics.Chunk(slices.Values([]int{1, 2, 3, 4, 5}), 2))
+	want := [][]int{{1, 2}, {3, 4}, {5}}
+	if !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+
+	// The chunks are separate slices: changing one doesn't change another
+	got[0][0] = 99
+	if got[1][0] != 3 {
+		t.Error("want chunks that don't share memory")
+	}
+
+	if got := slices.Collect(generics.Chunk(slices.Values([]int(nil)), 3)); len(got) != 0 {
+		t.Errorf("want no chunks from an empty seq; got %v", got)
+	}
+}
+
+func TestChunkPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want Chunk(seq, 0) to panic")
+		}
+	}()
+	generics.Chunk(slices.Values([]int{1}), 0)
+}
@@ -0,0 +1,105 @@
+package generics
+
+import (
+	"cmp"
+	"iter"
+	"maps"
+	"slices"
+)
+
+// Set is an unordered collection of unique values. The zero value is an
+// empty set ready to use.
+//
+// A Set is not safe for concurrent use.
+type Set[T comparable] struct {
+	items map[T]struct{}
+}
+
+// NewSet returns a set that holds items.
+func NewSet[T comparable](items ...T) *Set[T] {
+	s := &Set[T]{items: make(map[T]struct{}, len(items))}
+	s.Add(items...)
+	return s
+}
+
+// Add adds items to the set. Items already in the set are ignored.
+func (s *Set[T]) Add(items ...T) {
+	if s.items == nil {
+		s.items = make(map[T]struct{}, len(items))
+	}
+	for _, item := range items {
+		s.items[item] = struct{}{}
+	}
+}
+
+// Remove removes items from the set. Items not in the set are ignored.
+func (s *Set[T]) Remove(items ...T) {
+	for _, item := range items {
+		delete(s.items, item)
+	}
+}
+
+// Contains reports whether item is in the set.
+func (s *Set[T]) Contains(item T) bool {
+	_, ok := s.items[item]
+	return ok
+}
+
+// Len returns the number of items in the set.
+func (s *Set[T]) Len() int {
+	return len(s.items)
+}
+
+// Union returns a new set with the items that are in s, other, or both.
+func (s *Set[T]) Union(other *Set[T]) *Set[T] {
+	u := &Set[T]{items: maps.Clone(s.items)}
+	for item := range other.items {
+		u.Add(item)
+	}
+	return u
+}
+
+// Intersection returns a new set with the items that are in both s and
+// other.
+func (s *Set[T]) Intersection(other *Set[T]) *Set[T] {
+	// Range over the smaller set
+	small, large := s, other
+	if small.Len() > large.Len() {
+		small, large = large, small
+	}
+
+	in := &Set[T]{}
+	for item := range small.items {
+		if large.Contains(item) {
+			in.Add(item)
+		}
+	}
+	return in
+}
+
+// Difference returns a new set with the items that are in s but not in
+// other.
+func (s *Set[T]) Difference(other *Set[T]) *Set[T] {
+	d := &Set[T]{}
+	for item := range s.items {
+		if !other.Contains(item) {
+			d.Add(item)
+		}
+	}
+	return d
+}
+
+// Iter returns an iterator over the items, in no particular order.
+//
+//	for item := range s.Iter() { ... }
+func (s *Set[T]) Iter() iter.Seq[T] {
+	return maps.Keys(s.items)
+}
+
+// Sorted returns the items of s as a sorted slice.
+//
+// It is a function rather than a method: a method can't require a
+// stricter constraint than its type's comparable.
+func Sorted[T cmp.Ordered](s *Set[T]) []T {
+	return slices.Sorted(s.Iter())
+}
@@ -0,0 +1,109 @@
+package generics_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/generics"
+)
+
+func TestSetAddRemoveContains(t *testing.T) {
+	s := generics.NewSet(1, 2, 2, 3)
+
+	if s.Len() != 3 {
+		t.Errorf("want duplicates ignored, len 3; got %d", s.Len())
+	}
+
+	s.Add(4, 1)
+	s.Remove(2, 99)
+
+	for _, item := range []int{1, 3, 4} {
+		if !s.Contains(item) {
+			t.Errorf("want %d in the set", item)
+		}
+	}
+	if s.Contains(2) {
+		t.Error("want 2 removed")
+	}
+	if got := generics.Sorted(s); !slices.Equal(got, []int{1, 3, 4}) {
+		t.Errorf("want [1 3 4]; got %v", got)
+	}
+}
+
+func TestSetZeroValue(t *testing.T) {
+	var s generics.Set[string]
+
+	if s.Len() != 0 || s.Contains("a") {
+		t.Error("want an empty set")
+	}
+	s.Remove("a") // must not panic
+	s.Add("a")
+	if !s.Contains("a") {
+		t.Error("want Add to work on the zero value")
+	}
+}
+
+func TestSetOperations(t *testing.T) {
+	a := generics.NewSet(1, 2, 3, 4)
+	b := generics.NewSet(3, 4, 5)
+
+	tests := []struct {
+		name string
+		got  *generics.Set[int]
+		want []int
+	}{
+		{"union", a.Union(b), []int{1, 2, 3, 4, 5}},
+		{"intersection", a.Intersection(b), []int{3, 4}},
+		{"intersection, other way", b.Intersection(a), []int{3, 4}},
+		{"difference", a.Difference(b), []int{1, 2}},
+		{"difference, other way", b.Difference(a), []int{5}},
+		{"with empty", a.Intersection(&generics.Set[int]{}), nil},
+		{"empty union", new(generics.Set[int]).Union(b), []int{3, 4, 5}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := generics.Sorted(tt.got); !slices.Equal(got, tt.want) {
+				t.Errorf("want %v; got %v", tt.want, got)
+			}
+		})
+	}
+
+	// The operations return new sets
+	if got := generics.Sorted(a); !slices.Equal(got, []int{1, 2, 3, 4}) {
+		t.Errorf("want a unchanged; got %v", got)
+	}
+	u := a.Union(b)
+	u.Add(100)
+	if a.Contains(100) {
+		t.Error("want Union to copy, not share, the items")
+	}
+}
+
+func TestSetIter(t *testing.T) {
+	s := generics.NewSet("go", "rust", "zig")
+
+	var got []string
+	for item := range s.Iter() {
+		got = append(got, item)
+	}
+	slices.Sort(got)
+
+	if !slices.Equal(got, []string{"go", "rust", "zig"}) {
+		t.Errorf("want every item once; got %v", got)
+	}
+
+	// Stopping early must not panic
+	for range s.Iter() {
+		break
+	}
+}
+
+func TestSetStructItems(t *testing.T) {
+	type point struct{ x, y int }
+	s := generics.NewSet(point{1, 2}, point{1, 2}, point{3, 4})
+
+	if s.Len() != 2 || !s.Contains(point{3, 4}) {
+		t.Errorf("want comparable structs to work as items; got len %d", s.Len())
+	}
+}
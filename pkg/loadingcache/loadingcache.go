@@ -0,0 +1,287 @@
+// Package loadingcache provides a concurrency-safe cache that loads
+// missing values itself, once per key.
+//
+// When many goroutines ask for the same missing key at once, only the
+// first one calls the loader. The others wait for its result instead of
+// sending the same query to a database or an API, a pattern known as
+// singleflight:
+//
+//	users := loadingcache.New[int, User](time.Minute)
+//	defer users.Close()
+//
+//	u, err := users.GetOrLoad(ctx, 42, func(ctx context.Context, id int) (User, error) {
+//		return db.FindUser(ctx, id)
+//	})
+//
+// Loaded values expire after a TTL. A background janitor goroutine
+// removes expired entries, so keys that are never asked for again don't
+// stay in memory; Close stops it.
+package loadingcache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrClosed is returned by GetOrLoad after Close.
+var ErrClosed = errors.New("loadingcache: cache is closed")
+
+// Loader loads the value for key. It should stop and return when ctx is
+// done.
+type Loader[K comparable, V any] func(ctx context.Context, key K) (V, error)
+
+// Stats counts how a cache was used.
+type Stats struct {
+	Hits    int // Get or GetOrLoad found a live entry
+	Loads   int // the loader was called
+	Shared  int // GetOrLoad waited for a load another call started
+	Expired int // entries removed because their TTL passed
+}
+
+// entry is a loaded value.
+type entry[V any] struct {
+	value   V
+	expires time.Time
+}
+
+// call is a load in progress. Its value and err are set before done is
+// closed, and only read after.
+type call[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+
+	waiters int                // GetOrLoad calls waiting; guarded by Cache.mu
+	cancel  context.CancelFunc // cancels the loader's context
+}
+
+// Cache is a concurrency-safe cache that loads missing values with a
+// Loader. The zero value is not usable; call New.
+type Cache[K comparable, V any] struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	entries map[K]entry[V]
+	calls   map[K]*call[V]
+	stats   Stats
+	closed  bool
+
+	stop chan struct{}  // closed by Close to stop the janitor
+	wg   sync.WaitGroup // the janitor and running loaders
+}
+
+// options holds the settings changed by options.
+type options struct {
+	interval time.Duration
+}
+
+// Option configures New.
+type Option func(*options)
+
+// WithJanitorInterval sets how often the janitor removes expired
+// entries. The default is the TTL.
+func WithJanitorInterval(d time.Duration) Option {
+	return func(o *options) { o.interval = d }
+}
+
+// New returns an empty cache whose values expire ttl after they were
+// loaded, and starts its janitor. Call Close when done with the cache.
+// New panics if ttl or the janitor interval is not positive.
+func New[K comparable, V any](ttl time.Duration, opts ...Option) *Cache[K, V] {
+	if ttl <= 0 {
+		panic("loadingcache: ttl must be positive")
+	}
+
+	o := options{interval: ttl}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.interval <= 0 {
+		panic("loadingcache: janitor interval must be positive")
+	}
+
+	c := &Cache[K, V]{
+		ttl:     ttl,
+		entries: make(map[K]entry[V]),
+		calls:   make(map[K]*call[V]),
+		stop:    make(chan struct{}),
+	}
+	c.wg.Go(func() { c.janitor(o.interval) })
+	return c
+}
+
+// GetOrLoad returns the cached value for key. If there is none, it calls
+// load, caches the value if load succeeds, and returns load's result.
+//
+// Concurrent calls for the same key share one call to load. If ctx is
+// done first, GetOrLoad returns ctx.Err() without waiting, but the load
+// goes on for the other callers. The loader's context keeps ctx's values
+// and is canceled only when every caller has given up, or by Close.
+//
+// Errors are not cached: the next call after a failed load tries again.
+func (c *Cache[K, V]) GetOrLoad(ctx context.Context, key K, load Loader[K, V]) (V, error) {
+	var zero V
+
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return zero, ErrClosed
+	}
+	if e, ok := c.entries[key]; ok && time.Now().Before(e.expires) {
+		c.stats.Hits++
+		c.mu.Unlock()
+		return e.value, nil
+	}
+
+	cl, ok := c.calls[key]
+	if ok {
+		c.stats.Shared++
+	} else {
+		cl = c.startLoad(ctx, key, load)
+	}
+	cl.waiters++
+	c.mu.Unlock()
+
+	select {
+	case <-cl.done:
+		return cl.value, cl.err
+	case <-ctx.Done():
+		c.leave(key, cl)
+		return zero, ctx.Err()
+	}
+}
+
+// startLoad runs load in a new goroutine. c.mu must be held.
+func (c *Cache[K, V]) startLoad(ctx context.Context, key K, load Loader[K, V]) *call[V] {
+	// The load belongs to every caller, not only the first: don't let
+	// the first caller's deadline cancel it
+	ctx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	cl := &call[V]{done: make(chan struct{}), cancel: cancel}
+	c.calls[key] = cl
+	c.stats.Loads++
+
+	c.wg.Go(func() {
+		defer cancel()
+		v, err := load(ctx, key)
+
+		c.mu.Lock()
+		if err == nil && !c.closed {
+			c.entries[key] = entry[V]{value: v, expires: time.Now().Add(c.ttl)}
+		}
+		// leave may already have replaced this call
+		if c.calls[key] == cl {
+			delete(c.calls, key)
+		}
+		c.mu.Unlock()
+
+		cl.value, cl.err = v, err
+		close(cl.done)
+	})
+	return cl
+}
+
+// leave is called when a caller stops waiting for cl. The last one to
+// leave cancels the load, and forgets it so the next caller starts a
+// new one instead of joining a canceled load.
+func (c *Cache[K, V]) leave(key K, cl *call[V]) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	cl.waiters--
+	if cl.waiters > 0 {
+		return
+	}
+	cl.cancel()
+	if c.calls[key] == cl {
+		delete(c.calls, key)
+	}
+}
+
+// Get returns the cached value for key, without loading it.
+func (c *Cache[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !time.Now().Before(e.expires) {
+		var zero V
+		return zero, false
+	}
+	c.stats.Hits++
+	return e.value, true
+}
+
+// Delete removes key from the cache. A load of key that is in progress
+// still stores its value when it finishes.
+func (c *Cache[K, V]) Delete(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// Len returns the number of cached entries, including expired ones the
+// janitor has not removed yet.
+func (c *Cache[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.entries)
+}
+
+// Stats returns the usage counters.
+func (c *Cache[K, V]) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.stats
+}
+
+// Close stops the janitor, cancels the loads in progress, and waits for
+// them to return. Callers waiting on a canceled load get the loader's
+// error. Later calls to GetOrLoad return ErrClosed. Close can be called
+// more than once.
+func (c *Cache[K, V]) Close() {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return
+	}
+	c.closed = true
+	for _, cl := range c.calls {
+		cl.cancel()
+	}
+	c.entries = make(map[K]entry[V])
+	c.mu.Unlock()
+
+	close(c.stop)
+	c.wg.Wait()
+}
+
+// janitor removes expired entries every interval until Close.
+func (c *Cache[K, V]) janitor(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.removeExpired()
+		}
+	}
+}
+
+// removeExpired deletes every expired entry.
+func (c *Cache[K, V]) removeExpired() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	for key, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, key)
+			c.stats.Expired++
+		}
+	}
+}
@@ -0,0 +1,298 @@
+package loadingcache_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/loadingcache"
+)
+
+// slowLoader counts its calls and takes a second to return key * 10.
+func slowLoader(calls *atomic.Int32) loadingcache.Loader[int, int] {
+	return func(ctx context.Context, key int) (int, error) {
+		calls.Add(1)
+		select {
+		case <-time.After(time.Second):
+			return key * 10, nil
+		case <-ctx.Done():
+			return 0, ctx.Err()
+		}
+	}
+}
+
+func TestGetOrLoadCoalesces(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := loadingcache.New[int, int](time.Minute)
+		defer c.Close()
+
+		var calls atomic.Int32
+		load := slowLoader(&calls)
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				v, err := c.GetOrLoad(t.Context(), 7, load)
+				if err != nil || v != 70 {
+					t.Errorf("want 70, nil; got %d, %v", v, err)
+				}
+			})
+		}
+		wg.Wait()
+
+		if n := calls.Load(); n != 1 {
+			t.Errorf("want one load for ten callers; got %d", n)
+		}
+		if s := c.Stats(); s.Loads != 1 || s.Shared != 9 {
+			t.Errorf("want 1 load and 9 shared; got %+v", s)
+		}
+
+		// Now it is cached: no load, no waiting
+		start := time.Now()
+		if v, _ := c.GetOrLoad(t.Context(), 7, load); v != 70 {
+			t.Errorf("want cached 70; got %d", v)
+		}
+		if time.Since(start) != 0 || calls.Load() != 1 {
+			t.Error("want a cache hit without a load")
+		}
+	})
+}
+
+func TestGetOrLoadKeysIndependent(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := loadingcache.New[int, int](time.Minute)
+		defer c.Close()
+
+		var calls atomic.Int32
+		load := slowLoader(&calls)
+
+		start := time.Now()
+		var wg sync.WaitGroup
+		for key := range 3 {
+			wg.Go(func() { c.GetOrLoad(t.Context(), key, load) })
+		}
+		wg.Wait()
+
+		if calls.Load() != 3 {
+			t.Errorf("want one load per key; got %d", calls.Load())
+		}
+		if d := time.Since(start); d != time.Second {
+			t.Errorf("want different keys to load in parallel in 1s; took %v", d)
+		}
+	})
+}
+
+func TestErrorsNotCached(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := loadingcache.New[string, int](time.Minute)
+		defer c.Close()
+
+		errDown := errors.New("database down")
+		fail := true
+		load := func(ctx context.Context, key string) (int, error) {
+			if fail {
+				return 0, errDown
+			}
+			return 1, nil
+		}
+
+		if _, err := c.GetOrLoad(t.Context(), "k", load); !errors.Is(err, errDown) {
+			t.Fatalf("want errDown; got %v", err)
+		}
+		fail = false
+		if v, err := c.GetOrLoad(t.Context(), "k", load); err != nil || v != 1 {
+			t.Errorf("want a retry after a failed load to give 1, nil; got %d, %v", v, err)
+		}
+	})
+}
+
+func TestTTL(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		// A long janitor interval: only the lazy check in Get runs
+		c := loadingcache.New[int, int](time.Minute, loadingcache.WithJanitorInterval(time.Hour))
+		defer c.Close()
+
+		var calls atomic.Int32
+		c.GetOrLoad(t.Context(), 1, slowLoader(&calls))
+
+		// The TTL counts from when the load finished
+		time.Sleep(time.Minute - 1)
+		if _, ok := c.Get(1); !ok {
+			t.Error("want the entry live just before its TTL")
+		}
+
+		time.Sleep(1)
+		if _, ok := c.Get(1); ok {
+			t.Error("want the entry expired at its TTL")
+		}
+
+		c.GetOrLoad(t.Context(), 1, slowLoader(&calls))
+		if calls.Load() != 2 {
+			t.Errorf("want an expired entry loaded again; got %d loads", calls.Load())
+		}
+	})
+}
+
+func TestJanitor(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := loadingcache.New[int, int](time.Minute, loadingcache.WithJanitorInterval(10*time.Second))
+		defer c.Close()
+
+		load := func(ctx context.Context, key int) (int, error) { return key, nil }
+		for key := range 5 {
+			c.GetOrLoad(t.Context(), key, load)
+		}
+
+		time.Sleep(time.Minute)
+		synctest.Wait()
+		if c.Len() != 0 {
+			t.Errorf("want the janitor to remove unused entries; %d left", c.Len())
+		}
+		if s := c.Stats(); s.Expired != 5 {
+			t.Errorf("want 5 expired; got %+v", s)
+		}
+	})
+}
+
+func TestCallerGivesUp(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := loadingcache.New[int, int](time.Minute)
+		defer c.Close()
+
+		var calls atomic.Int32
+		load := slowLoader(&calls)
+
+		// The impatient caller starts the load, then leaves
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+			defer cancel()
+			if _, err := c.GetOrLoad(ctx, 1, load); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("want DeadlineExceeded; got %v", err)
+			}
+		})
+		synctest.Wait()
+
+		// The patient one still gets the value from the same load
+		if v, err := c.GetOrLoad(t.Context(), 1, load); err != nil || v != 10 {
+			t.Errorf("want 10, nil; got %d, %v", v, err)
+		}
+		wg.Wait()
+
+		if calls.Load() != 1 {
+			t.Errorf("want the first load to keep going; got %d loads", calls.Load())
+		}
+	})
+}
+
+func TestLastCallerCancelsLoad(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := loadingcache.New[int, int](time.Minute)
+		defer c.Close()
+
+		loadErr := make(chan error, 1)
+		load := func(ctx context.Context, key int) (int, error) {
+			<-ctx.Done()
+			loadErr <- ctx.Err()
+			return 0, ctx.Err()
+		}
+
+		ctx, cancel := context.WithCancel(t.Context())
+		go cancel()
+		if _, err := c.GetOrLoad(ctx, 1, load); !errors.Is(err, context.Canceled) {
+			t.Errorf("want Canceled; got %v", err)
+		}
+		if err := <-loadErr; !errors.Is(err, context.Canceled) {
+			t.Errorf("want the load canceled when nobody waits; got %v", err)
+		}
+	})
+}
+
+func TestLoaderSeesContextValues(t *testing.T) {
+	type ctxKey struct{}
+	c := loadingcache.New[int, string](time.Minute)
+	defer c.Close()
+
+	ctx := context.WithValue(t.Context(), ctxKey{}, "req-42")
+	v, _ := c.GetOrLoad(ctx, 1, func(ctx context.Context, key int) (string, error) {
+		s, _ := ctx.Value(ctxKey{}).(string)
+		return s, nil
+	})
+	if v != "req-42" {
+		t.Errorf("want the caller's values in the loader's context; got %q", v)
+	}
+}
+
+func TestClose(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		c := loadingcache.New[int, int](time.Minute)
+
+		var calls atomic.Int32
+		done := make(chan error)
+		go func() {
+			_, err := c.GetOrLoad(context.Background(), 1, slowLoader(&calls))
+			done <- err
+		}()
+		synctest.Wait()
+
+		c.Close()
+		if err := <-done; !errors.Is(err, context.Canceled) {
+			t.Errorf("want the waiting caller to see the canceled load; got %v", err)
+		}
+
+		if _, err := c.GetOrLoad(t.Context(), 1, slowLoader(&calls)); !errors.Is(err, loadingcache.ErrClosed) {
+			t.Errorf("want ErrClosed; got %v", err)
+		}
+		c.Close() // must not panic
+	})
+}
+
+func TestDelete(t *testing.T) {
+	c := loadingcache.New[string, int](time.Minute)
+	defer c.Close()
+
+	c.GetOrLoad(t.Context(), "k", func(ctx context.Context, key string) (int, error) { return 1, nil })
+	c.Delete("k")
+	if _, ok := c.Get("k"); ok {
+		t.Error("want k deleted")
+	}
+}
+
+func TestNewPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want New(0) to panic")
+		}
+	}()
+	loadingcache.New[int, int](0)
+}
+
+// Run with -race: many goroutines load, read, and delete a few keys
+func TestConcurrentUse(t *testing.T) {
+	c := loadingcache.New[int, int](time.Millisecond, loadingcache.WithJanitorInterval(time.Millisecond))
+	defer c.Close()
+
+	load := func(ctx context.Context, key int) (int, error) { return key, nil }
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Go(func() {
+			for i := range 500 {
+				key := (g + i) % 10
+				if v, err := c.GetOrLoad(t.Context(), key, load); err != nil || v != key {
+					t.Errorf("want %d, nil; got %d, %v", key, v, err)
+					return
+				}
+				c.Get(key)
+				if i%50 == 0 {
+					c.Delete(key)
+				}
+			}
+		})
+	}
+	wg.Wait()
+}
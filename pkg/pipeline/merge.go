@@ -0,0 +1,80 @@
+package pipeline
+
+import "sync"
+
+// Merge combines several input channels into one and closes the output
+// once every input has been closed. Values from different inputs are
+// interleaved in arrival order; values from one input keep their order.
+//
+// Merge has no context, so the caller must read the output until it is
+// closed. Use FanIn when the consumer may stop early.
+func Merge[T any](ins ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	// One forwarding goroutine per input; the WaitGroup counts how many
+	// are still running, so the output is closed exactly when the last
+	// input is drained. Closing any earlier loses values or panics with
+	// "send on closed channel"; closing any later blocks the consumer.
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Go(func() {
+			for v := range in {
+				out <- v
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// OrderedMerge combines several input channels into one, keeping input
+// index order: every value from ins[0], then every value from ins[1],
+// and so on. The output is closed once every input has been closed.
+//
+// All inputs are drained concurrently, so a producer never waits for an
+// earlier input to finish. Values from ins[0] are forwarded as they
+// arrive; values from later inputs are buffered until their turn.
+//
+// Like Merge, the caller must read the output until it is closed.
+func OrderedMerge[T any](ins ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	// buffered[i] delivers everything ins[i] sent, once it is closed.
+	// The channel has room for its one value, so the draining goroutine
+	// never blocks on it.
+	buffered := make([]chan []T, len(ins))
+	for i, in := range ins {
+		if i == 0 {
+			continue
+		}
+		buffered[i] = make(chan []T, 1)
+		go func() {
+			var vs []T
+			for v := range in {
+				vs = append(vs, v)
+			}
+			buffered[i] <- vs
+		}()
+	}
+
+	go func() {
+		defer close(out)
+		if len(ins) == 0 {
+			return
+		}
+
+		for v := range ins[0] {
+			out <- v
+		}
+		for _, ch := range buffered[1:] {
+			for _, v := range <-ch {
+				out <- v
+			}
+		}
+	}()
+	return out
+}
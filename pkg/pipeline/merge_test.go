@@ -0,0 +1,150 @@
+package pipeline_test
+
+import (
+	"slices"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/pipeline"
+)
+
+// produce returns a channel that sends n values starting at base, then closes
+func produce(base, n int) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for i := range n {
+			out <- base + i
+		}
+	}()
+	return out
+}
+
+// drain reads ch until it is closed
+func drain[T any](ch <-chan T) []T {
+	var vs []T
+	for v := range ch {
+		vs = append(vs, v)
+	}
+	return vs
+}
+
+func TestMergeNoDropsNoDuplicates(t *testing.T) {
+	const inputs, perInput = 16, 500
+
+	var ins []<-chan int
+	for i := range inputs {
+		ins = append(ins, produce(i*perInput, perInput))
+	}
+
+	got := drain(pipeline.Merge(ins...))
+
+	if len(got) != inputs*perInput {
+		t.Fatalf("want %d values; got %d", inputs*perInput, len(got))
+	}
+	seen := make(map[int]int)
+	for _, v := range got {
+		seen[v]++
+	}
+	for v := range inputs * perInput {
+		if seen[v] != 1 {
+			t.Errorf("value %d seen %d times; want 1", v, seen[v])
+		}
+	}
+}
+
+func TestMergeKeepsPerInputOrder(t *testing.T) {
+	got := drain(pipeline.Merge(produce(0, 100), produce(1000, 100)))
+
+	var a, b []int
+	for _, v := range got {
+		if v < 1000 {
+			a = append(a, v)
+		} else {
+			b = append(b, v)
+		}
+	}
+	if !slices.IsSorted(a) || !slices.IsSorted(b) {
+		t.Errorf("values from one input were reordered: %v", got)
+	}
+}
+
+func TestMergeWaitsForSlowestInput(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		slow := make(chan int)
+		go func() {
+			defer close(slow)
+			time.Sleep(time.Hour) // far longer than any sleep-based close
+			slow <- 99
+		}()
+
+		got := drain(pipeline.Merge(produce(0, 3), slow))
+
+		slices.Sort(got)
+		if want := []int{0, 1, 2, 99}; !slices.Equal(got, want) {
+			t.Errorf("want %v; got %v", want, got)
+		}
+	})
+}
+
+func TestMergeNoInputs(t *testing.T) {
+	if got := drain(pipeline.Merge[int]()); len(got) != 0 {
+		t.Errorf("want no values; got %v", got)
+	}
+}
+
+func TestOrderedMergeKeepsInputOrder(t *testing.T) {
+	got := drain(pipeline.OrderedMerge(
+		produce(0, 100),
+		produce(100, 100),
+		produce(200, 100),
+	))
+
+	want := make([]int, 300)
+	for i := range want {
+		want[i] = i
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("want 0..299 in order; got %v", got)
+	}
+}
+
+func TestOrderedMergeDrainsLaterInputsConcurrently(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		start := time.Now()
+
+		first := make(chan int)
+		go func() {
+			defer close(first)
+			time.Sleep(time.Second)
+			first <- 1
+		}()
+
+		// second is unbuffered: if OrderedMerge read it only after
+		// first closed, this producer would be stuck for a second
+		second := make(chan int)
+		var finished time.Duration
+		go func() {
+			defer close(second)
+			second <- 2
+			second <- 3
+			finished = time.Since(start)
+		}()
+
+		got := drain(pipeline.OrderedMerge(first, second))
+
+		if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+			t.Errorf("want %v; got %v", want, got)
+		}
+		if finished != 0 {
+			t.Errorf("second producer waited %v for the first input", finished)
+		}
+	})
+}
+
+func TestOrderedMergeNoInputs(t *testing.T) {
+	if got := drain(pipeline.OrderedMerge[int]()); len(got) != 0 {
+		t.Errorf("want no values; got %v", got)
+	}
+}
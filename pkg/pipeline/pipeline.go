@@ -0,0 +1,208 @@
+// Package pipeline provides generic, context-aware channel stages.
+//
+// Every stage follows the same rules, which are the rules the
+// hand-written int pipelines in 29-concurrency follow:
+//
+//   - A stage owns its output channel and closes it when it is done.
+//   - A stage is done when its input is closed or its context is
+//     cancelled, whichever comes first.
+//   - Every send and receive selects on ctx.Done(), so cancelling the
+//     context unblocks and stops every goroutine in the pipeline.
+//
+// Stages compose by passing one stage's output to the next:
+//
+//	nums := pipeline.FromSlice(ctx, []int{1, 2, 3, 4})
+//	squares := pipeline.MapStage(ctx, nums, func(n int) int { return n * n })
+//	evens := pipeline.FilterStage(ctx, squares, func(n int) bool { return n%2 == 0 })
+//
+//	for n := range evens {
+//		fmt.Println(n)
+//	}
+//
+// Merge and OrderedMerge are the exception: they take no context and
+// are meant for inputs that are known to close, like the hand-written
+// merge in 29-concurrency/02-channels.
+package pipeline
+
+import (
+	"context"
+	"sync"
+)
+
+// send delivers v on out unless ctx is cancelled first.
+// It reports whether the value was sent.
+func send[T any](ctx context.Context, out chan<- T, v T) bool {
+	select {
+	case out <- v:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// recv receives the next value from in unless ctx is cancelled first.
+// It reports false when in is closed or ctx is done.
+func recv[T any](ctx context.Context, in <-chan T) (T, bool) {
+	select {
+	case v, ok := <-in:
+		return v, ok
+	case <-ctx.Done():
+		var zero T
+		return zero, false
+	}
+}
+
+// FromSlice emits the items of a slice in order.
+func FromSlice[T any](ctx context.Context, items []T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, item := range items {
+			if !send(ctx, out, item) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// MapStage emits fn(v) for every v received from in.
+func MapStage[T, U any](ctx context.Context, in <-chan T, fn func(T) U) <-chan U {
+	out := make(chan U)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+			if !send(ctx, out, fn(v)) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// FilterStage emits only the values from in for which keep returns true.
+func FilterStage[T any](ctx context.Context, in <-chan T, keep func(T) bool) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+			if !keep(v) {
+				continue
+			}
+			if !send(ctx, out, v) {
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// FanOut spreads the values from in across n output channels.
+//
+// Each value goes to exactly one output: whichever one is ready to
+// receive first. Attach a slow stage to every output to process values
+// in parallel, then combine the outputs again with FanIn.
+func FanOut[T any](ctx context.Context, in <-chan T, n int) []<-chan T {
+	if n < 1 {
+		n = 1
+	}
+
+	outs := make([]<-chan T, n)
+	for i := range outs {
+		out := make(chan T)
+		outs[i] = out
+
+		go func() {
+			defer close(out)
+			for {
+				v, ok := recv(ctx, in)
+				if !ok || !send(ctx, out, v) {
+					return
+				}
+			}
+		}()
+	}
+	return outs
+}
+
+// FanIn merges several input channels into one. The output is closed
+// after every input has been closed (or ctx is cancelled). Values from
+// different inputs are interleaved in arrival order.
+func FanIn[T any](ctx context.Context, ins ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+	for _, in := range ins {
+		wg.Go(func() {
+			for {
+				v, ok := recv(ctx, in)
+				if !ok || !send(ctx, out, v) {
+					return
+				}
+			}
+		})
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+	return out
+}
+
+// Tee copies every value from in to both outputs.
+//
+// A value is not read from in until both outputs have received the
+// previous one, so the slower consumer sets the pace for both.
+func Tee[T any](ctx context.Context, in <-chan T) (<-chan T, <-chan T) {
+	out1 := make(chan T)
+	out2 := make(chan T)
+
+	go func() {
+		defer close(out1)
+		defer close(out2)
+
+		for {
+			v, ok := recv(ctx, in)
+			if !ok {
+				return
+			}
+
+			// Local copies: set to nil once that output has its value,
+			// because a send on a nil channel is never selected
+			o1, o2 := out1, out2
+			for range 2 {
+				select {
+				case o1 <- v:
+					o1 = nil
+				case o2 <- v:
+					o2 = nil
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out1, out2
+}
+
+// Collect reads in until it is closed or ctx is cancelled and returns
+// the values received.
+func Collect[T any](ctx context.Context, in <-chan T) []T {
+	var items []T
+	for {
+		v, ok := recv(ctx, in)
+		if !ok {
+			return items
+		}
+		items = append(items, v)
+	}
+}
@@ -0,0 +1,161 @@
+package pipeline_test
+
+import (
+	"context"
+	"slices"
+	"strconv"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/pipeline"
+)
+
+func TestFromSlice(t *testing.T) {
+	ctx := context.Background()
+
+	got := pipeline.Collect(ctx, pipeline.FromSlice(ctx, []int{1, 2, 3}))
+	if want := []int{1, 2, 3}; !slices.Equal(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
+
+func TestMapAndFilterCompose(t *testing.T) {
+	ctx := context.Background()
+
+	nums := pipeline.FromSlice(ctx, []int{1, 2, 3, 4, 5, 6})
+	squares := pipeline.MapStage(ctx, nums, func(n int) int { return n * n })
+	evens := pipeline.FilterStage(ctx, squares, func(n int) bool { return n%2 == 0 })
+	labels := pipeline.MapStage(ctx, evens, strconv.Itoa)
+
+	got := pipeline.Collect(ctx, labels)
+	if want := []string{"4", "16", "36"}; !slices.Equal(got, want) {
+		t.Errorf("want %v; got %v", want, got)
+	}
+}
+
+func TestFanOutFanIn(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx := context.Background()
+
+		input := make([]int, 12)
+		for i := range input {
+			input[i] = i + 1
+		}
+
+		// Each worker takes one (fake) second per value
+		slow := func(n int) int {
+			time.Sleep(time.Second)
+			return n * 10
+		}
+
+		start := time.Now()
+
+		outs := pipeline.FanOut(ctx, pipeline.FromSlice(ctx, input), 4)
+		workers := make([]<-chan int, len(outs))
+		for i, out := range outs {
+			workers[i] = pipeline.MapStage(ctx, out, slow)
+		}
+		got := pipeline.Collect(ctx, pipeline.FanIn(ctx, workers...))
+
+		slices.Sort(got)
+		want := make([]int, len(input))
+		for i, n := range input {
+			want[i] = n * 10
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("want %v; got %v", want, got)
+		}
+
+		// 12 values over 4 parallel workers: 3 rounds
+		if elapsed := time.Since(start); elapsed != 3*time.Second {
+			t.Errorf("want 3s with 4 workers; got %v", elapsed)
+		}
+	})
+}
+
+func TestFanOutMinimumOneOutput(t *testing.T) {
+	ctx := context.Background()
+
+	outs := pipeline.FanOut(ctx, pipeline.FromSlice(ctx, []int{1}), 0)
+	if len(outs) != 1 {
+		t.Fatalf("want 1 output; got %d", len(outs))
+	}
+	if got := pipeline.Collect(ctx, outs[0]); !slices.Equal(got, []int{1}) {
+		t.Errorf("want [1]; got %v", got)
+	}
+}
+
+func TestTeeCopiesToBoth(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx := context.Background()
+
+		a, b := pipeline.Tee(ctx, pipeline.FromSlice(ctx, []string{"x", "y", "z"}))
+
+		var gotA, gotB []string
+		done := make(chan struct{})
+		go func() {
+			gotB = pipeline.Collect(ctx, b)
+			close(done)
+		}()
+		gotA = pipeline.Collect(ctx, a)
+		<-done
+
+		want := []string{"x", "y", "z"}
+		if !slices.Equal(gotA, want) || !slices.Equal(gotB, want) {
+			t.Errorf("want both %v; got %v and %v", want, gotA, gotB)
+		}
+	})
+}
+
+// TestCancellationStopsEveryStage relies on synctest: if any goroutine
+// started by the pipeline were still blocked when the test function
+// returns, synctest.Test would fail with a deadlock.
+func TestCancellationStopsEveryStage(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		// An input that never closes
+		endless := make(chan int)
+		go func() {
+			for i := 0; ; i++ {
+				select {
+				case endless <- i:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		mapped := pipeline.MapStage(ctx, endless, func(n int) int { return n + 1 })
+		filtered := pipeline.FilterStage(ctx, mapped, func(n int) bool { return true })
+		outs := pipeline.FanOut(ctx, filtered, 3)
+		merged := pipeline.FanIn(ctx, outs...)
+		a, b := pipeline.Tee(ctx, merged)
+
+		// Read a few values, then walk away from b entirely
+		for range 5 {
+			<-a
+			<-b
+		}
+		cancel()
+
+		// Both outputs close once cancellation reaches them
+		for range a {
+		}
+		for range b {
+		}
+	})
+}
+
+func TestCollectStopsOnCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		never := make(chan int)
+		if got := pipeline.Collect(ctx, never); len(got) != 0 {
+			t.Errorf("want no values; got %v", got)
+		}
+	})
+}
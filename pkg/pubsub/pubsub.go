@@ -0,0 +1,261 @@
+// Package pubsub implements an in-process, topic-based message broker.
+//
+// Publishers send messages to a topic; every subscriber of that topic
+// gets its own copy on its own buffered channel. The broker is built
+// from the pieces in 29-concurrency: channels for delivery, RWMutexes
+// for the subscriber table and for each subscriber's channel, and
+// select for non-blocking and cancellable sends.
+//
+//	b := pubsub.NewBroker[Order]()
+//	defer b.Close()
+//
+//	sub := b.Subscribe("orders", 16, pubsub.Drop)
+//	defer sub.Unsubscribe()
+//
+//	b.Publish(ctx, "orders", Order{ID: 1})
+//	fmt.Println(<-sub.C())
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+)
+
+// ErrClosed is returned by Publish after the broker has been closed.
+var ErrClosed = errors.New("pubsub: broker is closed")
+
+// Policy decides what Publish does when a subscriber's buffer is full.
+type Policy int
+
+const (
+	// Block makes Publish wait until the subscriber has room. A slow
+	// subscriber slows down every publisher on its topic; Subscribe,
+	// Unsubscribe, and publishers on other topics don't wait for it.
+	Block Policy = iota
+
+	// Drop discards the message for that subscriber only and counts it.
+	// Publishers never wait, but slow subscribers miss messages.
+	Drop
+)
+
+// String returns the policy name.
+func (p Policy) String() string {
+	switch p {
+	case Block:
+		return "block"
+	case Drop:
+		return "drop"
+	}
+	return "unknown"
+}
+
+// Broker delivers messages of type T to topic subscribers.
+// It is safe for concurrent use.
+type Broker[T any] struct {
+	mu     sync.RWMutex
+	topics map[string]map[*Subscription[T]]struct{}
+	closed bool
+
+	quit      chan struct{} // closed first by Close to unblock publishers
+	closeOnce sync.Once
+}
+
+// NewBroker returns an empty broker.
+func NewBroker[T any]() *Broker[T] {
+	return &Broker[T]{
+		topics: make(map[string]map[*Subscription[T]]struct{}),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Subscription is one subscriber's view of a topic.
+type Subscription[T any] struct {
+	topic   string
+	policy  Policy
+	ch      chan T
+	done    chan struct{} // closed by Unsubscribe to unblock publishers
+	dropped atomic.Int64
+	broker  *Broker[T]
+
+	doneOnce sync.Once
+	mu       sync.RWMutex // held for reading while sending on ch
+	closed   bool         // ch is closed; guarded by mu
+}
+
+// Subscribe registers a new subscriber for topic with a channel buffer
+// of the given size and a slow-consumer policy.
+//
+// Subscribing to a closed broker returns a subscription whose channel
+// is already closed.
+func (b *Broker[T]) Subscribe(topic string, buffer int, policy Policy) *Subscription[T] {
+	s := &Subscription[T]{
+		topic:  topic,
+		policy: policy,
+		ch:     make(chan T, max(buffer, 0)),
+		done:   make(chan struct{}),
+		broker: b,
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		s.closeChannels()
+		return s
+	}
+
+	if b.topics[topic] == nil {
+		b.topics[topic] = make(map[*Subscription[T]]struct{})
+	}
+	b.topics[topic][s] = struct{}{}
+	return s
+}
+
+// Publish sends msg to every current subscriber of topic.
+//
+// Subscribers with the Drop policy never make Publish wait. For Block
+// subscribers, Publish waits for buffer space; it gives up and returns
+// ctx.Err() if ctx is cancelled first.
+func (b *Broker[T]) Publish(ctx context.Context, topic string, msg T) error {
+	// Copy the subscribers and let go of the lock before delivering:
+	// waiting for a Block subscriber while holding it would stall every
+	// Subscribe, Unsubscribe, and, once one of those is waiting for the
+	// write lock, every Publish on every topic
+	b.mu.RLock()
+	if b.closed {
+		b.mu.RUnlock()
+		return ErrClosed
+	}
+	subs := make([]*Subscription[T], 0, len(b.topics[topic]))
+	for s := range b.topics[topic] {
+		subs = append(subs, s)
+	}
+	b.mu.RUnlock()
+
+	for _, s := range subs {
+		if err := s.deliver(ctx, msg, b.quit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// deliver sends msg on the subscriber's channel according to its policy.
+// It holds s.mu for reading, so that the channel can't be closed under it.
+func (s *Subscription[T]) deliver(ctx context.Context, msg T, quit <-chan struct{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		return nil // unsubscribed since Publish copied the subscribers
+	}
+
+	switch s.policy {
+	case Drop:
+		select {
+		case s.ch <- msg:
+		default:
+			s.dropped.Add(1)
+		}
+
+	default:
+		select {
+		case s.ch <- msg:
+		case <-s.done:
+			// Unsubscribed while we were waiting, or the broker is
+			// closing: Close closes quit before any done
+			select {
+			case <-quit:
+				return ErrClosed
+			default:
+			}
+		case <-quit:
+			return ErrClosed
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// Subscribers returns the number of subscribers of topic.
+func (b *Broker[T]) Subscribers(topic string) int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return len(b.topics[topic])
+}
+
+// Close unsubscribes everyone and closes their channels. Publish
+// returns ErrClosed afterwards. Close is safe to call more than once.
+func (b *Broker[T]) Close() {
+	// Unblock publishers stuck on a Block subscriber, so that they
+	// let go of the subscribers' locks closeChannels needs
+	b.closeOnce.Do(func() { close(b.quit) })
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return
+	}
+	b.closed = true
+
+	for _, subs := range b.topics {
+		for s := range subs {
+			s.closeChannels()
+		}
+	}
+	clear(b.topics)
+}
+
+// C returns the channel messages are delivered on. It is closed by
+// Unsubscribe or Broker.Close.
+func (s *Subscription[T]) C() <-chan T {
+	return s.ch
+}
+
+// Topic returns the subscribed topic.
+func (s *Subscription[T]) Topic() string {
+	return s.topic
+}
+
+// Dropped returns how many messages were discarded because the buffer
+// was full. It is always zero for the Block policy.
+func (s *Subscription[T]) Dropped() int64 {
+	return s.dropped.Load()
+}
+
+// Unsubscribe removes the subscription and closes its channel.
+// It is safe to call more than once.
+func (s *Subscription[T]) Unsubscribe() {
+	b := s.broker
+
+	b.mu.Lock()
+	if subs := b.topics[s.topic]; subs != nil {
+		delete(subs, s)
+		if len(subs) == 0 {
+			delete(b.topics, s.topic)
+		}
+	}
+	b.mu.Unlock()
+
+	s.closeChannels()
+}
+
+// closeChannels closes done and ch exactly once. Closing done first
+// releases any publisher blocked on a full buffer, which holds s.mu
+// for reading until it returns.
+func (s *Subscription[T]) closeChannels() {
+	s.doneOnce.Do(func() { close(s.done) })
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		close(s.ch)
+		s.closed = true
+	}
+}
@@ -0,0 +1,237 @@
+package pubsub_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/pubsub"
+)
+
+func TestEverySubscriberGetsACopy(t *testing.T) {
+	b := pubsub.NewBroker[string]()
+	defer b.Close()
+
+	s1 := b.Subscribe("news", 1, pubsub.Block)
+	s2 := b.Subscribe("news", 1, pubsub.Drop)
+	other := b.Subscribe("sports", 1, pubsub.Drop)
+
+	if err := b.Publish(context.Background(), "news", "hello"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i, s := range []*pubsub.Subscription[string]{s1, s2} {
+		if got := <-s.C(); got != "hello" {
+			t.Errorf("subscriber %d: want %q; got %q", i+1, "hello", got)
+		}
+	}
+
+	select {
+	case msg := <-other.C():
+		t.Errorf("other topic received %q", msg)
+	default:
+	}
+}
+
+func TestDropPolicyCountsDroppedMessages(t *testing.T) {
+	b := pubsub.NewBroker[int]()
+	defer b.Close()
+
+	s := b.Subscribe("n", 2, pubsub.Drop)
+	for i := range 5 {
+		if err := b.Publish(context.Background(), "n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := s.Dropped(); got != 3 {
+		t.Errorf("want 3 dropped; got %d", got)
+	}
+	// The first two messages were kept
+	if a, b := <-s.C(), <-s.C(); a != 0 || b != 1 {
+		t.Errorf("want 0 and 1; got %d and %d", a, b)
+	}
+}
+
+func TestBlockPolicyWaitsForConsumer(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		b := pubsub.NewBroker[int]()
+		defer b.Close()
+
+		s := b.Subscribe("n", 1, pubsub.Block)
+
+		// A slow consumer: one message per second
+		var got []int
+		var wg sync.WaitGroup
+		wg.Go(func() {
+			for n := range s.C() {
+				got = append(got, n)
+				time.Sleep(time.Second)
+			}
+		})
+
+		start := time.Now()
+		for i := range 4 {
+			if err := b.Publish(context.Background(), "n", i); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		// The publisher was held back by the consumer
+		if elapsed := time.Since(start); elapsed < 2*time.Second {
+			t.Errorf("want publisher slowed down; took %v", elapsed)
+		}
+
+		s.Unsubscribe()
+		wg.Wait()
+
+		if len(got) != 4 || s.Dropped() != 0 {
+			t.Errorf("want all 4 delivered, none dropped; got %v, %d dropped", got, s.Dropped())
+		}
+	})
+}
+
+func TestBlockPolicyRespectsContext(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		b := pubsub.NewBroker[int]()
+		defer b.Close()
+
+		b.Subscribe("n", 0, pubsub.Block) // nobody reads it
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		if err := b.Publish(ctx, "n", 1); !errors.Is(err, context.DeadlineExceeded) {
+			t.Errorf("want DeadlineExceeded; got %v", err)
+		}
+	})
+}
+
+func TestUnsubscribeReleasesBlockedPublisher(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		b := pubsub.NewBroker[int]()
+		defer b.Close()
+
+		s := b.Subscribe("n", 0, pubsub.Block)
+
+		done := make(chan error)
+		go func() { done <- b.Publish(context.Background(), "n", 1) }()
+
+		synctest.Wait() // the publisher is now blocked
+		s.Unsubscribe()
+
+		if err := <-done; err != nil {
+			t.Errorf("want nil after unsubscribe; got %v", err)
+		}
+		if _, ok := <-s.C(); ok {
+			t.Error("want channel closed")
+		}
+		if n := b.Subscribers("n"); n != 0 {
+			t.Errorf("want 0 subscribers; got %d", n)
+		}
+
+		s.Unsubscribe() // second call is a no-op
+	})
+}
+
+func TestBlockedPublisherDoesNotStallOthers(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		b := pubsub.NewBroker[int]()
+		defer b.Close()
+
+		slow := b.Subscribe("slow", 0, pubsub.Block) // nobody reads it yet
+
+		done := make(chan error)
+		go func() { done <- b.Publish(context.Background(), "slow", 1) }()
+		synctest.Wait() // the publisher is now blocked
+
+		// None of these wait for the slow subscriber
+		other := b.Subscribe("other", 1, pubsub.Drop)
+		if err := b.Publish(context.Background(), "other", 2); err != nil {
+			t.Fatal(err)
+		}
+		if got := <-other.C(); got != 2 {
+			t.Errorf("want 2; got %d", got)
+		}
+		other.Unsubscribe()
+
+		select {
+		case err := <-done:
+			t.Fatalf("publisher returned %v before the slow subscriber read", err)
+		default:
+		}
+		if got := <-slow.C(); got != 1 {
+			t.Errorf("want 1; got %d", got)
+		}
+		if err := <-done; err != nil {
+			t.Errorf("want nil; got %v", err)
+		}
+	})
+}
+
+func TestCloseReleasesBlockedPublisher(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		b := pubsub.NewBroker[int]()
+		s := b.Subscribe("n", 0, pubsub.Block)
+
+		done := make(chan error)
+		go func() { done <- b.Publish(context.Background(), "n", 1) }()
+
+		synctest.Wait()
+		b.Close()
+
+		if err := <-done; !errors.Is(err, pubsub.ErrClosed) {
+			t.Errorf("want ErrClosed; got %v", err)
+		}
+		if _, ok := <-s.C(); ok {
+			t.Error("want channel closed")
+		}
+	})
+}
+
+func TestAfterClose(t *testing.T) {
+	b := pubsub.NewBroker[int]()
+	b.Close()
+	b.Close()
+
+	if err := b.Publish(context.Background(), "n", 1); !errors.Is(err, pubsub.ErrClosed) {
+		t.Errorf("want ErrClosed; got %v", err)
+	}
+
+	s := b.Subscribe("n", 1, pubsub.Drop)
+	if _, ok := <-s.C(); ok {
+		t.Error("want subscription on a closed broker to be closed")
+	}
+	s.Unsubscribe()
+}
+
+func TestConcurrentPublishAndUnsubscribe(t *testing.T) {
+	b := pubsub.NewBroker[int]()
+	defer b.Close()
+
+	var wg sync.WaitGroup
+	for range 10 {
+		wg.Go(func() {
+			s := b.Subscribe("n", 4, pubsub.Drop)
+			for range 100 {
+				b.Publish(context.Background(), "n", 1)
+			}
+			s.Unsubscribe()
+			s.Unsubscribe()
+		})
+	}
+	wg.Wait()
+
+	if n := b.Subscribers("n"); n != 0 {
+		t.Errorf("want 0 subscribers; got %d", n)
+	}
+}
+
+func TestPolicyString(t *testing.T) {
+	if pubsub.Block.String() != "block" || pubsub.Drop.String() != "drop" {
+		t.Error("unexpected policy names")
+	}
+}
@@ -0,0 +1,158 @@
+// Package ratelimit implements a token bucket rate limiter.
+//
+// A bucket holds up to burst tokens and refills at a steady rate. Every
+// event spends one token. While the bucket has tokens, events go through
+// immediately (up to a burst); once it is empty, events are spaced out
+// to the refill rate.
+//
+// The API mirrors golang.org/x/time/rate so the two are easy to compare:
+//
+//	lim := ratelimit.New(2, 3) // 2 events per second, bursts of up to 3
+//
+//	if lim.Allow() {
+//		// fast path: a token was available right now
+//	}
+//
+//	if err := lim.Wait(ctx); err != nil {
+//		// ctx was cancelled, or its deadline is too early
+//	}
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDeadline is returned by Wait when the context's deadline would
+// expire before a token becomes available.
+var ErrDeadline = errors.New("ratelimit: wait would exceed context deadline")
+
+// Limiter is a token bucket. It is safe for concurrent use.
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64 // tokens added per second
+	burst  float64 // bucket capacity
+	tokens float64 // may go negative while callers wait
+	last   time.Time
+}
+
+// New returns a limiter that allows rate events per second with bursts
+// of up to burst events. The bucket starts full.
+//
+// New panics if rate is not positive. A burst below one is treated as one.
+func New(rate float64, burst int) *Limiter {
+	if rate <= 0 {
+		panic("ratelimit: rate must be positive")
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &Limiter{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Every converts an interval between events into a rate for New:
+// Every(500*time.Millisecond) is 2 events per second.
+func Every(interval time.Duration) float64 {
+	return float64(time.Second) / float64(interval)
+}
+
+// refill adds the tokens earned since the last call. Callers hold l.mu.
+func (l *Limiter) refill(now time.Time) {
+	elapsed := now.Sub(l.last).Seconds()
+	l.last = now
+
+	l.tokens += elapsed * l.rate
+	if l.tokens > l.burst {
+		l.tokens = l.burst
+	}
+}
+
+// Allow reports whether an event may happen now. It spends a token if
+// one is available and never blocks.
+func (l *Limiter) Allow() bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	if l.tokens < 1 {
+		return false
+	}
+	l.tokens--
+	return true
+}
+
+// Delay returns how long until Allow would succeed, or zero if it would
+// succeed now. It doesn't spend a token, so a server can use it to tell
+// a rejected client when to retry.
+func (l *Limiter) Delay() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	if l.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+}
+
+// Wait blocks until a token is available or ctx is done.
+//
+// Wait reserves its token up front, so concurrent callers are served in
+// the order they called Wait. If ctx is cancelled while waiting, the
+// reserved token is given back.
+func (l *Limiter) Wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	now := time.Now()
+	l.refill(now)
+
+	// Reserve a token, possibly going into debt
+	l.tokens--
+	if l.tokens >= 0 {
+		l.mu.Unlock()
+		return nil
+	}
+	delay := time.Duration(-l.tokens / l.rate * float64(time.Second))
+
+	// Don't wait at all if we already know the deadline is too early
+	if deadline, ok := ctx.Deadline(); ok && deadline.Before(now.Add(delay)) {
+		l.tokens++
+		l.mu.Unlock()
+		return ErrDeadline
+	}
+	l.mu.Unlock()
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// Tokens returns the number of tokens currently in the bucket. A
+// negative number means callers are already waiting.
+func (l *Limiter) Tokens() float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.refill(time.Now())
+	return l.tokens
+}
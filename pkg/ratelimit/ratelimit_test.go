@@ -0,0 +1,194 @@
+package ratelimit_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/ratelimit"
+)
+
+func TestAllowBurstThenDeny(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(1, 3)
+
+		for i := range 3 {
+			if !lim.Allow() {
+				t.Fatalf("call %d: want burst to be allowed", i+1)
+			}
+		}
+		if lim.Allow() {
+			t.Fatal("want 4th call to be denied")
+		}
+	})
+}
+
+func TestAllowRefillsOverTime(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(2, 1) // one token every 500ms
+
+		if !lim.Allow() {
+			t.Fatal("want first call allowed")
+		}
+
+		time.Sleep(499 * time.Millisecond)
+		if lim.Allow() {
+			t.Fatal("want call before refill to be denied")
+		}
+
+		time.Sleep(time.Millisecond)
+		if !lim.Allow() {
+			t.Fatal("want call after refill to be allowed")
+		}
+	})
+}
+
+func TestRefillIsCappedAtBurst(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(10, 2)
+
+		time.Sleep(time.Hour)
+		if got := lim.Tokens(); got != 2 {
+			t.Errorf("want 2 tokens after a long idle period; got %v", got)
+		}
+	})
+}
+
+func TestWaitSpacesEvents(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(2, 2)
+		ctx := context.Background()
+		start := time.Now()
+
+		var at []time.Duration
+		for range 6 {
+			if err := lim.Wait(ctx); err != nil {
+				t.Fatal(err)
+			}
+			at = append(at, time.Since(start))
+		}
+
+		// Burst of 2 immediately, then one every 500ms
+		want := []time.Duration{
+			0, 0,
+			500 * time.Millisecond,
+			1000 * time.Millisecond,
+			1500 * time.Millisecond,
+			2000 * time.Millisecond,
+		}
+		for i := range want {
+			if at[i] != want[i] {
+				t.Errorf("event %d: want %v; got %v", i+1, want[i], at[i])
+			}
+		}
+	})
+}
+
+func TestWaitConcurrentCallers(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(10, 1)
+		start := time.Now()
+
+		var wg sync.WaitGroup
+		for range 10 {
+			wg.Go(func() {
+				if err := lim.Wait(context.Background()); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+		wg.Wait()
+
+		// One immediately, nine more at 100ms intervals
+		if got := time.Since(start); got != 900*time.Millisecond {
+			t.Errorf("want 900ms; got %v", got)
+		}
+	})
+}
+
+func TestWaitCancelled(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(1, 1)
+		lim.Allow() // empty the bucket
+
+		ctx, cancel := context.WithCancel(context.Background())
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			cancel()
+		}()
+
+		if err := lim.Wait(ctx); !errors.Is(err, context.Canceled) {
+			t.Fatalf("want context.Canceled; got %v", err)
+		}
+
+		// The reserved token was handed back: after the refill
+		// interval the next caller gets it without further delay
+		time.Sleep(900 * time.Millisecond)
+		if !lim.Allow() {
+			t.Error("want token to be available after cancelled Wait")
+		}
+	})
+}
+
+func TestWaitDeadlineTooEarly(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(1, 1)
+		lim.Allow()
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		if err := lim.Wait(ctx); !errors.Is(err, ratelimit.ErrDeadline) {
+			t.Fatalf("want ErrDeadline; got %v", err)
+		}
+		if waited := time.Since(start); waited != 0 {
+			t.Errorf("want Wait to fail fast; waited %v", waited)
+		}
+	})
+}
+
+func TestDelay(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		lim := ratelimit.New(4, 2) // one token every 250ms
+
+		if d := lim.Delay(); d != 0 {
+			t.Fatalf("want no delay with a full bucket; got %v", d)
+		}
+		lim.Allow()
+		lim.Allow()
+		if d := lim.Delay(); d != 250*time.Millisecond {
+			t.Fatalf("want 250ms when empty; got %v", d)
+		}
+
+		// Delay doesn't spend: asking twice gives the same answer
+		time.Sleep(100 * time.Millisecond)
+		lim.Delay()
+		if d := lim.Delay(); d != 150*time.Millisecond {
+			t.Errorf("want 150ms later on; got %v", d)
+		}
+
+		time.Sleep(150 * time.Millisecond)
+		if d := lim.Delay(); d != 0 || !lim.Allow() {
+			t.Errorf("want the token ready once the delay has passed; got %v", d)
+		}
+	})
+}
+
+func TestEvery(t *testing.T) {
+	if got := ratelimit.Every(250 * time.Millisecond); got != 4 {
+		t.Errorf("want 4 events per second; got %v", got)
+	}
+}
+
+func TestNewPanicsOnBadRate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want New to panic on zero rate")
+		}
+	}()
+	ratelimit.New(0, 1)
+}
@@ -0,0 +1,61 @@
+package retry
+
+// Errors classify themselves by implementing one of these methods. The
+// first is this package's convention; the others are what net.Error and
+// many other packages already implement.
+type (
+	retryable interface{ Retryable() bool }
+	temporary interface{ Temporary() bool }
+	timeout   interface{ Timeout() bool }
+)
+
+// Retryable reports false: a permanent error is never retried.
+func (e *PermanentError) Retryable() bool { return false }
+
+// IsRetryable reports whether err is a transient failure that is worth
+// retrying.
+//
+// It walks err's chain, like errors.As, and uses the first error that
+// has a Retryable, Temporary, or Timeout method, in that order of
+// preference. Errors that say nothing about themselves are not
+// retryable: when in doubt, do not repeat an operation.
+func IsRetryable(err error) bool {
+	retry, _ := classify(err)
+	return retry
+}
+
+// classify returns the answer of the first error in the chain that
+// classifies itself, and whether one was found.
+func classify(err error) (retry, found bool) {
+	for err != nil {
+		switch e := err.(type) {
+		case retryable:
+			return e.Retryable(), true
+		case temporary:
+			return e.Temporary(), true
+		case timeout:
+			return e.Timeout(), true
+		}
+
+		switch e := err.(type) {
+		case interface{ Unwrap() error }:
+			err = e.Unwrap()
+		case interface{ Unwrap() []error }:
+			for _, inner := range e.Unwrap() {
+				if retry, found := classify(inner); found {
+					return retry, true
+				}
+			}
+			return false, false
+		default:
+			return false, false
+		}
+	}
+	return false, false
+}
+
+// WithRetryableOnly retries only the errors that IsRetryable accepts.
+// It is the same as WithRetryIf(IsRetryable).
+func WithRetryableOnly() Option {
+	return WithRetryIf(IsRetryable)
+}
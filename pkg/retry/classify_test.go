@@ -0,0 +1,105 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"testing"
+	"testing/synctest"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+// transient and fatal classify themselves with Retryable
+type transient struct{}
+
+func (transient) Error() string   { return "transient" }
+func (transient) Retryable() bool { return true }
+
+type fatal struct{}
+
+func (fatal) Error() string   { return "fatal" }
+func (fatal) Retryable() bool { return false }
+
+// overloaded uses the older Temporary convention
+type overloaded struct{}
+
+func (overloaded) Error() string   { return "overloaded" }
+func (overloaded) Temporary() bool { return true }
+
+// mixed says two different things; Retryable wins
+type mixed struct{}
+
+func (mixed) Error() string   { return "mixed" }
+func (mixed) Retryable() bool { return false }
+func (mixed) Temporary() bool { return true }
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"unclassified", errors.New("boom"), false},
+		{"Retryable true", transient{}, true},
+		{"Retryable false", fatal{}, false},
+		{"Temporary", overloaded{}, true},
+		{"Retryable beats Temporary", mixed{}, false},
+		{"Timeout", os.ErrDeadlineExceeded, true},
+		{"net.Error timeout", &net.DNSError{Err: "timeout", IsTimeout: true}, true},
+		{"permanent", retry.Permanent(transient{}), false},
+
+		{"wrapped", fmt.Errorf("query: %w", transient{}), true},
+		{"wrapped twice", fmt.Errorf("a: %w", fmt.Errorf("b: %w", overloaded{})), true},
+		{"outermost decides", fmt.Errorf("a: %w", retry.Permanent(fmt.Errorf("b: %w", transient{}))), false},
+		{"wrapped unclassified", fmt.Errorf("a: %w", errors.New("boom")), false},
+		{"joined, first classified wins", errors.Join(errors.New("boom"), fatal{}, transient{}), false},
+		{"joined, skips unclassified", errors.Join(errors.New("boom"), fmt.Errorf("x: %w", transient{})), true},
+		{"joined, none classified", errors.Join(errors.New("a"), errors.New("b")), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retry.IsRetryable(tt.err); got != tt.want {
+				t.Errorf("want %v; got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestWithRetryableOnly(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		// Two transient failures, then one that cannot be fixed by retrying
+		results := []error{fmt.Errorf("dial: %w", transient{}), overloaded{}, fmt.Errorf("query: %w", fatal{})}
+		calls := 0
+
+		err := retry.Do(context.Background(), func(context.Context) error {
+			err := results[calls]
+			calls++
+			return err
+		}, retry.WithMaxAttempts(10), retry.WithRetryableOnly())
+
+		if calls != 3 {
+			t.Errorf("want 3 attempts; got %d", calls)
+		}
+		var f fatal
+		if !errors.As(err, &f) {
+			t.Errorf("want the fatal error; got %v", err)
+		}
+	})
+}
+
+func TestWithRetryableOnlyStopsOnUnknown(t *testing.T) {
+	calls := 0
+	err := retry.Do(context.Background(), func(context.Context) error {
+		calls++
+		return errors.New("unknown")
+	}, retry.WithRetryableOnly())
+
+	if calls != 1 || err == nil {
+		t.Errorf("want 1 attempt and the error; got %d, %v", calls, err)
+	}
+}
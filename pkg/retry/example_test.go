@@ -0,0 +1,118 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+// flaky returns an operation that fails n times before it succeeds.
+func flaky(n int) func(context.Context) error {
+	return func(ctx context.Context) error {
+		if n > 0 {
+			n--
+			return errors.New("connection refused")
+		}
+		return nil
+	}
+}
+
+func ExampleDo() {
+	err := retry.Do(context.Background(), flaky(2),
+		retry.WithBackoff(time.Millisecond, 10*time.Millisecond),
+	)
+	fmt.Println(err)
+	// Output:
+	// <nil>
+}
+
+// Without jitter the delays are exact, so a hook can report them.
+func ExampleDo_onRetry() {
+	err := retry.Do(context.Background(), flaky(5),
+		retry.WithMaxAttempts(4),
+		retry.WithBackoff(time.Millisecond, 3*time.Millisecond),
+		retry.WithOnRetry(func(attempt int, err error, delay time.Duration) {
+			fmt.Printf("attempt %d: %v; waiting %v\n", attempt, err, delay)
+		}),
+	)
+	fmt.Println(err)
+	// Output:
+	// attempt 1: connection refused; waiting 1ms
+	// attempt 2: connection refused; waiting 2ms
+	// attempt 3: connection refused; waiting 3ms
+	// retry: gave up after 4 attempts: connection refused
+}
+
+func ExampleDoValue() {
+	calls := 0
+	n, err := retry.DoValue(context.Background(), func(ctx context.Context) (int, error) {
+		calls++
+		if calls < 3 {
+			return 0, errors.New("not yet")
+		}
+		return 42, nil
+	}, retry.WithBackoff(time.Millisecond, time.Millisecond))
+
+	fmt.Println(n, err, calls)
+	// Output:
+	// 42 <nil> 3
+}
+
+func ExamplePermanent() {
+	calls := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		return retry.Permanent(errors.New("404 not found"))
+	})
+	fmt.Println(err, calls)
+	// Output:
+	// 404 not found 1
+}
+
+// busyError says it is worth retrying, as net.Error does with Timeout.
+type busyError struct{}
+
+func (busyError) Error() string   { return "server busy" }
+func (busyError) Retryable() bool { return true }
+
+func ExampleIsRetryable() {
+	wrapped := fmt.Errorf("fetch: %w", busyError{})
+
+	fmt.Println(retry.IsRetryable(wrapped))
+	fmt.Println(retry.IsRetryable(errors.New("bad request")))
+	fmt.Println(retry.IsRetryable(context.DeadlineExceeded))
+	// Output:
+	// true
+	// false
+	// true
+}
+
+func ExampleWithRetryableOnly() {
+	calls := 0
+	err := retry.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			return busyError{}
+		}
+		return errors.New("bad request")
+	},
+		retry.WithRetryableOnly(),
+		retry.WithBackoff(time.Millisecond, time.Millisecond),
+	)
+	fmt.Println(err, calls)
+	// Output:
+	// bad request 2
+}
+
+func ExampleJitter_String() {
+	for _, j := range []retry.Jitter{retry.NoJitter, retry.FullJitter, retry.EqualJitter} {
+		fmt.Println(j)
+	}
+	// Output:
+	// none
+	// full
+	// equal
+}
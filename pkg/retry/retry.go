@@ -0,0 +1,210 @@
+// Package retry retries failing operations with exponential backoff.
+//
+// The default configuration makes 3 attempts, waiting 100ms and then 200ms
+// between them. Options change the limits, the delays, and which errors
+// are worth retrying:
+//
+//	err := retry.Do(ctx, func(ctx context.Context) error {
+//		return client.Ping(ctx)
+//	},
+//		retry.WithMaxAttempts(5),
+//		retry.WithMaxElapsed(10*time.Second),
+//		retry.WithBackoff(50*time.Millisecond, 2*time.Second),
+//		retry.WithJitter(retry.FullJitter),
+//	)
+//
+// An operation stops the retries early by returning Permanent(err).
+// Errors can also classify themselves with a Retryable, Temporary, or
+// Timeout method; WithRetryableOnly retries only those that say yes.
+package retry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"time"
+)
+
+// Jitter is a strategy for randomizing the delay between attempts, so
+// that many clients failing at once do not all retry at once.
+type Jitter int
+
+const (
+	// NoJitter waits exactly the backoff delay.
+	NoJitter Jitter = iota
+	// FullJitter waits a random duration in [0, delay].
+	FullJitter
+	// EqualJitter waits delay/2 plus a random duration in [0, delay/2].
+	EqualJitter
+)
+
+// String returns the name of the strategy.
+func (j Jitter) String() string {
+	switch j {
+	case NoJitter:
+		return "none"
+	case FullJitter:
+		return "full"
+	case EqualJitter:
+		return "equal"
+	default:
+		return fmt.Sprintf("Jitter(%d)", int(j))
+	}
+}
+
+// apply returns the delay to actually wait.
+func (j Jitter) apply(d time.Duration) time.Duration {
+	if d <= 0 {
+		return d
+	}
+	switch j {
+	case FullJitter:
+		return rand.N(d + 1)
+	case EqualJitter:
+		half := d / 2
+		return half + rand.N(d-half+1)
+	default:
+		return d
+	}
+}
+
+// PermanentError marks an error that must not be retried.
+type PermanentError struct {
+	Err error
+}
+
+func (e *PermanentError) Error() string { return e.Err.Error() }
+func (e *PermanentError) Unwrap() error { return e.Err }
+
+// Permanent wraps err so that Do returns it without retrying.
+// Permanent(nil) is nil.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &PermanentError{Err: err}
+}
+
+// config holds the settings changed by options.
+type config struct {
+	maxAttempts int
+	maxElapsed  time.Duration
+	initial     time.Duration
+	maxDelay    time.Duration
+	jitter      Jitter
+	retryIf     func(error) bool
+	onRetry     func(attempt int, err error, delay time.Duration)
+}
+
+// Option configures Do.
+type Option func(*config)
+
+// WithMaxAttempts sets how many times the operation runs at most,
+// including the first attempt. Zero or less means no limit.
+func WithMaxAttempts(n int) Option {
+	return func(c *config) { c.maxAttempts = n }
+}
+
+// WithMaxElapsed gives up once another wait would take the total time
+// past d. Zero means no limit.
+func WithMaxElapsed(d time.Duration) Option {
+	return func(c *config) { c.maxElapsed = d }
+}
+
+// WithBackoff sets the first delay, which doubles after every attempt
+// up to max. A first delay above max is cut to max.
+func WithBackoff(initial, max time.Duration) Option {
+	return func(c *config) {
+		c.initial = initial
+		c.maxDelay = max
+	}
+}
+
+// WithJitter sets the jitter strategy.
+func WithJitter(j Jitter) Option {
+	return func(c *config) { c.jitter = j }
+}
+
+// WithRetryIf retries only the errors for which fn returns true.
+// Other errors are returned immediately, like permanent errors.
+func WithRetryIf(fn func(error) bool) Option {
+	return func(c *config) { c.retryIf = fn }
+}
+
+// WithOnRetry calls fn after every failed attempt that will be retried,
+// before waiting delay.
+func WithOnRetry(fn func(attempt int, err error, delay time.Duration)) Option {
+	return func(c *config) { c.onRetry = fn }
+}
+
+// Do runs op until it succeeds, returns a permanent error, or a limit
+// is reached.
+//
+// It returns nil on success, the unwrapped error for permanent and
+// non-retryable errors, ctx.Err() if ctx is done while waiting, and
+// otherwise the last error wrapped with the reason for giving up.
+func Do(ctx context.Context, op func(context.Context) error, opts ...Option) error {
+	_, err := DoValue(ctx, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, op(ctx)
+	}, opts...)
+	return err
+}
+
+// DoValue is like Do for operations that return a value.
+func DoValue[T any](ctx context.Context, op func(context.Context) (T, error), opts ...Option) (T, error) {
+	c := config{
+		maxAttempts: 3,
+		initial:     100 * time.Millisecond,
+		maxDelay:    10 * time.Second,
+	}
+	for _, opt := range opts {
+		opt(&c)
+	}
+
+	var zero T
+	start := time.Now()
+	delay := min(c.initial, c.maxDelay)
+
+	for attempt := 1; ; attempt++ {
+		v, err := op(ctx)
+		if err == nil {
+			return v, nil
+		}
+
+		var pe *PermanentError
+		if errors.As(err, &pe) {
+			return zero, pe.Err
+		}
+		if c.retryIf != nil && !c.retryIf(err) {
+			return zero, err
+		}
+		if c.maxAttempts > 0 && attempt >= c.maxAttempts {
+			return zero, fmt.Errorf("retry: gave up after %d attempts: %w", attempt, err)
+		}
+
+		wait := c.jitter.apply(delay)
+		if c.maxElapsed > 0 && time.Since(start)+wait > c.maxElapsed {
+			return zero, fmt.Errorf("retry: gave up after %v: %w", c.maxElapsed, err)
+		}
+
+		if c.onRetry != nil {
+			c.onRetry(attempt, err, wait)
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return zero, ctx.Err()
+		}
+
+		// Compare before doubling so a huge max cannot overflow
+		if delay > c.maxDelay/2 {
+			delay = c.maxDelay
+		} else {
+			delay *= 2
+		}
+	}
+}
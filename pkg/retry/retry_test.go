@@ -0,0 +1,279 @@
+package retry_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/retry"
+)
+
+var errTemporary = errors.New("temporary")
+
+// failTimes returns an operation that fails n times, then succeeds,
+// and a pointer to the number of calls made
+func failTimes(n int) (func(context.Context) error, *int) {
+	calls := 0
+	return func(context.Context) error {
+		calls++
+		if calls <= n {
+			return errTemporary
+		}
+		return nil
+	}, &calls
+}
+
+// delays records the delay of every retry through WithOnRetry
+func delays(into *[]time.Duration) retry.Option {
+	return retry.WithOnRetry(func(_ int, _ error, d time.Duration) {
+		*into = append(*into, d)
+	})
+}
+
+func TestDefaults(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		op, calls := failTimes(10)
+		start := time.Now()
+
+		err := retry.Do(context.Background(), op)
+
+		if !errors.Is(err, errTemporary) {
+			t.Errorf("want errTemporary wrapped; got %v", err)
+		}
+		if *calls != 3 {
+			t.Errorf("want 3 attempts; got %d", *calls)
+		}
+		if elapsed := time.Since(start); elapsed != 300*time.Millisecond {
+			t.Errorf("want 100ms + 200ms; got %v", elapsed)
+		}
+	})
+}
+
+func TestSucceedsAfterFailures(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		op, calls := failTimes(2)
+
+		if err := retry.Do(context.Background(), op); err != nil {
+			t.Fatal(err)
+		}
+		if *calls != 3 {
+			t.Errorf("want 3 attempts; got %d", *calls)
+		}
+	})
+}
+
+func TestDoValue(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		calls := 0
+		v, err := retry.DoValue(context.Background(), func(context.Context) (string, error) {
+			calls++
+			if calls == 1 {
+				return "", errTemporary
+			}
+			return "ok", nil
+		})
+
+		if err != nil || v != "ok" {
+			t.Errorf("want ok, nil; got %q, %v", v, err)
+		}
+	})
+}
+
+func TestBackoffDoublesUpToMax(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var got []time.Duration
+		op, _ := failTimes(10)
+
+		retry.Do(context.Background(), op,
+			retry.WithMaxAttempts(6),
+			retry.WithBackoff(time.Second, 5*time.Second),
+			delays(&got),
+		)
+
+		want := []time.Duration{
+			1 * time.Second, 2 * time.Second, 4 * time.Second,
+			5 * time.Second, 5 * time.Second,
+		}
+		if !slices.Equal(got, want) {
+			t.Errorf("want delays %v; got %v", want, got)
+		}
+	})
+}
+
+func TestInitialDelayCappedAtMax(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var got []time.Duration
+		op, _ := failTimes(10)
+
+		retry.Do(context.Background(), op,
+			retry.WithMaxAttempts(3),
+			retry.WithBackoff(time.Second, 100*time.Millisecond),
+			delays(&got),
+		)
+
+		want := []time.Duration{100 * time.Millisecond, 100 * time.Millisecond}
+		if !slices.Equal(got, want) {
+			t.Errorf("want delays %v; got %v", want, got)
+		}
+	})
+}
+
+func TestPermanentErrorStopsRetries(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		errNotFound := errors.New("not found")
+		calls := 0
+
+		err := retry.Do(context.Background(), func(context.Context) error {
+			calls++
+			return retry.Permanent(errNotFound)
+		}, retry.WithMaxAttempts(5))
+
+		if err != errNotFound {
+			t.Errorf("want the unwrapped error; got %v", err)
+		}
+		if calls != 1 {
+			t.Errorf("want 1 attempt; got %d", calls)
+		}
+	})
+}
+
+func TestPermanentNil(t *testing.T) {
+	if err := retry.Permanent(nil); err != nil {
+		t.Errorf("want nil; got %v", err)
+	}
+}
+
+func TestRetryIf(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		errFatal := errors.New("fatal")
+		calls := 0
+
+		err := retry.Do(context.Background(), func(context.Context) error {
+			calls++
+			if calls < 3 {
+				return errTemporary
+			}
+			return errFatal
+		},
+			retry.WithMaxAttempts(10),
+			retry.WithRetryIf(func(err error) bool { return errors.Is(err, errTemporary) }),
+		)
+
+		if err != errFatal {
+			t.Errorf("want errFatal as is; got %v", err)
+		}
+		if calls != 3 {
+			t.Errorf("want 3 attempts; got %d", calls)
+		}
+	})
+}
+
+func TestMaxElapsed(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		op, calls := failTimes(100)
+		start := time.Now()
+
+		err := retry.Do(context.Background(), op,
+			retry.WithMaxAttempts(0), // no attempt limit
+			retry.WithBackoff(time.Second, time.Minute),
+			retry.WithMaxElapsed(10*time.Second),
+		)
+
+		if !errors.Is(err, errTemporary) {
+			t.Errorf("want errTemporary wrapped; got %v", err)
+		}
+		// Waits 1+2+4 = 7s; the next 8s wait would pass 10s, so stop
+		if *calls != 4 {
+			t.Errorf("want 4 attempts; got %d", *calls)
+		}
+		if elapsed := time.Since(start); elapsed != 7*time.Second {
+			t.Errorf("want to give up at 7s; got %v", elapsed)
+		}
+	})
+}
+
+func TestJitterBounds(t *testing.T) {
+	tests := []struct {
+		jitter   retry.Jitter
+		min, max time.Duration
+	}{
+		{retry.NoJitter, time.Second, time.Second},
+		{retry.FullJitter, 0, time.Second},
+		{retry.EqualJitter, 500 * time.Millisecond, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.jitter.String(), func(t *testing.T) {
+			synctest.Test(t, func(t *testing.T) {
+				var got []time.Duration
+				op, _ := failTimes(1000)
+
+				retry.Do(context.Background(), op,
+					retry.WithMaxAttempts(200),
+					retry.WithBackoff(time.Second, time.Second),
+					retry.WithJitter(tt.jitter),
+					delays(&got),
+				)
+
+				for _, d := range got {
+					if d < tt.min || d > tt.max {
+						t.Fatalf("delay %v outside [%v, %v]", d, tt.min, tt.max)
+					}
+				}
+				if tt.jitter != retry.NoJitter && len(slices.Compact(slices.Sorted(slices.Values(got)))) == 1 {
+					t.Errorf("jitter produced identical delays: %v", got[0])
+				}
+			})
+		})
+	}
+}
+
+func TestOnRetryAttempts(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var attempts []int
+		op, _ := failTimes(10)
+
+		retry.Do(context.Background(), op,
+			retry.WithMaxAttempts(4),
+			retry.WithOnRetry(func(attempt int, err error, _ time.Duration) {
+				if !errors.Is(err, errTemporary) {
+					t.Errorf("hook got %v", err)
+				}
+				attempts = append(attempts, attempt)
+			}),
+		)
+
+		// No hook after the last attempt: there is no retry
+		if want := []int{1, 2, 3}; !slices.Equal(attempts, want) {
+			t.Errorf("want hooks for %v; got %v", want, attempts)
+		}
+	})
+}
+
+func TestContextCancelledWhileWaiting(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		op, calls := failTimes(100)
+		start := time.Now()
+
+		err := retry.Do(ctx, op,
+			retry.WithMaxAttempts(0),
+			retry.WithBackoff(time.Hour, time.Hour),
+		)
+
+		if err != context.DeadlineExceeded {
+			t.Errorf("want DeadlineExceeded; got %v", err)
+		}
+		if *calls != 1 {
+			t.Errorf("want 1 attempt; got %d", *calls)
+		}
+		if elapsed := time.Since(start); elapsed != 5*time.Second {
+			t.Errorf("want to stop at the deadline; got %v", elapsed)
+		}
+	})
+}
@@ -0,0 +1,93 @@
+// Package sloghelpers logs errors as structured attributes instead of
+// flattened strings.
+//
+// An error type takes part by implementing slog.LogValuer and returning
+// a group of its fields:
+//
+//	func (e *DatabaseError) LogValue() slog.Value {
+//		return slog.GroupValue(slog.String("op", e.Op), slog.String("table", e.Table))
+//	}
+//
+// Err then walks the error's chain and merges the fields of every error
+// that has them under one "error" group:
+//
+//	logger.Error("load failed", sloghelpers.Err(err))
+//	// {"msg":"load failed","error":{"msg":"...","op":"SELECT","table":"users","host":"db","port":5432}}
+//
+// ReplaceAttr does the same for attributes that already hold an error,
+// such as slog.Any("err", err), when it is set in slog.HandlerOptions.
+package sloghelpers
+
+import (
+	"errors"
+	"log/slog"
+	"strconv"
+)
+
+// Key is the attribute key used by Err.
+const Key = "error"
+
+// Err returns an attribute with the key "error" that logs err's message
+// and the fields of every error in its chain. It returns an empty
+// attribute, which handlers ignore, if err is nil.
+func Err(err error) slog.Attr {
+	if err == nil {
+		return slog.Attr{}
+	}
+	return slog.Any(Key, value{err})
+}
+
+// ReplaceAttr logs every attribute whose value is an error the way Err
+// does, keeping the attribute's key. Use it as
+// slog.HandlerOptions.ReplaceAttr.
+//
+// Handlers resolve a slog.LogValuer before calling ReplaceAttr, so an
+// error that is itself a LogValuer arrives as its own fields, without
+// the message or the rest of its chain. Errors wrapped again, such as
+// with fmt.Errorf, arrive whole. Use Err to be sure.
+func ReplaceAttr(_ []string, a slog.Attr) slog.Attr {
+	if a.Value.Kind() != slog.KindAny {
+		return a
+	}
+	if err, ok := a.Value.Any().(error); ok {
+		a.Value = value{err}.LogValue()
+	}
+	return a
+}
+
+// Attrs returns the fields of every error in err's chain, outermost
+// first, without the message. The errors inside a joined error, whose
+// fields could clash, are grouped under the key "errors" by their index,
+// each with its own message:
+//
+//	"errors": {"0": {"msg": "...", "field": "email"}, "1": {"msg": "...", "field": "age"}}
+func Attrs(err error) []slog.Attr {
+	var attrs []slog.Attr
+	for err != nil {
+		if lv, ok := err.(slog.LogValuer); ok {
+			if v := lv.LogValue().Resolve(); v.Kind() == slog.KindGroup {
+				attrs = append(attrs, v.Group()...)
+			}
+		}
+
+		if joined, ok := err.(interface{ Unwrap() []error }); ok {
+			var inner []slog.Attr
+			for i, e := range joined.Unwrap() {
+				inner = append(inner, slog.Attr{Key: strconv.Itoa(i), Value: value{e}.LogValue()})
+			}
+			return append(attrs, slog.Attr{Key: "errors", Value: slog.GroupValue(inner...)})
+		}
+		err = errors.Unwrap(err)
+	}
+	return attrs
+}
+
+// value defers building the group until a handler logs it, so a
+// disabled level costs nothing.
+type value struct{ err error }
+
+// LogValue implements slog.LogValuer.
+func (v value) LogValue() slog.Value {
+	attrs := append([]slog.Attr{slog.String("msg", v.err.Error())}, Attrs(v.err)...)
+	return slog.GroupValue(attrs...)
+}
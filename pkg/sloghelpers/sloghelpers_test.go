@@ -0,0 +1,158 @@
+package sloghelpers_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"testing"
+
+	"github.com/inancgumus/learngo/pkg/sloghelpers"
+)
+
+type dbError struct {
+	op, table string
+	err       error
+}
+
+func (e *dbError) Error() string { return e.op + " " + e.table + ": " + e.err.Error() }
+func (e *dbError) Unwrap() error { return e.err }
+
+func (e *dbError) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("op", e.op), slog.String("table", e.table))
+}
+
+type netError struct {
+	host string
+	port int
+}
+
+func (e *netError) Error() string { return fmt.Sprintf("dial %s:%d: refused", e.host, e.port) }
+
+func (e *netError) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("host", e.host), slog.Int("port", e.port))
+}
+
+// record logs one message with attrs through a JSON handler and returns
+// the decoded line
+func record(t *testing.T, opts *slog.HandlerOptions, attrs ...any) map[string]any {
+	t.Helper()
+
+	var buf bytes.Buffer
+	slog.New(slog.NewJSONHandler(&buf, opts)).Error("failed", attrs...)
+
+	var m map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatalf("decode %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestErrNil(t *testing.T) {
+	m := record(t, nil, sloghelpers.Err(nil))
+	if _, ok := m["error"]; ok {
+		t.Errorf("want no error attribute; got %v", m["error"])
+	}
+}
+
+func TestErrPlain(t *testing.T) {
+	m := record(t, nil, sloghelpers.Err(errors.New("boom")))
+
+	want := map[string]any{"msg": "boom"}
+	if !reflect.DeepEqual(m["error"], want) {
+		t.Errorf("want %v; got %v", want, m["error"])
+	}
+}
+
+func TestErrMergesTheChain(t *testing.T) {
+	err := fmt.Errorf("load: %w", &dbError{op: "SELECT", table: "users", err: &netError{host: "db", port: 5432}})
+	m := record(t, nil, sloghelpers.Err(err))
+
+	want := map[string]any{
+		"msg":   err.Error(),
+		"op":    "SELECT",
+		"table": "users",
+		"host":  "db",
+		"port":  5432.0, // JSON numbers decode as float64
+	}
+	if !reflect.DeepEqual(m["error"], want) {
+		t.Errorf("want %v; got %v", want, m["error"])
+	}
+}
+
+func TestErrGroupsJoinedErrors(t *testing.T) {
+	err := fmt.Errorf("sync: %w", errors.Join(
+		&netError{host: "a", port: 1},
+		fmt.Errorf("x: %w", &dbError{op: "UPDATE", table: "t", err: errors.New("deadlock")}),
+	))
+	m := record(t, nil, sloghelpers.Err(err))
+
+	want := map[string]any{
+		"msg": err.Error(),
+		"errors": map[string]any{
+			"0": map[string]any{"msg": "dial a:1: refused", "host": "a", "port": 1.0},
+			"1": map[string]any{"msg": "x: UPDATE t: deadlock", "op": "UPDATE", "table": "t"},
+		},
+	}
+	if !reflect.DeepEqual(m["error"], want) {
+		t.Errorf("want %v; got %v", want, m["error"])
+	}
+}
+
+func TestAttrs(t *testing.T) {
+	err := &dbError{op: "SELECT", table: "users", err: &netError{host: "db", port: 1}}
+
+	var keys []string
+	for _, a := range sloghelpers.Attrs(err) {
+		keys = append(keys, a.Key)
+	}
+	want := []string{"op", "table", "host", "port"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("want %v; got %v", want, keys)
+	}
+	if attrs := sloghelpers.Attrs(errors.New("plain")); attrs != nil {
+		t.Errorf("plain error: want no attributes; got %v", attrs)
+	}
+}
+
+func TestReplaceAttr(t *testing.T) {
+	opts := &slog.HandlerOptions{ReplaceAttr: sloghelpers.ReplaceAttr}
+	err := fmt.Errorf("cancel order: %w", &dbError{op: "DELETE", table: "orders", err: errors.New("locked")})
+
+	m := record(t, opts, slog.Any("cause", err), slog.String("user", "ann"), slog.Int("n", 3))
+
+	cause, ok := m["cause"].(map[string]any)
+	if !ok || cause["op"] != "DELETE" || cause["msg"] != err.Error() {
+		t.Errorf("want cause as a group with op and msg; got %v", m["cause"])
+	}
+	if m["user"] != "ann" || m["n"] != 3.0 {
+		t.Errorf("want other attributes unchanged; got user=%v n=%v", m["user"], m["n"])
+	}
+}
+
+// counted counts how often its fields are built
+type counted struct{ calls *int }
+
+func (e counted) Error() string { return "counted" }
+
+func (e counted) LogValue() slog.Value {
+	*e.calls++
+	return slog.GroupValue()
+}
+
+func TestErrIsLazy(t *testing.T) {
+	calls := 0
+	logger := slog.New(slog.NewJSONHandler(&bytes.Buffer{}, &slog.HandlerOptions{Level: slog.LevelError}))
+
+	logger.Debug("ignored", sloghelpers.Err(counted{&calls}))
+	if calls != 0 {
+		t.Errorf("disabled level: want 0 calls; got %d", calls)
+	}
+
+	logger.Error("logged", sloghelpers.Err(counted{&calls}))
+	if calls != 1 {
+		t.Errorf("enabled level: want 1 call; got %d", calls)
+	}
+}
@@ -0,0 +1,68 @@
+// Package timing controls how often a function runs when the events
+// that trigger it arrive in bursts.
+//
+//	Debouncer  runs once, after a burst is over
+//	Throttler  runs at most once per interval, during a burst
+//	Sampler    reports the latest value once per interval
+//
+// Every type takes a context: cancelling it stops the type just like
+// calling Stop, and drops any call that is still pending.
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Debouncer runs a function only after calls have stopped arriving for
+// a given delay. Each call to Do restarts the wait and replaces the
+// function that will run.
+//
+// Use it for "do this once things settle down": saving a document
+// after the user stops typing, or reloading a config after a burst of
+// file change events.
+type Debouncer struct {
+	delay time.Duration
+
+	mu      sync.Mutex
+	timer   *time.Timer
+	stopped bool
+
+	stopCtx func() bool
+}
+
+// NewDebouncer returns a Debouncer that waits for delay of quiet.
+// It stops when ctx is done.
+func NewDebouncer(ctx context.Context, delay time.Duration) *Debouncer {
+	d := &Debouncer{delay: delay}
+	d.stopCtx = context.AfterFunc(ctx, func() { d.Stop() })
+	return d
+}
+
+// Do schedules f to run once delay passes without another call to Do.
+// f runs on its own goroutine. Do does nothing after Stop.
+func (d *Debouncer) Do(f func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.stopped {
+		return
+	}
+	if d.timer != nil {
+		d.timer.Stop()
+	}
+	d.timer = time.AfterFunc(d.delay, f)
+}
+
+// Stop cancels the pending call, if any, and disables the Debouncer.
+// It reports whether a pending call was cancelled.
+func (d *Debouncer) Stop() bool {
+	d.stopCtx()
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	d.stopped = true
+	return d.timer != nil && d.timer.Stop()
+}
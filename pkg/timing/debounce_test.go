@@ -0,0 +1,77 @@
+package timing_test
+
+import (
+	"context"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/timing"
+)
+
+func TestDebouncerRunsOnceAfterBurst(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		rec := newRecorder()
+		d := timing.NewDebouncer(context.Background(), ms(100))
+
+		// Five events, 30ms apart: never 100ms of quiet until the end
+		every(5, ms(30), func(i int) { d.Do(rec.fn(i)) })
+
+		time.Sleep(time.Second)
+		rec.check(t, call{ms(4*30 + 100), 4})
+	})
+}
+
+func TestDebouncerSpacedCallsAllRun(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		rec := newRecorder()
+		d := timing.NewDebouncer(context.Background(), ms(100))
+
+		every(3, ms(150), func(i int) { d.Do(rec.fn(i)) })
+
+		time.Sleep(time.Second)
+		rec.check(t,
+			call{ms(100), 0},
+			call{ms(250), 1},
+			call{ms(400), 2},
+		)
+	})
+}
+
+func TestDebouncerStop(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		rec := newRecorder()
+		d := timing.NewDebouncer(context.Background(), ms(100))
+
+		d.Do(rec.fn(0))
+		if !d.Stop() {
+			t.Error("want Stop to report a cancelled call")
+		}
+
+		d.Do(rec.fn(1)) // ignored after Stop
+		time.Sleep(time.Second)
+
+		rec.check(t)
+		if d.Stop() {
+			t.Error("want a second Stop to cancel nothing")
+		}
+	})
+}
+
+func TestDebouncerContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rec := newRecorder()
+		d := timing.NewDebouncer(ctx, ms(100))
+
+		d.Do(rec.fn(0))
+		time.Sleep(ms(50))
+		cancel()
+		synctest.Wait() // let the context's AfterFunc run
+
+		d.Do(rec.fn(1))
+		time.Sleep(time.Second)
+
+		rec.check(t)
+	})
+}
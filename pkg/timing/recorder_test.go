@@ -0,0 +1,66 @@
+package timing_test
+
+import (
+	"fmt"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// call is one recorded call: when it ran, relative to the start of the
+// test, and which event triggered it
+type call struct {
+	at    time.Duration
+	event int
+}
+
+func (c call) String() string { return fmt.Sprintf("%v:#%d", c.at, c.event) }
+
+// recorder records calls made from any goroutine
+type recorder struct {
+	start time.Time
+
+	mu    sync.Mutex
+	calls []call
+}
+
+func newRecorder() *recorder {
+	return &recorder{start: time.Now()}
+}
+
+// fn returns a function that records a call for event
+func (r *recorder) fn(event int) func() {
+	return func() { r.record(event) }
+}
+
+func (r *recorder) record(event int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, call{time.Since(r.start), event})
+}
+
+// check compares the recorded calls with want
+func (r *recorder) check(t *testing.T, want ...call) {
+	t.Helper()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if !slices.Equal(r.calls, want) {
+		t.Errorf("want calls %v; got %v", want, r.calls)
+	}
+}
+
+// ms is short for a number of milliseconds
+func ms(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+
+// every calls fn(i) for i in [0, n), step apart, starting now
+func every(n int, step time.Duration, fn func(i int)) {
+	for i := range n {
+		if i > 0 {
+			time.Sleep(step)
+		}
+		fn(i)
+	}
+}
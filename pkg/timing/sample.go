@@ -0,0 +1,83 @@
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Sampler reports the latest observed value once per interval.
+//
+// Values passed to Observe overwrite each other; on every tick, the
+// most recent one is handed to fn, if it arrived since the last tick.
+// Ticks without a new value are skipped.
+//
+// Use it for "show me where things are, regularly": a sensor reading,
+// a download's byte count, or a queue length on a dashboard.
+type Sampler[T any] struct {
+	mu     sync.Mutex
+	latest T
+	fresh  bool
+
+	quit chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// NewSampler starts a Sampler that calls fn with the latest value every
+// interval. fn runs on the Sampler's own goroutine, one call at a time.
+// The Sampler stops when ctx is done.
+func NewSampler[T any](ctx context.Context, interval time.Duration, fn func(T)) *Sampler[T] {
+	s := &Sampler[T]{
+		quit: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+
+	go func() {
+		defer close(s.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				if v, ok := s.take(); ok {
+					fn(v)
+				}
+			case <-ctx.Done():
+				return
+			case <-s.quit:
+				return
+			}
+		}
+	}()
+
+	return s
+}
+
+// Observe records v as the latest value.
+func (s *Sampler[T]) Observe(v T) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latest = v
+	s.fresh = true
+}
+
+// take returns the latest value if it has not been reported yet.
+func (s *Sampler[T]) take() (T, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fresh := s.fresh
+	s.fresh = false
+	return s.latest, fresh
+}
+
+// Stop stops the Sampler and waits for its goroutine to exit.
+// It must not be called from fn.
+func (s *Sampler[T]) Stop() {
+	s.once.Do(func() { close(s.quit) })
+	<-s.done
+}
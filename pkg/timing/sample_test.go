@@ -0,0 +1,85 @@
+package timing_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/timing"
+)
+
+// samples collects the values a Sampler reports
+type samples struct {
+	mu   sync.Mutex
+	vals []int
+}
+
+func (s *samples) add(v int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.vals = append(s.vals, v)
+}
+
+func (s *samples) check(t *testing.T, want ...int) {
+	t.Helper()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !slices.Equal(s.vals, want) {
+		t.Errorf("want samples %v; got %v", want, s.vals)
+	}
+}
+
+func TestSamplerReportsLatestPerTick(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var got samples
+		s := timing.NewSampler(context.Background(), ms(30), got.add)
+		defer s.Stop()
+
+		// Observe the current time in ms at 5, 15, ..., 95ms
+		time.Sleep(ms(5))
+		every(10, ms(10), func(i int) { s.Observe(5 + 10*i) })
+
+		time.Sleep(time.Second)
+
+		// Ticks at 30, 60, 90, 120ms; later ticks have nothing new
+		got.check(t, 25, 55, 85, 95)
+	})
+}
+
+func TestSamplerStop(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var got samples
+		s := timing.NewSampler(context.Background(), ms(30), got.add)
+
+		s.Observe(1)
+		time.Sleep(ms(40))
+		s.Stop()
+		s.Stop() // safe to call twice
+
+		s.Observe(2)
+		time.Sleep(time.Second)
+
+		got.check(t, 1)
+	})
+}
+
+func TestSamplerContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		var got samples
+		s := timing.NewSampler(ctx, ms(30), got.add)
+
+		s.Observe(1)
+		cancel()
+		synctest.Wait() // the sampler goroutine has exited
+
+		time.Sleep(time.Second)
+		got.check(t)
+	})
+}
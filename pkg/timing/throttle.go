@@ -0,0 +1,127 @@
+package timing
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Throttler runs a function at most once per interval, no matter how
+// often Do is called.
+//
+// The first call opens a window of one interval. With the leading edge
+// enabled, that call runs at once. Calls made while the window is open
+// do not run; with the trailing edge enabled, the last of them runs
+// when the window closes, and opens a new window.
+//
+// Use it for "keep up, but not too often": updating a progress bar or
+// sending position updates while the user drags something.
+type Throttler struct {
+	interval time.Duration
+	leading  bool
+	trailing bool
+
+	mu      sync.Mutex
+	timer   *time.Timer // non-nil while a window is open
+	pending func()
+	stopped bool
+
+	stopCtx func() bool
+}
+
+// ThrottleOption configures a Throttler.
+type ThrottleOption func(*Throttler)
+
+// WithLeading sets whether the first call of a window runs at once.
+// The default is true.
+func WithLeading(enabled bool) ThrottleOption {
+	return func(t *Throttler) { t.leading = enabled }
+}
+
+// WithTrailing sets whether the last call made during a window runs
+// when the window closes. The default is true.
+func WithTrailing(enabled bool) ThrottleOption {
+	return func(t *Throttler) { t.trailing = enabled }
+}
+
+// NewThrottler returns a Throttler that runs at most one call per
+// interval. It stops when ctx is done.
+//
+// It panics if both the leading and the trailing edge are disabled,
+// since nothing would ever run.
+func NewThrottler(ctx context.Context, interval time.Duration, opts ...ThrottleOption) *Throttler {
+	t := &Throttler{interval: interval, leading: true, trailing: true}
+	for _, opt := range opts {
+		opt(t)
+	}
+	if !t.leading && !t.trailing {
+		panic("timing: throttler needs the leading or the trailing edge")
+	}
+
+	t.stopCtx = context.AfterFunc(ctx, func() { t.Stop() })
+	return t
+}
+
+// Do runs f now, runs it later, or drops it, depending on the window
+// and the edges enabled. A leading call runs on the caller's goroutine;
+// a trailing call runs on its own. Do does nothing after Stop.
+func (t *Throttler) Do(f func()) {
+	t.mu.Lock()
+
+	if t.stopped {
+		t.mu.Unlock()
+		return
+	}
+
+	if t.timer == nil {
+		t.timer = time.AfterFunc(t.interval, t.closeWindow)
+		if t.leading {
+			t.mu.Unlock()
+			f()
+			return
+		}
+	}
+
+	if t.trailing {
+		t.pending = f // keep only the latest call
+	}
+	t.mu.Unlock()
+}
+
+// closeWindow runs the trailing call, if any, which opens a new window
+// so that it is also followed by a full interval.
+func (t *Throttler) closeWindow() {
+	t.mu.Lock()
+
+	f := t.pending
+	t.pending = nil
+	if f == nil || t.stopped {
+		t.timer = nil
+		t.mu.Unlock()
+		return
+	}
+
+	t.timer = time.AfterFunc(t.interval, t.closeWindow)
+	t.mu.Unlock()
+
+	f()
+}
+
+// Stop drops the pending trailing call, if any, and disables the
+// Throttler. It reports whether a pending call was dropped.
+func (t *Throttler) Stop() bool {
+	t.stopCtx()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.stopped = true
+	if t.timer != nil {
+		t.timer.Stop()
+		t.timer = nil
+	}
+
+	dropped := t.pending != nil
+	t.pending = nil
+	return dropped
+}
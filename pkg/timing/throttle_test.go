@@ -0,0 +1,103 @@
+package timing_test
+
+import (
+	"context"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/timing"
+)
+
+// Every test sends 10 events 10ms apart (at 0, 10, ..., 90ms) through
+// a throttler with a 33ms interval, so no event lands exactly on the
+// end of a window.
+func throttle(t *testing.T, opts ...timing.ThrottleOption) *recorder {
+	t.Helper()
+
+	rec := newRecorder()
+	th := timing.NewThrottler(context.Background(), ms(33), opts...)
+
+	every(10, ms(10), func(i int) { th.Do(rec.fn(i)) })
+	time.Sleep(time.Second)
+
+	return rec
+}
+
+func TestThrottlerBothEdges(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		// Each window ends with the latest event seen during it
+		throttle(t).check(t,
+			call{ms(0), 0},  // leading
+			call{ms(33), 3}, // trailing: 1, 2, 3 arrived in [0, 33)
+			call{ms(66), 6}, // 4, 5, 6 arrived in [33, 66)
+			call{ms(99), 9},
+		)
+	})
+}
+
+func TestThrottlerLeadingOnly(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		// A window opens on a call and drops the calls inside it
+		throttle(t, timing.WithTrailing(false)).check(t,
+			call{ms(0), 0},
+			call{ms(40), 4},
+			call{ms(80), 8},
+		)
+	})
+}
+
+func TestThrottlerTrailingOnly(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		// Like both edges, without the immediate first call
+		throttle(t, timing.WithLeading(false)).check(t,
+			call{ms(33), 3},
+			call{ms(66), 6},
+			call{ms(99), 9},
+		)
+	})
+}
+
+func TestThrottlerNoEdgesPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("want a panic")
+		}
+	}()
+	timing.NewThrottler(context.Background(), time.Second,
+		timing.WithLeading(false), timing.WithTrailing(false))
+}
+
+func TestThrottlerStopDropsTrailingCall(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		rec := newRecorder()
+		th := timing.NewThrottler(context.Background(), ms(100))
+
+		th.Do(rec.fn(0)) // leading: runs now
+		th.Do(rec.fn(1)) // trailing: pending
+
+		if !th.Stop() {
+			t.Error("want Stop to report a dropped call")
+		}
+		th.Do(rec.fn(2)) // ignored after Stop
+		time.Sleep(time.Second)
+
+		rec.check(t, call{0, 0})
+	})
+}
+
+func TestThrottlerContextCancel(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		rec := newRecorder()
+		th := timing.NewThrottler(ctx, ms(100))
+
+		th.Do(rec.fn(0))
+		th.Do(rec.fn(1))
+		cancel()
+		synctest.Wait()
+
+		time.Sleep(time.Second)
+		rec.check(t, call{0, 0})
+	})
+}
@@ -0,0 +1,90 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/inancgumus/learngo/pkg/workerpool"
+)
+
+func upper(ctx context.Context, s string) (string, error) {
+	if s == "" {
+		return "", errors.New("empty input")
+	}
+	return strings.ToUpper(s), nil
+}
+
+// Workers finish in any order, so the results do too. An unordered
+// output block compares the lines as a set.
+func Example() {
+	pool := workerpool.New(context.Background(), 3, upper)
+
+	go func() {
+		for _, s := range []string{"go", "", "gopher", "pool"} {
+			if err := pool.Submit(s); err != nil {
+				break
+			}
+		}
+		pool.Drain()
+	}()
+
+	for r := range pool.Results() {
+		if r.Err != nil {
+			fmt.Printf("%q: %v\n", r.Input, r.Err)
+			continue
+		}
+		fmt.Printf("%q: %s\n", r.Input, r.Value)
+	}
+	// Unordered output:
+	// "go": GO
+	// "": empty input
+	// "gopher": GOPHER
+	// "pool": POOL
+}
+
+// With a single worker, the results keep the order of the inputs.
+func ExampleNew() {
+	pool := workerpool.New(context.Background(), 1, upper)
+
+	go func() {
+		for _, s := range []string{"a", "b", "c"} {
+			pool.Submit(s)
+		}
+		pool.Drain()
+	}()
+
+	for r := range pool.Results() {
+		fmt.Print(r.Value, " ")
+	}
+	fmt.Println()
+	// Output:
+	// A B C
+}
+
+func ExamplePool_Drain() {
+	pool := workerpool.New(context.Background(), 2, upper)
+
+	// Nothing was submitted, so Drain does not need Results to be read
+	pool.Drain()
+
+	err := pool.Submit("late")
+	fmt.Println(err, errors.Is(err, workerpool.ErrClosed))
+	// Output:
+	// workerpool: pool is closed true
+}
+
+func ExamplePool_Submit() {
+	ctx, cancel := context.WithCancel(context.Background())
+	pool := workerpool.New(ctx, 2, upper)
+
+	cancel()
+	fmt.Println(pool.Submit("x"))
+
+	_, open := <-pool.Results()
+	fmt.Println(open)
+	// Output:
+	// context canceled
+	// false
+}
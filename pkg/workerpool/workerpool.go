@@ -0,0 +1,148 @@
+// Package workerpool runs a function over submitted inputs using a fixed
+// number of goroutines.
+//
+// It is the importable version of the pattern built by hand in
+// 29-concurrency/08-worker-pool: a jobs channel, N workers, a results
+// channel that is closed once every worker has returned.
+//
+// Typical use submits from one goroutine and reads results in another:
+//
+//	pool := workerpool.New(ctx, 3, resize)
+//
+//	go func() {
+//		for _, img := range images {
+//			if err := pool.Submit(img); err != nil {
+//				break
+//			}
+//		}
+//		pool.Drain()
+//	}()
+//
+//	for r := range pool.Results() {
+//		fmt.Println(r.Input, r.Value, r.Err)
+//	}
+package workerpool
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// ErrClosed is returned by Submit after Drain has been called.
+var ErrClosed = errors.New("workerpool: pool is closed")
+
+// Result is the outcome of processing one input.
+type Result[In, Out any] struct {
+	Input In
+	Value Out
+	Err   error
+}
+
+// Pool processes inputs of type In into outputs of type Out using a
+// bounded number of worker goroutines.
+type Pool[In, Out any] struct {
+	fn      func(context.Context, In) (Out, error)
+	ctx     context.Context
+	jobs    chan In
+	results chan Result[In, Out]
+	quit    chan struct{}
+	done    chan struct{}
+	once    sync.Once
+}
+
+// New starts a pool with the given number of workers (at least one).
+//
+// Every worker calls fn for each input it receives. The context is
+// passed to fn; cancelling it stops the workers, after which Submit
+// fails and Results is closed.
+func New[In, Out any](ctx context.Context, workers int, fn func(context.Context, In) (Out, error)) *Pool[In, Out] {
+	if workers < 1 {
+		workers = 1
+	}
+
+	p := &Pool[In, Out]{
+		fn:      fn,
+		ctx:     ctx,
+		jobs:    make(chan In),
+		results: make(chan Result[In, Out], workers),
+		quit:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Go(p.work)
+	}
+
+	// Close results only after every worker has stopped sending
+	go func() {
+		wg.Wait()
+		close(p.results)
+		close(p.done)
+	}()
+
+	return p
+}
+
+// work is the loop run by each worker goroutine.
+func (p *Pool[In, Out]) work() {
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-p.ctx.Done():
+			return
+		case in := <-p.jobs:
+			out, err := p.fn(p.ctx, in)
+
+			select {
+			case p.results <- Result[In, Out]{Input: in, Value: out, Err: err}:
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Submit hands an input to the next free worker. It blocks until a
+// worker accepts it, which gives natural backpressure.
+//
+// Submit returns ErrClosed after Drain and the context's error after
+// the pool's context is cancelled.
+func (p *Pool[In, Out]) Submit(in In) error {
+	// Check first so a closed pool never accepts work, even when a
+	// worker happens to be ready at the same moment
+	select {
+	case <-p.quit:
+		return ErrClosed
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	default:
+	}
+
+	select {
+	case p.jobs <- in:
+		return nil
+	case <-p.quit:
+		return ErrClosed
+	case <-p.ctx.Done():
+		return p.ctx.Err()
+	}
+}
+
+// Results returns the channel of processed results. It is closed once
+// all workers have stopped, after Drain or context cancellation.
+func (p *Pool[In, Out]) Results() <-chan Result[In, Out] {
+	return p.results
+}
+
+// Drain stops accepting new inputs and waits until every accepted input
+// has been processed and Results has been closed.
+//
+// Results must be read while Drain waits, otherwise the workers cannot
+// deliver their last results. Drain is safe to call more than once.
+func (p *Pool[In, Out]) Drain() {
+	p.once.Do(func() { close(p.quit) })
+	<-p.done
+}
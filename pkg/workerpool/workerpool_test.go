@@ -0,0 +1,190 @@
+package workerpool_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"testing/synctest"
+	"time"
+
+	"github.com/inancgumus/learngo/pkg/workerpool"
+)
+
+// double sleeps for a second of fake time and doubles its input
+func double(ctx context.Context, n int) (int, error) {
+	time.Sleep(time.Second)
+	return n * 2, nil
+}
+
+// submitAll submits inputs in order and then drains the pool
+func submitAll[In, Out any](t *testing.T, p *workerpool.Pool[In, Out], inputs []In) {
+	go func() {
+		for _, in := range inputs {
+			if err := p.Submit(in); err != nil {
+				t.Errorf("Submit(%v): %v", in, err)
+			}
+		}
+		p.Drain()
+	}()
+}
+
+func TestPoolProcessesEveryInput(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := workerpool.New(context.Background(), 3, double)
+		submitAll(t, p, []int{1, 2, 3, 4, 5})
+
+		var got []int
+		for r := range p.Results() {
+			if r.Err != nil {
+				t.Errorf("unexpected error for %d: %v", r.Input, r.Err)
+			}
+			if r.Value != r.Input*2 {
+				t.Errorf("input %d: want %d; got %d", r.Input, r.Input*2, r.Value)
+			}
+			got = append(got, r.Value)
+		}
+
+		slices.Sort(got)
+		if want := []int{2, 4, 6, 8, 10}; !slices.Equal(got, want) {
+			t.Errorf("want %v; got %v", want, got)
+		}
+	})
+}
+
+func TestPoolBoundsConcurrency(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		const workers = 3
+		var active, peak atomic.Int32
+
+		p := workerpool.New(context.Background(), workers,
+			func(ctx context.Context, n int) (int, error) {
+				now := active.Add(1)
+				for {
+					old := peak.Load()
+					if now <= old || peak.CompareAndSwap(old, now) {
+						break
+					}
+				}
+				time.Sleep(time.Second)
+				active.Add(-1)
+				return n, nil
+			})
+
+		start := time.Now()
+		submitAll(t, p, make([]int, 10))
+		for range p.Results() {
+		}
+
+		if got := peak.Load(); got != workers {
+			t.Errorf("want peak concurrency %d; got %d", workers, got)
+		}
+
+		// 10 jobs over 3 workers run in 4 rounds of one (fake) second
+		if got := time.Since(start); got != 4*time.Second {
+			t.Errorf("want 4s; got %v", got)
+		}
+	})
+}
+
+func TestPoolReportsErrors(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		errOdd := errors.New("odd input")
+
+		p := workerpool.New(context.Background(), 2,
+			func(ctx context.Context, n int) (int, error) {
+				if n%2 != 0 {
+					return 0, errOdd
+				}
+				return n, nil
+			})
+		submitAll(t, p, []int{1, 2, 3, 4})
+
+		failed := 0
+		for r := range p.Results() {
+			if errors.Is(r.Err, errOdd) {
+				failed++
+			}
+		}
+		if failed != 2 {
+			t.Errorf("want 2 failures; got %d", failed)
+		}
+	})
+}
+
+func TestSubmitAfterDrain(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		p := workerpool.New(context.Background(), 2, double)
+		p.Drain()
+
+		if err := p.Submit(1); !errors.Is(err, workerpool.ErrClosed) {
+			t.Errorf("want ErrClosed; got %v", err)
+		}
+
+		// Drain can be called again and Results is closed
+		p.Drain()
+		if _, ok := <-p.Results(); ok {
+			t.Error("want Results to be closed")
+		}
+	})
+}
+
+func TestDrainWaitsForInFlightJobs(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		var finished atomic.Int32
+
+		p := workerpool.New(context.Background(), 2,
+			func(ctx context.Context, n int) (int, error) {
+				time.Sleep(time.Duration(n) * time.Second)
+				finished.Add(1)
+				return n, nil
+			})
+
+		go func() {
+			for range p.Results() {
+			}
+		}()
+
+		p.Submit(5)
+		p.Submit(3)
+		p.Drain()
+
+		if got := finished.Load(); got != 2 {
+			t.Errorf("want 2 finished jobs after Drain; got %d", got)
+		}
+	})
+}
+
+func TestContextCancellation(t *testing.T) {
+	synctest.Test(t, func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		p := workerpool.New(ctx, 2,
+			func(ctx context.Context, n int) (int, error) {
+				select {
+				case <-time.After(time.Hour):
+					return n, nil
+				case <-ctx.Done():
+					return 0, ctx.Err()
+				}
+			})
+
+		p.Submit(1)
+		p.Submit(2)
+
+		time.Sleep(time.Minute)
+		cancel()
+
+		// Results closes once the workers notice the cancellation
+		for r := range p.Results() {
+			if !errors.Is(r.Err, context.Canceled) {
+				t.Errorf("want context.Canceled; got %v", r.Err)
+			}
+		}
+
+		if err := p.Submit(3); !errors.Is(err, context.Canceled) {
+			t.Errorf("want context.Canceled from Submit; got %v", err)
+		}
+	})
+}